@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// PutAliasRequest defines the index an alias points to
+type PutAliasRequest struct {
+	Index string `json:"index" binding:"required"`
+}
+
+// SwapAliasesRequest defines the two aliases whose indexes are exchanged
+type SwapAliasesRequest struct {
+	Aliases []string `json:"aliases" binding:"required,len=2"`
+}
+
+// ListAliasesHandler handles listing every index alias.
+func (api *API) ListAliasesHandler(c *gin.Context) {
+	aliasManager, ok := api.aliasManager(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aliases": aliasManager.ListAliases()})
+}
+
+// GetAliasHandler handles requests for the index an alias points to.
+func (api *API) GetAliasHandler(c *gin.Context) {
+	alias := c.Param("alias")
+
+	aliasManager, ok := api.aliasManager(c)
+	if !ok {
+		return
+	}
+
+	result, err := aliasManager.GetAlias(alias)
+	if err != nil {
+		sendAliasError(c, alias, "get alias", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PutAliasHandler handles pointing an alias at an index, creating the alias or atomically moving it
+// from its current index.
+func (api *API) PutAliasHandler(c *gin.Context) {
+	alias := c.Param("alias")
+
+	if result := ValidateIndexName(alias); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	aliasManager, ok := api.aliasManager(c)
+	if !ok {
+		return
+	}
+
+	var req PutAliasRequest
+	if result := ValidateJSONBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	previous, err := aliasManager.PutAlias(alias, req.Index)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrIndexNotFound) {
+			SendIndexNotFoundError(c, req.Index)
+			return
+		}
+		sendAliasError(c, alias, "put alias", err)
+		return
+	}
+
+	response := gin.H{"alias": alias, "index": req.Index}
+	if previous != "" {
+		response["previous_index"] = previous
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SwapAliasesHandler handles exchanging the indexes of two aliases in a single step, so traffic
+// moves to a rebuilt index without downtime.
+func (api *API) SwapAliasesHandler(c *gin.Context) {
+	aliasManager, ok := api.aliasManager(c)
+	if !ok {
+		return
+	}
+
+	var req SwapAliasesRequest
+	if result := ValidateJSONBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	aliases, err := aliasManager.SwapAliases(req.Aliases[0], req.Aliases[1])
+	if err != nil {
+		var aliasErr *internalErrors.AliasNotFoundError
+		if errors.As(err, &aliasErr) {
+			SendAliasNotFoundError(c, aliasErr.Alias)
+			return
+		}
+		sendAliasError(c, "", "swap aliases", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aliases": aliases})
+}
+
+// DeleteAliasHandler handles removing an alias; the index it points to is kept.
+func (api *API) DeleteAliasHandler(c *gin.Context) {
+	alias := c.Param("alias")
+
+	aliasManager, ok := api.aliasManager(c)
+	if !ok {
+		return
+	}
+
+	if err := aliasManager.DeleteAlias(alias); err != nil {
+		sendAliasError(c, alias, "delete alias", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alias '" + alias + "' deleted"})
+}
+
+// aliasManager returns the engine's alias operations, or sends an error if the engine does not support them.
+func (api *API) aliasManager(c *gin.Context) (services.AliasManager, bool) {
+	aliasManager, ok := api.engine.(services.AliasManager)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Aliases not supported by this engine")
+	}
+	return aliasManager, ok
+}
+
+// sendAliasError maps alias errors to API error responses.
+func sendAliasError(c *gin.Context, alias, operation string, err error) {
+	var validationErr *internalErrors.ValidationError
+	switch {
+	case errors.Is(err, internalErrors.ErrAliasNotFound):
+		SendAliasNotFoundError(c, alias)
+	case errors.As(err, &validationErr):
+		SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+	default:
+		SendInternalError(c, operation, err)
+	}
+}
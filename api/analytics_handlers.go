@@ -1,11 +1,15 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
 )
 
 // GetAnalyticsHandler handles the request to get analytics data
@@ -27,3 +31,97 @@ func (api *API) HealthCheckHandler(c *gin.Context) {
 		"timestamp": fmt.Sprintf("%d", time.Now().Unix()),
 	})
 }
+
+const (
+	defaultAnalyticsReportWindow = 24 * time.Hour
+	defaultAnalyticsReportLimit  = 10
+	maxAnalyticsReportLimit      = 100
+)
+
+// AnalyticsReportRequest holds the query parameters of the per-index analytics reports
+type AnalyticsReportRequest struct {
+	Window string `form:"window"`
+	Limit  int    `form:"limit"`
+}
+
+// bindAnalyticsReportRequest checks that the index exists and parses the window and limit of a
+// per-index analytics report. On failure the error response is sent and ok is false.
+func (api *API) bindAnalyticsReportRequest(c *gin.Context) (indexName string, window time.Duration, limit int, ok bool) {
+	indexName = c.Param("indexName")
+
+	if _, err := api.engine.GetIndexSettings(indexName); err != nil {
+		if errors.Is(err, internalErrors.ErrIndexNotFound) {
+			SendIndexNotFoundError(c, indexName)
+		} else {
+			SendInternalError(c, "get index", err)
+		}
+		return "", 0, 0, false
+	}
+
+	var req AnalyticsReportRequest
+	if result := ValidateQueryBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return "", 0, 0, false
+	}
+
+	window, limit, result := ValidateAnalyticsReportParams(req.Window, req.Limit)
+	if result.HasErrors() {
+		SendValidationError(c, result)
+		return "", 0, 0, false
+	}
+	return indexName, window, limit, true
+}
+
+// GetPopularSearchesHandler lists the most frequent queries of an index that returned results,
+// so frontends can show trending searches.
+func (api *API) GetPopularSearchesHandler(c *gin.Context) {
+	indexName, window, limit, ok := api.bindAnalyticsReportRequest(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, model.PopularSearchesReport{
+		IndexName: indexName,
+		Window:    window.String(),
+		Searches:  api.analytics.GetPopularSearches(indexName, window, limit),
+	})
+}
+
+// GetTopQueriesHandler lists the most frequent queries of an index, including those that returned
+// no results, with their zero-result counts and average response times.
+func (api *API) GetTopQueriesHandler(c *gin.Context) {
+	indexName, window, limit, ok := api.bindAnalyticsReportRequest(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, model.QueryStatsReport{
+		IndexName: indexName,
+		Window:    window.String(),
+		Queries:   api.analytics.GetTopQueries(indexName, window, limit),
+	})
+}
+
+// GetZeroResultQueriesHandler lists the queries of an index that most often returned no results.
+func (api *API) GetZeroResultQueriesHandler(c *gin.Context) {
+	indexName, window, limit, ok := api.bindAnalyticsReportRequest(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, model.QueryStatsReport{
+		IndexName: indexName,
+		Window:    window.String(),
+		Queries:   api.analytics.GetZeroResultQueries(indexName, window, limit),
+	})
+}
+
+// GetSearchLatencyHandler reports the response time percentiles of the searches of an index.
+func (api *API) GetSearchLatencyHandler(c *gin.Context) {
+	indexName, window, _, ok := api.bindAnalyticsReportRequest(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, api.analytics.GetLatencyReport(indexName, window))
+}
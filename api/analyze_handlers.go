@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// AnalyzeHandler handles previewing the tokens an index produces for a field value and for a
+// query, to debug why a document does not match.
+func (api *API) AnalyzeHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	textAnalyzer, ok := api.engine.(services.TextAnalyzer)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Text analysis not supported by this engine")
+		return
+	}
+
+	var request model.AnalyzeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		SendInvalidJSONError(c, err)
+		return
+	}
+
+	result, err := textAnalyzer.Analyze(indexName, request)
+	if err != nil {
+		var validationErr *internalErrors.ValidationError
+		switch {
+		case errors.Is(err, internalErrors.ErrIndexNotFound):
+			SendIndexNotFoundError(c, indexName)
+		case errors.As(err, &validationErr):
+			SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+		default:
+			SendInternalError(c, "analyze text", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
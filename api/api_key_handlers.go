@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// ListAPIKeysHandler handles listing the API keys, without their secrets.
+func (api *API) ListAPIKeysHandler(c *gin.Context) {
+	keyManager, ok := api.keyManager(c)
+	if !ok {
+		return
+	}
+
+	keys := keyManager.ListAPIKeys()
+	c.JSON(http.StatusOK, gin.H{"keys": keys, "total": len(keys)})
+}
+
+// GetAPIKeyHandler handles requests to get a single API key, without its secret.
+func (api *API) GetAPIKeyHandler(c *gin.Context) {
+	keyID := c.Param("keyId")
+
+	keyManager, ok := api.keyManager(c)
+	if !ok {
+		return
+	}
+
+	key, err := keyManager.GetAPIKey(keyID)
+	if err != nil {
+		sendAPIKeyError(c, keyID, "get API key", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// CreateAPIKeyHandler handles creating an API key. The response is the only one with its secret.
+func (api *API) CreateAPIKeyHandler(c *gin.Context) {
+	keyManager, ok := api.keyManager(c)
+	if !ok {
+		return
+	}
+
+	var key model.APIKey
+	if err := c.ShouldBindJSON(&key); err != nil {
+		SendInvalidJSONError(c, err)
+		return
+	}
+
+	created, err := keyManager.CreateAPIKey(key)
+	if err != nil {
+		sendAPIKeyError(c, "", "create API key", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// DeleteAPIKeyHandler handles revoking an API key.
+func (api *API) DeleteAPIKeyHandler(c *gin.Context) {
+	keyID := c.Param("keyId")
+
+	keyManager, ok := api.keyManager(c)
+	if !ok {
+		return
+	}
+
+	if err := keyManager.DeleteAPIKey(keyID); err != nil {
+		sendAPIKeyError(c, keyID, "delete API key", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key '" + keyID + "' deleted"})
+}
+
+// keyManager returns the engine's API key operations, or sends an error if the engine does not support them.
+func (api *API) keyManager(c *gin.Context) (services.APIKeyManager, bool) {
+	keyManager, ok := api.engine.(services.APIKeyManager)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "API keys not supported by this engine")
+	}
+	return keyManager, ok
+}
+
+// sendAPIKeyError maps API key operation errors to API error responses.
+func sendAPIKeyError(c *gin.Context, keyID, operation string, err error) {
+	var validationErr *internalErrors.ValidationError
+	switch {
+	case errors.Is(err, internalErrors.ErrAPIKeyNotFound):
+		SendAPIKeyNotFoundError(c, keyID)
+	case errors.As(err, &validationErr):
+		SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+	default:
+		SendInternalError(c, operation, err)
+	}
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gcbaptista/go-search-engine/internal/auth"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// apiKeyContextKey is the gin context key of the API key a request was authorized with
+const apiKeyContextKey = "api_key"
+
+// publicRoutes need no key
+var publicRoutes = map[string]bool{
+	"GET /health": true,
+}
+
+// routeActions are the actions API keys need for each route, by method and route path. Routes
+// missing here, such as key management, snapshots and benchmarks, need the admin key.
+var routeActions = map[string]model.APIKeyAction{
+	"GET /analytics":      model.APIKeyActionIndexesRead,
+	"GET /jobs":           model.APIKeyActionIndexesRead,
+	"GET /jobs/:jobId":    model.APIKeyActionIndexesRead,
+	"DELETE /jobs/:jobId": model.APIKeyActionIndexesWrite,
+	"GET /jobs/metrics":   model.APIKeyActionIndexesRead,
+	"POST /jobs/_cleanup": model.APIKeyActionIndexesWrite,
+
+	"GET /aliases":           model.APIKeyActionIndexesRead,
+	"GET /aliases/:alias":    model.APIKeyActionIndexesRead,
+	"PUT /aliases/:alias":    model.APIKeyActionIndexesWrite,
+	"DELETE /aliases/:alias": model.APIKeyActionIndexesWrite,
+	"POST /aliases/_swap":    model.APIKeyActionIndexesWrite,
+
+	"POST /indexes":                                      model.APIKeyActionIndexesWrite,
+	"GET /indexes":                                       model.APIKeyActionIndexesRead,
+	"DELETE /indexes":                                    model.APIKeyActionIndexesWrite,
+	"GET /indexes/:indexName":                            model.APIKeyActionIndexesRead,
+	"DELETE /indexes/:indexName":                         model.APIKeyActionIndexesWrite,
+	"PATCH /indexes/:indexName/settings":                 model.APIKeyActionIndexesWrite,
+	"GET /indexes/:indexName/settings/_diff/:otherIndex": model.APIKeyActionIndexesRead,
+	"POST /indexes/:indexName/rename":                    model.APIKeyActionIndexesWrite,
+	"GET /indexes/:indexName/stats":                      model.APIKeyActionIndexesRead,
+	"GET /indexes/:indexName/jobs":                       model.APIKeyActionIndexesRead,
+	"POST /indexes/:indexName/_rollback":                 model.APIKeyActionDocumentsWrite,
+	"PUT /indexes/:indexName/_shadow":                    model.APIKeyActionIndexesWrite,
+	"GET /indexes/:indexName/_shadow":                    model.APIKeyActionIndexesRead,
+	"DELETE /indexes/:indexName/_shadow":                 model.APIKeyActionIndexesWrite,
+	"POST /indexes/:indexName/_analyze":                  model.APIKeyActionIndexesRead,
+	"POST /indexes/:indexName/_spellcheck":               model.APIKeyActionSearch,
+	"GET /indexes/:indexName/_terms":                     model.APIKeyActionIndexesRead,
+	"GET /indexes/:indexName/_suggest":                   model.APIKeyActionSearch,
+	"POST /indexes/:indexName/_verify":                   model.APIKeyActionIndexesWrite,
+	"GET /indexes/:indexName/_seq":                       model.APIKeyActionDocumentsRead,
+	"POST /indexes/:indexName/_close":                    model.APIKeyActionIndexesWrite,
+	"POST /indexes/:indexName/_open":                     model.APIKeyActionIndexesWrite,
+
+	"GET /indexes/:indexName/popular_searches":    model.APIKeyActionIndexesRead,
+	"GET /indexes/:indexName/top_queries":         model.APIKeyActionIndexesRead,
+	"GET /indexes/:indexName/zero_result_queries": model.APIKeyActionIndexesRead,
+	"GET /indexes/:indexName/search_latency":      model.APIKeyActionIndexesRead,
+
+	"PUT /indexes/:indexName/documents":                model.APIKeyActionDocumentsWrite,
+	"PUT /indexes/:indexName/documents/_bulk":          model.APIKeyActionDocumentsWrite,
+	"GET /indexes/:indexName/documents":                model.APIKeyActionDocumentsRead,
+	"POST /indexes/:indexName/documents/_mget":         model.APIKeyActionDocumentsRead,
+	"GET /indexes/:indexName/documents/_export":        model.APIKeyActionDocumentsRead,
+	"DELETE /indexes/:indexName/documents":             model.APIKeyActionDocumentsWrite,
+	"GET /indexes/:indexName/documents/:documentId":    model.APIKeyActionDocumentsRead,
+	"DELETE /indexes/:indexName/documents/:documentId": model.APIKeyActionDocumentsWrite,
+
+	"POST /indexes/:indexName/_batch":                                  model.APIKeyActionDocumentsWrite,
+	"GET /indexes/:indexName/_batch/:batchId":                          model.APIKeyActionDocumentsWrite,
+	"DELETE /indexes/:indexName/_batch/:batchId":                       model.APIKeyActionDocumentsWrite,
+	"PUT /indexes/:indexName/_batch/:batchId/documents":                model.APIKeyActionDocumentsWrite,
+	"DELETE /indexes/:indexName/_batch/:batchId/documents/:documentId": model.APIKeyActionDocumentsWrite,
+	"POST /indexes/:indexName/_batch/:batchId/_commit":                 model.APIKeyActionDocumentsWrite,
+
+	"GET /indexes/:indexName/rules":            model.APIKeyActionIndexesRead,
+	"POST /indexes/:indexName/rules":           model.APIKeyActionIndexesWrite,
+	"POST /indexes/:indexName/rules/_bulk":     model.APIKeyActionIndexesWrite,
+	"GET /indexes/:indexName/rules/_export":    model.APIKeyActionIndexesRead,
+	"GET /indexes/:indexName/rules/:ruleId":    model.APIKeyActionIndexesRead,
+	"PUT /indexes/:indexName/rules/:ruleId":    model.APIKeyActionIndexesWrite,
+	"DELETE /indexes/:indexName/rules/:ruleId": model.APIKeyActionIndexesWrite,
+
+	"GET /indexes/:indexName/relevance_tests":       model.APIKeyActionIndexesRead,
+	"PUT /indexes/:indexName/relevance_tests":       model.APIKeyActionIndexesWrite,
+	"POST /indexes/:indexName/relevance_tests/_run": model.APIKeyActionIndexesRead,
+
+	"POST /indexes/:indexName/_search":              model.APIKeyActionSearch,
+	"POST /indexes/:indexName/_multi_search":        model.APIKeyActionSearch,
+	"POST /indexes/:indexName/_search/export":       model.APIKeyActionSearch,
+	"GET /indexes/:indexName/_search/export/:jobId": model.APIKeyActionSearch,
+}
+
+// filteredRoutes are the only routes keys with filters can use: those that read documents and limit
+// them to the ones matching the key's filters. There is no delete-by-query route, and document
+// writes are not filtered, so they stay forbidden to such keys.
+var filteredRoutes = map[string]bool{
+	"POST /indexes/:indexName/_search":              true,
+	"POST /indexes/:indexName/_multi_search":        true,
+	"POST /indexes/:indexName/_search/export":       true,
+	"GET /indexes/:indexName/_search/export/:jobId": true,
+	"GET /indexes/:indexName/_suggest":              true,
+	"POST /indexes/:indexName/_spellcheck":          true,
+	"GET /indexes/:indexName/documents":             true,
+	"GET /indexes/:indexName/documents/:documentId": true,
+	"POST /indexes/:indexName/documents/_mget":      true,
+	"GET /indexes/:indexName/documents/_export":     true,
+}
+
+// AuthMiddleware authorizes requests once the engine has an admin key. Requests present a key in
+// an "Authorization: Bearer <key>" or "X-API-Key" header: the admin key is allowed every request,
+// and an API key only the actions and indexes of its scope. Requests without a valid key get a
+// 401 and those outside the key's scope a 403.
+//
+// Index names are checked as requested and, when they are the old name of a renamed index or an
+// alias, as resolved, so both must match the key's index patterns.
+func AuthMiddleware(engine services.IndexManager) gin.HandlerFunc {
+	keyManager, ok := engine.(services.APIKeyManager)
+	aliasResolver, _ := engine.(services.AliasManager)
+	renameResolver, _ := engine.(services.RenameAliasResolver)
+	return gin.HandlerFunc(func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		if !ok || !keyManager.AuthEnabled() || publicRoutes[route] {
+			c.Next()
+			return
+		}
+
+		key, admin, valid := keyManager.Authenticate(requestKey(c))
+		if !valid {
+			c.Header("WWW-Authenticate", "Bearer")
+			SendError(c, ErrorCodeUnauthorized, "A valid API key is required")
+			c.Abort()
+			return
+		}
+		if admin {
+			c.Next()
+			return
+		}
+
+		action, known := routeActions[route]
+		if !known || !auth.Allows(key, action) {
+			SendError(c, ErrorCodeForbidden, "API key '"+key.ID+"' is not allowed this request")
+			c.Abort()
+			return
+		}
+		if len(key.Filters) > 0 && !filteredRoutes[route] {
+			SendError(c, ErrorCodeForbidden, "API key '"+key.ID+"' has filters and can only search and read documents")
+			c.Abort()
+			return
+		}
+		if len(key.Indexes) > 0 {
+			names := requestIndexNames(c, aliasResolver, renameResolver)
+			if len(names) == 0 {
+				SendError(c, ErrorCodeForbidden, "API key '"+key.ID+"' is restricted to some indexes and cannot make requests across indexes")
+				c.Abort()
+				return
+			}
+			for _, name := range names {
+				if !auth.AllowsIndex(key, name) {
+					SendError(c, ErrorCodeForbidden, "API key '"+key.ID+"' is not allowed on index '"+name+"'")
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	})
+}
+
+// requestKey returns the key a request presents, from its Authorization or X-API-Key header.
+func requestKey(c *gin.Context) string {
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		if scheme, key, found := strings.Cut(authorization, " "); found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(key)
+		}
+		return ""
+	}
+	return c.GetHeader("X-API-Key")
+}
+
+// requestIndexNames returns the index names of a request's path parameters, with the names they
+// resolve to when they are old names of renamed indexes or aliases.
+func requestIndexNames(c *gin.Context, aliasResolver services.AliasManager, renameResolver services.RenameAliasResolver) []string {
+	var names []string
+	for _, param := range c.Params {
+		if param.Key != "indexName" && param.Key != "otherIndex" {
+			continue
+		}
+		name := param.Value
+		names = append(names, name)
+		if renameResolver != nil {
+			if newName, _, renamed := renameResolver.ResolveRenamedIndex(name); renamed {
+				name = newName
+				names = append(names, name)
+			}
+		}
+		if aliasResolver != nil {
+			if indexName, aliased := aliasResolver.ResolveAlias(name); aliased {
+				names = append(names, indexName)
+			}
+		}
+	}
+	return names
+}
+
+// requestAPIKey returns the API key a request was authorized with, if any. Requests with the admin
+// key, or while API keys are disabled, have none.
+func requestAPIKey(c *gin.Context) (model.APIKey, bool) {
+	if value, exists := c.Get(apiKeyContextKey); exists {
+		key, ok := value.(model.APIKey)
+		return key, ok
+	}
+	return model.APIKey{}, false
+}
+
+// withKeyFilters adds the filter conditions of the API key a request was authorized with, if any,
+// to a query's filters. Without filters of its own, the query gets nil unless the key has filters.
+func withKeyFilters(c *gin.Context, filters *services.Filters) *services.Filters {
+	if key, ok := requestAPIKey(c); ok {
+		return auth.AddFilters(key, filters)
+	}
+	return filters
+}
+
+// keyFilterConditions returns the filter conditions of the API key a request was authorized with,
+// if any.
+func keyFilterConditions(c *gin.Context) []model.RuleFilter {
+	key, _ := requestAPIKey(c)
+	return key.Filters
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// OpenBatchHandler handles opening a write batch on an index.
+func (api *API) OpenBatchHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	if result := ValidateIndexName(indexName); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	batchManager, ok := api.batchManager(c)
+	if !ok {
+		return
+	}
+
+	batch, err := batchManager.OpenBatch(indexName)
+	if err != nil {
+		sendBatchError(c, indexName, "", "open batch", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, batch)
+}
+
+// GetBatchHandler handles requests to get the state of an open batch.
+func (api *API) GetBatchHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	batchID := c.Param("batchId")
+
+	batchManager, ok := api.batchManager(c)
+	if !ok {
+		return
+	}
+
+	batch, err := batchManager.GetBatch(indexName, batchID)
+	if err != nil {
+		sendBatchError(c, indexName, batchID, "get batch", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// AddBatchDocumentsHandler handles staging documents to be added or updated when a batch is committed.
+func (api *API) AddBatchDocumentsHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	batchID := c.Param("batchId")
+
+	batchManager, ok := api.batchManager(c)
+	if !ok {
+		return
+	}
+
+	docs, ok := bindDocuments(c)
+	if !ok {
+		return
+	}
+
+	batch, err := batchManager.AddToBatch(indexName, batchID, docs)
+	if err != nil {
+		sendBatchError(c, indexName, batchID, "stage documents", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// DeleteBatchDocumentHandler handles staging the deletion of a document when a batch is committed.
+func (api *API) DeleteBatchDocumentHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	batchID := c.Param("batchId")
+	documentID := c.Param("documentId")
+
+	if result := ValidateDocumentID(documentID); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	batchManager, ok := api.batchManager(c)
+	if !ok {
+		return
+	}
+
+	batch, err := batchManager.DeleteInBatch(indexName, batchID, documentID)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrDocumentNotFound) {
+			SendDocumentNotFoundError(c, documentID, indexName)
+			return
+		}
+		sendBatchError(c, indexName, batchID, "stage deletion", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// AbortBatchHandler handles discarding an open batch.
+func (api *API) AbortBatchHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	batchID := c.Param("batchId")
+
+	batchManager, ok := api.batchManager(c)
+	if !ok {
+		return
+	}
+
+	if err := batchManager.AbortBatch(indexName, batchID); err != nil {
+		sendBatchError(c, indexName, batchID, "abort batch", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Batch '%s' aborted", batchID)})
+}
+
+// CommitBatchHandler handles committing a batch. The staged changes are applied
+// asynchronously and become visible to searches all at once.
+func (api *API) CommitBatchHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	batchID := c.Param("batchId")
+
+	batchManager, ok := api.batchManager(c)
+	if !ok {
+		return
+	}
+
+	jobID, err := batchManager.CommitBatchAsync(indexName, batchID)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrIndexNotFound) || errors.Is(err, internalErrors.ErrBatchNotFound) {
+			sendBatchError(c, indexName, batchID, "commit batch", err)
+			return
+		}
+		SendJobExecutionError(c, "batch commit", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":   "accepted",
+		"message":  fmt.Sprintf("Commit of batch '%s' started for index '%s'", batchID, indexName),
+		"job_id":   jobID,
+		"batch_id": batchID,
+	})
+}
+
+// batchManager returns the engine's batch operations, or sends an error if the engine does not support them.
+func (api *API) batchManager(c *gin.Context) (services.BatchManager, bool) {
+	batchManager, ok := api.engine.(services.BatchManager)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Batch operations not supported by this engine")
+	}
+	return batchManager, ok
+}
+
+// sendBatchError maps batch operation errors to API error responses.
+func sendBatchError(c *gin.Context, indexName, batchID, operation string, err error) {
+	var validationErr *internalErrors.ValidationError
+	switch {
+	case errors.Is(err, internalErrors.ErrIndexNotFound):
+		SendIndexNotFoundError(c, indexName)
+	case errors.Is(err, internalErrors.ErrBatchNotFound):
+		SendBatchNotFoundError(c, batchID)
+	case errors.As(err, &validationErr):
+		SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+	default:
+		SendInternalError(c, operation, err)
+	}
+}
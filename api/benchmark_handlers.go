@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gcbaptista/go-search-engine/benchmarks"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+)
+
+// BenchmarkHandler handles running a benchmark: a synthetic corpus is generated, indexed into an
+// in-memory index separate from the engine's indexes and searched, and the indexing and search
+// throughput and latency are returned. An empty body runs the default benchmark.
+func (api *API) BenchmarkHandler(c *gin.Context) {
+	var spec benchmarks.RunSpec
+	if c.Request.ContentLength != 0 {
+		if result := ValidateJSONBinding(c, &spec); result.HasErrors() {
+			SendValidationError(c, result)
+			return
+		}
+	}
+
+	report, err := benchmarks.Run(spec)
+	if err != nil {
+		var validationErr *internalErrors.ValidationError
+		if errors.As(err, &validationErr) {
+			SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+		} else {
+			SendInternalError(c, "run benchmark", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
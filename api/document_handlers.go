@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,7 +11,9 @@ import (
 
 	"github.com/gcbaptista/go-search-engine/internal/engine"
 	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/logging"
 	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
 )
 
 // AddDocumentsHandler handles adding/updating documents in an index.
@@ -33,67 +36,56 @@ func (api *API) AddDocumentsHandler(c *gin.Context) {
 		return
 	}
 
-	// Read the raw JSON data first
-	var rawData interface{}
-	if result := ValidateJSONBinding(c, &rawData); result.HasErrors() {
+	idempotencyKey := c.GetHeader(idempotencyKeyHeader)
+	if result := ValidateIdempotencyKey(idempotencyKey); result.HasErrors() {
 		SendValidationError(c, result)
 		return
 	}
 
-	var docs []model.Document
-
-	// Check if the raw data is a slice (array) or a single object
-	if dataSlice, isSlice := rawData.([]interface{}); isSlice {
-		// Handle array of documents
-		docs = make([]model.Document, len(dataSlice))
-		for i, item := range dataSlice {
-			if docMap, isMap := item.(map[string]interface{}); isMap {
-				docs[i] = docMap
-			} else {
-				SendError(c, http.StatusBadRequest, ErrorCodeInvalidRequest, fmt.Sprintf("Document at index %d is not a valid object", i))
-				return
-			}
-		}
-	} else if docMap, isMap := rawData.(map[string]interface{}); isMap {
-		// Handle single document
-		docs = []model.Document{docMap}
-	} else {
-		SendError(c, http.StatusBadRequest, ErrorCodeInvalidRequest, "Invalid request body. Expecting a document object or an array of documents")
-		return
-	}
-
-	// Validate documents
-	if result := ValidateDocuments(docs); result.HasErrors() {
-		SendValidationError(c, result)
+	docs, ok := readDocuments(c)
+	if !ok {
 		return
 	}
 
-	// Clean up document IDs (trim whitespace)
-	for i := range docs {
-		docMap := docs[i]
-		if docIDVal, exists := docMap["documentID"]; exists {
-			if docIDStr, ok := docIDVal.(string); ok {
-				docMap["documentID"] = strings.TrimSpace(docIDStr)
-			}
+	// Assign IDs to the documents without one, when the index generates them
+	var generatedIDs map[int]string
+	if generator, ok := api.engine.(services.DocumentIDGenerator); ok {
+		generatedIDs, err = generator.GenerateDocumentIDs(indexName, idempotencyKey, docs)
+		if err != nil {
+			SendInternalError(c, "generate document IDs", err)
+			return
 		}
 	}
+	if !validateDocuments(c, docs) {
+		return
+	}
 
 	// Add documents asynchronously
 	var jobID string
 	if concreteEngine, ok := api.engine.(*engine.Engine); ok {
-		jobID, err = concreteEngine.AddDocumentsAsync(indexName, docs)
+		jobID, err = startWriteJob(c, concreteEngine, indexName, idempotencyKey, "add_documents", docs, func() (string, error) {
+			return concreteEngine.AddDocumentsAsync(indexName, docs)
+		})
 		if err != nil {
+			if errors.Is(err, internalErrors.ErrIdempotencyKeyReused) {
+				SendIdempotencyKeyReusedError(c, idempotencyKey)
+				return
+			}
 			SendJobExecutionError(c, "document addition", err)
 			return
 		}
 
 		// Return job ID with 202 Accepted status
-		c.JSON(http.StatusAccepted, gin.H{
+		response := gin.H{
 			"status":         "accepted",
 			"message":        fmt.Sprintf("Document addition started for index '%s' (%d documents)", indexName, len(docs)),
 			"job_id":         jobID,
 			"document_count": len(docs),
-		})
+		}
+		if len(generatedIDs) > 0 {
+			response["generated_ids"] = generatedIDs
+		}
+		c.JSON(http.StatusAccepted, response)
 	} else {
 		indexAccessor, _ := api.engine.GetIndex(indexName)
 		err = indexAccessor.AddDocuments(docs)
@@ -101,8 +93,50 @@ func (api *API) AddDocumentsHandler(c *gin.Context) {
 			SendIndexingError(c, "add documents", err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%d document(s) added/updated in index '%s'", len(docs), indexName)})
+		response := gin.H{"message": fmt.Sprintf("%d document(s) added/updated in index '%s'", len(docs), indexName)}
+		if len(generatedIDs) > 0 {
+			response["generated_ids"] = generatedIDs
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// BulkIngestHandler handles streaming newline-delimited JSON documents, one per line, into an index.
+// Documents are indexed as they are read, without loading the whole body, and the response reports
+// the lines that are not valid documents.
+func (api *API) BulkIngestHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	// Validate index name
+	if result := ValidateIndexName(indexName); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	ingester, ok := api.engine.(services.BulkIngester)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Bulk ingest not supported by this engine")
+		return
+	}
+
+	report, err := ingester.BulkIngest(indexName, c.Request.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, internalErrors.ErrIndexNotFound):
+			SendIndexNotFoundError(c, indexName)
+		case errors.Is(err, internalErrors.ErrInvalidInput):
+			SendError(c, ErrorCodeValidationFailed, fmt.Sprintf("%v (%d documents indexed)", err, report.Indexed))
+		default:
+			SendIndexingError(c, "bulk ingest", err)
+		}
+		return
+	}
+	if report.Lines == 0 {
+		SendError(c, ErrorCodeValidationFailed, "No documents provided")
+		return
 	}
+
+	c.JSON(http.StatusOK, report)
 }
 
 // DeleteAllDocumentsHandler handles the request to delete all documents from an index.
@@ -125,11 +159,23 @@ func (api *API) DeleteAllDocumentsHandler(c *gin.Context) {
 		return
 	}
 
+	idempotencyKey := c.GetHeader(idempotencyKeyHeader)
+	if result := ValidateIdempotencyKey(idempotencyKey); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
 	// Delete all documents asynchronously
 	var jobID string
 	if concreteEngine, ok := api.engine.(*engine.Engine); ok {
-		jobID, err = concreteEngine.DeleteAllDocumentsAsync(indexName)
+		jobID, err = startWriteJob(c, concreteEngine, indexName, idempotencyKey, "delete_all_documents", nil, func() (string, error) {
+			return concreteEngine.DeleteAllDocumentsAsync(indexName)
+		})
 		if err != nil {
+			if errors.Is(err, internalErrors.ErrIdempotencyKeyReused) {
+				SendIdempotencyKeyReusedError(c, idempotencyKey)
+				return
+			}
 			SendJobExecutionError(c, "document deletion", err)
 			return
 		}
@@ -151,13 +197,14 @@ func (api *API) DeleteAllDocumentsHandler(c *gin.Context) {
 	}
 }
 
-// DocumentListRequest defines the structure for document listing requests
+// DocumentListRequest defines the page of documents listed and their fields
 type DocumentListRequest struct {
-	Page     int `form:"page" json:"page"`
-	PageSize int `form:"page_size" json:"page_size"`
+	Page              int      `form:"page" json:"page"`
+	PageSize          int      `form:"page_size" json:"page_size"`
+	RetrievableFields []string `form:"retrievable_fields" json:"retrievable_fields"` // Optional: fields to return besides documentID, repeated; all fields when empty
 }
 
-// GetDocumentsHandler lists documents in an index with pagination
+// GetDocumentsHandler lists documents in an index with pagination, in documentID order
 func (api *API) GetDocumentsHandler(c *gin.Context) {
 	indexName := c.Param("indexName")
 
@@ -167,13 +214,9 @@ func (api *API) GetDocumentsHandler(c *gin.Context) {
 		return
 	}
 
-	_, err := api.engine.GetIndex(indexName)
-	if err != nil {
-		if errors.Is(err, internalErrors.ErrIndexNotFound) {
-			SendIndexNotFoundError(c, indexName)
-			return
-		}
-		SendInternalError(c, "get index", err)
+	documentFetcher, ok := api.engine.(services.DocumentFetcher)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Document listing not supported by this engine")
 		return
 	}
 
@@ -191,45 +234,28 @@ func (api *API) GetDocumentsHandler(c *gin.Context) {
 		SendValidationError(c, result)
 		return
 	}
-	req.Page = page
-	req.PageSize = pageSize
-
-	var documents []model.Document
-	totalCount := 0
 
-	if concreteEngine, ok := api.engine.(*engine.Engine); ok {
-		if instance, err := concreteEngine.GetIndex(indexName); err == nil {
-			if engineInstance, ok := instance.(*engine.IndexInstance); ok {
-				allDocs := engineInstance.DocumentStore.Docs
-				totalCount = len(allDocs)
-
-				// Calculate pagination
-				startIndex := (req.Page - 1) * req.PageSize
-				endIndex := startIndex + req.PageSize
-
-				i := 0
-				for _, doc := range allDocs {
-					if i >= startIndex && i < endIndex {
-						documents = append(documents, doc)
-					}
-					i++
-					if i >= endIndex {
-						break
-					}
-				}
-			}
+	var list model.DocumentList
+	var err error
+	if withKeyFilters(c, nil) != nil {
+		list, err = api.listMatchingDocuments(c, documentFetcher, indexName, page, pageSize, req.RetrievableFields)
+	} else {
+		list, err = documentFetcher.ListDocuments(indexName, page, pageSize, req.RetrievableFields)
+	}
+	if err != nil {
+		if errors.Is(err, errKeyFiltersNotSupported) {
+			return
 		}
+		sendDocumentFetchError(c, indexName, "list documents", err)
+		return
 	}
 
-	response := gin.H{
-		"documents": documents,
-		"total":     totalCount,
-		"page":      req.Page,
-		"page_size": req.PageSize,
-		"pages":     (totalCount + req.PageSize - 1) / req.PageSize,
-	}
+	c.JSON(http.StatusOK, list)
+}
 
-	c.JSON(http.StatusOK, response)
+// DocumentGetRequest defines the fields returned by a document retrieval
+type DocumentGetRequest struct {
+	RetrievableFields []string `form:"retrievable_fields"` // Optional: fields to return besides documentID, repeated; all fields when empty
 }
 
 // GetDocumentHandler retrieves a specific document by ID
@@ -249,39 +275,238 @@ func (api *API) GetDocumentHandler(c *gin.Context) {
 		return
 	}
 
-	_, err := api.engine.GetIndex(indexName)
+	documentFetcher, ok := api.engine.(services.DocumentFetcher)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Document retrieval not supported by this engine")
+		return
+	}
+
+	var req DocumentGetRequest
+	if result := ValidateQueryBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	document, err := documentFetcher.GetDocument(indexName, documentId, req.RetrievableFields)
+	if err == nil {
+		// A document that the API key's filters exclude is not found, so as not to reveal it exists
+		var matching []string
+		matching, err = api.keyMatchingDocumentIDs(c, indexName, []string{documentId})
+		if err == nil && len(matching) == 0 {
+			err = internalErrors.ErrDocumentNotFound
+		}
+	}
 	if err != nil {
-		if errors.Is(err, internalErrors.ErrIndexNotFound) {
-			SendIndexNotFoundError(c, indexName)
+		if errors.Is(err, errKeyFiltersNotSupported) {
 			return
 		}
-		SendInternalError(c, "get index", err)
+		if errors.Is(err, internalErrors.ErrDocumentNotFound) {
+			SendDocumentNotFoundError(c, documentId, indexName)
+			return
+		}
+		sendDocumentFetchError(c, indexName, "get document", err)
 		return
 	}
 
-	var document model.Document
-	found := false
+	c.JSON(http.StatusOK, document)
+}
 
-	if concreteEngine, ok := api.engine.(*engine.Engine); ok {
-		if instance, err := concreteEngine.GetIndex(indexName); err == nil {
-			if engineInstance, ok := instance.(*engine.IndexInstance); ok {
-				for _, doc := range engineInstance.DocumentStore.Docs {
-					if docID, exists := doc.GetDocumentID(); exists && docID == documentId {
-						document = doc
-						found = true
-						break
-					}
-				}
-			}
+// errKeyFiltersNotSupported is returned once a request is answered because its API key has filters
+// that the engine cannot apply to documents.
+var errKeyFiltersNotSupported = errors.New("API key filters not supported by this engine")
+
+// keyMatchingDocumentIDs returns the given document IDs, or all those of the index when nil, that
+// match the filters of the API key a request was authorized with. Without key filters, the IDs are
+// returned as they are. If the engine cannot apply the filters, it sends the error response and
+// returns errKeyFiltersNotSupported.
+func (api *API) keyMatchingDocumentIDs(c *gin.Context, indexName string, documentIDs []string) ([]string, error) {
+	keyFilters := withKeyFilters(c, nil)
+	if keyFilters == nil {
+		return documentIDs, nil
+	}
+	matcher, ok := api.engine.(services.DocumentMatcher)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "API key filters on documents not supported by this engine")
+		return nil, errKeyFiltersNotSupported
+	}
+	return matcher.MatchingDocumentIDs(indexName, documentIDs, *keyFilters)
+}
+
+// listMatchingDocuments lists a page of the documents that match the filters of the API key a
+// request was authorized with, in documentID order like ListDocuments.
+func (api *API) listMatchingDocuments(c *gin.Context, documentFetcher services.DocumentFetcher, indexName string, page, pageSize int, fields []string) (model.DocumentList, error) {
+	documentIDs, err := api.keyMatchingDocumentIDs(c, indexName, nil)
+	if err != nil {
+		return model.DocumentList{}, err
+	}
+	list := model.DocumentList{
+		Documents: []model.Document{},
+		Total:     len(documentIDs),
+		Page:      page,
+		PageSize:  pageSize,
+		Pages:     (len(documentIDs) + pageSize - 1) / pageSize,
+	}
+	start := min((page-1)*pageSize, len(documentIDs))
+	for _, documentID := range documentIDs[start:min(start+pageSize, len(documentIDs))] {
+		doc, err := documentFetcher.GetDocument(indexName, documentID, fields)
+		if errors.Is(err, internalErrors.ErrDocumentNotFound) {
+			continue // Deleted since it was matched
 		}
+		if err != nil {
+			return model.DocumentList{}, err
+		}
+		list.Documents = append(list.Documents, doc)
+	}
+	return list, nil
+}
+
+// sendDocumentFetchError sends the error of fetching documents: a missing index, invalid fields or
+// an internal error.
+func sendDocumentFetchError(c *gin.Context, indexName, operation string, err error) {
+	var validationErr *internalErrors.ValidationError
+	switch {
+	case errors.Is(err, internalErrors.ErrIndexNotFound):
+		SendIndexNotFoundError(c, indexName)
+	case errors.As(err, &validationErr):
+		SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+	default:
+		SendInternalError(c, operation, err)
 	}
+}
+
+// MultiGetRequest defines the documents fetched by a multi-get request
+type MultiGetRequest struct {
+	IDs    []string `json:"ids" binding:"required"`
+	Fields []string `json:"fields,omitempty"` // Optional: document fields to return, the index's default retrievable fields when empty
+}
 
-	if !found {
-		SendDocumentNotFoundError(c, documentId, indexName)
+// MultiGetDocumentsHandler fetches documents by ID in a single request, e.g. to hydrate the hits of
+// an ids_only search. Documents are returned in the order of the IDs, with the IDs not found listed
+// as missing.
+func (api *API) MultiGetDocumentsHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	documentFetcher, ok := api.engine.(services.DocumentFetcher)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Multi-get not supported by this engine")
 		return
 	}
 
-	c.JSON(http.StatusOK, document)
+	var req MultiGetRequest
+	if result := ValidateJSONBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	result, err := documentFetcher.MultiGetDocuments(indexName, req.IDs, req.Fields)
+	if err == nil {
+		result, err = api.keepKeyMatchingDocuments(c, indexName, req.IDs, result)
+	}
+	if err != nil {
+		if errors.Is(err, errKeyFiltersNotSupported) {
+			return
+		}
+		sendDocumentFetchError(c, indexName, "multi-get documents", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DocumentExportRequest defines the documents streamed by a document export
+type DocumentExportRequest struct {
+	Filters           string   `form:"filters"`            // Optional: JSON filter expression, as in search requests
+	FilterLocale      string   `form:"filter_locale"`      // Optional: locale of numbers and dates written as strings in filter values
+	RetrievableFields []string `form:"retrievable_fields"` // Optional: fields to export besides documentID, repeated
+	After             string   `form:"after"`              // Optional: cursor, the documentID the previous page ended with
+	Limit             int      `form:"limit"`              // Optional: maximum documents exported; all of them when 0
+}
+
+// keepKeyMatchingDocuments lists the documents of a multi-get result that the filters of the API
+// key a request was authorized with exclude as missing, so as not to reveal they exist.
+func (api *API) keepKeyMatchingDocuments(c *gin.Context, indexName string, documentIDs []string, result model.MultiGetResult) (model.MultiGetResult, error) {
+	if withKeyFilters(c, nil) == nil {
+		return result, nil
+	}
+	matching, err := api.keyMatchingDocumentIDs(c, indexName, documentIDs)
+	if err != nil {
+		return model.MultiGetResult{}, err
+	}
+	matched := make(map[string]bool, len(matching))
+	for _, documentID := range matching {
+		matched[documentID] = true
+	}
+
+	filtered := model.MultiGetResult{Documents: []model.Document{}, Missing: []string{}}
+	for _, doc := range result.Documents {
+		if documentID, _ := doc["documentID"].(string); matched[documentID] {
+			filtered.Documents = append(filtered.Documents, doc)
+		}
+	}
+	listed := make(map[string]bool, len(documentIDs))
+	for _, documentID := range documentIDs {
+		if !matched[documentID] && !listed[documentID] {
+			filtered.Missing = append(filtered.Missing, documentID)
+			listed[documentID] = true
+		}
+	}
+	return filtered, nil
+}
+
+// ExportDocumentsHandler streams the documents of an index, or those matching a filter, as
+// newline-delimited JSON in documentID order. With a limit, the cursor of the next page is sent in
+// the X-Next-Cursor trailer, and is also the documentID of the last exported document.
+func (api *API) ExportDocumentsHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	exporter, ok := api.engine.(services.DocumentExporter)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Document exports not supported by this engine")
+		return
+	}
+
+	var req DocumentExportRequest
+	if result := ValidateQueryBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+	query := services.DocumentExportQuery{
+		FilterLocale:      req.FilterLocale,
+		RetrievableFields: req.RetrievableFields,
+		After:             req.After,
+		Limit:             req.Limit,
+	}
+	if req.Filters != "" {
+		var filters services.Filters
+		if err := json.Unmarshal([]byte(req.Filters), &filters); err != nil {
+			SendError(c, ErrorCodeValidationFailed, "Invalid filters: "+err.Error())
+			return
+		}
+		query.Filters = &filters
+	}
+	query.Filters = withKeyFilters(c, query.Filters)
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Trailer", "X-Next-Cursor")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	nextCursor, err := exporter.ExportDocuments(indexName, query, func(doc model.Document) error {
+		return encoder.Encode(doc)
+	})
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Trailer")
+			sendDocumentFetchError(c, indexName, "export documents", err)
+			return
+		}
+		// The status is already sent, so the client only sees a truncated export
+		logging.FromContext(c.Request.Context()).Error("document export failed", "index", indexName, "error", err)
+		_ = c.Error(err)
+		return
+	}
+	c.Writer.Header().Set("X-Next-Cursor", nextCursor)
 }
 
 // DeleteDocumentHandler deletes a specific document by ID
@@ -311,15 +536,27 @@ func (api *API) DeleteDocumentHandler(c *gin.Context) {
 		return
 	}
 
+	idempotencyKey := c.GetHeader(idempotencyKeyHeader)
+	if result := ValidateIdempotencyKey(idempotencyKey); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
 	// Delete document asynchronously
 	var jobID string
 	if concreteEngine, ok := api.engine.(*engine.Engine); ok {
-		jobID, err = concreteEngine.DeleteDocumentAsync(indexName, documentId)
+		jobID, err = startWriteJob(c, concreteEngine, indexName, idempotencyKey, "delete_document", documentId, func() (string, error) {
+			return concreteEngine.DeleteDocumentAsync(indexName, documentId)
+		})
 		if err != nil {
 			if errors.Is(err, internalErrors.ErrDocumentNotFound) {
 				SendDocumentNotFoundError(c, documentId, indexName)
 				return
 			}
+			if errors.Is(err, internalErrors.ErrIdempotencyKeyReused) {
+				SendIdempotencyKeyReusedError(c, idempotencyKey)
+				return
+			}
 			SendJobExecutionError(c, "document deletion", err)
 			return
 		}
@@ -345,3 +582,134 @@ func (api *API) DeleteDocumentHandler(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "Document '" + documentId + "' deleted from index '" + indexName + "'"})
 	}
 }
+
+// RollbackRequest represents the query parameters of a rollback request
+type RollbackRequest struct {
+	Ops int `form:"ops" json:"ops"`
+}
+
+// RollbackHandler handles reversing the last N document upserts and deletes of an index.
+func (api *API) RollbackHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	// Validate index name
+	if result := ValidateIndexName(indexName); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	req := RollbackRequest{Ops: 1}
+	if result := ValidateQueryBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	rollbacker, ok := api.engine.(services.Rollbacker)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Rollback not supported by this engine")
+		return
+	}
+
+	jobID, err := rollbacker.RollbackAsync(indexName, req.Ops)
+	if err != nil {
+		var validationErr *internalErrors.ValidationError
+		switch {
+		case errors.Is(err, internalErrors.ErrIndexNotFound):
+			SendIndexNotFoundError(c, indexName)
+		case errors.As(err, &validationErr):
+			SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+		default:
+			SendJobExecutionError(c, "rollback", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "accepted",
+		"message": fmt.Sprintf("Rollback of %d operation(s) started for index '%s'", req.Ops, indexName),
+		"job_id":  jobID,
+		"ops":     req.Ops,
+	})
+}
+
+// idempotencyKeyHeader carries a client-chosen key that makes retried write requests safe
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// startWriteJob starts a document write job. Without an idempotency key the job is always started;
+// with one, a retry of the same request returns the job of the first request, and the response is
+// marked with the Idempotent-Replayed header.
+func startWriteJob(c *gin.Context, runner services.IdempotentRunner, indexName, idempotencyKey, operation string, payload interface{}, start func() (string, error)) (string, error) {
+	if idempotencyKey == "" {
+		return start()
+	}
+	jobID, replayed, err := runner.RunIdempotent(indexName, idempotencyKey, operation, payload, start)
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	return jobID, err
+}
+
+// bindDocuments reads a document object or an array of documents from the request body,
+// validates them and trims their IDs. It sends the error response and returns false on failure.
+func bindDocuments(c *gin.Context) ([]model.Document, bool) {
+	docs, ok := readDocuments(c)
+	if !ok || !validateDocuments(c, docs) {
+		return nil, false
+	}
+	return docs, true
+}
+
+// readDocuments reads a document object or an array of documents from the request body without
+// validating them. It sends the error response and returns false on failure.
+func readDocuments(c *gin.Context) ([]model.Document, bool) {
+	// Read the raw JSON data first
+	var rawData interface{}
+	if result := ValidateJSONBinding(c, &rawData); result.HasErrors() {
+		SendValidationError(c, result)
+		return nil, false
+	}
+
+	var docs []model.Document
+
+	// Check if the raw data is a slice (array) or a single object
+	if dataSlice, isSlice := rawData.([]interface{}); isSlice {
+		// Handle array of documents
+		docs = make([]model.Document, len(dataSlice))
+		for i, item := range dataSlice {
+			if docMap, isMap := item.(map[string]interface{}); isMap {
+				docs[i] = docMap
+			} else {
+				SendError(c, ErrorCodeInvalidRequest, fmt.Sprintf("Document at index %d is not a valid object", i))
+				return nil, false
+			}
+		}
+	} else if docMap, isMap := rawData.(map[string]interface{}); isMap {
+		// Handle single document
+		docs = []model.Document{docMap}
+	} else {
+		SendError(c, ErrorCodeInvalidRequest, "Invalid request body. Expecting a document object or an array of documents")
+		return nil, false
+	}
+	return docs, true
+}
+
+// validateDocuments validates documents and trims their IDs. It sends the error response and
+// returns false when they are not valid.
+func validateDocuments(c *gin.Context, docs []model.Document) bool {
+	if result := ValidateDocuments(docs); result.HasErrors() {
+		SendValidationError(c, result)
+		return false
+	}
+
+	// Clean up document IDs (trim whitespace)
+	for i := range docs {
+		docMap := docs[i]
+		if docIDVal, exists := docMap["documentID"]; exists {
+			if docIDStr, ok := docIDVal.(string); ok {
+				docMap["documentID"] = strings.TrimSpace(docIDStr)
+			}
+		}
+	}
+
+	return true
+}
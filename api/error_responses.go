@@ -1,33 +1,49 @@
 package api
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
 )
 
-// ErrorCode represents standardized error codes for the API
-type ErrorCode string
+// ErrorCode represents standardized error codes for the API. The HTTP status and retryability of
+// each code are defined by the error taxonomy in internal/errors.
+type ErrorCode = internalErrors.Code
 
 const (
 	// Client Error Codes (4xx)
-	ErrorCodeValidationFailed ErrorCode = "VALIDATION_FAILED"
-	ErrorCodeIndexNotFound    ErrorCode = "INDEX_NOT_FOUND"
-	ErrorCodeDocumentNotFound ErrorCode = "DOCUMENT_NOT_FOUND"
-	ErrorCodeJobNotFound      ErrorCode = "JOB_NOT_FOUND"
-	ErrorCodeIndexExists      ErrorCode = "INDEX_ALREADY_EXISTS"
-	ErrorCodeInvalidRequest   ErrorCode = "INVALID_REQUEST"
-	ErrorCodeInvalidJSON      ErrorCode = "INVALID_JSON"
-	ErrorCodeInvalidQuery     ErrorCode = "INVALID_QUERY"
-	ErrorCodeSameName         ErrorCode = "SAME_NAME_PROVIDED"
+	ErrorCodeValidationFailed     = internalErrors.CodeValidationFailed
+	ErrorCodeIndexNotFound        = internalErrors.CodeIndexNotFound
+	ErrorCodeDocumentNotFound     = internalErrors.CodeDocumentNotFound
+	ErrorCodeJobNotFound          = internalErrors.CodeJobNotFound
+	ErrorCodeBatchNotFound        = internalErrors.CodeBatchNotFound
+	ErrorCodeRuleNotFound         = internalErrors.CodeRuleNotFound
+	ErrorCodeShadowNotFound       = internalErrors.CodeShadowNotFound
+	ErrorCodeAliasNotFound        = internalErrors.CodeAliasNotFound
+	ErrorCodeIndexExists          = internalErrors.CodeIndexExists
+	ErrorCodeInvalidRequest       = internalErrors.CodeInvalidRequest
+	ErrorCodeInvalidJSON          = internalErrors.CodeInvalidJSON
+	ErrorCodeInvalidQuery         = internalErrors.CodeInvalidQuery
+	ErrorCodeSameName             = internalErrors.CodeSameName
+	ErrorCodeIdempotencyKeyReused = internalErrors.CodeIdempotencyKeyReused
+	ErrorCodeReadOnly             = internalErrors.CodeReadOnly
+	ErrorCodeIndexClosed          = internalErrors.CodeIndexClosed
+	ErrorCodeIndexBusy            = internalErrors.CodeIndexBusy
+	ErrorCodeJobFinished          = internalErrors.CodeJobFinished
+	ErrorCodeAPIKeyNotFound       = internalErrors.CodeAPIKeyNotFound
+	ErrorCodeUnauthorized         = internalErrors.CodeUnauthorized
+	ErrorCodeForbidden            = internalErrors.CodeForbidden
+	ErrorCodeRateLimited          = internalErrors.CodeRateLimited
 
 	// Server Error Codes (5xx)
-	ErrorCodeInternalError      ErrorCode = "INTERNAL_ERROR"
-	ErrorCodeIndexingFailed     ErrorCode = "INDEXING_FAILED"
-	ErrorCodeSearchFailed       ErrorCode = "SEARCH_FAILED"
-	ErrorCodePersistenceFailed  ErrorCode = "PERSISTENCE_FAILED"
-	ErrorCodeJobExecutionFailed ErrorCode = "JOB_EXECUTION_FAILED"
+	ErrorCodeInternalError      = internalErrors.CodeInternalError
+	ErrorCodeIndexingFailed     = internalErrors.CodeIndexingFailed
+	ErrorCodeSearchFailed       = internalErrors.CodeSearchFailed
+	ErrorCodePersistenceFailed  = internalErrors.CodePersistenceFailed
+	ErrorCodeJobExecutionFailed = internalErrors.CodeJobExecutionFailed
+	ErrorCodeNotImplemented     = internalErrors.CodeNotImplemented
 )
 
 // ErrorDetail provides additional context for an error
@@ -42,6 +58,7 @@ type APIError struct {
 	Error     string        `json:"error"`
 	Code      ErrorCode     `json:"code"`
 	Message   string        `json:"message"`
+	Retryable bool          `json:"retryable"`
 	Details   []ErrorDetail `json:"details,omitempty"`
 	Timestamp time.Time     `json:"timestamp"`
 	RequestID string        `json:"request_id,omitempty"`
@@ -53,23 +70,24 @@ func APIErrorResponse(code ErrorCode, message string, details ...ErrorDetail) *A
 		Error:     "Request failed",
 		Code:      code,
 		Message:   message,
+		Retryable: internalErrors.KindOf(code).Retryable,
 		Details:   details,
 		Timestamp: time.Now(),
 	}
 }
 
-// SendError sends a standardized error response
-func SendError(c *gin.Context, statusCode int, code ErrorCode, message string, details ...ErrorDetail) {
+// SendError sends a standardized error response with the HTTP status of the error code
+func SendError(c *gin.Context, code ErrorCode, message string, details ...ErrorDetail) {
 	errorResponse := APIErrorResponse(code, message, details...)
 
 	// Add request ID if available
-	if requestID, exists := c.Get("request_id"); exists {
+	if requestID, exists := c.Get(requestIDContextKey); exists {
 		if id, ok := requestID.(string); ok {
 			errorResponse.RequestID = id
 		}
 	}
 
-	c.JSON(statusCode, errorResponse)
+	c.JSON(internalErrors.KindOf(code).HTTPStatus, errorResponse)
 }
 
 // SendStructuredValidationError sends a validation error with structured details using the new error format
@@ -83,12 +101,12 @@ func SendStructuredValidationError(c *gin.Context, result *ValidationResult) {
 		}
 	}
 
-	SendError(c, http.StatusBadRequest, ErrorCodeValidationFailed, "Request validation failed", details...)
+	SendError(c, ErrorCodeValidationFailed, "Request validation failed", details...)
 }
 
 // SendIndexNotFoundError sends a standardized index not found error
 func SendIndexNotFoundError(c *gin.Context, indexName string) {
-	SendError(c, http.StatusNotFound, ErrorCodeIndexNotFound,
+	SendError(c, ErrorCodeIndexNotFound,
 		"Index '"+indexName+"' not found")
 }
 
@@ -98,53 +116,93 @@ func SendDocumentNotFoundError(c *gin.Context, documentID, indexName string) {
 	if indexName != "" {
 		message += " in index '" + indexName + "'"
 	}
-	SendError(c, http.StatusNotFound, ErrorCodeDocumentNotFound, message)
+	SendError(c, ErrorCodeDocumentNotFound, message)
 }
 
 // SendJobNotFoundError sends a standardized job not found error
 func SendJobNotFoundError(c *gin.Context, jobID string) {
-	SendError(c, http.StatusNotFound, ErrorCodeJobNotFound,
+	SendError(c, ErrorCodeJobNotFound,
 		"Job '"+jobID+"' not found")
 }
 
+// SendBatchNotFoundError sends a standardized batch not found error
+func SendBatchNotFoundError(c *gin.Context, batchID string) {
+	SendError(c, ErrorCodeBatchNotFound,
+		"Batch '"+batchID+"' not found or expired")
+}
+
+// SendRuleNotFoundError sends a standardized rule not found error
+func SendRuleNotFoundError(c *gin.Context, ruleID, indexName string) {
+	SendError(c, ErrorCodeRuleNotFound,
+		"Rule '"+ruleID+"' not found in index '"+indexName+"'")
+}
+
+// SendShadowNotFoundError sends a standardized shadow mode not enabled error
+func SendShadowNotFoundError(c *gin.Context, indexName string) {
+	SendError(c, ErrorCodeShadowNotFound,
+		"Shadow mode is not enabled for index '"+indexName+"'")
+}
+
+// SendAPIKeyNotFoundError sends a standardized API key not found error
+func SendAPIKeyNotFoundError(c *gin.Context, keyID string) {
+	SendError(c, ErrorCodeAPIKeyNotFound,
+		"API key '"+keyID+"' not found")
+}
+
+// SendAliasNotFoundError sends a standardized alias not found error
+func SendAliasNotFoundError(c *gin.Context, alias string) {
+	SendError(c, ErrorCodeAliasNotFound,
+		"Alias '"+alias+"' not found")
+}
+
 // SendIndexExistsError sends a standardized index already exists error
 func SendIndexExistsError(c *gin.Context, indexName string) {
-	SendError(c, http.StatusConflict, ErrorCodeIndexExists,
+	SendError(c, ErrorCodeIndexExists,
 		"Index '"+indexName+"' already exists")
 }
 
 // SendSameNameError sends a standardized same name error
 func SendSameNameError(c *gin.Context, name string) {
-	SendError(c, http.StatusBadRequest, ErrorCodeSameName,
+	SendError(c, ErrorCodeSameName,
 		"New name '"+name+"' is the same as the current name")
 }
 
+// SendIdempotencyKeyReusedError sends a standardized error for an idempotency key sent with a different request
+func SendIdempotencyKeyReusedError(c *gin.Context, key string) {
+	SendError(c, ErrorCodeIdempotencyKeyReused,
+		"Idempotency key '"+key+"' was already used for a different request")
+}
+
 // SendInvalidJSONError sends a standardized invalid JSON error
 func SendInvalidJSONError(c *gin.Context, err error) {
-	SendError(c, http.StatusBadRequest, ErrorCodeInvalidJSON,
+	SendError(c, ErrorCodeInvalidJSON,
 		"Invalid JSON in request body: "+err.Error())
 }
 
-// SendInternalError sends a standardized internal server error
+// SendInternalError sends a standardized internal server error, or the error's own code when it
+// wraps a known error condition
 func SendInternalError(c *gin.Context, operation string, err error) {
-	SendError(c, http.StatusInternalServerError, ErrorCodeInternalError,
+	SendError(c, internalErrors.Classify(err, ErrorCodeInternalError),
 		"Internal error during "+operation+": "+err.Error())
 }
 
-// SendIndexingError sends a standardized indexing error
+// SendIndexingError sends a standardized indexing error, or the error's own code when it wraps a
+// known error condition
 func SendIndexingError(c *gin.Context, operation string, err error) {
-	SendError(c, http.StatusInternalServerError, ErrorCodeIndexingFailed,
+	SendError(c, internalErrors.Classify(err, ErrorCodeIndexingFailed),
 		"Indexing operation failed ("+operation+"): "+err.Error())
 }
 
-// SendSearchError sends a standardized search error
+// SendSearchError sends a standardized search error, or the error's own code when it wraps a known
+// error condition, such as an invalid query
 func SendSearchError(c *gin.Context, indexName string, err error) {
-	SendError(c, http.StatusInternalServerError, ErrorCodeSearchFailed,
+	SendError(c, internalErrors.Classify(err, ErrorCodeSearchFailed),
 		"Search failed on index '"+indexName+"': "+err.Error())
 }
 
-// SendJobExecutionError sends a standardized job execution error
+// SendJobExecutionError sends a standardized job execution error, or the error's own code when it
+// wraps a known error condition
 func SendJobExecutionError(c *gin.Context, operation string, err error) {
-	SendError(c, http.StatusInternalServerError, ErrorCodeJobExecutionFailed,
+	SendError(c, internalErrors.Classify(err, ErrorCodeJobExecutionFailed),
 		"Failed to start "+operation+" job: "+err.Error())
 }
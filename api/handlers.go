@@ -1,6 +1,8 @@
 package api
 
 import (
+	"sync"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/gcbaptista/go-search-engine/internal/analytics"
@@ -9,23 +11,64 @@ import (
 
 // API holds dependencies for API handlers, primarily the search engine manager.
 type API struct {
-	engine    services.IndexManager
-	analytics *analytics.Service
+	engine       services.IndexManager
+	analytics    *analytics.Service
+	exportOwners sync.Map // Search export job ID -> ID of the API key with filters that started it
 }
 
 // NewAPI creates a new API handler structure.
 func NewAPI(engine services.IndexManager) *API {
-	return &API{
+	// Analytics are only kept in memory for engines without a data directory
+	dataDir := ""
+	if directory, ok := engine.(services.DataDirectory); ok {
+		dataDir = directory.DataDir()
+	}
+	api := &API{
 		engine:    engine,
-		analytics: analytics.NewService(engine),
+		analytics: analytics.NewService(engine, dataDir),
 	}
+	// Indexes with cache warming re-execute the popular queries tracked by the analytics
+	if warmer, ok := engine.(services.CacheWarmer); ok {
+		warmer.SetPopularQuerySource(api.analytics)
+	}
+	return api
+}
+
+// indexDataFreeRoutes are the index routes that do not need the data of the index, so they leave a
+// closed index closed
+var indexDataFreeRoutes = []string{
+	"/indexes/:indexName", // Settings, and deleting the index
+	"/indexes/:indexName/settings/_diff/:otherIndex",
+	"/indexes/:indexName/jobs",
+	"/indexes/:indexName/_restore",
+	"/indexes/:indexName/_close",
+	"/indexes/:indexName/_open",
+	"/indexes/:indexName/popular_searches",
+	"/indexes/:indexName/top_queries",
+	"/indexes/:indexName/zero_result_queries",
+	"/indexes/:indexName/search_latency",
+}
+
+// RouteOptions configure the middleware of the API routes.
+type RouteOptions struct {
+	RateLimits RateLimits // Budgets of each client; zero rates disable rate limiting
 }
 
-// SetupRoutes defines all the API routes for the search engine.
+// SetupRoutes defines all the API routes for the search engine, without rate limiting.
 func SetupRoutes(router *gin.Engine, engine services.IndexManager) {
+	SetupRoutesWithOptions(router, engine, RouteOptions{})
+}
+
+// SetupRoutesWithOptions defines all the API routes for the search engine with the given options.
+func SetupRoutesWithOptions(router *gin.Engine, engine services.IndexManager, options RouteOptions) {
 	// Add middleware
+	router.Use(RequestIDMiddleware())     // X-Request-ID, carried into logs and error responses
+	router.Use(TracingMiddleware())       // Span per request, continuing the caller's trace
+	router.Use(RequestLoggerMiddleware()) // One structured log entry per request
 	router.Use(CORSMiddleware())
-	router.Use(RequestSizeLimitMiddleware(500 << 20)) // 500 MB limit
+	router.Use(RequestSizeLimitMiddleware(500<<20, bulkIngestRoute)) // 500 MB limit, except for streamed bulk ingests
+	router.Use(AuthMiddleware(engine))                               // API keys, once the engine has an admin key
+	router.Use(RateLimitMiddleware(options.RateLimits))              // Per API key, or per IP without one
 
 	apiHandler := NewAPI(engine)
 
@@ -35,37 +78,121 @@ func SetupRoutes(router *gin.Engine, engine services.IndexManager) {
 	// Analytics route
 	router.GET("/analytics", apiHandler.GetAnalyticsHandler)
 
+	// API key management routes, for the admin key only
+	keyRoutes := router.Group("/keys")
+	{
+		keyRoutes.GET("", apiHandler.ListAPIKeysHandler)            // List API keys
+		keyRoutes.POST("", apiHandler.CreateAPIKeyHandler)          // Create an API key
+		keyRoutes.GET("/:keyId", apiHandler.GetAPIKeyHandler)       // Get an API key
+		keyRoutes.DELETE("/:keyId", apiHandler.DeleteAPIKeyHandler) // Revoke an API key
+	}
+
+	// Benchmark route: indexes and searches a generated corpus, apart from the engine's indexes
+	router.POST("/_benchmark", apiHandler.BenchmarkHandler)
+
 	// Job management routes
 	jobRoutes := router.Group("/jobs")
 	{
+		jobRoutes.GET("", apiHandler.ListAllJobsHandler)           // List jobs of all indexes
 		jobRoutes.GET("/:jobId", apiHandler.GetJobHandler)         // Get job status by ID
+		jobRoutes.DELETE("/:jobId", apiHandler.CancelJobHandler)   // Cancel a job
 		jobRoutes.GET("/metrics", apiHandler.GetJobMetricsHandler) // Get job performance metrics
+		jobRoutes.POST("/_cleanup", apiHandler.CleanupJobsHandler) // Remove finished jobs
 	}
 
-	// Index management routes
-	indexRoutes := router.Group("/indexes")
+	// Index alias routes
+	aliasRoutes := router.Group("/aliases")
 	{
-		indexRoutes.POST("", apiHandler.CreateIndexHandler)                              // Create a new index
-		indexRoutes.GET("", apiHandler.ListIndexesHandler)                               // List all indexes
-		indexRoutes.GET("/:indexName", apiHandler.GetIndexHandler)                       // Get specific index details (e.g., settings)
-		indexRoutes.DELETE("/:indexName", apiHandler.DeleteIndexHandler)                 // Delete an index
-		indexRoutes.PATCH("/:indexName/settings", apiHandler.UpdateIndexSettingsHandler) // Update index settings
-		indexRoutes.POST("/:indexName/rename", apiHandler.RenameIndexHandler)            // Rename an index
-		indexRoutes.GET("/:indexName/stats", apiHandler.GetIndexStatsHandler)            // Get index statistics
-		indexRoutes.GET("/:indexName/jobs", apiHandler.ListJobsHandler)                  // List jobs for an index
+		aliasRoutes.GET("", apiHandler.ListAliasesHandler)           // List aliases
+		aliasRoutes.POST("/_swap", apiHandler.SwapAliasesHandler)    // Exchange the indexes of two aliases
+		aliasRoutes.GET("/:alias", apiHandler.GetAliasHandler)       // Get the index of an alias
+		aliasRoutes.PUT("/:alias", apiHandler.PutAliasHandler)       // Point an alias at an index
+		aliasRoutes.DELETE("/:alias", apiHandler.DeleteAliasHandler) // Delete an alias
+	}
+
+	// Index management routes; closed indexes are opened by the routes that need their data
+	indexRoutes := router.Group("/indexes", RenameAliasMiddleware(engine), IndexAutoOpenMiddleware(engine, indexDataFreeRoutes...))
+	aliased := IndexAliasMiddleware(engine) // Document and search routes also accept an alias
+	{
+		indexRoutes.POST("", apiHandler.CreateIndexHandler)                                       // Create a new index
+		indexRoutes.GET("", apiHandler.ListIndexesHandler)                                        // List all indexes
+		indexRoutes.DELETE("", apiHandler.DeleteIndexesHandler)                                   // Delete the indexes matching a pattern, as one job
+		indexRoutes.GET("/:indexName", apiHandler.GetIndexHandler)                                // Get specific index details (e.g., settings)
+		indexRoutes.DELETE("/:indexName", apiHandler.DeleteIndexHandler)                          // Delete an index
+		indexRoutes.PATCH("/:indexName/settings", apiHandler.UpdateIndexSettingsHandler)          // Update index settings
+		indexRoutes.GET("/:indexName/settings/_diff/:otherIndex", apiHandler.SettingsDiffHandler) // Compare settings with another index
+		indexRoutes.POST("/:indexName/rename", apiHandler.RenameIndexHandler)                     // Rename an index
+		indexRoutes.GET("/:indexName/stats", apiHandler.GetIndexStatsHandler)                     // Get index statistics
+		indexRoutes.GET("/:indexName/jobs", apiHandler.ListJobsHandler)                           // List jobs for an index
+		indexRoutes.POST("/:indexName/_rollback", apiHandler.RollbackHandler)                     // Reverse the last N document operations
+		indexRoutes.PUT("/:indexName/_shadow", apiHandler.EnableShadowHandler)                    // Mirror a sample of searches to a candidate index
+		indexRoutes.GET("/:indexName/_shadow", apiHandler.GetShadowStatsHandler)                  // Compare the index with its shadow candidate
+		indexRoutes.DELETE("/:indexName/_shadow", apiHandler.DisableShadowHandler)                // Stop shadow mode
+		indexRoutes.POST("/:indexName/_analyze", apiHandler.AnalyzeHandler)                       // Preview index-side and query-side tokens
+		indexRoutes.POST("/:indexName/_spellcheck", apiHandler.SpellcheckHandler)                 // Suggest query corrections without searching
+		indexRoutes.GET("/:indexName/_terms", apiHandler.TermsHandler)                            // List indexed terms by prefix with document counts
+		indexRoutes.GET("/:indexName/_suggest", aliased, apiHandler.SuggestHandler)               // Complete a typed prefix for typeahead
+		indexRoutes.POST("/:indexName/_verify", apiHandler.VerifyIndexHandler)                    // Check, and optionally repair, index consistency
+		indexRoutes.GET("/:indexName/_seq", aliased, apiHandler.GetIndexSeqHandler)               // Sequence number of the latest document write
+		indexRoutes.GET("/:indexName/_snapshot", apiHandler.SnapshotIndexHandler)                 // Download an archive of the index
+		indexRoutes.POST("/:indexName/_restore", apiHandler.RestoreIndexHandler)                  // Create the index from a snapshot archive
+		indexRoutes.POST("/:indexName/_close", apiHandler.CloseIndexHandler)                      // Unload the index from memory, keeping its files
+		indexRoutes.POST("/:indexName/_open", apiHandler.OpenIndexHandler)                        // Load a closed index back into memory
+
+		// Analytics presets per index
+		indexRoutes.GET("/:indexName/popular_searches", apiHandler.GetPopularSearchesHandler)      // Most frequent successful queries
+		indexRoutes.GET("/:indexName/top_queries", apiHandler.GetTopQueriesHandler)                // Most frequent queries
+		indexRoutes.GET("/:indexName/zero_result_queries", apiHandler.GetZeroResultQueriesHandler) // Queries most often without results
+		indexRoutes.GET("/:indexName/search_latency", apiHandler.GetSearchLatencyHandler)          // Response time percentiles
 
 		// Document management routes per index
-		docRoutes := indexRoutes.Group("/:indexName/documents")
+		docRoutes := indexRoutes.Group("/:indexName/documents", aliased)
 		{
 			docRoutes.PUT("", apiHandler.AddDocumentsHandler)                  // Add/Update documents
+			docRoutes.PUT("/_bulk", apiHandler.BulkIngestHandler)              // Stream newline-delimited documents
 			docRoutes.GET("", apiHandler.GetDocumentsHandler)                  // List documents with pagination
+			docRoutes.POST("/_mget", apiHandler.MultiGetDocumentsHandler)      // Get documents by ID
+			docRoutes.GET("/_export", apiHandler.ExportDocumentsHandler)       // Stream documents as newline-delimited JSON
 			docRoutes.DELETE("", apiHandler.DeleteAllDocumentsHandler)         // Delete all documents
 			docRoutes.GET("/:documentId", apiHandler.GetDocumentHandler)       // Get specific document
 			docRoutes.DELETE("/:documentId", apiHandler.DeleteDocumentHandler) // Delete specific document
 		}
 
+		// Write batch routes per index: stage changes, then commit them atomically
+		batchRoutes := indexRoutes.Group("/:indexName/_batch", aliased)
+		{
+			batchRoutes.POST("", apiHandler.OpenBatchHandler)                                            // Open a batch
+			batchRoutes.GET("/:batchId", apiHandler.GetBatchHandler)                                     // Get batch state
+			batchRoutes.DELETE("/:batchId", apiHandler.AbortBatchHandler)                                // Abort a batch
+			batchRoutes.PUT("/:batchId/documents", apiHandler.AddBatchDocumentsHandler)                  // Stage documents
+			batchRoutes.DELETE("/:batchId/documents/:documentId", apiHandler.DeleteBatchDocumentHandler) // Stage a deletion
+			batchRoutes.POST("/:batchId/_commit", apiHandler.CommitBatchHandler)                         // Commit a batch
+		}
+
+		// Merchandising rule routes per index
+		ruleRoutes := indexRoutes.Group("/:indexName/rules")
+		{
+			ruleRoutes.GET("", apiHandler.ListRulesHandler)             // List rules
+			ruleRoutes.POST("", apiHandler.CreateRuleHandler)           // Create a rule
+			ruleRoutes.POST("/_bulk", apiHandler.ImportRulesHandler)    // Create and replace many rules at once
+			ruleRoutes.GET("/_export", apiHandler.ExportRulesHandler)   // Download every rule
+			ruleRoutes.GET("/:ruleId", apiHandler.GetRuleHandler)       // Get a rule
+			ruleRoutes.PUT("/:ruleId", apiHandler.UpdateRuleHandler)    // Replace a rule
+			ruleRoutes.DELETE("/:ruleId", apiHandler.DeleteRuleHandler) // Delete a rule
+		}
+
+		// Relevance test routes per index
+		relevanceRoutes := indexRoutes.Group("/:indexName/relevance_tests")
+		{
+			relevanceRoutes.GET("", apiHandler.GetRelevanceTestsHandler)       // List relevance tests
+			relevanceRoutes.PUT("", apiHandler.SetRelevanceTestsHandler)       // Replace relevance tests
+			relevanceRoutes.POST("/_run", apiHandler.RunRelevanceTestsHandler) // Run relevance tests
+		}
+
 		// Search routes per index
-		indexRoutes.POST("/:indexName/_search", apiHandler.SearchHandler)
-		indexRoutes.POST("/:indexName/_multi_search", apiHandler.MultiSearchHandler)
+		indexRoutes.POST("/:indexName/_search", aliased, apiHandler.SearchHandler)
+		indexRoutes.POST("/:indexName/_multi_search", aliased, apiHandler.MultiSearchHandler)
+		indexRoutes.POST("/:indexName/_search/export", aliased, apiHandler.SearchExportHandler)               // Export every hit of a search as a job
+		indexRoutes.GET("/:indexName/_search/export/:jobId", aliased, apiHandler.DownloadSearchExportHandler) // Download a completed export
 	}
 }
@@ -4,17 +4,29 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/gcbaptista/go-search-engine/benchmarks"
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/internal/engine"
 	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // Global registry to track test directories for cleanup
@@ -166,6 +178,48 @@ func TestAddDocumentsHandler(t *testing.T) {
 	}
 }
 
+func TestAddDocumentsGeneratedIDs(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_docs_generated_ids", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	addDocuments := func(docs []model.Document) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(docs)
+		req, _ := http.NewRequest("PUT", "/indexes/test_docs_generated_ids/documents", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	docs := []model.Document{{"documentID": "doc1", "title": "First"}, {"title": "Second"}}
+
+	if w := addDocuments(docs); w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected documents without an ID to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+
+	settings, _ := eng.GetIndexSettings("test_docs_generated_ids")
+	settings.GenerateDocumentIDs = true
+	if err := eng.UpdateIndexSettings("test_docs_generated_ids", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	w := addDocuments(docs)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	var response struct {
+		GeneratedIDs map[string]string `json:"generated_ids"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.GeneratedIDs) != 1 || response.GeneratedIDs["1"] == "" {
+		t.Errorf("Expected an ID generated for the document at position 1, got %v", response.GeneratedIDs)
+	}
+}
+
 func TestSearchHandler(t *testing.T) {
 	eng := setupTestEngine()
 	router := setupTestRouter(eng)
@@ -236,7 +290,7 @@ func TestSearchHandler(t *testing.T) {
 				PageSize:                 10,
 				RestrictSearchableFields: []string{"Title", "invalid_field"},
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name: "search restricted to single field",
@@ -277,6 +331,112 @@ func TestListIndexesHandler(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
 	}
+
+	for _, settings := range []config.IndexSettings{
+		{Name: "test_list_prod", SearchableFields: []string{"title"}, Metadata: &config.IndexMetadata{Owner: "search-team", Tags: []string{"prod", "movies"}}},
+		{Name: "test_list_staging", SearchableFields: []string{"title"}, Metadata: &config.IndexMetadata{Tags: []string{"staging", "movies"}}},
+		{Name: "test_list_untagged", SearchableFields: []string{"title"}},
+	} {
+		if err := eng.CreateIndex(settings); err != nil {
+			t.Fatalf("Failed to create index: %v", err)
+		}
+	}
+
+	for query, expected := range map[string][]string{
+		"?tag=prod":            {"test_list_prod"},
+		"?tag=movies":          {"test_list_prod", "test_list_staging"},
+		"?tag=movies&tag=prod": {"test_list_prod"},
+		"?tag=archive":         {},
+	} {
+		req, _ := http.NewRequest("GET", "/indexes"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Indexes  []string                         `json:"indexes"`
+			Count    int                              `json:"count"`
+			Metadata map[string]*config.IndexMetadata `json:"metadata"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		slices.Sort(response.Indexes)
+		if !slices.Equal(response.Indexes, expected) || response.Count != len(expected) {
+			t.Errorf("Expected %v for %s, got %v (count %d)", expected, query, response.Indexes, response.Count)
+		}
+		if len(expected) > 0 && response.Metadata[expected[0]] == nil {
+			t.Errorf("Expected the metadata of %s to be listed for %s", expected[0], query)
+		}
+	}
+}
+
+func TestListIndexesHandlerPagination(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	for name, documents := range map[string]int{"test_page_alpha": 3, "test_page_beta": 1, "test_page_gamma": 2, "other_index": 5} {
+		if err := eng.CreateIndex(config.IndexSettings{Name: name, SearchableFields: []string{"title"}}); err != nil {
+			t.Fatalf("Failed to create index: %v", err)
+		}
+		indexAccessor, err := eng.GetIndex(name)
+		if err != nil {
+			t.Fatalf("Failed to get index: %v", err)
+		}
+		var docs []model.Document
+		for i := 0; i < documents; i++ {
+			docs = append(docs, model.Document{"documentID": fmt.Sprintf("doc%d", i), "title": "Title"})
+		}
+		if err := indexAccessor.AddDocuments(docs); err != nil {
+			t.Fatalf("Failed to add documents: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query          string
+		expected       []string
+		expectedTotal  int
+		expectedCounts map[string]int
+	}{
+		{"?q=PAGE", []string{"test_page_alpha", "test_page_beta", "test_page_gamma"}, 3, nil},
+		{"?q=page&sort=document_count", []string{"test_page_alpha", "test_page_gamma", "test_page_beta"}, 3, map[string]int{"test_page_alpha": 3, "test_page_gamma": 2, "test_page_beta": 1}},
+		{"?q=page&sort=name&order=desc&page=1&page_size=2", []string{"test_page_gamma", "test_page_beta"}, 3, nil},
+		{"?q=page&sort=document_count&order=asc&page=2&page_size=2", []string{"test_page_alpha"}, 3, nil},
+		{"?page=3&page_size=2", []string{}, 4, nil},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "/indexes"+tt.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d for %s, got %d: %s", http.StatusOK, tt.query, w.Code, w.Body.String())
+		}
+		var response struct {
+			Indexes        []string       `json:"indexes"`
+			Total          int            `json:"total"`
+			DocumentCounts map[string]int `json:"document_counts"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if !slices.Equal(response.Indexes, tt.expected) || response.Total != tt.expectedTotal {
+			t.Errorf("Expected %v (total %d) for %s, got %v (total %d)", tt.expected, tt.expectedTotal, tt.query, response.Indexes, response.Total)
+		}
+		for name, count := range tt.expectedCounts {
+			if response.DocumentCounts[name] != count {
+				t.Errorf("Expected %d documents in %s for %s, got %d", count, name, tt.query, response.DocumentCounts[name])
+			}
+		}
+	}
+
+	for _, query := range []string{"?sort=size", "?order=up", "?page=-1"} {
+		req, _ := http.NewRequest("GET", "/indexes"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
 }
 
 func TestGetIndexHandler(t *testing.T) {
@@ -367,6 +527,42 @@ func TestDeleteIndexHandler(t *testing.T) {
 	}
 }
 
+func TestDeleteIndexesHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	for _, name := range []string{"tmp_delete_a", "tmp_delete_b"} {
+		if err := eng.CreateIndex(config.IndexSettings{Name: name, SearchableFields: []string{"title"}}); err != nil {
+			t.Fatalf("Failed to create index: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{name: "missing pattern", query: "?confirm=true", expectedStatus: http.StatusBadRequest},
+		{name: "invalid pattern", query: "?pattern=tmp_[&confirm=true", expectedStatus: http.StatusBadRequest},
+		{name: "missing confirmation", query: "?pattern=tmp_delete_*", expectedStatus: http.StatusBadRequest},
+		{name: "confirmed deletion", query: "?pattern=tmp_delete_*&confirm=true", expectedStatus: http.StatusAccepted},
+		{name: "no matching index", query: "?pattern=tmp_missing_*&confirm=true", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("DELETE", "/indexes"+tt.query, nil)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestUpdateIndexSettingsHandler(t *testing.T) {
 	eng := setupTestEngine()
 	router := setupTestRouter(eng)
@@ -479,7 +675,253 @@ func TestUpdateIndexSettingsHandler(t *testing.T) {
 			expectedStatus:    http.StatusAccepted,
 			expectedReindexed: &[]bool{true}[0],
 		},
-
+		{
+			name: "update zero-result fallbacks (no reindexing)",
+			requestBody: map[string]interface{}{
+				"zero_result_fallbacks": []string{"relax_typos", "browse"},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "invalid zero-result fallback",
+			requestBody: map[string]interface{}{
+				"zero_result_fallbacks": []string{"guess"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "enable read replica (no reindexing)",
+			requestBody: map[string]interface{}{
+				"read_replica": map[string]interface{}{"refresh_interval_ms": 500},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "invalid read replica refresh interval",
+			requestBody: map[string]interface{}{
+				"read_replica": map[string]interface{}{"refresh_interval_ms": -1},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "enable document compression (no reindexing)",
+			requestBody: map[string]interface{}{
+				"document_compression": map[string]interface{}{"min_document_bytes": 64, "cache_size": 10},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "unsupported document compression algorithm",
+			requestBody: map[string]interface{}{
+				"document_compression": map[string]interface{}{"algorithm": "lz4"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "enable segment storage (no reindexing)",
+			requestBody: map[string]interface{}{
+				"segment_storage": map[string]interface{}{"max_segments": 4},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "negative max segments",
+			requestBody: map[string]interface{}{
+				"segment_storage": map[string]interface{}{"max_segments": -1},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "enable query sanitizer (no reindexing)",
+			requestBody: map[string]interface{}{
+				"query_sanitizer": map[string]interface{}{"max_query_length": 128, "emoji_tokens": map[string]interface{}{"🍕": "pizza"}},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "invalid query sanitizer length",
+			requestBody: map[string]interface{}{
+				"query_sanitizer": map[string]interface{}{"max_query_length": -5},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "set metadata (no reindexing)",
+			requestBody: map[string]interface{}{
+				"metadata": map[string]interface{}{"description": "Movie catalog", "owner": "search-team", "tags": []string{"prod"}},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "duplicate metadata tags",
+			requestBody: map[string]interface{}{
+				"metadata": map[string]interface{}{"tags": []string{"prod", "prod"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "set field weights (no reindexing)",
+			requestBody: map[string]interface{}{
+				"field_weights": map[string]interface{}{"title": 3, "content": 0.5},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "field weight of a field that is not searchable",
+			requestBody: map[string]interface{}{
+				"field_weights": map[string]interface{}{"unknown_field": 2},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "set synonyms and spell correction (no reindexing)",
+			requestBody: map[string]interface{}{
+				"synonyms":         map[string]interface{}{"tv": "television"},
+				"spell_correction": map[string]interface{}{"max_distance": 1},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "spell correction distance above 2",
+			requestBody: map[string]interface{}{
+				"spell_correction": map[string]interface{}{"max_distance": 3},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "set field formats (no reindexing)",
+			requestBody: map[string]interface{}{
+				"field_formats": map[string]interface{}{"year": map[string]interface{}{"locale": "de"}},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "field format of a field that is not filterable",
+			requestBody: map[string]interface{}{
+				"field_formats": map[string]interface{}{"title": map[string]interface{}{"date_format": "DD/MM/YYYY"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "set scoring algorithm (no reindexing)",
+			requestBody: map[string]interface{}{
+				"scoring_algorithm": "bm25",
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "invalid scoring algorithm",
+			requestBody: map[string]interface{}{
+				"scoring_algorithm": "pagerank",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "set page sizes (no reindexing)",
+			requestBody: map[string]interface{}{
+				"default_page_size": 20,
+				"max_page_size":     200,
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "default page size above the maximum",
+			requestBody: map[string]interface{}{
+				"default_page_size": 50,
+				"max_page_size":     25,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "set typo budget (no reindexing)",
+			requestBody: map[string]interface{}{
+				"typo_budget": map[string]interface{}{"two_typo_fields": 1, "one_typo_fields": 1},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "invalid typo budget",
+			requestBody: map[string]interface{}{
+				"typo_budget": map[string]interface{}{"one_typo_fields": -1},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "enable cache warming (no reindexing)",
+			requestBody: map[string]interface{}{
+				"cache_warming": map[string]interface{}{"top_queries": 10, "interval_ms": 30000, "max_qps": 2},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{false}[0],
+		},
+		{
+			name: "invalid cache warming rate",
+			requestBody: map[string]interface{}{
+				"cache_warming": map[string]interface{}{"max_qps": -1},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
+		{
+			name: "enable stop words (requires reindexing)",
+			requestBody: map[string]interface{}{
+				"stop_words": map[string]interface{}{"words": []string{"the", "a"}, "keep_in_phrases": true},
+			},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{true}[0],
+		},
+		{
+			name:              "enable compound words (requires reindexing)",
+			requestBody:       map[string]interface{}{"compound_words": true},
+			expectedStatus:    http.StatusAccepted,
+			expectedReindexed: &[]bool{true}[0],
+		},
+		{
+			name: "invalid stop words",
+			requestBody: map[string]interface{}{
+				"stop_words": map[string]interface{}{"words": []string{"the", " "}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+			errorContains:  "validation failed",
+		},
 		{
 			name:           "empty request body",
 			requestBody:    map[string]interface{}{},
@@ -986,7 +1428,7 @@ func TestMultiSearchHandler(t *testing.T) {
 					},
 				},
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
 			validateFunc: func(t *testing.T, response map[string]interface{}) {
 				if errorMsg, exists := response["error"]; !exists {
 					t.Error("Expected error message for invalid searchable field")
@@ -997,6 +1439,9 @@ func TestMultiSearchHandler(t *testing.T) {
 					if !bytes.Contains([]byte(fullErrorStr), []byte("not configured as a searchable field")) {
 						t.Errorf("Expected error about invalid searchable field, got: %v", fullErrorStr)
 					}
+					if response["code"] != string(ErrorCodeInvalidQuery) || response["retryable"] != false {
+						t.Errorf("Expected non-retryable %s error, got code %v, retryable %v", ErrorCodeInvalidQuery, response["code"], response["retryable"])
+					}
 				}
 			},
 		},
@@ -1070,9 +1515,1266 @@ func TestMultiSearchHandler_IndexNotFound(t *testing.T) {
 	}
 }
 
-func TestMain(m *testing.M) {
-	// Setup code before tests
-	code := m.Run()
+func TestBatchHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	indexSettings := config.IndexSettings{
+		Name:             "test_batch",
+		SearchableFields: []string{"title"},
+	}
+	if err := eng.CreateIndex(indexSettings); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reqBody *bytes.Buffer
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reqBody = bytes.NewBuffer(encoded)
+		} else {
+			reqBody = bytes.NewBuffer(nil)
+		}
+		req, _ := http.NewRequest(method, path, reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("POST", "/indexes/test_batch/_batch", nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var batch model.BatchInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &batch); err != nil {
+		t.Fatalf("Failed to unmarshal batch: %v", err)
+	}
+	batchPath := "/indexes/test_batch/_batch/" + batch.ID
+
+	w = doRequest("PUT", batchPath+"/documents", []model.Document{
+		{"documentID": "doc1", "title": "First"},
+		{"documentID": "doc2", "title": "Second"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w = doRequest("DELETE", batchPath+"/documents/unknown", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown document, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = doRequest("GET", batchPath, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &batch); err != nil {
+		t.Fatalf("Failed to unmarshal batch: %v", err)
+	}
+	if batch.StagedDocuments != 2 {
+		t.Errorf("Expected 2 staged documents, got %d", batch.StagedDocuments)
+	}
+
+	w = doRequest("POST", batchPath+"/_commit", nil)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+
+	// The batch is closed once committed
+	w = doRequest("POST", batchPath+"/_commit", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a committed batch, got %d", http.StatusNotFound, w.Code)
+	}
+	w = doRequest("GET", "/indexes/nonexistent_index/_batch/"+batch.ID, nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a nonexistent index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestRollbackHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	indexSettings := config.IndexSettings{
+		Name:             "test_rollback",
+		SearchableFields: []string{"title"},
+	}
+	if err := eng.CreateIndex(indexSettings); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, err := eng.GetIndex("test_rollback")
+	if err != nil {
+		t.Fatalf("Failed to get index: %v", err)
+	}
+	if err := indexAccessor.AddDocuments([]model.Document{{"documentID": "doc1", "title": "First"}}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"default single operation", "/indexes/test_rollback/_rollback", http.StatusAccepted},
+		{"more operations than logged", "/indexes/test_rollback/_rollback?ops=5", http.StatusBadRequest},
+		{"non-positive ops", "/indexes/test_rollback/_rollback?ops=0", http.StatusBadRequest},
+		{"invalid ops", "/indexes/test_rollback/_rollback?ops=abc", http.StatusBadRequest},
+		{"nonexistent index", "/indexes/nonexistent_index/_rollback", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Response: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotentDocumentWrites(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_idempotent", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	send := func(method, path, key, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	jobID := func(w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		id, _ := response["job_id"].(string)
+		return id
+	}
+
+	body := `[{"documentID": "1", "title": "The Matrix"}]`
+	first := send("PUT", "/indexes/test_idempotent/documents", "import-1", body)
+	if first.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusAccepted, first.Code, first.Body.String())
+	}
+	retry := send("PUT", "/indexes/test_idempotent/documents", "import-1", body)
+	if retry.Code != http.StatusAccepted || jobID(retry) != jobID(first) {
+		t.Errorf("Expected the retry to return job %s, got %d: %s", jobID(first), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected only the retry to be marked as replayed")
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		key            string
+		body           string
+		expectedStatus int
+	}{
+		{"key reused for other documents", "PUT", "/indexes/test_idempotent/documents", "import-1", `[{"documentID": "2", "title": "Other"}]`, http.StatusConflict},
+		{"key reused for another operation", "DELETE", "/indexes/test_idempotent/documents/1", "import-1", "", http.StatusConflict},
+		{"key with surrounding whitespace", "PUT", "/indexes/test_idempotent/documents", " import-2", body, http.StatusBadRequest},
+		{"new key", "DELETE", "/indexes/test_idempotent/documents/1", "delete-1", "", http.StatusAccepted},
+		{"nonexistent index", "DELETE", "/indexes/nonexistent_index/documents", "delete-2", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := send(tt.method, tt.path, tt.key, tt.body); w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Response: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRuleHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	indexSettings := config.IndexSettings{
+		Name:             "test_rules",
+		SearchableFields: []string{"title"},
+	}
+	if err := eng.CreateIndex(indexSettings); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		reqBody := bytes.NewBuffer(nil)
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reqBody = bytes.NewBuffer(encoded)
+		}
+		req, _ := http.NewRequest(method, path, reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	rule := model.Rule{
+		Condition: model.RuleCondition{Query: "shoes"},
+		Actions:   []model.RuleAction{{Type: model.RuleActionPin, DocumentIDs: []string{"doc1"}}},
+	}
+
+	w := doRequest("POST", "/indexes/test_rules/rules", model.Rule{})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid rule, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("POST", "/indexes/missing/rules", rule)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for missing index, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = doRequest("POST", "/indexes/test_rules/rules", rule)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created model.Rule
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal rule: %v", err)
+	}
+	rulePath := "/indexes/test_rules/rules/" + created.ID
+
+	w = doRequest("GET", "/indexes/test_rules/rules", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), created.ID) {
+		t.Errorf("Expected rule list with %s, got %d: %s", created.ID, w.Code, w.Body.String())
+	}
+
+	rule.Description = "Spring promotion"
+	w = doRequest("PUT", rulePath, rule)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Spring promotion") {
+		t.Errorf("Expected updated rule, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest("DELETE", rulePath, nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	w = doRequest("GET", rulePath, nil)
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), string(ErrorCodeRuleNotFound)) {
+		t.Errorf("Expected rule not found, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Exports import back into another index, keeping the IDs of the rules
+	second := rule
+	second.Condition.Query = "boots"
+	w = doRequest("POST", "/indexes/test_rules/rules/_bulk", RuleImportRequest{Rules: []model.Rule{rule, second}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"created":2`) {
+		t.Fatalf("Expected 2 imported rules, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest("GET", "/indexes/test_rules/rules?page=2&page_size=1", nil)
+	var page model.RuleList
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Failed to unmarshal rule list: %v", err)
+	}
+	if w.Code != http.StatusOK || page.Total != 2 || page.Pages != 2 || len(page.Rules) != 1 || page.Rules[0].Condition.Query != "boots" {
+		t.Errorf("Expected the second of 2 rules, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest("GET", "/indexes/test_rules/rules?sort=priority", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown sort, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("GET", "/indexes/test_rules/rules/_export", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "test_rules-rules.json") {
+		t.Fatalf("Expected rules export, got %d: %s", w.Code, w.Body.String())
+	}
+	var exported RuleImportRequest
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil || len(exported.Rules) != 2 {
+		t.Fatalf("Failed to read export (%v): %s", err, w.Body.String())
+	}
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_rules_copy", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	for range 2 {
+		w = doRequest("POST", "/indexes/test_rules_copy/rules/_bulk", exported)
+	}
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"updated":2`) || !strings.Contains(w.Body.String(), `"total":2`) {
+		t.Errorf("Expected a second import to update the same 2 rules, got %d: %s", w.Code, w.Body.String())
+	}
+	if w = doRequest("GET", "/indexes/test_rules_copy/rules/"+exported.Rules[0].ID, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected imported rule to keep its ID, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRelevanceTestHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	indexSettings := config.IndexSettings{
+		Name:             "test_relevance",
+		SearchableFields: []string{"title"},
+	}
+	if err := eng.CreateIndex(indexSettings); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, _ := eng.GetIndex("test_relevance")
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "doc1", "title": "Running Shoes"},
+		{"documentID": "doc2", "title": "Running Socks"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		reqBody := bytes.NewBuffer(nil)
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reqBody = bytes.NewBuffer(encoded)
+		}
+		req, _ := http.NewRequest(method, path, reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("PUT", "/indexes/test_relevance/relevance_tests", SetRelevanceTestsRequest{
+		Tests: []model.RelevanceTest{{Query: "shoes"}},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a test without document, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("POST", "/indexes/missing/relevance_tests/_run", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for missing index, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = doRequest("PUT", "/indexes/test_relevance/relevance_tests", SetRelevanceTestsRequest{
+		Tests: []model.RelevanceTest{{Query: "running shoes", DocumentID: "doc1", TopN: 1}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w = doRequest("GET", "/indexes/test_relevance/relevance_tests", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"running shoes"`) {
+		t.Errorf("Expected relevance test list, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest("POST", "/indexes/test_relevance/relevance_tests/_run", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var report model.RelevanceReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal report: %v", err)
+	}
+	if !report.Passed || report.Total != 1 || report.Results[0].Position != 1 {
+		t.Errorf("Expected the relevance test to pass at position 1, got %+v", report)
+	}
+}
+
+func TestIDsOnlySearchAndMultiGetHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	indexSettings := config.IndexSettings{
+		Name:             "test_mget",
+		SearchableFields: []string{"title"},
+		RankingCriteria:  []config.RankingCriterion{{Field: "popularity", Order: "desc"}},
+	}
+	if err := eng.CreateIndex(indexSettings); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, _ := eng.GetIndex("test_mget")
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "doc1", "title": "Running Shoes", "popularity": 1.0},
+		{"documentID": "doc2", "title": "Running Socks", "popularity": 2.0},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(encoded))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("POST", "/indexes/test_mget/_search", SearchRequest{Query: "running", IDsOnly: true})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var searchResult services.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &searchResult); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(searchResult.Hits) != 0 || len(searchResult.HitRefs) != 2 {
+		t.Fatalf("Expected 2 hit refs and no hits, got %+v", searchResult)
+	}
+	if searchResult.HitRefs[0].ID != "doc2" || !reflect.DeepEqual(searchResult.HitRefs[0].SortKeys, []interface{}{2.0}) {
+		t.Errorf("First hit ref = %+v, want doc2 with sort key 2", searchResult.HitRefs[0])
+	}
+
+	w = doRequest("POST", "/indexes/test_mget/documents/_mget", MultiGetRequest{IDs: []string{"doc2", "gone", "doc1"}, Fields: []string{"title"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result model.MultiGetResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	want := model.MultiGetResult{
+		Documents: []model.Document{
+			{"documentID": "doc2", "title": "Running Socks"},
+			{"documentID": "doc1", "title": "Running Shoes"},
+		},
+		Missing: []string{"gone"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Multi-get = %+v, want %+v", result, want)
+	}
+
+	w = doRequest("POST", "/indexes/test_mget/documents/_mget", MultiGetRequest{IDs: []string{}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for no IDs, got %d", http.StatusBadRequest, w.Code)
+	}
+	w = doRequest("POST", "/indexes/missing/documents/_mget", MultiGetRequest{IDs: []string{"doc1"}})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestGetAndListDocumentsHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_get_docs", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, _ := eng.GetIndex("test_get_docs")
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "doc2", "title": "Second", "year": 2020.0},
+		{"documentID": "doc1", "title": "First", "year": 2010.0},
+		{"documentID": "doc3", "title": "Third", "year": 2021.0},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	doRequest := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("/indexes/test_get_docs/documents/doc1?retrievable_fields=year")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var doc model.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !reflect.DeepEqual(doc, model.Document{"documentID": "doc1", "year": 2010.0}) {
+		t.Errorf("Document = %v, want documentID and year only", doc)
+	}
+	if w := doRequest("/indexes/test_get_docs/documents/gone"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for missing document, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = doRequest("/indexes/test_get_docs/documents?page=1&page_size=2&retrievable_fields=title")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var list model.DocumentList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	want := model.DocumentList{
+		Documents: []model.Document{
+			{"documentID": "doc1", "title": "First"},
+			{"documentID": "doc2", "title": "Second"},
+		},
+		Total:    3,
+		Page:     1,
+		PageSize: 2,
+		Pages:    2,
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("Document list = %+v, want %+v", list, want)
+	}
+	if w := doRequest("/indexes/missing/documents"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestExportDocumentsHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	indexSettings := config.IndexSettings{
+		Name:             "test_export",
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"year"},
+	}
+	if err := eng.CreateIndex(indexSettings); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, _ := eng.GetIndex("test_export")
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "doc2", "title": "Second", "year": 2020.0},
+		{"documentID": "doc1", "title": "First", "year": 2010.0},
+		{"documentID": "doc3", "title": "Third", "year": 2021.0},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	doRequest := func(query url.Values) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/indexes/test_export/documents/_export?"+query.Encode(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	filters := `{"filters":[{"field":"year","operator":"_gte","value":2015}]}`
+	w := doRequest(url.Values{"filters": {filters}, "retrievable_fields": {"title"}, "limit": {"1"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", contentType)
+	}
+	if w.Body.String() != `{"documentID":"doc2","title":"Second"}`+"\n" {
+		t.Errorf("First page = %q, want doc2 with its title only", w.Body.String())
+	}
+	cursor := w.Result().Trailer.Get("X-Next-Cursor")
+	if cursor != "doc2" {
+		t.Fatalf("X-Next-Cursor = %q, want doc2", cursor)
+	}
+
+	w = doRequest(url.Values{"filters": {filters}, "after": {cursor}, "limit": {"1"}})
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"documentID":"doc3"`) {
+		t.Errorf("Second page = %q, want doc3", w.Body.String())
+	}
+	if cursor := w.Result().Trailer.Get("X-Next-Cursor"); cursor != "" {
+		t.Errorf("X-Next-Cursor of the last page = %q, want none", cursor)
+	}
+
+	w = doRequest(nil)
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 3 {
+		t.Errorf("Full export has %d lines, want 3: %s", len(lines), w.Body.String())
+	}
+
+	w = doRequest(url.Values{"filters": {"{not json"}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid filters, got %d", http.StatusBadRequest, w.Code)
+	}
+	w = doRequest(url.Values{"limit": {"-1"}})
+	if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") == "application/x-ndjson" {
+		t.Errorf("Expected a JSON error with status %d for a negative limit, got %d (%s)", http.StatusBadRequest, w.Code, w.Header().Get("Content-Type"))
+	}
+	req, _ := http.NewRequest("GET", "/indexes/missing/documents/_export", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestShadowHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	for _, name := range []string{"test_shadow", "test_shadow_candidate"} {
+		if err := eng.CreateIndex(config.IndexSettings{Name: name, SearchableFields: []string{"title"}}); err != nil {
+			t.Fatalf("Failed to create index: %v", err)
+		}
+	}
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		reqBody := bytes.NewBuffer(nil)
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reqBody = bytes.NewBuffer(encoded)
+		}
+		req, _ := http.NewRequest(method, path, reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("GET", "/indexes/test_shadow/_shadow", nil)
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), string(ErrorCodeShadowNotFound)) {
+		t.Errorf("Expected shadow not found, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest("PUT", "/indexes/test_shadow/_shadow", model.ShadowConfig{CandidateIndex: "test_shadow_candidate", SamplePercentage: 200})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid percentage, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("PUT", "/indexes/test_shadow/_shadow", model.ShadowConfig{CandidateIndex: "missing", SamplePercentage: 100})
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "missing") {
+		t.Errorf("Expected missing candidate index, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest("PUT", "/indexes/test_shadow/_shadow", model.ShadowConfig{CandidateIndex: "test_shadow_candidate", SamplePercentage: 100})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w = doRequest("POST", "/indexes/test_shadow/_search", SearchRequest{Query: "anything", PageSize: 10})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var stats model.ShadowStats
+	deadline := time.Now().Add(5 * time.Second)
+	for stats.SampledQueries == 0 && time.Now().Before(deadline) {
+		w = doRequest("GET", "/indexes/test_shadow/_shadow", nil)
+		if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("Failed to unmarshal shadow stats: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats.SampledQueries != 1 || stats.CandidateIndex != "test_shadow_candidate" {
+		t.Errorf("Expected one sampled query mirrored to the candidate, got %+v", stats)
+	}
+
+	w = doRequest("DELETE", "/indexes/test_shadow/_shadow", nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestAnalyzeHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_analyze", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	doRequest := func(path string, body interface{}) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(encoded))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("/indexes/test_analyze/_analyze", model.AnalyzeRequest{Text: "The Matrix", Field: "title", Query: "matrx"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result model.AnalyzeResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal analyze result: %v", err)
+	}
+	if len(result.IndexTokens) != 2 || len(result.UnmatchedQueryTokens) != 1 || result.UnmatchedQueryTokens[0] != "matrx" {
+		t.Errorf("Expected two index tokens and 'matrx' unmatched, got %+v", result)
+	}
+
+	w = doRequest("/indexes/test_analyze/_analyze", model.AnalyzeRequest{Text: "The Matrix", Field: "year"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a non-searchable field, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("/indexes/missing/_analyze", model.AnalyzeRequest{Text: "The Matrix", Field: "title"})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestSpellcheckHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_spellcheck", SearchableFields: []string{"title"}, MinWordSizeFor1Typo: 4, MinWordSizeFor2Typos: 7}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	instance, _ := eng.GetIndex("test_spellcheck")
+	if err := instance.AddDocuments([]model.Document{{"documentID": "1", "title": "The Matrix"}}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	doRequest := func(path string, body interface{}) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(encoded))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("/indexes/test_spellcheck/_spellcheck", model.SpellcheckRequest{Query: "matrx"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result model.SpellcheckResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal spellcheck result: %v", err)
+	}
+	if result.CorrectedQuery != "matrix" || len(result.Corrections) != 1 {
+		t.Errorf("Expected 'matrx' to be corrected to 'matrix', got %+v", result)
+	}
+
+	w = doRequest("/indexes/test_spellcheck/_spellcheck", model.SpellcheckRequest{Query: " "})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an empty query, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("/indexes/missing/_spellcheck", model.SpellcheckRequest{Query: "matrx"})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestTermsHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_terms", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	instance, _ := eng.GetIndex("test_terms")
+	if err := instance.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Matrix"},
+		{"documentID": "2", "title": "Matrix Reloaded"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	doRequest := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("/indexes/test_terms/_terms?prefix=ma&limit=5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result model.TermsResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal terms result: %v", err)
+	}
+	if len(result.Terms) != 1 || result.Terms[0] != (model.TermCount{Term: "matrix", Documents: 2}) {
+		t.Errorf("Expected 'matrix' in 2 documents, got %+v", result)
+	}
+
+	w = doRequest("/indexes/test_terms/_terms?field=year")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a field that is not searchable, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("/indexes/test_terms/_terms?limit=many")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid limit, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("/indexes/missing/_terms?prefix=ma")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestSuggestHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_suggest", SearchableFields: []string{"title"}, SuggestionField: "title"}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	instance, _ := eng.GetIndex("test_suggest")
+	if err := instance.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Matrix"},
+		{"documentID": "2", "title": "The Matrix"},
+		{"documentID": "3", "title": "Mad Max"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	doRequest := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("/indexes/test_suggest/_suggest?q=ma&limit=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result model.SuggestResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal suggest result: %v", err)
+	}
+	if result.Field != "title" || len(result.Suggestions) != 1 || result.Suggestions[0] != (model.Suggestion{Text: "The Matrix", Documents: 2}) || !result.HasMore {
+		t.Errorf("Expected 'The Matrix' in 2 documents and more suggestions, got %+v", result)
+	}
+
+	w = doRequest("/indexes/test_suggest/_suggest?q=ma&limit=-1")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a negative limit, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = doRequest("/indexes/missing/_suggest?q=ma")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestVerifyIndexHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_verify", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	accessor, _ := eng.GetIndex("test_verify")
+	if err := accessor.AddDocuments([]model.Document{{"documentID": "1", "title": "The Matrix"}}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+	// Leave a mapping to a document that no longer exists
+	instance := accessor.(*engine.IndexInstance)
+	instance.DocumentStore.ExternalIDtoInternalID["ghost"] = 42
+
+	verify := func(path string) model.IntegrityReport {
+		t.Helper()
+		req, _ := http.NewRequest("POST", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var report model.IntegrityReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to unmarshal integrity report: %v", err)
+		}
+		return report
+	}
+
+	report := verify("/indexes/test_verify/_verify")
+	if report.Healthy || report.IssueCounts[model.IntegrityDanglingMapping] != 1 || report.Repaired {
+		t.Errorf("Expected one unrepaired dangling mapping, got %+v", report)
+	}
+	if report = verify("/indexes/test_verify/_verify?repair=true"); !report.Repaired {
+		t.Errorf("Expected the index to be repaired, got %+v", report)
+	}
+	if report = verify("/indexes/test_verify/_verify"); !report.Healthy {
+		t.Errorf("Expected a healthy index after repair, got %+v", report)
+	}
+
+	for path, status := range map[string]int{
+		"/indexes/test_verify/_verify?repair=maybe": http.StatusBadRequest,
+		"/indexes/missing/_verify":                  http.StatusNotFound,
+	} {
+		req, _ := http.NewRequest("POST", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, w.Code)
+		}
+	}
+}
+
+func TestBulkIngestHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_bulk", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	ingest := func(path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req, _ := http.NewRequest("PUT", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := ingest("/indexes/test_bulk/documents/_bulk", `{"documentID": "1", "title": "The Matrix"}
+{"documentID": "2", "title": "Inception"}
+not json
+`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var report model.BulkIngestReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal bulk ingest report: %v", err)
+	}
+	if report.Indexed != 2 || report.Failed != 1 || len(report.Errors) != 1 || report.Errors[0].Line != 3 {
+		t.Errorf("Expected 2 documents indexed and line 3 reported, got %+v", report)
+	}
+
+	accessor, _ := eng.GetIndex("test_bulk")
+	if _, found := accessor.(*engine.IndexInstance).DocumentStore.Lookup("2"); !found {
+		t.Error("Expected the ingested documents to be stored")
+	}
+
+	if w := ingest("/indexes/test_bulk/documents/_bulk", "\n\n"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an empty body, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := ingest("/indexes/missing/documents/_bulk", `{"documentID": "1"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestSearchPageSizeLimit(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_page_size", SearchableFields: []string{"title"}, MaxPageSize: 50}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	for path, body := range map[string]string{
+		"/indexes/test_page_size/_search":       `{"query": "matrix", "page_size": 100000}`,
+		"/indexes/test_page_size/_multi_search": `{"queries": [{"name": "q", "query": "matrix"}], "page_size": 51}`,
+	} {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "maximum page size of 50") {
+			t.Errorf("Expected status %d with the maximum page size for %s, got %d: %s", http.StatusBadRequest, path, w.Code, w.Body.String())
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "/indexes/test_page_size/_search", strings.NewReader(`{"query": "matrix", "page_size": 50}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d for the maximum page size, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+}
+
+func TestSettingsDiffHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_diff_live", SearchableFields: []string{"title"}, DistinctField: "sku"}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_diff_candidate", SearchableFields: []string{"title", "brand"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "/indexes/test_diff_live/settings/_diff/test_diff_candidate", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var diff model.SettingsDiff
+	if err := json.Unmarshal(w.Body.Bytes(), &diff); err != nil {
+		t.Fatalf("Failed to unmarshal settings diff: %v", err)
+	}
+	if diff.Identical || len(diff.Added) != 0 || len(diff.Removed) != 1 || diff.Removed[0].Key != "distinct_field" ||
+		len(diff.Changed) != 1 || diff.Changed[0].Key != "searchable_fields" {
+		t.Errorf("Expected distinct_field removed and searchable_fields changed, got %+v", diff)
+	}
+
+	for _, path := range []string{"/indexes/missing/settings/_diff/test_diff_live", "/indexes/test_diff_live/settings/_diff/missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusNotFound, path, w.Code)
+		}
+	}
+}
+
+func TestSearchExportHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_export", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	accessor, _ := eng.GetIndex("test_export")
+	if err := accessor.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Matrix", "year": 1999},
+		{"documentID": "2", "title": "The Matrix Reloaded", "year": 2003},
+		{"documentID": "3", "title": "Inception", "year": 2010},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("POST", "/indexes/test_export/_search/export", `{"query": "matrix", "format": "ndjson", "fields": ["documentID", "year"]}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	var started struct {
+		JobID string `json:"job_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &started); err != nil || started.JobID == "" {
+		t.Fatalf("Expected a job ID, got %s", w.Body.String())
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := eng.GetJob(started.JobID)
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if job.Status == model.JobStatusCompleted {
+			break
+		}
+		if job.Status == model.JobStatusFailed || time.Now().After(deadline) {
+			t.Fatalf("Expected the export job to complete, got %s: %s", job.Status, job.Error)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w = request("GET", "/indexes/test_export/_search/export/"+started.JobID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+		t.Errorf("Expected an NDJSON download, got %q", contentType)
+	}
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 2 || strings.Contains(w.Body.String(), "title") {
+		t.Errorf("Expected the two matching documents with the exported fields only, got:\n%s", w.Body.String())
+	}
+
+	if w := request("POST", "/indexes/test_export/_search/export", `{"query": "matrix", "format": "xml"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unsupported format, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := request("POST", "/indexes/missing/_search/export", `{"query": "matrix"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing index, got %d", http.StatusNotFound, w.Code)
+	}
+	if w := request("GET", "/indexes/test_export/_search/export/unknown-job", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown export, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestGetPopularSearchesHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	// Analytics events are persisted across runs, so use a fresh index name
+	indexName := fmt.Sprintf("test_popular_%d", time.Now().UnixNano())
+	if err := eng.CreateIndex(config.IndexSettings{Name: indexName, SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	accessor, _ := eng.GetIndex(indexName)
+	if err := accessor.AddDocuments([]model.Document{{"documentID": "1", "title": "The Matrix"}}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	for _, query := range []string{"matrix", "Matrix", "nothing here"} {
+		body, _ := json.Marshal(SearchRequest{Query: query})
+		req, _ := http.NewRequest("POST", "/indexes/"+indexName+"/_search", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+	}
+
+	// Search events are tracked asynchronously
+	var report model.PopularSearchesReport
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		req, _ := http.NewRequest("GET", "/indexes/"+indexName+"/popular_searches?window=1h&limit=5", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to unmarshal popular searches: %v", err)
+		}
+		if len(report.Searches) > 0 && report.Searches[0].SearchCount == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if report.IndexName != indexName || report.Window != "1h0m0s" {
+		t.Errorf("Unexpected report metadata: %+v", report)
+	}
+	if len(report.Searches) != 1 || report.Searches[0].Query != "matrix" || report.Searches[0].SearchCount != 2 {
+		t.Errorf("Expected only the successful query counted twice, got %+v", report.Searches)
+	}
+
+	for path, status := range map[string]int{
+		"/indexes/" + indexName + "/popular_searches?window=forever": http.StatusBadRequest,
+		"/indexes/" + indexName + "/popular_searches?window=-1h":     http.StatusBadRequest,
+		"/indexes/" + indexName + "/popular_searches?limit=-1":       http.StatusBadRequest,
+		"/indexes/" + indexName + "/popular_searches?limit=many":     http.StatusBadRequest,
+		"/indexes/missing/popular_searches":                          http.StatusNotFound,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, w.Code)
+		}
+	}
+}
+
+func TestQueryAnalyticsHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	// Analytics events are persisted across runs, so use a fresh index name
+	indexName := fmt.Sprintf("test_query_analytics_%d", time.Now().UnixNano())
+	if err := eng.CreateIndex(config.IndexSettings{Name: indexName, SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	accessor, _ := eng.GetIndex(indexName)
+	if err := accessor.AddDocuments([]model.Document{{"documentID": "1", "title": "The Matrix"}}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	// Events are tracked concurrently, so either search of qwxz may be the last one
+	queryIDs := make(map[string]string)
+	for _, query := range []string{"matrix", "qwxz", "Qwxz"} {
+		body, _ := json.Marshal(SearchRequest{Query: query})
+		req, _ := http.NewRequest("POST", "/indexes/"+indexName+"/_search", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var result struct {
+			QueryID string `json:"query_id"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &result)
+		queryIDs[result.QueryID] = strings.ToLower(query)
+	}
+
+	get := func(path string, target any) {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d. Response: %s", path, http.StatusOK, w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), target); err != nil {
+			t.Fatalf("%s: failed to unmarshal response: %v", path, err)
+		}
+	}
+
+	// Search events are tracked asynchronously
+	var top model.QueryStatsReport
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		get("/indexes/"+indexName+"/top_queries?window=1h", &top)
+		if len(top.Queries) == 2 && top.Queries[0].SearchCount == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(top.Queries) != 2 || top.Queries[0].Query != "qwxz" || top.Queries[1].Query != "matrix" {
+		t.Fatalf("Expected qwxz then matrix as top queries, got %+v", top.Queries)
+	}
+	if top.Queries[0].ZeroResultCount != 2 || queryIDs[top.Queries[0].LastQueryID] != "qwxz" {
+		t.Errorf("Expected qwxz without results twice and last searched by one of its query IDs, got %+v", top.Queries[0])
+	}
+
+	var zeroResult model.QueryStatsReport
+	get("/indexes/"+indexName+"/zero_result_queries?limit=5", &zeroResult)
+	if zeroResult.Window != "24h0m0s" || len(zeroResult.Queries) != 1 || zeroResult.Queries[0].Query != "qwxz" {
+		t.Errorf("Expected only qwxz as a zero-result query over the default window, got %+v", zeroResult)
+	}
+
+	var latency model.LatencyReport
+	get("/indexes/"+indexName+"/search_latency?window=1h", &latency)
+	if latency.IndexName != indexName || latency.SearchCount != 3 || latency.MaxMs < latency.P50Ms {
+		t.Errorf("Expected a latency report over the 3 searches, got %+v", latency)
+	}
+
+	for path, status := range map[string]int{
+		"/indexes/" + indexName + "/top_queries?window=forever":   http.StatusBadRequest,
+		"/indexes/" + indexName + "/zero_result_queries?limit=-1": http.StatusBadRequest,
+		"/indexes/" + indexName + "/search_latency?window=-1h":    http.StatusBadRequest,
+		"/indexes/missing/top_queries":                            http.StatusNotFound,
+		"/indexes/missing/zero_result_queries":                    http.StatusNotFound,
+		"/indexes/missing/search_latency":                         http.StatusNotFound,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, w.Code)
+		}
+	}
+}
+
+func TestRenameAliasRouting(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_alias_source", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	if err := eng.RenameIndex("test_alias_source", "test_alias_target"); err != nil {
+		t.Fatalf("Failed to rename index: %v", err)
+	}
+
+	body, _ := json.Marshal(SearchRequest{Query: "anything"})
+	req, _ := http.NewRequest("POST", "/indexes/test_alias_source/_search", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d through the old name, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w.Header().Get("Deprecation") != "true" || w.Header().Get("Sunset") == "" {
+		t.Errorf("Expected Deprecation and Sunset headers, got %v", w.Header())
+	}
+	if link := w.Header().Get("Link"); !strings.Contains(link, "/indexes/test_alias_target") {
+		t.Errorf("Expected Link header to the new name, got %q", link)
+	}
+
+	req, _ = http.NewRequest("GET", "/indexes/test_alias_target", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Deprecation") != "" {
+		t.Errorf("Expected the new name to be served without deprecation, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestMain(m *testing.M) {
+	// Setup code before tests
+	code := m.Run()
 	// Cleanup code after tests
 	// Remove all registered test directories
 	testDirsMu.Lock()
@@ -1081,6 +2783,821 @@ func TestMain(m *testing.M) {
 			fmt.Printf("Warning: Failed to remove test directory %s: %v\n", testDir, err)
 		}
 	}
-	testDirsMu.Unlock()
-	os.Exit(code)
+	testDirsMu.Unlock()
+	os.Exit(code)
+}
+
+func TestAliasHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	for _, name := range []string{"test_alias_v1", "test_alias_v2"} {
+		if err := eng.CreateIndex(config.IndexSettings{Name: name, SearchableFields: []string{"title"}}); err != nil {
+			t.Fatalf("Failed to create index: %v", err)
+		}
+		instance, _ := eng.GetIndex(name)
+		if err := instance.AddDocuments([]model.Document{{"documentID": name, "title": "Matrix"}}); err != nil {
+			t.Fatalf("Failed to add documents: %v", err)
+		}
+	}
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	searchedIndex := func(alias string) string {
+		t.Helper()
+		w := doRequest("POST", "/indexes/"+alias+"/_search", SearchRequest{Query: "matrix"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var result struct {
+			Hits []struct {
+				Document model.Document `json:"document"`
+			} `json:"hits"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil || len(result.Hits) != 1 {
+			t.Fatalf("Expected one hit, got %s", w.Body.String())
+		}
+		return result.Hits[0].Document["documentID"].(string)
+	}
+
+	for alias, index := range map[string]string{"test_alias_live": "test_alias_v1", "test_alias_staging": "test_alias_v2"} {
+		if w := doRequest("PUT", "/aliases/"+alias, PutAliasRequest{Index: index}); w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+	}
+	if got := searchedIndex("test_alias_live"); got != "test_alias_v1" {
+		t.Errorf("Expected the alias to search its index, got a hit of %s", got)
+	}
+	if w := doRequest("GET", "/indexes/test_alias_live/documents/test_alias_v1", nil); w.Code != http.StatusOK || w.Header().Get("X-Index-Name") != "test_alias_v1" {
+		t.Errorf("Expected documents to be read through the alias, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := doRequest("POST", "/aliases/_swap", SwapAliasesRequest{Aliases: []string{"test_alias_live", "test_alias_staging"}}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := searchedIndex("test_alias_live"); got != "test_alias_v2" {
+		t.Errorf("Expected the swapped alias to search the other index, got a hit of %s", got)
+	}
+
+	// Index management routes do not resolve aliases
+	if w := doRequest("DELETE", "/indexes/test_alias_live", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected deleting through an alias to fail with %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		want   int
+	}{
+		{"missing index", "PUT", "/aliases/test_alias_other", PutAliasRequest{Index: "missing"}, http.StatusNotFound},
+		{"alias named after an index", "PUT", "/aliases/test_alias_v1", PutAliasRequest{Index: "test_alias_v2"}, http.StatusBadRequest},
+		{"swap without two aliases", "POST", "/aliases/_swap", SwapAliasesRequest{Aliases: []string{"test_alias_live"}}, http.StatusBadRequest},
+		{"swap missing alias", "POST", "/aliases/_swap", SwapAliasesRequest{Aliases: []string{"test_alias_live", "missing"}}, http.StatusNotFound},
+		{"get alias", "GET", "/aliases/test_alias_live", nil, http.StatusOK},
+		{"list aliases", "GET", "/aliases", nil, http.StatusOK},
+		{"delete alias", "DELETE", "/aliases/test_alias_live", nil, http.StatusOK},
+		{"deleted alias", "GET", "/aliases/test_alias_live", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := doRequest(tt.method, tt.path, tt.body); w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d. Response: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetIndexSeqHandler(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_seq_index", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	instance, _ := eng.GetIndex("test_seq_index")
+	if err := instance.AddDocuments([]model.Document{{"documentID": "1", "title": "Matrix"}, {"documentID": "2", "title": "Alien"}}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "/indexes/test_seq_index/_seq", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var seq model.IndexSeq
+	if err := json.Unmarshal(w.Body.Bytes(), &seq); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	expected := model.IndexSeq{IndexName: "test_seq_index", Seq: 2, SearchableSeq: 2}
+	if seq != expected {
+		t.Errorf("Expected %+v, got %+v", expected, seq)
+	}
+
+	req, _ = http.NewRequest("GET", "/indexes/missing_seq_index/_seq", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestBenchmarkHandler(t *testing.T) {
+	router := setupTestRouter(setupTestEngine())
+	doRequest := func(spec benchmarks.RunSpec) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(spec)
+		req, _ := http.NewRequest("POST", "/_benchmark", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest(benchmarks.RunSpec{Corpus: benchmarks.CorpusSpec{Documents: 100, VocabularySize: 200}, Queries: 20})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var report benchmarks.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if report.Corpus.Documents != 100 || report.Corpus.Seed != benchmarks.DefaultSeed || report.Queries != 20 || report.Terms == 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	if w := doRequest(benchmarks.RunSpec{Corpus: benchmarks.CorpusSpec{Documents: benchmarks.MaxDocuments + 1}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for too many documents, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	for _, name := range []string{"test_auth_products", "test_auth_orders"} {
+		if err := eng.CreateIndex(config.IndexSettings{Name: name, SearchableFields: []string{"title"}, FilterableFields: []string{"tenant"}}); err != nil {
+			t.Fatalf("Failed to create index: %v", err)
+		}
+		indexAccessor, _ := eng.GetIndex(name)
+		if err := indexAccessor.AddDocuments([]model.Document{
+			{"documentID": "doc1", "title": "blue shoes", "tenant": "acme"},
+			{"documentID": "doc2", "title": "blue shoes", "tenant": "globex"},
+		}); err != nil {
+			t.Fatalf("Failed to add documents: %v", err)
+		}
+	}
+
+	doRequest := func(method, path, key string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Buffer
+		if body != nil {
+			data, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(data)
+		} else {
+			reader = &bytes.Buffer{}
+		}
+		req, _ := http.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	search := SearchRequest{Query: "shoes"}
+
+	// Without an admin key the API is open
+	if w := doRequest("POST", "/indexes/test_auth_products/_search", "", search); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d without an admin key, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	const adminKey = "admin-key-for-tests"
+	if err := eng.SetAdminKey(adminKey); err != nil {
+		t.Fatalf("Failed to set admin key: %v", err)
+	}
+	if w := doRequest("GET", "/health", "", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected /health to stay open, got %d", w.Code)
+	}
+	if w := doRequest("POST", "/indexes/test_auth_products/_search", "", search); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d without a key, got %d", http.StatusUnauthorized, w.Code)
+	}
+	if w := doRequest("POST", "/indexes/test_auth_products/_search", "wrong-key", search); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d with an unknown key, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	w := doRequest("POST", "/keys", adminKey, model.APIKey{
+		Actions: []model.APIKeyAction{model.APIKeyActionSearch},
+		Indexes: []string{"test_auth_prod*"},
+		Filters: []model.RuleFilter{{Field: "tenant", Value: "acme"}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d creating a key, got %d. Response: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created model.CreatedAPIKey
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal created key: %v", err)
+	}
+
+	// The key's filters are added to its searches
+	w = doRequest("POST", "/indexes/test_auth_products/_search", created.Key, search)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d searching with the key, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result services.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal search result: %v", err)
+	}
+	if result.Total != 1 || result.Hits[0].Document["tenant"] != "acme" {
+		t.Errorf("Expected the key to only find the acme document, got %+v", result.Hits)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+	}{
+		{"POST", "/indexes/test_auth_orders/_search"},    // Index outside the key's patterns
+		{"GET", "/indexes/test_auth_products/documents"}, // Action outside the key's scope
+		{"GET", "/indexes"},                              // Across indexes
+		{"GET", "/keys"},                                 // Admin only
+	} {
+		if w := doRequest(tc.method, tc.path, created.Key, search); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.path, http.StatusForbidden, w.Code)
+		}
+	}
+
+	// Keys can also be sent in the X-API-Key header, and the admin key is allowed everything
+	req, _ := http.NewRequest("GET", "/indexes/test_auth_products/documents", nil)
+	req.Header.Set("X-API-Key", adminKey)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d with the admin key in X-API-Key, got %d", http.StatusOK, w.Code)
+	}
+
+	if w := doRequest("DELETE", "/keys/"+created.ID, adminKey, nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d deleting the key, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w := doRequest("POST", "/indexes/test_auth_products/_search", created.Key, search); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d with a deleted key, got %d", http.StatusUnauthorized, w.Code)
+	}
+	if w := doRequest("GET", "/keys/"+created.ID, adminKey, nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d getting a deleted key, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestAPIKeyFiltersOnDocumentReads(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_key_filters", SearchableFields: []string{"title"}, FilterableFields: []string{"tenant"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, _ := eng.GetIndex("test_key_filters")
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "acme1", "title": "blue shoes", "tenant": "acme"},
+		{"documentID": "globex1", "title": "green sneakers shoes", "tenant": "globex"},
+		{"documentID": "acme2", "title": "red shoes", "tenant": "acme"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	const adminKey = "admin-key-for-tests"
+	if err := eng.SetAdminKey(adminKey); err != nil {
+		t.Fatalf("Failed to set admin key: %v", err)
+	}
+	doRequest := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	data, _ := json.Marshal(model.APIKey{
+		Actions: []model.APIKeyAction{model.APIKeyActionSearch, model.APIKeyActionDocumentsRead},
+		Filters: []model.RuleFilter{{Field: "tenant", Value: "acme"}},
+	})
+	w := doRequest("POST", "/keys", adminKey, string(data))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d creating a key, got %d. Response: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created model.CreatedAPIKey
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal created key: %v", err)
+	}
+	key := created.Key
+
+	t.Run("get", func(t *testing.T) {
+		if w := doRequest("GET", "/indexes/test_key_filters/documents/acme1", key, ""); w.Code != http.StatusOK {
+			t.Errorf("Expected status %d getting a matching document, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if w := doRequest("GET", "/indexes/test_key_filters/documents/globex1", key, ""); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d getting another tenant's document, got %d", http.StatusNotFound, w.Code)
+		}
+		if w := doRequest("GET", "/indexes/test_key_filters/documents/globex1", adminKey, ""); w.Code != http.StatusOK {
+			t.Errorf("Expected the admin key to get any document, got %d", w.Code)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		w := doRequest("GET", "/indexes/test_key_filters/documents?page_size=1&page=2", key, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var list model.DocumentList
+		if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Failed to unmarshal list: %v", err)
+		}
+		if list.Total != 2 || list.Pages != 2 || len(list.Documents) != 1 || list.Documents[0]["documentID"] != "acme2" {
+			t.Errorf("Expected the second of the 2 acme documents, got %+v", list)
+		}
+	})
+
+	t.Run("multi-get", func(t *testing.T) {
+		w := doRequest("POST", "/indexes/test_key_filters/documents/_mget", key, `{"ids": ["globex1", "acme2", "missing", "globex1"]}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var result model.MultiGetResult
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		if len(result.Documents) != 1 || result.Documents[0]["documentID"] != "acme2" {
+			t.Errorf("Expected only acme2, got %+v", result.Documents)
+		}
+		if !reflect.DeepEqual(result.Missing, []string{"globex1", "missing"}) {
+			t.Errorf("Expected globex1 to be missing like an unknown ID, got %v", result.Missing)
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		w := doRequest("GET", "/indexes/test_key_filters/documents/_export", key, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 2 || strings.Contains(w.Body.String(), "globex") {
+			t.Errorf("Expected the 2 acme documents, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("suggest and spellcheck", func(t *testing.T) {
+		w := doRequest("GET", "/indexes/test_key_filters/_suggest?q=s", key, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var suggestions model.SuggestResult
+		if err := json.Unmarshal(w.Body.Bytes(), &suggestions); err != nil {
+			t.Fatalf("Failed to unmarshal suggestions: %v", err)
+		}
+		if want := []model.Suggestion{{Text: "shoes", Documents: 2}}; !reflect.DeepEqual(suggestions.Suggestions, want) {
+			t.Errorf("Suggestions = %+v, want %+v", suggestions.Suggestions, want)
+		}
+
+		w = doRequest("POST", "/indexes/test_key_filters/_spellcheck", key, `{"query": "sneakerz"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var spellcheck model.SpellcheckResult
+		if err := json.Unmarshal(w.Body.Bytes(), &spellcheck); err != nil {
+			t.Fatalf("Failed to unmarshal spellcheck: %v", err)
+		}
+		if len(spellcheck.Corrections) != 0 {
+			t.Errorf("Expected no correction to another tenant's word, got %+v", spellcheck.Corrections)
+		}
+	})
+
+	t.Run("search export", func(t *testing.T) {
+		w := doRequest("POST", "/indexes/test_key_filters/_search/export", key, `{"query": "shoes", "format": "ndjson"}`)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusAccepted, w.Code, w.Body.String())
+		}
+		var started struct {
+			JobID string `json:"job_id"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &started); err != nil || started.JobID == "" {
+			t.Fatalf("Expected a job ID, got %s", w.Body.String())
+		}
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			job, err := eng.GetJob(started.JobID)
+			if err != nil {
+				t.Fatalf("Failed to get job: %v", err)
+			}
+			if job.Status == model.JobStatusCompleted {
+				break
+			}
+			if job.Status == model.JobStatusFailed || time.Now().After(deadline) {
+				t.Fatalf("Expected the export job to complete, got %s: %s", job.Status, job.Error)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		w = doRequest("GET", "/indexes/test_key_filters/_search/export/"+started.JobID, key, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 2 || strings.Contains(w.Body.String(), "globex") {
+			t.Errorf("Expected the 2 acme hits, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("other routes stay forbidden", func(t *testing.T) {
+		if w := doRequest("GET", "/indexes/test_key_filters/_terms?prefix=s", key, ""); w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d listing terms with a filtered key, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+}
+
+func TestRouteActionsCoverRoutes(t *testing.T) {
+	router := setupTestRouter(setupTestEngine())
+
+	// Routes left to the admin key on purpose
+	adminOnly := map[string]bool{
+		"POST /_benchmark":                  true,
+		"GET /keys":                         true,
+		"POST /keys":                        true,
+		"GET /keys/:keyId":                  true,
+		"DELETE /keys/:keyId":               true,
+		"GET /indexes/:indexName/_snapshot": true,
+		"POST /indexes/:indexName/_restore": true,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		_, scoped := routeActions[key]
+		if !scoped && !publicRoutes[key] && !adminOnly[key] {
+			t.Errorf("Route %s has no API key action", key)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	eng := setupTestEngine()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRoutesWithOptions(router, eng, RouteOptions{RateLimits: RateLimits{
+		Search:   RateLimit{Rate: 0.01, Burst: 2},
+		Indexing: RateLimit{Rate: 0.01, Burst: 1},
+	}})
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_rate_limit", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	doRequest := func(method, path, remoteAddr string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	search := func(remoteAddr string) *httptest.ResponseRecorder {
+		return doRequest("POST", "/indexes/test_rate_limit/_search", remoteAddr, SearchRequest{Query: "anything"})
+	}
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		w := search("192.0.2.1:1234")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Search %d: expected status %d, got %d. Response: %s", i+1, http.StatusOK, w.Code, w.Body.String())
+		}
+		if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != wantRemaining {
+			t.Errorf("Search %d: RateLimit-Limit %q, RateLimit-Remaining %q, want 2 and %s", i+1,
+				w.Header().Get("RateLimit-Limit"), w.Header().Get("RateLimit-Remaining"), wantRemaining)
+		}
+	}
+
+	w := search("192.0.2.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d over the limit, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retryAfter < 1 {
+		t.Errorf("Retry-After = %q, want a positive number of seconds", w.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(w.Body.String(), string(ErrorCodeRateLimited)) {
+		t.Errorf("Expected a %s error, got %s", ErrorCodeRateLimited, w.Body.String())
+	}
+
+	// Other clients and the indexing budget are not affected, and unlimited routes have no headers
+	if w := search("192.0.2.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("Expected another client to search, got %d", w.Code)
+	}
+	documents := []model.Document{{"documentID": "doc1", "title": "Test"}}
+	if w := doRequest("PUT", "/indexes/test_rate_limit/documents", "192.0.2.1:1234", documents); w.Code != http.StatusAccepted {
+		t.Errorf("Expected the indexing budget to be separate, got %d. Response: %s", w.Code, w.Body.String())
+	}
+	if w := doRequest("PUT", "/indexes/test_rate_limit/documents", "192.0.2.1:1234", documents); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the second write over the indexing limit, got %d", w.Code)
+	}
+	if w := doRequest("GET", "/indexes/test_rate_limit", "192.0.2.1:1234", nil); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("Expected index details not to be limited, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{Rate: 2})
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, _, _, allowed := limiter.allow("client"); !allowed {
+			t.Fatalf("Request %d within the burst was not allowed", i+1)
+		}
+	}
+	remaining, reset, retryAfter, allowed := limiter.allow("client")
+	if allowed || remaining != 0 || retryAfter != 500*time.Millisecond || reset != time.Second {
+		t.Errorf("allow() over the burst = %d, %v, %v, %v; want denied, retry after 500ms and full after 1s", remaining, reset, retryAfter, allowed)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if _, _, _, allowed := limiter.allow("client"); !allowed {
+		t.Error("Expected a token to be back after 500ms at 2 requests per second")
+	}
+
+	now = now.Add(rateLimitSweepInterval)
+	limiter.allow("other")
+	if _, exists := limiter.buckets["client"]; exists {
+		t.Error("Expected the refilled bucket of an idle client to be dropped")
+	}
+	if newRateLimiter(RateLimit{}) != nil {
+		t.Error("Expected a zero rate to disable the limiter")
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	router := setupTestRouter(setupTestEngine())
+	doRequest := func(requestID string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/indexes/missing_index", nil)
+		if requestID != "" {
+			req.Header.Set(requestIDHeader, requestID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := doRequest("")
+	generated := w.Header().Get(requestIDHeader)
+	if generated == "" {
+		t.Fatal("Expected a generated X-Request-ID header")
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response["request_id"] != generated {
+		t.Errorf("Error response request_id = %v, want the X-Request-ID header %q", response["request_id"], generated)
+	}
+
+	if w := doRequest("edge-proxy:1234"); w.Header().Get(requestIDHeader) != "edge-proxy:1234" {
+		t.Errorf("X-Request-ID = %q, want the client's request ID", w.Header().Get(requestIDHeader))
+	}
+	for _, invalid := range []string{"has spaces", "new\nline", strings.Repeat("a", maxRequestIDLength+1)} {
+		if got := doRequest(invalid).Header().Get(requestIDHeader); got == invalid || got == "" {
+			t.Errorf("X-Request-ID for client ID %q = %q, want a generated ID", invalid, got)
+		}
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_tracing", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	const traceID, callerSpanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	body, _ := json.Marshal(SearchRequest{Query: "anything"})
+	req, _ := http.NewRequest("POST", "/indexes/test_tracing/_search", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-"+callerSpanID+"-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	requestSpan, ok := spans["POST /indexes/:indexName/_search"]
+	if !ok {
+		t.Fatalf("No span of the request among %v", slices.Collect(maps.Keys(spans)))
+	}
+	if requestSpan.SpanContext().TraceID().String() != traceID || requestSpan.Parent().SpanID().String() != callerSpanID {
+		t.Errorf("Request span is not a child of the caller's span in the traceparent header")
+	}
+	if searchSpan, ok := spans["search"]; !ok || searchSpan.Parent().SpanID() != requestSpan.SpanContext().SpanID() {
+		t.Errorf("Expected the search span to be a child of the request span")
+	}
+}
+
+func TestCloseAndOpenIndexHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_close", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, _ := eng.GetIndex("test_close")
+	if err := indexAccessor.AddDocuments([]model.Document{{"documentID": "1", "title": "Dormant Archive"}}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Buffer
+		if body != nil {
+			data, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(data)
+		} else {
+			reader = bytes.NewBuffer(nil)
+		}
+		req, _ := http.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := doRequest("POST", "/indexes/test_close/_close", nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d closing the index, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Getting the settings and listing indexes leave the index closed
+	if w := doRequest("GET", "/indexes/test_close", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d getting a closed index, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	w := doRequest("GET", "/indexes", nil)
+	var list struct {
+		States map[string]string `json:"states"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if list.States["test_close"] != "closed" {
+		t.Errorf("Expected the index to be listed as closed, got states %v", list.States)
+	}
+
+	// A search opens the index, with a warning about the delay
+	w = doRequest("POST", "/indexes/test_close/_search", SearchRequest{Query: "archive"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d searching a closed index, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Header().Get("Warning"), "opened for this request") {
+		t.Errorf("Expected a Warning header about opening the index, got %q", w.Header().Get("Warning"))
+	}
+	var result services.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil || result.Total != 1 {
+		t.Errorf("Expected 1 hit from the reopened index, got %d (err: %v)", result.Total, err)
+	}
+
+	if w := doRequest("POST", "/indexes/test_close/_search", SearchRequest{Query: "archive"}); w.Header().Get("Warning") != "" {
+		t.Errorf("Expected no Warning header once the index is open, got %q", w.Header().Get("Warning"))
+	}
+
+	// Opening explicitly
+	doRequest("POST", "/indexes/test_close/_close", nil)
+	w = doRequest("POST", "/indexes/test_close/_open", nil)
+	var opened struct {
+		State  string `json:"state"`
+		Opened bool   `json:"opened"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &opened); err != nil || w.Code != http.StatusOK || !opened.Opened || opened.State != "open" {
+		t.Errorf("Expected the index to be opened, got status %d. Response: %s", w.Code, w.Body.String())
+	}
+
+	if w := doRequest("POST", "/indexes/missing_index/_close", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d closing a missing index, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestJobManagementHandlers(t *testing.T) {
+	eng := setupTestEngine()
+	router := setupTestRouter(eng)
+	if err := eng.CreateIndex(config.IndexSettings{Name: "test_jobs", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	jobID, err := eng.AddDocumentsAsync("test_jobs", []model.Document{{"documentID": "1", "title": "Queued Work"}})
+	if err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		job, err := eng.GetJob(jobID)
+		if err == nil && job.IsFinished() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the add documents job to finish, got %+v (err: %v)", job, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	doRequest := func(method, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response of %s %s: %v", method, path, err)
+		}
+		return w, response
+	}
+
+	w, response := doRequest("GET", "/jobs?index=test_jobs&status=completed&type=add_documents")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d listing jobs, got %d. Response: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	jobs, _ := response["jobs"].([]interface{})
+	if response["total"] != float64(1) || len(jobs) != 1 || jobs[0].(map[string]interface{})["id"] != jobID {
+		t.Errorf("Expected the completed job to be listed, got %s", w.Body.String())
+	}
+	progress, _ := jobs[0].(map[string]interface{})["progress"].(map[string]interface{})
+	if progress["percentage"] != float64(100) {
+		t.Errorf("Expected the job to report 100%% progress, got %v", progress)
+	}
+	if _, response := doRequest("GET", "/jobs?status=running"); response["total"] != float64(0) {
+		t.Errorf("Expected no running job, got %v", response)
+	}
+	if w, _ := doRequest("GET", "/jobs?status=unknown"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown job status, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if w, response := doRequest("DELETE", "/jobs/"+jobID); w.Code != http.StatusConflict || response["code"] != string(ErrorCodeJobFinished) {
+		t.Errorf("Expected %s cancelling a finished job, got status %d. Response: %s", ErrorCodeJobFinished, w.Code, w.Body.String())
+	}
+	if w, response := doRequest("DELETE", "/jobs/missing-job"); w.Code != http.StatusNotFound || response["code"] != string(ErrorCodeJobNotFound) {
+		t.Errorf("Expected %s cancelling a missing job, got status %d. Response: %s", ErrorCodeJobNotFound, w.Code, w.Body.String())
+	}
+
+	if w, _ := doRequest("POST", "/jobs/_cleanup?older_than=soon"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid older_than, got %d", http.StatusBadRequest, w.Code)
+	}
+	if _, response := doRequest("POST", "/jobs/_cleanup?older_than=1h"); response["removed"] != float64(0) {
+		t.Errorf("Expected no job to have finished an hour ago, got %v", response)
+	}
+	if _, response := doRequest("POST", "/jobs/_cleanup"); response["removed"].(float64) < 1 {
+		t.Errorf("Expected the finished jobs to be removed, got %v", response)
+	}
+	if w, _ := doRequest("GET", "/jobs/"+jobID); w.Code != http.StatusNotFound {
+		t.Errorf("Expected the removed job to be gone, got status %d", w.Code)
+	}
+}
+
+// localeRoutingEngine is an engine that is not *engine.Engine, as a wrapper of it would be
+type localeRoutingEngine struct {
+	*engine.Engine
+}
+
+func TestSearchLocaleRouting(t *testing.T) {
+	eng := setupTestEngine()
+	for _, settings := range []config.IndexSettings{
+		{Name: "movies_en", SearchableFields: []string{"title"}, Locale: "en"},
+		{Name: "movies_de", SearchableFields: []string{"title"}, Locale: "de"},
+	} {
+		if err := eng.CreateIndex(settings); err != nil {
+			t.Fatalf("Failed to create index %s: %v", settings.Name, err)
+		}
+	}
+
+	search := func(router *gin.Engine) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(SearchRequest{Query: "anything"})
+		req, _ := http.NewRequest("POST", "/indexes/movies/_search?lang=de-CH,de%3Bq%3D0.9", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRoutes(router, localeRoutingEngine{eng})
+	w := search(router)
+	if w.Code != http.StatusOK || w.Header().Get("X-Resolved-Index") != "movies_de" {
+		t.Errorf("Expected the search to be routed to movies_de, got status %d and X-Resolved-Index %q. Response: %s",
+			w.Code, w.Header().Get("X-Resolved-Index"), w.Body.String())
+	}
+
+	// Engines without locale routing reject lang rather than ignoring it
+	router = gin.New()
+	SetupRoutes(router, struct{ services.IndexManager }{eng})
+	if w := search(router); w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status %d without locale routing, got %d. Response: %s", http.StatusNotImplemented, w.Code, w.Body.String())
+	}
 }
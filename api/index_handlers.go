@@ -4,12 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/internal/engine"
 	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
 	"github.com/gcbaptista/go-search-engine/services"
 )
 
@@ -62,16 +66,155 @@ func (api *API) CreateIndexHandler(c *gin.Context) {
 	}
 }
 
-// ListIndexesHandler lists all available indexes.
+// IndexListRequest defines the selection, sorting and pagination of index listings
+type IndexListRequest struct {
+	Tags     []string `form:"tag"`       // Only indexes with all of the tags
+	Query    string   `form:"q"`         // Only indexes whose name contains it, ignoring case
+	Sort     string   `form:"sort"`      // name (default), document_count or updated_at
+	Order    string   `form:"order"`     // asc or desc; defaults to asc for name and desc otherwise
+	Page     int      `form:"page"`      // Pages are only returned when page or page_size is set
+	PageSize int      `form:"page_size"` // Defaults to 10, at most 100
+}
+
+// indexListEntry is an index considered for a listing.
+type indexListEntry struct {
+	name          string
+	metadata      *config.IndexMetadata
+	state         model.IndexState
+	hasStats      bool // Whether the engine reports the document count and update time
+	documentCount int
+	updatedAt     time.Time
+}
+
+// ListIndexesHandler lists the available indexes with their metadata, states, document counts and
+// update times; closed indexes have no document count or update time. Repeated tag query
+// parameters (?tag=prod&tag=search) list only the indexes with all of the tags, and q only those
+// whose name contains it. Indexes are sorted by name unless sort says otherwise; all of them are
+// returned unless page or page_size is set.
 func (api *API) ListIndexesHandler(c *gin.Context) {
-	names := api.engine.ListIndexes()
-	c.JSON(http.StatusOK, gin.H{"indexes": names, "count": len(names)})
+	var req IndexListRequest
+	if result := ValidateQueryBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+	result := &ValidationResult{Valid: true}
+	switch req.Sort {
+	case "":
+		req.Sort = "name"
+	case "name", "document_count", "updated_at":
+	default:
+		result.AddError("sort", "Sort must be one of name, document_count or updated_at")
+	}
+	descending := req.Sort != "name"
+	switch req.Order {
+	case "":
+	case "asc", "desc":
+		descending = req.Order == "desc"
+	default:
+		result.AddError("order", "Order must be asc or desc")
+	}
+	paginated := req.Page != 0 || req.PageSize != 0
+	if req.Page < 0 {
+		result.AddError("page", "Page number must be greater than 0")
+	}
+	if req.PageSize < 0 {
+		result.AddError("page_size", "Page size must be greater than 0")
+	}
+	if result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	query := strings.ToLower(req.Query)
+	entries := make([]indexListEntry, 0)
+	for _, name := range api.engine.ListIndexes() {
+		if !strings.Contains(strings.ToLower(name), query) {
+			continue
+		}
+		settings, err := api.engine.GetIndexSettings(name)
+		if err != nil {
+			continue // Deleted since it was listed
+		}
+		if !settings.Metadata.HasTags(req.Tags...) {
+			continue
+		}
+		entry := indexListEntry{name: name, metadata: settings.Metadata, state: model.IndexStateOpen}
+		indexAccessor, err := api.engine.GetIndex(name)
+		switch {
+		case errors.Is(err, internalErrors.ErrIndexClosed):
+			entry.state = model.IndexStateClosed // Listed without opening it, so without stats
+		case err != nil:
+			continue // Deleted since it was listed
+		default:
+			if engineInstance, ok := indexAccessor.(*engine.IndexInstance); ok {
+				entry.hasStats = true
+				entry.documentCount = engineInstance.DocumentStore.Len()
+				entry.updatedAt = engineInstance.UpdatedAt()
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if descending {
+			a, b = b, a
+		}
+		switch req.Sort {
+		case "name":
+			return a.name < b.name
+		case "document_count":
+			if a.documentCount != b.documentCount {
+				return a.documentCount < b.documentCount
+			}
+		case "updated_at":
+			if !a.updatedAt.Equal(b.updatedAt) {
+				return a.updatedAt.Before(b.updatedAt)
+			}
+		}
+		// Ties are listed by name in ascending order
+		return entries[i].name < entries[j].name
+	})
+
+	total := len(entries)
+	response := gin.H{"total": total}
+	if paginated {
+		page, pageSize, _ := ValidatePagination(req.Page, req.PageSize)
+		start := min((page-1)*pageSize, total)
+		entries = entries[start:min(start+pageSize, total)]
+		response["page"] = page
+		response["page_size"] = pageSize
+		response["pages"] = (total + pageSize - 1) / pageSize
+	}
+
+	names := make([]string, 0, len(entries))
+	metadata := make(map[string]*config.IndexMetadata, len(entries))
+	documentCounts := make(map[string]int, len(entries))
+	updatedAt := make(map[string]time.Time, len(entries))
+	states := make(map[string]model.IndexState, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.name)
+		metadata[entry.name] = entry.metadata
+		states[entry.name] = entry.state
+		if entry.hasStats {
+			documentCounts[entry.name] = entry.documentCount
+			updatedAt[entry.name] = entry.updatedAt
+		}
+	}
+	response["indexes"] = names
+	response["count"] = len(names)
+	response["metadata"] = metadata
+	response["document_counts"] = documentCounts
+	response["updated_at"] = updatedAt
+	response["states"] = states
+	c.JSON(http.StatusOK, response)
 }
 
-// GetIndexHandler retrieves details about a specific index (its settings).
+// GetIndexHandler retrieves details about a specific index (its settings), without opening it if
+// it is closed.
 func (api *API) GetIndexHandler(c *gin.Context) {
 	indexName := c.Param("indexName")
-	indexAccessor, err := api.engine.GetIndex(indexName)
+	settings, err := api.engine.GetIndexSettings(indexName)
 	if err != nil {
 		if errors.Is(err, internalErrors.ErrIndexNotFound) {
 			SendIndexNotFoundError(c, indexName)
@@ -80,7 +223,30 @@ func (api *API) GetIndexHandler(c *gin.Context) {
 		SendInternalError(c, "get index", err)
 		return
 	}
-	c.JSON(http.StatusOK, indexAccessor.Settings())
+	c.JSON(http.StatusOK, settings)
+}
+
+// SettingsDiffHandler handles comparing the settings of two indexes, e.g. before swapping an alias
+// to a new index or to find drift between environments.
+func (api *API) SettingsDiffHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	otherIndex := c.Param("otherIndex")
+
+	settings := make([]config.IndexSettings, 0, 2)
+	for _, name := range []string{indexName, otherIndex} {
+		indexSettings, err := api.engine.GetIndexSettings(name)
+		if err != nil {
+			if errors.Is(err, internalErrors.ErrIndexNotFound) {
+				SendIndexNotFoundError(c, name)
+				return
+			}
+			SendInternalError(c, "get index", err)
+			return
+		}
+		settings = append(settings, indexSettings)
+	}
+
+	c.JSON(http.StatusOK, settings[0].Diff(&settings[1]))
 }
 
 // DeleteIndexHandler handles deleting an index.
@@ -119,6 +285,62 @@ func (api *API) DeleteIndexHandler(c *gin.Context) {
 	}
 }
 
+// DeleteIndexesRequest defines the selection of indexes deleted together
+type DeleteIndexesRequest struct {
+	Pattern string `form:"pattern"` // Glob pattern of index names, e.g. staging_*
+	Confirm bool   `form:"confirm"` // Must be true; without it the matching indexes are only reported
+}
+
+// DeleteIndexesHandler handles deleting every index matching a pattern in a single job. The
+// confirm parameter is mandatory, so that a mistyped pattern cannot delete indexes by accident;
+// without it the validation error lists the indexes the pattern matches.
+func (api *API) DeleteIndexesHandler(c *gin.Context) {
+	var req DeleteIndexesRequest
+	if result := ValidateQueryBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	deleter, ok := api.engine.(services.BulkIndexDeleter)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Bulk index deletion not supported by this engine")
+		return
+	}
+
+	names, err := deleter.MatchIndexes(req.Pattern)
+	if err != nil {
+		SendJobExecutionError(c, "delete indexes", err)
+		return
+	}
+	if !req.Confirm {
+		result := &ValidationResult{Valid: true}
+		result.AddError("confirm", fmt.Sprintf("Must be true to delete the %d indexes matching '%s': %s",
+			len(names), req.Pattern, strings.Join(names, ", ")))
+		SendValidationError(c, result)
+		return
+	}
+
+	jobID, names, err := deleter.DeleteIndexesAsync(req.Pattern)
+	if err != nil {
+		SendJobExecutionError(c, "delete indexes", err)
+		return
+	}
+	if jobID == "" {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "No index matches '" + req.Pattern + "'",
+			"indexes": names,
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "accepted",
+		"message": fmt.Sprintf("Deletion of %d indexes matching '%s' started", len(names), req.Pattern),
+		"job_id":  jobID,
+		"indexes": names,
+	})
+}
+
 // RenameIndexRequest defines the structure for renaming an index
 type RenameIndexRequest struct {
 	NewName string `json:"new_name" binding:"required"`
@@ -190,15 +412,48 @@ func (api *API) RenameIndexHandler(c *gin.Context) {
 
 // IndexSettingsUpdate defines the structure for updating index settings
 type IndexSettingsUpdate struct {
-	FieldsWithoutPrefixSearch *[]string                  `json:"fields_without_prefix_search,omitempty"` // Use []string, not *[]string, to allow sending an empty list to clear
-	NoTypoToleranceFields     *[]string                  `json:"no_typo_tolerance_fields,omitempty"`     // Use []string to allow sending an empty list to clear
-	NonTypoTolerantWords      *[]string                  `json:"non_typo_tolerant_words,omitempty"`      // Specific words that should never be typo-matched
-	DistinctField             *string                    `json:"distinct_field,omitempty"`               // Use pointer to distinguish between empty string and not provided
-	SearchableFields          *[]string                  `json:"searchable_fields,omitempty"`            // Fields that can be searched, in priority order
-	FilterableFields          *[]string                  `json:"filterable_fields,omitempty"`            // Fields that can be used in filters
-	RankingCriteria           *[]config.RankingCriterion `json:"ranking_criteria,omitempty"`             // Ranking criteria for search results
-	MinWordSizeFor1Typo       *int                       `json:"min_word_size_for_1_typo,omitempty"`     // Minimum word length to allow 1 typo
-	MinWordSizeFor2Typos      *int                       `json:"min_word_size_for_2_typos,omitempty"`    // Minimum word length to allow 2 typos
+	FieldsWithoutPrefixSearch *[]string                      `json:"fields_without_prefix_search,omitempty"` // Use []string, not *[]string, to allow sending an empty list to clear
+	NoTypoToleranceFields     *[]string                      `json:"no_typo_tolerance_fields,omitempty"`     // Use []string to allow sending an empty list to clear
+	NonTypoTolerantWords      *[]string                      `json:"non_typo_tolerant_words,omitempty"`      // Specific words that should never be typo-matched
+	TypoBudget                *config.TypoBudget             `json:"typo_budget,omitempty"`                  // Typo tolerance by searchable field priority; null disables it
+	NumberTypos               *bool                          `json:"number_typos,omitempty"`                 // Let all-digit query words match with typos
+	DistinctField             *string                        `json:"distinct_field,omitempty"`               // Use pointer to distinguish between empty string and not provided
+	SuggestionField           *string                        `json:"suggestion_field,omitempty"`             // Searchable field whose values _suggest completes; empty completes indexed words
+	SearchableFields          *[]string                      `json:"searchable_fields,omitempty"`            // Fields that can be searched, in priority order
+	FilterableFields          *[]string                      `json:"filterable_fields,omitempty"`            // Fields that can be used in filters
+	RankingCriteria           *[]config.RankingCriterion     `json:"ranking_criteria,omitempty"`             // Ranking criteria for search results
+	SortableFields            *[]string                      `json:"sortable_fields,omitempty"`              // Fields whose typed sort keys are built as documents are indexed
+	MinWordSizeFor1Typo       *int                           `json:"min_word_size_for_1_typo,omitempty"`     // Minimum word length to allow 1 typo
+	MinWordSizeFor2Typos      *int                           `json:"min_word_size_for_2_typos,omitempty"`    // Minimum word length to allow 2 typos
+	Scorer                    *string                        `json:"scorer,omitempty"`                       // Name of a custom scorer registered on the engine
+	ScoringAlgorithm          *config.ScoringAlgorithm       `json:"scoring_algorithm,omitempty"`            // How relevance scores are computed: "tf" or "bm25"
+	Metadata                  *config.IndexMetadata          `json:"metadata,omitempty"`                     // Description, owner and tags of the index
+	FieldWeights              *map[string]float64            `json:"field_weights,omitempty"`                // Score multiplier of matches in each searchable field
+	QueryTokenDecay           *float64                       `json:"query_token_decay,omitempty"`            // Weight of each query word relative to the word before it
+	FieldFormats              *map[string]config.FieldFormat `json:"field_formats,omitempty"`                // Locale and date format of string numbers and dates in filterable fields
+	Locale                    *string                        `json:"locale,omitempty"`                       // Language of the indexed content, selects the analyzer
+	ZeroResultFallbacks       *[]config.FallbackStrategy     `json:"zero_result_fallbacks,omitempty"`        // Strategies tried in order when a query returns no results
+	LanguageDetection         *config.LanguageDetection      `json:"language_detection,omitempty"`           // Language detection at ingest; null disables it
+	FilterScoreWeight         *float64                       `json:"filter_score_weight,omitempty"`          // Weight of the filter score added to the relevance score
+	DefaultPageSize           *int                           `json:"default_page_size,omitempty"`            // Hits per page of searches that do not set a page size
+	MaxPageSize               *int                           `json:"max_page_size,omitempty"`                // Largest page size a search can request
+	DefaultRetrievableFields  *[]string                      `json:"default_retrievable_fields,omitempty"`   // Document fields returned by searches that do not set retrievable_fields
+	ReadReplica               *config.ReadReplica            `json:"read_replica,omitempty"`                 // Serve searches from a copy refreshed with the writes; null disables it
+	DocumentCompression       *config.Compression            `json:"document_compression,omitempty"`         // Compress stored documents; null disables it
+	SegmentStorage            *config.SegmentStorage         `json:"segment_storage,omitempty"`              // Keep the inverted index in memory-mapped on-disk segments; null disables it
+	QuerySanitizer            *config.QuerySanitizer         `json:"query_sanitizer,omitempty"`              // Clean up raw user queries before tokenization; null disables it
+	StopWords                 *config.StopWords              `json:"stop_words,omitempty"`                   // Remove common words from fields and queries; null disables it
+	Synonyms                  *map[string]string             `json:"synonyms,omitempty"`                     // One-way replacements of query words
+	SpellCorrection           *config.SpellCorrection        `json:"spell_correction,omitempty"`             // Correct query words that are not in the index; null disables it
+	CacheWarming              *config.CacheWarming           `json:"cache_warming,omitempty"`                // Re-execute popular queries after writes; null disables it
+	SafeMode                  *config.SafeMode               `json:"safe_mode,omitempty"`                    // Revert later settings updates followed by failing or empty searches; null disables it
+	SearchLimits              *config.SearchLimits           `json:"search_limits,omitempty"`                // Bound the candidates and time a single search evaluates; null removes the limits
+	CompoundWords             *bool                          `json:"compound_words,omitempty"`               // Index hyphenated words joined as well as split, and keep contractions one word
+	WordCharacters            *string                        `json:"word_characters,omitempty"`              // Punctuation and symbols that are part of words instead of separating them
+	Stemming                  *bool                          `json:"stemming,omitempty"`                     // Index and search words by their stem
+	TextNormalization         *config.TextNormalization      `json:"text_normalization,omitempty"`           // Unicode normalization of text before tokenization: "fold_accents" or "keep_accents"
+	CJKFields                 *[]string                      `json:"cjk_fields,omitempty"`                   // Searchable fields whose CJK text is split into two-character words
+	GenerateDocumentIDs       *bool                          `json:"generate_document_ids,omitempty"`        // Assign a generated UUID to documents added without a documentID
 }
 
 // UpdateIndexSettingsHandler handles requests to update index settings
@@ -264,6 +519,22 @@ func (api *API) UpdateIndexSettingsHandler(c *gin.Context) {
 		updated = true
 	}
 
+	// Handle sortable_fields (search-time setting - sort keys are rebuilt from the stored documents)
+	if fieldValue, keyExists := rawRequest["sortable_fields"]; keyExists {
+		if fieldValue == nil {
+			settings.SortableFields = nil
+		} else if fieldSlice, isSlice := fieldValue.([]interface{}); isSlice {
+			stringSlice := make([]string, len(fieldSlice))
+			for i, v := range fieldSlice {
+				if str, isStr := v.(string); isStr {
+					stringSlice[i] = str
+				}
+			}
+			settings.SortableFields = stringSlice
+		}
+		updated = true
+	}
+
 	// Handle ranking_criteria (CORE SETTING - affects search results)
 	if fieldValue, keyExists := rawRequest["ranking_criteria"]; keyExists {
 		if fieldValue == nil {
@@ -279,6 +550,9 @@ func (api *API) UpdateIndexSettingsHandler(c *gin.Context) {
 					if order, hasOrder := criterionMap["order"].(string); hasOrder {
 						criterion.Order = order
 					}
+					if selector, hasSelector := criterionMap["selector"].(string); hasSelector {
+						criterion.Selector = config.RankingSelector(selector)
+					}
 					rankingCriteria[i] = criterion
 				}
 			}
@@ -362,6 +636,16 @@ func (api *API) UpdateIndexSettingsHandler(c *gin.Context) {
 		updated = true
 	}
 
+	// Handle number_typos (search-time setting)
+	if fieldValue, keyExists := rawRequest["number_typos"]; keyExists {
+		if fieldValue == nil {
+			settings.NumberTypos = false
+		} else if enabled, isBool := fieldValue.(bool); isBool {
+			settings.NumberTypos = enabled
+		}
+		updated = true
+	}
+
 	// Handle distinct_field (field-level setting)
 	if fieldValue, keyExists := rawRequest["distinct_field"]; keyExists {
 		if fieldValue == nil {
@@ -372,8 +656,504 @@ func (api *API) UpdateIndexSettingsHandler(c *gin.Context) {
 		updated = true
 	}
 
+	// Handle suggestion_field (search-time setting)
+	if fieldValue, keyExists := rawRequest["suggestion_field"]; keyExists {
+		if fieldValue == nil {
+			settings.SuggestionField = ""
+		} else if str, isStr := fieldValue.(string); isStr {
+			settings.SuggestionField = str
+		}
+		updated = true
+	}
+
+	// Handle generate_document_ids (ingestion setting)
+	if fieldValue, keyExists := rawRequest["generate_document_ids"]; keyExists {
+		if fieldValue == nil {
+			settings.GenerateDocumentIDs = false
+		} else if enabled, isBool := fieldValue.(bool); isBool {
+			settings.GenerateDocumentIDs = enabled
+		}
+		updated = true
+	}
+
+	// Handle scorer (search-time setting)
+	if fieldValue, keyExists := rawRequest["scorer"]; keyExists {
+		if fieldValue == nil {
+			settings.Scorer = ""
+		} else if str, isStr := fieldValue.(string); isStr {
+			settings.Scorer = str
+		}
+		updated = true
+	}
+
+	// Handle scoring_algorithm (search-time setting)
+	if fieldValue, keyExists := rawRequest["scoring_algorithm"]; keyExists {
+		if fieldValue == nil {
+			settings.ScoringAlgorithm = ""
+		} else if str, isStr := fieldValue.(string); isStr {
+			settings.ScoringAlgorithm = config.ScoringAlgorithm(str)
+		}
+		updated = true
+	}
+
+	// Handle field_weights (search-time setting)
+	if fieldValue, keyExists := rawRequest["field_weights"]; keyExists {
+		if fieldValue == nil {
+			settings.FieldWeights = nil
+		} else if weightsMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			weights := make(map[string]float64, len(weightsMap))
+			for field, v := range weightsMap {
+				if weight, isNumber := v.(float64); isNumber {
+					weights[field] = weight
+				}
+			}
+			settings.FieldWeights = weights
+		}
+		updated = true
+	}
+
+	// Handle field_formats (search-time setting)
+	if fieldValue, keyExists := rawRequest["field_formats"]; keyExists {
+		if fieldValue == nil {
+			settings.FieldFormats = nil
+		} else if formatsMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			formats := make(map[string]config.FieldFormat, len(formatsMap))
+			for field, v := range formatsMap {
+				if formatMap, isFormatMap := v.(map[string]interface{}); isFormatMap {
+					locale, _ := formatMap["locale"].(string)
+					dateFormat, _ := formatMap["date_format"].(string)
+					formats[field] = config.FieldFormat{Locale: locale, DateFormat: dateFormat}
+				}
+			}
+			settings.FieldFormats = formats
+		}
+		updated = true
+	}
+
+	// Handle locale (CORE SETTING - requires reindexing because it changes the analyzer)
+	if fieldValue, keyExists := rawRequest["locale"]; keyExists {
+		if fieldValue == nil {
+			settings.Locale = ""
+		} else if str, isStr := fieldValue.(string); isStr {
+			settings.Locale = str
+		}
+		if originalSettings.Locale != settings.Locale {
+			requiresReindexing = true
+		}
+		updated = true
+	}
+
+	// Handle zero_result_fallbacks (search-time setting)
+	if fieldValue, keyExists := rawRequest["zero_result_fallbacks"]; keyExists {
+		if fieldValue == nil {
+			settings.ZeroResultFallbacks = []config.FallbackStrategy{}
+		} else if fieldSlice, isSlice := fieldValue.([]interface{}); isSlice {
+			strategies := make([]config.FallbackStrategy, len(fieldSlice))
+			for i, v := range fieldSlice {
+				if str, isStr := v.(string); isStr {
+					strategies[i] = config.FallbackStrategy(str)
+				}
+			}
+			settings.ZeroResultFallbacks = strategies
+		}
+		updated = true
+	}
+
+	// Handle filter_score_weight (search-time setting)
+	if fieldValue, keyExists := rawRequest["filter_score_weight"]; keyExists {
+		if fieldValue == nil {
+			settings.FilterScoreWeight = 0
+		} else if weight, isNumber := fieldValue.(float64); isNumber {
+			settings.FilterScoreWeight = weight
+		}
+		updated = true
+	}
+
+	// Handle query_token_decay (search-time setting)
+	if fieldValue, keyExists := rawRequest["query_token_decay"]; keyExists {
+		if fieldValue == nil {
+			settings.QueryTokenDecay = 0
+		} else if decay, isNumber := fieldValue.(float64); isNumber {
+			settings.QueryTokenDecay = decay
+		}
+		updated = true
+	}
+
+	// Handle default_page_size and max_page_size (search-time settings)
+	if fieldValue, keyExists := rawRequest["default_page_size"]; keyExists {
+		if fieldValue == nil {
+			settings.DefaultPageSize = 0
+		} else if pageSize, isNumber := fieldValue.(float64); isNumber {
+			settings.DefaultPageSize = int(pageSize)
+		}
+		updated = true
+	}
+	if fieldValue, keyExists := rawRequest["max_page_size"]; keyExists {
+		if fieldValue == nil {
+			settings.MaxPageSize = 0
+		} else if pageSize, isNumber := fieldValue.(float64); isNumber {
+			settings.MaxPageSize = int(pageSize)
+		}
+		updated = true
+	}
+
+	// Handle default_retrievable_fields (search-time setting)
+	if fieldValue, keyExists := rawRequest["default_retrievable_fields"]; keyExists {
+		if fieldValue == nil {
+			settings.DefaultRetrievableFields = nil
+		} else if fieldSlice, isSlice := fieldValue.([]interface{}); isSlice {
+			stringSlice := make([]string, len(fieldSlice))
+			for i, v := range fieldSlice {
+				if str, isStr := v.(string); isStr {
+					stringSlice[i] = str
+				}
+			}
+			settings.DefaultRetrievableFields = stringSlice
+		}
+		updated = true
+	}
+
+	// Handle read_replica (search-time setting)
+	if fieldValue, keyExists := rawRequest["read_replica"]; keyExists {
+		if fieldValue == nil {
+			settings.ReadReplica = nil
+		} else if replicaMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			replica := &config.ReadReplica{}
+			if interval, isNumber := replicaMap["refresh_interval_ms"].(float64); isNumber {
+				replica.RefreshIntervalMs = int(interval)
+			}
+			settings.ReadReplica = replica
+		}
+		updated = true
+	}
+
+	// Handle cache_warming (search-time setting)
+	if fieldValue, keyExists := rawRequest["cache_warming"]; keyExists {
+		if fieldValue == nil {
+			settings.CacheWarming = nil
+		} else if warmingMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			warming := &config.CacheWarming{}
+			if topQueries, isNumber := warmingMap["top_queries"].(float64); isNumber {
+				warming.TopQueries = int(topQueries)
+			}
+			if interval, isNumber := warmingMap["interval_ms"].(float64); isNumber {
+				warming.IntervalMs = int(interval)
+			}
+			if maxQPS, isNumber := warmingMap["max_qps"].(float64); isNumber {
+				warming.MaxQPS = maxQPS
+			}
+			if windowHours, isNumber := warmingMap["window_hours"].(float64); isNumber {
+				warming.WindowHours = int(windowHours)
+			}
+			settings.CacheWarming = warming
+		}
+		updated = true
+	}
+
+	// Handle safe_mode (search-time setting: it applies to the settings updates after this one)
+	if fieldValue, keyExists := rawRequest["safe_mode"]; keyExists {
+		if fieldValue == nil {
+			settings.SafeMode = nil
+		} else if safeModeMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			safeMode := &config.SafeMode{}
+			if window, isNumber := safeModeMap["window_ms"].(float64); isNumber {
+				safeMode.WindowMs = int(window)
+			}
+			if minSearches, isNumber := safeModeMap["min_searches"].(float64); isNumber {
+				safeMode.MinSearches = int(minSearches)
+			}
+			if maxErrorRate, isNumber := safeModeMap["max_error_rate"].(float64); isNumber {
+				safeMode.MaxErrorRate = maxErrorRate
+			}
+			if maxZeroResultRate, isNumber := safeModeMap["max_zero_result_rate"].(float64); isNumber {
+				safeMode.MaxZeroResultRate = maxZeroResultRate
+			}
+			if webhookURL, isString := safeModeMap["webhook_url"].(string); isString {
+				safeMode.WebhookURL = webhookURL
+			}
+			settings.SafeMode = safeMode
+		}
+		updated = true
+	}
+
+	// Handle search_limits (search-time setting)
+	if fieldValue, keyExists := rawRequest["search_limits"]; keyExists {
+		if fieldValue == nil {
+			settings.SearchLimits = nil
+		} else if limitsMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			limits := &config.SearchLimits{}
+			if maxCandidates, isNumber := limitsMap["max_candidates"].(float64); isNumber {
+				limits.MaxCandidates = int(maxCandidates)
+			}
+			if timeLimit, isNumber := limitsMap["time_limit_ms"].(float64); isNumber {
+				limits.TimeLimitMs = int(timeLimit)
+			}
+			settings.SearchLimits = limits
+		}
+		updated = true
+	}
+
+	// Handle document_compression (search-time setting: stored documents are converted in place)
+	if fieldValue, keyExists := rawRequest["document_compression"]; keyExists {
+		if fieldValue == nil {
+			settings.DocumentCompression = nil
+		} else if compressionMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			compression := &config.Compression{}
+			if algorithm, isString := compressionMap["algorithm"].(string); isString {
+				compression.Algorithm = algorithm
+			}
+			if minBytes, isNumber := compressionMap["min_document_bytes"].(float64); isNumber {
+				compression.MinDocumentBytes = int(minBytes)
+			}
+			if cacheSize, isNumber := compressionMap["cache_size"].(float64); isNumber {
+				compression.CacheSize = int(cacheSize)
+			}
+			settings.DocumentCompression = compression
+		}
+		updated = true
+	}
+
+	// Handle segment_storage (search-time setting: the inverted index moves to or from disk when persisted)
+	if fieldValue, keyExists := rawRequest["segment_storage"]; keyExists {
+		if fieldValue == nil {
+			settings.SegmentStorage = nil
+		} else if storageMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			storage := &config.SegmentStorage{}
+			if maxSegments, isNumber := storageMap["max_segments"].(float64); isNumber {
+				storage.MaxSegments = int(maxSegments)
+			}
+			settings.SegmentStorage = storage
+		}
+		updated = true
+	}
+
+	// Handle typo_budget (search-time setting)
+	if fieldValue, keyExists := rawRequest["typo_budget"]; keyExists {
+		if fieldValue == nil {
+			settings.TypoBudget = nil
+		} else if budgetMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			budget := &config.TypoBudget{}
+			if twoTypoFields, isNumber := budgetMap["two_typo_fields"].(float64); isNumber {
+				budget.TwoTypoFields = int(twoTypoFields)
+			}
+			if oneTypoFields, isNumber := budgetMap["one_typo_fields"].(float64); isNumber {
+				budget.OneTypoFields = int(oneTypoFields)
+			}
+			settings.TypoBudget = budget
+		}
+		updated = true
+	}
+
+	// Handle metadata (no effect on indexing or search)
+	if fieldValue, keyExists := rawRequest["metadata"]; keyExists {
+		if fieldValue == nil {
+			settings.Metadata = nil
+		} else if metadataMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			metadata := &config.IndexMetadata{}
+			if description, isStr := metadataMap["description"].(string); isStr {
+				metadata.Description = description
+			}
+			if owner, isStr := metadataMap["owner"].(string); isStr {
+				metadata.Owner = owner
+			}
+			if tagSlice, isSlice := metadataMap["tags"].([]interface{}); isSlice {
+				for _, v := range tagSlice {
+					if str, isStr := v.(string); isStr {
+						metadata.Tags = append(metadata.Tags, str)
+					}
+				}
+			}
+			settings.Metadata = metadata
+		}
+		updated = true
+	}
+
+	// Handle query_sanitizer (search-time setting)
+	if fieldValue, keyExists := rawRequest["query_sanitizer"]; keyExists {
+		if fieldValue == nil {
+			settings.QuerySanitizer = nil
+		} else if sanitizerMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			sanitizer := &config.QuerySanitizer{}
+			if maxLength, isNumber := sanitizerMap["max_query_length"].(float64); isNumber {
+				sanitizer.MaxQueryLength = int(maxLength)
+			}
+			if maxRepeated, isNumber := sanitizerMap["max_repeated_characters"].(float64); isNumber {
+				sanitizer.MaxRepeatedCharacters = int(maxRepeated)
+			}
+			if emojiMap, isMap := sanitizerMap["emoji_tokens"].(map[string]interface{}); isMap {
+				sanitizer.EmojiTokens = make(map[string]string, len(emojiMap))
+				for emoji, token := range emojiMap {
+					if str, isStr := token.(string); isStr {
+						sanitizer.EmojiTokens[emoji] = str
+					}
+				}
+			}
+			settings.QuerySanitizer = sanitizer
+		}
+		updated = true
+	}
+
+	// Handle synonyms (search-time setting)
+	if fieldValue, keyExists := rawRequest["synonyms"]; keyExists {
+		if fieldValue == nil {
+			settings.Synonyms = nil
+		} else if synonymsMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			synonyms := make(map[string]string, len(synonymsMap))
+			for term, v := range synonymsMap {
+				if replacement, isStr := v.(string); isStr {
+					synonyms[term] = replacement
+				}
+			}
+			settings.Synonyms = synonyms
+		}
+		updated = true
+	}
+
+	// Handle spell_correction (search-time setting)
+	if fieldValue, keyExists := rawRequest["spell_correction"]; keyExists {
+		if fieldValue == nil {
+			settings.SpellCorrection = nil
+		} else if correctionMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			correction := &config.SpellCorrection{}
+			if maxDistance, isNumber := correctionMap["max_distance"].(float64); isNumber {
+				correction.MaxDistance = int(maxDistance)
+			}
+			if minWordLength, isNumber := correctionMap["min_word_length"].(float64); isNumber {
+				correction.MinWordLength = int(minWordLength)
+			}
+			settings.SpellCorrection = correction
+		}
+		updated = true
+	}
+
+	// Handle stop_words (CORE SETTING - requires reindexing because it changes the indexed words)
+	if fieldValue, keyExists := rawRequest["stop_words"]; keyExists {
+		if fieldValue == nil {
+			settings.StopWords = nil
+		} else if stopWordsMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			stopWords := &config.StopWords{}
+			if wordSlice, isSlice := stopWordsMap["words"].([]interface{}); isSlice {
+				for _, v := range wordSlice {
+					if str, isStr := v.(string); isStr {
+						stopWords.Words = append(stopWords.Words, str)
+					}
+				}
+			}
+			if keep, isBool := stopWordsMap["keep_in_phrases"].(bool); isBool {
+				stopWords.KeepInPhrases = keep
+			}
+			settings.StopWords = stopWords
+		}
+		if !stopWordsEqual(originalSettings.StopWords, settings.StopWords) {
+			requiresReindexing = true
+		}
+		updated = true
+	}
+
+	// Handle compound_words (CORE SETTING - requires reindexing because it changes the indexed words)
+	if fieldValue, keyExists := rawRequest["compound_words"]; keyExists {
+		if fieldValue == nil {
+			settings.CompoundWords = false
+		} else if enabled, isBool := fieldValue.(bool); isBool {
+			settings.CompoundWords = enabled
+		}
+		if originalSettings.CompoundWords != settings.CompoundWords {
+			requiresReindexing = true
+		}
+		updated = true
+	}
+
+	// Handle word_characters (CORE SETTING - requires reindexing because it changes the indexed words)
+	if fieldValue, keyExists := rawRequest["word_characters"]; keyExists {
+		if fieldValue == nil {
+			settings.WordCharacters = ""
+		} else if characters, isString := fieldValue.(string); isString {
+			settings.WordCharacters = characters
+		}
+		if originalSettings.WordCharacters != settings.WordCharacters {
+			requiresReindexing = true
+		}
+		updated = true
+	}
+
+	// Handle stemming (CORE SETTING - requires reindexing because it changes the indexed words)
+	if fieldValue, keyExists := rawRequest["stemming"]; keyExists {
+		if fieldValue == nil {
+			settings.Stemming = false
+		} else if enabled, isBool := fieldValue.(bool); isBool {
+			settings.Stemming = enabled
+		}
+		if originalSettings.Stemming != settings.Stemming {
+			requiresReindexing = true
+		}
+		updated = true
+	}
+
+	// Handle text_normalization (CORE SETTING - requires reindexing because it changes the indexed words)
+	if fieldValue, keyExists := rawRequest["text_normalization"]; keyExists {
+		if fieldValue == nil {
+			settings.TextNormalization = ""
+		} else if str, isString := fieldValue.(string); isString {
+			settings.TextNormalization = config.TextNormalization(str)
+		}
+		if originalSettings.TextNormalization != settings.TextNormalization {
+			requiresReindexing = true
+		}
+		updated = true
+	}
+
+	// Handle cjk_fields (CORE SETTING - requires reindexing because it changes the indexed words)
+	if fieldValue, keyExists := rawRequest["cjk_fields"]; keyExists {
+		if fieldValue == nil {
+			settings.CJKFields = nil
+		} else if fieldSlice, isSlice := fieldValue.([]interface{}); isSlice {
+			stringSlice := make([]string, len(fieldSlice))
+			for i, v := range fieldSlice {
+				if str, isStr := v.(string); isStr {
+					stringSlice[i] = str
+				}
+			}
+			settings.CJKFields = stringSlice
+		}
+		if !slicesEqual(originalSettings.CJKFields, settings.CJKFields) {
+			requiresReindexing = true
+		}
+		updated = true
+	}
+
+	// Handle language_detection (CORE SETTING - requires reindexing because documents are routed again)
+	if fieldValue, keyExists := rawRequest["language_detection"]; keyExists {
+		if fieldValue == nil {
+			settings.LanguageDetection = nil
+		} else if detectionMap, isMap := fieldValue.(map[string]interface{}); isMap {
+			detection := &config.LanguageDetection{Fields: []string{}, Languages: []string{}}
+			if fieldSlice, isSlice := detectionMap["fields"].([]interface{}); isSlice {
+				for _, v := range fieldSlice {
+					if str, isStr := v.(string); isStr {
+						detection.Fields = append(detection.Fields, str)
+					}
+				}
+			}
+			if languageSlice, isSlice := detectionMap["languages"].([]interface{}); isSlice {
+				for _, v := range languageSlice {
+					if str, isStr := v.(string); isStr {
+						detection.Languages = append(detection.Languages, str)
+					}
+				}
+			}
+			if languageField, isStr := detectionMap["language_field"].(string); isStr {
+				detection.LanguageField = languageField
+			}
+			settings.LanguageDetection = detection
+		}
+		if !languageDetectionEqual(originalSettings.LanguageDetection, settings.LanguageDetection) {
+			requiresReindexing = true
+		}
+		updated = true
+	}
+
 	if !updated {
-		SendError(c, http.StatusBadRequest, ErrorCodeInvalidRequest, "No valid updatable fields provided or no changes detected")
+		SendError(c, ErrorCodeInvalidRequest, "No valid updatable fields provided or no changes detected")
 		return
 	}
 
@@ -386,7 +1166,7 @@ func (api *API) UpdateIndexSettingsHandler(c *gin.Context) {
 				Code:    "FIELD_VALIDATION_ERROR",
 			}
 		}
-		SendError(c, http.StatusBadRequest, ErrorCodeValidationFailed, "Field name validation failed", details...)
+		SendError(c, ErrorCodeValidationFailed, "Field name validation failed", details...)
 		return
 	}
 
@@ -435,12 +1215,26 @@ func (api *API) GetIndexStatsHandler(c *gin.Context) {
 
 	settings := indexAccessor.Settings()
 
-	// Get document count from the document store
+	// Get document count from the document store and typo expansion metrics from the searcher
 	documentCount := 0
+	var typoStats model.TypoStats
+	var replicaStats *model.ReadReplicaStats
+	var compressionStats *model.DocumentCompressionStats
+	var segmentStats *model.SegmentStorageStats
+	var warmingStats *model.CacheWarmingStats
+	var safeModeStats *model.SafeModeStats
+	var fieldStats map[string]model.FieldStats
 	if concreteEngine, ok := api.engine.(*engine.Engine); ok {
 		if instance, err := concreteEngine.GetIndex(indexName); err == nil {
 			if engineInstance, ok := instance.(*engine.IndexInstance); ok {
-				documentCount = len(engineInstance.DocumentStore.Docs)
+				documentCount = engineInstance.DocumentStore.Len()
+				typoStats = engineInstance.TypoStats()
+				replicaStats = engineInstance.ReadReplicaStats()
+				compressionStats = engineInstance.DocumentStore.CompressionStats()
+				segmentStats = engineInstance.SegmentStorageStats()
+				warmingStats = engineInstance.CacheWarmingStats()
+				safeModeStats = engineInstance.SafeModeStats()
+				fieldStats = engineInstance.FieldStats()
 			}
 		}
 	}
@@ -454,12 +1248,29 @@ func (api *API) GetIndexStatsHandler(c *gin.Context) {
 			"min_word_size_for_1_typo":  settings.MinWordSizeFor1Typo,
 			"min_word_size_for_2_typos": settings.MinWordSizeFor2Typos,
 		},
+		"typo_stats":  typoStats,
+		"field_stats": fieldStats,
 		"field_settings": gin.H{
 			"fields_without_prefix_search": settings.FieldsWithoutPrefixSearch,
 			"no_typo_tolerance_fields":     settings.NoTypoToleranceFields,
 			"distinct_field":               settings.DistinctField,
 		},
 	}
+	if replicaStats != nil {
+		stats["read_replica"] = replicaStats
+	}
+	if compressionStats != nil {
+		stats["document_compression"] = compressionStats
+	}
+	if segmentStats != nil {
+		stats["segment_storage"] = segmentStats
+	}
+	if warmingStats != nil {
+		stats["cache_warming"] = warmingStats
+	}
+	if safeModeStats != nil {
+		stats["safe_mode"] = safeModeStats
+	}
 
 	c.JSON(http.StatusOK, stats)
 }
@@ -483,9 +1294,25 @@ func rankingCriteriaEqual(a, b []config.RankingCriterion) bool {
 		return false
 	}
 	for i := range a {
-		if a[i].Field != b[i].Field || a[i].Order != b[i].Order {
+		if a[i].Field != b[i].Field || a[i].Order != b[i].Order || a[i].Selector != b[i].Selector {
 			return false
 		}
 	}
 	return true
 }
+
+// Helper function to compare language detection settings
+func languageDetectionEqual(a, b *config.LanguageDetection) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return slicesEqual(a.Fields, b.Fields) && slicesEqual(a.Languages, b.Languages) && a.LanguageFieldName() == b.LanguageFieldName()
+}
+
+// Helper function to compare stop-word settings
+func stopWordsEqual(a, b *config.StopWords) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return slicesEqual(a.List(), b.List()) && a.KeepInPhrases == b.KeepInPhrases
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// CloseIndexHandler handles closing an index: it is persisted and unloaded from memory, keeping its
+// files on disk, until it is opened again explicitly or by the next request that needs it.
+func (api *API) CloseIndexHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	closer, ok := api.indexCloser(c)
+	if !ok {
+		return
+	}
+
+	if err := closer.CloseIndex(indexName); err != nil {
+		sendIndexStateError(c, indexName, "close index", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Index '" + indexName + "' closed",
+		"index_name": indexName,
+		"state":      model.IndexStateClosed,
+	})
+}
+
+// OpenIndexHandler handles loading a closed index back into memory. Opening an open index does
+// nothing.
+func (api *API) OpenIndexHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	closer, ok := api.indexCloser(c)
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	opened, err := closer.OpenIndex(indexName)
+	if err != nil {
+		sendIndexStateError(c, indexName, "open index", err)
+		return
+	}
+
+	message := "Index '" + indexName + "' is already open"
+	if opened {
+		message = "Index '" + indexName + "' opened"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    message,
+		"index_name": indexName,
+		"state":      model.IndexStateOpen,
+		"opened":     opened,
+		"took_ms":    time.Since(start).Milliseconds(),
+	})
+}
+
+// indexCloser returns the engine's index open and close operations, or sends an error if the
+// engine does not support them.
+func (api *API) indexCloser(c *gin.Context) (services.IndexCloser, bool) {
+	closer, ok := api.engine.(services.IndexCloser)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Closing indexes not supported by this engine")
+	}
+	return closer, ok
+}
+
+// sendIndexStateError maps errors of opening and closing indexes to API error responses.
+func sendIndexStateError(c *gin.Context, indexName, operation string, err error) {
+	switch {
+	case errors.Is(err, internalErrors.ErrIndexNotFound):
+		SendIndexNotFoundError(c, indexName)
+	case errors.Is(err, internalErrors.ErrIndexBusy):
+		SendError(c, ErrorCodeIndexBusy, err.Error())
+	default:
+		SendInternalError(c, operation, err)
+	}
+}
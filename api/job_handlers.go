@@ -3,6 +3,8 @@ package api
 import (
 	"errors"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -29,7 +31,7 @@ func (api *API) GetJobHandler(c *gin.Context) {
 
 		c.JSON(http.StatusOK, job)
 	} else {
-		SendError(c, http.StatusNotImplemented, ErrorCodeInternalError, "Job management not supported by this engine")
+		SendError(c, ErrorCodeNotImplemented, "Job management not supported by this engine")
 	}
 }
 
@@ -52,10 +54,149 @@ func (api *API) ListJobsHandler(c *gin.Context) {
 			"total":      len(jobs),
 		})
 	} else {
-		SendError(c, http.StatusNotImplemented, ErrorCodeInternalError, "Job management not supported by this engine")
+		SendError(c, ErrorCodeNotImplemented, "Job management not supported by this engine")
 	}
 }
 
+// JobListRequest defines the selection and pagination of job listings
+type JobListRequest struct {
+	Status   string `form:"status"`    // Only jobs with the status
+	Type     string `form:"type"`      // Only jobs of the type
+	Index    string `form:"index"`     // Only jobs of the index
+	Page     int    `form:"page"`      // Defaults to 1
+	PageSize int    `form:"page_size"` // Defaults to 10, at most 100
+}
+
+// jobStatuses are the statuses jobs can be listed by
+var jobStatuses = []model.JobStatus{
+	model.JobStatusPending,
+	model.JobStatusRunning,
+	model.JobStatusCompleted,
+	model.JobStatusFailed,
+	model.JobStatusCancelled,
+}
+
+// ListAllJobsHandler handles requests to list the jobs of all indexes, newest first
+func (api *API) ListAllJobsHandler(c *gin.Context) {
+	var req JobListRequest
+	if result := ValidateQueryBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+	result := &ValidationResult{Valid: true}
+	if req.Status != "" && !slices.Contains(jobStatuses, model.JobStatus(req.Status)) {
+		result.AddError("status", "Status must be one of pending, running, completed, failed or cancelled")
+	}
+	if req.Page < 0 {
+		result.AddError("page", "Page number must be greater than 0")
+	}
+	if req.PageSize < 0 {
+		result.AddError("page_size", "Page size must be greater than 0")
+	}
+	if result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	controller, ok := api.jobController(c)
+	if !ok {
+		return
+	}
+
+	jobs := controller.ListAllJobs(model.JobFilter{
+		IndexName: req.Index,
+		Status:    model.JobStatus(req.Status),
+		Type:      model.JobType(req.Type),
+	})
+	total := len(jobs)
+	page, pageSize, _ := ValidatePagination(req.Page, req.PageSize)
+	start := min((page-1)*pageSize, total)
+	jobs = jobs[start:min(start+pageSize, total)]
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":      jobs,
+		"count":     len(jobs),
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"pages":     (total + pageSize - 1) / pageSize,
+	})
+}
+
+// CancelJobHandler handles requests to cancel a job. Pending jobs are cancelled at once; running
+// jobs are asked to stop, which they do at the next point where the index is left consistent, so
+// the response is 202 Accepted and the job is polled for its final status.
+func (api *API) CancelJobHandler(c *gin.Context) {
+	jobID := c.Param("jobId")
+
+	controller, ok := api.jobController(c)
+	if !ok {
+		return
+	}
+
+	job, err := controller.CancelJob(jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, internalErrors.ErrJobNotFound):
+			SendJobNotFoundError(c, jobID)
+		case errors.Is(err, internalErrors.ErrJobFinished):
+			SendError(c, ErrorCodeJobFinished, err.Error())
+		default:
+			SendInternalError(c, "cancel job", err)
+		}
+		return
+	}
+
+	if !job.IsFinished() {
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// CleanupJobsHandler handles requests to remove the records of finished jobs, those that finished
+// more than older_than ago if it is set. Pending and running jobs are kept.
+func (api *API) CleanupJobsHandler(c *gin.Context) {
+	result := &ValidationResult{Valid: true}
+	var olderThan time.Duration
+	if param := c.Query("older_than"); param != "" {
+		parsed, err := time.ParseDuration(param)
+		switch {
+		case err != nil:
+			result.AddError("older_than", "Older than must be a duration such as 1h or 24h")
+		case parsed < 0:
+			result.AddError("older_than", "Older than must not be negative")
+		default:
+			olderThan = parsed
+		}
+	}
+	if result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	controller, ok := api.jobController(c)
+	if !ok {
+		return
+	}
+
+	removed := controller.CleanupJobs(olderThan)
+	c.JSON(http.StatusOK, gin.H{
+		"removed":    removed,
+		"older_than": olderThan.String(),
+	})
+}
+
+// jobController returns the engine's job listing, cancellation and cleanup, or sends an error if
+// the engine does not support them.
+func (api *API) jobController(c *gin.Context) (services.JobController, bool) {
+	controller, ok := api.engine.(services.JobController)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Job management not supported by this engine")
+	}
+	return controller, ok
+}
+
 // GetJobMetricsHandler handles requests to get job performance metrics
 func (api *API) GetJobMetricsHandler(c *gin.Context) {
 	if engineWithMetrics, ok := api.engine.(*engine.Engine); ok {
@@ -71,6 +212,6 @@ func (api *API) GetJobMetricsHandler(c *gin.Context) {
 
 		c.JSON(http.StatusOK, response)
 	} else {
-		SendError(c, http.StatusNotImplemented, ErrorCodeInternalError, "Job metrics not supported by this engine")
+		SendError(c, ErrorCodeNotImplemented, "Job metrics not supported by this engine")
 	}
 }
@@ -1,14 +1,31 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/logging"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
 )
 
-// RequestSizeLimitMiddleware limits the size of request bodies to prevent memory exhaustion
-func RequestSizeLimitMiddleware(maxSize int64) gin.HandlerFunc {
+// bulkIngestRoute streams its request body, so its size is not limited
+const bulkIngestRoute = "/indexes/:indexName/documents/_bulk"
+
+// RequestSizeLimitMiddleware limits the size of request bodies to prevent memory exhaustion.
+// Routes that read their body as a stream can be exempted.
+func RequestSizeLimitMiddleware(maxSize int64, streamingRoutes ...string) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
+		if slices.Contains(streamingRoutes, c.FullPath()) {
+			c.Next()
+			return
+		}
 		// Limit request body size
 		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
 		c.Next()
@@ -20,7 +37,7 @@ func CORSMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -30,3 +47,102 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Next()
 	})
 }
+
+// RenameAliasMiddleware routes requests addressed to the old name of a recently renamed index to
+// its new name during the engine's grace period. Such responses carry Deprecation, Sunset and
+// Link headers pointing clients to the new name.
+func RenameAliasMiddleware(engine services.IndexManager) gin.HandlerFunc {
+	resolver, ok := engine.(services.RenameAliasResolver)
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if !ok {
+			c.Next()
+			return
+		}
+
+		for i, param := range c.Params {
+			if param.Key != "indexName" {
+				continue
+			}
+			newName, expiresAt, renamed := resolver.ResolveRenamedIndex(param.Value)
+			if !renamed {
+				break
+			}
+			c.Params[i].Value = newName
+			c.Header("Deprecation", "true")
+			c.Header("Sunset", expiresAt.UTC().Format(http.TimeFormat))
+			c.Header("Link", fmt.Sprintf("</indexes/%s>; rel=\"successor-version\"", newName))
+			c.Writer.Header().Add("Access-Control-Expose-Headers", "Deprecation, Sunset, Link")
+			break
+		}
+
+		c.Next()
+	})
+}
+
+// IndexAliasMiddleware routes requests addressed to an index alias to the index it points to. The
+// alias is resolved once per request, so a request runs entirely against one index even when the
+// alias is moved meanwhile. Responses name the resolved index in the X-Index-Name header.
+func IndexAliasMiddleware(engine services.IndexManager) gin.HandlerFunc {
+	resolver, ok := engine.(services.AliasManager)
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if !ok {
+			c.Next()
+			return
+		}
+
+		for i, param := range c.Params {
+			if param.Key != "indexName" {
+				continue
+			}
+			if indexName, aliased := resolver.ResolveAlias(param.Value); aliased {
+				c.Params[i].Value = indexName
+				c.Header("X-Index-Name", indexName)
+				c.Writer.Header().Add("Access-Control-Expose-Headers", "X-Index-Name")
+			}
+			break
+		}
+
+		c.Next()
+	})
+}
+
+// IndexAutoOpenMiddleware opens a closed index on the first request that needs it, so closing an
+// index frees its memory without making it unavailable. Reading the index from disk makes that
+// request slower, so the delay is logged as a warning and sent to the client in a Warning header.
+// Requests to the exempt routes, such as getting the settings of an index, leave it closed.
+func IndexAutoOpenMiddleware(engine services.IndexManager, exemptRoutes ...string) gin.HandlerFunc {
+	closer, ok := engine.(services.IndexCloser)
+	aliasResolver, _ := engine.(services.AliasManager)
+	return gin.HandlerFunc(func(c *gin.Context) {
+		indexName := c.Param("indexName")
+		if !ok || indexName == "" || slices.Contains(exemptRoutes, c.FullPath()) {
+			c.Next()
+			return
+		}
+		// Routes accepting an alias resolve it later; the index it points to is opened here
+		if aliasResolver != nil {
+			if target, aliased := aliasResolver.ResolveAlias(indexName); aliased {
+				indexName = target
+			}
+		}
+		if state, err := closer.IndexState(indexName); err != nil || state != model.IndexStateClosed {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		opened, err := closer.OpenIndex(indexName)
+		if err != nil && !errors.Is(err, internalErrors.ErrIndexNotFound) {
+			SendInternalError(c, "open index", err)
+			c.Abort()
+			return
+		}
+		if opened {
+			took := time.Since(start).Milliseconds()
+			logging.FromContext(c.Request.Context()).Warn("closed index opened on demand", "index", indexName, "took_ms", took)
+			c.Header("Warning", fmt.Sprintf("199 - \"index '%s' was closed and opened for this request in %dms\"", indexName, took))
+			c.Writer.Header().Add("Access-Control-Expose-Headers", "Warning")
+		}
+		c.Next()
+	})
+}
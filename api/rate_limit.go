@@ -0,0 +1,158 @@
+package api
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// rateLimitSweepInterval is how often buckets refilled to their burst are dropped, so clients that
+// stopped sending requests do not hold memory
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit is a token bucket budget: each client may send Burst requests at once, and then Rate
+// requests per second. A zero Rate disables the limit.
+type RateLimit struct {
+	Rate  float64 // Requests per second
+	Burst int     // Requests at once; defaults to Rate rounded up, and at least 1
+}
+
+// RateLimits are the budgets of each client. Search covers searches and document reads; Indexing
+// covers document and index writes. Other routes are not limited.
+type RateLimits struct {
+	Search   RateLimit
+	Indexing RateLimit
+}
+
+// rateBudgets maps the actions of routes to the budget they draw from
+var rateBudgets = map[model.APIKeyAction]string{
+	model.APIKeyActionSearch:         "search",
+	model.APIKeyActionDocumentsRead:  "search",
+	model.APIKeyActionDocumentsWrite: "indexing",
+	model.APIKeyActionIndexesWrite:   "indexing",
+}
+
+// tokenBucket holds the requests a client has left, as of updated
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimiter keeps a token bucket per client for one budget.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// newRateLimiter creates the limiter of a budget, or returns nil when the budget is disabled.
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = max(int(math.Ceil(limit.Rate)), 1)
+	}
+	return &rateLimiter{
+		rate:      limit.Rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// allow takes a token from a client's bucket. It reports the tokens left, how long until the bucket
+// is full again and, when no token was left, how long until one is.
+func (l *rateLimiter) allow(client string) (remaining int, reset, retryAfter time.Duration, allowed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		for id, bucket := range l.buckets {
+			if l.refill(bucket, now) >= l.burst {
+				delete(l.buckets, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, exists := l.buckets[client]
+	if !exists {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[client] = bucket
+	}
+	tokens := l.refill(bucket, now)
+	if tokens >= 1 {
+		tokens--
+		allowed = true
+	} else {
+		retryAfter = l.duration(1 - tokens)
+	}
+	bucket.tokens, bucket.updated = tokens, now
+	return int(tokens), l.duration(l.burst - tokens), retryAfter, allowed
+}
+
+// refill returns the tokens of a bucket at a time, without changing it.
+func (l *rateLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	return math.Min(l.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate)
+}
+
+// duration returns how long the bucket takes to gain tokens.
+func (l *rateLimiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// RateLimitMiddleware limits the requests of each client with a token bucket per budget. Clients
+// are told apart by the API key they were authorized with, or else by their IP address, so the
+// middleware runs after AuthMiddleware. Limited responses carry RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers; requests over the limit get a 429 with a Retry-After header.
+func RateLimitMiddleware(limits RateLimits) gin.HandlerFunc {
+	limiters := map[string]*rateLimiter{
+		"search":   newRateLimiter(limits.Search),
+		"indexing": newRateLimiter(limits.Indexing),
+	}
+	return gin.HandlerFunc(func(c *gin.Context) {
+		budget := rateBudgets[routeActions[c.Request.Method+" "+c.FullPath()]]
+		limiter := limiters[budget]
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		client := "ip:" + c.ClientIP()
+		if value, exists := c.Get(apiKeyContextKey); exists {
+			if key, ok := value.(model.APIKey); ok {
+				client = "key:" + key.ID
+			}
+		}
+
+		remaining, reset, retryAfter, allowed := limiter.allow(client)
+		c.Header("RateLimit-Limit", strconv.Itoa(int(limiter.burst)))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(reset)))
+		c.Writer.Header().Add("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(seconds(retryAfter)))
+			SendError(c, ErrorCodeRateLimited, "Rate limit of "+budget+" requests exceeded; retry in "+strconv.Itoa(seconds(retryAfter))+"s")
+			c.Abort()
+			return
+		}
+		c.Next()
+	})
+}
+
+// seconds rounds a duration up to whole seconds, as rate limit headers count them.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SetRelevanceTestsRequest defines the relevance tests that replace those of an index
+type SetRelevanceTestsRequest struct {
+	Tests []model.RelevanceTest `json:"tests"`
+}
+
+// GetRelevanceTestsHandler handles listing the relevance tests of an index.
+func (api *API) GetRelevanceTestsHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	relevanceTester, ok := api.relevanceTester(c)
+	if !ok {
+		return
+	}
+
+	tests, err := relevanceTester.GetRelevanceTests(indexName)
+	if err != nil {
+		sendRelevanceError(c, indexName, "get relevance tests", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tests": tests, "total": len(tests)})
+}
+
+// SetRelevanceTestsHandler handles replacing the relevance tests of an index, e.g. with fixtures
+// kept next to the settings and rules they check.
+func (api *API) SetRelevanceTestsHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	relevanceTester, ok := api.relevanceTester(c)
+	if !ok {
+		return
+	}
+
+	var req SetRelevanceTestsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendInvalidJSONError(c, err)
+		return
+	}
+
+	tests, err := relevanceTester.SetRelevanceTests(indexName, req.Tests)
+	if err != nil {
+		sendRelevanceError(c, indexName, "set relevance tests", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tests": tests, "total": len(tests)})
+}
+
+// RunRelevanceTestsHandler handles running the relevance tests of an index. The response is sent
+// with 200 OK whether or not the tests passed; its passed field is what a deployment gate checks.
+func (api *API) RunRelevanceTestsHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	relevanceTester, ok := api.relevanceTester(c)
+	if !ok {
+		return
+	}
+
+	report, err := relevanceTester.RunRelevanceTests(indexName)
+	if err != nil {
+		sendRelevanceError(c, indexName, "run relevance tests", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// relevanceTester returns the engine's relevance test operations, or sends an error if the engine does not support them.
+func (api *API) relevanceTester(c *gin.Context) (services.RelevanceTester, bool) {
+	relevanceTester, ok := api.engine.(services.RelevanceTester)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Relevance tests not supported by this engine")
+	}
+	return relevanceTester, ok
+}
+
+// sendRelevanceError maps relevance test errors to API error responses.
+func sendRelevanceError(c *gin.Context, indexName, operation string, err error) {
+	var validationErr *internalErrors.ValidationError
+	switch {
+	case errors.Is(err, internalErrors.ErrIndexNotFound):
+		SendIndexNotFoundError(c, indexName)
+	case errors.As(err, &validationErr):
+		SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+	default:
+		SendInternalError(c, operation, err)
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gcbaptista/go-search-engine/internal/logging"
+	"github.com/gcbaptista/go-search-engine/internal/tracing"
+)
+
+// Gin context keys of the IDs a request is traced by
+const (
+	requestIDContextKey = "request_id" // ID of the request, sent back in the X-Request-ID header
+	queryIDContextKey   = "query_id"   // ID of the search a search request ran, as in its response
+)
+
+// requestIDHeader carries the ID of a request, set by the client or a proxy in front of the server
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest request ID taken from the X-Request-ID header
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware gives each request an ID: the X-Request-ID header it was sent with, so a
+// proxy's IDs carry through, or a generated UUID. The ID is sent back in the X-Request-ID header,
+// included in error responses and logs, and passed down to searches through the request context.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set(requestIDContextKey, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+		c.Header(requestIDHeader, requestID)
+		c.Writer.Header().Add("Access-Control-Expose-Headers", requestIDHeader)
+		c.Next()
+	})
+}
+
+// validRequestID reports whether a client's request ID is short and made of letters, digits and
+// -_.: only, so it is safe to log and send back.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' && r != '.' && r != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestLoggerMiddleware logs each request once it is served, with its ID, route, status and
+// duration, and the query ID of the search it ran and the ID of its trace if any. Server errors are logged as errors and
+// health checks at the debug level.
+func RequestLoggerMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case c.FullPath() == "/health":
+			level = slog.LevelDebug
+		}
+		attributes := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
+		if queryID := c.GetString(queryIDContextKey); queryID != "" {
+			attributes = append(attributes, "query_id", queryID)
+		}
+		if traceID := tracing.TraceID(c.Request.Context()); traceID != "" {
+			attributes = append(attributes, "trace_id", traceID)
+		}
+		if len(c.Errors) > 0 {
+			attributes = append(attributes, "errors", c.Errors.String())
+		}
+		logging.FromContext(c.Request.Context()).Log(c.Request.Context(), level, "request", attributes...)
+	})
+}
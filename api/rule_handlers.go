@@ -0,0 +1,217 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// RuleListRequest defines the sorting and pagination of rule listings
+type RuleListRequest struct {
+	Sort     string `form:"sort"`      // created_at (default), updated_at or id
+	Order    string `form:"order"`     // asc (default) or desc
+	Page     int    `form:"page"`      // Pages are only returned when page or page_size is set
+	PageSize int    `form:"page_size"` // Defaults to 10, at most 100
+}
+
+// RuleImportRequest defines the rules of a bulk import, in the format of rule exports
+type RuleImportRequest struct {
+	Rules   []model.Rule `json:"rules" binding:"required"`
+	Replace bool         `json:"replace,omitempty"` // Delete the rules of the index missing from the import
+}
+
+// ListRulesHandler handles listing the merchandising rules of an index, in creation order unless
+// sort says otherwise. All of them are returned unless page or page_size is set.
+func (api *API) ListRulesHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	ruleManager, ok := api.ruleManager(c)
+	if !ok {
+		return
+	}
+
+	var req RuleListRequest
+	if result := ValidateQueryBinding(c, &req); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+	query := services.RuleListQuery{Sort: req.Sort, Order: req.Order}
+	if req.Page != 0 || req.PageSize != 0 {
+		if req.Page < 0 || req.PageSize < 0 {
+			result := &ValidationResult{Valid: true}
+			result.AddError("page", "Page and page size must be greater than 0")
+			SendValidationError(c, result)
+			return
+		}
+		query.Page, query.PageSize, _ = ValidatePagination(req.Page, req.PageSize)
+	}
+
+	list, err := ruleManager.ListRules(indexName, query)
+	if err != nil {
+		sendRuleError(c, indexName, "", "list rules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// ExportRulesHandler handles downloading every rule of an index, in creation order, in the format
+// ImportRulesHandler accepts, e.g. to copy them to another environment.
+func (api *API) ExportRulesHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	ruleManager, ok := api.ruleManager(c)
+	if !ok {
+		return
+	}
+
+	list, err := ruleManager.ListRules(indexName, services.RuleListQuery{})
+	if err != nil {
+		sendRuleError(c, indexName, "", "export rules", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-rules.json\"", indexName))
+	c.JSON(http.StatusOK, gin.H{"index_name": indexName, "rules": list.Rules, "total": list.Total})
+}
+
+// ImportRulesHandler handles creating and replacing many rules of an index at once. Rules keep
+// their IDs, so importing an export again updates the same rules.
+func (api *API) ImportRulesHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	ruleManager, ok := api.ruleManager(c)
+	if !ok {
+		return
+	}
+
+	var req RuleImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendInvalidJSONError(c, err)
+		return
+	}
+
+	result, err := ruleManager.ImportRules(indexName, req.Rules, req.Replace)
+	if err != nil {
+		sendRuleError(c, indexName, "", "import rules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetRuleHandler handles requests to get a single rule.
+func (api *API) GetRuleHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	ruleID := c.Param("ruleId")
+
+	ruleManager, ok := api.ruleManager(c)
+	if !ok {
+		return
+	}
+
+	rule, err := ruleManager.GetRule(indexName, ruleID)
+	if err != nil {
+		sendRuleError(c, indexName, ruleID, "get rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRuleHandler handles creating a rule. The rule applies to searches immediately.
+func (api *API) CreateRuleHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	ruleManager, ok := api.ruleManager(c)
+	if !ok {
+		return
+	}
+
+	var rule model.Rule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		SendInvalidJSONError(c, err)
+		return
+	}
+
+	created, err := ruleManager.CreateRule(indexName, rule)
+	if err != nil {
+		sendRuleError(c, indexName, "", "create rule", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateRuleHandler handles replacing the condition, actions, validity window and description of a rule.
+func (api *API) UpdateRuleHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	ruleID := c.Param("ruleId")
+
+	ruleManager, ok := api.ruleManager(c)
+	if !ok {
+		return
+	}
+
+	var rule model.Rule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		SendInvalidJSONError(c, err)
+		return
+	}
+
+	updated, err := ruleManager.UpdateRule(indexName, ruleID, rule)
+	if err != nil {
+		sendRuleError(c, indexName, ruleID, "update rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteRuleHandler handles deleting a rule.
+func (api *API) DeleteRuleHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	ruleID := c.Param("ruleId")
+
+	ruleManager, ok := api.ruleManager(c)
+	if !ok {
+		return
+	}
+
+	if err := ruleManager.DeleteRule(indexName, ruleID); err != nil {
+		sendRuleError(c, indexName, ruleID, "delete rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Rule '%s' deleted", ruleID)})
+}
+
+// ruleManager returns the engine's rule operations, or sends an error if the engine does not support them.
+func (api *API) ruleManager(c *gin.Context) (services.RuleManager, bool) {
+	ruleManager, ok := api.engine.(services.RuleManager)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Rules not supported by this engine")
+	}
+	return ruleManager, ok
+}
+
+// sendRuleError maps rule operation errors to API error responses.
+func sendRuleError(c *gin.Context, indexName, ruleID, operation string, err error) {
+	var validationErr *internalErrors.ValidationError
+	switch {
+	case errors.Is(err, internalErrors.ErrIndexNotFound):
+		SendIndexNotFoundError(c, indexName)
+	case errors.Is(err, internalErrors.ErrRuleNotFound):
+		SendRuleNotFoundError(c, ruleID, indexName)
+	case errors.As(err, &validationErr):
+		SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+	default:
+		SendInternalError(c, operation, err)
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SearchExportRequest defines the structure for exporting every hit of a search. It takes the
+// query options of SearchRequest, without pagination.
+type SearchExportRequest struct {
+	Query                    string                    `json:"query"`
+	Filters                  *services.Filters         `json:"filters,omitempty"`
+	RestrictSearchableFields []string                  `json:"restrict_searchable_fields,omitempty"`
+	ExcludeSearchableFields  []string                  `json:"exclude_searchable_fields,omitempty"`
+	ExcludeTerms             []string                  `json:"exclude_terms,omitempty"`
+	MatchingStrategy         services.MatchingStrategy `json:"matching_strategy,omitempty"`
+	PrefixLast               bool                      `json:"prefix_last,omitempty"`
+	RequireSameField         bool                      `json:"require_same_field,omitempty"`
+	MinWordSizeFor1Typo      *int                      `json:"min_word_size_for_1_typo,omitempty"`
+	MinWordSizeFor2Typos     *int                      `json:"min_word_size_for_2_typos,omitempty"`
+	Tokens                   []services.QueryToken     `json:"tokens,omitempty"`
+	FieldWeights             map[string]float64        `json:"field_weights,omitempty"`
+	QueryTokenDecay          *float64                  `json:"query_token_decay,omitempty"`
+	Boosts                   []services.BoostRule      `json:"boosts,omitempty"`
+	FilterLocale             string                    `json:"filter_locale,omitempty"`
+	Sample                   float64                   `json:"sample,omitempty"`
+	Format                   model.SearchExportFormat  `json:"format,omitempty"` // "csv" (default) or "ndjson"
+	Fields                   []string                  `json:"fields,omitempty"` // Document fields to export; CSV defaults to the document ID and searchable fields, NDJSON to all fields
+}
+
+// SearchExportHandler handles starting a job that writes every hit of a search to a CSV or NDJSON
+// file, downloaded with DownloadSearchExportHandler once the job has completed.
+func (api *API) SearchExportHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	if result := ValidateIndexName(indexName); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	exporter, ok := api.engine.(services.SearchExporter)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Search exports not supported by this engine")
+		return
+	}
+
+	var req SearchExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, ErrorCodeInvalidQuery, "Invalid request body: "+err.Error())
+		return
+	}
+	if result := ValidateQueryTokens(req.Query, req.Tokens); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	query := services.SearchQuery{
+		QueryString:              req.Query,
+		Filters:                  withKeyFilters(c, req.Filters),
+		RestrictSearchableFields: req.RestrictSearchableFields,
+		ExcludeSearchableFields:  req.ExcludeSearchableFields,
+		ExcludeTerms:             req.ExcludeTerms,
+		MatchingStrategy:         req.MatchingStrategy,
+		PrefixLast:               req.PrefixLast,
+		RequireSameField:         req.RequireSameField,
+		MinWordSizeFor1Typo:      req.MinWordSizeFor1Typo,
+		MinWordSizeFor2Typos:     req.MinWordSizeFor2Typos,
+		Tokens:                   req.Tokens,
+		FieldWeights:             req.FieldWeights,
+		QueryTokenDecay:          req.QueryTokenDecay,
+		Boosts:                   req.Boosts,
+		FilterLocale:             req.FilterLocale,
+		Sample:                   req.Sample,
+	}
+
+	jobID, err := exporter.ExportSearchAsync(indexName, query, req.Format, req.Fields)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrIndexNotFound) {
+			SendIndexNotFoundError(c, indexName)
+			return
+		}
+		SendJobExecutionError(c, "search export", err)
+		return
+	}
+	if key, ok := requestAPIKey(c); ok && len(key.Filters) > 0 {
+		api.exportOwners.Store(jobID, key.ID)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "accepted",
+		"message": fmt.Sprintf("Search export started for index '%s'", indexName),
+		"job_id":  jobID,
+	})
+}
+
+// DownloadSearchExportHandler handles downloading the file written by a completed search export job.
+func (api *API) DownloadSearchExportHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+	jobID := c.Param("jobId")
+
+	exporter, ok := api.engine.(services.SearchExporter)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Search exports not supported by this engine")
+		return
+	}
+
+	// An API key with filters can only download the exports it started, which its filters applied to
+	if key, ok := requestAPIKey(c); ok && len(key.Filters) > 0 {
+		if owner, _ := api.exportOwners.Load(jobID); owner != key.ID {
+			SendJobNotFoundError(c, jobID)
+			return
+		}
+	}
+
+	export, file, err := exporter.OpenSearchExport(indexName, jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, internalErrors.ErrJobNotFound):
+			SendJobNotFoundError(c, jobID)
+		case errors.Is(err, internalErrors.ErrInvalidInput):
+			SendError(c, ErrorCodeValidationFailed, err.Error())
+		default:
+			SendInternalError(c, "open search export", err)
+		}
+		return
+	}
+	defer file.Close()
+
+	contentType := "text/csv"
+	if export.Format == model.SearchExportNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	fileName := fmt.Sprintf("%s-%s.%s", indexName, jobID, export.Format)
+	c.DataFromReader(http.StatusOK, export.Bytes, contentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", fileName),
+	})
+}
@@ -2,7 +2,6 @@ package api
 
 import (
 	"errors"
-	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -10,38 +9,77 @@ import (
 	"github.com/gin-gonic/gin"
 
 	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/logging"
 	"github.com/gcbaptista/go-search-engine/model"
 	"github.com/gcbaptista/go-search-engine/services"
 )
 
 // SearchRequest defines the structure for search queries.
 type SearchRequest struct {
-	Query                    string            `json:"query"`
-	Filters                  *services.Filters `json:"filters,omitempty"`
-	Page                     int               `json:"page"`
-	PageSize                 int               `json:"page_size"`
-	RestrictSearchableFields []string          `json:"restrict_searchable_fields,omitempty"`
-	RetrievableFields        []string          `json:"retrievable_fields,omitempty"`
-	MinWordSizeFor1Typo      *int              `json:"min_word_size_for_1_typo,omitempty"`  // Optional: override index setting for minimum word size for 1 typo
-	MinWordSizeFor2Typos     *int              `json:"min_word_size_for_2_typos,omitempty"` // Optional: override index setting for minimum word size for 2 typos
+	Query                    string                    `json:"query"`
+	Filters                  *services.Filters         `json:"filters,omitempty"`
+	Page                     int                       `json:"page"`
+	PageSize                 int                       `json:"page_size"`
+	RestrictSearchableFields []string                  `json:"restrict_searchable_fields,omitempty"`
+	ExcludeSearchableFields  []string                  `json:"exclude_searchable_fields,omitempty"`
+	ExcludeTerms             []string                  `json:"exclude_terms,omitempty"`
+	MatchingStrategy         services.MatchingStrategy `json:"matching_strategy,omitempty"`  // Optional: "all", "any" or "most" query tokens must match
+	PrefixLast               bool                      `json:"prefix_last,omitempty"`        // Optional: match the last query token as a prefix, for search-as-you-type
+	RequireSameField         bool                      `json:"require_same_field,omitempty"` // Optional: the query tokens must match within a single field
+	RetrievableFields        []string                  `json:"retrievable_fields,omitempty"`
+	MinWordSizeFor1Typo      *int                      `json:"min_word_size_for_1_typo,omitempty"`  // Optional: override index setting for minimum word size for 1 typo
+	MinWordSizeFor2Typos     *int                      `json:"min_word_size_for_2_typos,omitempty"` // Optional: override index setting for minimum word size for 2 typos
+	Tokens                   []services.QueryToken     `json:"tokens,omitempty"`                    // Optional: tokens with explicit match modes, instead of a query string
+	NormalizedPreview        bool                      `json:"normalized_preview,omitempty"`        // Optional: return the normalized text used for matching
+	MaxMatchesPerField       int                       `json:"max_matches_per_field,omitempty"`     // Optional: maximum matched terms reported per field, 0 for all
+	FieldsToReport           []string                  `json:"fields_to_report,omitempty"`          // Optional: fields reported in field_matches, all when empty
+	Facets                   []string                  `json:"facets,omitempty"`                    // Optional: filterable fields whose value counts are returned
+	FieldWeights             map[string]float64        `json:"field_weights,omitempty"`             // Optional: override index setting for the score multiplier of each searchable field
+	QueryTokenDecay          *float64                  `json:"query_token_decay,omitempty"`         // Optional: override index setting for the weight of each query token relative to the one before it
+	Boosts                   []services.BoostRule      `json:"boosts,omitempty"`                    // Optional: score changes of the hits matching filter conditions
+	FilterLocale             string                    `json:"filter_locale,omitempty"`             // Optional: locale of numbers and dates written as strings in filter values
+	Sample                   float64                   `json:"sample,omitempty"`                    // Optional: share of the candidates evaluated, with counts extrapolated
+	Suggest                  bool                      `json:"suggest,omitempty"`                   // Optional: suggest corrected or relaxed queries when nothing is found
+	ExplainFilters           bool                      `json:"explain_filters,omitempty"`           // Optional: report the filter conditions and groups each hit matched
+	IDsOnly                  bool                      `json:"ids_only,omitempty"`                  // Optional: return hit_refs with the ID, score and sort keys of each hit instead of hits
+	SearchAfter              string                    `json:"search_after,omitempty"`              // Optional: next_cursor of the previous page, to page without re-ranking every hit
 }
 
 // MultiSearchRequest represents the JSON request for multi-search
 type MultiSearchRequest struct {
-	Queries  []NamedSearchRequest `json:"queries" binding:"required"`
-	Page     int                  `json:"page,omitempty"`
-	PageSize int                  `json:"page_size,omitempty"`
+	Queries     []NamedSearchRequest `json:"queries" binding:"required"`
+	Page        int                  `json:"page,omitempty"`
+	PageSize    int                  `json:"page_size,omitempty"`
+	Deduplicate bool                 `json:"deduplicate,omitempty"` // Show each document only in the first query that matches it
 }
 
 // NamedSearchRequest represents a single named search query in the request
 type NamedSearchRequest struct {
-	Name                     string            `json:"name" binding:"required"`
-	Query                    string            `json:"query" binding:"required"`
-	RestrictSearchableFields []string          `json:"restrict_searchable_fields,omitempty"`
-	RetrievableFields        []string          `json:"retrievable_fields,omitempty"`
-	Filters                  *services.Filters `json:"filters,omitempty"`
-	MinWordSizeFor1Typo      *int              `json:"min_word_size_for_1_typo,omitempty"`
-	MinWordSizeFor2Typos     *int              `json:"min_word_size_for_2_typos,omitempty"`
+	Name                     string                    `json:"name" binding:"required"`
+	Query                    string                    `json:"query"`
+	RestrictSearchableFields []string                  `json:"restrict_searchable_fields,omitempty"`
+	ExcludeSearchableFields  []string                  `json:"exclude_searchable_fields,omitempty"`
+	ExcludeTerms             []string                  `json:"exclude_terms,omitempty"`
+	MatchingStrategy         services.MatchingStrategy `json:"matching_strategy,omitempty"`  // Optional: "all", "any" or "most" query tokens must match
+	PrefixLast               bool                      `json:"prefix_last,omitempty"`        // Optional: match the last query token as a prefix, for search-as-you-type
+	RequireSameField         bool                      `json:"require_same_field,omitempty"` // Optional: the query tokens must match within a single field
+	RetrievableFields        []string                  `json:"retrievable_fields,omitempty"`
+	Filters                  *services.Filters         `json:"filters,omitempty"`
+	MinWordSizeFor1Typo      *int                      `json:"min_word_size_for_1_typo,omitempty"`
+	MinWordSizeFor2Typos     *int                      `json:"min_word_size_for_2_typos,omitempty"`
+	Tokens                   []services.QueryToken     `json:"tokens,omitempty"`
+	NormalizedPreview        bool                      `json:"normalized_preview,omitempty"`
+	MaxMatchesPerField       int                       `json:"max_matches_per_field,omitempty"`
+	FieldsToReport           []string                  `json:"fields_to_report,omitempty"`
+	Facets                   []string                  `json:"facets,omitempty"`
+	FieldWeights             map[string]float64        `json:"field_weights,omitempty"`
+	QueryTokenDecay          *float64                  `json:"query_token_decay,omitempty"`
+	Boosts                   []services.BoostRule      `json:"boosts,omitempty"`
+	FilterLocale             string                    `json:"filter_locale,omitempty"`
+	Sample                   float64                   `json:"sample,omitempty"`
+	Suggest                  bool                      `json:"suggest,omitempty"`
+	ExplainFilters           bool                      `json:"explain_filters,omitempty"`
+	IDsOnly                  bool                      `json:"ids_only,omitempty"`
 }
 
 // SearchHandler handles search requests to an index.
@@ -56,6 +94,11 @@ func (api *API) SearchHandler(c *gin.Context) {
 		return
 	}
 
+	indexName, ok := api.resolveLocaleIndex(c, indexName)
+	if !ok {
+		return
+	}
+
 	indexAccessor, err := api.engine.GetIndex(indexName)
 	if err != nil {
 		if errors.Is(err, internalErrors.ErrIndexNotFound) {
@@ -70,7 +113,20 @@ func (api *API) SearchHandler(c *gin.Context) {
 
 	// Bind JSON directly with error handling
 	if err := c.ShouldBindJSON(&req); err != nil {
-		SendError(c, http.StatusBadRequest, ErrorCodeInvalidQuery, "Invalid request body: "+err.Error())
+		SendError(c, ErrorCodeInvalidQuery, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if result := ValidateQueryTokens(req.Query, req.Tokens); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+	if result := ValidateMatchReporting(req.MaxMatchesPerField); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+	if result := ValidateSearchPageSize(req.PageSize, indexAccessor.Settings()); result.HasErrors() {
+		SendValidationError(c, result)
 		return
 	}
 
@@ -80,16 +136,44 @@ func (api *API) SearchHandler(c *gin.Context) {
 		Page:                     req.Page,
 		PageSize:                 req.PageSize,
 		RestrictSearchableFields: req.RestrictSearchableFields,
+		ExcludeSearchableFields:  req.ExcludeSearchableFields,
+		ExcludeTerms:             req.ExcludeTerms,
+		MatchingStrategy:         req.MatchingStrategy,
+		PrefixLast:               req.PrefixLast,
+		RequireSameField:         req.RequireSameField,
 		RetrievableFields:        req.RetrievableFields,
 		MinWordSizeFor1Typo:      req.MinWordSizeFor1Typo,
 		MinWordSizeFor2Typos:     req.MinWordSizeFor2Typos,
+		Tokens:                   req.Tokens,
+		NormalizedPreview:        req.NormalizedPreview,
+		MaxMatchesPerField:       req.MaxMatchesPerField,
+		FieldsToReport:           req.FieldsToReport,
+		Facets:                   req.Facets,
+		FieldWeights:             req.FieldWeights,
+		QueryTokenDecay:          req.QueryTokenDecay,
+		Boosts:                   req.Boosts,
+		FilterLocale:             req.FilterLocale,
+		Sample:                   req.Sample,
+		Suggest:                  req.Suggest,
+		ExplainFilters:           req.ExplainFilters,
+		IDsOnly:                  req.IDsOnly,
+		SearchAfter:              req.SearchAfter,
+		RequestID:                c.GetString(requestIDContextKey),
+		Context:                  c.Request.Context(),
 	}
+	searchQuery.Filters = withKeyFilters(c, searchQuery.Filters)
 
+	searchStart := time.Now()
 	results, err := indexAccessor.Search(searchQuery)
 	if err != nil {
 		SendSearchError(c, indexName, err)
 		return
 	}
+	c.Set(queryIDContextKey, results.QueryId)
+
+	if shadowManager, ok := api.engine.(services.ShadowManager); ok {
+		shadowManager.ShadowSearch(indexName, searchQuery, results, time.Since(searchStart))
+	}
 
 	// Track analytics event
 	responseTime := time.Since(startTime)
@@ -97,16 +181,18 @@ func (api *API) SearchHandler(c *gin.Context) {
 
 	event := model.SearchEvent{
 		IndexName:    indexName,
-		Query:        req.Query,
+		Query:        queryText(req.Query, req.Tokens),
+		QueryID:      results.QueryId,
 		SearchType:   searchType,
 		ResponseTime: responseTime,
 		ResultCount:  results.Total,
 	}
 
 	// Track the event asynchronously to avoid slowing down the response
+	logger := logging.FromContext(c.Request.Context())
 	go func() {
 		if err := api.analytics.TrackSearchEvent(event); err != nil {
-			log.Printf("Warning: Failed to track search event: %v", err)
+			logger.Warn("failed to track search event", "index", indexName, "query_id", event.QueryID, "error", err)
 		}
 	}()
 
@@ -125,6 +211,11 @@ func (api *API) MultiSearchHandler(c *gin.Context) {
 		return
 	}
 
+	indexName, ok := api.resolveLocaleIndex(c, indexName)
+	if !ok {
+		return
+	}
+
 	indexAccessor, err := api.engine.GetIndex(indexName)
 	if err != nil {
 		if errors.Is(err, internalErrors.ErrIndexNotFound) {
@@ -139,13 +230,13 @@ func (api *API) MultiSearchHandler(c *gin.Context) {
 
 	// Bind JSON directly with error handling
 	if err := c.ShouldBindJSON(&req); err != nil {
-		SendError(c, http.StatusBadRequest, ErrorCodeInvalidQuery, "Invalid request body: "+err.Error())
+		SendError(c, ErrorCodeInvalidQuery, "Invalid request body: "+err.Error())
 		return
 	}
 
 	// Validate that we have at least one query
 	if len(req.Queries) == 0 {
-		SendError(c, http.StatusBadRequest, ErrorCodeInvalidQuery, "At least one query is required")
+		SendError(c, ErrorCodeInvalidQuery, "At least one query is required")
 		return
 	}
 
@@ -153,20 +244,41 @@ func (api *API) MultiSearchHandler(c *gin.Context) {
 	queryNames := make(map[string]bool)
 	for _, namedQuery := range req.Queries {
 		if namedQuery.Name == "" {
-			SendError(c, http.StatusBadRequest, ErrorCodeInvalidQuery, "All queries must have a non-empty name")
+			SendError(c, ErrorCodeInvalidQuery, "All queries must have a non-empty name")
 			return
 		}
 		if queryNames[namedQuery.Name] {
-			SendError(c, http.StatusBadRequest, ErrorCodeInvalidQuery, "Query names must be unique: '"+namedQuery.Name+"' appears multiple times")
+			SendError(c, ErrorCodeInvalidQuery, "Query names must be unique: '"+namedQuery.Name+"' appears multiple times")
 			return
 		}
 		queryNames[namedQuery.Name] = true
+
+		if namedQuery.Query == "" && len(namedQuery.Tokens) == 0 {
+			SendError(c, ErrorCodeInvalidQuery, "Query '"+namedQuery.Name+"' must have a query or tokens")
+			return
+		}
+		if result := ValidateQueryTokens(namedQuery.Query, namedQuery.Tokens); result.HasErrors() {
+			SendValidationError(c, result)
+			return
+		}
+		if result := ValidateMatchReporting(namedQuery.MaxMatchesPerField); result.HasErrors() {
+			SendValidationError(c, result)
+			return
+		}
+	}
+
+	if result := ValidateSearchPageSize(req.PageSize, indexAccessor.Settings()); result.HasErrors() {
+		SendValidationError(c, result)
+		return
 	}
 
 	// Convert API request to service request
 	multiSearchQuery := services.MultiSearchQuery{
-		Page:     req.Page,
-		PageSize: req.PageSize,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		Deduplicate: req.Deduplicate,
+		RequestID:   c.GetString(requestIDContextKey),
+		Context:     c.Request.Context(),
 	}
 
 	// Convert named search requests
@@ -175,10 +287,28 @@ func (api *API) MultiSearchHandler(c *gin.Context) {
 			Name:                     namedReq.Name,
 			Query:                    namedReq.Query,
 			RestrictSearchableFields: namedReq.RestrictSearchableFields,
+			ExcludeSearchableFields:  namedReq.ExcludeSearchableFields,
+			ExcludeTerms:             namedReq.ExcludeTerms,
+			MatchingStrategy:         namedReq.MatchingStrategy,
+			PrefixLast:               namedReq.PrefixLast,
+			RequireSameField:         namedReq.RequireSameField,
 			RetrievableFields:        namedReq.RetrievableFields,
-			Filters:                  namedReq.Filters,
+			Filters:                  withKeyFilters(c, namedReq.Filters),
 			MinWordSizeFor1Typo:      namedReq.MinWordSizeFor1Typo,
 			MinWordSizeFor2Typos:     namedReq.MinWordSizeFor2Typos,
+			Tokens:                   namedReq.Tokens,
+			NormalizedPreview:        namedReq.NormalizedPreview,
+			MaxMatchesPerField:       namedReq.MaxMatchesPerField,
+			FieldsToReport:           namedReq.FieldsToReport,
+			Facets:                   namedReq.Facets,
+			FieldWeights:             namedReq.FieldWeights,
+			QueryTokenDecay:          namedReq.QueryTokenDecay,
+			Boosts:                   namedReq.Boosts,
+			FilterLocale:             namedReq.FilterLocale,
+			Sample:                   namedReq.Sample,
+			Suggest:                  namedReq.Suggest,
+			ExplainFilters:           namedReq.ExplainFilters,
+			IDsOnly:                  namedReq.IDsOnly,
 		}
 		multiSearchQuery.Queries = append(multiSearchQuery.Queries, namedQuery)
 	}
@@ -191,12 +321,13 @@ func (api *API) MultiSearchHandler(c *gin.Context) {
 
 	// Track analytics events for each individual query
 	responseTime := time.Since(startTime)
+	logger := logging.FromContext(c.Request.Context())
 	for queryName, result := range results.Results {
 		// Find the original request for this query to get the query string
 		var originalQuery string
 		for _, namedReq := range req.Queries {
 			if namedReq.Name == queryName {
-				originalQuery = namedReq.Query
+				originalQuery = queryText(namedReq.Query, namedReq.Tokens)
 				break
 			}
 		}
@@ -204,6 +335,7 @@ func (api *API) MultiSearchHandler(c *gin.Context) {
 		event := model.SearchEvent{
 			IndexName:    indexName,
 			Query:        originalQuery,
+			QueryID:      result.QueryId,
 			SearchType:   "multi_search",
 			ResponseTime: responseTime,
 			ResultCount:  result.Total,
@@ -212,7 +344,7 @@ func (api *API) MultiSearchHandler(c *gin.Context) {
 		// Track the event asynchronously
 		go func(e model.SearchEvent) {
 			if err := api.analytics.TrackSearchEvent(e); err != nil {
-				log.Printf("Warning: Failed to track search event: %v", err)
+				logger.Warn("failed to track search event", "index", indexName, "query_id", e.QueryID, "error", err)
 			}
 		}(event)
 	}
@@ -220,6 +352,19 @@ func (api *API) MultiSearchHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, results)
 }
 
+// queryText returns the text a request searched for: its query string, or the tokens of a
+// structured query separated by spaces.
+func queryText(query string, tokens []services.QueryToken) string {
+	if len(tokens) == 0 {
+		return query
+	}
+	parts := make([]string, len(tokens))
+	for i, token := range tokens {
+		parts[i] = token.Token
+	}
+	return strings.Join(parts, " ")
+}
+
 // determineSearchType determines the type of search based on the request
 func (api *API) determineSearchType(req SearchRequest) string {
 	if req.Filters != nil {
@@ -239,3 +384,30 @@ func (api *API) determineSearchType(req SearchRequest) string {
 
 	return "exact_match"
 }
+
+// resolveLocaleIndex routes a request to the locale-specific variant of an index when the
+// "lang" query parameter is present (Accept-Language syntax, e.g. "de-CH,de;q=0.9,en;q=0.5").
+// The selected index is reported in the X-Resolved-Index response header.
+func (api *API) resolveLocaleIndex(c *gin.Context, indexName string) (string, bool) {
+	acceptLanguage := c.Query("lang")
+	if acceptLanguage == "" {
+		return indexName, true
+	}
+	resolver, ok := api.engine.(services.LocaleResolver)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Locale routing not supported by this engine")
+		return "", false
+	}
+
+	resolved, err := resolver.ResolveLocaleIndex(indexName, acceptLanguage)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrIndexNotFound) {
+			SendIndexNotFoundError(c, indexName)
+			return "", false
+		}
+		SendInternalError(c, "resolve locale index", err)
+		return "", false
+	}
+	c.Header("X-Resolved-Index", resolved)
+	return resolved, true
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// GetIndexSeqHandler handles requests for the sequence numbers of an index. A client that wrote
+// documents can wait for searchable_seq to reach the seq reported by its write job before searching.
+func (api *API) GetIndexSeqHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	sequenceReader, ok := api.engine.(services.SequenceReader)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Sequence numbers not supported by this engine")
+		return
+	}
+
+	result, err := sequenceReader.IndexSeq(indexName)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrIndexNotFound) {
+			SendIndexNotFoundError(c, indexName)
+			return
+		}
+		SendInternalError(c, "get sequence numbers", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
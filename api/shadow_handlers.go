@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// EnableShadowHandler handles enabling shadow mode: a sample of the index's live searches is also
+// run against a candidate index and only the metrics of those runs are kept.
+func (api *API) EnableShadowHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	shadowManager, ok := api.shadowManager(c)
+	if !ok {
+		return
+	}
+
+	var config model.ShadowConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		SendInvalidJSONError(c, err)
+		return
+	}
+
+	stats, err := shadowManager.EnableShadow(indexName, config)
+	if err != nil {
+		var indexErr *internalErrors.IndexNotFoundError
+		if errors.As(err, &indexErr) {
+			SendIndexNotFoundError(c, indexErr.IndexName)
+			return
+		}
+		sendShadowError(c, indexName, "enable shadow mode", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetShadowStatsHandler handles requests to compare an index with its shadow candidate.
+func (api *API) GetShadowStatsHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	shadowManager, ok := api.shadowManager(c)
+	if !ok {
+		return
+	}
+
+	stats, err := shadowManager.GetShadowStats(indexName)
+	if err != nil {
+		sendShadowError(c, indexName, "get shadow stats", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// DisableShadowHandler handles stopping shadow mode. The response holds the final metrics.
+func (api *API) DisableShadowHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	shadowManager, ok := api.shadowManager(c)
+	if !ok {
+		return
+	}
+
+	stats, err := shadowManager.DisableShadow(indexName)
+	if err != nil {
+		sendShadowError(c, indexName, "disable shadow mode", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// shadowManager returns the engine's shadow operations, or sends an error if the engine does not support them.
+func (api *API) shadowManager(c *gin.Context) (services.ShadowManager, bool) {
+	shadowManager, ok := api.engine.(services.ShadowManager)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Shadow mode not supported by this engine")
+	}
+	return shadowManager, ok
+}
+
+// sendShadowError maps shadow mode errors to API error responses.
+func sendShadowError(c *gin.Context, indexName, operation string, err error) {
+	var validationErr *internalErrors.ValidationError
+	switch {
+	case errors.Is(err, internalErrors.ErrIndexNotFound):
+		SendIndexNotFoundError(c, indexName)
+	case errors.Is(err, internalErrors.ErrShadowNotFound):
+		SendShadowNotFoundError(c, indexName)
+	case errors.As(err, &validationErr):
+		SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+	default:
+		SendInternalError(c, operation, err)
+	}
+}
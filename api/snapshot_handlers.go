@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/logging"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SnapshotIndexHandler handles downloading an archive of an index's settings, inverted index and
+// document store, which RestoreIndexHandler can restore on this or another server.
+func (api *API) SnapshotIndexHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	snapshotter, ok := api.engine.(services.IndexSnapshotter)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Index snapshots not supported by this engine")
+		return
+	}
+
+	// Check the index first: once the archive is being streamed, errors can no longer be reported
+	if _, err := api.engine.GetIndex(indexName); err != nil {
+		if errors.Is(err, internalErrors.ErrIndexNotFound) {
+			SendIndexNotFoundError(c, indexName)
+		} else {
+			SendInternalError(c, "get index", err)
+		}
+		return
+	}
+
+	fileName := fmt.Sprintf("%s-%s.snapshot", indexName, time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Status(http.StatusOK)
+
+	if _, err := snapshotter.SnapshotIndex(indexName, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			// The snapshot failed before the archive started streaming, e.g. its consistency check
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Content-Type")
+			SendInternalError(c, "snapshot index", err)
+			return
+		}
+		// The status is already sent, so the truncated archive is only detected when restoring it
+		logging.FromContext(c.Request.Context()).Error("snapshot failed", "index", indexName, "error", err)
+		_ = c.Error(err)
+	}
+}
+
+// RestoreIndexHandler handles creating an index from a snapshot archive sent as the request body.
+// The index must not exist yet.
+func (api *API) RestoreIndexHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	if result := ValidateIndexName(indexName); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	snapshotter, ok := api.engine.(services.IndexSnapshotter)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Index snapshots not supported by this engine")
+		return
+	}
+
+	info, err := snapshotter.RestoreIndex(indexName, c.Request.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, internalErrors.ErrIndexAlreadyExists):
+			SendIndexExistsError(c, indexName)
+		case errors.Is(err, internalErrors.ErrInvalidInput):
+			SendError(c, ErrorCodeValidationFailed, err.Error())
+		default:
+			SendInternalError(c, "restore index", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, info)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SpellcheckHandler handles suggesting corrections for a query string without running a search.
+func (api *API) SpellcheckHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	spellchecker, ok := api.engine.(services.Spellchecker)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Spellcheck not supported by this engine")
+		return
+	}
+
+	var request model.SpellcheckRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		SendInvalidJSONError(c, err)
+		return
+	}
+
+	request.Filters = keyFilterConditions(c)
+
+	result, err := spellchecker.Spellcheck(indexName, request)
+	if err != nil {
+		var validationErr *internalErrors.ValidationError
+		switch {
+		case errors.Is(err, internalErrors.ErrIndexNotFound):
+			SendIndexNotFoundError(c, indexName)
+		case errors.As(err, &validationErr):
+			SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+		default:
+			SendInternalError(c, "spellcheck query", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SuggestHandler handles completing a typed prefix with the most frequent indexed words, or values
+// of the index's suggestion field, for typeahead.
+func (api *API) SuggestHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	autocompleter, ok := api.engine.(services.Autocompleter)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Suggestions not supported by this engine")
+		return
+	}
+
+	var request model.SuggestRequest
+	if result := ValidateQueryBinding(c, &request); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	request.Filters = keyFilterConditions(c)
+
+	result, err := autocompleter.Suggest(indexName, request)
+	if err != nil {
+		var validationErr *internalErrors.ValidationError
+		switch {
+		case errors.Is(err, internalErrors.ErrIndexNotFound):
+			SendIndexNotFoundError(c, indexName)
+		case errors.As(err, &validationErr):
+			SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+		default:
+			SendInternalError(c, "suggest completions", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
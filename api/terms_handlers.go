@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// TermsHandler handles listing the indexed terms of an index starting with a prefix, with the
+// number of documents containing each.
+func (api *API) TermsHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	explorer, ok := api.engine.(services.TermExplorer)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Term listing not supported by this engine")
+		return
+	}
+
+	var request model.TermsRequest
+	if result := ValidateQueryBinding(c, &request); result.HasErrors() {
+		SendValidationError(c, result)
+		return
+	}
+
+	result, err := explorer.Terms(indexName, request)
+	if err != nil {
+		var validationErr *internalErrors.ValidationError
+		switch {
+		case errors.Is(err, internalErrors.ErrIndexNotFound):
+			SendIndexNotFoundError(c, indexName)
+		case errors.As(err, &validationErr):
+			SendError(c, ErrorCodeValidationFailed, validationErr.Error())
+		default:
+			SendInternalError(c, "list terms", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
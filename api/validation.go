@@ -4,11 +4,13 @@ package api
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
 )
 
 // ValidationError represents a validation error with field context
@@ -71,6 +73,25 @@ func ValidateDocumentID(documentID string) *ValidationResult {
 	return result
 }
 
+// maxIdempotencyKeyLength bounds the Idempotency-Key header of write requests
+const maxIdempotencyKeyLength = 255
+
+// ValidateIdempotencyKey validates the optional Idempotency-Key header of a write request
+func ValidateIdempotencyKey(key string) *ValidationResult {
+	result := &ValidationResult{Valid: true}
+
+	if strings.TrimSpace(key) != key {
+		result.AddError(idempotencyKeyHeader, "Idempotency key cannot have leading or trailing whitespace")
+		return result
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		result.AddError(idempotencyKeyHeader, fmt.Sprintf("Idempotency key cannot be longer than %d characters", maxIdempotencyKeyLength))
+	}
+
+	return result
+}
+
 // ValidateIndexSettings validates index settings for creation
 func ValidateIndexSettings(settings *config.IndexSettings) *ValidationResult {
 	result := &ValidationResult{Valid: true}
@@ -157,6 +178,90 @@ func ValidatePagination(page, pageSize int) (int, int, *ValidationResult) {
 	return page, pageSize, result
 }
 
+// ValidateAnalyticsReportParams parses the window and sets defaults for the per-index analytics reports
+func ValidateAnalyticsReportParams(window string, limit int) (time.Duration, int, *ValidationResult) {
+	result := &ValidationResult{Valid: true}
+
+	duration := defaultAnalyticsReportWindow
+	if window != "" {
+		parsed, err := time.ParseDuration(window)
+		if err != nil {
+			result.AddError("window", "Window must be a duration such as 1h or 24h")
+		} else if parsed <= 0 {
+			result.AddError("window", "Window must be greater than 0")
+		} else {
+			duration = parsed
+		}
+	}
+
+	if limit < 0 {
+		result.AddError("limit", "Limit must not be negative")
+	}
+	if limit == 0 {
+		limit = defaultAnalyticsReportLimit
+	}
+	if limit > maxAnalyticsReportLimit {
+		limit = maxAnalyticsReportLimit
+	}
+
+	return duration, limit, result
+}
+
+// ValidateQueryTokens validates the tokens of a structured search query
+func ValidateQueryTokens(query string, tokens []services.QueryToken) *ValidationResult {
+	result := &ValidationResult{Valid: true}
+
+	if len(tokens) == 0 {
+		return result
+	}
+
+	if strings.TrimSpace(query) != "" {
+		result.AddError("tokens", "Tokens cannot be combined with a query string")
+	}
+
+	for i, token := range tokens {
+		field := fmt.Sprintf("tokens[%d]", i)
+		if strings.TrimSpace(token.Token) == "" {
+			result.AddError(field+".token", "Token cannot be empty")
+		}
+		switch token.Mode {
+		case services.TokenMatchExact, services.TokenMatchPrefix, services.TokenMatchFuzzy:
+		default:
+			result.AddError(field+".mode", fmt.Sprintf("Unsupported match mode '%s' (expected 'exact', 'prefix' or 'fuzzy')", token.Mode))
+		}
+		if token.MaxTypos != 0 && token.Mode != services.TokenMatchFuzzy {
+			result.AddError(field+".max_typos", "Max typos is only supported by fuzzy tokens")
+		} else if token.MaxTypos < 0 || token.MaxTypos > 2 {
+			result.AddError(field+".max_typos", "Max typos must be 1 or 2")
+		}
+	}
+
+	return result
+}
+
+// ValidateMatchReporting validates the controls limiting the matched terms reported per hit
+func ValidateMatchReporting(maxMatchesPerField int) *ValidationResult {
+	result := &ValidationResult{Valid: true}
+
+	if maxMatchesPerField < 0 {
+		result.AddError("max_matches_per_field", "Max matches per field cannot be negative")
+	}
+
+	return result
+}
+
+// ValidateSearchPageSize validates a search page size against the maximum page size of the index.
+// Page sizes of 0 or less use the index's default page size.
+func ValidateSearchPageSize(pageSize int, settings config.IndexSettings) *ValidationResult {
+	result := &ValidationResult{Valid: true}
+
+	if limit := settings.SearchPageSizeLimit(); pageSize > limit {
+		result.AddError("page_size", fmt.Sprintf("Page size %d exceeds the maximum page size of %d for index '%s'", pageSize, limit, settings.Name))
+	}
+
+	return result
+}
+
 // ValidateRenameRequest validates a rename index request
 func ValidateRenameRequest(oldName, newName string) *ValidationResult {
 	result := &ValidationResult{Valid: true}
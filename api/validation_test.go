@@ -5,6 +5,7 @@ import (
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
 )
 
 func TestValidationResult_AddError(t *testing.T) {
@@ -416,3 +417,78 @@ func TestValidateRenameRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateQueryTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		tokens    []services.QueryToken
+		wantValid bool
+		wantError string
+	}{
+		{
+			name:      "no tokens",
+			query:     "matrix",
+			wantValid: true,
+		},
+		{
+			name:      "valid tokens",
+			tokens:    []services.QueryToken{{Token: "matr", Mode: services.TokenMatchPrefix}, {Token: "1999", Mode: services.TokenMatchExact}, {Token: "reloded", Mode: services.TokenMatchFuzzy, MaxTypos: 2}},
+			wantValid: true,
+		},
+		{
+			name:      "tokens with query string",
+			query:     "matrix",
+			tokens:    []services.QueryToken{{Token: "matr", Mode: services.TokenMatchPrefix}},
+			wantValid: false,
+			wantError: "Tokens cannot be combined with a query string",
+		},
+		{
+			name:      "empty token",
+			tokens:    []services.QueryToken{{Token: " ", Mode: services.TokenMatchExact}},
+			wantValid: false,
+			wantError: "Token cannot be empty",
+		},
+		{
+			name:      "unknown mode",
+			tokens:    []services.QueryToken{{Token: "matr", Mode: "regex"}},
+			wantValid: false,
+			wantError: "Unsupported match mode 'regex' (expected 'exact', 'prefix' or 'fuzzy')",
+		},
+		{
+			name:      "max typos on exact token",
+			tokens:    []services.QueryToken{{Token: "matr", Mode: services.TokenMatchExact, MaxTypos: 1}},
+			wantValid: false,
+			wantError: "Max typos is only supported by fuzzy tokens",
+		},
+		{
+			name:      "max typos out of range",
+			tokens:    []services.QueryToken{{Token: "matr", Mode: services.TokenMatchFuzzy, MaxTypos: 3}},
+			wantValid: false,
+			wantError: "Max typos must be 1 or 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateQueryTokens(tt.query, tt.tokens)
+
+			if result.Valid != tt.wantValid {
+				t.Errorf("ValidateQueryTokens() Valid = %v, want %v", result.Valid, tt.wantValid)
+			}
+
+			if !tt.wantValid {
+				found := false
+				for _, err := range result.Errors {
+					if err.Message == tt.wantError {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("ValidateQueryTokens() expected error '%v' not found in %v", tt.wantError, result.Errors)
+				}
+			}
+		})
+	}
+}
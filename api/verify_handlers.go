@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// VerifyIndexHandler handles cross-checking an index's documents against its inverted index.
+// The repair query parameter fixes the issues found.
+func (api *API) VerifyIndexHandler(c *gin.Context) {
+	indexName := c.Param("indexName")
+
+	verifier, ok := api.engine.(services.IndexVerifier)
+	if !ok {
+		SendError(c, ErrorCodeNotImplemented, "Index verification not supported by this engine")
+		return
+	}
+
+	repair := false
+	if repairParam := c.Query("repair"); repairParam != "" {
+		parsed, err := strconv.ParseBool(repairParam)
+		if err != nil {
+			SendError(c, ErrorCodeValidationFailed, "repair must be true or false")
+			return
+		}
+		repair = parsed
+	}
+
+	report, err := verifier.VerifyIndex(indexName, repair)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrIndexNotFound) {
+			SendIndexNotFoundError(c, indexName)
+		} else {
+			SendInternalError(c, "verify index", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,123 @@
+package benchmarks
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// benchmarkSizes are the corpus sizes benchmarked by go test -bench
+var benchmarkSizes = []int{1000, 10000}
+
+func TestNewCorpus(t *testing.T) {
+	spec := CorpusSpec{Documents: 50, VocabularySize: 100, Seed: 7}
+	first, err := NewCorpus(spec)
+	if err != nil {
+		t.Fatalf("NewCorpus failed: %v", err)
+	}
+	second, _ := NewCorpus(spec)
+	if !reflect.DeepEqual(first.Documents, second.Documents) || !reflect.DeepEqual(first.Queries(20), second.Queries(20)) {
+		t.Error("Expected the same spec to generate the same documents and queries")
+	}
+
+	if len(first.Documents) != 50 || len(first.Vocabulary) != 100 {
+		t.Errorf("Expected 50 documents and 100 words, got %d and %d", len(first.Documents), len(first.Vocabulary))
+	}
+	words := make(map[string]struct{}, len(first.Vocabulary))
+	for _, word := range first.Vocabulary {
+		words[word] = struct{}{}
+	}
+	if len(words) != len(first.Vocabulary) {
+		t.Errorf("Expected distinct vocabulary words, got %d distinct of %d", len(words), len(first.Vocabulary))
+	}
+	if first.Spec.Fields[0].Name != "title" || first.Documents[0]["title"] == "" || first.Documents[0]["category"] == "" {
+		t.Errorf("Expected default fields, got %+v", first.Documents[0])
+	}
+
+	for _, invalid := range []CorpusSpec{
+		{Documents: MaxDocuments + 1},
+		{VocabularySize: 1},
+		{Fields: []FieldSpec{{Name: "title", MinWords: 5, MaxWords: 2}}},
+		{Fields: []FieldSpec{{Name: "title", MinWords: 1, MaxWords: 2}, {Name: "title", MinWords: 1, MaxWords: 2}}},
+		{Fields: []FieldSpec{{Name: "category", MinWords: 1, MaxWords: 2}}},
+	} {
+		if _, err := NewCorpus(invalid); !errors.Is(err, internalErrors.ErrInvalidInput) {
+			t.Errorf("Expected a validation error for %+v, got %v", invalid, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	report, err := Run(RunSpec{Corpus: CorpusSpec{Documents: 200, VocabularySize: 300}, Queries: 50, BatchSize: 64})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Corpus.Documents != 200 || report.Queries != 50 || report.Terms == 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if report.ZeroResultQueries == report.Queries || report.MeanHits == 0 {
+		t.Errorf("Expected queries taken from the documents to have hits, got %+v", report)
+	}
+	if report.SearchLatency.P50 > report.SearchLatency.P99 || report.SearchLatency.P99 > report.SearchLatency.Max {
+		t.Errorf("Expected ordered percentiles, got %+v", report.SearchLatency)
+	}
+
+	if _, err := Run(RunSpec{Queries: MaxQueries + 1}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected a validation error for too many queries, got %v", err)
+	}
+}
+
+// BenchmarkIndexing measures indexing generated corpora from an empty index, in batches of
+// DefaultBatchSize documents.
+func BenchmarkIndexing(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("docs=%d", size), func(b *testing.B) {
+			corpus, err := NewCorpus(CorpusSpec{Documents: size})
+			if err != nil {
+				b.Fatalf("Failed to generate corpus: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				bench, err := NewBench(corpus.Spec)
+				if err != nil {
+					b.Fatalf("Failed to create index: %v", err)
+				}
+				b.StartTimer()
+				for offset := 0; offset < size; offset += DefaultBatchSize {
+					if err := bench.Indexer.AddDocuments(bench.Corpus.Documents[offset:min(offset+DefaultBatchSize, size)]); err != nil {
+						b.Fatalf("Failed to index documents: %v", err)
+					}
+				}
+			}
+			b.ReportMetric(float64(size*b.N)/b.Elapsed().Seconds(), "docs/s")
+		})
+	}
+}
+
+// BenchmarkSearch measures searching generated queries, some mistyped or partially typed, in
+// indexed corpora.
+func BenchmarkSearch(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("docs=%d", size), func(b *testing.B) {
+			bench, err := NewBench(CorpusSpec{Documents: size})
+			if err != nil {
+				b.Fatalf("Failed to create index: %v", err)
+			}
+			if err := bench.Indexer.AddDocuments(bench.Corpus.Documents); err != nil {
+				b.Fatalf("Failed to index documents: %v", err)
+			}
+			queries := bench.Corpus.Queries(DefaultQueries)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := bench.Searcher.Search(services.SearchQuery{QueryString: queries[i%len(queries)]}); err != nil {
+					b.Fatalf("Search failed: %v", err)
+				}
+			}
+		})
+	}
+}
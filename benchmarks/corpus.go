@@ -0,0 +1,202 @@
+// Package benchmarks generates synthetic corpora and measures indexing and search performance on
+// them, so changes to the engine can be compared on the same data. Benchmarks run with `go test
+// -bench . ./benchmarks` and through the `POST /_benchmark` endpoint.
+package benchmarks
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// Limits of generated corpora, so a benchmark fits in the memory of a running engine
+const (
+	MaxDocuments      = 100000
+	MaxVocabularySize = 100000
+	MaxFieldWords     = 500
+)
+
+// Defaults of CorpusSpec values left at zero
+const (
+	DefaultDocuments      = 10000
+	DefaultVocabularySize = 5000
+	DefaultSeed           = 1
+)
+
+// syllables make up the generated words. Words are numbers written in base len(syllables), so
+// every word of a vocabulary is distinct.
+var syllables = []string{
+	"ba", "ko", "ri", "mu", "te", "la", "no", "si", "da", "ve",
+	"pa", "zu", "me", "go", "ni", "ta", "lo", "ke", "ra", "su",
+}
+
+// categories are the values of the generated "category" field, for filters and facets
+var categories = []string{"books", "movies", "music", "games", "toys", "garden", "sports", "tools"}
+
+// FieldSpec describes a generated text field: each document gets between MinWords and MaxWords
+// words in it.
+type FieldSpec struct {
+	Name     string `json:"name"`
+	MinWords int    `json:"min_words"`
+	MaxWords int    `json:"max_words"`
+}
+
+// CorpusSpec describes a synthetic corpus. Words are drawn from the vocabulary with a Zipf
+// distribution, like in natural text, so a few words are in most documents and most words in few.
+// The same spec always generates the same corpus.
+type CorpusSpec struct {
+	Documents      int         `json:"documents,omitempty"`       // Defaults to DefaultDocuments
+	VocabularySize int         `json:"vocabulary_size,omitempty"` // Distinct words; defaults to DefaultVocabularySize
+	Fields         []FieldSpec `json:"fields,omitempty"`          // Text fields; defaults to DefaultFields
+	Seed           int64       `json:"seed,omitempty"`            // Seed of the generator; defaults to DefaultSeed
+}
+
+// DefaultFields returns the text fields of corpora without fields: a short title and a longer
+// description.
+func DefaultFields() []FieldSpec {
+	return []FieldSpec{
+		{Name: "title", MinWords: 2, MaxWords: 6},
+		{Name: "description", MinWords: 10, MaxWords: 40},
+	}
+}
+
+// WithDefaults returns the spec with its unset values replaced by their defaults.
+func (spec CorpusSpec) WithDefaults() CorpusSpec {
+	if spec.Documents == 0 {
+		spec.Documents = DefaultDocuments
+	}
+	if spec.VocabularySize == 0 {
+		spec.VocabularySize = DefaultVocabularySize
+	}
+	if len(spec.Fields) == 0 {
+		spec.Fields = DefaultFields()
+	}
+	if spec.Seed == 0 {
+		spec.Seed = DefaultSeed
+	}
+	return spec
+}
+
+// Validate checks the values of a spec with its defaults applied.
+func (spec CorpusSpec) Validate() error {
+	if spec.Documents < 1 || spec.Documents > MaxDocuments {
+		return errors.NewValidationError("documents", fmt.Sprintf("must be between 1 and %d", MaxDocuments))
+	}
+	if spec.VocabularySize < 2 || spec.VocabularySize > MaxVocabularySize {
+		return errors.NewValidationError("vocabulary_size", fmt.Sprintf("must be between 2 and %d", MaxVocabularySize))
+	}
+	seen := make(map[string]struct{}, len(spec.Fields))
+	for i, field := range spec.Fields {
+		path := fmt.Sprintf("fields[%d]", i)
+		if strings.TrimSpace(field.Name) == "" || field.Name == "documentID" || field.Name == "category" || field.Name == "popularity" {
+			return errors.NewValidationError(path+".name", "must be set and not documentID, category or popularity")
+		}
+		if _, duplicate := seen[field.Name]; duplicate {
+			return errors.NewValidationError(path+".name", fmt.Sprintf("'%s' is repeated", field.Name))
+		}
+		seen[field.Name] = struct{}{}
+		if field.MinWords < 1 || field.MaxWords < field.MinWords || field.MaxWords > MaxFieldWords {
+			return errors.NewValidationError(path, fmt.Sprintf("needs 1 <= min_words <= max_words <= %d", MaxFieldWords))
+		}
+	}
+	return nil
+}
+
+// Corpus is a generated set of documents with the vocabulary they are written with.
+type Corpus struct {
+	Spec       CorpusSpec
+	Vocabulary []string
+	Documents  []model.Document
+}
+
+// NewCorpus generates the corpus of a spec, applying its defaults first. Besides their text fields,
+// documents have a "category" keyword and a numeric "popularity" for filters and ranking.
+func NewCorpus(spec CorpusSpec) (*Corpus, error) {
+	spec = spec.WithDefaults()
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	vocabulary := make([]string, spec.VocabularySize)
+	for i := range vocabulary {
+		vocabulary[i] = word(i)
+	}
+
+	random := rand.New(rand.NewSource(spec.Seed))
+	zipf := rand.NewZipf(random, 1.1, 1, uint64(spec.VocabularySize-1))
+	documents := make([]model.Document, spec.Documents)
+	for i := range documents {
+		doc := model.Document{
+			"documentID": fmt.Sprintf("doc_%d", i),
+			"category":   categories[random.Intn(len(categories))],
+			"popularity": float64(random.Intn(1000)),
+		}
+		for _, field := range spec.Fields {
+			words := make([]string, field.MinWords+random.Intn(field.MaxWords-field.MinWords+1))
+			for j := range words {
+				words[j] = vocabulary[zipf.Uint64()]
+			}
+			doc[field.Name] = strings.Join(words, " ")
+		}
+		documents[i] = doc
+	}
+	return &Corpus{Spec: spec, Vocabulary: vocabulary, Documents: documents}, nil
+}
+
+// word returns the i-th word of a vocabulary, of at least two syllables.
+func word(i int) string {
+	var b strings.Builder
+	for n := i + len(syllables); n > 0; n /= len(syllables) {
+		b.WriteString(syllables[n%len(syllables)])
+	}
+	return b.String()
+}
+
+// Settings returns the settings of an index for the corpus: its text fields are searchable, in
+// the order of the spec, "category" and "popularity" are filterable, and hits are ranked by score
+// then popularity, which is sortable.
+func (c *Corpus) Settings(name string) config.IndexSettings {
+	settings := config.IndexSettings{
+		Name:             name,
+		FilterableFields: []string{"category", "popularity"},
+		SortableFields:   []string{"popularity"},
+		RankingCriteria:  []config.RankingCriterion{{Field: "~score", Order: "desc"}, {Field: "popularity", Order: "desc"}},
+	}
+	for _, field := range c.Spec.Fields {
+		settings.SearchableFields = append(settings.SearchableFields, field.Name)
+	}
+	settings.ApplyDefaults()
+	return settings
+}
+
+// Queries returns count queries of one to three consecutive words taken from the first field of
+// random documents, so most queries have hits, with a share of them mistyped or typed partially to
+// exercise typo tolerance and prefix search. The same corpus and count always give the same
+// queries.
+func (c *Corpus) Queries(count int) []string {
+	random := rand.New(rand.NewSource(c.Spec.Seed + 1))
+	field := c.Spec.Fields[0].Name
+	queries := make([]string, count)
+	for i := range queries {
+		words := strings.Fields(c.Documents[random.Intn(len(c.Documents))][field].(string))
+		length := min(1+random.Intn(3), len(words))
+		start := random.Intn(len(words) - length + 1)
+		query := words[start : start+length]
+		last := query[len(query)-1]
+		switch random.Intn(10) {
+		case 0: // Typo: two letters swapped
+			letters := []byte(last)
+			k := random.Intn(len(letters) - 1)
+			letters[k], letters[k+1] = letters[k+1], letters[k]
+			query[len(query)-1] = string(letters)
+		case 1: // Partially typed last word
+			query[len(query)-1] = last[:len(last)-1]
+		}
+		queries[i] = strings.Join(query, " ")
+	}
+	return queries
+}
@@ -0,0 +1,180 @@
+package benchmarks
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/indexing"
+	"github.com/gcbaptista/go-search-engine/internal/search"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+	"github.com/gcbaptista/go-search-engine/store"
+)
+
+// Limits and defaults of a benchmark run
+const (
+	MaxQueries       = 10000
+	MaxBatchSize     = 10000
+	DefaultQueries   = 200
+	DefaultBatchSize = 1000
+)
+
+// RunSpec describes a benchmark: the corpus indexed, in batches of BatchSize documents, and the
+// number of queries searched once it is indexed.
+type RunSpec struct {
+	Corpus    CorpusSpec `json:"corpus"`
+	Queries   int        `json:"queries,omitempty"`    // Defaults to DefaultQueries
+	BatchSize int        `json:"batch_size,omitempty"` // Defaults to DefaultBatchSize
+}
+
+// Latency summarizes the durations of an operation, in milliseconds.
+type Latency struct {
+	Mean float64 `json:"mean_ms"`
+	P50  float64 `json:"p50_ms"`
+	P95  float64 `json:"p95_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+}
+
+// Report is the outcome of a benchmark run.
+type Report struct {
+	Corpus             CorpusSpec `json:"corpus"` // With its defaults applied
+	Terms              int        `json:"terms"`  // Distinct terms of the inverted index, prefix n-grams included
+	IndexingMs         float64    `json:"indexing_ms"`
+	DocumentsPerSecond float64    `json:"documents_per_second"`
+	BatchLatency       Latency    `json:"batch_latency"`
+	Queries            int        `json:"queries"`
+	SearchMs           float64    `json:"search_ms"`
+	QueriesPerSecond   float64    `json:"queries_per_second"`
+	SearchLatency      Latency    `json:"search_latency"`
+	ZeroResultQueries  int        `json:"zero_result_queries"`
+	MeanHits           float64    `json:"mean_hits"` // Mean total hits per query
+}
+
+// Bench is an index of a generated corpus, kept in memory only and separate from the indexes of
+// the engine.
+type Bench struct {
+	Corpus   *Corpus
+	Settings *config.IndexSettings
+	Indexer  *indexing.Service
+	Searcher *search.Service
+	index    *index.InvertedIndex
+}
+
+// NewBench generates the corpus of a spec and creates an empty index for it, with the corpus's
+// settings.
+func NewBench(spec CorpusSpec) (*Bench, error) {
+	corpus, err := NewCorpus(spec)
+	if err != nil {
+		return nil, err
+	}
+	settings := corpus.Settings("benchmark")
+	invIndex := index.NewInvertedIndex(&settings)
+	docStore := &store.DocumentStore{
+		Docs:                   make(map[uint32]model.Document),
+		ExternalIDtoInternalID: make(map[string]uint32),
+	}
+	indexer, err := indexing.NewService(invIndex, docStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create indexer service: %w", err)
+	}
+	indexer.SetSortableFields(settings.SortableFields)
+	searcher, err := search.NewService(invIndex, docStore, &settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search service: %w", err)
+	}
+	return &Bench{Corpus: corpus, Settings: &settings, Indexer: indexer, Searcher: searcher, index: invIndex}, nil
+}
+
+// Run generates a corpus, indexes it and searches it, reporting the throughput and latency of
+// both. Nothing is written to disk.
+func Run(spec RunSpec) (Report, error) {
+	if spec.Queries == 0 {
+		spec.Queries = DefaultQueries
+	}
+	if spec.BatchSize == 0 {
+		spec.BatchSize = DefaultBatchSize
+	}
+	if spec.Queries < 1 || spec.Queries > MaxQueries {
+		return Report{}, errors.NewValidationError("queries", fmt.Sprintf("must be between 1 and %d", MaxQueries))
+	}
+	if spec.BatchSize < 1 || spec.BatchSize > MaxBatchSize {
+		return Report{}, errors.NewValidationError("batch_size", fmt.Sprintf("must be between 1 and %d", MaxBatchSize))
+	}
+	bench, err := NewBench(spec.Corpus)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{Corpus: bench.Corpus.Spec, Queries: spec.Queries}
+
+	documents := bench.Corpus.Documents
+	var batches []time.Duration
+	start := time.Now()
+	for offset := 0; offset < len(documents); offset += spec.BatchSize {
+		batchStart := time.Now()
+		if err := bench.Indexer.AddDocuments(documents[offset:min(offset+spec.BatchSize, len(documents))]); err != nil {
+			return Report{}, fmt.Errorf("failed to index documents: %w", err)
+		}
+		batches = append(batches, time.Since(batchStart))
+	}
+	indexingTime := time.Since(start)
+	report.IndexingMs = milliseconds(indexingTime)
+	report.DocumentsPerSecond = float64(len(documents)) / indexingTime.Seconds()
+	report.BatchLatency = summarize(batches)
+	report.Terms = bench.index.Len()
+
+	queries := bench.Corpus.Queries(spec.Queries)
+	latencies := make([]time.Duration, len(queries))
+	totalHits := 0
+	start = time.Now()
+	for i, query := range queries {
+		queryStart := time.Now()
+		result, err := bench.Searcher.Search(services.SearchQuery{QueryString: query})
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to search '%s': %w", query, err)
+		}
+		latencies[i] = time.Since(queryStart)
+		totalHits += result.Total
+		if result.Total == 0 {
+			report.ZeroResultQueries++
+		}
+	}
+	searchTime := time.Since(start)
+	report.SearchMs = milliseconds(searchTime)
+	report.QueriesPerSecond = float64(len(queries)) / searchTime.Seconds()
+	report.SearchLatency = summarize(latencies)
+	report.MeanHits = float64(totalHits) / float64(len(queries))
+	return report, nil
+}
+
+// summarize returns the mean, percentiles and maximum of durations.
+func summarize(durations []time.Duration) Latency {
+	if len(durations) == 0 {
+		return Latency{}
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) float64 {
+		return milliseconds(sorted[int(p*float64(len(sorted)-1))])
+	}
+	return Latency{
+		Mean: milliseconds(total / time.Duration(len(sorted))),
+		P50:  percentile(0.50),
+		P95:  percentile(0.95),
+		P99:  percentile(0.99),
+		Max:  milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+// milliseconds converts a duration to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,16 +13,32 @@ import (
 
 	"github.com/gcbaptista/go-search-engine/api"
 	"github.com/gcbaptista/go-search-engine/internal/engine"
+	"github.com/gcbaptista/go-search-engine/internal/jobs"
+	"github.com/gcbaptista/go-search-engine/internal/logging"
+	"github.com/gcbaptista/go-search-engine/internal/tracing"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	// Define command-line flags
 	var (
-		help    = flag.Bool("help", false, "Show help message")
-		version = flag.Bool("version", false, "Show version information")
-		port    = flag.String("port", "8080", "Port to run the server on")
-		dataDir = flag.String("data-dir", "./search_data", "Directory to store search data")
+		help               = flag.Bool("help", false, "Show help message")
+		version            = flag.Bool("version", false, "Show version information")
+		port               = flag.String("port", "8080", "Port to run the server on")
+		dataDir            = flag.String("data-dir", "./search_data", "Directory to store search data")
+		renameGracePeriod  = flag.Duration("rename-grace-period", 5*time.Minute, "How long the old name of a renamed index keeps routing to it (0 disables)")
+		readOnly           = flag.Bool("read-only", false, "Open an existing data directory without writing to it or running jobs, e.g. for analytics next to a live server")
+		searchRateLimit    = flag.Float64("search-rate-limit", 0, "Search and document read requests per second allowed to each API key or IP (0 disables)")
+		searchBurst        = flag.Int("search-burst", 0, "Search requests each client may send at once (defaults to the rate)")
+		indexingRateLimit  = flag.Float64("indexing-rate-limit", 0, "Document and index write requests per second allowed to each API key or IP (0 disables)")
+		indexingBurst      = flag.Int("indexing-burst", 0, "Write requests each client may send at once (defaults to the rate)")
+		adminKey           = flag.String("admin-key", os.Getenv("SEARCH_ENGINE_ADMIN_KEY"), "Key allowed every request, which enables API keys (defaults to $SEARCH_ENGINE_ADMIN_KEY; unset leaves the API open)")
+		logLevel           = flag.String("log-level", "info", "Lowest level logged: debug, info, warn or error")
+		logFormat          = flag.String("log-format", logging.FormatText, "Log output format: text or json")
+		slowQueryThreshold = flag.Duration("slow-query-threshold", 0, "Log searches taking longer than this as slow queries (0 disables)")
+		traceExporter      = flag.String("tracing", tracing.ExporterNone, "Export OpenTelemetry traces: none, otlp (configured by the OTEL_EXPORTER_OTLP_* variables) or stdout")
+		traceSampleRatio   = flag.Float64("trace-sample-ratio", 1, "Share of the traces starting at the server that are sampled; callers' traces follow their traceparent header")
+		jobRetention       = flag.Duration("job-retention", jobs.DefaultRetention, "How long the records of finished jobs are kept (0 keeps them until cleaned up)")
 	)
 
 	flag.Parse()
@@ -37,6 +53,12 @@ func main() {
 		fmt.Printf("  %s                          # Start server on default port 8080\n", os.Args[0])
 		fmt.Printf("  %s --port 9000              # Start server on port 9000\n", os.Args[0])
 		fmt.Printf("  %s --data-dir /tmp/search   # Use custom data directory\n", os.Args[0])
+		fmt.Printf("  %s --read-only --port 8081  # Serve the data of a live server read-only\n", os.Args[0])
+		fmt.Printf("  %s --admin-key <secret>     # Require API keys\n", os.Args[0])
+		fmt.Printf("  %s --search-rate-limit 50   # Allow each client 50 searches per second\n", os.Args[0])
+		fmt.Printf("  %s --log-format json        # Log one JSON object per line\n", os.Args[0])
+		fmt.Printf("  %s --slow-query-threshold 200ms  # Log searches slower than 200ms\n", os.Args[0])
+		fmt.Printf("  %s --tracing otlp           # Send traces to the OTLP endpoint of $OTEL_EXPORTER_OTLP_ENDPOINT\n", os.Args[0])
 		return
 	}
 
@@ -47,15 +69,54 @@ func main() {
 		return
 	}
 
+	// Set up logging before anything logs
+	if err := logging.Setup(os.Stderr, *logLevel, *logFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging options: %v\n", err)
+		os.Exit(2)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), *traceExporter, *traceSampleRatio)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid tracing options: %v\n", err)
+		os.Exit(2)
+	}
+	if *traceExporter != tracing.ExporterNone {
+		slog.Info("tracing enabled", "exporter", *traceExporter, "sample_ratio", *traceSampleRatio)
+	}
+
 	// Initialize the search engine
-	log.Printf("Using data directory: %s", *dataDir)
-	searchEngine := engine.NewEngine(*dataDir)
+	slog.Info("using data directory", "data_dir", *dataDir)
+	var searchEngine *engine.Engine
+	if *readOnly {
+		if searchEngine, err = engine.NewReadOnlyEngine(*dataDir); err != nil {
+			fatal("failed to open data directory read-only", err)
+		}
+	} else {
+		searchEngine = engine.NewEngine(*dataDir)
+	}
+	searchEngine.SetRenameGracePeriod(*renameGracePeriod)
+	searchEngine.SetSlowQueryThreshold(*slowQueryThreshold)
+	searchEngine.SetJobRetention(*jobRetention)
+	if *adminKey != "" {
+		if err := searchEngine.SetAdminKey(*adminKey); err != nil {
+			fatal("invalid admin key", err)
+		}
+		slog.Info("API keys enabled")
+	} else {
+		slog.Warn("no admin key set, the API is open to every request")
+	}
 
-	// Initialize Gin router
-	router := gin.Default()
+	// Initialize Gin router; requests are logged by the API's request logger
+	router := gin.New()
+	router.Use(gin.Recovery())
 
 	// Setup API routes
-	api.SetupRoutes(router, searchEngine)
+	api.SetupRoutesWithOptions(router, searchEngine, api.RouteOptions{
+		RateLimits: api.RateLimits{
+			Search:   api.RateLimit{Rate: *searchRateLimit, Burst: *searchBurst},
+			Indexing: api.RateLimit{Rate: *indexingRateLimit, Burst: *indexingBurst},
+		},
+	})
 
 	// Configure HTTP server with timeouts to prevent hanging connections
 	srv := &http.Server{
@@ -69,9 +130,9 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server on port %s with timeouts configured...", *port)
+		slog.Info("starting server", "port", *port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal("failed to start server", err)
 		}
 	}()
 
@@ -79,15 +140,24 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("shutting down server")
 
 	// Give outstanding requests 30 seconds to complete
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
+		slog.Error("server forced to shutdown", "error", err)
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+
+	slog.Info("server exited")
+}
 
-	log.Println("Server exited")
+// fatal logs the error that stops the server and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -3,15 +3,456 @@
 package config
 
 import (
+	"net/url"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gcbaptista/go-search-engine/internal/langdetect"
+	"github.com/gcbaptista/go-search-engine/internal/stemmer"
 )
 
 // RankingCriterion defines a single field and direction to use for ranking search results.
 // The ranking is applied in the order specified in the IndexSettings.RankingCriteria slice.
 // Fields can be any document field, not just those in SearchableFields or FilterableFields.
 type RankingCriterion struct {
-	Field string `json:"field"` // Field name to rank by (e.g., "popularity", "title", "created_at"). Can be any document field.
-	Order string `json:"order"` // Sort order: "asc" for ascending, "desc" for descending
+	Field    string          `json:"field"`              // Field name to rank by (e.g., "popularity", "title", "created_at"). Can be any document field.
+	Order    string          `json:"order"`              // Sort order: "asc" for ascending, "desc" for descending
+	Selector RankingSelector `json:"selector,omitempty"` // Optional value ranked by when the field holds an array of numbers: "min", "max" or "avg"
+}
+
+// RankingSelector picks the value a ranking criterion sorts by from an array of numbers, so that
+// documents can be ranked by e.g. their best episode rating. A single number counts as an array of
+// one; other values in the array are ignored, and documents without numbers in the field rank as
+// if they did not have it.
+type RankingSelector string
+
+const (
+	RankingSelectorMin RankingSelector = "min" // Smallest number of the array
+	RankingSelectorMax RankingSelector = "max" // Largest number of the array
+	RankingSelectorAvg RankingSelector = "avg" // Mean of the numbers of the array
+)
+
+// FallbackStrategy is a way to relax a query that returned no results.
+type FallbackStrategy string
+
+const (
+	FallbackRelaxTypos      FallbackStrategy = "relax_typos"       // Allow typos on shorter words
+	FallbackDropRarestToken FallbackStrategy = "drop_rarest_token" // Drop the query word found in the fewest documents
+	FallbackMatchAny        FallbackStrategy = "match_any"         // Return documents matching any query word instead of all of them
+	FallbackBrowse          FallbackStrategy = "browse"            // Ignore the query words and return documents ordered by the ranking criteria
+)
+
+// IsValid reports whether the strategy is one of the supported fallback strategies.
+func (f FallbackStrategy) IsValid() bool {
+	switch f {
+	case FallbackRelaxTypos, FallbackDropRarestToken, FallbackMatchAny, FallbackBrowse:
+		return true
+	}
+	return false
+}
+
+// TextNormalization is the Unicode normalization of field and query text before it is tokenized.
+// Without it, accents are folded by the analyzer of the index locale, if any, and only ASCII
+// letters and digits make up tokens.
+type TextNormalization string
+
+const (
+	TextNormalizationFoldAccents TextNormalization = "fold_accents" // NFKD with diacritics removed: "café" matches "cafe" and full-width "ｃａｆｅ", in any script
+	TextNormalizationKeepAccents TextNormalization = "keep_accents" // NFKC only: full-width forms match ASCII, but "résumé" does not match "resume"
+)
+
+// IsValid reports whether the normalization is one of the supported text normalizations.
+func (n TextNormalization) IsValid() bool {
+	switch n {
+	case TextNormalizationFoldAccents, TextNormalizationKeepAccents:
+		return true
+	}
+	return false
+}
+
+// ScoringAlgorithm is the way the relevance score of a hit is computed from its matched terms.
+type ScoringAlgorithm string
+
+const (
+	ScoringTF   ScoringAlgorithm = "tf"   // Sum of the frequencies of the matched terms in their fields
+	ScoringBM25 ScoringAlgorithm = "bm25" // BM25: term frequencies saturated, weighted by term rarity and normalized by field length
+)
+
+// IsValid reports whether the algorithm is one of the supported scoring algorithms.
+func (a ScoringAlgorithm) IsValid() bool {
+	switch a {
+	case ScoringTF, ScoringBM25:
+		return true
+	}
+	return false
+}
+
+// DefaultLanguageField is the document field that stores the detected language when
+// LanguageDetection.LanguageField is not set.
+const DefaultLanguageField = "language"
+
+// LanguageDetection configures language detection at ingest. The language of each document is
+// detected from the text of Fields and stored in LanguageField, and the text of each field is
+// copied to a per-language field named "<field>.<language>" (e.g., "title.de"). Per-language
+// fields are analyzed with the analyzer of their language once they are added to SearchableFields.
+type LanguageDetection struct {
+	Fields        []string `json:"fields"`         // Text fields used for detection and routed to per-language fields
+	Languages     []string `json:"languages"`      // Candidate languages (e.g., ["en", "de"])
+	LanguageField string   `json:"language_field"` // Field storing the detected language; defaults to "language"
+}
+
+// LanguageFieldName returns the document field that stores the detected language.
+func (d *LanguageDetection) LanguageFieldName() string {
+	if d.LanguageField == "" {
+		return DefaultLanguageField
+	}
+	return d.LanguageField
+}
+
+// DefaultReplicaRefreshIntervalMs is how often writes are merged into a read replica when
+// ReadReplica.RefreshIntervalMs is not set.
+const DefaultReplicaRefreshIntervalMs = 1000
+
+// ReadReplica configures read/write splitting for an index. Searches are served from an in-memory
+// copy of the index that writes never lock, while the changes made by writes are merged into the
+// copy every RefreshIntervalMs. Searches therefore see writes up to one refresh interval late.
+type ReadReplica struct {
+	RefreshIntervalMs int `json:"refresh_interval_ms"` // How often writes are merged into the copy; defaults to 1000
+}
+
+// RefreshInterval returns how often writes are merged into the replica.
+func (r *ReadReplica) RefreshInterval() time.Duration {
+	if r.RefreshIntervalMs <= 0 {
+		return DefaultReplicaRefreshIntervalMs * time.Millisecond
+	}
+	return time.Duration(r.RefreshIntervalMs) * time.Millisecond
+}
+
+// Defaults applied when the CacheWarming fields are not set.
+const (
+	DefaultWarmingTopQueries  = 20
+	DefaultWarmingIntervalMs  = 60000
+	DefaultWarmingMaxQPS      = 5
+	DefaultWarmingWindowHours = 24
+)
+
+// CacheWarming configures a background refresher that re-executes the most popular queries of an
+// index, taken from search analytics, after writes to the index. The caches searches rely on, such
+// as decompressed documents and typo candidates, and the OS page cache are then warm again when
+// users send those queries. Queries are sent at most MaxQPS per second to spare live traffic.
+type CacheWarming struct {
+	TopQueries  int     `json:"top_queries"`  // Number of popular queries re-executed; defaults to 20
+	IntervalMs  int     `json:"interval_ms"`  // How often the index is checked for writes; defaults to 60000
+	MaxQPS      float64 `json:"max_qps"`      // Maximum warming queries per second; defaults to 5
+	WindowHours int     `json:"window_hours"` // Analytics window the popular queries are taken from; defaults to 24
+}
+
+// Queries returns the number of popular queries re-executed.
+func (w *CacheWarming) Queries() int {
+	if w.TopQueries <= 0 {
+		return DefaultWarmingTopQueries
+	}
+	return w.TopQueries
+}
+
+// Interval returns how often the index is checked for writes.
+func (w *CacheWarming) Interval() time.Duration {
+	if w.IntervalMs <= 0 {
+		return DefaultWarmingIntervalMs * time.Millisecond
+	}
+	return time.Duration(w.IntervalMs) * time.Millisecond
+}
+
+// QPS returns the maximum number of warming queries per second.
+func (w *CacheWarming) QPS() float64 {
+	if w.MaxQPS <= 0 {
+		return DefaultWarmingMaxQPS
+	}
+	return w.MaxQPS
+}
+
+// Window returns the analytics window the popular queries are taken from.
+func (w *CacheWarming) Window() time.Duration {
+	if w.WindowHours <= 0 {
+		return DefaultWarmingWindowHours * time.Hour
+	}
+	return time.Duration(w.WindowHours) * time.Hour
+}
+
+// Defaults applied when the SafeMode fields are not set.
+const (
+	DefaultSafeModeWindowMs          = 300000
+	DefaultSafeModeMinSearches       = 20
+	DefaultSafeModeMaxErrorRate      = 0.05
+	DefaultSafeModeMaxZeroResultRate = 0.5
+)
+
+// SafeMode watches the searches of an index for WindowMs after each asynchronous settings update.
+// Once MinSearches searches were seen, if the share of them failing or returning no results exceeds
+// its maximum, the settings in effect before the update are restored and an alert is posted to
+// WebhookURL. Rates are fractions between 0 and 1. The safe mode in effect before an update is the
+// one applied to it, so enabling safe mode protects the updates after it.
+type SafeMode struct {
+	WindowMs          int     `json:"window_ms"`            // How long searches are watched after an update; defaults to 300000
+	MinSearches       int     `json:"min_searches"`         // Searches needed before the rates are judged; defaults to 20
+	MaxErrorRate      float64 `json:"max_error_rate"`       // Share of failing searches that triggers a revert; defaults to 0.05
+	MaxZeroResultRate float64 `json:"max_zero_result_rate"` // Share of searches without results that triggers a revert; defaults to 0.5
+	WebhookURL        string  `json:"webhook_url"`          // Optional URL the revert alert is posted to as JSON
+}
+
+// Window returns how long searches are watched after an update.
+func (m *SafeMode) Window() time.Duration {
+	if m.WindowMs <= 0 {
+		return DefaultSafeModeWindowMs * time.Millisecond
+	}
+	return time.Duration(m.WindowMs) * time.Millisecond
+}
+
+// Searches returns the number of searches needed before the rates are judged.
+func (m *SafeMode) Searches() int {
+	if m.MinSearches <= 0 {
+		return DefaultSafeModeMinSearches
+	}
+	return m.MinSearches
+}
+
+// ErrorRate returns the share of failing searches that triggers a revert.
+func (m *SafeMode) ErrorRate() float64 {
+	if m.MaxErrorRate <= 0 {
+		return DefaultSafeModeMaxErrorRate
+	}
+	return m.MaxErrorRate
+}
+
+// ZeroResultRate returns the share of searches without results that triggers a revert.
+func (m *SafeMode) ZeroResultRate() float64 {
+	if m.MaxZeroResultRate <= 0 {
+		return DefaultSafeModeMaxZeroResultRate
+	}
+	return m.MaxZeroResultRate
+}
+
+// SearchLimits are circuit breakers bounding the work of a single search, so a spike of broad
+// queries on a large index cannot exhaust the server. A search cut short by a limit returns the
+// hits of the candidates it evaluated, with a warning saying which limit it hit.
+type SearchLimits struct {
+	MaxCandidates int `json:"max_candidates"` // Candidate documents evaluated per search, those with the lowest internal IDs first; 0 evaluates all of them
+	TimeLimitMs   int `json:"time_limit_ms"`  // Time after which a search stops evaluating candidates; 0 has no time limit
+}
+
+// TimeLimit returns the time after which a search stops evaluating candidates, or 0 when searches
+// have no time limit.
+func (l *SearchLimits) TimeLimit() time.Duration {
+	return time.Duration(l.TimeLimitMs) * time.Millisecond
+}
+
+// CompressionDeflate is the DEFLATE algorithm (RFC 1951), the default document compression algorithm.
+const CompressionDeflate = "deflate"
+
+// Defaults applied when the Compression fields are not set.
+const (
+	DefaultCompressionMinDocumentBytes = 512
+	DefaultCompressionCacheSize        = 1000
+)
+
+// Compression configures compression of stored documents, trading CPU on reads for memory.
+// Documents are decompressed when read, and the most recently read ones are kept decompressed in
+// an LRU cache of CacheSize documents.
+type Compression struct {
+	Algorithm        string `json:"algorithm"`          // Compression algorithm; only "deflate" is supported, the default
+	MinDocumentBytes int    `json:"min_document_bytes"` // Smaller documents are stored verbatim; defaults to 512
+	CacheSize        int    `json:"cache_size"`         // Decompressed documents kept in memory; defaults to 1000
+}
+
+// MinBytes returns the encoded size from which documents are compressed.
+func (c *Compression) MinBytes() int {
+	if c.MinDocumentBytes <= 0 {
+		return DefaultCompressionMinDocumentBytes
+	}
+	return c.MinDocumentBytes
+}
+
+// CachedDocuments returns the number of decompressed documents kept in the LRU cache.
+func (c *Compression) CachedDocuments() int {
+	if c.CacheSize <= 0 {
+		return DefaultCompressionCacheSize
+	}
+	return c.CacheSize
+}
+
+// DefaultMaxSegments is the number of segments kept when SegmentStorage.MaxSegments is not set.
+const DefaultMaxSegments = 8
+
+// SegmentStorage keeps the inverted index in immutable on-disk segments instead of memory, so the
+// index can be larger than the memory available. Segments are memory-mapped, so the operating
+// system keeps the pages of frequently searched terms in memory and loads the others on demand.
+// Changes are buffered in memory and written to a new segment whenever the index is persisted;
+// when there are more than MaxSegments segments, a background merge combines them into one.
+type SegmentStorage struct {
+	MaxSegments int `json:"max_segments"` // Segments kept before they are merged; defaults to 8
+}
+
+// SegmentLimit returns the number of segments kept before they are merged.
+func (s *SegmentStorage) SegmentLimit() int {
+	if s.MaxSegments <= 0 {
+		return DefaultMaxSegments
+	}
+	return s.MaxSegments
+}
+
+// Defaults applied when the QuerySanitizer fields are not set.
+const (
+	DefaultSanitizerMaxQueryLength        = 256
+	DefaultSanitizerMaxRepeatedCharacters = 3
+)
+
+// QuerySanitizer configures the cleanup of raw user queries before they are tokenized, protecting
+// search latency from pasted or abusive input. Control characters are always removed, runs of the
+// same letter are collapsed to MaxRepeatedCharacters, queries are clamped to MaxQueryLength
+// characters, and emoji are replaced by their token in EmojiTokens or dropped.
+type QuerySanitizer struct {
+	MaxQueryLength        int               `json:"max_query_length"`        // Longer queries are truncated; defaults to 256 characters
+	MaxRepeatedCharacters int               `json:"max_repeated_characters"` // Longer runs of the same letter are shortened; defaults to 3
+	EmojiTokens           map[string]string `json:"emoji_tokens"`            // Emoji mapped to query text (e.g. "🍕": "pizza"); other emoji are dropped
+}
+
+// QueryLength returns the maximum number of characters kept from a query.
+func (q *QuerySanitizer) QueryLength() int {
+	if q.MaxQueryLength <= 0 {
+		return DefaultSanitizerMaxQueryLength
+	}
+	return q.MaxQueryLength
+}
+
+// RepeatedCharacters returns the maximum length of a run of the same letter.
+func (q *QuerySanitizer) RepeatedCharacters() int {
+	if q.MaxRepeatedCharacters <= 0 {
+		return DefaultSanitizerMaxRepeatedCharacters
+	}
+	return q.MaxRepeatedCharacters
+}
+
+// Defaults applied when the SpellCorrection fields are not set.
+const (
+	DefaultSpellCorrectionMaxDistance   = 2
+	DefaultSpellCorrectionMinWordLength = 4
+)
+
+// SpellCorrection configures the correction of query words that are not in the index: before a
+// search, each unknown word of at least MinWordLength characters is replaced by the indexed word
+// closest to it within MaxDistance edits, preferring the most frequent one.
+type SpellCorrection struct {
+	MaxDistance   int `json:"max_distance"`    // Most edits between a query word and its correction, 1 or 2; defaults to 2
+	MinWordLength int `json:"min_word_length"` // Shorter query words are never corrected; defaults to 4 characters
+}
+
+// Distance returns the most edits between a query word and its correction.
+func (s *SpellCorrection) Distance() int {
+	if s.MaxDistance <= 0 {
+		return DefaultSpellCorrectionMaxDistance
+	}
+	return s.MaxDistance
+}
+
+// WordLength returns the length in characters of the shortest query word corrected.
+func (s *SpellCorrection) WordLength() int {
+	if s.MinWordLength <= 0 {
+		return DefaultSpellCorrectionMinWordLength
+	}
+	return s.MinWordLength
+}
+
+// DefaultEnglishStopWords is the built-in stop-word list, used when StopWords.Words is empty.
+var DefaultEnglishStopWords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in", "into", "is", "it",
+	"no", "not", "of", "on", "or", "such", "that", "the", "their", "then", "there", "these", "they",
+	"this", "to", "was", "will", "with",
+}
+
+// StopWords configures the removal of common words that carry little meaning, so that documents
+// do not match or rank only because they contain words like "the". Stop words are removed from
+// field values at index time and from queries at query time; a query made only of stop words is
+// searched as is. With KeepInPhrases, stop words are still indexed so quoted phrases match them.
+type StopWords struct {
+	Words         []string `json:"words"`           // Words to remove; defaults to DefaultEnglishStopWords
+	KeepInPhrases bool     `json:"keep_in_phrases"` // Index stop words and keep them in quoted phrases, so "the office" matches exactly
+}
+
+// List returns the stop words to remove.
+func (s *StopWords) List() []string {
+	if len(s.Words) == 0 {
+		return DefaultEnglishStopWords
+	}
+	return s.Words
+}
+
+// Defaults applied when the page size settings are not set.
+const (
+	DefaultSearchPageSize    = 10
+	DefaultMaxSearchPageSize = 1000
+)
+
+// MaxQueryTokenDecay is the largest query_token_decay. From 2 on, each query word already outweighs
+// all the words before it together.
+const MaxQueryTokenDecay = 2
+
+// IndexMetadata describes an index to the people operating it, so fleets of indexes can be told
+// apart and listed by tag. It has no effect on indexing or search.
+type IndexMetadata struct {
+	Description string   `json:"description,omitempty"` // What the index holds
+	Owner       string   `json:"owner,omitempty"`       // Team or person responsible for the index
+	Tags        []string `json:"tags,omitempty"`        // Free-form labels, e.g. the environment ("prod")
+}
+
+// HasTags reports whether the metadata has all of the tags. Any metadata, including none, has all
+// of an empty list of tags.
+func (m *IndexMetadata) HasTags(tags ...string) bool {
+	for _, tag := range tags {
+		if m == nil || !slices.Contains(m.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// FieldFormat describes how a filterable field writes numbers and dates in string values, so
+// filters compare them as numbers and dates. Filter values sent as strings are read the same way,
+// unless the query sets its own filter locale.
+type FieldFormat struct {
+	Locale     string `json:"locale,omitempty"`      // Locale of the values (e.g., "de" reads "1.234,56" and "31.12.2024")
+	DateFormat string `json:"date_format,omitempty"` // Date format of the values, e.g. "DD/MM/YYYY"; overrides the locale's date order
+}
+
+// dateFormatTokens translates the tokens of FieldFormat.DateFormat to Go time layout elements.
+var dateFormatTokens = strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02", "HH", "15", "mm", "04", "ss", "05")
+
+// DateLayout returns the Go time layout of the date format, or "" when no date format is set.
+func (f FieldFormat) DateLayout() string {
+	return dateFormatTokens.Replace(f.DateFormat)
+}
+
+// TypoBudget allocates typo tolerance by the priority order of SearchableFields, so typo matches
+// come from the short, high-priority fields rather than from long, noisy ones like descriptions.
+// The first TwoTypoFields searchable fields match with up to 2 typos, the next OneTypoFields with
+// up to 1 typo, and the remaining fields only match exactly. The word-size thresholds still apply.
+type TypoBudget struct {
+	TwoTypoFields int `json:"two_typo_fields"` // Number of top-priority fields matched with up to 2 typos
+	OneTypoFields int `json:"one_typo_fields"` // Number of following fields matched with up to 1 typo
+}
+
+// MaxTypos returns the most typos allowed in the searchable field at the given priority, 0 being
+// the first field.
+func (b *TypoBudget) MaxTypos(priority int) int {
+	switch {
+	case priority < b.TwoTypoFields:
+		return 2
+	case priority < b.TwoTypoFields+b.OneTypoFields:
+		return 1
+	}
+	return 0
 }
 
 // IndexSettings contains all configuration options for a search index.
@@ -29,17 +470,122 @@ type RankingCriterion struct {
 // This ensures higher-priority fields (like "title") are fully exhausted
 // before moving to lower-priority fields (like "description").
 type IndexSettings struct {
-	Name                      string             `json:"name"`                         // Unique name for the index
-	SearchableFields          []string           `json:"searchable_fields"`            // Fields that can be searched, in priority order (e.g., ["title", "cast", "genres"])
-	FilterableFields          []string           `json:"filterable_fields"`            // Fields that can be used in filters (exact match, range)
-	RankingCriteria           []RankingCriterion `json:"ranking_criteria"`             // Ordered list of ranking criteria, applied in sequence. Fields can be any document field.
-	MinWordSizeFor1Typo       int                `json:"min_word_size_for_1_typo"`     // Minimum word length to allow 1 typo (e.g., 4)
-	MinWordSizeFor2Typos      int                `json:"min_word_size_for_2_typos"`    // Minimum word length to allow 2 typos (e.g., 7)
-	FieldsWithoutPrefixSearch []string           `json:"fields_without_prefix_search"` // Fields for which prefix/n-gram search is disabled (only whole words indexed). Must be in SearchableFields.
-	NoTypoToleranceFields     []string           `json:"no_typo_tolerance_fields"`     // Fields for which typo tolerance is disabled (only exact matches). Must be in SearchableFields.
-	NonTypoTolerantWords      []string           `json:"non_typo_tolerant_words"`      // Specific words that should never be typo-matched (e.g., sensitive terms, proper nouns)
-	DistinctField             string             `json:"distinct_field"`               // Field to use for deduplication to avoid returning duplicate documents. Can be any document field.
-	// Future: Field weights for relevance scoring
+	Name                      string                 `json:"name"`                         // Unique name for the index
+	SearchableFields          []string               `json:"searchable_fields"`            // Fields that can be searched, in priority order (e.g., ["title", "cast", "genres"])
+	FilterableFields          []string               `json:"filterable_fields"`            // Fields that can be used in filters (exact match, range)
+	RankingCriteria           []RankingCriterion     `json:"ranking_criteria"`             // Ordered list of ranking criteria, applied in sequence. Fields can be any document field.
+	SortableFields            []string               `json:"sortable_fields"`              // Fields whose values are turned into typed sort keys as documents are indexed, so ranking criteria on them sort without reading documents (e.g., ["popularity", "release_date"])
+	MinWordSizeFor1Typo       int                    `json:"min_word_size_for_1_typo"`     // Minimum word length to allow 1 typo (e.g., 4)
+	MinWordSizeFor2Typos      int                    `json:"min_word_size_for_2_typos"`    // Minimum word length to allow 2 typos (e.g., 7)
+	FieldsWithoutPrefixSearch []string               `json:"fields_without_prefix_search"` // Fields for which prefix/n-gram search is disabled (only whole words indexed). Must be in SearchableFields.
+	NoTypoToleranceFields     []string               `json:"no_typo_tolerance_fields"`     // Fields for which typo tolerance is disabled (only exact matches). Must be in SearchableFields.
+	NonTypoTolerantWords      []string               `json:"non_typo_tolerant_words"`      // Specific words that should never be typo-matched (e.g., sensitive terms, proper nouns)
+	TypoBudget                *TypoBudget            `json:"typo_budget"`                  // Optional typo tolerance by searchable field priority: full typos on top fields, exact matches only on the rest
+	NumberTypos               bool                   `json:"number_typos"`                 // Let all-digit query words match with typos ("1999" -> "1990"), which is disabled by default
+	DistinctField             string                 `json:"distinct_field"`               // Field to use for deduplication to avoid returning duplicate documents. Can be any document field.
+	SuggestionField           string                 `json:"suggestion_field"`             // Searchable field whose values the _suggest endpoint completes (e.g., "title"). Empty completes indexed words.
+	Scorer                    string                 `json:"scorer"`                       // Name of a custom scorer registered on the engine. Empty uses the default frequency-based scoring.
+	ScoringAlgorithm          ScoringAlgorithm       `json:"scoring_algorithm"`            // How relevance scores are computed: "tf" (default) or "bm25". Custom scorers receive it as the base score.
+	Locale                    string                 `json:"locale"`                       // Language of the indexed content (e.g., "en", "de"). Selects the locale-specific analyzer and is used for locale routing.
+	ZeroResultFallbacks       []FallbackStrategy     `json:"zero_result_fallbacks"`        // Strategies tried in order when a query returns no results, until one finds hits
+	LanguageDetection         *LanguageDetection     `json:"language_detection"`           // Optional language detection at ingest, routing text to per-language fields
+	FilterScoreWeight         float64                `json:"filter_score_weight"`          // Weight of the filter score added to the relevance score (~score). 0 keeps filter scores out of relevance.
+	ReadReplica               *ReadReplica           `json:"read_replica"`                 // Optional read/write splitting: searches use a copy of the index refreshed with the writes
+	DocumentCompression       *Compression           `json:"document_compression"`         // Optional compression of stored documents
+	SegmentStorage            *SegmentStorage        `json:"segment_storage"`              // Optional on-disk, memory-mapped storage of the inverted index
+	QuerySanitizer            *QuerySanitizer        `json:"query_sanitizer"`              // Optional cleanup of raw user queries before tokenization
+	StopWords                 *StopWords             `json:"stop_words"`                   // Optional removal of common words from fields and queries
+	Synonyms                  map[string]string      `json:"synonyms"`                     // One-way replacements of query words (e.g., {"tv": "television"}); the replacement can have several words
+	SpellCorrection           *SpellCorrection       `json:"spell_correction"`             // Optional correction of query words that are not in the index
+	CacheWarming              *CacheWarming          `json:"cache_warming"`                // Optional re-execution of popular queries after writes
+	SafeMode                  *SafeMode              `json:"safe_mode"`                    // Optional automatic revert of settings updates followed by failing or empty searches
+	SearchLimits              *SearchLimits          `json:"search_limits"`                // Optional circuit breakers bounding the candidates and time a single search evaluates
+	CompoundWords             bool                   `json:"compound_words"`               // Index hyphenated words joined as well as split ("sci-fi" -> "sci", "fi", "scifi") and keep contractions one word ("don't" -> "dont")
+	WordCharacters            string                 `json:"word_characters"`              // ASCII punctuation and symbols that are part of words instead of separating them (e.g., "_/" keeps "src/main_test" one token)
+	Stemming                  bool                   `json:"stemming"`                     // Index and search words by their stem in the language of the locale or of per-language fields, so "running" matches "run". Only English has a stemmer.
+	TextNormalization         TextNormalization      `json:"text_normalization"`           // Unicode normalization of text before tokenization: "fold_accents" or "keep_accents". Empty folds the accents of the locale's analyzer only.
+	CJKFields                 []string               `json:"cjk_fields"`                   // Searchable fields whose Chinese, Japanese and Korean text is split into overlapping two-character words, since it has no spaces between words. Must be in SearchableFields.
+	GenerateDocumentIDs       bool                   `json:"generate_document_ids"`        // Assign a generated UUID to documents added without a documentID
+	DefaultPageSize           int                    `json:"default_page_size"`            // Hits per page of searches that do not set a page size; defaults to 10
+	MaxPageSize               int                    `json:"max_page_size"`                // Largest page size a search can request; defaults to 1000
+	DefaultRetrievableFields  []string               `json:"default_retrievable_fields"`   // Document fields returned by searches that do not set retrievable_fields (e.g., leave out raw descriptions); empty returns every field
+	Metadata                  *IndexMetadata         `json:"metadata"`                     // Optional description, owner and tags of the index
+	FieldWeights              map[string]float64     `json:"field_weights"`                // Multiplier of the scores of matches in each searchable field (e.g., {"title": 3}); fields without a weight count 1
+	QueryTokenDecay           float64                `json:"query_token_decay"`            // Weight of each query word relative to the word before it when scores are summed: below 1 favors the first words typed (e.g., 0.8), above 1 the last ones. 0 or 1 weights every word the same.
+	FieldFormats              map[string]FieldFormat `json:"field_formats"`                // Locale and date format of string numbers and dates in filterable fields (e.g., {"price": {"locale": "de"}})
+}
+
+// SearchPageSize returns the number of hits per page of searches that do not set a page size.
+func (settings *IndexSettings) SearchPageSize() int {
+	if settings.DefaultPageSize <= 0 {
+		return min(DefaultSearchPageSize, settings.SearchPageSizeLimit())
+	}
+	return settings.DefaultPageSize
+}
+
+// SearchPageSizeLimit returns the largest page size a search can request.
+func (settings *IndexSettings) SearchPageSizeLimit() int {
+	if settings.MaxPageSize <= 0 {
+		return DefaultMaxSearchPageSize
+	}
+	return settings.MaxPageSize
+}
+
+// FieldMaxTypos returns the most typos a query word can have to match a word of the searchable
+// field: none for NoTypoToleranceFields and fields that are not searchable, the typo budget's
+// allowance for the field's priority when one is set, and 2 otherwise.
+func (settings *IndexSettings) FieldMaxTypos(field string) int {
+	priority := slices.Index(settings.SearchableFields, field)
+	if priority < 0 || slices.Contains(settings.NoTypoToleranceFields, field) {
+		return 0
+	}
+	if settings.TypoBudget == nil {
+		return 2
+	}
+	return settings.TypoBudget.MaxTypos(priority)
+}
+
+// hasStemmer reports whether the locale or a language of language detection has a stemmer.
+func (settings *IndexSettings) hasStemmer() bool {
+	if stemmer.IsSupported(settings.Locale) {
+		return true
+	}
+	if detection := settings.LanguageDetection; detection != nil {
+		for _, language := range detection.Languages {
+			if stemmer.IsSupported(language) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NestedFields returns the dot-notation paths ("credits.cast.name") among the searchable,
+// filterable, sortable, ranking, distinct and language detection fields, which the indexing service
+// resolves in the nested objects of documents. Per-language fields of language detection are left
+// out.
+func (settings *IndexSettings) NestedFields() []string {
+	fields := slices.Concat(settings.SearchableFields, settings.FilterableFields, settings.SortableFields, []string{settings.DistinctField})
+	for _, criterion := range settings.RankingCriteria {
+		fields = append(fields, criterion.Field)
+	}
+	perLanguage := make(map[string]bool)
+	if detection := settings.LanguageDetection; detection != nil {
+		fields = append(fields, detection.Fields...)
+		for _, field := range detection.Fields {
+			for _, language := range detection.Languages {
+				perLanguage[field+"."+language] = true
+			}
+		}
+	}
+
+	var paths []string
+	for _, field := range fields {
+		if strings.Contains(field, ".") && !perLanguage[field] && !slices.Contains(paths, field) {
+			paths = append(paths, field)
+		}
+	}
+	return paths
 }
 
 // ValidateFieldNames validates field names for basic requirements.
@@ -51,9 +597,12 @@ func (settings *IndexSettings) ValidateFieldNames() []string {
 	// Check for duplicate field names within each category
 	conflicts = append(conflicts, checkDuplicates("searchable_fields", settings.SearchableFields)...)
 	conflicts = append(conflicts, checkDuplicates("filterable_fields", settings.FilterableFields)...)
+	conflicts = append(conflicts, checkDuplicates("sortable_fields", settings.SortableFields)...)
 	conflicts = append(conflicts, checkDuplicates("fields_without_prefix_search", settings.FieldsWithoutPrefixSearch)...)
 	conflicts = append(conflicts, checkDuplicates("no_typo_tolerance_fields", settings.NoTypoToleranceFields)...)
 	conflicts = append(conflicts, checkDuplicates("non_typo_tolerant_words", settings.NonTypoTolerantWords)...)
+	conflicts = append(conflicts, checkDuplicates("default_retrievable_fields", settings.DefaultRetrievableFields)...)
+	conflicts = append(conflicts, checkDuplicates("cjk_fields", settings.CJKFields)...)
 
 	// Validate field references across configurations
 	conflicts = append(conflicts, settings.validateFieldReferences()...)
@@ -62,9 +611,12 @@ func (settings *IndexSettings) ValidateFieldNames() []string {
 	allFields := make([]string, 0)
 	allFields = append(allFields, settings.SearchableFields...)
 	allFields = append(allFields, settings.FilterableFields...)
+	allFields = append(allFields, settings.SortableFields...)
 	allFields = append(allFields, settings.FieldsWithoutPrefixSearch...)
 	allFields = append(allFields, settings.NoTypoToleranceFields...)
 	allFields = append(allFields, settings.NonTypoTolerantWords...)
+	allFields = append(allFields, settings.DefaultRetrievableFields...)
+	allFields = append(allFields, settings.CJKFields...)
 	if settings.DistinctField != "" {
 		allFields = append(allFields, settings.DistinctField)
 	}
@@ -72,6 +624,8 @@ func (settings *IndexSettings) ValidateFieldNames() []string {
 	for _, field := range allFields {
 		if strings.TrimSpace(field) == "" {
 			conflicts = append(conflicts, "Field name cannot be empty or whitespace-only")
+		} else if slices.Contains(strings.Split(field, "."), "") {
+			conflicts = append(conflicts, "Field '"+field+"' has an empty segment in its dot-notation path")
 		}
 	}
 
@@ -123,15 +677,251 @@ func (settings *IndexSettings) validateFieldReferences() []string {
 		}
 	}
 
+	// Validate that fields in CJKFields are actually searchable
+	for _, field := range settings.CJKFields {
+		if !searchableFieldsSet[field] {
+			errors = append(errors, "Field '"+field+"' in cjk_fields is not in searchable_fields")
+		}
+	}
+
+	// Validate that the suggestion field is searchable
+	if settings.SuggestionField != "" && !searchableFieldsSet[settings.SuggestionField] {
+		errors = append(errors, "Field '"+settings.SuggestionField+"' in suggestion_field is not in searchable_fields")
+	}
+
+	// Validate that weighted fields are searchable and their weights positive
+	for field, weight := range settings.FieldWeights {
+		if !searchableFieldsSet[field] {
+			errors = append(errors, "Field '"+field+"' in field_weights is not in searchable_fields")
+		} else if weight <= 0 {
+			errors = append(errors, "Weight of field '"+field+"' in field_weights must be greater than 0")
+		}
+	}
+
+	// Validate that formatted fields are filterable and their date formats complete
+	for field, format := range settings.FieldFormats {
+		if !filterableFieldsSet[field] {
+			errors = append(errors, "Field '"+field+"' in field_formats is not in filterable_fields")
+			continue
+		}
+		if format.Locale == "" && format.DateFormat == "" {
+			errors = append(errors, "Format of field '"+field+"' in field_formats must set a locale or a date_format")
+		}
+		if format.DateFormat != "" && !(strings.Contains(format.DateFormat, "YYYY") && strings.Contains(format.DateFormat, "MM") && strings.Contains(format.DateFormat, "DD")) {
+			errors = append(errors, "Date format '"+format.DateFormat+"' of field '"+field+"' in field_formats must contain YYYY, MM and DD")
+		}
+	}
+
+	// Validate zero-result fallback strategies
+	seenFallbacks := make(map[FallbackStrategy]bool)
+	for _, strategy := range settings.ZeroResultFallbacks {
+		if !strategy.IsValid() {
+			errors = append(errors, "Invalid strategy '"+string(strategy)+"' in zero_result_fallbacks (must be 'relax_typos', 'drop_rarest_token', 'match_any' or 'browse')")
+		} else if seenFallbacks[strategy] {
+			errors = append(errors, "Duplicate strategy '"+string(strategy)+"' found in zero_result_fallbacks")
+		}
+		seenFallbacks[strategy] = true
+	}
+
+	if settings.ScoringAlgorithm != "" && !settings.ScoringAlgorithm.IsValid() {
+		errors = append(errors, "Invalid scoring_algorithm '"+string(settings.ScoringAlgorithm)+"' (must be 'tf' or 'bm25')")
+	}
+
+	if budget := settings.TypoBudget; budget != nil {
+		if budget.TwoTypoFields < 0 {
+			errors = append(errors, "typo_budget.two_typo_fields cannot be negative")
+		}
+		if budget.OneTypoFields < 0 {
+			errors = append(errors, "typo_budget.one_typo_fields cannot be negative")
+		}
+	}
+
+	if metadata := settings.Metadata; metadata != nil {
+		seenTags := make(map[string]bool)
+		for _, tag := range metadata.Tags {
+			if strings.TrimSpace(tag) == "" {
+				errors = append(errors, "metadata.tags cannot contain empty tags")
+			} else if seenTags[tag] {
+				errors = append(errors, "Duplicate tag '"+tag+"' found in metadata.tags")
+			}
+			seenTags[tag] = true
+		}
+	}
+
+	if settings.DefaultPageSize < 0 {
+		errors = append(errors, "default_page_size cannot be negative")
+	}
+	if settings.MaxPageSize < 0 {
+		errors = append(errors, "max_page_size cannot be negative")
+	}
+	if settings.DefaultPageSize > settings.SearchPageSizeLimit() {
+		errors = append(errors, "default_page_size cannot exceed max_page_size")
+	}
+
+	if settings.FilterScoreWeight < 0 {
+		errors = append(errors, "filter_score_weight cannot be negative")
+	}
+	if settings.QueryTokenDecay < 0 || settings.QueryTokenDecay > MaxQueryTokenDecay {
+		errors = append(errors, "query_token_decay must be between 0 and 2")
+	}
+
+	if settings.ReadReplica != nil && settings.ReadReplica.RefreshIntervalMs < 0 {
+		errors = append(errors, "read_replica.refresh_interval_ms cannot be negative")
+	}
+
+	if settings.SegmentStorage != nil && settings.SegmentStorage.MaxSegments < 0 {
+		errors = append(errors, "segment_storage.max_segments cannot be negative")
+	}
+
+	if compression := settings.DocumentCompression; compression != nil {
+		if compression.Algorithm != "" && compression.Algorithm != CompressionDeflate {
+			errors = append(errors, "document_compression.algorithm '"+compression.Algorithm+"' is not supported; supported: "+CompressionDeflate)
+		}
+		if compression.MinDocumentBytes < 0 {
+			errors = append(errors, "document_compression.min_document_bytes cannot be negative")
+		}
+		if compression.CacheSize < 0 {
+			errors = append(errors, "document_compression.cache_size cannot be negative")
+		}
+	}
+
+	if sanitizer := settings.QuerySanitizer; sanitizer != nil {
+		if sanitizer.MaxQueryLength < 0 {
+			errors = append(errors, "query_sanitizer.max_query_length cannot be negative")
+		}
+		if sanitizer.MaxRepeatedCharacters < 0 {
+			errors = append(errors, "query_sanitizer.max_repeated_characters cannot be negative")
+		}
+		for emoji := range sanitizer.EmojiTokens {
+			if emoji == "" {
+				errors = append(errors, "query_sanitizer.emoji_tokens cannot map an empty string")
+			}
+		}
+	}
+
+	for term, replacement := range settings.Synonyms {
+		if len(strings.Fields(term)) != 1 {
+			errors = append(errors, "synonyms key '"+term+"' must be a single word")
+		} else if strings.TrimSpace(replacement) == "" {
+			errors = append(errors, "synonyms replacement of '"+term+"' cannot be empty")
+		}
+	}
+
+	if correction := settings.SpellCorrection; correction != nil {
+		if correction.MaxDistance < 0 || correction.MaxDistance > 2 {
+			errors = append(errors, "spell_correction.max_distance must be between 0 and 2")
+		}
+		if correction.MinWordLength < 0 {
+			errors = append(errors, "spell_correction.min_word_length cannot be negative")
+		}
+	}
+
+	if warming := settings.CacheWarming; warming != nil {
+		if warming.TopQueries < 0 {
+			errors = append(errors, "cache_warming.top_queries cannot be negative")
+		}
+		if warming.IntervalMs < 0 {
+			errors = append(errors, "cache_warming.interval_ms cannot be negative")
+		}
+		if warming.MaxQPS < 0 {
+			errors = append(errors, "cache_warming.max_qps cannot be negative")
+		}
+		if warming.WindowHours < 0 {
+			errors = append(errors, "cache_warming.window_hours cannot be negative")
+		}
+	}
+
+	if limits := settings.SearchLimits; limits != nil {
+		if limits.MaxCandidates < 0 {
+			errors = append(errors, "search_limits.max_candidates cannot be negative")
+		}
+		if limits.TimeLimitMs < 0 {
+			errors = append(errors, "search_limits.time_limit_ms cannot be negative")
+		}
+	}
+
+	if safeMode := settings.SafeMode; safeMode != nil {
+		if safeMode.WindowMs < 0 {
+			errors = append(errors, "safe_mode.window_ms cannot be negative")
+		}
+		if safeMode.MinSearches < 0 {
+			errors = append(errors, "safe_mode.min_searches cannot be negative")
+		}
+		if safeMode.MaxErrorRate < 0 || safeMode.MaxErrorRate > 1 {
+			errors = append(errors, "safe_mode.max_error_rate must be between 0 and 1")
+		}
+		if safeMode.MaxZeroResultRate < 0 || safeMode.MaxZeroResultRate > 1 {
+			errors = append(errors, "safe_mode.max_zero_result_rate must be between 0 and 1")
+		}
+		if safeMode.WebhookURL != "" {
+			if parsed, err := url.Parse(safeMode.WebhookURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				errors = append(errors, "safe_mode.webhook_url must be an http or https URL")
+			}
+		}
+	}
+
+	for _, r := range settings.WordCharacters {
+		if r >= utf8.RuneSelf || !(unicode.IsPunct(r) || unicode.IsSymbol(r)) {
+			errors = append(errors, "word_characters can only contain ASCII punctuation and symbols, not '"+string(r)+"'")
+			break
+		}
+	}
+
+	if settings.TextNormalization != "" && !settings.TextNormalization.IsValid() {
+		errors = append(errors, "Invalid text_normalization '"+string(settings.TextNormalization)+"' (must be 'fold_accents' or 'keep_accents')")
+	}
+
+	if settings.Stemming && !settings.hasStemmer() {
+		errors = append(errors, "stemming requires a locale or a language_detection language with a stemmer (supported: 'en')")
+	}
+
+	if stopWords := settings.StopWords; stopWords != nil {
+		for _, word := range stopWords.Words {
+			if strings.TrimSpace(word) == "" {
+				errors = append(errors, "stop_words.words cannot contain an empty word")
+				break
+			}
+		}
+	}
+
+	if detection := settings.LanguageDetection; detection != nil {
+		if len(detection.Fields) == 0 {
+			errors = append(errors, "language_detection requires at least one field in fields")
+		}
+		if len(detection.Languages) == 0 {
+			errors = append(errors, "language_detection requires at least one language in languages")
+		}
+		errors = append(errors, checkDuplicates("language_detection.fields", detection.Fields)...)
+		errors = append(errors, checkDuplicates("language_detection.languages", detection.Languages)...)
+		for _, language := range detection.Languages {
+			if !langdetect.IsSupported(language) {
+				errors = append(errors, "Unsupported language '"+language+"' in language_detection.languages (must be one of 'en', 'de', 'fr', 'es', 'it', 'pt' or 'nl')")
+			}
+		}
+		for _, field := range detection.Fields {
+			if field == detection.LanguageFieldName() {
+				errors = append(errors, "Field '"+field+"' in language_detection.fields cannot also be the language_field")
+			}
+		}
+	}
+
 	// Note: DistinctField can be any field that exists in documents - no validation needed
 	// Note: RankingCriteria fields can be any field that exists in documents - no validation needed
 
-	// Validate ranking criteria order values only
+	// Validate ranking criteria order and selector values only
 	for _, criterion := range settings.RankingCriteria {
 		// Validate order values
 		if criterion.Order != "asc" && criterion.Order != "desc" {
 			errors = append(errors, "Invalid order '"+criterion.Order+"' for field '"+criterion.Field+"' in ranking_criteria (must be 'asc' or 'desc')")
 		}
+		switch criterion.Selector {
+		case "", RankingSelectorMin, RankingSelectorMax, RankingSelectorAvg:
+		default:
+			errors = append(errors, "Invalid selector '"+string(criterion.Selector)+"' for field '"+criterion.Field+"' in ranking_criteria (must be 'min', 'max' or 'avg')")
+		}
+		if criterion.Selector != "" && strings.HasPrefix(criterion.Field, "~") {
+			errors = append(errors, "Field '"+criterion.Field+"' in ranking_criteria cannot have a selector")
+		}
 	}
 
 	return errors
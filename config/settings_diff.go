@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// Diff compares the settings with those of another index, by JSON key. Nested settings are compared
+// field by field; lists are compared as a whole. Null values, empty strings and empty lists count
+// as not set.
+func (settings *IndexSettings) Diff(other *IndexSettings) model.SettingsDiff {
+	diff := model.SettingsDiff{
+		IndexName:  settings.Name,
+		OtherIndex: other.Name,
+		Added:      []model.SettingChange{},
+		Removed:    []model.SettingChange{},
+		Changed:    []model.SettingChange{},
+	}
+
+	from := settings.flatten()
+	to := other.flatten()
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, exists := from[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+		switch {
+		case !inFrom:
+			diff.Added = append(diff.Added, model.SettingChange{Key: key, To: toValue})
+		case !inTo:
+			diff.Removed = append(diff.Removed, model.SettingChange{Key: key, From: fromValue})
+		case !reflect.DeepEqual(fromValue, toValue):
+			diff.Changed = append(diff.Changed, model.SettingChange{Key: key, From: fromValue, To: toValue})
+		}
+	}
+	diff.Identical = len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0
+	return diff
+}
+
+// flatten returns the settings that are set by dotted JSON key, without the index name.
+func (settings *IndexSettings) flatten() map[string]interface{} {
+	var values map[string]interface{}
+	// Settings are plain data, so they always encode
+	encoded, _ := json.Marshal(settings)
+	_ = json.Unmarshal(encoded, &values)
+	delete(values, "name")
+
+	flat := make(map[string]interface{})
+	flattenValues("", values, flat)
+	return flat
+}
+
+// flattenValues adds the set values of a decoded JSON object to flat, keyed by their dotted path.
+func flattenValues(prefix string, values map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case nil:
+		case string:
+			if v != "" {
+				flat[key] = v
+			}
+		case map[string]interface{}:
+			flattenValues(key, v, flat)
+		case []interface{}:
+			if len(v) > 0 {
+				flat[key] = v
+			}
+		default:
+			flat[key] = v
+		}
+	}
+}
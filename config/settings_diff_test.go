@@ -0,0 +1,56 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestIndexSettingsDiff(t *testing.T) {
+	live := IndexSettings{
+		Name:                "products_v1",
+		SearchableFields:    []string{"title", "description"},
+		MinWordSizeFor1Typo: 4,
+		DistinctField:       "sku",
+		QuerySanitizer:      &QuerySanitizer{MaxQueryLength: 128},
+	}
+	candidate := IndexSettings{
+		Name:                  "products_v2",
+		SearchableFields:      []string{"title", "brand", "description"},
+		MinWordSizeFor1Typo:   4,
+		NoTypoToleranceFields: []string{},
+		QuerySanitizer:        &QuerySanitizer{MaxQueryLength: 256},
+		TypoBudget:            &TypoBudget{TwoTypoFields: 1},
+	}
+
+	diff := live.Diff(&candidate)
+	if diff.IndexName != "products_v1" || diff.OtherIndex != "products_v2" || diff.Identical {
+		t.Errorf("Unexpected diff header: %+v", diff)
+	}
+	expectedAdded := []model.SettingChange{
+		{Key: "typo_budget.one_typo_fields", To: float64(0)},
+		{Key: "typo_budget.two_typo_fields", To: float64(1)},
+	}
+	if !reflect.DeepEqual(diff.Added, expectedAdded) {
+		t.Errorf("Added = %+v, want %+v", diff.Added, expectedAdded)
+	}
+	expectedRemoved := []model.SettingChange{{Key: "distinct_field", From: "sku", To: nil}}
+	if !reflect.DeepEqual(diff.Removed, expectedRemoved) {
+		t.Errorf("Removed = %+v, want %+v", diff.Removed, expectedRemoved)
+	}
+	expectedChanged := []model.SettingChange{
+		{Key: "query_sanitizer.max_query_length", From: float64(128), To: float64(256)},
+		{Key: "searchable_fields", From: []interface{}{"title", "description"}, To: []interface{}{"title", "brand", "description"}},
+	}
+	if !reflect.DeepEqual(diff.Changed, expectedChanged) {
+		t.Errorf("Changed = %+v, want %+v", diff.Changed, expectedChanged)
+	}
+
+	renamed := live
+	renamed.Name = "products_copy"
+	renamed.FieldsWithoutPrefixSearch = []string{} // Empty lists count as not set
+	if diff := live.Diff(&renamed); !diff.Identical {
+		t.Errorf("Expected indexes differing only by name to be identical, got %+v", diff)
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -63,6 +64,20 @@ func TestValidateFieldReferences_RelaxedValidation(t *testing.T) {
 			expectedErrors: 1,
 			description:    "Invalid ranking order should still be caught",
 		},
+		{
+			name: "ranking selectors",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				RankingCriteria: []RankingCriterion{
+					{Field: "episode_ratings", Order: "desc", Selector: RankingSelectorMax},
+					{Field: "ratings", Order: "desc", Selector: "median"},
+					{Field: "~score", Order: "desc", Selector: RankingSelectorAvg},
+				},
+			},
+			expectedErrors: 2,
+			description:    "Unknown selectors and selectors on ~score should be caught",
+		},
 		{
 			name: "field reference validation still works for other fields",
 			settings: IndexSettings{
@@ -74,6 +89,180 @@ func TestValidateFieldReferences_RelaxedValidation(t *testing.T) {
 			expectedErrors: 1,
 			description:    "Other field reference validations should still work",
 		},
+		{
+			name: "suggestion field must be searchable",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				SuggestionField:  "category",
+			},
+			expectedErrors: 1,
+			description:    "A suggestion field that is not searchable should be caught",
+		},
+		{
+			name: "zero-result fallbacks must be known and unique",
+			settings: IndexSettings{
+				Name:                "test_index",
+				SearchableFields:    []string{"title"},
+				ZeroResultFallbacks: []FallbackStrategy{FallbackRelaxTypos, "guess", FallbackRelaxTypos, FallbackBrowse},
+			},
+			expectedErrors: 2,
+			description:    "Unknown and duplicate fallback strategies should be caught",
+		},
+		{
+			name: "negative filter score weight",
+			settings: IndexSettings{
+				Name:              "test_index",
+				SearchableFields:  []string{"title"},
+				FilterScoreWeight: -1,
+			},
+			expectedErrors: 1,
+			description:    "A negative filter score weight should be caught",
+		},
+		{
+			name: "query token decay out of range",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				QueryTokenDecay:  2.5,
+			},
+			expectedErrors: 1,
+			description:    "A query token decay above the maximum should be caught",
+		},
+		{
+			name: "invalid word characters",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				WordCharacters:   "_ é",
+			},
+			expectedErrors: 1,
+			description:    "Word characters other than ASCII punctuation and symbols should be caught",
+		},
+		{
+			name: "invalid default retrievable fields",
+			settings: IndexSettings{
+				Name:                     "test_index",
+				SearchableFields:         []string{"title"},
+				DefaultRetrievableFields: []string{"title", "title", " "},
+			},
+			expectedErrors: 2,
+			description:    "Duplicate and empty default retrievable fields should be caught",
+		},
+		{
+			name: "unknown text normalization",
+			settings: IndexSettings{
+				Name:              "test_index",
+				SearchableFields:  []string{"title"},
+				TextNormalization: "nfc",
+			},
+			expectedErrors: 1,
+			description:    "Text normalization should be fold_accents or keep_accents",
+		},
+		{
+			name: "cjk field not searchable",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				CJKFields:        []string{"description"},
+			},
+			expectedErrors: 1,
+			description:    "CJK fields should be searchable fields",
+		},
+		{
+			name: "stemming without a stemmer language",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				Locale:           "de",
+				Stemming:         true,
+			},
+			expectedErrors: 1,
+			description:    "Stemming should require a locale or detected language with a stemmer",
+		},
+		{
+			name: "negative read replica refresh interval",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				ReadReplica:      &ReadReplica{RefreshIntervalMs: -5},
+			},
+			expectedErrors: 1,
+			description:    "A negative read replica refresh interval should be caught",
+		},
+		{
+			name: "invalid document compression",
+			settings: IndexSettings{
+				Name:                "test_index",
+				SearchableFields:    []string{"title"},
+				DocumentCompression: &Compression{Algorithm: "zip", CacheSize: -1},
+			},
+			expectedErrors: 2,
+			description:    "An unsupported compression algorithm and a negative cache size should be caught",
+		},
+		{
+			name: "invalid query sanitizer",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				QuerySanitizer:   &QuerySanitizer{MaxQueryLength: -1, EmojiTokens: map[string]string{"": "empty"}},
+			},
+			expectedErrors: 2,
+			description:    "A negative query length and an empty emoji should be caught",
+		},
+		{
+			name: "invalid cache warming",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				CacheWarming:     &CacheWarming{TopQueries: -1, MaxQPS: -2},
+			},
+			expectedErrors: 2,
+			description:    "Negative query counts and rates should be caught",
+		},
+		{
+			name: "invalid search limits",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				SearchLimits:     &SearchLimits{MaxCandidates: -1, TimeLimitMs: -5},
+			},
+			expectedErrors: 2,
+			description:    "Negative candidate and time limits should be caught",
+		},
+		{
+			name: "invalid safe mode",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				SafeMode:         &SafeMode{WindowMs: -1, MaxErrorRate: 1.5, WebhookURL: "ftp://alerts"},
+			},
+			expectedErrors: 3,
+			description:    "A negative window, a rate above 1 and a non-HTTP webhook should be caught",
+		},
+		{
+			name: "invalid stop words",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				StopWords:        &StopWords{Words: []string{"the", ""}},
+			},
+			expectedErrors: 1,
+			description:    "An empty stop word should be caught",
+		},
+		{
+			name: "invalid language detection",
+			settings: IndexSettings{
+				Name:             "test_index",
+				SearchableFields: []string{"title"},
+				LanguageDetection: &LanguageDetection{
+					Fields:    []string{"title", "language"},
+					Languages: []string{"en", "ja", "en"},
+				},
+			},
+			expectedErrors: 3,
+			description:    "Unsupported and duplicate languages and a field used as language_field should be caught",
+		},
 		{
 			name: "comprehensive valid configuration",
 			settings: IndexSettings{
@@ -133,3 +322,45 @@ func TestValidateFieldReferences_BackwardCompatibility(t *testing.T) {
 		t.Errorf("Expected no errors for backward compatible configuration, got: %v", errors)
 	}
 }
+
+func TestFieldMaxTypos(t *testing.T) {
+	settings := IndexSettings{
+		SearchableFields:      []string{"title", "cast", "genres", "description"},
+		NoTypoToleranceFields: []string{"cast"},
+	}
+	if got := settings.FieldMaxTypos("title"); got != 2 {
+		t.Errorf("Expected 2 typos without a typo budget, got %d", got)
+	}
+
+	settings.TypoBudget = &TypoBudget{TwoTypoFields: 1, OneTypoFields: 2}
+	expected := map[string]int{"title": 2, "cast": 0, "genres": 1, "description": 0, "year": 0}
+	for field, want := range expected {
+		if got := settings.FieldMaxTypos(field); got != want {
+			t.Errorf("FieldMaxTypos(%q) = %d, want %d", field, got, want)
+		}
+	}
+
+	settings.TypoBudget = &TypoBudget{TwoTypoFields: -1}
+	if errors := settings.ValidateFieldNames(); len(errors) != 1 {
+		t.Errorf("Expected an error for a negative typo budget, got: %v", errors)
+	}
+}
+
+func TestNestedFields(t *testing.T) {
+	settings := IndexSettings{
+		SearchableFields:  []string{"title", "credits.cast.name", "title.de"},
+		FilterableFields:  []string{"details.year", "credits.cast.name"},
+		DistinctField:     "series.id",
+		RankingCriteria:   []RankingCriterion{{Field: "~score", Order: "desc"}, {Field: "stats.views", Order: "desc"}},
+		LanguageDetection: &LanguageDetection{Fields: []string{"title"}, Languages: []string{"en", "de"}},
+	}
+	want := []string{"credits.cast.name", "details.year", "series.id", "stats.views"}
+	if got := settings.NestedFields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NestedFields() = %v, want %v", got, want)
+	}
+
+	settings.FilterableFields = []string{"details..year"}
+	if errors := settings.ValidateFieldNames(); len(errors) != 1 {
+		t.Errorf("Expected an error for an empty path segment, got: %v", errors)
+	}
+}
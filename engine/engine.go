@@ -0,0 +1,25 @@
+// Package engine creates search engines for programs that embed this module, e.g. to serve the
+// routes of package api with their own job runner, rule store or document store.
+package engine
+
+import (
+	internalEngine "github.com/gcbaptista/go-search-engine/internal/engine"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// NewEngine creates a search engine keeping its data in dataDir, with the built-in extensions.
+func NewEngine(dataDir string) services.IndexManager {
+	return internalEngine.NewEngine(dataDir)
+}
+
+// NewEngineWithExtensions creates a search engine keeping its data in dataDir, built with the given
+// extensions. It fails if a query rewriter or scorer is nil or shares its name with another one.
+// Like the engine of the server, it implements the optional interfaces of package services, such as
+// services.ExtensionRegistry, which the api package looks for.
+func NewEngineWithExtensions(dataDir string, extensions services.Extensions) (services.IndexManager, error) {
+	eng, err := internalEngine.NewEngineWithExtensions(dataDir, extensions)
+	if err != nil {
+		return nil, err
+	}
+	return eng, nil
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+type constantScorer struct{}
+
+func (constantScorer) Name() string { return "constant" }
+
+func (constantScorer) Score(services.ScoringContext, services.ScoringCandidate) float64 { return 1 }
+
+func TestNewEngineWithExtensions(t *testing.T) {
+	dataDir := t.TempDir()
+
+	eng, err := NewEngineWithExtensions(dataDir, services.Extensions{Scorers: []services.Scorer{constantScorer{}, constantScorer{}}})
+	if err == nil || eng != nil {
+		t.Fatalf("NewEngineWithExtensions() = %v, %v, want no engine and an error for scorers sharing a name", eng, err)
+	}
+
+	eng, err = NewEngineWithExtensions(dataDir, services.Extensions{Scorers: []services.Scorer{constantScorer{}}})
+	if err != nil {
+		t.Fatalf("NewEngineWithExtensions() error = %v", err)
+	}
+	registry, ok := eng.(services.ExtensionRegistry)
+	if !ok {
+		t.Fatal("Expected the engine to implement services.ExtensionRegistry")
+	}
+	extensions := registry.Extensions()
+	t.Cleanup(extensions.JobRunner.Stop)
+	if len(extensions.Scorers) != 1 || extensions.RuleStore == nil || extensions.DocumentStore == nil {
+		t.Errorf("Extensions() = %+v, want the scorer and the built-in rule and document stores", extensions)
+	}
+
+	if err := eng.CreateIndex(config.IndexSettings{Name: "movies", SearchableFields: []string{"title"}, Scorer: "constant"}); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if indexes := eng.ListIndexes(); len(indexes) != 1 || indexes[0] != "movies" {
+		t.Errorf("ListIndexes() = %v, want [movies]", indexes)
+	}
+}
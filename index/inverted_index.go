@@ -3,21 +3,241 @@ package index
 import (
 	"bytes"
 	"encoding/gob"
+	"maps"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/typoutil"
 )
 
+// TermShards is the number of lock-striped shards the term dictionary is split into.
+const TermShards = 32
+
 // InvertedIndex maps a term (token) to a list of documents containing that term,
 // sorted by their ranking score (popularity).
+//
+// The term dictionary is split into TermShards shards, each with its own lock, so searches and
+// writes touching different terms don't block each other. Posting lists are never modified once
+// stored: writers store a new list instead, so a list returned by Get can be read without locks.
+//
+// Mu is the structural lock. Searches and term-level writes hold it shared; operations that must
+// appear atomic to searches, such as write batches or clearing the index, hold it exclusively.
+//
+// Term document frequencies are updated whenever a posting list is stored, and the field lengths of
+// documents whenever the indexing service indexes them, so scoring and query planning can read
+// them without scanning posting lists.
+//
+// With segments (see FlushSegment), posting lists are stored in immutable on-disk segments and the
+// term shards only buffer the changes made since the last flush.
+//
+// The indexed terms are also kept in a vocabulary trie, updated as terms are stored and deleted, so
+// typo lookups (see Typos) don't scan the term dictionary.
 type InvertedIndex struct {
-	Mu       sync.RWMutex
-	Index    map[string]PostingList
-	Settings *config.IndexSettings // Reference to settings for this index
+	Mu         sync.RWMutex
+	shards     [TermShards]termShard
+	fieldStats fieldStats
+	Settings   *config.IndexSettings // Reference to settings for this index
+
+	segmentsMu sync.Mutex                 // Serializes changes of the segment list
+	segments   atomic.Pointer[[]*Segment] // Segments, oldest first; nil without segments
+
+	vocabularyMu sync.Mutex                        // Serializes changes of the vocabulary
+	vocabulary   atomic.Pointer[typoutil.TermTrie] // Indexed terms; nil when there are none
+
+	version atomic.Uint64 // Incremented whenever a posting list changes
+}
+
+// termShard is one lock stripe of the term dictionary.
+type termShard struct {
+	mu       sync.RWMutex
+	postings map[string]PostingList
+	docFreqs map[string]int // Distinct documents per term
+}
+
+// NewInvertedIndex creates an empty inverted index.
+func NewInvertedIndex(settings *config.IndexSettings) *InvertedIndex {
+	ii := &InvertedIndex{Settings: settings}
+	ii.Reset()
+	return ii
+}
+
+// shard returns the shard holding a term, using FNV-1a to spread terms across shards.
+func (ii *InvertedIndex) shard(term string) *termShard {
+	hash := uint32(2166136261)
+	for i := 0; i < len(term); i++ {
+		hash ^= uint32(term[i])
+		hash *= 16777619
+	}
+	return &ii.shards[hash%TermShards]
+}
+
+// Get returns the posting list of a term.
+func (ii *InvertedIndex) Get(term string) (PostingList, bool) {
+	shard := ii.shard(term)
+	shard.mu.RLock()
+	postings, buffered := shard.postings[term]
+	shard.mu.RUnlock()
+	if buffered || ii.segments.Load() == nil {
+		return postings, postings != nil
+	}
+	return ii.segmentPostings(term)
+}
+
+// Set stores the posting list of a term. The list must not be modified afterwards.
+func (ii *InvertedIndex) Set(term string, postings PostingList) {
+	shard := ii.shard(term)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	shard.setUnsafe(term, postings)
+	ii.updateVocabulary(term, postings != nil)
+	ii.version.Add(1)
+}
+
+// setUnsafe stores the posting list of a term, leaving the vocabulary alone. The caller must hold
+// shard.mu.
+func (shard *termShard) setUnsafe(term string, postings PostingList) {
+	if shard.postings == nil {
+		shard.postings = make(map[string]PostingList)
+	}
+	shard.setDocumentFrequencyUnsafe(term, postings)
+	shard.postings[term] = postings
+}
+
+// Delete removes a term and its posting list.
+func (ii *InvertedIndex) Delete(term string) {
+	shard := ii.shard(term)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	shard.setDocumentFrequencyUnsafe(term, nil)
+	ii.updateVocabulary(term, false)
+	ii.version.Add(1)
+	if ii.segments.Load() == nil {
+		delete(shard.postings, term)
+		return
+	}
+	// The deletion hides the term in the segments until it is flushed
+	if shard.postings == nil {
+		shard.postings = make(map[string]PostingList)
+	}
+	shard.postings[term] = nil
+}
+
+// Len returns the number of indexed terms.
+func (ii *InvertedIndex) Len() int {
+	if ii.segments.Load() == nil {
+		total := 0
+		for i := range ii.shards {
+			shard := &ii.shards[i]
+			shard.mu.RLock()
+			total += len(shard.postings)
+			shard.mu.RUnlock()
+		}
+		return total
+	}
+
+	total := 0
+	ii.rangeBuffered(func(_ string, _ PostingList) bool {
+		total++
+		return true
+	})
+	ii.rangeSegments(ii.bufferedTerms(), func(_ string, _ []byte) bool {
+		total++
+		return true
+	})
+	return total
+}
+
+// Range calls fn for every term and its posting list, one shard at a time and then the terms of
+// the segments, until fn returns false. fn must not modify the index.
+func (ii *InvertedIndex) Range(fn func(term string, postings PostingList) bool) {
+	if !ii.rangeBuffered(fn) || ii.segments.Load() == nil {
+		return
+	}
+	ii.rangeSegments(ii.bufferedTerms(), func(term string, body []byte) bool {
+		postings, err := decodeBody(body)
+		return err != nil || fn(term, postings)
+	})
+}
+
+// rangeBuffered calls fn for every term buffered in the shards, skipping deleted terms, until fn
+// returns false. It reports whether all terms were visited.
+func (ii *InvertedIndex) rangeBuffered(fn func(term string, postings PostingList) bool) bool {
+	for i := range ii.shards {
+		shard := &ii.shards[i]
+		shard.mu.RLock()
+		for term, postings := range shard.postings {
+			if postings != nil && !fn(term, postings) {
+				shard.mu.RUnlock()
+				return false
+			}
+		}
+		shard.mu.RUnlock()
+	}
+	return true
+}
+
+// Terms returns all indexed terms, in no particular order.
+func (ii *InvertedIndex) Terms() []string {
+	terms := make([]string, 0, ii.Len())
+	ii.Range(func(term string, _ PostingList) bool {
+		terms = append(terms, term)
+		return true
+	})
+	return terms
+}
+
+// Reset removes all terms, including the segments, whose files are left for the caller to remove.
+func (ii *InvertedIndex) Reset() {
+	ii.segmentsMu.Lock()
+	ii.setSegmentListUnsafe(nil)
+	ii.segmentsMu.Unlock()
+	ii.vocabularyMu.Lock()
+	ii.vocabulary.Store(nil)
+	ii.vocabularyMu.Unlock()
+	for i := range ii.shards {
+		shard := &ii.shards[i]
+		shard.mu.Lock()
+		shard.postings = make(map[string]PostingList)
+		shard.docFreqs = make(map[string]int)
+		shard.mu.Unlock()
+	}
+	ii.fieldStats.mu.Lock()
+	ii.fieldStats.documents = nil
+	ii.fieldStats.totals = nil
+	ii.fieldStats.mu.Unlock()
+	ii.version.Add(1)
+}
+
+// Version returns a number that changes whenever a posting list is stored or deleted, so data
+// derived from the index can tell when it is out of date.
+func (ii *InvertedIndex) Version() uint64 {
+	return ii.version.Load()
+}
+
+// Clone returns a copy of the index as it is now. Posting lists, field lengths and vocabularies are
+// never modified once stored, so the copy shares them and only the term dictionary is copied. The
+// caller must keep writers out while cloning, for the copy to be a consistent view.
+func (ii *InvertedIndex) Clone() *InvertedIndex {
+	clone := &InvertedIndex{Settings: ii.Settings}
+	clone.segments.Store(ii.segments.Load())
+	clone.vocabulary.Store(ii.vocabulary.Load())
+	for i := range ii.shards {
+		shard, cloneShard := &ii.shards[i], &clone.shards[i]
+		shard.mu.RLock()
+		cloneShard.postings = maps.Clone(shard.postings)
+		cloneShard.docFreqs = maps.Clone(shard.docFreqs)
+		shard.mu.RUnlock()
+	}
+	ii.fieldStats.mu.RLock()
+	clone.fieldStats.documents = maps.Clone(ii.fieldStats.documents)
+	clone.fieldStats.totals = maps.Clone(ii.fieldStats.totals)
+	ii.fieldStats.mu.RUnlock()
+	return clone
 }
 
 // gobInvertedIndexData is a helper struct for Gob encoding/decoding InvertedIndex data.
-// It excludes the mutex.
+// It stores the term dictionary as a single map and excludes the locks.
 type gobInvertedIndexData struct {
 	Index    map[string]PostingList
 	Settings *config.IndexSettings
@@ -28,8 +248,13 @@ func (ii *InvertedIndex) GobEncode() ([]byte, error) {
 	ii.Mu.RLock() // Ensure consistent data during encoding
 	defer ii.Mu.RUnlock()
 
+	postings := make(map[string]PostingList, ii.Len())
+	ii.Range(func(term string, list PostingList) bool {
+		postings[term] = list
+		return true
+	})
 	dataToEncode := gobInvertedIndexData{
-		Index:    ii.Index,
+		Index:    postings,
 		Settings: ii.Settings,
 	}
 
@@ -54,13 +279,16 @@ func (ii *InvertedIndex) GobDecode(data []byte) error {
 	ii.Mu.Lock() // Ensure exclusive access during decoding
 	defer ii.Mu.Unlock()
 
-	ii.Index = decodedData.Index
-	ii.Settings = decodedData.Settings
-
-	// Ensure maps are initialized if they were nil after decoding (e.g. from an empty file)
-	if ii.Index == nil {
-		ii.Index = make(map[string]PostingList)
+	ii.Reset()
+	for term, postings := range decodedData.Index {
+		shard := ii.shard(term)
+		shard.mu.Lock()
+		shard.setUnsafe(term, postings)
+		shard.mu.Unlock()
 	}
+	ii.Settings = decodedData.Settings
+	ii.rebuildFieldLengths()
+	ii.rebuildVocabulary()
 
 	// Settings can be nil if not present, no need to force initialize unless required by logic
 	return nil
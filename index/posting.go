@@ -7,7 +7,7 @@ type PostingEntry struct {
 	FieldName  string  // The name of the field where the term was found (e.g., "title", "tags")
 	Score      float64 // For now, term frequency within this field for this document
 	IsFullWord bool    // True if this token represents a complete word from the original text, false if it's a generated n-gram (prefix)
-	Positions  []int   // Added to store token positions
+	Positions  []int   // Word positions of the term in the field, in ascending order; only set for full words
 }
 
 // PostingList is a slice of PostingEntry.
@@ -0,0 +1,380 @@
+package index
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Segment files start with segmentMagic, followed by the number of terms and the offset of the term
+// table. The entries come next, one per term in ascending term order: the term, then the entry
+// body. The term table at the end holds the offset of each entry, so terms are found by binary
+// search without reading the whole file.
+//
+// An entry body is a flags byte, the document frequency and the posting entries; a tombstone body
+// has no posting entries and hides the term in older segments.
+const (
+	segmentMagic      = "GSESEG01"
+	segmentHeaderSize = len(segmentMagic) + 4 + 8
+
+	segmentFlagTombstone = 1
+)
+
+// Segment is an immutable, sorted set of posting lists stored in a file. Its contents are
+// memory-mapped where the platform supports it, so only the pages of the terms read are loaded.
+// Posting lists are decoded into new lists when read and never reference the mapped memory.
+type Segment struct {
+	name      string // File name, relative to the directory of the index's segments
+	size      int64
+	data      []byte
+	unmap     func() error
+	termCount int
+	table     int // Offset of the term table
+}
+
+// OpenSegment maps a segment file written by FlushSegment or MergeSegments. The mapping is released
+// once the segment is no longer referenced, so a segment replaced by a merge stays readable by the
+// copies of the index still using it.
+func OpenSegment(path string) (*Segment, error) {
+	file, err := os.Open(path) // #nosec G304 -- segment paths are built by the engine
+	if err != nil {
+		return nil, fmt.Errorf("failed to open segment %s: %w", path, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open segment %s: %w", path, err)
+	}
+	if info.Size() < int64(segmentHeaderSize) || info.Size() > math.MaxInt {
+		return nil, fmt.Errorf("segment %s is not a segment file", path)
+	}
+	data, unmap, err := mapFile(file, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to map segment %s: %w", path, err)
+	}
+
+	segment := &Segment{name: filepath.Base(path), size: info.Size(), data: data, unmap: unmap}
+	if err := segment.readHeader(); err != nil {
+		_ = unmap()
+		return nil, fmt.Errorf("segment %s is corrupted: %w", path, err)
+	}
+	runtime.SetFinalizer(segment, (*Segment).release)
+	return segment, nil
+}
+
+// readHeader checks the magic and reads the term count and the term table position.
+func (s *Segment) readHeader() error {
+	if string(s.data[:len(segmentMagic)]) != segmentMagic {
+		return fmt.Errorf("not a segment file")
+	}
+	s.termCount = int(binary.LittleEndian.Uint32(s.data[len(segmentMagic):]))
+	table := binary.LittleEndian.Uint64(s.data[len(segmentMagic)+4:])
+	if table < uint64(segmentHeaderSize) || table > uint64(len(s.data)) || (uint64(len(s.data))-table)/8 < uint64(s.termCount) {
+		return fmt.Errorf("term table out of bounds")
+	}
+	s.table = int(table)
+	return nil
+}
+
+// release unmaps the segment.
+func (s *Segment) release() {
+	if s.unmap != nil {
+		_ = s.unmap()
+		s.unmap = nil
+	}
+}
+
+// Name returns the file name of the segment.
+func (s *Segment) Name() string {
+	return s.name
+}
+
+// entry returns the term and the body of the i-th entry in term order.
+func (s *Segment) entry(i int) (term, body []byte, err error) {
+	offset := binary.LittleEndian.Uint64(s.data[s.table+8*i:])
+	if offset >= uint64(s.table) {
+		return nil, nil, fmt.Errorf("entry %d out of bounds", i)
+	}
+	reader := segmentReader{data: s.data[:s.table], pos: int(offset)}
+	term = reader.bytes()
+	body = reader.bytes()
+	return term, body, reader.err
+}
+
+// find returns the body of a term's entry.
+func (s *Segment) find(term string) ([]byte, bool) {
+	var err error
+	i := sort.Search(s.termCount, func(i int) bool {
+		entryTerm, _, entryErr := s.entry(i)
+		if entryErr != nil {
+			err = entryErr
+			return true
+		}
+		return string(entryTerm) >= term
+	})
+	if err == nil && i < s.termCount {
+		var entryTerm, body []byte
+		if entryTerm, body, err = s.entry(i); err == nil && string(entryTerm) == term {
+			return body, true
+		}
+	}
+	if err != nil {
+		slog.Warn("segment is corrupted", "segment", s.name, "error", err)
+	}
+	return nil, false
+}
+
+// rangeEntries calls fn for every entry in term order until fn returns false.
+func (s *Segment) rangeEntries(fn func(term string, body []byte) bool) {
+	for i := 0; i < s.termCount; i++ {
+		term, body, err := s.entry(i)
+		if err != nil {
+			slog.Warn("segment is corrupted", "segment", s.name, "error", err)
+			return
+		}
+		if !fn(string(term), body) {
+			return
+		}
+	}
+}
+
+// rangePrefix calls fn for the entries whose term starts with prefix, in term order, until fn
+// returns false.
+func (s *Segment) rangePrefix(prefix string, fn func(term string, body []byte) bool) {
+	start := sort.Search(s.termCount, func(i int) bool {
+		term, _, err := s.entry(i)
+		return err != nil || string(term) >= prefix
+	})
+	for i := start; i < s.termCount; i++ {
+		term, body, err := s.entry(i)
+		if err != nil {
+			slog.Warn("segment is corrupted", "segment", s.name, "error", err)
+			return
+		}
+		if !strings.HasPrefix(string(term), prefix) || !fn(string(term), body) {
+			return
+		}
+	}
+}
+
+// isTombstone reports whether an entry body hides its term.
+func isTombstone(body []byte) bool {
+	return len(body) > 0 && body[0]&segmentFlagTombstone != 0
+}
+
+// bodyDocumentFrequency reads the document frequency of an entry body.
+func bodyDocumentFrequency(body []byte) int {
+	reader := segmentReader{data: body, pos: 1}
+	frequency := reader.uvarint()
+	if reader.err != nil {
+		return 0
+	}
+	return int(frequency)
+}
+
+// decodeBody decodes the posting list of an entry body.
+func decodeBody(body []byte) (PostingList, error) {
+	reader := segmentReader{data: body, pos: 1}
+	reader.uvarint() // Document frequency
+	count := reader.uvarint()
+	if reader.err != nil || count > uint64(len(body)) {
+		return nil, fmt.Errorf("invalid posting count")
+	}
+	postings := make(PostingList, count)
+	fields := make(map[string]string) // Field names repeat across entries, so they are allocated once
+	for i := range postings {
+		entry := &postings[i]
+		entry.DocID = uint32(reader.uvarint())
+		fieldBytes := reader.bytes()
+		field, known := fields[string(fieldBytes)]
+		if !known {
+			field = string(fieldBytes)
+			fields[field] = field
+		}
+		entry.FieldName = field
+		entry.Score = math.Float64frombits(reader.uint64())
+		entry.IsFullWord = reader.byte() != 0
+		if positions := reader.uvarint(); positions > uint64(len(body)) {
+			return nil, fmt.Errorf("invalid position count")
+		} else if positions > 0 {
+			entry.Positions = make([]int, positions)
+			previous := 0
+			for j := range entry.Positions {
+				previous += int(reader.uvarint())
+				entry.Positions[j] = previous
+			}
+		}
+		if reader.err != nil {
+			return nil, reader.err
+		}
+	}
+	return postings, nil
+}
+
+// encodeBody encodes a posting list, or a tombstone for a nil list, as an entry body.
+func encodeBody(postings PostingList) []byte {
+	if postings == nil {
+		return []byte{segmentFlagTombstone}
+	}
+	body := []byte{0}
+	body = binary.AppendUvarint(body, uint64(documentFrequency(postings)))
+	body = binary.AppendUvarint(body, uint64(len(postings)))
+	for _, entry := range postings {
+		body = binary.AppendUvarint(body, uint64(entry.DocID))
+		body = binary.AppendUvarint(body, uint64(len(entry.FieldName)))
+		body = append(body, entry.FieldName...)
+		body = binary.LittleEndian.AppendUint64(body, math.Float64bits(entry.Score))
+		if entry.IsFullWord {
+			body = append(body, 1)
+		} else {
+			body = append(body, 0)
+		}
+		body = binary.AppendUvarint(body, uint64(len(entry.Positions)))
+		previous := 0
+		for _, position := range entry.Positions {
+			body = binary.AppendUvarint(body, uint64(position-previous))
+			previous = position
+		}
+	}
+	return body
+}
+
+// segmentWriter writes the entries of a segment file, which must be added in ascending term order.
+type segmentWriter struct {
+	file    *os.File
+	buf     *bufio.Writer
+	offset  uint64
+	offsets []uint64
+}
+
+// newSegmentWriter creates a temporary file next to path for a segment.
+func newSegmentWriter(path string) (*segmentWriter, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create segment %s: %w", path, err)
+	}
+	// Temporary files are only readable by their owner; other processes may open the data read-only
+	if err := file.Chmod(0644); err != nil { // #nosec G302 -- index files are not secret
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to create segment %s: %w", path, err)
+	}
+	w := &segmentWriter{file: file, buf: bufio.NewWriter(file), offset: uint64(segmentHeaderSize)}
+	// The header is rewritten once the term table position is known
+	_, _ = w.buf.Write(make([]byte, segmentHeaderSize))
+	return w, nil
+}
+
+// add writes the entry of a term.
+func (w *segmentWriter) add(term string, body []byte) {
+	w.offsets = append(w.offsets, w.offset)
+	entry := binary.AppendUvarint(nil, uint64(len(term)))
+	entry = append(entry, term...)
+	entry = binary.AppendUvarint(entry, uint64(len(body)))
+	_, _ = w.buf.Write(entry)
+	_, _ = w.buf.Write(body)
+	w.offset += uint64(len(entry) + len(body))
+}
+
+// finish writes the term table and the header and moves the file to path. On error, the temporary
+// file is removed.
+func (w *segmentWriter) finish(path string) (err error) {
+	defer func() {
+		if err != nil {
+			_ = w.file.Close()
+			_ = os.Remove(w.file.Name())
+		}
+	}()
+	for _, offset := range w.offsets {
+		_ = binary.Write(w.buf, binary.LittleEndian, offset)
+	}
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write segment %s: %w", path, err)
+	}
+	header := append([]byte(segmentMagic), make([]byte, 12)...)
+	binary.LittleEndian.PutUint32(header[len(segmentMagic):], uint32(len(w.offsets)))
+	binary.LittleEndian.PutUint64(header[len(segmentMagic)+4:], w.offset)
+	if _, err := w.file.WriteAt(header, 0); err != nil {
+		return fmt.Errorf("failed to write segment %s: %w", path, err)
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to write segment %s: %w", path, err)
+	}
+	if err := os.Rename(w.file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write segment %s: %w", path, err)
+	}
+	return nil
+}
+
+// abort removes the temporary file of a segment that is not finished.
+func (w *segmentWriter) abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
+
+// segmentReader decodes the values of a segment, recording the first out-of-bounds read.
+type segmentReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *segmentReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(r.data[min(r.pos, len(r.data)):])
+	if n <= 0 {
+		r.err = fmt.Errorf("truncated value at offset %d", r.pos)
+		return 0
+	}
+	r.pos += n
+	return value
+}
+
+func (r *segmentReader) bytes() []byte {
+	length := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if length > uint64(len(r.data)-r.pos) {
+		r.err = fmt.Errorf("truncated value at offset %d", r.pos)
+		return nil
+	}
+	value := r.data[r.pos : r.pos+int(length)]
+	r.pos += int(length)
+	return value
+}
+
+func (r *segmentReader) uint64() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data)-r.pos < 8 {
+		r.err = fmt.Errorf("truncated value at offset %d", r.pos)
+		return 0
+	}
+	value := binary.LittleEndian.Uint64(r.data[r.pos:])
+	r.pos += 8
+	return value
+}
+
+func (r *segmentReader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.data) {
+		r.err = fmt.Errorf("truncated value at offset %d", r.pos)
+		return 0
+	}
+	value := r.data[r.pos]
+	r.pos++
+	return value
+}
@@ -0,0 +1,326 @@
+package index
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+)
+
+// An inverted index with segments keeps most of its posting lists on disk. The term shards then
+// only buffer the posting lists written since the last flush, and tombstones (nil lists) for the
+// terms deleted since then, which override the segments. Segments are searched newest first.
+
+// Segments are unmapped once unreachable, so code reading their mapped memory keeps the segment
+// list it read them from reachable until it is done (see segmentBody).
+
+// segmentList returns the segments of the index, oldest first. The list must not be modified.
+func (ii *InvertedIndex) segmentList() []*Segment {
+	if segments := ii.segments.Load(); segments != nil {
+		return *segments
+	}
+	return nil
+}
+
+// setSegmentListUnsafe replaces the segments of the index. The caller must hold ii.segmentsMu.
+func (ii *InvertedIndex) setSegmentListUnsafe(segments []*Segment) {
+	if len(segments) == 0 {
+		ii.segments.Store(nil)
+		return
+	}
+	ii.segments.Store(&segments)
+}
+
+// segmentBody returns the entry body of a term in the newest of segments holding it. The body is
+// mapped memory of the segment, so the caller must keep segments reachable while reading it.
+func segmentBody(segments []*Segment, term string) ([]byte, bool) {
+	for i := len(segments) - 1; i >= 0; i-- {
+		if body, found := segments[i].find(term); found {
+			return body, true
+		}
+	}
+	return nil, false
+}
+
+// segmentPostings returns the posting list of a term stored in the segments.
+func (ii *InvertedIndex) segmentPostings(term string) (PostingList, bool) {
+	segments := ii.segmentList()
+	defer runtime.KeepAlive(segments)
+	body, found := segmentBody(segments, term)
+	if !found || isTombstone(body) {
+		return nil, false
+	}
+	postings, err := decodeBody(body)
+	if err != nil {
+		return nil, false
+	}
+	return postings, true
+}
+
+// segmentDocumentFrequency returns the document frequency of a term stored in the segments.
+func (ii *InvertedIndex) segmentDocumentFrequency(term string) int {
+	segments := ii.segmentList()
+	defer runtime.KeepAlive(segments)
+	if body, found := segmentBody(segments, term); found && !isTombstone(body) {
+		return bodyDocumentFrequency(body)
+	}
+	return 0
+}
+
+// rangeSegments calls fn for the terms of the segments that are not in seen, newest segment first,
+// until fn returns false. Visited terms, including deleted ones, are added to seen. It reports
+// whether fn stopped the iteration.
+func (ii *InvertedIndex) rangeSegments(seen map[string]struct{}, fn func(term string, body []byte) bool) bool {
+	segments := ii.segmentList()
+	defer runtime.KeepAlive(segments)
+	stopped := false
+	for i := len(segments) - 1; i >= 0 && !stopped; i-- {
+		segments[i].rangeEntries(func(term string, body []byte) bool {
+			if _, visited := seen[term]; visited {
+				return true
+			}
+			seen[term] = struct{}{}
+			if isTombstone(body) {
+				return true
+			}
+			stopped = !fn(term, body)
+			return !stopped
+		})
+	}
+	return stopped
+}
+
+// bufferedTerms returns the terms buffered in the shards, including deleted ones.
+func (ii *InvertedIndex) bufferedTerms() map[string]struct{} {
+	terms := make(map[string]struct{})
+	for i := range ii.shards {
+		shard := &ii.shards[i]
+		shard.mu.RLock()
+		for term := range shard.postings {
+			terms[term] = struct{}{}
+		}
+		shard.mu.RUnlock()
+	}
+	return terms
+}
+
+// OpenSegments makes the segment files at paths, oldest first, the segments of an empty index,
+// e.g. when the index is loaded from disk.
+func (ii *InvertedIndex) OpenSegments(paths []string) error {
+	segments := make([]*Segment, 0, len(paths))
+	for _, path := range paths {
+		segment, err := OpenSegment(path)
+		if err != nil {
+			return err
+		}
+		segments = append(segments, segment)
+	}
+	ii.segmentsMu.Lock()
+	ii.setSegmentListUnsafe(segments)
+	ii.segmentsMu.Unlock()
+	ii.rebuildVocabulary()
+	return nil
+}
+
+// SegmentNames returns the file names of the segments of the index, oldest first.
+func (ii *InvertedIndex) SegmentNames() []string {
+	segments := ii.segmentList()
+	names := make([]string, len(segments))
+	for i, segment := range segments {
+		names[i] = segment.Name()
+	}
+	return names
+}
+
+// SegmentStats returns the number of segments, their total size in bytes and the number of terms
+// buffered in memory since the last flush.
+func (ii *InvertedIndex) SegmentStats() (segments int, size int64, bufferedTerms int) {
+	for _, segment := range ii.segmentList() {
+		segments++
+		size += segment.size
+	}
+	for i := range ii.shards {
+		shard := &ii.shards[i]
+		shard.mu.RLock()
+		bufferedTerms += len(shard.postings)
+		shard.mu.RUnlock()
+	}
+	return segments, size, bufferedTerms
+}
+
+// FlushSegment writes the posting lists buffered in memory, and the deletions of terms stored in
+// segments, to a new segment file at path and serves them from it, releasing the memory they
+// used. It reports whether a segment was written: nothing is written when nothing is buffered.
+// The caller must keep writers out.
+func (ii *InvertedIndex) FlushSegment(path string) (bool, error) {
+	buffered := make(map[string]PostingList)
+	for i := range ii.shards {
+		shard := &ii.shards[i]
+		shard.mu.RLock()
+		maps.Copy(buffered, shard.postings)
+		shard.mu.RUnlock()
+	}
+	if len(buffered) == 0 {
+		return false, nil
+	}
+
+	writer, err := newSegmentWriter(path)
+	if err != nil {
+		return false, err
+	}
+	for _, term := range slices.Sorted(maps.Keys(buffered)) {
+		writer.add(term, encodeBody(buffered[term]))
+	}
+	if err := writer.finish(path); err != nil {
+		return false, err
+	}
+	segment, err := OpenSegment(path)
+	if err != nil {
+		return false, err
+	}
+
+	// The segment is added before the buffered lists are dropped, so searches find the terms in one
+	// or the other
+	ii.segmentsMu.Lock()
+	ii.setSegmentListUnsafe(append(slices.Clone(ii.segmentList()), segment))
+	ii.segmentsMu.Unlock()
+	for term := range buffered {
+		shard := ii.shard(term)
+		shard.mu.Lock()
+		delete(shard.postings, term)
+		delete(shard.docFreqs, term)
+		shard.mu.Unlock()
+	}
+	return true, nil
+}
+
+// MergeSegments merges the segments of the index into one segment file at path, keeping the newest
+// posting list of each term and dropping deleted terms, and replaces them with it. Writes and
+// flushes can proceed while segments are merged. It returns the file names of the segments
+// replaced, which can be removed once the index no longer lists them; none are returned when there
+// was nothing to merge or the segments were dropped meanwhile, e.g. by Reset.
+func (ii *InvertedIndex) MergeSegments(path string) ([]string, error) {
+	segments := ii.segmentList()
+	if len(segments) < 2 {
+		return nil, nil
+	}
+
+	writer, err := newSegmentWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeSegmentEntries(segments, writer); err != nil {
+		writer.abort()
+		return nil, err
+	}
+	if err := writer.finish(path); err != nil {
+		return nil, err
+	}
+	merged, err := OpenSegment(path)
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	ii.segmentsMu.Lock()
+	defer ii.segmentsMu.Unlock()
+	current := ii.segmentList()
+	if len(current) < len(segments) || !slices.Equal(current[:len(segments)], segments) {
+		_ = os.Remove(path)
+		return nil, nil
+	}
+	ii.setSegmentListUnsafe(append([]*Segment{merged}, current[len(segments):]...))
+
+	replaced := make([]string, len(segments))
+	for i, segment := range segments {
+		replaced[i] = segment.Name()
+	}
+	return replaced, nil
+}
+
+// mergeSegmentEntries writes the entries of segments, oldest first, in term order, taking each
+// term from the newest segment holding it. Deleted terms are left out, as no older segment remains
+// for them to hide.
+func mergeSegmentEntries(segments []*Segment, writer *segmentWriter) error {
+	type cursor struct {
+		segment *Segment
+		next    int
+		term    string
+		body    []byte
+	}
+	cursors := make([]*cursor, len(segments))
+	advance := func(c *cursor) error {
+		if c.next == c.segment.termCount {
+			c.segment = nil
+			return nil
+		}
+		term, body, err := c.segment.entry(c.next)
+		if err != nil {
+			return fmt.Errorf("segment %s is corrupted: %w", c.segment.Name(), err)
+		}
+		c.term, c.body = string(term), body
+		c.next++
+		return nil
+	}
+	for i, segment := range segments {
+		cursors[i] = &cursor{segment: segment}
+		if err := advance(cursors[i]); err != nil {
+			return err
+		}
+	}
+
+	for {
+		var term string
+		var body []byte
+		found := false
+		// Later segments are newer, so they win ties
+		for _, c := range cursors {
+			if c.segment != nil && (!found || c.term <= term) {
+				term, body, found = c.term, c.body, true
+			}
+		}
+		if !found {
+			return nil
+		}
+		for _, c := range cursors {
+			if c.segment != nil && c.term == term {
+				if err := advance(c); err != nil {
+					return err
+				}
+			}
+		}
+		if !isTombstone(body) {
+			writer.add(term, body)
+		}
+	}
+}
+
+// MaterializeSegments reads the posting lists of the segments into memory and stops using the
+// segments, e.g. when segment storage is disabled. The caller must keep writers out.
+func (ii *InvertedIndex) MaterializeSegments() {
+	if len(ii.segmentList()) == 0 {
+		return
+	}
+	stored := make(map[string]PostingList)
+	ii.rangeSegments(ii.bufferedTerms(), func(term string, body []byte) bool {
+		if postings, err := decodeBody(body); err == nil {
+			stored[term] = postings
+		}
+		return true
+	})
+	for term, postings := range stored {
+		ii.Set(term, postings)
+	}
+
+	ii.segmentsMu.Lock()
+	ii.setSegmentListUnsafe(nil)
+	ii.segmentsMu.Unlock()
+	// Deletions only had to hide terms of the segments
+	for i := range ii.shards {
+		shard := &ii.shards[i]
+		shard.mu.Lock()
+		maps.DeleteFunc(shard.postings, func(_ string, postings PostingList) bool { return postings == nil })
+		shard.mu.Unlock()
+	}
+}
@@ -0,0 +1,171 @@
+package index
+
+import (
+	"maps"
+	"sync"
+)
+
+// fieldStats holds the field lengths of an index's documents, set by the indexing service as it
+// indexes them, so scoring can read them without scanning posting lists or documents.
+type fieldStats struct {
+	mu        sync.RWMutex
+	documents map[uint32]map[string]int // Words per field, by document
+	totals    map[string]int            // Words per field, across all documents
+}
+
+// setUnsafe replaces the field lengths of a document; nil lengths remove them. The caller must
+// hold fs.mu.
+func (fs *fieldStats) setUnsafe(docID uint32, lengths map[string]int) {
+	if fs.documents == nil {
+		fs.documents = make(map[uint32]map[string]int)
+		fs.totals = make(map[string]int)
+	}
+	for field, length := range fs.documents[docID] {
+		fs.totals[field] -= length
+	}
+	if lengths == nil {
+		delete(fs.documents, docID)
+		return
+	}
+	fs.documents[docID] = lengths
+	for field, length := range lengths {
+		fs.totals[field] += length
+	}
+}
+
+// wordOccurrences returns how many times the term of a full-word posting entry occurs in its field.
+func wordOccurrences(entry PostingEntry) int {
+	if len(entry.Positions) == 0 {
+		// Indexes persisted before word positions were tracked only have the term frequency
+		return int(entry.Score)
+	}
+	return len(entry.Positions)
+}
+
+// documentFrequency returns the number of distinct documents in a posting list. A document has
+// one entry per field containing the term.
+func documentFrequency(postings PostingList) int {
+	if len(postings) <= 1 {
+		return len(postings)
+	}
+	docs := make(map[uint32]struct{}, len(postings))
+	for _, entry := range postings {
+		docs[entry.DocID] = struct{}{}
+	}
+	return len(docs)
+}
+
+// setDocumentFrequencyUnsafe stores the document frequency of a term's posting list; a nil list
+// removes it. The caller must hold shard.mu.
+func (shard *termShard) setDocumentFrequencyUnsafe(term string, postings PostingList) {
+	if postings == nil {
+		delete(shard.docFreqs, term)
+		return
+	}
+	if shard.docFreqs == nil {
+		shard.docFreqs = make(map[string]int)
+	}
+	shard.docFreqs[term] = documentFrequency(postings)
+}
+
+// rebuildFieldLengths recomputes the field lengths of all documents from the full-word entries of
+// the posting lists, for indexes loaded from disk.
+func (ii *InvertedIndex) rebuildFieldLengths() {
+	documents := make(map[uint32]map[string]int)
+	ii.Range(func(_ string, postings PostingList) bool {
+		for _, entry := range postings {
+			// Prefix entries are n-grams of words already counted by their full-word entry
+			if !entry.IsFullWord {
+				continue
+			}
+			if documents[entry.DocID] == nil {
+				documents[entry.DocID] = make(map[string]int)
+			}
+			documents[entry.DocID][entry.FieldName] += wordOccurrences(entry)
+		}
+		return true
+	})
+
+	ii.fieldStats.mu.Lock()
+	defer ii.fieldStats.mu.Unlock()
+	ii.fieldStats.documents = nil
+	for docID, lengths := range documents {
+		ii.fieldStats.setUnsafe(docID, lengths)
+	}
+}
+
+// DocumentFrequency returns the number of documents containing a term in any searchable field.
+func (ii *InvertedIndex) DocumentFrequency(term string) int {
+	shard := ii.shard(term)
+	shard.mu.RLock()
+	frequency := shard.docFreqs[term]
+	_, buffered := shard.postings[term]
+	shard.mu.RUnlock()
+	if buffered || ii.segments.Load() == nil {
+		return frequency
+	}
+	return ii.segmentDocumentFrequency(term)
+}
+
+// AllFieldLengths returns the field lengths of all documents, by internal ID, e.g. to persist them
+// with the segments. The returned maps must not be modified.
+func (ii *InvertedIndex) AllFieldLengths() map[uint32]map[string]int {
+	ii.fieldStats.mu.RLock()
+	defer ii.fieldStats.mu.RUnlock()
+	return maps.Clone(ii.fieldStats.documents)
+}
+
+// LoadFieldLengths replaces the field lengths of all documents with lengths persisted by
+// AllFieldLengths, instead of recomputing them from the posting lists.
+func (ii *InvertedIndex) LoadFieldLengths(documents map[uint32]map[string]int) {
+	ii.fieldStats.mu.Lock()
+	defer ii.fieldStats.mu.Unlock()
+	ii.fieldStats.documents = nil
+	for docID, lengths := range documents {
+		ii.fieldStats.setUnsafe(docID, lengths)
+	}
+}
+
+// SetFieldLengths stores the number of words of each searchable field of a document, replacing
+// the lengths stored for it before. The lengths must not be modified afterwards.
+func (ii *InvertedIndex) SetFieldLengths(docID uint32, lengths map[string]int) {
+	ii.fieldStats.mu.Lock()
+	defer ii.fieldStats.mu.Unlock()
+	ii.fieldStats.setUnsafe(docID, lengths)
+}
+
+// DeleteFieldLengths removes the field lengths of a deleted document.
+func (ii *InvertedIndex) DeleteFieldLengths(docID uint32) {
+	ii.SetFieldLengths(docID, nil)
+}
+
+// FieldLengths returns the number of words of each searchable field of a document. The returned
+// map must not be modified.
+func (ii *InvertedIndex) FieldLengths(docID uint32) map[string]int {
+	ii.fieldStats.mu.RLock()
+	defer ii.fieldStats.mu.RUnlock()
+	return ii.fieldStats.documents[docID]
+}
+
+// DocumentFieldLength returns the number of words of a field of a document.
+func (ii *InvertedIndex) DocumentFieldLength(docID uint32, field string) int {
+	ii.fieldStats.mu.RLock()
+	defer ii.fieldStats.mu.RUnlock()
+	return ii.fieldStats.documents[docID][field]
+}
+
+// FieldLength returns the number of word occurrences in a field, across all documents.
+func (ii *InvertedIndex) FieldLength(field string) int {
+	ii.fieldStats.mu.RLock()
+	defer ii.fieldStats.mu.RUnlock()
+	return ii.fieldStats.totals[field]
+}
+
+// AverageFieldLength returns the average number of words of a field over documentCount documents,
+// or 0 when there are no documents.
+func (ii *InvertedIndex) AverageFieldLength(field string, documentCount int) float64 {
+	if documentCount <= 0 {
+		return 0
+	}
+	return float64(ii.FieldLength(field)) / float64(documentCount)
+}
@@ -0,0 +1,52 @@
+package index
+
+import (
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// RangePrefix calls fn for every term starting with prefix, in ascending order, with its document
+// frequency, until fn returns false. Only the matching terms are collected, so exploring the
+// vocabulary by prefix does not copy the whole term dictionary the way Terms does.
+func (ii *InvertedIndex) RangePrefix(prefix string, fn func(term string, documentFrequency int) bool) {
+	// Deleted terms are recorded with a negative frequency, so they hide the same term in segments
+	frequencies := make(map[string]int)
+	for i := range ii.shards {
+		shard := &ii.shards[i]
+		shard.mu.RLock()
+		for term, postings := range shard.postings {
+			if !strings.HasPrefix(term, prefix) {
+				continue
+			}
+			if postings == nil {
+				frequencies[term] = -1
+			} else {
+				frequencies[term] = shard.docFreqs[term]
+			}
+		}
+		shard.mu.RUnlock()
+	}
+
+	segments := ii.segmentList()
+	for i := len(segments) - 1; i >= 0; i-- {
+		segments[i].rangePrefix(prefix, func(term string, body []byte) bool {
+			if _, found := frequencies[term]; !found {
+				if isTombstone(body) {
+					frequencies[term] = -1
+				} else {
+					frequencies[term] = bodyDocumentFrequency(body)
+				}
+			}
+			return true
+		})
+	}
+	runtime.KeepAlive(segments)
+
+	for _, term := range slices.Sorted(maps.Keys(frequencies)) {
+		if frequency := frequencies[term]; frequency > 0 && !fn(term, frequency) {
+			return
+		}
+	}
+}
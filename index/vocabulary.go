@@ -0,0 +1,35 @@
+package index
+
+import "github.com/gcbaptista/go-search-engine/internal/typoutil"
+
+// Typos returns the indexed terms within maxDistance edits of term, other than term itself, in
+// ascending order; at most maxResults of them unless maxResults is 0. Lookups walk the vocabulary
+// trie rather than scanning the terms, so they stay fast on large vocabularies.
+func (ii *InvertedIndex) Typos(term string, maxDistance int, maxResults int) []string {
+	return ii.vocabulary.Load().Typos(term, maxDistance, maxResults)
+}
+
+// updateVocabulary adds a term to the vocabulary, or removes it. The caller must hold the lock of
+// the term's shard, so the changes of a term reach the vocabulary in the order they were stored.
+func (ii *InvertedIndex) updateVocabulary(term string, indexed bool) {
+	if ii.vocabulary.Load().Contains(term) == indexed {
+		return
+	}
+	ii.vocabularyMu.Lock()
+	defer ii.vocabularyMu.Unlock()
+	if indexed {
+		ii.vocabulary.Store(ii.vocabulary.Load().Insert(term))
+	} else {
+		ii.vocabulary.Store(ii.vocabulary.Load().Remove(term))
+	}
+}
+
+// rebuildVocabulary builds the vocabulary from the indexed terms at once, e.g. when the index is
+// loaded from disk. The caller must keep writers out.
+func (ii *InvertedIndex) rebuildVocabulary() {
+	vocabulary := typoutil.NewTermTrie(ii.Terms())
+	ii.vocabularyMu.Lock()
+	defer ii.vocabularyMu.Unlock()
+	ii.vocabulary.Store(vocabulary)
+	ii.version.Add(1)
+}
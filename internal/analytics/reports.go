@@ -0,0 +1,140 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// GetTopQueries returns the queries of an index searched most often within the given window, whether
+// or not they returned results, most frequent first. Queries are compared case- and
+// whitespace-insensitively.
+func (s *Service) GetTopQueries(indexName string, window time.Duration, limit int) []model.QueryStats {
+	queries := s.queryStats(indexName, window)
+
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].SearchCount != queries[j].SearchCount {
+			return queries[i].SearchCount > queries[j].SearchCount
+		}
+		return queries[i].Query < queries[j].Query
+	})
+	return limitQueries(queries, limit)
+}
+
+// GetZeroResultQueries returns the queries of an index that returned no results within the given
+// window, those that did so most often first, pointing at content or synonyms the index lacks.
+func (s *Service) GetZeroResultQueries(indexName string, window time.Duration, limit int) []model.QueryStats {
+	queries := s.queryStats(indexName, window)
+
+	zeroResult := queries[:0]
+	for _, query := range queries {
+		if query.ZeroResultCount > 0 {
+			zeroResult = append(zeroResult, query)
+		}
+	}
+	sort.Slice(zeroResult, func(i, j int) bool {
+		if zeroResult[i].ZeroResultCount != zeroResult[j].ZeroResultCount {
+			return zeroResult[i].ZeroResultCount > zeroResult[j].ZeroResultCount
+		}
+		return zeroResult[i].Query < zeroResult[j].Query
+	})
+	return limitQueries(zeroResult, limit)
+}
+
+// GetLatencyReport summarizes the response times of the searches of an index within the given window
+func (s *Service) GetLatencyReport(indexName string, window time.Duration) model.LatencyReport {
+	windowStart := time.Now().Add(-window)
+
+	s.mutex.RLock()
+	var latencies []time.Duration
+	s.events.each(func(event *model.SearchEvent) {
+		if event.IndexName == indexName && event.Timestamp.After(windowStart) {
+			latencies = append(latencies, event.ResponseTime)
+		}
+	})
+	s.mutex.RUnlock()
+
+	report := model.LatencyReport{
+		IndexName:   indexName,
+		Window:      window.String(),
+		SearchCount: len(latencies),
+	}
+	if len(latencies) == 0 {
+		return report
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	report.AvgMs = durationMs(total / time.Duration(len(latencies)))
+	report.P50Ms = durationMs(percentile(latencies, 50))
+	report.P90Ms = durationMs(percentile(latencies, 90))
+	report.P95Ms = durationMs(percentile(latencies, 95))
+	report.P99Ms = durationMs(percentile(latencies, 99))
+	report.MaxMs = durationMs(latencies[len(latencies)-1])
+	return report
+}
+
+// queryStats aggregates the searches of an index within the given window by normalized query, in no
+// particular order. Searches without a query, such as filter-only ones, are left out.
+func (s *Service) queryStats(indexName string, window time.Duration) []model.QueryStats {
+	windowStart := time.Now().Add(-window)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats := make(map[string]*model.QueryStats)
+	totalTimes := make(map[string]time.Duration)
+	s.events.each(func(event *model.SearchEvent) {
+		if event.IndexName != indexName || !event.Timestamp.After(windowStart) {
+			return
+		}
+		query := normalizePopularQuery(event.Query)
+		if query == "" {
+			return
+		}
+		stat, ok := stats[query]
+		if !ok {
+			stat = &model.QueryStats{Query: query}
+			stats[query] = stat
+		}
+		stat.SearchCount++
+		if event.ResultCount == 0 {
+			stat.ZeroResultCount++
+		}
+		totalTimes[query] += event.ResponseTime
+		// Events are visited oldest first
+		stat.LastQueryID = event.QueryID
+		stat.LastSearchedAt = event.Timestamp
+	})
+
+	queries := make([]model.QueryStats, 0, len(stats))
+	for query, stat := range stats {
+		stat.AvgResponseTimeMs = durationMs(totalTimes[query] / time.Duration(stat.SearchCount))
+		queries = append(queries, *stat)
+	}
+	return queries
+}
+
+// limitQueries truncates queries to limit, unless limit is 0
+func limitQueries(queries []model.QueryStats, limit int) []model.QueryStats {
+	if limit > 0 && len(queries) > limit {
+		return queries[:limit]
+	}
+	return queries
+}
+
+// percentile returns the p-th percentile of the sorted latencies by the nearest-rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,52 @@
+package analytics
+
+import "github.com/gcbaptista/go-search-engine/model"
+
+// eventRing holds the latest search events in a circular buffer of fixed capacity, so recording an
+// event never moves the others.
+type eventRing struct {
+	events   []model.SearchEvent // Grows up to capacity, then wraps around
+	next     int                 // Position the next event overwrites once the ring is full
+	capacity int
+}
+
+// newEventRing returns a ring of the given capacity holding the latest of events, oldest first.
+func newEventRing(capacity int, events []model.SearchEvent) *eventRing {
+	ring := &eventRing{events: make([]model.SearchEvent, 0, min(capacity, len(events))), capacity: capacity}
+	if len(events) > capacity {
+		events = events[len(events)-capacity:]
+	}
+	ring.events = append(ring.events, events...)
+	return ring
+}
+
+// add records an event, dropping the oldest one when the ring is full.
+func (r *eventRing) add(event model.SearchEvent) {
+	if len(r.events) < r.capacity {
+		r.events = append(r.events, event)
+		return
+	}
+	r.events[r.next] = event
+	r.next = (r.next + 1) % r.capacity
+}
+
+// len returns the number of events in the ring.
+func (r *eventRing) len() int {
+	return len(r.events)
+}
+
+// each calls fn for every event, oldest first. fn must not modify the event.
+func (r *eventRing) each(fn func(event *model.SearchEvent)) {
+	for i := range r.events {
+		fn(&r.events[(r.next+i)%len(r.events)])
+	}
+}
+
+// all returns a copy of the events, oldest first.
+func (r *eventRing) all() []model.SearchEvent {
+	events := make([]model.SearchEvent, 0, len(r.events))
+	r.each(func(event *model.SearchEvent) {
+		events = append(events, *event)
+	})
+	return events
+}
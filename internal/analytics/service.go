@@ -1,13 +1,17 @@
 package analytics
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,29 +21,39 @@ import (
 )
 
 const (
-	analyticsDataFile = "search_data/analytics.json"
-	maxEventsToKeep   = 10000 // Keep last 10k events for performance
+	analyticsDataFile = "analytics.jsonl" // In the data directory of the engine
+	maxEventsToKeep   = 10000             // Keep last 10k events for performance
 )
 
-// Service implements analytics tracking and reporting
+// Service implements analytics tracking and reporting. Events are kept in a ring buffer of the
+// latest maxEventsToKeep events and appended to a rolling file of JSON lines, which is rewritten
+// with the ring's events once it holds twice as many.
 type Service struct {
 	mutex        sync.RWMutex
-	events       []model.SearchEvent
+	events       *eventRing
 	indexManager services.IndexManager
-	dataFilePath string
+	dataFilePath string // Empty when events are only kept in memory
+
+	pending    []model.SearchEvent // Events tracked but not appended to the data file yet
+	appending  bool                // Whether a goroutine is appending the pending events
+	fileEvents int                 // Events in the data file, used by the appending goroutine only
 }
 
-// NewService creates a new analytics service
-func NewService(indexManager services.IndexManager) *Service {
+// NewService creates a new analytics service saving its events in dataDir, or only keeping them in
+// memory when dataDir is empty
+func NewService(indexManager services.IndexManager, dataDir string) *Service {
 	service := &Service{
-		events:       make([]model.SearchEvent, 0),
+		events:       newEventRing(maxEventsToKeep, nil),
 		indexManager: indexManager,
-		dataFilePath: analyticsDataFile,
 	}
+	if dataDir == "" {
+		return service
+	}
+	service.dataFilePath = filepath.Join(dataDir, analyticsDataFile)
 
 	// Load existing analytics data
 	if err := service.loadData(); err != nil {
-		log.Printf("Warning: Failed to load analytics data: %v", err)
+		slog.Warn("failed to load analytics data", "error", err)
 	}
 
 	return service
@@ -51,23 +65,17 @@ func (s *Service) TrackSearchEvent(event model.SearchEvent) error {
 	defer s.mutex.Unlock()
 
 	event.Timestamp = time.Now()
-	s.events = append(s.events, event)
+	s.events.add(event)
 
-	// Keep only the latest events to prevent unbounded growth
-	if len(s.events) > maxEventsToKeep {
-		s.events = s.events[len(s.events)-maxEventsToKeep:]
+	if s.dataFilePath == "" {
+		return nil
+	}
+	// Persist data asynchronously, one goroutine at a time so events are appended in order
+	s.pending = append(s.pending, event)
+	if !s.appending {
+		s.appending = true
+		go s.appendPendingEvents()
 	}
-
-	// Make a copy of events for async saving to avoid data races
-	eventsCopy := make([]model.SearchEvent, len(s.events))
-	copy(eventsCopy, s.events)
-
-	// Persist data asynchronously
-	go func(events []model.SearchEvent) {
-		if err := s.saveDataWithEvents(events); err != nil {
-			log.Printf("Warning: Failed to save analytics data: %v", err)
-		}
-	}(eventsCopy)
 
 	return nil
 }
@@ -82,9 +90,10 @@ func (s *Service) GetDashboardData() (model.AnalyticsDashboard, error) {
 	lastWeek := now.Add(-7 * 24 * time.Hour)
 
 	// Filter events for different time periods
-	last24hEvents := s.filterEventsByTime(s.events, yesterday)
-	lastWeekEvents := s.filterEventsByTime(s.events, lastWeek)
-	prevWeekEvents := s.filterEventsByTimeRange(s.events, lastWeek.Add(-7*24*time.Hour), lastWeek)
+	events := s.events.all()
+	last24hEvents := s.filterEventsByTime(events, yesterday)
+	lastWeekEvents := s.filterEventsByTime(events, lastWeek)
+	prevWeekEvents := s.filterEventsByTimeRange(events, lastWeek.Add(-7*24*time.Hour), lastWeek)
 
 	dashboard := model.AnalyticsDashboard{
 		TotalSearches:            len(last24hEvents),
@@ -181,7 +190,7 @@ func (s *Service) getTotalDocuments() int {
 			if concreteEngine, ok := s.indexManager.(*engine.Engine); ok {
 				if instance, err := concreteEngine.GetIndex(indexName); err == nil {
 					if engineInstance, ok := instance.(*engine.IndexInstance); ok {
-						total += len(engineInstance.DocumentStore.Docs)
+						total += engineInstance.DocumentStore.Len()
 					}
 				}
 			}
@@ -293,7 +302,7 @@ func (s *Service) getIndexUsage(events []model.SearchEvent) []model.IndexStats {
 			if concreteEngine, ok := s.indexManager.(*engine.Engine); ok {
 				if instance, err := concreteEngine.GetIndex(indexName); err == nil {
 					if engineInstance, ok := instance.(*engine.IndexInstance); ok {
-						documentCount = len(engineInstance.DocumentStore.Docs)
+						documentCount = engineInstance.DocumentStore.Len()
 						sizeInMB = float64(documentCount) * 0.001
 					}
 				}
@@ -379,7 +388,7 @@ func (s *Service) getSystemHealth() model.SystemHealth {
 	}
 }
 
-// loadData loads analytics data from file
+// loadData loads the events of the data file into the ring
 func (s *Service) loadData() error {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(s.dataFilePath)
@@ -387,39 +396,179 @@ func (s *Service) loadData() error {
 		return fmt.Errorf("failed to create analytics directory: %v", err)
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(s.dataFilePath); os.IsNotExist(err) {
-		return nil // File doesn't exist yet, that's okay
-	}
-
 	data, err := os.ReadFile(s.dataFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // No data saved yet, that's okay
+	}
 	if err != nil {
 		return fmt.Errorf("failed to read analytics file: %v", err)
 	}
 
-	if err := json.Unmarshal(data, &s.events); err != nil {
-		return fmt.Errorf("failed to unmarshal analytics data: %v", err)
+	var events []model.SearchEvent
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var event model.SearchEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// A line cut short by a crash while appending is skipped
+			slog.Warn("skipping malformed analytics event", "error", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read analytics file: %v", err)
 	}
 
+	s.events = newEventRing(maxEventsToKeep, events)
+	s.fileEvents = len(events)
 	return nil
 }
 
-// saveDataWithEvents saves the provided events to file (thread-safe)
-func (s *Service) saveDataWithEvents(events []model.SearchEvent) error {
+// appendPendingEvents appends the pending events to the data file until there are none left. Once
+// the file holds twice as many events as the ring, it is rewritten with the ring's events instead.
+func (s *Service) appendPendingEvents() {
+	for {
+		s.mutex.Lock()
+		events := s.pending
+		s.pending = nil
+		if len(events) == 0 {
+			s.appending = false
+			s.mutex.Unlock()
+			return
+		}
+		// The ring already holds the pending events, so a rewrite replaces appending them
+		rewrite := s.fileEvents+len(events) > 2*maxEventsToKeep
+		if rewrite {
+			events = s.events.all()
+		}
+		s.mutex.Unlock()
+
+		var err error
+		if rewrite {
+			err = s.rewriteDataFile(events)
+			s.fileEvents = len(events)
+		} else {
+			err = s.appendToDataFile(events)
+			s.fileEvents += len(events)
+		}
+		if err != nil {
+			slog.Warn("failed to save analytics data", "error", err)
+		}
+	}
+}
+
+// appendToDataFile appends events to the data file, one JSON line each
+func (s *Service) appendToDataFile(events []model.SearchEvent) error {
+	data, err := encodeEvents(events)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.dataFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open analytics file: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write analytics file: %v", err)
+	}
+	return file.Close()
+}
+
+// rewriteDataFile replaces the data file with one holding events, one JSON line each
+func (s *Service) rewriteDataFile(events []model.SearchEvent) error {
+	data, err := encodeEvents(events)
+	if err != nil {
+		return err
+	}
+
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(s.dataFilePath)
 	if err := os.MkdirAll(dir, 0750); err != nil {
 		return fmt.Errorf("failed to create analytics directory: %v", err)
 	}
+	tempPath := s.dataFilePath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write analytics file: %v", err)
+	}
+	if err := os.Rename(tempPath, s.dataFilePath); err != nil {
+		return fmt.Errorf("failed to replace analytics file: %v", err)
+	}
+	return nil
+}
 
-	data, err := json.MarshalIndent(events, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal analytics data: %v", err)
+// encodeEvents encodes events as JSON lines
+func encodeEvents(events []model.SearchEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, fmt.Errorf("failed to marshal analytics data: %v", err)
+		}
 	}
+	return buf.Bytes(), nil
+}
 
-	if err := os.WriteFile(s.dataFilePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write analytics file: %v", err)
+// GetPopularSearches returns the queries of an index that most often returned results within the given window,
+// most frequent first. Queries are compared case- and whitespace-insensitively, and each trend compares the
+// query's count with the window just before it.
+func (s *Service) GetPopularSearches(indexName string, window time.Duration, limit int) []model.PopularSearch {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+	windowStart := now.Add(-window)
+	previousStart := windowStart.Add(-window)
+
+	currentCounts := make(map[string]int)
+	previousCounts := make(map[string]int)
+	s.events.each(func(event *model.SearchEvent) {
+		if event.IndexName != indexName || event.ResultCount == 0 {
+			return
+		}
+		query := normalizePopularQuery(event.Query)
+		if query == "" {
+			return
+		}
+		switch {
+		case event.Timestamp.After(windowStart):
+			currentCounts[query]++
+		case event.Timestamp.After(previousStart):
+			previousCounts[query]++
+		}
+	})
+
+	popular := make([]model.PopularSearch, 0, len(currentCounts))
+	for query, count := range currentCounts {
+		trend := "stable"
+		if previous := previousCounts[query]; count > previous {
+			trend = "up"
+		} else if count < previous {
+			trend = "down"
+		}
+		popular = append(popular, model.PopularSearch{
+			Query:       query,
+			SearchCount: count,
+			TrendChange: trend,
+		})
 	}
 
-	return nil
+	// Sort by count descending, then alphabetically for a stable order
+	sort.Slice(popular, func(i, j int) bool {
+		if popular[i].SearchCount != popular[j].SearchCount {
+			return popular[i].SearchCount > popular[j].SearchCount
+		}
+		return popular[i].Query < popular[j].Query
+	})
+
+	if limit > 0 && len(popular) > limit {
+		popular = popular[:limit]
+	}
+	return popular
+}
+
+// normalizePopularQuery lowercases a query and collapses its whitespace so equivalent searches are counted together
+func normalizePopularQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
 }
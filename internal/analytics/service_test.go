@@ -1,6 +1,10 @@
 package analytics
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,14 +31,33 @@ func (m *MockIndexManager) DeleteIndex(_ string) error      { return nil }
 func (m *MockIndexManager) ListIndexes() []string           { return m.indexes }
 func (m *MockIndexManager) PersistIndexData(_ string) error { return nil }
 
+// newTestService returns a service saving its events to a temporary directory, waiting for them
+// to be saved when the test ends
+func newTestService(t *testing.T, indexManager services.IndexManager) *Service {
+	service := NewService(indexManager, t.TempDir())
+	t.Cleanup(func() { waitForAppending(service) })
+	return service
+}
+
+// waitForAppending waits until the tracked events are appended to the data file
+func waitForAppending(s *Service) {
+	for {
+		s.mutex.RLock()
+		appending := s.appending
+		s.mutex.RUnlock()
+		if !appending {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func TestAnalyticsService_TrackSearchEvent(t *testing.T) {
 	mockIndexManager := &MockIndexManager{
 		indexes: []string{"test_index"},
 	}
 
-	service := NewService(mockIndexManager)
-	// Clear any existing events from previous tests
-	service.events = make([]model.SearchEvent, 0)
+	service := newTestService(t, mockIndexManager)
 
 	event := model.SearchEvent{
 		IndexName:    "test_index",
@@ -50,11 +73,11 @@ func TestAnalyticsService_TrackSearchEvent(t *testing.T) {
 	}
 
 	// Verify event was stored
-	if len(service.events) != 1 {
-		t.Fatalf("Expected 1 event, got %d", len(service.events))
+	if service.events.len() != 1 {
+		t.Fatalf("Expected 1 event, got %d", service.events.len())
 	}
 
-	storedEvent := service.events[0]
+	storedEvent := service.events.all()[0]
 	if storedEvent.IndexName != event.IndexName {
 		t.Errorf("Expected IndexName %s, got %s", event.IndexName, storedEvent.IndexName)
 	}
@@ -68,9 +91,7 @@ func TestAnalyticsService_GetDashboardData(t *testing.T) {
 		indexes: []string{"test_index1", "test_index2"},
 	}
 
-	service := NewService(mockIndexManager)
-	// Clear any existing events from previous tests
-	service.events = make([]model.SearchEvent, 0)
+	service := newTestService(t, mockIndexManager)
 
 	// Add some test events
 	events := []model.SearchEvent{
@@ -116,3 +137,193 @@ func TestAnalyticsService_GetDashboardData(t *testing.T) {
 		t.Error("Expected some popular searches, got none")
 	}
 }
+
+func TestAnalyticsService_GetPopularSearches(t *testing.T) {
+	service := newTestService(t, &MockIndexManager{indexes: []string{"movies", "books"}})
+
+	now := time.Now()
+	service.events = newEventRing(maxEventsToKeep, []model.SearchEvent{
+		{IndexName: "movies", Query: "Matrix", ResultCount: 3, Timestamp: now.Add(-1 * time.Hour)},
+		{IndexName: "movies", Query: " matrix  ", ResultCount: 3, Timestamp: now.Add(-2 * time.Hour)},
+		{IndexName: "movies", Query: "batman", ResultCount: 1, Timestamp: now.Add(-3 * time.Hour)},
+		{IndexName: "movies", Query: "batman", ResultCount: 1, Timestamp: now.Add(-30 * time.Hour)},
+		{IndexName: "movies", Query: "batman", ResultCount: 1, Timestamp: now.Add(-40 * time.Hour)},
+		{IndexName: "movies", Query: "alien", ResultCount: 2, Timestamp: now.Add(-4 * time.Hour)},
+		{IndexName: "movies", Query: "xyzzy", ResultCount: 0, Timestamp: now.Add(-1 * time.Hour)},
+		{IndexName: "movies", Query: "", ResultCount: 5, Timestamp: now.Add(-1 * time.Hour)},
+		{IndexName: "books", Query: "dune", ResultCount: 4, Timestamp: now.Add(-1 * time.Hour)},
+	})
+
+	popular := service.GetPopularSearches("movies", 24*time.Hour, 10)
+
+	expected := []model.PopularSearch{
+		{Query: "matrix", SearchCount: 2, TrendChange: "up"},
+		{Query: "alien", SearchCount: 1, TrendChange: "up"},
+		{Query: "batman", SearchCount: 1, TrendChange: "down"},
+	}
+	if len(popular) != len(expected) {
+		t.Fatalf("Expected %d popular searches, got %d: %+v", len(expected), len(popular), popular)
+	}
+	for i, want := range expected {
+		if popular[i] != want {
+			t.Errorf("Expected popular search %d to be %+v, got %+v", i, want, popular[i])
+		}
+	}
+
+	if limited := service.GetPopularSearches("movies", 24*time.Hour, 1); len(limited) != 1 || limited[0].Query != "matrix" {
+		t.Errorf("Expected only the top query with limit 1, got %+v", limited)
+	}
+
+	if none := service.GetPopularSearches("movies", 30*time.Minute, 10); len(none) != 0 {
+		t.Errorf("Expected no popular searches in a 30m window, got %+v", none)
+	}
+}
+
+func TestEventRing(t *testing.T) {
+	ring := newEventRing(3, nil)
+	for i := 1; i <= 5; i++ {
+		ring.add(model.SearchEvent{Query: strconv.Itoa(i)})
+	}
+
+	if ring.len() != 3 {
+		t.Fatalf("Expected 3 events, got %d", ring.len())
+	}
+	var queries []string
+	for _, event := range ring.all() {
+		queries = append(queries, event.Query)
+	}
+	if strings.Join(queries, ",") != "3,4,5" {
+		t.Errorf("Expected the latest events oldest first, got %v", queries)
+	}
+
+	if reloaded := newEventRing(2, ring.all()); reloaded.len() != 2 || reloaded.all()[0].Query != "4" {
+		t.Errorf("Expected a smaller ring to keep the latest events, got %+v", reloaded.all())
+	}
+}
+
+func TestAnalyticsService_QueryReports(t *testing.T) {
+	service := newTestService(t, &MockIndexManager{indexes: []string{"movies", "books"}})
+
+	now := time.Now()
+	service.events = newEventRing(maxEventsToKeep, []model.SearchEvent{
+		{IndexName: "movies", Query: "Matrix", QueryID: "q1", ResultCount: 3, ResponseTime: 10 * time.Millisecond, Timestamp: now.Add(-3 * time.Hour)},
+		{IndexName: "movies", Query: "matrix", QueryID: "q2", ResultCount: 3, ResponseTime: 30 * time.Millisecond, Timestamp: now.Add(-2 * time.Hour)},
+		{IndexName: "movies", Query: "xyzzy", QueryID: "q3", ResultCount: 0, ResponseTime: 20 * time.Millisecond, Timestamp: now.Add(-2 * time.Hour)},
+		{IndexName: "movies", Query: "xyzzy", QueryID: "q4", ResultCount: 0, ResponseTime: 40 * time.Millisecond, Timestamp: now.Add(-1 * time.Hour)},
+		{IndexName: "movies", Query: "Matrix ", QueryID: "q5", ResultCount: 0, ResponseTime: 50 * time.Millisecond, Timestamp: now.Add(-1 * time.Hour)},
+		{IndexName: "movies", Query: "alien", QueryID: "q6", ResultCount: 1, ResponseTime: 60 * time.Millisecond, Timestamp: now.Add(-1 * time.Hour)},
+		{IndexName: "movies", Query: "", ResultCount: 5, ResponseTime: 100 * time.Millisecond, Timestamp: now.Add(-1 * time.Hour)},
+		{IndexName: "movies", Query: "old", ResultCount: 0, ResponseTime: 900 * time.Millisecond, Timestamp: now.Add(-30 * time.Hour)},
+		{IndexName: "books", Query: "dune", ResultCount: 0, ResponseTime: 5 * time.Millisecond, Timestamp: now.Add(-1 * time.Hour)},
+	})
+
+	top := service.GetTopQueries("movies", 24*time.Hour, 10)
+	if len(top) != 3 {
+		t.Fatalf("Expected 3 top queries, got %+v", top)
+	}
+	matrix := top[0]
+	if matrix.Query != "matrix" || matrix.SearchCount != 3 || matrix.ZeroResultCount != 1 || matrix.AvgResponseTimeMs != 30 || matrix.LastQueryID != "q5" {
+		t.Errorf("Expected matrix searched 3 times, once without results, 30ms on average and last as q5, got %+v", matrix)
+	}
+	if top[1].Query != "xyzzy" || top[2].Query != "alien" {
+		t.Errorf("Expected xyzzy then alien after matrix, got %+v", top[1:])
+	}
+
+	zeroResult := service.GetZeroResultQueries("movies", 24*time.Hour, 10)
+	if len(zeroResult) != 2 || zeroResult[0].Query != "xyzzy" || zeroResult[0].ZeroResultCount != 2 || zeroResult[1].Query != "matrix" {
+		t.Errorf("Expected xyzzy then matrix as zero-result queries, got %+v", zeroResult)
+	}
+	if limited := service.GetZeroResultQueries("movies", 24*time.Hour, 1); len(limited) != 1 || limited[0].Query != "xyzzy" {
+		t.Errorf("Expected only the top zero-result query with limit 1, got %+v", limited)
+	}
+
+	latency := service.GetLatencyReport("movies", 24*time.Hour)
+	expected := model.LatencyReport{
+		IndexName:   "movies",
+		Window:      "24h0m0s",
+		SearchCount: 7,
+		AvgMs:       durationMs(310 * time.Millisecond / 7),
+		P50Ms:       40,
+		P90Ms:       100,
+		P95Ms:       100,
+		P99Ms:       100,
+		MaxMs:       100,
+	}
+	if latency != expected {
+		t.Errorf("Expected latency report %+v, got %+v", expected, latency)
+	}
+
+	if empty := service.GetLatencyReport("movies", 30*time.Minute); empty.SearchCount != 0 || empty.MaxMs != 0 {
+		t.Errorf("Expected an empty latency report in a 30m window, got %+v", empty)
+	}
+}
+
+func TestAnalyticsService_Persistence(t *testing.T) {
+	service := newTestService(t, &MockIndexManager{})
+	for i := 0; i < 5; i++ {
+		if err := service.TrackSearchEvent(model.SearchEvent{IndexName: "movies", Query: "query " + strconv.Itoa(i), ResultCount: i}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	waitForAppending(service)
+
+	// A line cut short while appending is skipped on load
+	file, err := os.OpenFile(service.dataFilePath, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open the data file: %v", err)
+	}
+	_, _ = file.WriteString(`{"index_name":"mov`)
+	_ = file.Close()
+
+	reloaded := &Service{events: newEventRing(maxEventsToKeep, nil), dataFilePath: service.dataFilePath}
+	if err := reloaded.loadData(); err != nil {
+		t.Fatalf("Failed to load analytics data: %v", err)
+	}
+	events := reloaded.events.all()
+	if len(events) != 5 || events[0].Query != "query 0" || events[4].Query != "query 4" {
+		t.Errorf("Expected the 5 tracked events in order, got %+v", events)
+	}
+}
+
+func TestAnalyticsService_DataDir(t *testing.T) {
+	dir := t.TempDir()
+	service := NewService(&MockIndexManager{}, dir)
+	_ = service.TrackSearchEvent(model.SearchEvent{IndexName: "movies", Query: "matrix"})
+	waitForAppending(service)
+
+	if _, err := os.Stat(filepath.Join(dir, analyticsDataFile)); err != nil {
+		t.Fatalf("Expected the events to be saved in the data directory, got %v", err)
+	}
+	if reloaded := NewService(&MockIndexManager{}, dir); reloaded.events.len() != 1 {
+		t.Errorf("Expected the saved event to be loaded, got %d events", reloaded.events.len())
+	}
+
+	inMemory := NewService(&MockIndexManager{}, "")
+	_ = inMemory.TrackSearchEvent(model.SearchEvent{IndexName: "movies", Query: "matrix"})
+	if inMemory.events.len() != 1 || inMemory.appending {
+		t.Errorf("Expected the event to only be kept in memory without a data directory, got %d events", inMemory.events.len())
+	}
+}
+
+func TestAnalyticsService_RewritesFullDataFile(t *testing.T) {
+	service := newTestService(t, &MockIndexManager{})
+	for i := 0; i < 3; i++ {
+		_ = service.TrackSearchEvent(model.SearchEvent{IndexName: "movies", Query: "query " + strconv.Itoa(i)})
+	}
+	waitForAppending(service)
+
+	// Pretend the file holds twice as many events as the ring, so the next event rewrites it
+	service.mutex.Lock()
+	service.fileEvents = 2 * maxEventsToKeep
+	service.mutex.Unlock()
+	_ = service.TrackSearchEvent(model.SearchEvent{IndexName: "movies", Query: "query 3"})
+	waitForAppending(service)
+
+	data, err := os.ReadFile(service.dataFilePath)
+	if err != nil {
+		t.Fatalf("Failed to read the data file: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 4 || service.fileEvents != 4 {
+		t.Errorf("Expected the data file rewritten with the 4 events of the ring, got %d lines and a count of %d", lines, service.fileEvents)
+	}
+}
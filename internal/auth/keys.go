@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/rules"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// secretBytes is the number of random bytes of a generated secret, written as twice as many hex
+// characters
+const secretBytes = 32
+
+// prefixLength is the number of characters of a secret kept as the prefix of its key
+const prefixLength = 8
+
+// actions are the actions a key can be allowed
+var actions = []model.APIKeyAction{
+	model.APIKeyActionSearch,
+	model.APIKeyActionDocumentsRead,
+	model.APIKeyActionDocumentsWrite,
+	model.APIKeyActionIndexesRead,
+	model.APIKeyActionIndexesWrite,
+}
+
+// GenerateSecret returns a new random secret with its prefix, as shown in model.APIKey.KeyPrefix.
+func GenerateSecret() (secret, prefix string, err error) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret = hex.EncodeToString(buf)
+	return secret, secret[:prefixLength], nil
+}
+
+// HashSecret returns the hash a secret is stored and looked up by.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateKey checks the scope of a key. It returns a *errors.ValidationError describing the
+// first problem found.
+func ValidateKey(key model.APIKey) error {
+	if len(key.Actions) == 0 {
+		return errors.NewValidationError("actions", "at least one action is required")
+	}
+	for i, action := range key.Actions {
+		if !slices.Contains(actions, action) {
+			return errors.NewValidationError(fmt.Sprintf("actions[%d]", i), fmt.Sprintf("unsupported action '%s' (expected one of %v)", action, actions))
+		}
+	}
+	for i, pattern := range key.Indexes {
+		if pattern == "" {
+			return errors.NewValidationError(fmt.Sprintf("indexes[%d]", i), "cannot be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("indexes[%d]", i), fmt.Sprintf("invalid pattern '%s'", pattern))
+		}
+	}
+	for _, action := range key.Actions {
+		if len(key.Filters) > 0 && action != model.APIKeyActionSearch && action != model.APIKeyActionDocumentsRead {
+			return errors.NewValidationError("filters", "keys with filters can only have the search and documents.read actions")
+		}
+	}
+	for i, filter := range key.Filters {
+		if err := rules.ValidateFilter(fmt.Sprintf("filters[%d]", i), filter); err != nil {
+			return err
+		}
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return errors.NewValidationError("expires_at", "must be in the future")
+	}
+	return nil
+}
+
+// Expired reports whether a key is rejected at the given time.
+func Expired(key model.APIKey, now time.Time) bool {
+	return key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)
+}
+
+// Allows reports whether a key is allowed an action.
+func Allows(key model.APIKey, action model.APIKeyAction) bool {
+	return slices.Contains(key.Actions, action)
+}
+
+// AllowsIndex reports whether a key reaches an index: when it has no index patterns, or the name
+// matches one of them.
+func AllowsIndex(key model.APIKey, indexName string) bool {
+	if len(key.Indexes) == 0 {
+		return true
+	}
+	for _, pattern := range key.Indexes {
+		if matched, _ := path.Match(pattern, indexName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// AddFilters adds the filter conditions of a key to a query's filters. Documents must match both
+// the key's conditions and the query's filters, which are nested as the first group of the result,
+// so a query cannot widen what the key lets it see.
+func AddFilters(key model.APIKey, filters *services.Filters) *services.Filters {
+	if len(key.Filters) == 0 {
+		return filters
+	}
+	conditions := make([]services.FilterCondition, len(key.Filters))
+	for i, filter := range key.Filters {
+		conditions[i] = services.FilterCondition{Field: filter.Field, Operator: filter.Operator, Value: filter.Value}
+	}
+	combined := &services.Filters{Operator: "AND", Filters: conditions}
+	if filters != nil {
+		combined.Groups = []services.Filters{*filters}
+	}
+	return combined
+}
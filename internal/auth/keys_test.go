@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestValidateKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	search := []model.APIKeyAction{model.APIKeyActionSearch}
+	tests := []struct {
+		name    string
+		key     model.APIKey
+		wantErr bool
+	}{
+		{"search only", model.APIKey{Actions: search}, false},
+		{"no actions", model.APIKey{}, true},
+		{"unknown action", model.APIKey{Actions: []model.APIKeyAction{"admin"}}, true},
+		{"index patterns", model.APIKey{Actions: search, Indexes: []string{"products_*", "articles"}}, false},
+		{"invalid pattern", model.APIKey{Actions: search, Indexes: []string{"products_["}}, true},
+		{"filters", model.APIKey{Actions: search, Filters: []model.RuleFilter{{Field: "tenant", Value: "acme"}}}, false},
+		{"filters without value", model.APIKey{Actions: search, Filters: []model.RuleFilter{{Field: "tenant"}}}, true},
+		{"filters with documents.read", model.APIKey{
+			Actions: []model.APIKeyAction{model.APIKeyActionSearch, model.APIKeyActionDocumentsRead},
+			Filters: []model.RuleFilter{{Field: "tenant", Value: "acme"}},
+		}, false},
+		{"filters with other actions", model.APIKey{
+			Actions: []model.APIKeyAction{model.APIKeyActionDocumentsRead, model.APIKeyActionDocumentsWrite},
+			Filters: []model.RuleFilter{{Field: "tenant", Value: "acme"}},
+		}, true},
+		{"expired", model.APIKey{Actions: search, ExpiresAt: &past}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKey(tt.key)
+			if tt.wantErr && !errors.Is(err, internalErrors.ErrInvalidInput) {
+				t.Errorf("ValidateKey() error = %v, want ErrInvalidInput", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("ValidateKey() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyScope(t *testing.T) {
+	expiresAt := time.Now()
+	key := model.APIKey{
+		Actions:   []model.APIKeyAction{model.APIKeyActionSearch},
+		Indexes:   []string{"products_*"},
+		Filters:   []model.RuleFilter{{Field: "tenant", Value: "acme"}},
+		ExpiresAt: &expiresAt,
+	}
+
+	if !Allows(key, model.APIKeyActionSearch) || Allows(key, model.APIKeyActionDocumentsWrite) {
+		t.Error("Expected the key to allow search only")
+	}
+	if !AllowsIndex(key, "products_v2") || AllowsIndex(key, "orders") {
+		t.Error("Expected the key to reach products_* indexes only")
+	}
+	if !AllowsIndex(model.APIKey{}, "orders") {
+		t.Error("Expected a key without index patterns to reach every index")
+	}
+	if Expired(key, expiresAt.Add(-time.Second)) || !Expired(key, expiresAt) {
+		t.Error("Expected the key to expire at its expiry time")
+	}
+
+	queryFilters := &services.Filters{Operator: "OR", Filters: []services.FilterCondition{{Field: "color", Value: "red"}}}
+	want := &services.Filters{
+		Operator: "AND",
+		Filters:  []services.FilterCondition{{Field: "tenant", Value: "acme"}},
+		Groups:   []services.Filters{*queryFilters},
+	}
+	if got := AddFilters(key, queryFilters); !reflect.DeepEqual(got, want) {
+		t.Errorf("AddFilters() = %+v, want %+v", got, want)
+	}
+	if got := AddFilters(model.APIKey{}, queryFilters); got != queryFilters {
+		t.Errorf("AddFilters() of a key without filters = %+v, want the query's filters", got)
+	}
+}
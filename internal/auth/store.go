@@ -0,0 +1,168 @@
+// Package auth keeps the API keys of the engine and decides which requests they allow.
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// storedKey is an API key as written to the keys file: with the hash of its secret, never the
+// secret itself.
+type storedKey struct {
+	model.APIKey
+	Hash string `json:"hash"`
+}
+
+// FileKeyStore keeps API keys in memory and writes them to a JSON file on every change.
+type FileKeyStore struct {
+	mu       sync.RWMutex
+	filePath string
+	keys     map[string]storedKey // Key ID -> key
+	byHash   map[string]string    // Hash of the secret -> key ID
+}
+
+// NewFileKeyStore creates a key store backed by the given file. Call Load to read existing keys.
+func NewFileKeyStore(filePath string) *FileKeyStore {
+	return &FileKeyStore{
+		filePath: filePath,
+		keys:     make(map[string]storedKey),
+		byHash:   make(map[string]string),
+	}
+}
+
+// Load reads the keys file. A missing file leaves the store empty.
+func (s *FileKeyStore) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read API keys file: %w", err)
+	}
+
+	var stored []storedKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to unmarshal API keys: %w", err)
+	}
+
+	s.keys = make(map[string]storedKey, len(stored))
+	s.byHash = make(map[string]string, len(stored))
+	for _, key := range stored {
+		s.keys[key.ID] = key
+		s.byHash[key.Hash] = key.ID
+	}
+	return nil
+}
+
+// List returns the API keys in creation order.
+func (s *FileKeyStore) List() []model.APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]model.APIKey, 0, len(s.keys))
+	for _, key := range s.sortedUnsafe() {
+		list = append(list, key.APIKey)
+	}
+	return list
+}
+
+// Get returns a single API key.
+func (s *FileKeyStore) Get(id string) (model.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return model.APIKey{}, errors.NewAPIKeyNotFoundError(id)
+	}
+	return key.APIKey, nil
+}
+
+// Lookup returns the API key whose secret has the given hash.
+func (s *FileKeyStore) Lookup(hash string) (model.APIKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, exists := s.byHash[hash]
+	if !exists {
+		return model.APIKey{}, false
+	}
+	return s.keys[id].APIKey, true
+}
+
+// Save stores a new API key with the hash of its secret.
+func (s *FileKeyStore) Save(key model.APIKey, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = storedKey{APIKey: key, Hash: hash}
+	s.byHash[hash] = key.ID
+	if err := s.persistUnsafe(); err != nil {
+		delete(s.keys, key.ID)
+		delete(s.byHash, hash)
+		return err
+	}
+	return nil
+}
+
+// Delete removes an API key. Requests made with it are rejected from then on.
+func (s *FileKeyStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return errors.NewAPIKeyNotFoundError(id)
+	}
+	delete(s.keys, id)
+	delete(s.byHash, key.Hash)
+	if err := s.persistUnsafe(); err != nil {
+		s.keys[id] = key
+		s.byHash[key.Hash] = id
+		return err
+	}
+	return nil
+}
+
+// sortedUnsafe returns the stored keys by creation time, then by ID. The caller must hold s.mu.
+func (s *FileKeyStore) sortedUnsafe() []storedKey {
+	all := make([]storedKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		all = append(all, key)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.Before(all[j].CreatedAt)
+		}
+		return all[i].ID < all[j].ID
+	})
+	return all
+}
+
+// persistUnsafe writes all keys to the keys file. The caller must hold s.mu.
+func (s *FileKeyStore) persistUnsafe() error {
+	dir := filepath.Dir(s.filePath)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create API keys directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.sortedUnsafe(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal API keys: %w", err)
+	}
+
+	if err := os.WriteFile(s.filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API keys file: %w", err)
+	}
+	return nil
+}
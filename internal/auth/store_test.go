@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestFileKeyStore(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "api_keys.json")
+	s := NewFileKeyStore(filePath)
+	if err := s.Load(); err != nil {
+		t.Fatalf("Load() on missing file error = %v", err)
+	}
+
+	now := time.Now()
+	for _, key := range []model.APIKey{
+		{ID: "b", Actions: []model.APIKeyAction{model.APIKeyActionSearch}, CreatedAt: now},
+		{ID: "a", Actions: []model.APIKeyAction{model.APIKeyActionSearch}, CreatedAt: now.Add(time.Second)},
+	} {
+		if err := s.Save(key, HashSecret("secret-"+key.ID)); err != nil {
+			t.Fatalf("Save(%s) error = %v", key.ID, err)
+		}
+	}
+
+	if list := s.List(); len(list) != 2 || list[0].ID != "b" || list[1].ID != "a" {
+		t.Errorf("List() = %v, want keys b, a in creation order", list)
+	}
+	if key, found := s.Lookup(HashSecret("secret-a")); !found || key.ID != "a" {
+		t.Errorf("Lookup() = %v, %v, want key a", key, found)
+	}
+
+	if err := s.Delete("b"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := s.Delete("b"); !errors.Is(err, internalErrors.ErrAPIKeyNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrAPIKeyNotFound", err)
+	}
+
+	reloaded := NewFileKeyStore(filePath)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, found := reloaded.Lookup(HashSecret("secret-b")); found {
+		t.Error("Expected the deleted key not to be reloaded")
+	}
+	if key, err := reloaded.Get("a"); err != nil || key.ID != "a" {
+		t.Errorf("Get() after reload = %v, %v, want key a", key, err)
+	}
+}
@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// aliasesFile stores the index aliases of the data directory, as a JSON object of alias name to index name
+const aliasesFile = "aliases.json"
+
+// PutAlias points an alias at an index, creating the alias or moving it from the index it pointed
+// at, and returns that previous index, if any. Moving an alias is atomic: every request addressed to
+// it resolves to either the previous or the new index. An alias cannot be named after an index.
+func (e *Engine) PutAlias(alias, indexName string) (string, error) {
+	if err := e.checkWritable("put alias"); err != nil {
+		return "", err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.indexExistsUnsafe(alias) {
+		return "", errors.NewValidationError("alias", fmt.Sprintf("'%s' is the name of an index", alias))
+	}
+	if !e.indexExistsUnsafe(indexName) {
+		return "", errors.NewIndexNotFoundError(indexName)
+	}
+
+	previous := e.aliases[alias]
+	e.aliases[alias] = indexName
+	if err := e.saveAliasesUnsafe(); err != nil {
+		if previous == "" {
+			delete(e.aliases, alias)
+		} else {
+			e.aliases[alias] = previous
+		}
+		return "", err
+	}
+	delete(e.renameAliases, alias) // The alias takes over the old name of a renamed index
+	slog.Info("alias set", "alias", alias, "index", indexName)
+	return previous, nil
+}
+
+// SwapAliases exchanges the indexes two aliases point to in a single step, e.g. to promote an index
+// rebuilt behind a staging alias to the live alias while the staging alias gets the old index.
+func (e *Engine) SwapAliases(first, second string) ([]model.Alias, error) {
+	if err := e.checkWritable("swap aliases"); err != nil {
+		return nil, err
+	}
+	if first == second {
+		return nil, errors.NewValidationError("aliases", "cannot swap an alias with itself")
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	firstIndex, exists := e.aliases[first]
+	if !exists {
+		return nil, errors.NewAliasNotFoundError(first)
+	}
+	secondIndex, exists := e.aliases[second]
+	if !exists {
+		return nil, errors.NewAliasNotFoundError(second)
+	}
+
+	e.aliases[first], e.aliases[second] = secondIndex, firstIndex
+	if err := e.saveAliasesUnsafe(); err != nil {
+		e.aliases[first], e.aliases[second] = firstIndex, secondIndex
+		return nil, err
+	}
+	slog.Info("aliases swapped", "alias", first, "index", secondIndex, "other_alias", second, "other_index", firstIndex)
+	return []model.Alias{{Name: first, Index: secondIndex}, {Name: second, Index: firstIndex}}, nil
+}
+
+// DeleteAlias removes an alias. The index it pointed at is left untouched.
+func (e *Engine) DeleteAlias(alias string) error {
+	if err := e.checkWritable("delete alias"); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	indexName, exists := e.aliases[alias]
+	if !exists {
+		return errors.NewAliasNotFoundError(alias)
+	}
+	delete(e.aliases, alias)
+	if err := e.saveAliasesUnsafe(); err != nil {
+		e.aliases[alias] = indexName
+		return err
+	}
+	slog.Info("alias deleted", "alias", alias)
+	return nil
+}
+
+// GetAlias returns the alias with the index it points to.
+func (e *Engine) GetAlias(alias string) (model.Alias, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	indexName, exists := e.aliases[alias]
+	if !exists {
+		return model.Alias{}, errors.NewAliasNotFoundError(alias)
+	}
+	return model.Alias{Name: alias, Index: indexName}, nil
+}
+
+// ListAliases returns every alias, ordered by name.
+func (e *Engine) ListAliases() []model.Alias {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	aliases := make([]model.Alias, 0, len(e.aliases))
+	for alias, indexName := range e.aliases {
+		aliases = append(aliases, model.Alias{Name: alias, Index: indexName})
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].Name < aliases[j].Name
+	})
+	return aliases
+}
+
+// ResolveAlias returns the index an alias points to. It reports false when name is not an alias.
+func (e *Engine) ResolveAlias(name string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	indexName, exists := e.aliases[name]
+	return indexName, exists
+}
+
+// aliasConflictUnsafe returns a validation error when an index cannot be named name because an
+// alias is. The caller must hold e.mu.
+func (e *Engine) aliasConflictUnsafe(name string) error {
+	if _, exists := e.aliases[name]; exists {
+		return errors.NewValidationError("name", fmt.Sprintf("'%s' is the name of an alias", name))
+	}
+	return nil
+}
+
+// retargetAliasesUnsafe points the aliases of a renamed index at its new name. The caller must
+// hold e.mu.
+func (e *Engine) retargetAliasesUnsafe(oldName, newName string) {
+	changed := false
+	for alias, indexName := range e.aliases {
+		if indexName == oldName {
+			e.aliases[alias] = newName
+			changed = true
+		}
+	}
+	if changed {
+		if err := e.saveAliasesUnsafe(); err != nil {
+			slog.Warn("failed to save the aliases of renamed index", "index", oldName, "new_index", newName, "error", err)
+		}
+	}
+}
+
+// dropAliasesOfUnsafe removes the aliases pointing to a deleted index. The caller must hold e.mu.
+func (e *Engine) dropAliasesOfUnsafe(indexName string) {
+	changed := false
+	for alias, target := range e.aliases {
+		if target == indexName {
+			delete(e.aliases, alias)
+			changed = true
+		}
+	}
+	if changed {
+		if err := e.saveAliasesUnsafe(); err != nil {
+			slog.Warn("failed to save the aliases of deleted index", "index", indexName, "error", err)
+		}
+	}
+}
+
+// loadAliases reads the aliases file of the data directory, dropping aliases of indexes that were
+// not loaded. A missing file leaves the engine without aliases.
+func (e *Engine) loadAliases() {
+	data, err := os.ReadFile(filepath.Join(e.dataDir, aliasesFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read aliases, starting without aliases", "data_dir", e.dataDir, "error", err)
+		}
+		return
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		slog.Warn("failed to unmarshal aliases, starting without aliases", "data_dir", e.dataDir, "error", err)
+		return
+	}
+	for alias, indexName := range aliases {
+		if !e.indexExistsUnsafe(indexName) {
+			slog.Warn("dropping alias of an index that was not loaded", "alias", alias, "index", indexName)
+			continue
+		}
+		e.aliases[alias] = indexName
+	}
+}
+
+// saveAliasesUnsafe writes the aliases file. The caller must hold e.mu.
+func (e *Engine) saveAliasesUnsafe() error {
+	data, err := json.MarshalIndent(e.aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal aliases: %w", err)
+	}
+	if err := os.MkdirAll(e.dataDir, dataDirPerm); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(e.dataDir, aliasesFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write aliases file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestAliases(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	if err := engine.CreateIndex(config.IndexSettings{Name: "test-batch-index-v2", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+
+	if previous, err := engine.PutAlias("live", "test-batch-index"); err != nil || previous != "" {
+		t.Fatalf("PutAlias() = %q, %v", previous, err)
+	}
+	if _, err := engine.PutAlias("staging", "test-batch-index-v2"); err != nil {
+		t.Fatalf("PutAlias() error = %v", err)
+	}
+	if indexName, ok := engine.ResolveAlias("live"); !ok || indexName != "test-batch-index" {
+		t.Errorf("ResolveAlias(live) = %q, %v", indexName, ok)
+	}
+	if _, ok := engine.ResolveAlias("test-batch-index"); ok {
+		t.Error("Expected an index name not to resolve as an alias")
+	}
+
+	t.Run("invalid aliases", func(t *testing.T) {
+		var validationErr *internalErrors.ValidationError
+		if _, err := engine.PutAlias("test-batch-index-v2", "test-batch-index"); !errors.As(err, &validationErr) {
+			t.Errorf("Expected an alias named after an index to be rejected, got %v", err)
+		}
+		if _, err := engine.PutAlias("other", "missing"); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+			t.Errorf("Expected ErrIndexNotFound, got %v", err)
+		}
+		if err := engine.CreateIndex(config.IndexSettings{Name: "live", SearchableFields: []string{"title"}}); !errors.As(err, &validationErr) {
+			t.Errorf("Expected an index named after an alias to be rejected, got %v", err)
+		}
+		if _, err := engine.SwapAliases("live", "missing"); !errors.Is(err, internalErrors.ErrAliasNotFound) {
+			t.Errorf("Expected ErrAliasNotFound, got %v", err)
+		}
+	})
+
+	t.Run("swap", func(t *testing.T) {
+		swapped, err := engine.SwapAliases("live", "staging")
+		if err != nil {
+			t.Fatalf("SwapAliases() error = %v", err)
+		}
+		want := []model.Alias{{Name: "live", Index: "test-batch-index-v2"}, {Name: "staging", Index: "test-batch-index"}}
+		if !reflect.DeepEqual(swapped, want) || !reflect.DeepEqual(engine.ListAliases(), want) {
+			t.Errorf("SwapAliases() = %v, aliases %v, want %v", swapped, engine.ListAliases(), want)
+		}
+		if previous, err := engine.PutAlias("live", "test-batch-index"); err != nil || previous != "test-batch-index-v2" {
+			t.Errorf("PutAlias() = %q, %v, want the previous index", previous, err)
+		}
+	})
+
+	t.Run("persisted", func(t *testing.T) {
+		reopened := NewEngine(engine.dataDir)
+		defer reopened.jobManager.Stop()
+		if !reflect.DeepEqual(reopened.ListAliases(), engine.ListAliases()) {
+			t.Errorf("Expected the aliases to be loaded, got %v", reopened.ListAliases())
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := engine.DeleteAlias("staging"); err != nil {
+			t.Fatalf("DeleteAlias() error = %v", err)
+		}
+		if err := engine.DeleteAlias("staging"); !errors.Is(err, internalErrors.ErrAliasNotFound) {
+			t.Errorf("Expected ErrAliasNotFound, got %v", err)
+		}
+		if _, err := engine.GetIndex("test-batch-index"); err != nil {
+			t.Errorf("Expected the index to be kept, got %v", err)
+		}
+	})
+
+	t.Run("follow renames and deletes", func(t *testing.T) {
+		if err := engine.RenameIndex("test-batch-index", "test-batch-index-v1"); err != nil {
+			t.Fatalf("RenameIndex() error = %v", err)
+		}
+		if alias, _ := engine.GetAlias("live"); alias.Index != "test-batch-index-v1" {
+			t.Errorf("Expected the alias to follow the renamed index, got %v", alias)
+		}
+		if err := engine.DeleteIndex("test-batch-index-v1"); err != nil {
+			t.Fatalf("DeleteIndex() error = %v", err)
+		}
+		if _, err := engine.GetAlias("live"); !errors.Is(err, internalErrors.ErrAliasNotFound) {
+			t.Errorf("Expected the alias of a deleted index to be dropped, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/tokenizer"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// Analyze shows how an index tokenizes text as the value of a searchable field and as a query,
+// so that a document that does not match a query can be explained without searching.
+func (e *Engine) Analyze(indexName string, request model.AnalyzeRequest) (model.AnalyzeResult, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.AnalyzeResult{}, e.missingIndexError(indexName)
+	}
+
+	settings := instance.Settings()
+	if strings.TrimSpace(request.Text) == "" {
+		return model.AnalyzeResult{}, errors.NewValidationError("text", "is required")
+	}
+	if !slices.Contains(settings.SearchableFields, request.Field) {
+		return model.AnalyzeResult{}, errors.NewValidationError("field", fmt.Sprintf("'%s' is not a searchable field of index '%s'", request.Field, indexName))
+	}
+	if instance.searcher == nil {
+		return model.AnalyzeResult{}, fmt.Errorf("search service not initialized for index '%s'", indexName)
+	}
+
+	analyzer := tokenizer.NewAnalyzer(&settings)
+	result := model.AnalyzeResult{
+		Field:         request.Field,
+		Locale:        analyzer.Locale(),
+		PrefixSearch:  !slices.Contains(settings.FieldsWithoutPrefixSearch, request.Field),
+		TypoTolerance: settings.FieldMaxTypos(request.Field) > 0,
+		IndexTokens:   analyzer.IndexedWords(request.Text, request.Field),
+		IndexNGrams:   []string{},
+	}
+
+	indexed := make(map[string]struct{})
+	for _, token := range result.IndexTokens {
+		indexed[token] = struct{}{}
+	}
+	for _, token := range analyzer.FieldTokens(request.Text, request.Field) {
+		if _, isWord := indexed[token]; !isWord {
+			result.IndexNGrams = append(result.IndexNGrams, token)
+			indexed[token] = struct{}{}
+		}
+	}
+
+	query := request.Query
+	if query == "" {
+		query = request.Text
+	}
+	queryTokens, err := instance.searcher.QueryTokens(query, []string{request.Field})
+	if err != nil {
+		return model.AnalyzeResult{}, err
+	}
+	result.QueryTokens = queryTokens
+	result.UnmatchedQueryTokens = []string{}
+	for _, token := range queryTokens {
+		if _, found := indexed[token]; !found {
+			result.UnmatchedQueryTokens = append(result.UnmatchedQueryTokens, token)
+		}
+	}
+	return result, nil
+}
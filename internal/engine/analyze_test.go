@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestAnalyze(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	const indexName = "test-batch-index"
+
+	result, err := engine.Analyze(indexName, model.AnalyzeRequest{Text: "Old Cat", Field: "title", Query: "old dog"})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if !result.PrefixSearch || !result.TypoTolerance {
+		t.Errorf("Expected prefix search and typo tolerance for title, got %+v", result)
+	}
+	if want := []string{"old", "cat"}; !reflect.DeepEqual(result.IndexTokens, want) {
+		t.Errorf("IndexTokens = %v, want %v", result.IndexTokens, want)
+	}
+	if want := []string{"o", "ol", "c", "ca"}; !reflect.DeepEqual(result.IndexNGrams, want) {
+		t.Errorf("IndexNGrams = %v, want %v", result.IndexNGrams, want)
+	}
+	if want := []string{"old", "dog"}; !reflect.DeepEqual(result.QueryTokens, want) {
+		t.Errorf("QueryTokens = %v, want %v", result.QueryTokens, want)
+	}
+	if want := []string{"dog"}; !reflect.DeepEqual(result.UnmatchedQueryTokens, want) {
+		t.Errorf("UnmatchedQueryTokens = %v, want %v", result.UnmatchedQueryTokens, want)
+	}
+
+	// Without a query the text is analyzed on both sides; fields without prefix search have no n-grams
+	settings := config.IndexSettings{
+		Name:                      "test-analyze-exact",
+		SearchableFields:          []string{"sku"},
+		FieldsWithoutPrefixSearch: []string{"sku"},
+		NoTypoToleranceFields:     []string{"sku"},
+	}
+	if err := engine.CreateIndex(settings); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	result, err = engine.Analyze(settings.Name, model.AnalyzeRequest{Text: "AB-123", Field: "sku"})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if result.PrefixSearch || result.TypoTolerance || len(result.IndexNGrams) != 0 || len(result.UnmatchedQueryTokens) != 0 {
+		t.Errorf("Expected whole-word matching without typos, got %+v", result)
+	}
+	if want := []string{"ab", "123"}; !reflect.DeepEqual(result.QueryTokens, want) {
+		t.Errorf("QueryTokens = %v, want %v", result.QueryTokens, want)
+	}
+
+	if _, err := engine.Analyze(indexName, model.AnalyzeRequest{Text: "old", Field: "description"}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Analyze() with a non-searchable field error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := engine.Analyze(indexName, model.AnalyzeRequest{Text: " ", Field: "title"}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Analyze() with empty text error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := engine.Analyze("missing", model.AnalyzeRequest{Text: "old", Field: "title"}); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Analyze() on a missing index error = %v, want ErrIndexNotFound", err)
+	}
+}
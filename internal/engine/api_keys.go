@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gcbaptista/go-search-engine/internal/auth"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// MinAdminKeyLength is the fewest characters an admin key can have
+const MinAdminKeyLength = 16
+
+// SetAdminKey sets the key allowed every request, including managing API keys. Until it is set,
+// authorization is disabled and requests need no key.
+func (e *Engine) SetAdminKey(key string) error {
+	if len(key) < MinAdminKeyLength {
+		return errors.NewValidationError("admin_key", fmt.Sprintf("must be at least %d characters", MinAdminKeyLength))
+	}
+	e.authMu.Lock()
+	defer e.authMu.Unlock()
+	e.adminKey = key
+	return nil
+}
+
+// AuthEnabled reports whether requests must present a key, which is once an admin key is set.
+func (e *Engine) AuthEnabled() bool {
+	e.authMu.RLock()
+	defer e.authMu.RUnlock()
+	return e.adminKey != ""
+}
+
+// Authenticate returns the API key a secret belongs to. admin reports the admin key, which is not
+// an API key and is allowed every request. ok is false for unknown and expired keys.
+func (e *Engine) Authenticate(secret string) (key model.APIKey, admin bool, ok bool) {
+	if secret == "" {
+		return model.APIKey{}, false, false
+	}
+	e.authMu.RLock()
+	adminKey := e.adminKey
+	e.authMu.RUnlock()
+	if adminKey != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(adminKey)) == 1 {
+		return model.APIKey{}, true, true
+	}
+
+	key, found := e.keyStore.Lookup(auth.HashSecret(secret))
+	if !found || auth.Expired(key, time.Now()) {
+		return model.APIKey{}, false, false
+	}
+	return key, false, true
+}
+
+// ListAPIKeys returns the API keys in creation order, without their secrets.
+func (e *Engine) ListAPIKeys() []model.APIKey {
+	return e.keyStore.List()
+}
+
+// GetAPIKey returns a single API key, without its secret.
+func (e *Engine) GetAPIKey(id string) (model.APIKey, error) {
+	return e.keyStore.Get(id)
+}
+
+// CreateAPIKey validates the scope of a new API key and stores it with a generated ID and secret.
+// The secret is only returned here: the engine keeps a hash of it.
+func (e *Engine) CreateAPIKey(key model.APIKey) (model.CreatedAPIKey, error) {
+	if err := e.checkWritable("create API key"); err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+	if err := auth.ValidateKey(key); err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+
+	secret, prefix, err := auth.GenerateSecret()
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+	key.ID = uuid.New().String()
+	key.KeyPrefix = prefix
+	key.CreatedAt = time.Now()
+	if err := e.keyStore.Save(key, auth.HashSecret(secret)); err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+	return model.CreatedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// DeleteAPIKey revokes an API key. Requests made with it are rejected from then on.
+func (e *Engine) DeleteAPIKey(id string) error {
+	if err := e.checkWritable("delete API key"); err != nil {
+		return err
+	}
+	return e.keyStore.Delete(id)
+}
+
+// MatchingDocumentIDs returns the IDs of the documents of an index that match the filters, among
+// the given IDs in their order, or among all documents in documentID order when documentIDs is
+// nil. IDs of documents that are not in the index are left out.
+func (e *Engine) MatchingDocumentIDs(indexName string, documentIDs []string, filters services.Filters) ([]string, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return nil, e.missingIndexError(indexName)
+	}
+	if instance.searcher == nil {
+		return nil, fmt.Errorf("search service not initialized for index '%s'", indexName)
+	}
+
+	matching := []string{}
+	if documentIDs == nil {
+		instance.DocumentStore.Range(func(_ uint32, doc model.Document) bool {
+			if instance.searcher.MatchesFilters(doc, filters, "") {
+				documentID, _ := doc["documentID"].(string)
+				matching = append(matching, documentID)
+			}
+			return true
+		})
+		sort.Strings(matching)
+		return matching, nil
+	}
+	for _, documentID := range documentIDs {
+		internalID, found := instance.DocumentStore.Lookup(documentID)
+		if !found {
+			continue
+		}
+		if doc, found := instance.DocumentStore.Get(internalID); found && instance.searcher.MatchesFilters(doc, filters, "") {
+			matching = append(matching, documentID)
+		}
+	}
+	return matching, nil
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestAPIKeys(t *testing.T) {
+	dataDir := t.TempDir()
+	engine := NewEngine(dataDir)
+
+	if engine.AuthEnabled() {
+		t.Error("Expected authorization to be disabled without an admin key")
+	}
+	if err := engine.SetAdminKey("short"); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("SetAdminKey() of a short key error = %v, want ErrInvalidInput", err)
+	}
+	const adminKey = "admin-key-for-tests"
+	if err := engine.SetAdminKey(adminKey); err != nil {
+		t.Fatalf("SetAdminKey() error = %v", err)
+	}
+	if !engine.AuthEnabled() {
+		t.Error("Expected authorization to be enabled with an admin key")
+	}
+	if _, admin, ok := engine.Authenticate(adminKey); !ok || !admin {
+		t.Errorf("Authenticate(admin key) = admin %v, ok %v, want both", admin, ok)
+	}
+
+	if _, err := engine.CreateAPIKey(model.APIKey{}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("CreateAPIKey() without actions error = %v, want ErrInvalidInput", err)
+	}
+	created, err := engine.CreateAPIKey(model.APIKey{
+		Description: "storefront",
+		Actions:     []model.APIKeyAction{model.APIKeyActionSearch},
+		Indexes:     []string{"products_*"},
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	if created.ID == "" || created.Key == "" || created.KeyPrefix != created.Key[:len(created.KeyPrefix)] {
+		t.Errorf("CreateAPIKey() = %+v, want an ID, a secret and its prefix", created)
+	}
+
+	key, admin, ok := engine.Authenticate(created.Key)
+	if !ok || admin || key.ID != created.ID {
+		t.Errorf("Authenticate(secret) = %+v, admin %v, ok %v, want the created key", key, admin, ok)
+	}
+	if _, _, ok := engine.Authenticate("not-a-key"); ok {
+		t.Error("Expected an unknown secret to be rejected")
+	}
+
+	// Keys are persisted, by the hash of their secret only
+	reopened := NewEngine(dataDir)
+	if key, _, ok := reopened.Authenticate(created.Key); !ok || key.ID != created.ID {
+		t.Errorf("Authenticate() after reopening = %+v, %v, want the created key", key, ok)
+	}
+	if keys := reopened.ListAPIKeys(); len(keys) != 1 || keys[0].Description != "storefront" {
+		t.Errorf("ListAPIKeys() after reopening = %+v, want the created key", keys)
+	}
+
+	if err := engine.DeleteAPIKey(created.ID); err != nil {
+		t.Fatalf("DeleteAPIKey() error = %v", err)
+	}
+	if _, _, ok := engine.Authenticate(created.Key); ok {
+		t.Error("Expected a deleted key to be rejected")
+	}
+	if _, err := engine.GetAPIKey(created.ID); !errors.Is(err, internalErrors.ErrAPIKeyNotFound) {
+		t.Errorf("GetAPIKey() of a deleted key error = %v, want ErrAPIKeyNotFound", err)
+	}
+}
+
+func TestMatchingDocumentIDs(t *testing.T) {
+	engine := NewEngine(t.TempDir())
+	settings := config.IndexSettings{
+		Name:             "products",
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"tenant"},
+	}
+	if err := engine.CreateIndex(settings); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	instance, _ := engine.GetIndex("products")
+	if err := instance.AddDocuments([]model.Document{
+		{"documentID": "3", "title": "Lamp", "tenant": "acme"},
+		{"documentID": "1", "title": "Desk", "tenant": "acme"},
+		{"documentID": "2", "title": "Chair", "tenant": "globex"},
+	}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	filters := services.Filters{Operator: "AND", Filters: []services.FilterCondition{{Field: "tenant", Value: "acme"}}}
+	if ids, err := engine.MatchingDocumentIDs("products", nil, filters); err != nil || !reflect.DeepEqual(ids, []string{"1", "3"}) {
+		t.Errorf("MatchingDocumentIDs() of all documents = %v, %v, want [1 3]", ids, err)
+	}
+	if ids, err := engine.MatchingDocumentIDs("products", []string{"3", "2", "missing"}, filters); err != nil || !reflect.DeepEqual(ids, []string{"3"}) {
+		t.Errorf("MatchingDocumentIDs() of given documents = %v, %v, want [3]", ids, err)
+	}
+	if _, err := engine.MatchingDocumentIDs("missing", nil, filters); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("MatchingDocumentIDs() of a missing index error = %v, want ErrIndexNotFound", err)
+	}
+}
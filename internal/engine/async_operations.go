@@ -3,27 +3,36 @@ package engine
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/internal/errors"
-	"github.com/gcbaptista/go-search-engine/internal/search"
+	"github.com/gcbaptista/go-search-engine/internal/tracing"
 	"github.com/gcbaptista/go-search-engine/model"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CreateIndexAsync creates a new index asynchronously.
 func (e *Engine) CreateIndexAsync(settings config.IndexSettings) (string, error) {
+	if err := e.checkWritable("create index"); err != nil {
+		return "", err
+	}
 	if settings.Name == "" {
 		return "", fmt.Errorf("index name cannot be empty")
 	}
 
 	e.mu.RLock()
-	if _, exists := e.indexes[settings.Name]; exists {
+	if e.indexExistsUnsafe(settings.Name) {
 		e.mu.RUnlock()
 		return "", errors.NewIndexAlreadyExistsError(settings.Name)
 	}
+	if err := e.aliasConflictUnsafe(settings.Name); err != nil {
+		e.mu.RUnlock()
+		return "", err
+	}
 	e.mu.RUnlock()
 
 	jobID := e.jobManager.CreateJob(model.JobTypeCreateIndex, settings.Name, map[string]string{
@@ -41,14 +50,17 @@ func (e *Engine) CreateIndexAsync(settings config.IndexSettings) (string, error)
 }
 
 // executeCreateIndexJob executes the create index job.
-func (e *Engine) executeCreateIndexJob(_ context.Context, settings config.IndexSettings, _ string) error {
+func (e *Engine) executeCreateIndexJob(ctx context.Context, settings config.IndexSettings, _ string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	// Double-check that index doesn't exist
-	if _, exists := e.indexes[settings.Name]; exists {
+	if e.indexExistsUnsafe(settings.Name) {
 		return errors.NewIndexAlreadyExistsError(settings.Name)
 	}
+	if err := e.aliasConflictUnsafe(settings.Name); err != nil {
+		return err
+	}
 
 	// Create in-memory instance first
 	instance, err := NewIndexInstance(settings)
@@ -57,26 +69,30 @@ func (e *Engine) executeCreateIndexJob(_ context.Context, settings config.IndexS
 	}
 
 	// Initialize the searcher
-	searchService, err := search.NewService(instance.InvertedIndex, instance.DocumentStore, instance.settings)
+	searchService, err := e.newSearchServiceUnsafe(instance)
 	if err != nil {
 		return fmt.Errorf("failed to create search service for new index '%s': %w", settings.Name, err)
 	}
 	instance.SetSearcher(searchService)
 
 	// Persist the initial state
-	if err := e.persistUpdatedIndexUnsafe(settings.Name, settings, instance); err != nil {
+	if err := e.persistUpdatedIndexUnsafe(ctx, settings.Name, settings, instance); err != nil {
 		return fmt.Errorf("failed to persist new index '%s': %w", settings.Name, err)
 	}
 
 	e.indexes[settings.Name] = instance
-	log.Printf("Index '%s' created and persisted asynchronously.", settings.Name)
+	e.dropRenameAliasesUnsafe(settings.Name)
+	slog.Info("index created", "index", settings.Name)
 	return nil
 }
 
 // DeleteIndexAsync deletes an index asynchronously.
 func (e *Engine) DeleteIndexAsync(name string) (string, error) {
+	if err := e.checkWritable("delete index"); err != nil {
+		return "", err
+	}
 	e.mu.RLock()
-	if _, exists := e.indexes[name]; !exists {
+	if !e.indexExistsUnsafe(name) {
 		e.mu.RUnlock()
 		return "", errors.NewIndexNotFoundError(name)
 	}
@@ -101,29 +117,23 @@ func (e *Engine) executeDeleteIndexJob(_ context.Context, name string, _ string)
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if _, exists := e.indexes[name]; !exists {
-		return errors.NewIndexNotFoundError(name)
+	if err := e.deleteIndexUnsafe(name); err != nil {
+		return err
 	}
 
-	// Remove from memory
-	delete(e.indexes, name)
-
-	// Remove from disk
-	indexPath := filepath.Join(e.dataDir, name)
-	if err := os.RemoveAll(indexPath); err != nil {
-		return fmt.Errorf("failed to remove index directory %s: %w", indexPath, err)
-	}
-
-	log.Printf("Index '%s' deleted successfully (async).", name)
+	slog.Info("index deleted", "index", name)
 	return nil
 }
 
 // AddDocumentsAsync adds documents to an index asynchronously.
 func (e *Engine) AddDocumentsAsync(indexName string, docs []model.Document) (string, error) {
+	if err := e.checkWritable("add documents"); err != nil {
+		return "", err
+	}
 	e.mu.RLock()
 	if _, exists := e.indexes[indexName]; !exists {
 		e.mu.RUnlock()
-		return "", errors.NewIndexNotFoundError(indexName)
+		return "", e.missingIndexError(indexName)
 	}
 	e.mu.RUnlock()
 
@@ -149,12 +159,58 @@ func (e *Engine) executeAddDocumentsJob(ctx context.Context, indexName string, d
 	e.mu.RUnlock()
 
 	if !exists {
-		return errors.NewIndexNotFoundError(indexName)
+		return e.missingIndexError(indexName)
 	}
 
 	// Update progress
 	e.jobManager.UpdateJobProgress(jobID, 0, len(docs), "Starting document addition")
 
+	_, span := tracing.Start(ctx, "index.add_documents", attribute.String("index", indexName), attribute.Int("documents", len(docs)))
+	added, err := e.addDocumentChunks(ctx, instance, docs, jobID)
+	tracing.End(span, err)
+	e.jobManager.SetJobMetadata(jobID, addedCountMetadataKey, strconv.Itoa(added))
+	if err != nil {
+		if added == 0 {
+			return err
+		}
+		// The chunks added before the job stopped, e.g. because it was cancelled, stay in the
+		// index, so they are persisted as well, or they would be lost on restart
+		if persistErr := e.persistAddedDocuments(ctx, indexName, instance, jobID); persistErr != nil {
+			return fmt.Errorf("%w; %w", err, persistErr)
+		}
+		slog.Info("documents partially added", "index", indexName, "documents", added, "requested", len(docs))
+		return err
+	}
+
+	// Update progress
+	e.jobManager.UpdateJobProgress(jobID, len(docs), len(docs), "Documents added, persisting to disk...")
+
+	if err := e.persistAddedDocuments(ctx, indexName, instance, jobID); err != nil {
+		return err
+	}
+	slog.Info("documents added", "index", indexName, "documents", len(docs))
+	return nil
+}
+
+// persistAddedDocuments persists an index once an add documents job added documents to it and
+// records its sequence number in the job.
+func (e *Engine) persistAddedDocuments(ctx context.Context, indexName string, instance *IndexInstance, jobID string) error {
+	e.mu.RLock()
+	err := e.persistUpdatedIndexUnsafe(ctx, indexName, *instance.settings, instance)
+	e.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to persist updated index '%s': %w", indexName, err)
+	}
+
+	e.recordJobSeq(jobID, instance)
+	return nil
+}
+
+// addDocumentChunks adds documents to an index in chunks, updating the progress of the job after
+// each chunk and stopping if the job is cancelled. It returns the number of documents added, which
+// is less than len(docs) when it stopped early.
+func (e *Engine) addDocumentChunks(ctx context.Context, instance *IndexInstance, docs []model.Document, jobID string) (int, error) {
 	// Process documents in chunks with progress updates and cancellation support
 	const chunkSize = 100
 	totalProcessed := 0
@@ -163,7 +219,7 @@ func (e *Engine) executeAddDocumentsJob(ctx context.Context, indexName string, d
 		// Check for cancellation
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("job cancelled: %w", ctx.Err())
+			return totalProcessed, fmt.Errorf("job cancelled: %w", ctx.Err())
 		default:
 		}
 
@@ -176,7 +232,7 @@ func (e *Engine) executeAddDocumentsJob(ctx context.Context, indexName string, d
 
 		// Add chunk of documents
 		if err := instance.AddDocuments(chunk); err != nil {
-			return fmt.Errorf("failed to add document chunk %d-%d to index '%s': %w", i, end-1, indexName, err)
+			return totalProcessed, fmt.Errorf("failed to add document chunk %d-%d to index '%s': %w", i, end-1, instance.settings.Name, err)
 		}
 
 		totalProcessed += len(chunk)
@@ -186,24 +242,14 @@ func (e *Engine) executeAddDocumentsJob(ctx context.Context, indexName string, d
 		e.jobManager.UpdateJobProgress(jobID, totalProcessed, len(docs), progressMsg)
 	}
 
-	// Update progress
-	e.jobManager.UpdateJobProgress(jobID, len(docs), len(docs), "Documents added, persisting to disk...")
-
-	// Persist the updated index
-	e.mu.RLock()
-	err := e.persistUpdatedIndexUnsafe(indexName, *instance.settings, instance)
-	e.mu.RUnlock()
-
-	if err != nil {
-		return fmt.Errorf("failed to persist updated index '%s': %w", indexName, err)
-	}
-
-	log.Printf("Added %d documents to index '%s' (async).", len(docs), indexName)
-	return nil
+	return totalProcessed, nil
 }
 
 // RenameIndexAsync renames an index asynchronously.
 func (e *Engine) RenameIndexAsync(oldName, newName string) (string, error) {
+	if err := e.checkWritable("rename index"); err != nil {
+		return "", err
+	}
 	if oldName == newName {
 		return "", errors.NewSameNameError(oldName)
 	}
@@ -211,12 +257,16 @@ func (e *Engine) RenameIndexAsync(oldName, newName string) (string, error) {
 	e.mu.RLock()
 	if _, exists := e.indexes[oldName]; !exists {
 		e.mu.RUnlock()
-		return "", errors.NewIndexNotFoundError(oldName)
+		return "", e.missingIndexError(oldName)
 	}
-	if _, exists := e.indexes[newName]; exists {
+	if e.indexExistsUnsafe(newName) {
 		e.mu.RUnlock()
 		return "", errors.NewIndexAlreadyExistsError(newName)
 	}
+	if err := e.aliasConflictUnsafe(newName); err != nil {
+		e.mu.RUnlock()
+		return "", err
+	}
 	e.mu.RUnlock()
 
 	jobID := e.jobManager.CreateJob(model.JobTypeRenameIndex, oldName, map[string]string{
@@ -236,25 +286,29 @@ func (e *Engine) RenameIndexAsync(oldName, newName string) (string, error) {
 }
 
 // executeRenameIndexJob executes the rename index job.
-func (e *Engine) executeRenameIndexJob(_ context.Context, oldName, newName string, _ string) error {
+func (e *Engine) executeRenameIndexJob(ctx context.Context, oldName, newName string, _ string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	instance, exists := e.indexes[oldName]
 	if !exists {
-		return errors.NewIndexNotFoundError(oldName)
+		return e.missingIndexError(oldName)
 	}
 
-	if _, exists := e.indexes[newName]; exists {
+	if e.indexExistsUnsafe(newName) {
 		return errors.NewIndexAlreadyExistsError(newName)
 	}
+	if err := e.aliasConflictUnsafe(newName); err != nil {
+		return err
+	}
 
 	// Update the settings with the new name
 	newSettings := *instance.settings
 	newSettings.Name = newName
 
-	// Create new directory and persist with new name
-	if err := e.persistUpdatedIndexUnsafe(newName, newSettings, instance); err != nil {
+	// Create new directory and persist with new name; merges write to the old directory
+	instance.waitForSegmentMerges()
+	if err := e.persistUpdatedIndexUnsafe(ctx, newName, newSettings, instance); err != nil {
 		return fmt.Errorf("failed to persist renamed index: %w", err)
 	}
 
@@ -265,23 +319,37 @@ func (e *Engine) executeRenameIndexJob(_ context.Context, oldName, newName strin
 	e.indexes[newName] = instance
 	delete(e.indexes, oldName)
 
+	if err := e.ruleStore.RenameIndexRules(oldName, newName); err != nil {
+		slog.Warn("failed to move rules of renamed index", "index", oldName, "new_index", newName, "error", err)
+	}
+	e.disableShadowsOf(oldName)
+	e.addRenameAliasUnsafe(oldName, newName)
+	e.retargetAliasesUnsafe(oldName, newName)
+	e.renameRelevanceTestsUnsafe(oldName, newName)
+
 	// Remove old directory
 	oldIndexPath := filepath.Join(e.dataDir, oldName)
 	if err := os.RemoveAll(oldIndexPath); err != nil {
-		log.Printf("Warning: Failed to remove old index directory %s: %v", oldIndexPath, err)
+		slog.Warn("failed to remove old index directory", "path", oldIndexPath, "error", err)
 		// Don't return error as the rename was successful
 	}
+	if err := e.documents.DeleteDocuments(oldName); err != nil {
+		slog.Warn("failed to delete documents stored under the old index name", "index", oldName, "error", err)
+	}
 
-	log.Printf("Index renamed from '%s' to '%s' successfully (async).", oldName, newName)
+	slog.Info("index renamed", "index", oldName, "new_index", newName)
 	return nil
 }
 
 // DeleteAllDocumentsAsync deletes all documents from an index asynchronously.
 func (e *Engine) DeleteAllDocumentsAsync(indexName string) (string, error) {
+	if err := e.checkWritable("delete documents"); err != nil {
+		return "", err
+	}
 	e.mu.RLock()
 	if _, exists := e.indexes[indexName]; !exists {
 		e.mu.RUnlock()
-		return "", errors.NewIndexNotFoundError(indexName)
+		return "", e.missingIndexError(indexName)
 	}
 	e.mu.RUnlock()
 
@@ -300,13 +368,13 @@ func (e *Engine) DeleteAllDocumentsAsync(indexName string) (string, error) {
 }
 
 // executeDeleteAllDocumentsJob executes the delete all documents job.
-func (e *Engine) executeDeleteAllDocumentsJob(_ context.Context, indexName string, _ string) error {
+func (e *Engine) executeDeleteAllDocumentsJob(ctx context.Context, indexName string, jobID string) error {
 	e.mu.RLock()
 	instance, exists := e.indexes[indexName]
 	e.mu.RUnlock()
 
 	if !exists {
-		return errors.NewIndexNotFoundError(indexName)
+		return e.missingIndexError(indexName)
 	}
 
 	// Delete all documents
@@ -316,23 +384,27 @@ func (e *Engine) executeDeleteAllDocumentsJob(_ context.Context, indexName strin
 
 	// Persist the updated index
 	e.mu.RLock()
-	err := e.persistUpdatedIndexUnsafe(indexName, *instance.settings, instance)
+	err := e.persistUpdatedIndexUnsafe(ctx, indexName, *instance.settings, instance)
 	e.mu.RUnlock()
 
 	if err != nil {
 		return fmt.Errorf("failed to persist updated index '%s': %w", indexName, err)
 	}
 
-	log.Printf("Deleted all documents from index '%s' (async).", indexName)
+	e.recordJobSeq(jobID, instance)
+	slog.Info("all documents deleted", "index", indexName)
 	return nil
 }
 
 // DeleteDocumentAsync deletes a specific document from an index asynchronously.
 func (e *Engine) DeleteDocumentAsync(indexName, documentID string) (string, error) {
+	if err := e.checkWritable("delete document"); err != nil {
+		return "", err
+	}
 	e.mu.RLock()
 	if _, exists := e.indexes[indexName]; !exists {
 		e.mu.RUnlock()
-		return "", errors.NewIndexNotFoundError(indexName)
+		return "", e.missingIndexError(indexName)
 	}
 	e.mu.RUnlock()
 
@@ -342,7 +414,7 @@ func (e *Engine) DeleteDocumentAsync(indexName, documentID string) (string, erro
 	})
 
 	err := e.jobManager.ExecuteJob(jobID, func(ctx context.Context, job *model.Job) error {
-		return e.executeDeleteDocumentJob(ctx, indexName, documentID)
+		return e.executeDeleteDocumentJob(ctx, indexName, documentID, jobID)
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to start delete document job: %w", err)
@@ -352,13 +424,13 @@ func (e *Engine) DeleteDocumentAsync(indexName, documentID string) (string, erro
 }
 
 // executeDeleteDocumentJob executes the delete document job.
-func (e *Engine) executeDeleteDocumentJob(_ context.Context, indexName, documentID string) error {
+func (e *Engine) executeDeleteDocumentJob(ctx context.Context, indexName, documentID, jobID string) error {
 	e.mu.RLock()
 	instance, exists := e.indexes[indexName]
 	e.mu.RUnlock()
 
 	if !exists {
-		return errors.NewIndexNotFoundError(indexName)
+		return e.missingIndexError(indexName)
 	}
 
 	// Delete the document
@@ -368,13 +440,14 @@ func (e *Engine) executeDeleteDocumentJob(_ context.Context, indexName, document
 
 	// Persist the updated index
 	e.mu.RLock()
-	err := e.persistUpdatedIndexUnsafe(indexName, *instance.settings, instance)
+	err := e.persistUpdatedIndexUnsafe(ctx, indexName, *instance.settings, instance)
 	e.mu.RUnlock()
 
 	if err != nil {
 		return fmt.Errorf("failed to persist updated index '%s': %w", indexName, err)
 	}
 
-	log.Printf("Deleted document '%s' from index '%s' (async).", documentID, indexName)
+	e.recordJobSeq(jobID, instance)
+	slog.Info("document deleted", "index", indexName, "document_id", documentID)
 	return nil
 }
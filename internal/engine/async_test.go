@@ -2,10 +2,12 @@ package engine
 
 import (
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/jobs"
 	"github.com/gcbaptista/go-search-engine/model"
 	"github.com/gcbaptista/go-search-engine/services"
 )
@@ -254,6 +256,66 @@ func TestEngine_ListJobsForIndex(t *testing.T) {
 	}
 }
 
+// cancellingJobRunner runs jobs with the built-in job manager and cancels each job once it reports
+// some progress, as a client cancelling it mid-way would.
+type cancellingJobRunner struct {
+	*jobs.Manager
+}
+
+func (r *cancellingJobRunner) UpdateJobProgress(jobID string, current, total int, message string) {
+	r.Manager.UpdateJobProgress(jobID, current, total, message)
+	if current > 0 && current < total {
+		_, _ = r.Manager.CancelJob(jobID)
+	}
+}
+
+func TestEngine_CancelledAddDocumentsKeepsAddedChunks(t *testing.T) {
+	testDir := createTestDir(t)
+	defer func() {
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Logf("Failed to remove test directory: %v", err)
+		}
+	}()
+
+	runner := &cancellingJobRunner{Manager: jobs.NewManager(2)}
+	runner.Start()
+	defer runner.Stop()
+	engine, err := NewEngineWithExtensions(testDir, services.Extensions{JobRunner: runner})
+	if err != nil {
+		t.Fatalf("NewEngineWithExtensions() error = %v", err)
+	}
+	if err := engine.CreateIndex(config.IndexSettings{Name: "cancelled", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	docs := make([]model.Document, 250)
+	for i := range docs {
+		docs[i] = model.Document{"documentID": "doc" + strconv.Itoa(i), "title": "title " + strconv.Itoa(i)}
+	}
+	jobID, err := engine.AddDocumentsAsync("cancelled", docs)
+	if err != nil {
+		t.Fatalf("AddDocumentsAsync() error = %v", err)
+	}
+	job := waitForJob(t, engine, jobID)
+	if job.Status != model.JobStatusCancelled {
+		t.Fatalf("Job status = %s (%s), want cancelled", job.Status, job.Error)
+	}
+	// The job stops after its first chunk of 100 documents
+	if job.Metadata[addedCountMetadataKey] != "100" || job.Metadata[seqMetadataKey] == "" {
+		t.Errorf("Expected the added count and seq of the first chunk in the job metadata, got %v", job.Metadata)
+	}
+
+	reloaded := NewEngine(testDir)
+	defer reloaded.jobManager.Stop()
+	instance, err := reloaded.GetIndex("cancelled")
+	if err != nil {
+		t.Fatalf("Failed to get reloaded index: %v", err)
+	}
+	if got := instance.(*IndexInstance).DocumentStore.Len(); got != 100 {
+		t.Errorf("Reloaded index has %d documents, want the 100 added before the job was cancelled", got)
+	}
+}
+
 // Helper functions
 func createTestDir(t *testing.T) string {
 	dir, err := os.MkdirTemp("", "engine_async_test_*")
@@ -0,0 +1,290 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// batchTTL is how long a batch may stay idle before it is discarded.
+const batchTTL = time.Hour
+
+// writeBatch accumulates document changes for a single index until they are committed.
+// Staging a document replaces any earlier change staged for the same document ID.
+type writeBatch struct {
+	mu        sync.Mutex
+	id        string
+	indexName string
+	upserts   map[string]model.Document
+	deletes   map[string]struct{}
+	closed    bool // Set once the batch is committed or aborted
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// info returns a snapshot of the batch. The caller must hold b.mu.
+func (b *writeBatch) info() model.BatchInfo {
+	return model.BatchInfo{
+		ID:              b.id,
+		IndexName:       b.indexName,
+		StagedDocuments: len(b.upserts),
+		StagedDeletions: len(b.deletes),
+		CreatedAt:       b.createdAt,
+		UpdatedAt:       b.updatedAt,
+		ExpiresAt:       b.updatedAt.Add(batchTTL),
+	}
+}
+
+// OpenBatch starts a new write batch for an index.
+func (e *Engine) OpenBatch(indexName string) (model.BatchInfo, error) {
+	if err := e.checkWritable("open batch"); err != nil {
+		return model.BatchInfo{}, err
+	}
+	e.mu.RLock()
+	_, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.BatchInfo{}, e.missingIndexError(indexName)
+	}
+
+	now := time.Now()
+	batch := &writeBatch{
+		id:        uuid.New().String(),
+		indexName: indexName,
+		upserts:   make(map[string]model.Document),
+		deletes:   make(map[string]struct{}),
+		createdAt: now,
+		updatedAt: now,
+	}
+
+	e.batchesMu.Lock()
+	e.removeExpiredBatchesUnsafe(now)
+	e.batches[batch.id] = batch
+	e.batchesMu.Unlock()
+
+	return batch.info(), nil
+}
+
+// AddToBatch stages documents to be added or updated when the batch is committed.
+// Every document must carry a documentID.
+func (e *Engine) AddToBatch(indexName, batchID string, docs []model.Document) (model.BatchInfo, error) {
+	batch, err := e.getBatch(indexName, batchID)
+	if err != nil {
+		return model.BatchInfo{}, err
+	}
+
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	if batch.closed {
+		return model.BatchInfo{}, errors.NewBatchNotFoundError(batchID)
+	}
+
+	for i, doc := range docs {
+		docID, ok := doc["documentID"].(string)
+		if !ok || docID == "" {
+			return model.BatchInfo{}, errors.NewValidationError("documentID", fmt.Sprintf("document at position %d has no documentID", i))
+		}
+	}
+	for _, doc := range docs {
+		docID := doc["documentID"].(string)
+		batch.upserts[docID] = doc
+		delete(batch.deletes, docID)
+	}
+	batch.updatedAt = time.Now()
+	return batch.info(), nil
+}
+
+// DeleteInBatch stages the deletion of a document when the batch is committed.
+// Deleting a document that was only staged in this batch simply unstages it.
+func (e *Engine) DeleteInBatch(indexName, batchID, documentID string) (model.BatchInfo, error) {
+	batch, err := e.getBatch(indexName, batchID)
+	if err != nil {
+		return model.BatchInfo{}, err
+	}
+
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.BatchInfo{}, e.missingIndexError(indexName)
+	}
+
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	if batch.closed {
+		return model.BatchInfo{}, errors.NewBatchNotFoundError(batchID)
+	}
+
+	_, staged := batch.upserts[documentID]
+	delete(batch.upserts, documentID)
+
+	instance.DocumentStore.Mu.RLock()
+	_, stored := instance.DocumentStore.ExternalIDtoInternalID[documentID]
+	instance.DocumentStore.Mu.RUnlock()
+
+	if stored {
+		batch.deletes[documentID] = struct{}{}
+	} else if !staged {
+		return model.BatchInfo{}, errors.NewDocumentNotFoundError(documentID, indexName)
+	}
+	batch.updatedAt = time.Now()
+	return batch.info(), nil
+}
+
+// GetBatch returns the current state of an open batch.
+func (e *Engine) GetBatch(indexName, batchID string) (model.BatchInfo, error) {
+	batch, err := e.getBatch(indexName, batchID)
+	if err != nil {
+		return model.BatchInfo{}, err
+	}
+
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	return batch.info(), nil
+}
+
+// AbortBatch discards an open batch and all of its staged changes.
+func (e *Engine) AbortBatch(indexName, batchID string) error {
+	batch, err := e.getBatch(indexName, batchID)
+	if err != nil {
+		return err
+	}
+
+	e.batchesMu.Lock()
+	delete(e.batches, batchID)
+	e.batchesMu.Unlock()
+
+	batch.mu.Lock()
+	batch.closed = true
+	batch.mu.Unlock()
+	return nil
+}
+
+// CommitBatchAsync closes a batch and applies its staged changes asynchronously.
+// The changes become visible to searches all at once; if any change is invalid, none is applied.
+func (e *Engine) CommitBatchAsync(indexName, batchID string) (string, error) {
+	if err := e.checkWritable("commit batch"); err != nil {
+		return "", err
+	}
+	batch, err := e.getBatch(indexName, batchID)
+	if err != nil {
+		return "", err
+	}
+
+	// Close the batch before reading it so no further changes can be staged
+	e.batchesMu.Lock()
+	delete(e.batches, batchID)
+	e.batchesMu.Unlock()
+
+	batch.mu.Lock()
+	if batch.closed {
+		batch.mu.Unlock()
+		return "", errors.NewBatchNotFoundError(batchID)
+	}
+	batch.closed = true
+	upserts := make([]model.Document, 0, len(batch.upserts))
+	for _, doc := range batch.upserts {
+		upserts = append(upserts, doc)
+	}
+	deletes := make([]string, 0, len(batch.deletes))
+	for docID := range batch.deletes {
+		deletes = append(deletes, docID)
+	}
+	batch.mu.Unlock()
+
+	// Apply in a deterministic order so new documents get stable internal IDs
+	sort.Slice(upserts, func(i, j int) bool {
+		return upserts[i]["documentID"].(string) < upserts[j]["documentID"].(string)
+	})
+	sort.Strings(deletes)
+
+	jobID := e.jobManager.CreateJob(model.JobTypeCommitBatch, indexName, map[string]string{
+		"operation":      "commit_batch",
+		"batch_id":       batchID,
+		"document_count": fmt.Sprintf("%d", len(upserts)),
+		"deletion_count": fmt.Sprintf("%d", len(deletes)),
+	})
+
+	err = e.jobManager.ExecuteJob(jobID, func(ctx context.Context, job *model.Job) error {
+		return e.executeCommitBatchJob(ctx, indexName, batchID, upserts, deletes, jobID)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start commit batch job: %w", err)
+	}
+
+	return jobID, nil
+}
+
+// executeCommitBatchJob executes the commit batch job.
+func (e *Engine) executeCommitBatchJob(ctx context.Context, indexName, batchID string, upserts []model.Document, deletes []string, jobID string) error {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+
+	if !exists {
+		return e.missingIndexError(indexName)
+	}
+
+	total := len(upserts) + len(deletes)
+	e.jobManager.UpdateJobProgress(jobID, 0, total, "Applying batch")
+
+	if err := instance.ApplyBatch(upserts, deletes); err != nil {
+		return fmt.Errorf("failed to apply batch '%s' to index '%s': %w", batchID, indexName, err)
+	}
+
+	e.jobManager.UpdateJobProgress(jobID, total, total, "Batch applied, persisting to disk...")
+
+	// Persist the updated index
+	e.mu.RLock()
+	err := e.persistUpdatedIndexUnsafe(ctx, indexName, *instance.settings, instance)
+	e.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to persist updated index '%s': %w", indexName, err)
+	}
+
+	e.recordJobSeq(jobID, instance)
+	slog.Info("batch committed", "batch_id", batchID, "index", indexName, "upserts", len(upserts), "deletions", len(deletes))
+	return nil
+}
+
+// getBatch looks up an open, unexpired batch belonging to the given index.
+func (e *Engine) getBatch(indexName, batchID string) (*writeBatch, error) {
+	e.mu.RLock()
+	_, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return nil, e.missingIndexError(indexName)
+	}
+
+	e.batchesMu.Lock()
+	defer e.batchesMu.Unlock()
+
+	e.removeExpiredBatchesUnsafe(time.Now())
+	batch, exists := e.batches[batchID]
+	if !exists || batch.indexName != indexName {
+		return nil, errors.NewBatchNotFoundError(batchID)
+	}
+	return batch, nil
+}
+
+// removeExpiredBatchesUnsafe discards batches that have been idle for longer than batchTTL.
+// The caller must hold e.batchesMu.
+func (e *Engine) removeExpiredBatchesUnsafe(now time.Time) {
+	for id, batch := range e.batches {
+		batch.mu.Lock()
+		expired := now.Sub(batch.updatedAt) > batchTTL
+		batch.mu.Unlock()
+		if expired {
+			delete(e.batches, id)
+		}
+	}
+}
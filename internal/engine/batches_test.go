@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// waitForJob polls a job until it completes or fails.
+func waitForJob(t *testing.T, engine *Engine, jobID string) *model.Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := engine.GetJob(jobID)
+		if err != nil {
+			t.Fatalf("Failed to get job status: %v", err)
+		}
+		if job.Status == model.JobStatusCompleted || job.Status == model.JobStatusFailed || job.Status == model.JobStatusCancelled {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish within timeout", jobID)
+	return nil
+}
+
+func newBatchTestEngine(t *testing.T) (*Engine, services.IndexAccessor) {
+	t.Helper()
+	testDir := createTestDir(t)
+	t.Cleanup(func() {
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Logf("Failed to remove test directory: %v", err)
+		}
+	})
+
+	engine := NewEngine(testDir)
+	t.Cleanup(engine.jobManager.Stop)
+
+	settings := config.IndexSettings{
+		Name:                 "test-batch-index",
+		SearchableFields:     []string{"title"},
+		MinWordSizeFor1Typo:  4,
+		MinWordSizeFor2Typos: 8,
+	}
+	if err := engine.CreateIndex(settings); err != nil {
+		t.Fatalf("Failed to create test index: %v", err)
+	}
+	indexAccessor, err := engine.GetIndex("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to get test index: %v", err)
+	}
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Old Catalog Entry"},
+		{"documentID": "2", "title": "Discontinued Product"},
+	}); err != nil {
+		t.Fatalf("Failed to add test documents: %v", err)
+	}
+	return engine, indexAccessor
+}
+
+func searchTotal(t *testing.T, indexAccessor services.IndexAccessor, queryString string) int {
+	t.Helper()
+	result, err := indexAccessor.Search(services.SearchQuery{QueryString: queryString, Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	return result.Total
+}
+
+func TestEngine_BatchCommit(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+
+	batch, err := engine.OpenBatch("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to open batch: %v", err)
+	}
+
+	if _, err := engine.AddToBatch("test-batch-index", batch.ID, []model.Document{
+		{"documentID": "1", "title": "Refreshed Catalog Entry"},
+		{"documentID": "3", "title": "Brand New Product"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents to batch: %v", err)
+	}
+	info, err := engine.DeleteInBatch("test-batch-index", batch.ID, "2")
+	if err != nil {
+		t.Fatalf("Failed to stage deletion: %v", err)
+	}
+	if info.StagedDocuments != 2 || info.StagedDeletions != 1 {
+		t.Errorf("Expected 2 staged documents and 1 staged deletion, got %d and %d", info.StagedDocuments, info.StagedDeletions)
+	}
+
+	// Nothing is visible before the commit
+	if total := searchTotal(t, indexAccessor, "brand"); total != 0 {
+		t.Errorf("Expected staged document to be invisible before commit, got %d hits", total)
+	}
+	if total := searchTotal(t, indexAccessor, "discontinued"); total != 1 {
+		t.Errorf("Expected staged deletion to be invisible before commit, got %d hits", total)
+	}
+
+	jobID, err := engine.CommitBatchAsync("test-batch-index", batch.ID)
+	if err != nil {
+		t.Fatalf("Failed to commit batch: %v", err)
+	}
+	job := waitForJob(t, engine, jobID)
+	if job.Status != model.JobStatusCompleted {
+		t.Fatalf("Expected commit job to complete, got %s: %s", job.Status, job.Error)
+	}
+	if job.Type != model.JobTypeCommitBatch {
+		t.Errorf("Expected job type %s, got %s", model.JobTypeCommitBatch, job.Type)
+	}
+
+	if total := searchTotal(t, indexAccessor, "brand"); total != 1 {
+		t.Errorf("Expected committed document to be searchable, got %d hits", total)
+	}
+	if total := searchTotal(t, indexAccessor, "refreshed"); total != 1 {
+		t.Errorf("Expected updated document to be searchable, got %d hits", total)
+	}
+	if total := searchTotal(t, indexAccessor, "discontinued"); total != 0 {
+		t.Errorf("Expected deleted document to be gone, got %d hits", total)
+	}
+
+	// A committed batch is closed
+	if _, err := engine.GetBatch("test-batch-index", batch.ID); !errors.Is(err, internalErrors.ErrBatchNotFound) {
+		t.Errorf("Expected ErrBatchNotFound after commit, got %v", err)
+	}
+}
+
+func TestEngine_BatchCommitIsAllOrNothing(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+
+	batch, err := engine.OpenBatch("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to open batch: %v", err)
+	}
+	if _, err := engine.AddToBatch("test-batch-index", batch.ID, []model.Document{
+		{"documentID": "3", "title": "Brand New Product"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents to batch: %v", err)
+	}
+	if _, err := engine.DeleteInBatch("test-batch-index", batch.ID, "2"); err != nil {
+		t.Fatalf("Failed to stage deletion: %v", err)
+	}
+
+	// The document disappears before the commit, which makes the staged deletion invalid
+	if err := indexAccessor.DeleteDocument("2"); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+
+	jobID, err := engine.CommitBatchAsync("test-batch-index", batch.ID)
+	if err != nil {
+		t.Fatalf("Failed to commit batch: %v", err)
+	}
+	if job := waitForJob(t, engine, jobID); job.Status != model.JobStatusFailed {
+		t.Fatalf("Expected commit job to fail, got %s", job.Status)
+	}
+	if total := searchTotal(t, indexAccessor, "brand"); total != 0 {
+		t.Errorf("Expected no change from a failed batch, got %d hits", total)
+	}
+}
+
+func TestEngine_BatchAbortAndValidation(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+
+	if _, err := engine.OpenBatch("missing-index"); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected ErrIndexNotFound, got %v", err)
+	}
+
+	batch, err := engine.OpenBatch("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to open batch: %v", err)
+	}
+	if _, err := engine.AddToBatch("test-batch-index", batch.ID, []model.Document{{"title": "No ID"}}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a document without ID, got %v", err)
+	}
+	if _, err := engine.DeleteInBatch("test-batch-index", batch.ID, "unknown"); !errors.Is(err, internalErrors.ErrDocumentNotFound) {
+		t.Errorf("Expected ErrDocumentNotFound for an unknown document, got %v", err)
+	}
+
+	// Deleting a document that only exists in the batch unstages it
+	if _, err := engine.AddToBatch("test-batch-index", batch.ID, []model.Document{{"documentID": "3", "title": "Draft"}}); err != nil {
+		t.Fatalf("Failed to add documents to batch: %v", err)
+	}
+	info, err := engine.DeleteInBatch("test-batch-index", batch.ID, "3")
+	if err != nil {
+		t.Fatalf("Failed to unstage document: %v", err)
+	}
+	if info.StagedDocuments != 0 || info.StagedDeletions != 0 {
+		t.Errorf("Expected an empty batch, got %d staged documents and %d staged deletions", info.StagedDocuments, info.StagedDeletions)
+	}
+
+	if err := engine.AbortBatch("test-batch-index", batch.ID); err != nil {
+		t.Fatalf("Failed to abort batch: %v", err)
+	}
+	if _, err := engine.CommitBatchAsync("test-batch-index", batch.ID); !errors.Is(err, internalErrors.ErrBatchNotFound) {
+		t.Errorf("Expected ErrBatchNotFound when committing an aborted batch, got %v", err)
+	}
+	if total := searchTotal(t, indexAccessor, "catalog"); total != 1 {
+		t.Errorf("Expected index to be unchanged, got %d hits", total)
+	}
+}
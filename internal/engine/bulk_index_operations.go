@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// MatchIndexes returns the names of the indexes matching a glob pattern ("staging_*",
+// "test_?"), sorted. Patterns use the syntax of path.Match.
+func (e *Engine) MatchIndexes(pattern string) ([]string, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.NewValidationError("pattern", "cannot be empty")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, errors.NewValidationError("pattern", fmt.Sprintf("'%s' is not a valid pattern", pattern))
+	}
+
+	var names []string
+	for _, name := range e.ListIndexes() {
+		if matched, _ := path.Match(pattern, name); matched {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// DeleteIndexesAsync deletes every index matching a glob pattern in a single job, so that
+// throwaway indexes can be cleaned up in one call. It returns the job's ID and the indexes it
+// deletes; when no index matches, no job is started and the ID is empty. Indexes deleted by
+// other means before the job runs are skipped.
+func (e *Engine) DeleteIndexesAsync(pattern string) (string, []string, error) {
+	if err := e.checkWritable("delete indexes"); err != nil {
+		return "", nil, err
+	}
+	names, err := e.MatchIndexes(pattern)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(names) == 0 {
+		return "", names, nil
+	}
+
+	jobID := e.jobManager.CreateJob(model.JobTypeDeleteIndexes, pattern, map[string]string{
+		"operation":   "delete_indexes",
+		"pattern":     pattern,
+		"indexes":     strings.Join(names, ","),
+		"index_count": strconv.Itoa(len(names)),
+	})
+
+	err = e.jobManager.ExecuteJob(jobID, func(ctx context.Context, job *model.Job) error {
+		return e.executeDeleteIndexesJob(ctx, names, jobID)
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start delete indexes job: %w", err)
+	}
+
+	return jobID, names, nil
+}
+
+// executeDeleteIndexesJob executes the delete indexes job, one index at a time so that searches
+// on the remaining indexes are not blocked for the whole job. An index failing to be deleted
+// does not stop the others; the job then fails listing it.
+func (e *Engine) executeDeleteIndexesJob(ctx context.Context, names []string, jobID string) error {
+	e.jobManager.UpdateJobProgress(jobID, 0, len(names), "Starting index deletion")
+
+	deleted := 0
+	var failed []string
+	for i, name := range names {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("job cancelled after deleting %d indexes: %w", deleted, ctx.Err())
+		default:
+		}
+
+		e.mu.Lock()
+		exists := e.indexExistsUnsafe(name) // Else deleted since the job was created
+		var err error
+		if exists {
+			err = e.deleteIndexUnsafe(name)
+		}
+		e.mu.Unlock()
+		if err != nil {
+			slog.Warn("failed to delete index", "index", name, "error", err)
+			failed = append(failed, name)
+		} else if exists {
+			deleted++
+			slog.Info("index deleted", "index", name)
+		}
+		e.jobManager.UpdateJobProgress(jobID, i+1, len(names), fmt.Sprintf("Deleted index '%s'", name))
+	}
+
+	e.jobManager.SetJobMetadata(jobID, "deleted_count", strconv.Itoa(deleted))
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete indexes: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
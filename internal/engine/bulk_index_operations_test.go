@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestDeleteIndexesAsync(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	for _, name := range []string{"staging_b", "staging_a", "production"} {
+		if err := engine.CreateIndex(config.IndexSettings{Name: name, SearchableFields: []string{"title"}}); err != nil {
+			t.Fatalf("CreateIndex(%q) error = %v", name, err)
+		}
+	}
+	if _, err := engine.PutAlias("staging", "staging_a"); err != nil {
+		t.Fatalf("PutAlias() error = %v", err)
+	}
+
+	var validationErr *internalErrors.ValidationError
+	for _, pattern := range []string{"", "staging_["} {
+		if _, _, err := engine.DeleteIndexesAsync(pattern); !errors.As(err, &validationErr) {
+			t.Errorf("DeleteIndexesAsync(%q) error = %v, want a validation error", pattern, err)
+		}
+	}
+
+	jobID, names, err := engine.DeleteIndexesAsync("staging_*")
+	if err != nil {
+		t.Fatalf("DeleteIndexesAsync() error = %v", err)
+	}
+	if want := []string{"staging_a", "staging_b"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("DeleteIndexesAsync() indexes = %v, want %v", names, want)
+	}
+	job := waitForJob(t, engine, jobID)
+	if job.Status != model.JobStatusCompleted {
+		t.Fatalf("Job status = %s (%s), want completed", job.Status, job.Error)
+	}
+	if job.Type != model.JobTypeDeleteIndexes || job.Metadata["deleted_count"] != "2" {
+		t.Errorf("Job = %s with metadata %v, want delete_indexes deleting 2 indexes", job.Type, job.Metadata)
+	}
+	if remaining, _ := engine.MatchIndexes("*"); !reflect.DeepEqual(remaining, []string{"production", "test-batch-index"}) {
+		t.Errorf("Remaining indexes = %v, want [production test-batch-index]", remaining)
+	}
+	if _, err := engine.GetAlias("staging"); err == nil {
+		t.Error("Expected the alias of a deleted index to be dropped")
+	}
+
+	// Nothing left to match: no job is started
+	jobID, names, err = engine.DeleteIndexesAsync("staging_*")
+	if err != nil || jobID != "" || len(names) != 0 {
+		t.Errorf("DeleteIndexesAsync() = %q, %v, %v, want no job", jobID, names, err)
+	}
+}
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/indexing"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+const (
+	// bulkIngestBatchSize is the number of parsed documents handed to the bulk indexer at once
+	bulkIngestBatchSize = 1000
+	// maxReportedLineErrors caps the skipped lines listed in a bulk ingest report; all are counted
+	maxReportedLineErrors = 100
+)
+
+// BulkIngest indexes newline-delimited JSON read from r, one document object per line. Documents
+// are indexed in batches as they are parsed, so the whole payload is never held in memory. Lines
+// that are not valid documents are skipped and reported with their line number.
+//
+// If reading r fails, the documents parsed until then are still indexed and a validation error is
+// returned with the report.
+func (e *Engine) BulkIngest(indexName string, r io.Reader) (model.BulkIngestReport, error) {
+	if err := e.checkWritable("ingest documents"); err != nil {
+		return model.BulkIngestReport{}, err
+	}
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.BulkIngestReport{}, e.missingIndexError(indexName)
+	}
+	if instance.indexer == nil {
+		return model.BulkIngestReport{}, fmt.Errorf("indexer service not initialized for index '%s'", indexName)
+	}
+
+	start := time.Now()
+	report := model.BulkIngestReport{IndexName: indexName, Errors: []model.BulkLineError{}}
+	config := indexing.DefaultBulkIndexingConfig()
+	config.BatchSize = bulkIngestBatchSize / config.WorkerCount
+	if config.BatchSize < 1 {
+		config.BatchSize = 1
+	}
+
+	batch := make([]model.Document, 0, bulkIngestBatchSize)
+	indexBatch := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := instance.indexer.BulkAddDocuments(batch, config); err != nil {
+			return fmt.Errorf("failed to index documents of index '%s': %w", indexName, err)
+		}
+		report.Indexed += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	ingestLine := func(lineNumber int, line []byte) error {
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			return nil
+		}
+		report.Lines++
+		doc, err := parseBulkLine(line)
+		if err != nil {
+			report.Failed++
+			if len(report.Errors) < maxReportedLineErrors {
+				report.Errors = append(report.Errors, model.BulkLineError{Line: lineNumber, Error: err.Error()})
+			}
+			return nil
+		}
+		batch = append(batch, doc)
+		if len(batch) < bulkIngestBatchSize {
+			return nil
+		}
+		return indexBatch()
+	}
+
+	reader := bufio.NewReader(r)
+	var readErr error
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			readErr = errors.NewValidationError("body", fmt.Sprintf("failed to read documents at line %d: %v", lineNumber, err))
+			break
+		}
+		if ingestErr := ingestLine(lineNumber, line); ingestErr != nil {
+			return report, ingestErr
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if err := indexBatch(); err != nil {
+		return report, err
+	}
+
+	if report.Indexed > 0 {
+		instance.recordWrite()
+		e.mu.RLock()
+		err := e.persistUpdatedIndexUnsafe(context.Background(), indexName, *instance.settings, instance)
+		e.mu.RUnlock()
+		if err != nil {
+			return report, fmt.Errorf("failed to persist updated index '%s': %w", indexName, err)
+		}
+	}
+
+	report.Seq = instance.Seq()
+	slog.Info("bulk ingest completed", "index", indexName, "indexed", report.Indexed, "skipped", report.Failed, "duration", time.Since(start))
+	return report, readErr
+}
+
+// parseBulkLine parses a trimmed, non-empty line of a bulk ingest into a document with a trimmed
+// document ID.
+func parseBulkLine(line []byte) (model.Document, error) {
+	if line[0] != '{' {
+		return nil, fmt.Errorf("line is not a JSON object")
+	}
+	var doc model.Document
+	if err := json.Unmarshal(line, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	docIDValue, exists := doc["documentID"]
+	if !exists {
+		return nil, fmt.Errorf("document must have a 'documentID' field")
+	}
+	docID, ok := docIDValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("documentID must be a string")
+	}
+	if strings.TrimSpace(docID) == "" {
+		return nil, fmt.Errorf("documentID cannot be empty or whitespace-only")
+	}
+	doc["documentID"] = strings.TrimSpace(docID)
+	return doc, nil
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestEngine_BulkIngest(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+
+	var body strings.Builder
+	body.WriteString(`{"documentID": "1", "title": "Refreshed Catalog Entry"}` + "\n")
+	body.WriteString("\n")
+	body.WriteString(`{"documentID": "broken"` + "\n")
+	body.WriteString(`["not", "a", "document"]` + "\n")
+	body.WriteString(`{"title": "No ID"}` + "\n")
+	body.WriteString(`{"documentID": "  "}` + "\n")
+	for i := 0; i < 2*bulkIngestBatchSize+10; i++ {
+		fmt.Fprintf(&body, `{"documentID": "bulk-%d", "title": "Bulk Item %d"}`+"\n", i, i)
+	}
+	body.WriteString(`{"documentID": "bulk-0", "title": "Bulk Item Replaced"}`) // Last line without newline
+
+	report, err := engine.BulkIngest("test-batch-index", strings.NewReader(body.String()))
+	if err != nil {
+		t.Fatalf("Failed to bulk ingest: %v", err)
+	}
+	wantIndexed := 2*bulkIngestBatchSize + 12
+	if report.Lines != wantIndexed+4 || report.Indexed != wantIndexed || report.Failed != 4 || len(report.Errors) != 4 {
+		t.Errorf("Unexpected report counts: lines %d, indexed %d, failed %d, errors %d", report.Lines, report.Indexed, report.Failed, len(report.Errors))
+	}
+	wantLines := []int{3, 4, 5, 6}
+	for i, lineError := range report.Errors {
+		if lineError.Line != wantLines[i] {
+			t.Errorf("Expected error %d on line %d, got line %d (%s)", i, wantLines[i], lineError.Line, lineError.Error)
+		}
+	}
+
+	if got := indexAccessor.(*IndexInstance).DocumentStore.Len(); got != 2+2*bulkIngestBatchSize+10 {
+		t.Errorf("Expected %d documents, got %d", 2+2*bulkIngestBatchSize+10, got)
+	}
+	search := func(queryString string) int {
+		t.Helper()
+		result, err := indexAccessor.Search(services.SearchQuery{QueryString: queryString})
+		if err != nil {
+			t.Fatalf("Search %q failed: %v", queryString, err)
+		}
+		return result.Total
+	}
+	if search("old") != 0 || search("refreshed") != 1 {
+		t.Error("Expected the updated document to match its new words only")
+	}
+	if search("replaced") != 1 {
+		t.Error("Expected the last document of a duplicated ID to be indexed")
+	}
+	if _, found := indexAccessor.(*IndexInstance).DocumentStore.Lookup("bulk-2009"); !found {
+		t.Error("Expected documents of the last, partial batch to be indexed")
+	}
+
+	reloaded := NewEngine(engine.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	if instance, err := reloaded.GetIndex("test-batch-index"); err != nil || instance.(*IndexInstance).DocumentStore.Len() != 2+2*bulkIngestBatchSize+10 {
+		t.Errorf("Expected the ingested documents to be persisted (err: %v)", err)
+	}
+}
+
+// failingReader returns an error once its content is read
+type failingReader struct{ content io.Reader }
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestEngine_BulkIngestErrors(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+
+	if _, err := engine.BulkIngest("missing", strings.NewReader(`{"documentID": "1"}`)); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected index not found, got %v", err)
+	}
+
+	body := &failingReader{content: strings.NewReader(`{"documentID": "3", "title": "Received"}` + "\n" + `{"documentID": "4", "ti`)}
+	report, err := engine.BulkIngest("test-batch-index", body)
+	if !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected invalid input when the body cannot be read, got %v", err)
+	}
+	if report.Indexed != 1 || report.Failed != 0 {
+		t.Errorf("Expected the complete line to be indexed and the cut one ignored, got %+v", report)
+	}
+	if result, _ := indexAccessor.Search(services.SearchQuery{QueryString: "received"}); result.Total != 1 {
+		t.Error("Expected documents read before the error to be indexed")
+	}
+}
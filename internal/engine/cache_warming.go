@@ -0,0 +1,193 @@
+package engine
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/search"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SetPopularQuerySource sets where indexes with cache warming take their popular queries from,
+// typically the search analytics. Without a source, cache warming has nothing to re-execute.
+func (e *Engine) SetPopularQuerySource(source services.PopularQuerySource) {
+	e.popularMu.Lock()
+	defer e.popularMu.Unlock()
+	e.popularQueries = source
+}
+
+// popularQuerySource returns the source of popular queries, or nil if none was set.
+func (e *Engine) popularQuerySource() services.PopularQuerySource {
+	e.popularMu.RLock()
+	defer e.popularMu.RUnlock()
+	return e.popularQueries
+}
+
+// cacheWarmer re-executes the popular queries of an index after writes, so their caches are warm
+// when users send them again. A warmer is replaced when its settings change.
+type cacheWarmer struct {
+	settings config.CacheWarming
+	source   func() services.PopularQuerySource
+	searcher atomic.Pointer[search.Service] // Search service of the index, replaced when it is recreated
+
+	warmMu       sync.Mutex // Serializes warming passes between the loop and explicit warms
+	warmed       bool
+	warmedWrites uint64 // Write count of the index when it was last warmed
+
+	statsMu       sync.Mutex // Guards the stats, so they can be read during a pass
+	runs          int64
+	queriesWarmed int64
+	lastWarmAt    time.Time
+
+	stop chan struct{} // Closed to stop the warming loop
+}
+
+// syncCacheWarmer starts, reconfigures or stops the index's cache warmer to match its settings, and
+// hands it the search service to warm. It is called whenever the search service is created.
+func (i *IndexInstance) syncCacheWarmer(searchService *search.Service, source func() services.PopularQuerySource) {
+	i.warmerMu.Lock()
+	defer i.warmerMu.Unlock()
+
+	warmingSettings := i.settings.CacheWarming
+	if warmingSettings == nil {
+		i.closeCacheWarmerUnsafe()
+		return
+	}
+	if i.warmer != nil && i.warmer.settings == *warmingSettings {
+		i.warmer.searcher.Store(searchService)
+		return
+	}
+
+	i.closeCacheWarmerUnsafe()
+	i.warmer = &cacheWarmer{
+		settings: *warmingSettings,
+		source:   source,
+		stop:     make(chan struct{}),
+	}
+	i.warmer.searcher.Store(searchService)
+	go i.runCacheWarmer(i.warmer)
+}
+
+// runCacheWarmer checks the index for writes every warming interval and warms it after them.
+func (i *IndexInstance) runCacheWarmer(warmer *cacheWarmer) {
+	ticker := time.NewTicker(warmer.settings.Interval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-warmer.stop:
+			return
+		case <-ticker.C:
+			i.warm(warmer, false)
+		}
+	}
+}
+
+// warm re-executes the popular queries of the index, unless it was already warmed since its last
+// write and force is false. Queries are spaced to respect the warmer's QPS cap, and the pass ends
+// early when the warmer is stopped.
+func (i *IndexInstance) warm(warmer *cacheWarmer, force bool) {
+	warmer.warmMu.Lock()
+	defer warmer.warmMu.Unlock()
+
+	writes := i.writes.Load()
+	if !force && warmer.warmed && writes == warmer.warmedWrites {
+		return
+	}
+	source := warmer.source()
+	if source == nil {
+		return
+	}
+
+	indexName := i.settings.Name
+	popular := source.GetPopularSearches(indexName, warmer.settings.Window(), warmer.settings.Queries())
+	pause := time.Duration(float64(time.Second) / warmer.settings.QPS())
+	warmedQueries := 0
+	for n, query := range popular {
+		if n > 0 {
+			select {
+			case <-warmer.stop:
+				return
+			case <-time.After(pause):
+			}
+		}
+		if _, err := warmer.searcher.Load().Search(services.SearchQuery{QueryString: query.Query}); err != nil {
+			slog.Warn("cache warming query failed", "index", indexName, "query", query.Query, "error", err)
+			continue
+		}
+		warmedQueries++
+	}
+
+	warmer.warmed = true
+	warmer.warmedWrites = writes
+
+	warmer.statsMu.Lock()
+	warmer.runs++
+	warmer.queriesWarmed += int64(warmedQueries)
+	warmer.lastWarmAt = time.Now()
+	warmer.statsMu.Unlock()
+}
+
+// WarmCaches re-executes the index's popular queries right away, e.g. after a bulk import. It is a
+// no-op for indexes without cache warming.
+func (i *IndexInstance) WarmCaches() {
+	i.warmerMu.Lock()
+	warmer := i.warmer
+	i.warmerMu.Unlock()
+
+	if warmer != nil {
+		i.warm(warmer, true)
+	}
+}
+
+// CacheWarmingStats describes the index's cache warmer, or returns nil if it has none.
+func (i *IndexInstance) CacheWarmingStats() *model.CacheWarmingStats {
+	i.warmerMu.Lock()
+	warmer := i.warmer
+	i.warmerMu.Unlock()
+
+	if warmer == nil {
+		return nil
+	}
+
+	warmer.statsMu.Lock()
+	defer warmer.statsMu.Unlock()
+
+	stats := &model.CacheWarmingStats{
+		IntervalMs:    warmer.settings.Interval().Milliseconds(),
+		TopQueries:    warmer.settings.Queries(),
+		MaxQPS:        warmer.settings.QPS(),
+		Runs:          warmer.runs,
+		QueriesWarmed: warmer.queriesWarmed,
+	}
+	if !warmer.lastWarmAt.IsZero() {
+		lastWarmAt := warmer.lastWarmAt
+		stats.LastWarmAt = &lastWarmAt
+	}
+	return stats
+}
+
+// recordWrite counts a write to the index, so the cache warmer warms it again.
+func (i *IndexInstance) recordWrite() {
+	i.writes.Add(1)
+}
+
+// closeCacheWarmer stops the index's cache warmer, e.g. when the index is deleted.
+func (i *IndexInstance) closeCacheWarmer() {
+	i.warmerMu.Lock()
+	defer i.warmerMu.Unlock()
+	i.closeCacheWarmerUnsafe()
+}
+
+// closeCacheWarmerUnsafe stops the warming loop without waiting for a pass in progress, which ends
+// before its next query. The caller must hold i.warmerMu.
+func (i *IndexInstance) closeCacheWarmerUnsafe() {
+	if i.warmer == nil {
+		return
+	}
+	close(i.warmer.stop)
+	i.warmer = nil
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// fakePopularQueries returns fixed popular queries and records the indexes they were requested for.
+type fakePopularQueries struct {
+	mu       sync.Mutex
+	queries  []model.PopularSearch
+	requests []string
+}
+
+func (f *fakePopularQueries) GetPopularSearches(indexName string, _ time.Duration, limit int) []model.PopularSearch {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, indexName)
+	if limit < len(f.queries) {
+		return f.queries[:limit]
+	}
+	return f.queries
+}
+
+func TestEngine_CacheWarming(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+	source := &fakePopularQueries{queries: []model.PopularSearch{
+		{Query: "catalog", SearchCount: 12},
+		{Query: "discontinued", SearchCount: 7},
+		{Query: "product", SearchCount: 3},
+	}}
+	engine.SetPopularQuerySource(source)
+
+	if stats := instance.CacheWarmingStats(); stats != nil {
+		t.Fatalf("Expected no cache warming stats before it is enabled, got %+v", stats)
+	}
+
+	settings := instance.Settings()
+	settings.CacheWarming = &config.CacheWarming{TopQueries: 2, IntervalMs: 10, MaxQPS: 1000}
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	waitForRuns := func(runs int64) *model.CacheWarmingStats {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			stats := instance.CacheWarmingStats()
+			if stats.Runs >= runs {
+				return stats
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d warming runs, got %+v", runs, stats)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// The first pass warms the index right away, re-executing the top queries only
+	stats := waitForRuns(1)
+	if stats.QueriesWarmed != 2 || stats.TopQueries != 2 || stats.LastWarmAt == nil {
+		t.Errorf("Expected the 2 top queries to be warmed, got %+v", stats)
+	}
+
+	// Without writes, the index is not warmed again
+	time.Sleep(50 * time.Millisecond)
+	if stats := instance.CacheWarmingStats(); stats.Runs != 1 {
+		t.Errorf("Expected no warming without writes, got %d runs", stats.Runs)
+	}
+
+	if err := indexAccessor.DeleteDocument("2"); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+	if stats := waitForRuns(2); stats.QueriesWarmed != 4 {
+		t.Errorf("Expected the top queries to be warmed again after a write, got %+v", stats)
+	}
+
+	source.mu.Lock()
+	for _, indexName := range source.requests {
+		if indexName != "test-batch-index" {
+			t.Errorf("Expected popular queries of test-batch-index, got a request for %q", indexName)
+		}
+	}
+	source.mu.Unlock()
+
+	// Disabling cache warming stops the warmer
+	settings.CacheWarming = nil
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if stats := instance.CacheWarmingStats(); stats != nil {
+		t.Errorf("Expected no cache warming stats once disabled, got %+v", stats)
+	}
+}
+
+func TestEngine_WarmCachesRespectsQPS(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+	engine.SetPopularQuerySource(&fakePopularQueries{queries: []model.PopularSearch{
+		{Query: "catalog"}, {Query: "discontinued"}, {Query: "product"},
+	}})
+
+	// A long interval keeps the warming loop out of the way, so warming is explicit
+	settings := instance.Settings()
+	settings.CacheWarming = &config.CacheWarming{IntervalMs: int(time.Hour.Milliseconds()), MaxQPS: 50}
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	start := time.Now()
+	instance.WarmCaches()
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected 3 queries at 50 QPS to take at least 40ms, took %v", elapsed)
+	}
+	if stats := instance.CacheWarmingStats(); stats.Runs != 1 || stats.QueriesWarmed != 3 {
+		t.Errorf("Expected one explicit pass warming 3 queries, got %+v", stats)
+	}
+}
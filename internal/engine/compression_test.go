@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestEngine_DocumentCompression(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+
+	settings := instance.Settings()
+	settings.DocumentCompression = &config.Compression{MinDocumentBytes: 1, CacheSize: 1}
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	stats := instance.DocumentStore.CompressionStats()
+	if stats == nil || stats.CompressedDocuments != 2 {
+		t.Fatalf("Expected the stored documents to be compressed, got %+v", stats)
+	}
+	result, err := indexAccessor.Search(services.SearchQuery{QueryString: "catalog", Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if result.Total != 1 || result.Hits[0].Document["title"] != "Old Catalog Entry" {
+		t.Errorf("Expected the compressed document to be found and returned, got %+v", result.Hits)
+	}
+
+	// Documents survive a restart compressed
+	if err := engine.PersistIndexData("test-batch-index"); err != nil {
+		t.Fatalf("Failed to persist index: %v", err)
+	}
+	reloaded := NewEngine(engine.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	reloadedAccessor, err := reloaded.GetIndex("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to get reloaded index: %v", err)
+	}
+	if stats := reloadedAccessor.(*IndexInstance).DocumentStore.CompressionStats(); stats == nil || stats.CompressedDocuments != 2 {
+		t.Errorf("Expected the reloaded documents to be compressed, got %+v", stats)
+	}
+	if total := searchTotal(t, reloadedAccessor, "catalog"); total != 1 {
+		t.Errorf("Expected the reloaded index to find the document, got %d hits", total)
+	}
+
+	settings.DocumentCompression = nil
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if instance.DocumentStore.CompressionStats() != nil || instance.DocumentStore.Len() != 2 {
+		t.Error("Expected documents to be stored verbatim once compression is disabled")
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// ExportDocuments calls emit with the documents of an index in documentID order, starting after the
+// query's After cursor and skipping those that do not match its filters. Only the query's
+// retrievable fields, and documentID, are kept, as in MultiGetDocuments. With a limit, it stops
+// after that many documents and returns the documentID of the last one as the cursor of the next
+// page, or "" once no matching documents are left. Documents are read one at a time, so a slow
+// reader does not hold up writes; documents written during the export may or may not be in it.
+func (e *Engine) ExportDocuments(indexName string, query services.DocumentExportQuery, emit func(model.Document) error) (string, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return "", e.missingIndexError(indexName)
+	}
+
+	if query.Limit < 0 {
+		return "", errors.NewValidationError("limit", "cannot be negative")
+	}
+	if err := validateFields("retrievable_fields", query.RetrievableFields); err != nil {
+		return "", err
+	}
+	fields := query.RetrievableFields
+	if len(fields) == 0 {
+		fields = instance.Settings().DefaultRetrievableFields
+	}
+
+	exported := 0
+	last := ""
+	for _, documentID := range instance.DocumentStore.ExternalIDs() {
+		if query.After != "" && documentID <= query.After {
+			continue
+		}
+		doc, found := instance.DocumentStore.GetByExternalID(documentID)
+		if !found {
+			continue // Deleted since the IDs were listed
+		}
+		if query.Filters != nil && !instance.searcher.MatchesFilters(doc, *query.Filters, query.FilterLocale) {
+			continue
+		}
+		if query.Limit > 0 && exported == query.Limit {
+			return last, nil // A matching document is left for the next page
+		}
+		if err := emit(keepFields(doc, fields)); err != nil {
+			return "", err
+		}
+		exported++
+		last = documentID
+	}
+	return "", nil
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestExportDocuments(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	const indexName = "test-export-index"
+	if err := engine.CreateIndex(config.IndexSettings{
+		Name:             indexName,
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"category"},
+	}); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, _ := engine.GetIndex(indexName)
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "c", "title": "Gamma", "category": "books"},
+		{"documentID": "a", "title": "Alpha", "category": "books"},
+		{"documentID": "d", "title": "Delta", "category": "music"},
+		{"documentID": "b", "title": "Beta", "category": "books"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	export := func(query services.DocumentExportQuery) ([]model.Document, string) {
+		t.Helper()
+		var docs []model.Document
+		cursor, err := engine.ExportDocuments(indexName, query, func(doc model.Document) error {
+			docs = append(docs, doc)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ExportDocuments(%+v) error = %v", query, err)
+		}
+		return docs, cursor
+	}
+	ids := func(docs []model.Document) []string {
+		ids := make([]string, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc["documentID"].(string)
+		}
+		return ids
+	}
+
+	docs, cursor := export(services.DocumentExportQuery{})
+	if got := ids(docs); !reflect.DeepEqual(got, []string{"a", "b", "c", "d"}) || cursor != "" {
+		t.Errorf("Full export = %v with cursor %q, want [a b c d] without cursor", got, cursor)
+	}
+
+	books := &services.Filters{Filters: []services.FilterCondition{{Field: "category", Value: "books"}}}
+	docs, cursor = export(services.DocumentExportQuery{Filters: books, RetrievableFields: []string{"title"}, Limit: 2})
+	want := []model.Document{{"documentID": "a", "title": "Alpha"}, {"documentID": "b", "title": "Beta"}}
+	if !reflect.DeepEqual(docs, want) || cursor != "b" {
+		t.Errorf("First page = %v with cursor %q, want %v with cursor b", docs, cursor, want)
+	}
+	docs, cursor = export(services.DocumentExportQuery{Filters: books, After: cursor, Limit: 2})
+	if got := ids(docs); !reflect.DeepEqual(got, []string{"c"}) || cursor != "" {
+		t.Errorf("Last page = %v with cursor %q, want [c] without cursor", got, cursor)
+	}
+
+	noop := func(model.Document) error { return nil }
+	if _, err := engine.ExportDocuments("missing-index", services.DocumentExportQuery{}, noop); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("ExportDocuments() on missing index error = %v, want ErrIndexNotFound", err)
+	}
+	if _, err := engine.ExportDocuments(indexName, services.DocumentExportQuery{Limit: -1}, noop); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("ExportDocuments() with negative limit error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// documentIDNamespace is the namespace of the document IDs derived from idempotency keys.
+var documentIDNamespace = uuid.MustParse("6f1d9a52-3c4e-4b8a-9f27-d05e8c1b7a34")
+
+// GenerateDocumentIDs sets a generated UUID as the documentID of the documents without one, when the
+// index has generate_document_ids enabled, and returns the IDs by position of the document. Without
+// the setting nothing is generated and nil is returned. With an idempotency key, the IDs are derived
+// from the key, the index and the position, so a retried request is assigned the same IDs.
+func (e *Engine) GenerateDocumentIDs(indexName, idempotencyKey string, docs []model.Document) (map[int]string, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	generate := exists && instance.settings.GenerateDocumentIDs
+	e.mu.RUnlock()
+	if !exists {
+		return nil, e.missingIndexError(indexName)
+	}
+	if !generate {
+		return nil, nil
+	}
+
+	var generated map[int]string
+	for i, doc := range docs {
+		if id, found := doc["documentID"]; found && id != nil {
+			continue
+		}
+		id := uuid.New()
+		if idempotencyKey != "" {
+			id = uuid.NewSHA1(documentIDNamespace, []byte(indexName+"\x00"+idempotencyKey+"\x00"+strconv.Itoa(i)))
+		}
+		if generated == nil {
+			generated = make(map[int]string)
+		}
+		generated[i] = id.String()
+		doc["documentID"] = generated[i]
+	}
+	return generated, nil
+}
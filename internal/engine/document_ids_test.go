@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestEngine_GenerateDocumentIDs(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	newDocs := func() []model.Document {
+		return []model.Document{{"title": "Untitled"}, {"documentID": "kept", "title": "Kept"}, {"documentID": nil, "title": "Null"}}
+	}
+
+	docs := newDocs()
+	if generated, err := engine.GenerateDocumentIDs("test-batch-index", "", docs); err != nil || generated != nil {
+		t.Fatalf("Expected no IDs without generate_document_ids, got %v (err=%v)", generated, err)
+	}
+	if _, found := docs[0]["documentID"]; found {
+		t.Errorf("Expected the documents to be left unchanged, got %v", docs[0])
+	}
+
+	engine.indexes["test-batch-index"].settings.GenerateDocumentIDs = true
+	generated, err := engine.GenerateDocumentIDs("test-batch-index", "", docs)
+	if err != nil {
+		t.Fatalf("GenerateDocumentIDs() error = %v", err)
+	}
+	if len(generated) != 2 || generated[0] == "" || generated[2] == "" || generated[0] == generated[2] {
+		t.Fatalf("Expected distinct IDs for positions 0 and 2, got %v", generated)
+	}
+	if docs[0]["documentID"] != generated[0] || docs[1]["documentID"] != "kept" || docs[2]["documentID"] != generated[2] {
+		t.Errorf("Expected the generated IDs to be set on the documents without one, got %v", docs)
+	}
+
+	// With an idempotency key, a retry is assigned the same IDs
+	first, _ := engine.GenerateDocumentIDs("test-batch-index", "import-1", newDocs())
+	retry, _ := engine.GenerateDocumentIDs("test-batch-index", "import-1", newDocs())
+	other, _ := engine.GenerateDocumentIDs("test-batch-index", "import-2", newDocs())
+	if first[0] != retry[0] || first[2] != retry[2] || first[0] == other[0] {
+		t.Errorf("Expected IDs derived from the idempotency key, got %v, %v and %v", first, retry, other)
+	}
+
+	if _, err := engine.GenerateDocumentIDs("missing-index", "", newDocs()); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected ErrIndexNotFound, got %v", err)
+	}
+}
@@ -1,12 +1,19 @@
 package engine
 
 import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/auth"
 	"github.com/gcbaptista/go-search-engine/internal/errors"
 	"github.com/gcbaptista/go-search-engine/internal/jobs"
+	"github.com/gcbaptista/go-search-engine/internal/rules"
 	"github.com/gcbaptista/go-search-engine/model"
 	"github.com/gcbaptista/go-search-engine/services"
 )
@@ -14,14 +21,110 @@ import (
 // Engine manages multiple search indexes.
 // It implements the services.IndexManager interface.
 type Engine struct {
-	mu         sync.RWMutex
-	indexes    map[string]*IndexInstance
-	dataDir    string
-	jobManager *jobs.Manager
+	mu             sync.RWMutex
+	indexes        map[string]*IndexInstance
+	dataDir        string
+	jobManager     services.JobRunner
+	rewriters      []services.QueryRewriter   // Query rewriters applied to every index
+	scorers        map[string]services.Scorer // Custom scorers selectable through IndexSettings.Scorer
+	slowQuery      time.Duration              // Searches taking at least this long are logged as slow; 0 logs none
+	batchesMu      sync.Mutex
+	batches        map[string]*writeBatch // Open write batches by batch ID
+	ruleStore      services.RuleStore     // Merchandising rules of every index
+	documents      services.DocumentStore // Persisted documents of every index
+	shadowsMu      sync.RWMutex
+	shadows        map[string]*shadowState // Shadow mode by live index name
+	shadowSlots    chan struct{}           // Bounds the shadow searches running at once
+	popularMu      sync.RWMutex
+	popularQueries services.PopularQuerySource // Source of the queries re-executed by cache warming
+	exportsMu      sync.Mutex
+	exports        map[string]*searchExport // Search export files by job ID
+	keyStore       *auth.FileKeyStore       // Scoped API keys
+	authMu         sync.RWMutex
+	adminKey       string // Allowed every request; API keys are only enforced once it is set
+
+	renameGracePeriod time.Duration                    // How long the old name of a renamed index keeps resolving
+	renameAliases     map[string]renameAlias           // Old names of recently renamed indexes, guarded by mu
+	aliases           map[string]string                // Index names by alias name, guarded by mu
+	relevanceTests    map[string][]model.RelevanceTest // Relevance tests by index name, guarded by mu
+
+	openMu        sync.Mutex                       // Serializes opening and closing indexes
+	closedMu      sync.RWMutex                     // Guards closedIndexes; changed while holding mu as well
+	closedIndexes map[string]*config.IndexSettings // Settings of the closed indexes, whose data is only on disk
+
+	readOnly bool // Set by NewReadOnlyEngine: nothing is written to dataDir and no jobs run
 }
 
 // NewEngine creates a new search engine orchestrator.
 func NewEngine(dataDir string) *Engine {
+	eng, _ := newEngine(dataDir, false, services.Extensions{}) // Only extensions fail to register
+	return eng
+}
+
+// newEngine creates an engine with its extensions and loads the indexes of its data directory.
+// Read-only engines do not start the built-in job manager.
+func newEngine(dataDir string, readOnly bool, extensions services.Extensions) (*Engine, error) {
+	eng := &Engine{
+		indexes:     make(map[string]*IndexInstance),
+		dataDir:     dataDir,
+		jobManager:  extensions.JobRunner,
+		ruleStore:   extensions.RuleStore,
+		documents:   extensions.DocumentStore,
+		scorers:     make(map[string]services.Scorer),
+		batches:     make(map[string]*writeBatch),
+		shadows:     make(map[string]*shadowState),
+		shadowSlots: make(chan struct{}, maxConcurrentShadowSearches),
+		exports:     make(map[string]*searchExport),
+
+		renameGracePeriod: defaultRenameGracePeriod,
+		renameAliases:     make(map[string]renameAlias),
+		aliases:           make(map[string]string),
+		relevanceTests:    make(map[string][]model.RelevanceTest),
+		closedIndexes:     make(map[string]*config.IndexSettings),
+		readOnly:          readOnly,
+	}
+	// Extensions are registered before indexes are loaded, so every index starts with them
+	for _, rewriter := range extensions.QueryRewriters {
+		if err := eng.RegisterQueryRewriter(rewriter); err != nil {
+			return nil, err
+		}
+	}
+	for _, scorer := range extensions.Scorers {
+		if err := eng.RegisterScorer(scorer); err != nil {
+			return nil, err
+		}
+	}
+	eng.popularQueries = extensions.PopularQuerySource
+
+	if eng.ruleStore == nil {
+		ruleStore := rules.NewFileRuleStore(filepath.Join(dataDir, rulesFile))
+		if err := ruleStore.Load(); err != nil {
+			slog.Warn("failed to load rules, starting without rules", "data_dir", dataDir, "error", err)
+		}
+		eng.ruleStore = ruleStore
+	}
+	if eng.documents == nil {
+		eng.documents = fileDocumentStore{dataDir: dataDir}
+	}
+	eng.keyStore = auth.NewFileKeyStore(filepath.Join(dataDir, apiKeysFile))
+	if err := eng.keyStore.Load(); err != nil {
+		slog.Warn("failed to load API keys, starting without API keys", "data_dir", dataDir, "error", err)
+	}
+	if eng.jobManager == nil {
+		jobManager := jobs.NewManager(defaultJobWorkers())
+		if !readOnly {
+			jobManager.Start()
+		}
+		eng.jobManager = jobManager
+	}
+	eng.loadIndexesFromDisk()
+	eng.loadAliases()
+	eng.loadRelevanceTests()
+	return eng, nil
+}
+
+// defaultJobWorkers returns the number of workers of the built-in job manager.
+func defaultJobWorkers() int {
 	// Calculate optimal worker count based on CPU cores
 	// Use 2x CPU cores for I/O bound operations, with minimum of 4 and maximum of 16
 	maxWorkers := runtime.NumCPU() * 2
@@ -31,50 +134,52 @@ func NewEngine(dataDir string) *Engine {
 	if maxWorkers > 16 {
 		maxWorkers = 16
 	}
-
-	eng := &Engine{
-		indexes:    make(map[string]*IndexInstance),
-		dataDir:    dataDir,
-		jobManager: jobs.NewManager(maxWorkers),
-	}
-	eng.jobManager.Start()
-	eng.loadIndexesFromDisk()
-	return eng
+	return maxWorkers
 }
 
-// GetIndex retrieves an index by its name.
+// GetIndex retrieves an index by its name. Closed indexes must be opened first.
 func (e *Engine) GetIndex(name string) (services.IndexAccessor, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	instance, exists := e.indexes[name]
 	if !exists {
-		return nil, errors.NewIndexNotFoundError(name)
+		return nil, e.missingIndexError(name)
 	}
 	return instance, nil
 }
 
-// GetIndexSettings retrieves the settings for a specific index.
+// GetIndexSettings retrieves the settings for a specific index, open or closed.
 func (e *Engine) GetIndexSettings(name string) (config.IndexSettings, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	instance, exists := e.indexes[name]
 	if !exists {
+		e.closedMu.RLock()
+		defer e.closedMu.RUnlock()
+		if settings, closed := e.closedIndexes[name]; closed {
+			return *settings, nil
+		}
 		return config.IndexSettings{}, errors.NewIndexNotFoundError(name)
 	}
 	return *instance.settings, nil // Return a copy
 }
 
-// ListIndexes returns a list of all index names.
+// ListIndexes returns a list of all index names, including closed indexes.
 func (e *Engine) ListIndexes() []string {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
+	e.closedMu.RLock()
+	defer e.closedMu.RUnlock()
 
-	names := make([]string, 0, len(e.indexes))
+	names := make([]string, 0, len(e.indexes)+len(e.closedIndexes))
 	for name := range e.indexes {
 		names = append(names, name)
 	}
+	for name := range e.closedIndexes {
+		names = append(names, name)
+	}
 	return names
 }
 
@@ -88,14 +193,70 @@ func (e *Engine) ListJobs(indexName string, status *model.JobStatus) []*model.Jo
 	return e.jobManager.ListJobs(indexName, status)
 }
 
-// GetJobMetrics returns job performance metrics.
+// ListAllJobs returns the jobs of every index selected by the filter, newest first. With a custom
+// JobRunner that cannot list them all, the jobs of each index are listed.
+func (e *Engine) ListAllJobs(filter model.JobFilter) []*model.Job {
+	if controller, ok := e.jobManager.(services.JobController); ok {
+		return controller.ListAllJobs(filter)
+	}
+
+	indexNames := e.ListIndexes()
+	if filter.IndexName != "" {
+		indexNames = []string{filter.IndexName}
+	}
+	result := []*model.Job{}
+	for _, name := range indexNames {
+		for _, job := range e.jobManager.ListJobs(name, nil) {
+			if filter.Matches(job) {
+				result = append(result, job)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+// CancelJob cancels a pending job, or asks a running job to stop. Custom JobRunners may not
+// support cancelling jobs.
+func (e *Engine) CancelJob(jobID string) (*model.Job, error) {
+	if controller, ok := e.jobManager.(services.JobController); ok {
+		return controller.CancelJob(jobID)
+	}
+	if _, err := e.jobManager.GetJob(jobID); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("cancelling jobs is not supported by the job runner")
+}
+
+// CleanupJobs removes the records of jobs that finished more than olderThan ago and returns how
+// many were removed, or 0 with a custom JobRunner that does not support it.
+func (e *Engine) CleanupJobs(olderThan time.Duration) int {
+	if controller, ok := e.jobManager.(services.JobController); ok {
+		return controller.CleanupJobs(olderThan)
+	}
+	return 0
+}
+
+// SetJobRetention sets how long the built-in job manager keeps the records of finished jobs; 0
+// keeps them until they are cleaned up explicitly. Custom JobRunners manage their own records.
+func (e *Engine) SetJobRetention(retention time.Duration) {
+	if manager, ok := e.jobManager.(*jobs.Manager); ok {
+		manager.SetRetention(retention)
+	}
+}
+
+// GetJobMetrics returns job performance metrics. Only the built-in job manager records them;
+// engines with a custom JobRunner report empty metrics.
 func (e *Engine) GetJobMetrics() jobs.JobMetricsData {
-	return e.jobManager.GetMetrics()
+	if manager, ok := e.jobManager.(*jobs.Manager); ok {
+		return manager.GetMetrics()
+	}
+	return jobs.JobMetricsData{}
 }
 
 // GetJobSuccessRate returns the success rate of jobs.
 func (e *Engine) GetJobSuccessRate() float64 {
-	metrics := e.jobManager.GetMetrics()
+	metrics := e.GetJobMetrics()
 	totalCompleted := metrics.JobsCompleted + metrics.JobsFailed
 	if totalCompleted == 0 {
 		return 1.0 // No jobs yet, assume 100% success
@@ -103,7 +264,10 @@ func (e *Engine) GetJobSuccessRate() float64 {
 	return float64(metrics.JobsCompleted) / float64(totalCompleted)
 }
 
-// GetCurrentWorkload returns the current number of running jobs.
+// GetCurrentWorkload returns the current number of running jobs, or 0 with a custom JobRunner.
 func (e *Engine) GetCurrentWorkload() int64 {
-	return e.jobManager.GetCurrentWorkload()
+	if manager, ok := e.jobManager.(*jobs.Manager); ok {
+		return manager.GetCurrentWorkload()
+	}
+	return 0
 }
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/search"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// RegisterQueryRewriter adds a query rewriter applied to searches on every index.
+// Rewriters run in registration order.
+func (e *Engine) RegisterQueryRewriter(rewriter services.QueryRewriter) error {
+	if rewriter == nil {
+		return fmt.Errorf("query rewriter cannot be nil")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, existing := range e.rewriters {
+		if existing.Name() == rewriter.Name() {
+			return fmt.Errorf("query rewriter '%s' is already registered", rewriter.Name())
+		}
+	}
+	e.rewriters = append(e.rewriters, rewriter)
+
+	for _, instance := range e.indexes {
+		if instance.searcher != nil {
+			instance.searcher.SetQueryRewriters(e.rewriters)
+		}
+	}
+	return nil
+}
+
+// RegisterScorer makes a scorer available to indexes whose settings select it by name.
+// Indexes that already reference the scorer start using it immediately.
+func (e *Engine) RegisterScorer(scorer services.Scorer) error {
+	if scorer == nil {
+		return fmt.Errorf("scorer cannot be nil")
+	}
+	if scorer.Name() == "" {
+		return fmt.Errorf("scorer name cannot be empty")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.scorers[scorer.Name()]; exists {
+		return fmt.Errorf("scorer '%s' is already registered", scorer.Name())
+	}
+	e.scorers[scorer.Name()] = scorer
+
+	for _, instance := range e.indexes {
+		if instance.searcher != nil && instance.settings.Scorer == scorer.Name() {
+			instance.searcher.SetScorer(scorer)
+		}
+	}
+	return nil
+}
+
+// SetSlowQueryThreshold makes searches on every index that take at least threshold log a warning
+// with their query, IDs and timing. 0 disables slow query logging.
+func (e *Engine) SetSlowQueryThreshold(threshold time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.slowQuery = threshold
+	for _, instance := range e.indexes {
+		if instance.searcher != nil {
+			instance.searcher.SetSlowQueryThreshold(threshold)
+		}
+	}
+}
+
+// newSearchServiceUnsafe creates the search service for an index instance and applies
+// the engine's registered extensions. The caller must hold e.mu.
+func (e *Engine) newSearchServiceUnsafe(instance *IndexInstance) (*search.Service, error) {
+	if instance.indexer != nil {
+		instance.indexer.SetDocumentCompression(instance.settings.DocumentCompression)
+		instance.indexer.SetSortableFields(instance.settings.SortableFields)
+	}
+	instance.syncSegmentStorage()
+	invertedIndex, documentStore := instance.syncReadReplica()
+	searchService, err := search.NewService(invertedIndex, documentStore, instance.settings)
+	if err != nil {
+		return nil, err
+	}
+	searchService.SetQueryRewriters(e.rewriters)
+	searchService.SetRuleStore(e.ruleStore)
+	searchService.SetSlowQueryThreshold(e.slowQuery)
+	instance.syncCacheWarmer(searchService, e.popularQuerySource)
+
+	if scorerName := instance.settings.Scorer; scorerName != "" {
+		if scorer, exists := e.scorers[scorerName]; exists {
+			searchService.SetScorer(scorer)
+		} else {
+			slog.Warn("scorer is not registered, using default scoring", "index", instance.settings.Name, "scorer", scorerName)
+		}
+	}
+	return searchService, nil
+}
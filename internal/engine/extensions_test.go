@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/rewrite"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestEngine_RegisterQueryRewriter(t *testing.T) {
+	testDir := createTestDir(t)
+	defer func() {
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Logf("Failed to remove test directory: %v", err)
+		}
+	}()
+
+	engine := NewEngine(testDir)
+	defer engine.jobManager.Stop()
+
+	settings := config.IndexSettings{
+		Name:                 "test-rewriter-index",
+		SearchableFields:     []string{"title"},
+		MinWordSizeFor1Typo:  4,
+		MinWordSizeFor2Typos: 8,
+	}
+	if err := engine.CreateIndex(settings); err != nil {
+		t.Fatalf("Failed to create test index: %v", err)
+	}
+
+	indexAccessor, err := engine.GetIndex("test-rewriter-index")
+	if err != nil {
+		t.Fatalf("Failed to get test index: %v", err)
+	}
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Television Classics"},
+	}); err != nil {
+		t.Fatalf("Failed to add test documents: %v", err)
+	}
+
+	query := services.SearchQuery{QueryString: "tv", Page: 1, PageSize: 10}
+	result, err := indexAccessor.Search(query)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if result.Total != 0 {
+		t.Fatalf("Expected no hits before registering the rewriter, got %d", result.Total)
+	}
+
+	// Registering applies to existing indexes
+	if err := engine.RegisterQueryRewriter(rewrite.NewSynonymRewriter(map[string]string{"tv": "television"})); err != nil {
+		t.Fatalf("Failed to register rewriter: %v", err)
+	}
+	if err := engine.RegisterQueryRewriter(rewrite.NewSynonymRewriter(nil)); err == nil {
+		t.Error("Expected an error when registering a rewriter with a duplicate name")
+	}
+
+	result, err = indexAccessor.Search(query)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if result.Total != 1 {
+		t.Fatalf("Expected 1 hit after registering the rewriter, got %d", result.Total)
+	}
+}
+
+type popularityScorer struct{}
+
+func (popularityScorer) Name() string { return "popularity" }
+
+func (popularityScorer) Score(_ services.ScoringContext, candidate services.ScoringCandidate) float64 {
+	popularity, _ := candidate.Document["popularity"].(float64)
+	return candidate.BaseScore + popularity
+}
+
+func TestEngine_RegisterScorer(t *testing.T) {
+	testDir := createTestDir(t)
+	defer func() {
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Logf("Failed to remove test directory: %v", err)
+		}
+	}()
+
+	engine := NewEngine(testDir)
+	defer engine.jobManager.Stop()
+
+	settings := config.IndexSettings{
+		Name:                 "test-scorer-index",
+		SearchableFields:     []string{"title"},
+		MinWordSizeFor1Typo:  4,
+		MinWordSizeFor2Typos: 8,
+		Scorer:               "popularity",
+	}
+	if err := engine.CreateIndex(settings); err != nil {
+		t.Fatalf("Failed to create test index: %v", err)
+	}
+
+	indexAccessor, err := engine.GetIndex("test-scorer-index")
+	if err != nil {
+		t.Fatalf("Failed to get test index: %v", err)
+	}
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Matrix Matrix", "popularity": 1.0},
+		{"documentID": "2", "title": "Matrix", "popularity": 10.0},
+	}); err != nil {
+		t.Fatalf("Failed to add test documents: %v", err)
+	}
+
+	query := services.SearchQuery{QueryString: "matrix", Page: 1, PageSize: 10}
+
+	// The scorer is not registered yet, so default scoring applies
+	result, err := indexAccessor.Search(query)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Hits) != 2 {
+		t.Fatalf("Expected 2 hits, got %d", len(result.Hits))
+	}
+	for _, hit := range result.Hits {
+		if hit.Score != 1.0 {
+			t.Errorf("Expected default score 1.0 for document %v, got %v", hit.Document["documentID"], hit.Score)
+		}
+	}
+
+	if err := engine.RegisterScorer(popularityScorer{}); err != nil {
+		t.Fatalf("Failed to register scorer: %v", err)
+	}
+
+	result, err = indexAccessor.Search(query)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Hits) != 2 || result.Hits[0].Document["documentID"] != "2" {
+		t.Fatalf("Expected document 2 first with the popularity scorer, got %+v", result.Hits)
+	}
+	if result.Hits[0].Score != 11.0 {
+		t.Errorf("Expected custom score 11.0, got %v", result.Hits[0].Score)
+	}
+}
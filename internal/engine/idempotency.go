@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+const (
+	// idempotencyKeyTTL is how long a retried request with the same idempotency key returns the original job.
+	idempotencyKeyTTL = 24 * time.Hour
+	// maxIdempotencyKeys bounds the idempotency keys remembered per index; the oldest are forgotten first.
+	maxIdempotencyKeys = 10000
+)
+
+// idempotentOperation is the job started for the first request carrying an idempotency key.
+type idempotentOperation struct {
+	fingerprint string // Digest of the operation and its payload
+	jobID       string
+	createdAt   time.Time
+}
+
+// RunIdempotent starts an operation on an index at most once per idempotency key. The first request
+// runs start and records the job it returns; retries with the same key, operation and payload return
+// that job instead of applying the operation again, and report it as replayed. A key is released when
+// its job failed, so the retry runs the operation again. Reusing a key for a different request fails
+// with an IdempotencyKeyReusedError. Keys are kept in memory for 24 hours.
+func (e *Engine) RunIdempotent(indexName, key, operation string, payload interface{}, start func() (string, error)) (string, bool, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return "", false, e.missingIndexError(indexName)
+	}
+
+	fingerprint, err := requestFingerprint(operation, payload)
+	if err != nil {
+		return "", false, err
+	}
+
+	instance.idempotencyMu.Lock()
+	defer instance.idempotencyMu.Unlock()
+
+	now := time.Now()
+	if instance.idempotentOps == nil {
+		instance.idempotentOps = make(map[string]idempotentOperation)
+	}
+	if op, found := instance.idempotentOps[key]; found && now.Sub(op.createdAt) < idempotencyKeyTTL {
+		if op.fingerprint != fingerprint {
+			return "", false, errors.NewIdempotencyKeyReusedError(key, indexName)
+		}
+		if job, err := e.jobManager.GetJob(op.jobID); err != nil || job.Status != model.JobStatusFailed {
+			return op.jobID, true, nil
+		}
+	}
+
+	jobID, err := start()
+	if err != nil {
+		return "", false, err
+	}
+	instance.rememberIdempotencyKeyUnsafe(key, idempotentOperation{fingerprint: fingerprint, jobID: jobID, createdAt: now})
+	return jobID, false, nil
+}
+
+// rememberIdempotencyKeyUnsafe records the job started for a key, forgetting expired keys and, past
+// the limit, the oldest one. The caller must hold i.idempotencyMu.
+func (i *IndexInstance) rememberIdempotencyKeyUnsafe(key string, op idempotentOperation) {
+	oldestKey := ""
+	for k, existing := range i.idempotentOps {
+		if op.createdAt.Sub(existing.createdAt) >= idempotencyKeyTTL {
+			delete(i.idempotentOps, k)
+		} else if oldestKey == "" || existing.createdAt.Before(i.idempotentOps[oldestKey].createdAt) {
+			oldestKey = k
+		}
+	}
+	if _, replaced := i.idempotentOps[key]; !replaced && len(i.idempotentOps) >= maxIdempotencyKeys {
+		delete(i.idempotentOps, oldestKey)
+	}
+	i.idempotentOps[key] = op
+}
+
+// requestFingerprint digests an operation and its payload, so a reused key can be told apart from a retry.
+func requestFingerprint(operation string, payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s request: %w", operation, err)
+	}
+	sum := sha256.Sum256(append([]byte(operation+"\x00"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
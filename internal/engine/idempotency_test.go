@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestEngine_RunIdempotent(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	docs := []model.Document{{"documentID": "3", "title": "New Arrival"}}
+
+	starts := 0
+	addDocs := func() (string, error) {
+		starts++
+		return engine.AddDocumentsAsync("test-batch-index", docs)
+	}
+
+	jobID, replayed, err := engine.RunIdempotent("test-batch-index", "import-1", "add_documents", docs, addDocs)
+	if err != nil || replayed {
+		t.Fatalf("Expected the first request to start a job, got replayed=%v, err=%v", replayed, err)
+	}
+	waitForJob(t, engine, jobID)
+
+	retryJobID, replayed, err := engine.RunIdempotent("test-batch-index", "import-1", "add_documents", docs, addDocs)
+	if err != nil || !replayed || retryJobID != jobID {
+		t.Errorf("Expected the retry to replay job %s, got %s (replayed=%v, err=%v)", jobID, retryJobID, replayed, err)
+	}
+	if starts != 1 {
+		t.Errorf("Expected the operation to start once, started %d times", starts)
+	}
+
+	// The same key with another payload is rejected
+	otherDocs := []model.Document{{"documentID": "4", "title": "Other"}}
+	if _, _, err := engine.RunIdempotent("test-batch-index", "import-1", "add_documents", otherDocs, addDocs); !errors.Is(err, internalErrors.ErrIdempotencyKeyReused) {
+		t.Errorf("Expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	// A key whose job failed is released for the retry
+	deleteMissing := func() (string, error) {
+		starts++
+		return engine.DeleteDocumentAsync("test-batch-index", "missing")
+	}
+	jobID, _, err = engine.RunIdempotent("test-batch-index", "delete-1", "delete_document", "missing", deleteMissing)
+	if err != nil {
+		t.Fatalf("Failed to start delete: %v", err)
+	}
+	if job := waitForJob(t, engine, jobID); job.Status != model.JobStatusFailed {
+		t.Fatalf("Expected deleting a missing document to fail, got %s", job.Status)
+	}
+	retryJobID, replayed, err = engine.RunIdempotent("test-batch-index", "delete-1", "delete_document", "missing", deleteMissing)
+	if err != nil || replayed || retryJobID == jobID {
+		t.Errorf("Expected the retry of a failed job to start a new job, got %s (replayed=%v, err=%v)", retryJobID, replayed, err)
+	}
+	waitForJob(t, engine, retryJobID)
+
+	if _, _, err := engine.RunIdempotent("missing-index", "import-1", "add_documents", docs, addDocs); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected ErrIndexNotFound, got %v", err)
+	}
+}
@@ -1,27 +1,33 @@
 package engine
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/internal/errors"
-	"github.com/gcbaptista/go-search-engine/internal/search"
 )
 
 // CreateIndex creates a new index with the given settings and persists it.
 func (e *Engine) CreateIndex(settings config.IndexSettings) error {
+	if err := e.checkWritable("create index"); err != nil {
+		return err
+	}
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	if settings.Name == "" {
 		return fmt.Errorf("index name cannot be empty")
 	}
-	if _, exists := e.indexes[settings.Name]; exists {
+	if e.indexExistsUnsafe(settings.Name) {
 		return errors.NewIndexAlreadyExistsError(settings.Name)
 	}
+	if err := e.aliasConflictUnsafe(settings.Name); err != nil {
+		return err
+	}
 
 	// Create in-memory instance first
 	instance, err := NewIndexInstance(settings) // This initializes sub-components
@@ -30,33 +36,56 @@ func (e *Engine) CreateIndex(settings config.IndexSettings) error {
 	}
 
 	// Initialize the searcher (as NewIndexInstance doesn't do it anymore to avoid cyclic deps during basic init)
-	searchService, err := search.NewService(instance.InvertedIndex, instance.DocumentStore, instance.settings)
+	searchService, err := e.newSearchServiceUnsafe(instance)
 	if err != nil {
 		return fmt.Errorf("failed to create search service for new index '%s': %w", settings.Name, err)
 	}
 	instance.SetSearcher(searchService)
 
 	// Persist the initial state
-	if err := e.persistUpdatedIndexUnsafe(settings.Name, settings, instance); err != nil {
+	if err := e.persistUpdatedIndexUnsafe(context.Background(), settings.Name, settings, instance); err != nil {
 		return fmt.Errorf("failed to persist new index '%s': %w", settings.Name, err)
 	}
 
 	e.indexes[settings.Name] = instance
-	log.Printf("Index '%s' created and persisted.", settings.Name)
+	e.dropRenameAliasesUnsafe(settings.Name)
+	slog.Info("index created", "index", settings.Name)
 	return nil
 }
 
 // DeleteIndex deletes an index and its data from disk.
 func (e *Engine) DeleteIndex(name string) error {
+	if err := e.checkWritable("delete index"); err != nil {
+		return err
+	}
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if _, exists := e.indexes[name]; !exists {
+	if err := e.deleteIndexUnsafe(name); err != nil {
+		return err
+	}
+
+	slog.Info("index deleted", "index", name)
+	return nil
+}
+
+// deleteIndexUnsafe removes an index from memory and disk, with its rules, shadows, aliases and
+// relevance tests. The caller must hold the engine's write lock.
+func (e *Engine) deleteIndexUnsafe(name string) error {
+	instance, exists := e.indexes[name]
+	if !exists && !e.isClosed(name) {
 		return errors.NewIndexNotFoundError(name)
 	}
 
 	// Remove from memory
-	delete(e.indexes, name)
+	if exists {
+		delete(e.indexes, name)
+		instance.closeReadReplica()
+		instance.closeCacheWarmer()
+		instance.closeSafeMode()
+		instance.waitForSegmentMerges()
+	}
+	e.forgetClosedIndexUnsafe(name)
 
 	// Remove from disk
 	indexPath := filepath.Join(e.dataDir, name)
@@ -64,12 +93,24 @@ func (e *Engine) DeleteIndex(name string) error {
 		return fmt.Errorf("failed to remove index directory %s: %w", indexPath, err)
 	}
 
-	log.Printf("Index '%s' deleted successfully.", name)
+	if err := e.documents.DeleteDocuments(name); err != nil {
+		slog.Warn("failed to delete documents of index", "index", name, "error", err)
+	}
+	if err := e.ruleStore.DeleteIndexRules(name); err != nil {
+		slog.Warn("failed to delete rules of index", "index", name, "error", err)
+	}
+	e.disableShadowsOf(name)
+	e.dropRenameAliasesUnsafe(name)
+	e.dropAliasesOfUnsafe(name)
+	e.dropRelevanceTestsUnsafe(name)
 	return nil
 }
 
 // RenameIndex renames an index.
 func (e *Engine) RenameIndex(oldName, newName string) error {
+	if err := e.checkWritable("rename index"); err != nil {
+		return err
+	}
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -79,19 +120,23 @@ func (e *Engine) RenameIndex(oldName, newName string) error {
 
 	instance, exists := e.indexes[oldName]
 	if !exists {
-		return errors.NewIndexNotFoundError(oldName)
+		return e.missingIndexError(oldName)
 	}
 
-	if _, exists := e.indexes[newName]; exists {
+	if e.indexExistsUnsafe(newName) {
 		return errors.NewIndexAlreadyExistsError(newName)
 	}
+	if err := e.aliasConflictUnsafe(newName); err != nil {
+		return err
+	}
 
 	// Update the settings with the new name
 	newSettings := *instance.settings
 	newSettings.Name = newName
 
-	// Create new directory and persist with new name
-	if err := e.persistUpdatedIndexUnsafe(newName, newSettings, instance); err != nil {
+	// Create new directory and persist with new name; merges write to the old directory
+	instance.waitForSegmentMerges()
+	if err := e.persistUpdatedIndexUnsafe(context.Background(), newName, newSettings, instance); err != nil {
 		return fmt.Errorf("failed to persist renamed index: %w", err)
 	}
 
@@ -102,13 +147,24 @@ func (e *Engine) RenameIndex(oldName, newName string) error {
 	e.indexes[newName] = instance
 	delete(e.indexes, oldName)
 
+	if err := e.ruleStore.RenameIndexRules(oldName, newName); err != nil {
+		slog.Warn("failed to move rules of renamed index", "index", oldName, "new_index", newName, "error", err)
+	}
+	e.disableShadowsOf(oldName)
+	e.addRenameAliasUnsafe(oldName, newName)
+	e.retargetAliasesUnsafe(oldName, newName)
+	e.renameRelevanceTestsUnsafe(oldName, newName)
+
 	// Remove old directory
 	oldIndexPath := filepath.Join(e.dataDir, oldName)
 	if err := os.RemoveAll(oldIndexPath); err != nil {
-		log.Printf("Warning: Failed to remove old index directory %s: %v", oldIndexPath, err)
+		slog.Warn("failed to remove old index directory", "path", oldIndexPath, "error", err)
 		// Don't return error as the rename was successful
 	}
+	if err := e.documents.DeleteDocuments(oldName); err != nil {
+		slog.Warn("failed to delete documents stored under the old index name", "index", oldName, "error", err)
+	}
 
-	log.Printf("Index renamed from '%s' to '%s' successfully.", oldName, newName)
+	slog.Info("index renamed", "index", oldName, "new_index", newName)
 	return nil
 }
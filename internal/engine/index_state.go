@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// CloseIndex persists an index and unloads it from memory, so an index that is rarely used does
+// not hold memory. Its files stay on disk, it is still listed with its settings, and it stays
+// closed across restarts until it is opened again. Indexes with jobs or write batches in progress
+// cannot be closed, as those would fail once the index is unloaded. Closing a closed index does
+// nothing.
+func (e *Engine) CloseIndex(name string) error {
+	e.openMu.Lock()
+	defer e.openMu.Unlock()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	instance, exists := e.indexes[name]
+	if !exists {
+		if e.isClosed(name) {
+			return nil
+		}
+		return errors.NewIndexNotFoundError(name)
+	}
+	if err := e.checkIdleUnsafe(name); err != nil {
+		return err
+	}
+
+	// Read-only engines wrote nothing to the index, so it is only unloaded
+	if !e.readOnly {
+		if err := e.persistUpdatedIndexUnsafe(context.Background(), name, *instance.settings, instance); err != nil {
+			return fmt.Errorf("failed to persist index before closing it: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(e.dataDir, name, closedMarkerFile), nil, 0600); err != nil {
+			return fmt.Errorf("failed to mark index %s as closed: %w", name, err)
+		}
+	}
+
+	delete(e.indexes, name)
+	instance.closeReadReplica()
+	instance.closeCacheWarmer()
+	instance.closeSafeMode()
+	instance.waitForSegmentMerges()
+	e.disableShadowsOf(name)
+
+	settings := *instance.settings
+	e.closedMu.Lock()
+	e.closedIndexes[name] = &settings
+	e.closedMu.Unlock()
+	slog.Info("index closed", "index", name)
+	return nil
+}
+
+// checkIdleUnsafe returns an error if the index has jobs or open write batches that need it
+// loaded. The caller must hold e.mu.
+func (e *Engine) checkIdleUnsafe(name string) error {
+	jobs := 0
+	for _, status := range []model.JobStatus{model.JobStatusPending, model.JobStatusRunning} {
+		jobs += len(e.jobManager.ListJobs(name, &status))
+	}
+	if jobs > 0 {
+		return errors.NewIndexBusyError(name, fmt.Sprintf("%d job(s) in progress", jobs))
+	}
+
+	e.batchesMu.Lock()
+	defer e.batchesMu.Unlock()
+	e.removeExpiredBatchesUnsafe(time.Now())
+	batches := 0
+	for _, batch := range e.batches {
+		if batch.indexName == name {
+			batches++
+		}
+	}
+	if batches > 0 {
+		return errors.NewIndexBusyError(name, fmt.Sprintf("%d write batch(es) open", batches))
+	}
+	return nil
+}
+
+// OpenIndex loads a closed index back into memory. It reports false when the index was open
+// already. Other indexes keep serving requests while the index is read from disk.
+func (e *Engine) OpenIndex(name string) (bool, error) {
+	e.openMu.Lock()
+	defer e.openMu.Unlock()
+
+	if !e.isClosed(name) {
+		e.mu.RLock()
+		_, exists := e.indexes[name]
+		e.mu.RUnlock()
+		if !exists {
+			return false, errors.NewIndexNotFoundError(name)
+		}
+		return false, nil
+	}
+
+	start := time.Now()
+	instance, rebuilt, err := e.readIndex(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to open index '%s': %w", name, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.isClosed(name) {
+		// Deleted while it was read
+		return false, errors.NewIndexNotFoundError(name)
+	}
+	if err := e.addLoadedIndexUnsafe(instance, rebuilt); err != nil {
+		return false, fmt.Errorf("failed to open index '%s': %w", name, err)
+	}
+	if !e.readOnly {
+		if err := os.Remove(filepath.Join(e.dataDir, name, closedMarkerFile)); err != nil && !os.IsNotExist(err) {
+			slog.Warn("failed to remove closed marker of opened index", "index", name, "error", err)
+		}
+	}
+	e.closedMu.Lock()
+	delete(e.closedIndexes, name)
+	e.closedMu.Unlock()
+
+	slog.Info("index opened", "index", name, "duration", time.Since(start))
+	return true, nil
+}
+
+// IndexState returns whether an index is open or closed.
+func (e *Engine) IndexState(name string) (model.IndexState, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if _, exists := e.indexes[name]; exists {
+		return model.IndexStateOpen, nil
+	}
+	if e.isClosed(name) {
+		return model.IndexStateClosed, nil
+	}
+	return "", errors.NewIndexNotFoundError(name)
+}
+
+// isClosed reports whether the index is closed.
+func (e *Engine) isClosed(name string) bool {
+	e.closedMu.RLock()
+	defer e.closedMu.RUnlock()
+	_, closed := e.closedIndexes[name]
+	return closed
+}
+
+// forgetClosedIndexUnsafe drops a closed index that is deleted. The caller must hold e.mu.
+func (e *Engine) forgetClosedIndexUnsafe(name string) {
+	e.closedMu.Lock()
+	defer e.closedMu.Unlock()
+	delete(e.closedIndexes, name)
+}
+
+// indexExistsUnsafe reports whether an index exists, open or closed, e.g. before a name is taken.
+// The caller must hold e.mu.
+func (e *Engine) indexExistsUnsafe(name string) bool {
+	if _, exists := e.indexes[name]; exists {
+		return true
+	}
+	return e.isClosed(name)
+}
+
+// missingIndexError returns the error of an operation on an index that is not loaded: the index
+// is closed, or it does not exist.
+func (e *Engine) missingIndexError(name string) error {
+	if e.isClosed(name) {
+		return errors.NewIndexClosedError(name)
+	}
+	return errors.NewIndexNotFoundError(name)
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestCloseAndOpenIndex(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	const name = "test-batch-index"
+
+	batch, err := engine.OpenBatch(name)
+	if err != nil {
+		t.Fatalf("Failed to open batch: %v", err)
+	}
+	if err := engine.CloseIndex(name); !errors.Is(err, internalErrors.ErrIndexBusy) {
+		t.Errorf("Expected closing an index with an open batch to fail as busy, got %v", err)
+	}
+	if err := engine.AbortBatch(name, batch.ID); err != nil {
+		t.Fatalf("Failed to abort batch: %v", err)
+	}
+
+	if err := engine.CloseIndex(name); err != nil {
+		t.Fatalf("Failed to close index: %v", err)
+	}
+	if err := engine.CloseIndex(name); err != nil {
+		t.Errorf("Expected closing a closed index to do nothing, got %v", err)
+	}
+	if state, err := engine.IndexState(name); err != nil || state != model.IndexStateClosed {
+		t.Errorf("IndexState() = %q, %v; want closed", state, err)
+	}
+	if _, err := engine.GetIndex(name); !errors.Is(err, internalErrors.ErrIndexClosed) {
+		t.Errorf("Expected getting a closed index to fail as closed, got %v", err)
+	}
+	if _, err := engine.AddDocumentsAsync(name, []model.Document{{"documentID": "3", "title": "New"}}); !errors.Is(err, internalErrors.ErrIndexClosed) {
+		t.Errorf("Expected writing to a closed index to fail as closed, got %v", err)
+	}
+	if settings, err := engine.GetIndexSettings(name); err != nil || settings.Name != name {
+		t.Errorf("Expected the settings of a closed index, got %+v (err: %v)", settings, err)
+	}
+	if !slices.Contains(engine.ListIndexes(), name) {
+		t.Errorf("Expected closed index in %v", engine.ListIndexes())
+	}
+	if err := engine.CreateIndex(config.IndexSettings{Name: name, SearchableFields: []string{"title"}}); !errors.Is(err, internalErrors.ErrIndexAlreadyExists) {
+		t.Errorf("Expected creating an index named after a closed index to fail, got %v", err)
+	}
+
+	// The index stays closed across restarts
+	restarted := NewEngine(engine.dataDir)
+	t.Cleanup(restarted.jobManager.Stop)
+	if state, err := restarted.IndexState(name); err != nil || state != model.IndexStateClosed {
+		t.Fatalf("IndexState() after restart = %q, %v; want closed", state, err)
+	}
+
+	opened, err := restarted.OpenIndex(name)
+	if err != nil || !opened {
+		t.Fatalf("OpenIndex() = %v, %v; want opened", opened, err)
+	}
+	if opened, err := restarted.OpenIndex(name); err != nil || opened {
+		t.Errorf("OpenIndex() of an open index = %v, %v; want nothing to do", opened, err)
+	}
+	indexAccessor, err := restarted.GetIndex(name)
+	if err != nil {
+		t.Fatalf("Failed to get opened index: %v", err)
+	}
+	result, err := indexAccessor.Search(services.SearchQuery{QueryString: "catalog"})
+	if err != nil || result.Total != 1 {
+		t.Errorf("Expected the opened index to keep its documents, got %d hits (err: %v)", result.Total, err)
+	}
+
+	if _, err := restarted.OpenIndex("missing-index"); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected opening a missing index to fail as not found, got %v", err)
+	}
+
+	// Closed indexes can be deleted without opening them
+	if err := restarted.CloseIndex(name); err != nil {
+		t.Fatalf("Failed to close index: %v", err)
+	}
+	if err := restarted.DeleteIndex(name); err != nil {
+		t.Fatalf("Failed to delete closed index: %v", err)
+	}
+	if _, err := restarted.IndexState(name); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected the deleted index to be gone, got %v", err)
+	}
+}
@@ -3,6 +3,9 @@ package engine
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/index"
@@ -21,6 +24,24 @@ type IndexInstance struct {
 	DocumentStore *store.DocumentStore
 	indexer       *indexing.Service
 	searcher      *search.Service
+
+	idempotencyMu sync.Mutex
+	idempotentOps map[string]idempotentOperation // Jobs started for idempotency keys, by key
+
+	replicaMu sync.Mutex
+	replica   *readReplica // Copy of the index serving searches when settings.ReadReplica is set
+
+	writes   atomic.Uint64 // Document writes, so the cache warmer knows when to warm the index again
+	warmerMu sync.Mutex
+	warmer   *cacheWarmer // Re-executes popular queries after writes when settings.CacheWarming is set
+
+	segments segmentState // On-disk segments of the inverted index when settings.SegmentStorage is set
+
+	safeMode atomic.Pointer[safeModeMonitor] // Watches searches after the latest settings update when settings.SafeMode was set
+
+	updatedAt atomic.Int64 // Unix nanoseconds of the latest change written to disk, to list indexes by update time
+
+	readOnly bool // Loaded by a read-only engine, so documents cannot be written
 }
 
 // NewIndexInstance creates and initializes a new IndexInstance.
@@ -35,23 +56,33 @@ func NewIndexInstance(settings config.IndexSettings) (*IndexInstance, error) {
 		NextID:                 0, // Start internal IDs from 0
 	}
 
-	invIndex := &index.InvertedIndex{
-		Index:    make(map[string]index.PostingList),
-		Settings: &settings,
-	}
+	invIndex := index.NewInvertedIndex(&settings)
 
 	indexerService, err := indexing.NewService(invIndex, docStore)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create indexer service: %w", err)
 	}
 
-	return &IndexInstance{
+	instance := &IndexInstance{
 		settings:      &settings,
 		InvertedIndex: invIndex,
 		DocumentStore: docStore,
 		indexer:       indexerService,
 		searcher:      nil, // Initialize searcher later to avoid circular dependencies
-	}, nil
+	}
+	instance.markUpdated(time.Now())
+	return instance, nil
+}
+
+// UpdatedAt returns when the documents or settings of the index last changed. Loaded indexes
+// start with the time their files were last written.
+func (i *IndexInstance) UpdatedAt() time.Time {
+	return time.Unix(0, i.updatedAt.Load())
+}
+
+// markUpdated records when the index last changed.
+func (i *IndexInstance) markUpdated(at time.Time) {
+	i.updatedAt.Store(at.UnixNano())
 }
 
 // AddDocuments delegates to the underlying Indexer service.
@@ -60,6 +91,10 @@ func (i *IndexInstance) AddDocuments(docs []model.Document) error {
 	if i.indexer == nil {
 		return fmt.Errorf("indexer service not initialized for index '%s'", i.settings.Name)
 	}
+	if err := i.checkWritable("add documents"); err != nil {
+		return err
+	}
+	defer i.recordWrite()
 	return i.indexer.AddDocuments(docs)
 }
 
@@ -69,6 +104,10 @@ func (i *IndexInstance) DeleteAllDocuments() error {
 	if i.indexer == nil {
 		return fmt.Errorf("indexer service not initialized for index '%s'", i.settings.Name)
 	}
+	if err := i.checkWritable("delete documents"); err != nil {
+		return err
+	}
+	defer i.recordWrite()
 	return i.indexer.DeleteAllDocuments()
 }
 
@@ -78,16 +117,54 @@ func (i *IndexInstance) DeleteDocument(docID string) error {
 	if i.indexer == nil {
 		return fmt.Errorf("indexer service not initialized for index '%s'", i.settings.Name)
 	}
+	if err := i.checkWritable("delete document"); err != nil {
+		return err
+	}
+	defer i.recordWrite()
 	return i.indexer.DeleteDocument(docID)
 }
 
+// ApplyBatch delegates to the underlying Indexer service, applying deletes and upserts atomically.
+func (i *IndexInstance) ApplyBatch(upserts []model.Document, deletes []string) error {
+	if i.indexer == nil {
+		return fmt.Errorf("indexer service not initialized for index '%s'", i.settings.Name)
+	}
+	if err := i.checkWritable("apply batch"); err != nil {
+		return err
+	}
+	defer i.recordWrite()
+	return i.indexer.ApplyBatch(upserts, deletes)
+}
+
+// Rollback delegates to the underlying Indexer service, reversing the last n document operations.
+func (i *IndexInstance) Rollback(n int) error {
+	if i.indexer == nil {
+		return fmt.Errorf("indexer service not initialized for index '%s'", i.settings.Name)
+	}
+	if err := i.checkWritable("roll back index"); err != nil {
+		return err
+	}
+	defer i.recordWrite()
+	return i.indexer.Rollback(n)
+}
+
 // Search delegates to the underlying Searcher service.
 // This satisfies a part of the services.IndexAccessor interface.
 func (i *IndexInstance) Search(query services.SearchQuery) (services.SearchResult, error) {
 	if i.searcher == nil {
 		return services.SearchResult{}, fmt.Errorf("search service not initialized for index '%s'", i.settings.Name)
 	}
-	return i.searcher.Search(query)
+	result, err := i.searcher.Search(query)
+	i.recordSafeModeSearch(err != nil, err == nil && result.Total == 0)
+	return result, err
+}
+
+// searchAll returns every hit of the query on a single page, whatever the index's maximum page size.
+func (i *IndexInstance) searchAll(query services.SearchQuery) (services.SearchResult, error) {
+	if i.searcher == nil {
+		return services.SearchResult{}, fmt.Errorf("search service not initialized for index '%s'", i.settings.Name)
+	}
+	return i.searcher.SearchAll(query)
 }
 
 // MultiSearch delegates to the underlying Searcher service.
@@ -96,7 +173,41 @@ func (i *IndexInstance) MultiSearch(query services.MultiSearchQuery) (*services.
 	if i.searcher == nil {
 		return nil, fmt.Errorf("search service not initialized for index '%s'", i.settings.Name)
 	}
-	return i.searcher.MultiSearch(context.Background(), query)
+	// The searches join the trace of the request, but run to completion like single searches
+	ctx := context.Background()
+	if query.Context != nil {
+		ctx = context.WithoutCancel(query.Context)
+	}
+	result, err := i.searcher.MultiSearch(ctx, query)
+	if err != nil {
+		i.recordSafeModeSearch(true, false)
+		return result, err
+	}
+	for _, queryResult := range result.Results {
+		i.recordSafeModeSearch(false, queryResult.Total == 0)
+	}
+	return result, nil
+}
+
+// TypoStats returns how much typo expansion has contributed to this index's searches.
+func (i *IndexInstance) TypoStats() model.TypoStats {
+	if i.searcher == nil {
+		return model.TypoStats{}
+	}
+	return i.searcher.TypoStats()
+}
+
+// FieldStats returns the length statistics of the index's searchable fields.
+func (i *IndexInstance) FieldStats() map[string]model.FieldStats {
+	documentCount := i.DocumentStore.Len()
+	stats := make(map[string]model.FieldStats, len(i.settings.SearchableFields))
+	for _, field := range i.settings.SearchableFields {
+		stats[field] = model.FieldStats{
+			TotalLength:   i.InvertedIndex.FieldLength(field),
+			AverageLength: i.InvertedIndex.AverageFieldLength(field, documentCount),
+		}
+	}
+	return stats
 }
 
 // Settings returns the configuration settings for this index.
@@ -116,5 +227,9 @@ func (i *IndexInstance) BulkReindex(config indexing.BulkIndexingConfig) error {
 	if i.indexer == nil {
 		return fmt.Errorf("indexer service not initialized for index '%s'", i.settings.Name)
 	}
+	if err := i.checkWritable("reindex"); err != nil {
+		return err
+	}
+	defer i.recordWrite()
 	return i.indexer.BulkReindex(config)
 }
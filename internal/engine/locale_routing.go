@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/internal/tokenizer"
+)
+
+// languagePreference is a single entry of an Accept-Language style list.
+type languagePreference struct {
+	tag     string
+	quality float64
+}
+
+// parseAcceptLanguage parses an Accept-Language style value ("de-CH,de;q=0.9,en;q=0.5")
+// into language tags sorted by descending quality. Entries with q=0 are dropped.
+func parseAcceptLanguage(value string) []languagePreference {
+	var preferences []languagePreference
+	for _, part := range strings.Split(value, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		preferences = append(preferences, languagePreference{tag: strings.ReplaceAll(tag, "_", "-"), quality: quality})
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	return preferences
+}
+
+// ResolveLocaleIndex selects the index variant of baseName that best matches an
+// Accept-Language style list. Variants are indexes named "<baseName>_<suffix>" (or baseName
+// itself) whose Locale setting matches a requested language. A full locale match ("de-ch")
+// wins over a primary language match ("de"). When nothing matches, baseName is returned if it
+// exists, otherwise the first variant in alphabetical order.
+func (e *Engine) ResolveLocaleIndex(baseName string, acceptLanguage string) (string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var variants []string
+	for name := range e.indexes {
+		if name == baseName || strings.HasPrefix(name, baseName+"_") {
+			variants = append(variants, name)
+		}
+	}
+	if len(variants) == 0 {
+		return "", e.missingIndexError(baseName)
+	}
+	sort.Strings(variants)
+
+	for _, preference := range parseAcceptLanguage(acceptLanguage) {
+		// Exact locale match first, e.g. "pt-br" against an index with locale "pt-BR"
+		for _, name := range variants {
+			locale := strings.ReplaceAll(strings.ToLower(e.indexes[name].settings.Locale), "_", "-")
+			if locale != "" && locale == preference.tag {
+				return name, nil
+			}
+		}
+		// Then primary language match, e.g. "de-ch" against an index with locale "de"
+		language := tokenizer.PrimaryLanguage(preference.tag)
+		for _, name := range variants {
+			if tokenizer.PrimaryLanguage(e.indexes[name].settings.Locale) == language {
+				return name, nil
+			}
+		}
+	}
+
+	if _, exists := e.indexes[baseName]; exists {
+		return baseName, nil
+	}
+	return variants[0], nil
+}
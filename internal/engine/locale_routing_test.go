@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestEngine_ResolveLocaleIndex(t *testing.T) {
+	testDir := createTestDir(t)
+	defer func() {
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Logf("Failed to remove test directory: %v", err)
+		}
+	}()
+
+	engine := NewEngine(testDir)
+	defer engine.jobManager.Stop()
+
+	for _, settings := range []config.IndexSettings{
+		{Name: "movies_en", SearchableFields: []string{"title"}, Locale: "en"},
+		{Name: "movies_de", SearchableFields: []string{"title"}, Locale: "de"},
+		{Name: "movies_pt_br", SearchableFields: []string{"title"}, Locale: "pt-BR"},
+		{Name: "books", SearchableFields: []string{"title"}, Locale: "de"},
+	} {
+		settings.ApplyDefaults()
+		if err := engine.CreateIndex(settings); err != nil {
+			t.Fatalf("Failed to create index %s: %v", settings.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		baseName       string
+		acceptLanguage string
+		expected       string
+	}{
+		{"exact language", "movies", "de", "movies_de"},
+		{"region falls back to primary language", "movies", "de-CH", "movies_de"},
+		{"quality ordering", "movies", "fr;q=0.9,en;q=0.8,de;q=0.5", "movies_en"},
+		{"full locale match", "movies", "pt-BR", "movies_pt_br"},
+		{"q=0 excludes a language", "movies", "de;q=0,en;q=0.1", "movies_en"},
+		{"no match falls back to first variant", "movies", "ja", "movies_de"},
+		{"base index is not a variant of another base", "books", "en", "books"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := engine.ResolveLocaleIndex(tt.baseName, tt.acceptLanguage)
+			if err != nil {
+				t.Fatalf("ResolveLocaleIndex failed: %v", err)
+			}
+			if resolved != tt.expected {
+				t.Errorf("ResolveLocaleIndex(%q, %q) = %q, want %q", tt.baseName, tt.acceptLanguage, resolved, tt.expected)
+			}
+		})
+	}
+
+	if _, err := engine.ResolveLocaleIndex("unknown", "en"); err == nil {
+		t.Error("Expected an error for an unknown base index")
+	}
+}
+
+func TestEngine_LocaleAnalyzerEndToEnd(t *testing.T) {
+	testDir := createTestDir(t)
+	defer func() {
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Logf("Failed to remove test directory: %v", err)
+		}
+	}()
+
+	engine := NewEngine(testDir)
+	defer engine.jobManager.Stop()
+
+	settings := config.IndexSettings{Name: "movies_de", SearchableFields: []string{"title"}, Locale: "de"}
+	settings.ApplyDefaults()
+	if err := engine.CreateIndex(settings); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	indexAccessor, err := engine.GetIndex("movies_de")
+	if err != nil {
+		t.Fatalf("Failed to get index: %v", err)
+	}
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Die fabelhafte Welt der Amélie"},
+		{"documentID": "2", "title": "Das Mädchen Rosemarie"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	for _, queryString := range []string{"Mädchen", "maedchen", "amelie"} {
+		result, err := indexAccessor.Search(services.SearchQuery{QueryString: queryString, Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if result.Total != 1 {
+			t.Errorf("Expected 1 hit for %q, got %d", queryString, result.Total)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// MaxMultiGetDocuments is the largest number of document IDs a single MultiGetDocuments call can fetch
+const MaxMultiGetDocuments = 1000
+
+// MultiGetDocuments returns the documents of an index with the given IDs, in the order of the IDs,
+// along with the IDs that are not in the index. Repeated IDs are fetched once. Only the given
+// fields, and documentID, are kept; without fields, the index's default retrievable fields are, as
+// for search hits. It pairs with IDs-only searches for clients that rank first and hydrate after.
+func (e *Engine) MultiGetDocuments(indexName string, documentIDs []string, fields []string) (model.MultiGetResult, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.MultiGetResult{}, e.missingIndexError(indexName)
+	}
+
+	if len(documentIDs) == 0 {
+		return model.MultiGetResult{}, errors.NewValidationError("ids", "cannot be empty")
+	}
+	if len(documentIDs) > MaxMultiGetDocuments {
+		return model.MultiGetResult{}, errors.NewValidationError("ids", fmt.Sprintf("cannot have more than %d IDs", MaxMultiGetDocuments))
+	}
+	if err := validateFields("fields", fields); err != nil {
+		return model.MultiGetResult{}, err
+	}
+	if len(fields) == 0 {
+		fields = instance.Settings().DefaultRetrievableFields
+	}
+
+	result := model.MultiGetResult{
+		Documents: make([]model.Document, 0, len(documentIDs)),
+		Missing:   []string{},
+	}
+	seen := make(map[string]struct{}, len(documentIDs))
+	for _, documentID := range documentIDs {
+		if _, duplicate := seen[documentID]; duplicate {
+			continue
+		}
+		seen[documentID] = struct{}{}
+
+		var doc model.Document
+		found := false
+		if internalID, exists := instance.DocumentStore.Lookup(documentID); exists {
+			doc, found = instance.DocumentStore.Get(internalID)
+		}
+		if !found {
+			result.Missing = append(result.Missing, documentID)
+			continue
+		}
+		result.Documents = append(result.Documents, keepFields(doc, fields))
+	}
+	return result, nil
+}
+
+// GetDocument returns a document of an index by ID. Only the given fields, and documentID, are kept;
+// without fields, the whole document is returned.
+func (e *Engine) GetDocument(indexName, documentID string, fields []string) (model.Document, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return nil, e.missingIndexError(indexName)
+	}
+	if err := validateFields("retrievable_fields", fields); err != nil {
+		return nil, err
+	}
+
+	internalID, exists := instance.DocumentStore.Lookup(documentID)
+	if !exists {
+		return nil, errors.NewDocumentNotFoundError(documentID, indexName)
+	}
+	doc, found := instance.DocumentStore.Get(internalID)
+	if !found {
+		return nil, errors.NewDocumentNotFoundError(documentID, indexName)
+	}
+	return keepFields(doc, fields), nil
+}
+
+// ListDocuments returns a page of the documents of an index in documentID order, so pages do not
+// overlap while the index is unchanged. Only the given fields, and documentID, are kept; without
+// fields, whole documents are returned.
+func (e *Engine) ListDocuments(indexName string, page, pageSize int, fields []string) (model.DocumentList, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.DocumentList{}, e.missingIndexError(indexName)
+	}
+	if page < 1 {
+		return model.DocumentList{}, errors.NewValidationError("page", "must be at least 1")
+	}
+	if pageSize < 1 {
+		return model.DocumentList{}, errors.NewValidationError("page_size", "must be at least 1")
+	}
+	if err := validateFields("retrievable_fields", fields); err != nil {
+		return model.DocumentList{}, err
+	}
+
+	documentIDs := instance.DocumentStore.ExternalIDs()
+	list := model.DocumentList{
+		Documents: []model.Document{},
+		Total:     len(documentIDs),
+		Page:      page,
+		PageSize:  pageSize,
+		Pages:     (len(documentIDs) + pageSize - 1) / pageSize,
+	}
+	start := min((page-1)*pageSize, len(documentIDs))
+	for _, documentID := range documentIDs[start:min(start+pageSize, len(documentIDs))] {
+		if doc, found := instance.DocumentStore.GetByExternalID(documentID); found {
+			list.Documents = append(list.Documents, keepFields(doc, fields))
+		}
+	}
+	return list, nil
+}
+
+// validateFields checks that the fields to keep of documents are not blank.
+func validateFields(parameter string, fields []string) error {
+	for _, field := range fields {
+		if strings.TrimSpace(field) == "" {
+			return errors.NewValidationError(parameter, "cannot contain an empty field")
+		}
+	}
+	return nil
+}
+
+// keepFields returns a copy of a document with only the given fields and documentID, or the
+// document itself without fields.
+func keepFields(doc model.Document, fields []string) model.Document {
+	if len(fields) == 0 {
+		return doc
+	}
+	kept := make(model.Document, len(fields)+1)
+	if documentID, ok := doc["documentID"]; ok {
+		kept["documentID"] = documentID
+	}
+	for _, field := range fields {
+		if value, ok := doc[field]; ok {
+			kept[field] = value
+		}
+	}
+	return kept
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestMultiGetDocuments(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	const indexName = "test-batch-index"
+
+	if _, err := engine.MultiGetDocuments("missing-index", []string{"1"}, nil); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("MultiGetDocuments() on missing index error = %v, want ErrIndexNotFound", err)
+	}
+	tooMany := make([]string, MaxMultiGetDocuments+1)
+	for _, ids := range [][]string{nil, tooMany} {
+		if _, err := engine.MultiGetDocuments(indexName, ids, nil); !errors.Is(err, internalErrors.ErrInvalidInput) {
+			t.Errorf("MultiGetDocuments(%d IDs) error = %v, want ErrInvalidInput", len(ids), err)
+		}
+	}
+
+	result, err := engine.MultiGetDocuments(indexName, []string{"2", "missing", "1", "2"}, nil)
+	if err != nil {
+		t.Fatalf("MultiGetDocuments() error = %v", err)
+	}
+	want := model.MultiGetResult{
+		Documents: []model.Document{
+			{"documentID": "2", "title": "Discontinued Product"},
+			{"documentID": "1", "title": "Old Catalog Entry"},
+		},
+		Missing: []string{"missing"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("MultiGetDocuments() = %+v, want %+v", result, want)
+	}
+
+	result, err = engine.MultiGetDocuments(indexName, []string{"1"}, []string{"price"})
+	if err != nil {
+		t.Fatalf("MultiGetDocuments() with fields error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Documents, []model.Document{{"documentID": "1"}}) {
+		t.Errorf("Documents = %v, want only the documentID", result.Documents)
+	}
+}
+
+func TestGetAndListDocuments(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	const indexName = "test-batch-index"
+	if err := indexAccessor.AddDocuments([]model.Document{{"documentID": "0", "title": "New Arrival", "price": 10.0}}); err != nil {
+		t.Fatalf("Failed to add document: %v", err)
+	}
+
+	doc, err := engine.GetDocument(indexName, "0", nil)
+	if err != nil || !reflect.DeepEqual(doc, model.Document{"documentID": "0", "title": "New Arrival", "price": 10.0}) {
+		t.Errorf("GetDocument() = %v, %v, want the whole document", doc, err)
+	}
+	doc, err = engine.GetDocument(indexName, "0", []string{"price"})
+	if err != nil || !reflect.DeepEqual(doc, model.Document{"documentID": "0", "price": 10.0}) {
+		t.Errorf("GetDocument() with fields = %v, %v, want documentID and price", doc, err)
+	}
+	if _, err := engine.GetDocument(indexName, "missing", nil); !errors.Is(err, internalErrors.ErrDocumentNotFound) {
+		t.Errorf("GetDocument() of missing document error = %v, want ErrDocumentNotFound", err)
+	}
+	if _, err := engine.GetDocument("missing-index", "0", nil); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("GetDocument() on missing index error = %v, want ErrIndexNotFound", err)
+	}
+
+	list, err := engine.ListDocuments(indexName, 2, 2, []string{"title"})
+	if err != nil {
+		t.Fatalf("ListDocuments() error = %v", err)
+	}
+	want := model.DocumentList{
+		Documents: []model.Document{{"documentID": "2", "title": "Discontinued Product"}},
+		Total:     3,
+		Page:      2,
+		PageSize:  2,
+		Pages:     2,
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("ListDocuments() = %+v, want %+v", list, want)
+	}
+	if list, err := engine.ListDocuments(indexName, 5, 2, nil); err != nil || len(list.Documents) != 0 {
+		t.Errorf("ListDocuments() past the last page = %+v, %v, want no documents", list, err)
+	}
+	if _, err := engine.ListDocuments(indexName, 1, 2, []string{" "}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("ListDocuments() with a blank field error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -1,19 +1,22 @@
 package engine
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/index"
 	"github.com/gcbaptista/go-search-engine/internal/indexing"
 	"github.com/gcbaptista/go-search-engine/internal/persistence"
-	"github.com/gcbaptista/go-search-engine/internal/search"
+	"github.com/gcbaptista/go-search-engine/internal/tracing"
 	"github.com/gcbaptista/go-search-engine/model"
 	"github.com/gcbaptista/go-search-engine/store"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -21,20 +24,50 @@ const (
 	settingsFile      = "settings.gob"
 	invertedIndexFile = "inverted_index.gob"
 	documentStoreFile = "document_store.gob"
+	rulesFile         = "rules.json"
+	apiKeysFile       = "api_keys.json"
+	// persistMarkerFile exists in an index directory while the index is being persisted. Finding
+	// it when loading means the process stopped half-way, so the files may be out of step.
+	persistMarkerFile = "persist.pending"
+	// closedMarkerFile exists in the directory of a closed index, so it stays closed on restart
+	closedMarkerFile = "index.closed"
 )
 
+// fileDocumentStore is the built-in services.DocumentStore, keeping the documents of each index in a
+// gob file of the index directory.
+type fileDocumentStore struct {
+	dataDir string
+}
+
+func (s fileDocumentStore) LoadDocuments(indexName string, docs *store.DocumentStore) error {
+	return persistence.LoadGob(filepath.Join(s.dataDir, indexName, documentStoreFile), docs)
+}
+
+func (s fileDocumentStore) SaveDocuments(indexName string, docs *store.DocumentStore) error {
+	return persistence.SaveGob(filepath.Join(s.dataDir, indexName, documentStoreFile), docs)
+}
+
+func (s fileDocumentStore) DeleteDocuments(indexName string) error {
+	if err := os.Remove(filepath.Join(s.dataDir, indexName, documentStoreFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
 // loadIndexesFromDisk loads all indexes from the data directory.
 func (e *Engine) loadIndexesFromDisk() {
-	log.Printf("Loading indexes from disk: %s", e.dataDir)
+	slog.Info("loading indexes from disk", "data_dir", e.dataDir)
 
-	// Create data directory if it doesn't exist
-	if err := os.MkdirAll(e.dataDir, dataDirPerm); err != nil {
-		log.Printf("Warning: Could not create data directory %s: %v. Proceeding without persistence for new indexes if loading fails.", e.dataDir, err)
+	// Create data directory if it doesn't exist; read-only engines leave it untouched
+	if e.readOnly {
+		slog.Info("opening data directory read-only", "data_dir", e.dataDir)
+	} else if err := os.MkdirAll(e.dataDir, dataDirPerm); err != nil {
+		slog.Warn("could not create data directory, proceeding without persistence for new indexes if loading fails", "data_dir", e.dataDir, "error", err)
 	}
 
 	items, err := os.ReadDir(e.dataDir)
 	if err != nil {
-		log.Printf("Warning: Failed to read data directory %s: %v. No indexes loaded.", e.dataDir, err)
+		slog.Warn("failed to read data directory, no indexes loaded", "data_dir", e.dataDir, "error", err)
 		return
 	}
 
@@ -43,68 +76,138 @@ func (e *Engine) loadIndexesFromDisk() {
 			continue
 		}
 		indexName := item.Name()
-		indexPath := filepath.Join(e.dataDir, indexName)
-		log.Printf("Attempting to load index: %s", indexName)
-
-		var settings config.IndexSettings
-		settingsPath := filepath.Join(indexPath, settingsFile)
-		if err := persistence.LoadGob(settingsPath, &settings); err != nil {
-			log.Printf("Warning: Failed to load settings for index %s from %s: %v. Skipping this index.", indexName, settingsPath, err)
+		if _, err := os.Stat(filepath.Join(e.dataDir, indexName, closedMarkerFile)); err == nil {
+			// Closed indexes stay on disk until they are opened
+			settings, _, err := e.readIndexSettings(indexName)
+			if err != nil {
+				slog.Warn("failed to load settings of closed index, skipping the index", "index", indexName, "error", err)
+				continue
+			}
+			e.closedIndexes[indexName] = &settings
+			slog.Info("index is closed", "index", indexName)
 			continue
 		}
 
-		// Validate settings name matches directory name
-		if settings.Name != indexName {
-			log.Printf("Warning: Index name in settings ('%s') does not match directory name ('%s') for path %s. Skipping this index.", settings.Name, indexName, indexPath)
+		slog.Info("loading index", "index", indexName)
+		instance, rebuilt, err := e.readIndex(indexName)
+		if err == nil {
+			err = e.addLoadedIndexUnsafe(instance, rebuilt)
+		}
+		if err != nil {
+			slog.Error("failed to load index, skipping the index", "index", indexName, "error", err)
 			continue
 		}
+		slog.Info("index loaded", "index", indexName)
+	}
+}
 
-		docStore := &store.DocumentStore{}
-		dsPath := filepath.Join(indexPath, documentStoreFile)
-		if err := persistence.LoadGob(dsPath, docStore); err != nil && !errors.Is(err, os.ErrNotExist) {
-			log.Printf("Warning: Failed to load document store for index %s from %s: %v. Proceeding with empty store.", indexName, dsPath, err)
-			// Initialize to empty if load failed but not due to file not existing (e.g. corrupted file)
-			docStore.Docs = make(map[uint32]model.Document)
-			docStore.ExternalIDtoInternalID = make(map[string]uint32)
-		} else if errors.Is(err, os.ErrNotExist) {
-			log.Printf("Info: Document store file %s not found for index %s. Initializing empty store.", dsPath, indexName)
-			docStore.Docs = make(map[uint32]model.Document)
-			docStore.ExternalIDtoInternalID = make(map[string]uint32)
-		}
+// readIndexSettings reads the settings of the index stored in the directory named indexName, and
+// when they were last written.
+func (e *Engine) readIndexSettings(indexName string) (config.IndexSettings, time.Time, error) {
+	var settings config.IndexSettings
+	settingsPath := filepath.Join(e.dataDir, indexName, settingsFile)
+	if err := persistence.LoadGob(settingsPath, &settings); err != nil {
+		return config.IndexSettings{}, time.Time{}, fmt.Errorf("failed to load settings from %s: %w", settingsPath, err)
+	}
+	// Validate settings name matches directory name
+	if settings.Name != indexName {
+		return config.IndexSettings{}, time.Time{}, fmt.Errorf("index name in settings ('%s') does not match directory name", settings.Name)
+	}
+	updatedAt := time.Now()
+	if info, err := os.Stat(settingsPath); err == nil {
+		updatedAt = info.ModTime() // Settings are written on every persist
+	}
+	return settings, updatedAt, nil
+}
 
-		invIndex := &index.InvertedIndex{Settings: &settings} // Settings must be linked here
+// readIndex reads the index stored in the directory named indexName, without starting its search
+// service. It reports whether the index was rebuilt from the stored documents because its files
+// were out of step, so it should be persisted again.
+func (e *Engine) readIndex(indexName string) (*IndexInstance, bool, error) {
+	indexPath := filepath.Join(e.dataDir, indexName)
+	settings, updatedAt, err := e.readIndexSettings(indexName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	docStore := &store.DocumentStore{}
+	if err := e.documents.LoadDocuments(indexName, docStore); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("failed to load document store, proceeding with an empty store", "index", indexName, "error", err)
+		// Initialize to empty if load failed but not due to the documents not existing (e.g. corrupted file)
+		docStore.Docs = make(map[uint32]model.Document)
+		docStore.ExternalIDtoInternalID = make(map[string]uint32)
+	} else if errors.Is(err, os.ErrNotExist) {
+		slog.Info("stored documents not found, initializing an empty store", "index", indexName)
+		docStore.Docs = make(map[uint32]model.Document)
+		docStore.ExternalIDtoInternalID = make(map[string]uint32)
+	}
+
+	invIndex := index.NewInvertedIndex(&settings) // Settings must be linked here
+	instance := &IndexInstance{
+		settings:      &settings,
+		InvertedIndex: invIndex,
+		DocumentStore: docStore,
+		readOnly:      e.readOnly,
+	}
+	instance.markUpdated(updatedAt)
+	interrupted := false
+	segmentsLoaded := false
+	if settings.SegmentStorage != nil {
+		// Segments are mapped rather than read, so the posting lists load as searches need them
+		if err := instance.loadSegments(indexPath); err == nil {
+			segmentsLoaded = true
+		} else if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to load segments, rebuilding the inverted index from the stored documents", "index", indexName, "error", err)
+			invIndex.Reset()
+			interrupted = true
+		}
+	}
+	if !segmentsLoaded && !interrupted {
 		iiPath := filepath.Join(indexPath, invertedIndexFile)
 		if err := persistence.LoadGob(iiPath, invIndex); err != nil && !errors.Is(err, os.ErrNotExist) {
-			log.Printf("Warning: Failed to load inverted index for index %s from %s: %v. Proceeding with empty index.", indexName, iiPath, err)
-			invIndex.Index = make(map[string]index.PostingList) // Init to empty if corrupted
+			slog.Warn("failed to load inverted index, proceeding with an empty index", "index", indexName, "path", iiPath, "error", err)
+			invIndex.Reset() // Init to empty if corrupted
 		} else if errors.Is(err, os.ErrNotExist) {
-			log.Printf("Info: Inverted index file %s not found for index %s. Initializing empty index.", iiPath, indexName)
-			invIndex.Index = make(map[string]index.PostingList)
+			slog.Info("inverted index file not found, initializing an empty index", "index", indexName, "path", iiPath)
 		}
+	}
 
-		indexerService, err := indexing.NewService(invIndex, docStore)
-		if err != nil {
-			log.Printf("Error creating indexer service for loaded index %s: %v. Skipping.", indexName, err)
-			continue
+	indexerService, err := indexing.NewService(invIndex, docStore)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create indexer service: %w", err)
+	}
+	instance.indexer = indexerService
+	if _, err := os.Stat(filepath.Join(indexPath, persistMarkerFile)); err == nil {
+		interrupted = true
+		// The document store is written first, so the inverted index may lag behind it
+		slog.Warn("persisting index was interrupted, rebuilding the inverted index from the stored documents", "index", indexName)
+	}
+	if interrupted {
+		if err := indexerService.BulkReindex(indexing.DefaultBulkIndexingConfig()); err != nil {
+			return nil, false, fmt.Errorf("failed to rebuild interrupted index: %w", err)
 		}
+	}
+	return instance, interrupted, nil
+}
 
-		searchService, err := search.NewService(invIndex, docStore, &settings)
-		if err != nil {
-			log.Printf("Error creating search service for loaded index %s: %v. Skipping.", indexName, err)
-			continue
-		}
+// addLoadedIndexUnsafe starts the search service of an index read from disk and adds it to the
+// engine, persisting it first if it was rebuilt. The caller must hold the engine's write lock.
+func (e *Engine) addLoadedIndexUnsafe(instance *IndexInstance, rebuilt bool) error {
+	name := instance.settings.Name
+	searchService, err := e.newSearchServiceUnsafe(instance)
+	if err != nil {
+		return fmt.Errorf("failed to create search service: %w", err)
+	}
+	instance.SetSearcher(searchService)
 
-		instance := &IndexInstance{
-			settings:      &settings,
-			InvertedIndex: invIndex,
-			DocumentStore: docStore,
-			indexer:       indexerService,
-			searcher:      searchService, // Assign loaded/initialized searcher
+	if rebuilt && !e.readOnly {
+		if err := e.persistUpdatedIndexUnsafe(context.Background(), name, *instance.settings, instance); err != nil {
+			slog.Warn("failed to persist rebuilt index", "index", name, "error", err)
 		}
-
-		e.indexes[indexName] = instance
-		log.Printf("Successfully loaded index: %s", indexName)
 	}
+
+	e.indexes[name] = instance
+	return nil
 }
 
 // PersistIndexData persists the data for a specific index to disk.
@@ -117,36 +220,69 @@ func (e *Engine) PersistIndexData(indexName string) error {
 		return fmt.Errorf("index named '%s' not found", indexName)
 	}
 
-	return e.persistUpdatedIndexUnsafe(indexName, *instance.settings, instance)
+	return e.persistUpdatedIndexUnsafe(context.Background(), indexName, *instance.settings, instance)
 }
 
-// persistUpdatedIndexUnsafe persists an index instance to disk.
+// persistUpdatedIndexUnsafe persists an index instance to disk. Writes to the index wait until
+// it is written, and the document store is written before the inverted index, so the inverted
+// index on disk never references documents that were not stored. If the process stops half-way,
+// the index is rebuilt from the stored documents when it is loaded again.
+// The write is traced as a span of the job or request ctx carries.
 // This method assumes the caller has appropriate locking.
-func (e *Engine) persistUpdatedIndexUnsafe(name string, settings config.IndexSettings, instance *IndexInstance) error {
+func (e *Engine) persistUpdatedIndexUnsafe(ctx context.Context, name string, settings config.IndexSettings, instance *IndexInstance) (err error) {
+	if err := e.checkWritable("persist index"); err != nil {
+		return err
+	}
+	_, span := tracing.Start(ctx, "index.persist",
+		attribute.String("index", name),
+		attribute.Int("documents", instance.DocumentStore.Len()),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	indexPath := filepath.Join(e.dataDir, name)
 	if err := os.MkdirAll(indexPath, dataDirPerm); err != nil {
 		return fmt.Errorf("failed to create directory for index %s: %w", name, err)
 	}
 
-	if err := persistence.SaveGob(filepath.Join(indexPath, settingsFile), settings); err != nil {
-		return fmt.Errorf("failed to save settings for index %s: %w", name, err)
+	markerPath := filepath.Join(indexPath, persistMarkerFile)
+	if err := os.WriteFile(markerPath, nil, 0600); err != nil {
+		return fmt.Errorf("failed to mark index %s as being persisted: %w", name, err)
 	}
-	if err := persistence.SaveGob(filepath.Join(indexPath, invertedIndexFile), instance.InvertedIndex); err != nil {
-		return fmt.Errorf("failed to save inverted index for %s: %w", name, err)
+	save := func() error {
+		if err := persistence.SaveGob(filepath.Join(indexPath, settingsFile), settings); err != nil {
+			return fmt.Errorf("failed to save settings for index %s: %w", name, err)
+		}
+		if err := e.documents.SaveDocuments(name, instance.DocumentStore); err != nil {
+			return fmt.Errorf("failed to save document store for %s: %w", name, err)
+		}
+		if settings.SegmentStorage != nil {
+			if err := instance.persistSegmentsUnsafe(indexPath); err != nil {
+				return fmt.Errorf("failed to save segments for %s: %w", name, err)
+			}
+			return nil
+		}
+		if err := persistence.SaveGob(filepath.Join(indexPath, invertedIndexFile), instance.InvertedIndex); err != nil {
+			return fmt.Errorf("failed to save inverted index for %s: %w", name, err)
+		}
+		instance.removeSegmentsUnsafe(indexPath)
+		return nil
 	}
-	if err := persistence.SaveGob(filepath.Join(indexPath, documentStoreFile), instance.DocumentStore); err != nil {
-		return fmt.Errorf("failed to save document store for %s: %w", name, err)
+	if instance.indexer != nil {
+		err = instance.indexer.Freeze(save)
+	} else {
+		err = save()
+	}
+	if err != nil {
+		// The marker stays, so the files are made consistent again when the index is loaded
+		return err
 	}
 
-	return nil
-}
-
-// extractAllDocumentsUnsafe extracts all documents from an index instance.
-// This method assumes the caller has appropriate locking.
-func (e *Engine) extractAllDocumentsUnsafe(instance *IndexInstance) []model.Document {
-	docs := make([]model.Document, 0, len(instance.DocumentStore.Docs))
-	for _, doc := range instance.DocumentStore.Docs {
-		docs = append(docs, doc)
+	if err := os.Remove(markerPath); err != nil {
+		return fmt.Errorf("failed to mark index %s as persisted: %w", name, err)
+	}
+	instance.markUpdated(time.Now())
+	if settings.SegmentStorage != nil {
+		instance.scheduleSegmentMerge(indexPath, settings.SegmentStorage.SegmentLimit())
 	}
-	return docs
+	return nil
 }
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/persistence"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestEngine_InterruptedPersistRecovery(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	if err := engine.PersistIndexData("test-batch-index"); err != nil {
+		t.Fatalf("Failed to persist index: %v", err)
+	}
+	indexPath := filepath.Join(engine.dataDir, "test-batch-index")
+	if _, err := os.Stat(filepath.Join(indexPath, persistMarkerFile)); !os.IsNotExist(err) {
+		t.Fatalf("Expected no persist marker after a completed persist, got %v", err)
+	}
+
+	// The process stops after writing the document store of a bulk import, before its inverted index
+	if err := indexAccessor.AddDocuments([]model.Document{{"documentID": "3", "title": "Brand New Product"}}); err != nil {
+		t.Fatalf("Failed to add document: %v", err)
+	}
+	instance := indexAccessor.(*IndexInstance)
+	if err := os.WriteFile(filepath.Join(indexPath, persistMarkerFile), nil, 0600); err != nil {
+		t.Fatalf("Failed to write persist marker: %v", err)
+	}
+	if err := persistence.SaveGob(filepath.Join(indexPath, documentStoreFile), instance.DocumentStore); err != nil {
+		t.Fatalf("Failed to save document store: %v", err)
+	}
+
+	reloaded := NewEngine(engine.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	recovered, err := reloaded.GetIndex("test-batch-index")
+	if err != nil {
+		t.Fatalf("Expected the interrupted index to be loaded: %v", err)
+	}
+	result, err := recovered.Search(services.SearchQuery{QueryString: "brand"})
+	if err != nil || result.Total != 1 {
+		t.Errorf("Expected the stored document to be indexed again, got %d hits (err: %v)", result.Total, err)
+	}
+	if report, err := reloaded.VerifyIndex("test-batch-index", false); err != nil || !report.Healthy {
+		t.Errorf("Expected the recovered index to be consistent, got %+v (err: %v)", report, err)
+	}
+	if _, err := os.Stat(filepath.Join(indexPath, persistMarkerFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the recovered index to be persisted again, got %v", err)
+	}
+}
+
+func TestEngine_IndexUpdatedAt(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	instance.markUpdated(past)
+	if err := engine.PersistIndexData("test-batch-index"); err != nil {
+		t.Fatalf("Failed to persist index: %v", err)
+	}
+	updatedAt := instance.UpdatedAt()
+	if !updatedAt.After(past) {
+		t.Fatalf("Expected the update time to advance when the index is persisted, got %v", updatedAt)
+	}
+
+	// Loaded indexes start with the time their settings were last written
+	settingsPath := filepath.Join(engine.dataDir, "test-batch-index", settingsFile)
+	if err := os.Chtimes(settingsPath, past, past); err != nil {
+		t.Fatalf("Failed to change the settings file times: %v", err)
+	}
+	reloaded := NewEngine(engine.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	reloadedAccessor, err := reloaded.GetIndex("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to get reloaded index: %v", err)
+	}
+	if got := reloadedAccessor.(*IndexInstance).UpdatedAt(); !got.Equal(past) {
+		t.Errorf("Expected the reloaded update time %v, got %v", past, got)
+	}
+}
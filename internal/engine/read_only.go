@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// NewReadOnlyEngine opens an existing data directory without ever writing to it, so a separate
+// process can run searches, exports and snapshots against the files of a live server. The
+// indexes are loaded as they are on disk when the engine is created; writes made by the server
+// afterwards are not seen. Operations that would write to the data directory or run jobs fail
+// with a read-only error.
+func NewReadOnlyEngine(dataDir string) (*Engine, error) {
+	info, err := os.Stat(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data directory %s: %w", dataDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("failed to open data directory %s: not a directory", dataDir)
+	}
+	return newEngine(dataDir, true, services.Extensions{})
+}
+
+// ReadOnly reports whether the engine opened its data directory read-only.
+func (e *Engine) ReadOnly() bool {
+	return e.readOnly
+}
+
+// DataDir returns the directory the engine stores its data in, or "" for a read-only engine, which
+// never writes to it.
+func (e *Engine) DataDir() string {
+	if e.readOnly {
+		return ""
+	}
+	return e.dataDir
+}
+
+// checkWritable returns a read-only error for the operation when the engine is read-only.
+func (e *Engine) checkWritable(operation string) error {
+	if e.readOnly {
+		return errors.NewReadOnlyError(operation)
+	}
+	return nil
+}
+
+// checkWritable returns a read-only error for the operation when the index was loaded by a
+// read-only engine.
+func (i *IndexInstance) checkWritable(operation string) error {
+	if i.readOnly {
+		return errors.NewReadOnlyError(operation)
+	}
+	return nil
+}
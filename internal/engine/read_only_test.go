@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// dataDirState lists the files of a data directory with their sizes and modification times.
+func dataDirState(t *testing.T, dataDir string) string {
+	t.Helper()
+	var state strings.Builder
+	err := filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&state, "%s %d %v\n", path, info.Size(), info.ModTime())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to list data directory: %v", err)
+	}
+	return state.String()
+}
+
+func TestReadOnlyEngine(t *testing.T) {
+	live, _ := newBatchTestEngine(t)
+	if err := live.PersistIndexData("test-batch-index"); err != nil {
+		t.Fatalf("Failed to persist index: %v", err)
+	}
+	before := dataDirState(t, live.dataDir)
+
+	engine, err := NewReadOnlyEngine(live.dataDir)
+	if err != nil {
+		t.Fatalf("Failed to open data directory read-only: %v", err)
+	}
+	t.Cleanup(engine.jobManager.Stop)
+	if !engine.ReadOnly() {
+		t.Error("Expected the engine to be read-only")
+	}
+
+	indexAccessor, err := engine.GetIndex("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to get index: %v", err)
+	}
+	result, err := indexAccessor.Search(services.SearchQuery{QueryString: "catalog"})
+	if err != nil || result.Total != 1 {
+		t.Errorf("Expected searches to run on the loaded index, got %d hits (err: %v)", result.Total, err)
+	}
+
+	writes := map[string]error{
+		"create index":    engine.CreateIndex(config.IndexSettings{Name: "new-index", SearchableFields: []string{"title"}}),
+		"delete index":    engine.DeleteIndex("test-batch-index"),
+		"add documents":   indexAccessor.AddDocuments([]model.Document{{"documentID": "3", "title": "New"}}),
+		"delete document": indexAccessor.DeleteDocument("1"),
+		"update settings": engine.UpdateIndexSettings("test-batch-index", indexAccessor.Settings()),
+	}
+	_, writes["add documents async"] = engine.AddDocumentsAsync("test-batch-index", []model.Document{{"documentID": "3"}})
+	_, writes["open batch"] = engine.OpenBatch("test-batch-index")
+	_, writes["repair index"] = engine.VerifyIndex("test-batch-index", true)
+	for operation, err := range writes {
+		if !errors.Is(err, internalErrors.ErrReadOnly) {
+			t.Errorf("Expected %s to fail with a read-only error, got %v", operation, err)
+		}
+	}
+
+	if _, err := engine.VerifyIndex("test-batch-index", false); err != nil {
+		t.Errorf("Expected verifying without repair to be allowed, got %v", err)
+	}
+	if after := dataDirState(t, live.dataDir); after != before {
+		t.Errorf("Expected the data directory to be left untouched, was:\n%s\nnow:\n%s", before, after)
+	}
+	if result, err := indexAccessor.Search(services.SearchQuery{QueryString: "catalog"}); err != nil || result.Total != 1 {
+		t.Errorf("Expected the index to be unchanged, got %d hits (err: %v)", result.Total, err)
+	}
+
+	if _, err := NewReadOnlyEngine(filepath.Join(live.dataDir, "missing")); err == nil {
+		t.Error("Expected opening a missing data directory read-only to fail")
+	}
+	if _, err := os.Stat(filepath.Join(live.dataDir, "missing")); !os.IsNotExist(err) {
+		t.Error("Expected a missing data directory not to be created")
+	}
+}
@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/internal/indexing"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/store"
+)
+
+// readReplica is a copy of an index that serves its searches, so writes to the index never hold
+// the locks searches wait on. A background loop merges the changes made by writes into the copy.
+type readReplica struct {
+	invertedIndex *index.InvertedIndex
+	documentStore *store.DocumentStore
+	interval      time.Duration
+
+	mergeMu       sync.Mutex // Serializes merges between the refresh loop and explicit refreshes
+	refreshes     int64
+	lastRefreshAt time.Time
+
+	stop chan struct{} // Closed to stop the refresh loop
+	done chan struct{} // Closed when the refresh loop has stopped
+}
+
+// syncReadReplica starts, reconfigures or stops the index's read replica to match its settings and
+// returns the structures searches should use. It is called whenever the search service is created.
+func (i *IndexInstance) syncReadReplica() (*index.InvertedIndex, *store.DocumentStore) {
+	i.replicaMu.Lock()
+	defer i.replicaMu.Unlock()
+
+	replicaSettings := i.settings.ReadReplica
+	if replicaSettings == nil || i.indexer == nil {
+		i.closeReadReplicaUnsafe()
+		return i.InvertedIndex, i.DocumentStore
+	}
+
+	interval := replicaSettings.RefreshInterval()
+	if i.replica == nil {
+		i.replica = &readReplica{
+			invertedIndex: index.NewInvertedIndex(i.settings),
+			documentStore: &store.DocumentStore{
+				Docs:                   make(map[uint32]model.Document),
+				ExternalIDtoInternalID: make(map[string]uint32),
+			},
+		}
+		i.indexer.TrackChanges(true)
+		i.replica.merge(i.indexer.CollectDelta())
+	} else if i.replica.interval != interval {
+		i.replica.stopRefreshing()
+	}
+	i.replica.documentStore.SetCompression(i.settings.DocumentCompression)
+	i.replica.documentStore.SetSortableFields(i.settings.SortableFields)
+
+	if i.replica.stop == nil {
+		i.replica.interval = interval
+		i.startRefreshing(i.replica)
+	}
+	return i.replica.invertedIndex, i.replica.documentStore
+}
+
+// startRefreshing runs the loop merging the index's writes into the replica every refresh interval.
+func (i *IndexInstance) startRefreshing(replica *readReplica) {
+	replica.stop = make(chan struct{})
+	replica.done = make(chan struct{})
+
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(replica.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				i.refreshReplica(replica)
+			}
+		}
+	}(replica.stop, replica.done)
+}
+
+// stopRefreshing stops the refresh loop and waits for a merge in progress to finish.
+func (r *readReplica) stopRefreshing() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	r.stop = nil
+	r.done = nil
+}
+
+// refreshReplica merges the writes made since the last refresh into the replica.
+func (i *IndexInstance) refreshReplica(replica *readReplica) {
+	if replica.merge(i.indexer.CollectDelta()) {
+		// Searches only see the writes now, so the cache warmer warms the index again
+		i.recordWrite()
+	}
+}
+
+// merge applies a delta to the replica and reports whether anything changed. Searches are only
+// blocked while the changed postings and documents are swapped in.
+func (r *readReplica) merge(delta indexing.Delta) bool {
+	r.mergeMu.Lock()
+	defer r.mergeMu.Unlock()
+
+	if delta.Empty() {
+		return false
+	}
+
+	// Searches hold the inverted index lock shared, so the merge is atomic to them
+	r.invertedIndex.Mu.Lock()
+	defer r.invertedIndex.Mu.Unlock()
+
+	if delta.Full {
+		r.invertedIndex.Reset()
+		r.documentStore.Reset()
+	}
+
+	for term, postings := range delta.Postings {
+		if postings == nil {
+			r.invertedIndex.Delete(term)
+		} else {
+			r.invertedIndex.Set(term, postings)
+		}
+	}
+
+	for documentID, change := range delta.Documents {
+		if previousID, exists := r.documentStore.Lookup(documentID); exists && (change.Document == nil || previousID != change.InternalID) {
+			r.documentStore.Remove(documentID, previousID)
+			r.invertedIndex.DeleteFieldLengths(previousID)
+		}
+		if change.Document != nil {
+			r.documentStore.Put(documentID, change.InternalID, change.Document)
+			r.invertedIndex.SetFieldLengths(change.InternalID, change.FieldLengths)
+		}
+	}
+	r.documentStore.Mu.Lock()
+	r.documentStore.NextID = delta.NextID
+	r.documentStore.LastSeq = delta.Seq
+	r.documentStore.Mu.Unlock()
+
+	r.refreshes++
+	r.lastRefreshAt = time.Now()
+	return true
+}
+
+// RefreshReadReplica merges the pending writes into the index's read replica right away, so the
+// next searches see them. It is a no-op for indexes without a read replica.
+func (i *IndexInstance) RefreshReadReplica() {
+	i.replicaMu.Lock()
+	replica := i.replica
+	i.replicaMu.Unlock()
+
+	if replica != nil {
+		i.refreshReplica(replica)
+	}
+}
+
+// ReadReplicaStats describes the index's read replica, or returns nil if it has none.
+func (i *IndexInstance) ReadReplicaStats() *model.ReadReplicaStats {
+	i.replicaMu.Lock()
+	replica := i.replica
+	var interval time.Duration
+	if replica != nil {
+		interval = replica.interval
+	}
+	i.replicaMu.Unlock()
+
+	if replica == nil {
+		return nil
+	}
+
+	replica.mergeMu.Lock()
+	defer replica.mergeMu.Unlock()
+
+	stats := &model.ReadReplicaStats{
+		RefreshIntervalMs: interval.Milliseconds(),
+		Refreshes:         replica.refreshes,
+		DocumentCount:     replica.documentStore.Len(),
+	}
+	if !replica.lastRefreshAt.IsZero() {
+		lastRefreshAt := replica.lastRefreshAt
+		stats.LastRefreshAt = &lastRefreshAt
+	}
+	return stats
+}
+
+// closeReadReplica drops the index's read replica and stops its refresh loop, e.g. when the index is deleted.
+func (i *IndexInstance) closeReadReplica() {
+	i.replicaMu.Lock()
+	defer i.replicaMu.Unlock()
+	i.closeReadReplicaUnsafe()
+}
+
+// closeReadReplicaUnsafe drops the read replica. The caller must hold i.replicaMu.
+func (i *IndexInstance) closeReadReplicaUnsafe() {
+	if i.replica == nil {
+		return
+	}
+	i.replica.stopRefreshing()
+	i.replica = nil
+	if i.indexer != nil {
+		i.indexer.TrackChanges(false)
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestEngine_ReadReplica(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+
+	updateReplica := func(replica *config.ReadReplica) {
+		t.Helper()
+		settings := instance.Settings()
+		settings.ReadReplica = replica
+		if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+			t.Fatalf("Failed to update settings: %v", err)
+		}
+	}
+
+	// A long interval keeps the refresh loop out of the way, so refreshes are explicit
+	updateReplica(&config.ReadReplica{RefreshIntervalMs: int(time.Hour.Milliseconds())})
+	if total := searchTotal(t, indexAccessor, "catalog"); total != 1 {
+		t.Fatalf("Expected the replica to start with the existing documents, got %d hits", total)
+	}
+
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Archived Entry"},
+		{"documentID": "3", "title": "Fresh Arrival"},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+	if err := indexAccessor.DeleteDocument("2"); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+
+	// Writes are not visible until they are merged into the replica
+	for query, expected := range map[string]int{"catalog": 1, "discontinued": 1, "archived": 0, "fresh": 0} {
+		if total := searchTotal(t, indexAccessor, query); total != expected {
+			t.Errorf("Before refresh: expected %d hits for %q, got %d", expected, query, total)
+		}
+	}
+	if seq, err := engine.IndexSeq("test-batch-index"); err != nil || seq.Seq != 5 || seq.SearchableSeq != 2 {
+		t.Errorf("Before refresh: expected seq 5 with searchable_seq 2, got %+v (err: %v)", seq, err)
+	}
+
+	instance.RefreshReadReplica()
+	for query, expected := range map[string]int{"catalog": 0, "discontinued": 0, "archived": 1, "fresh": 1} {
+		if total := searchTotal(t, indexAccessor, query); total != expected {
+			t.Errorf("After refresh: expected %d hits for %q, got %d", expected, query, total)
+		}
+	}
+	if seq, err := engine.IndexSeq("test-batch-index"); err != nil || seq.SearchableSeq != seq.Seq {
+		t.Errorf("After refresh: expected searchable_seq to catch up with seq, got %+v (err: %v)", seq, err)
+	}
+
+	// Field lengths follow the documents, so BM25 scores the replica like the index
+	for _, field := range instance.settings.SearchableFields {
+		if replicaLength, indexLength := instance.replica.invertedIndex.FieldLength(field), instance.InvertedIndex.FieldLength(field); replicaLength != indexLength {
+			t.Errorf("Expected the replica to have %d words in field %q, got %d", indexLength, field, replicaLength)
+		}
+	}
+
+	stats := instance.ReadReplicaStats()
+	if stats == nil || stats.Refreshes != 2 || stats.DocumentCount != 2 || stats.LastRefreshAt == nil {
+		t.Fatalf("Expected 2 refreshes and 2 documents, got %+v", stats)
+	}
+	instance.RefreshReadReplica()
+	if stats := instance.ReadReplicaStats(); stats.Refreshes != 2 {
+		t.Errorf("Expected a refresh without writes to be skipped, got %d refreshes", stats.Refreshes)
+	}
+
+	// The refresh loop merges writes on its own
+	updateReplica(&config.ReadReplica{RefreshIntervalMs: 10})
+	if err := indexAccessor.AddDocuments([]model.Document{{"documentID": "4", "title": "Scheduled Delivery"}}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for searchTotal(t, indexAccessor, "delivery") != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the refresh loop to merge the new document")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Without a replica, searches read the index directly again
+	updateReplica(nil)
+	if instance.ReadReplicaStats() != nil {
+		t.Error("Expected no replica stats after disabling the replica")
+	}
+	if err := indexAccessor.DeleteDocument("4"); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+	if total := searchTotal(t, indexAccessor, "delivery"); total != 0 {
+		t.Errorf("Expected the deletion to be visible immediately, got %d hits", total)
+	}
+}
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// NewEngineWithExtensions creates a search engine orchestrator built with the given extensions,
+// e.g. a job runner backed by a distributed queue or rules kept in a database. It fails if a query
+// rewriter or scorer is nil or shares its name with another one.
+func NewEngineWithExtensions(dataDir string, extensions services.Extensions) (*Engine, error) {
+	return newEngine(dataDir, false, extensions)
+}
+
+// Extensions returns the extensions the engine runs with: those it was created with, built-in
+// ones for the parts left zero, and the rewriters, scorers and popular query source registered
+// since. Scorers are sorted by name.
+func (e *Engine) Extensions() services.Extensions {
+	e.mu.RLock()
+	extensions := services.Extensions{
+		JobRunner:      e.jobManager,
+		RuleStore:      e.ruleStore,
+		DocumentStore:  e.documents,
+		QueryRewriters: slices.Clone(e.rewriters),
+	}
+	for _, scorer := range e.scorers {
+		extensions.Scorers = append(extensions.Scorers, scorer)
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(extensions.Scorers, func(i, j int) bool {
+		return extensions.Scorers[i].Name() < extensions.Scorers[j].Name()
+	})
+	extensions.PopularQuerySource = e.popularQuerySource()
+	return extensions
+}
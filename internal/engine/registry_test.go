@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/jobs"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+	"github.com/gcbaptista/go-search-engine/store"
+)
+
+// countingJobRunner runs jobs with the built-in job manager and counts the jobs created.
+type countingJobRunner struct {
+	*jobs.Manager
+	created atomic.Int32
+}
+
+func (r *countingJobRunner) CreateJob(jobType model.JobType, indexName string, metadata map[string]string) string {
+	r.created.Add(1)
+	return r.Manager.CreateJob(jobType, indexName, metadata)
+}
+
+// memoryDocumentStore keeps the documents of every index in memory, encoded like the built-in store.
+type memoryDocumentStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryDocumentStore) LoadDocuments(indexName string, docs *store.DocumentStore) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, exists := s.data[indexName]
+	if !exists {
+		return os.ErrNotExist
+	}
+	return docs.GobDecode(data)
+}
+
+func (s *memoryDocumentStore) SaveDocuments(indexName string, docs *store.DocumentStore) error {
+	data, err := docs.GobEncode()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[indexName] = data
+	return nil
+}
+
+func (s *memoryDocumentStore) DeleteDocuments(indexName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, indexName)
+	return nil
+}
+
+func (s *memoryDocumentStore) indexes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name := range s.data {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestNewEngineWithExtensions(t *testing.T) {
+	testDir := createTestDir(t)
+	defer func() {
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Logf("Failed to remove test directory: %v", err)
+		}
+	}()
+
+	runner := &countingJobRunner{Manager: jobs.NewManager(2)}
+	runner.Start()
+	defer runner.Stop()
+
+	if _, err := NewEngineWithExtensions(testDir, services.Extensions{
+		JobRunner: runner,
+		Scorers:   []services.Scorer{popularityScorer{}, popularityScorer{}},
+	}); err == nil {
+		t.Fatal("Expected an error for scorers sharing a name")
+	}
+
+	engine, err := NewEngineWithExtensions(testDir, services.Extensions{
+		JobRunner: runner,
+		Scorers:   []services.Scorer{popularityScorer{}},
+	})
+	if err != nil {
+		t.Fatalf("NewEngineWithExtensions() error = %v", err)
+	}
+
+	extensions := engine.Extensions()
+	if extensions.JobRunner != runner || len(extensions.Scorers) != 1 || extensions.RuleStore == nil {
+		t.Errorf("Extensions() = %+v, want the custom runner, the scorer and the built-in rule store", extensions)
+	}
+
+	jobID, err := engine.CreateIndexAsync(config.IndexSettings{Name: "extensions", SearchableFields: []string{"title"}})
+	if err != nil {
+		t.Fatalf("CreateIndexAsync() error = %v", err)
+	}
+	if job := waitForJob(t, engine, jobID); job.Status != model.JobStatusCompleted {
+		t.Fatalf("Job status = %s (%s), want completed", job.Status, job.Error)
+	}
+	if runner.created.Load() != 1 {
+		t.Errorf("Custom job runner created %d jobs, want 1", runner.created.Load())
+	}
+}
+
+func TestNewEngineWithExtensions_DocumentStore(t *testing.T) {
+	testDir := createTestDir(t)
+	defer func() {
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Logf("Failed to remove test directory: %v", err)
+		}
+	}()
+
+	documents := &memoryDocumentStore{data: make(map[string][]byte)}
+	engine, err := NewEngineWithExtensions(testDir, services.Extensions{DocumentStore: documents})
+	if err != nil {
+		t.Fatalf("NewEngineWithExtensions() error = %v", err)
+	}
+	t.Cleanup(engine.jobManager.Stop)
+	if engine.Extensions().DocumentStore != documents {
+		t.Errorf("Extensions().DocumentStore = %v, want the custom store", engine.Extensions().DocumentStore)
+	}
+
+	if err := engine.CreateIndex(config.IndexSettings{Name: "documents", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	accessor, err := engine.GetIndex("documents")
+	if err != nil {
+		t.Fatalf("GetIndex() error = %v", err)
+	}
+	if err := accessor.AddDocuments([]model.Document{{"documentID": "1", "title": "The Matrix"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	if err := engine.PersistIndexData("documents"); err != nil {
+		t.Fatalf("PersistIndexData() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(testDir, "documents", documentStoreFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no document store file with a custom document store, got %v", err)
+	}
+
+	// A new engine reads the documents back from the custom store
+	reloaded, err := NewEngineWithExtensions(testDir, services.Extensions{DocumentStore: documents})
+	if err != nil {
+		t.Fatalf("NewEngineWithExtensions() error = %v", err)
+	}
+	t.Cleanup(reloaded.jobManager.Stop)
+	accessor, err = reloaded.GetIndex("documents")
+	if err != nil {
+		t.Fatalf("GetIndex() error = %v", err)
+	}
+	if result, err := accessor.Search(services.SearchQuery{QueryString: "matrix"}); err != nil || result.Total != 1 {
+		t.Errorf("Expected the stored document to be found, got %d hits (err: %v)", result.Total, err)
+	}
+
+	if err := reloaded.RenameIndex("documents", "renamed"); err != nil {
+		t.Fatalf("RenameIndex() error = %v", err)
+	}
+	if names := documents.indexes(); len(names) != 1 || names[0] != "renamed" {
+		t.Errorf("Stored documents after rename = %v, want only those of renamed", names)
+	}
+	if err := reloaded.DeleteIndex("renamed"); err != nil {
+		t.Fatalf("DeleteIndex() error = %v", err)
+	}
+	if names := documents.indexes(); len(names) != 0 {
+		t.Errorf("Stored documents after delete = %v, want none", names)
+	}
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// relevanceTestsFile stores the relevance tests of the data directory, as a JSON object of index name
+// to tests
+const relevanceTestsFile = "relevance_tests.json"
+
+// SetRelevanceTests replaces the relevance tests of an index. An empty list removes them. A test
+// without top_n checks the top model.DefaultRelevanceTopN hits, and top_n cannot exceed the largest
+// page size a search of the index can request.
+func (e *Engine) SetRelevanceTests(indexName string, tests []model.RelevanceTest) ([]model.RelevanceTest, error) {
+	if err := e.checkWritable("set relevance tests"); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	instance, exists := e.indexes[indexName]
+	if !exists {
+		return nil, e.missingIndexError(indexName)
+	}
+
+	limit := instance.settings.SearchPageSizeLimit()
+	stored := make([]model.RelevanceTest, len(tests))
+	for i, test := range tests {
+		field := fmt.Sprintf("tests[%d]", i)
+		if test.DocumentID == "" {
+			return nil, errors.NewValidationError(field+".document_id", "is required")
+		}
+		if test.TopN < 0 || test.TopN > limit {
+			return nil, errors.NewValidationError(field+".top_n", fmt.Sprintf("must be between 1 and %d", limit))
+		}
+		if test.TopN == 0 {
+			test.TopN = min(model.DefaultRelevanceTopN, limit)
+		}
+		stored[i] = test
+	}
+
+	previous, existed := e.relevanceTests[indexName]
+	if len(stored) == 0 {
+		delete(e.relevanceTests, indexName)
+	} else {
+		e.relevanceTests[indexName] = stored
+	}
+	if err := e.saveRelevanceTestsUnsafe(); err != nil {
+		if existed {
+			e.relevanceTests[indexName] = previous
+		} else {
+			delete(e.relevanceTests, indexName)
+		}
+		return nil, err
+	}
+	return stored, nil
+}
+
+// GetRelevanceTests returns the relevance tests of an index in the order they were set.
+func (e *Engine) GetRelevanceTests(indexName string) ([]model.RelevanceTest, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if !e.indexExistsUnsafe(indexName) {
+		return nil, errors.NewIndexNotFoundError(indexName)
+	}
+	tests := make([]model.RelevanceTest, len(e.relevanceTests[indexName]))
+	copy(tests, e.relevanceTests[indexName])
+	return tests, nil
+}
+
+// RunRelevanceTests searches the query of every relevance test of an index, with its current
+// settings and rules, and reports whether each document is among the top hits of its query. A test
+// whose query fails, e.g. because of its syntax, fails without stopping the run.
+func (e *Engine) RunRelevanceTests(indexName string) (model.RelevanceReport, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	tests := e.relevanceTests[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.RelevanceReport{}, e.missingIndexError(indexName)
+	}
+
+	report := model.RelevanceReport{
+		IndexName: indexName,
+		Passed:    true,
+		Total:     len(tests),
+		Results:   make([]model.RelevanceTestResult, 0, len(tests)),
+	}
+	for _, test := range tests {
+		result := model.RelevanceTestResult{RelevanceTest: test}
+		searchResult, err := instance.Search(services.SearchQuery{QueryString: test.Query, Page: 1, PageSize: test.TopN})
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Total = searchResult.Total
+			for i, hit := range searchResult.Hits {
+				if id, _ := hit.Document["documentID"].(string); id == test.DocumentID {
+					result.Passed = true
+					result.Position = i + 1
+					break
+				}
+			}
+		}
+		if !result.Passed {
+			report.Passed = false
+			report.Failures++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// renameRelevanceTestsUnsafe moves the relevance tests of a renamed index to its new name. The
+// caller must hold e.mu.
+func (e *Engine) renameRelevanceTestsUnsafe(oldName, newName string) {
+	tests, exists := e.relevanceTests[oldName]
+	if !exists {
+		return
+	}
+	delete(e.relevanceTests, oldName)
+	e.relevanceTests[newName] = tests
+	if err := e.saveRelevanceTestsUnsafe(); err != nil {
+		slog.Warn("failed to save the relevance tests of renamed index", "index", oldName, "new_index", newName, "error", err)
+	}
+}
+
+// dropRelevanceTestsUnsafe removes the relevance tests of a deleted index. The caller must hold e.mu.
+func (e *Engine) dropRelevanceTestsUnsafe(indexName string) {
+	if _, exists := e.relevanceTests[indexName]; !exists {
+		return
+	}
+	delete(e.relevanceTests, indexName)
+	if err := e.saveRelevanceTestsUnsafe(); err != nil {
+		slog.Warn("failed to save the relevance tests of deleted index", "index", indexName, "error", err)
+	}
+}
+
+// loadRelevanceTests reads the relevance tests file of the data directory, dropping the tests of
+// indexes that were not loaded. A missing file leaves the engine without relevance tests.
+func (e *Engine) loadRelevanceTests() {
+	data, err := os.ReadFile(filepath.Join(e.dataDir, relevanceTestsFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read relevance tests, starting without relevance tests", "data_dir", e.dataDir, "error", err)
+		}
+		return
+	}
+	var tests map[string][]model.RelevanceTest
+	if err := json.Unmarshal(data, &tests); err != nil {
+		slog.Warn("failed to unmarshal relevance tests, starting without relevance tests", "data_dir", e.dataDir, "error", err)
+		return
+	}
+	for indexName, indexTests := range tests {
+		if !e.indexExistsUnsafe(indexName) {
+			slog.Warn("dropping relevance tests of an index that was not loaded", "index", indexName)
+			continue
+		}
+		e.relevanceTests[indexName] = indexTests
+	}
+}
+
+// saveRelevanceTestsUnsafe writes the relevance tests file. The caller must hold e.mu.
+func (e *Engine) saveRelevanceTestsUnsafe() error {
+	data, err := json.MarshalIndent(e.relevanceTests, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal relevance tests: %w", err)
+	}
+	if err := os.MkdirAll(e.dataDir, dataDirPerm); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(e.dataDir, relevanceTestsFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write relevance tests file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestRelevanceTests(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	const indexName = "test-batch-index"
+
+	tests := []model.RelevanceTest{
+		{Name: "exact title", Query: "discontinued product", DocumentID: "2", TopN: 1},
+		{Query: "catalog", DocumentID: "1"},
+		{Query: "catalog", DocumentID: "2"},
+	}
+
+	if _, err := engine.SetRelevanceTests("missing-index", tests); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("SetRelevanceTests() on missing index error = %v, want ErrIndexNotFound", err)
+	}
+	for _, invalid := range []model.RelevanceTest{{Query: "catalog"}, {Query: "catalog", DocumentID: "1", TopN: 5000}} {
+		if _, err := engine.SetRelevanceTests(indexName, []model.RelevanceTest{invalid}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+			t.Errorf("SetRelevanceTests(%+v) error = %v, want ErrInvalidInput", invalid, err)
+		}
+	}
+
+	stored, err := engine.SetRelevanceTests(indexName, tests)
+	if err != nil {
+		t.Fatalf("SetRelevanceTests() error = %v", err)
+	}
+	if stored[0].TopN != 1 || stored[1].TopN != model.DefaultRelevanceTopN {
+		t.Errorf("SetRelevanceTests() = %+v, want top_n kept or defaulted", stored)
+	}
+
+	report, err := engine.RunRelevanceTests(indexName)
+	if err != nil {
+		t.Fatalf("RunRelevanceTests() error = %v", err)
+	}
+	if report.Passed || report.Total != 3 || report.Failures != 1 {
+		t.Errorf("RunRelevanceTests() = %+v, want 1 of 3 tests failing", report)
+	}
+	passed := []bool{report.Results[0].Passed, report.Results[1].Passed, report.Results[2].Passed}
+	if !reflect.DeepEqual(passed, []bool{true, true, false}) {
+		t.Errorf("Passed = %v, want [true true false]", passed)
+	}
+	if report.Results[0].Position != 1 || report.Results[2].Position != 0 {
+		t.Errorf("Positions = %d, %d, want 1 and 0", report.Results[0].Position, report.Results[2].Position)
+	}
+
+	// Relevance tests are kept across restarts, follow their index through renames and are removed with it
+	if err := engine.RenameIndex(indexName, "renamed-index"); err != nil {
+		t.Fatalf("RenameIndex() error = %v", err)
+	}
+	reloaded := NewEngine(engine.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	if got, err := reloaded.GetRelevanceTests("renamed-index"); err != nil || !reflect.DeepEqual(got, stored) {
+		t.Errorf("GetRelevanceTests() after rename and reload = %+v, %v, want %+v", got, err, stored)
+	}
+	if err := engine.DeleteIndex("renamed-index"); err != nil {
+		t.Fatalf("DeleteIndex() error = %v", err)
+	}
+	if got := len(engine.relevanceTests["renamed-index"]); got != 0 {
+		t.Errorf("%d relevance tests left after deleting the index, want 0", got)
+	}
+
+	if _, err := engine.RunRelevanceTests("renamed-index"); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("RunRelevanceTests() on deleted index error = %v, want ErrIndexNotFound", err)
+	}
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"log/slog"
+	"time"
+)
+
+// defaultRenameGracePeriod is how long requests addressed to the old name of a renamed index
+// are still routed to it.
+const defaultRenameGracePeriod = 5 * time.Minute
+
+// renameAlias routes an old index name to the index's current name until it expires.
+type renameAlias struct {
+	target    string
+	expiresAt time.Time
+}
+
+// SetRenameGracePeriod sets how long the old name of a renamed index keeps resolving to the
+// index. A zero duration disables the aliases for later renames.
+func (e *Engine) SetRenameGracePeriod(gracePeriod time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.renameGracePeriod = gracePeriod
+}
+
+// ResolveRenamedIndex returns the current name of an index that was recently renamed away
+// from name, and when the alias expires. It reports false when name is not such an alias.
+func (e *Engine) ResolveRenamedIndex(name string) (string, time.Time, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alias, exists := e.renameAliases[name]
+	if !exists || time.Now().After(alias.expiresAt) {
+		return "", time.Time{}, false
+	}
+	return alias.target, alias.expiresAt, true
+}
+
+// addRenameAliasUnsafe keeps routing oldName, and any alias of it, to newName for the grace
+// period. The caller must hold e.mu.
+func (e *Engine) addRenameAliasUnsafe(oldName, newName string) {
+	now := time.Now()
+	delete(e.renameAliases, newName)
+	for name, alias := range e.renameAliases {
+		switch {
+		case now.After(alias.expiresAt):
+			delete(e.renameAliases, name)
+		case alias.target == oldName:
+			alias.target = newName
+			e.renameAliases[name] = alias
+		}
+	}
+
+	if e.renameGracePeriod <= 0 {
+		return
+	}
+	e.renameAliases[oldName] = renameAlias{target: newName, expiresAt: now.Add(e.renameGracePeriod)}
+	slog.Info("requests to renamed index routed to its new name", "index", oldName, "new_index", newName, "grace_period", e.renameGracePeriod)
+}
+
+// dropRenameAliasesUnsafe removes the aliases that conflict with a created or deleted index:
+// the alias named after it and those routing to it. The caller must hold e.mu.
+func (e *Engine) dropRenameAliasesUnsafe(indexName string) {
+	delete(e.renameAliases, indexName)
+	for name, alias := range e.renameAliases {
+		if alias.target == indexName {
+			delete(e.renameAliases, name)
+		}
+	}
+}
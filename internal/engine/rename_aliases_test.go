@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+)
+
+func TestRenameAliases(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+
+	if _, _, ok := engine.ResolveRenamedIndex("test-batch-index"); ok {
+		t.Fatal("Expected no alias for an index that was never renamed")
+	}
+
+	before := time.Now()
+	if err := engine.RenameIndex("test-batch-index", "renamed"); err != nil {
+		t.Fatalf("RenameIndex() error = %v", err)
+	}
+	newName, expiresAt, ok := engine.ResolveRenamedIndex("test-batch-index")
+	if !ok || newName != "renamed" {
+		t.Fatalf("ResolveRenamedIndex() = %q, %v, want renamed", newName, ok)
+	}
+	if expiresAt.Before(before.Add(defaultRenameGracePeriod)) {
+		t.Errorf("Alias expires at %v, expected the default grace period", expiresAt)
+	}
+
+	// Renaming again keeps the first name routed to the latest one
+	if err := engine.RenameIndex("renamed", "renamed-again"); err != nil {
+		t.Fatalf("RenameIndex() error = %v", err)
+	}
+	for _, oldName := range []string{"test-batch-index", "renamed"} {
+		if newName, _, ok := engine.ResolveRenamedIndex(oldName); !ok || newName != "renamed-again" {
+			t.Errorf("ResolveRenamedIndex(%q) = %q, %v, want renamed-again", oldName, newName, ok)
+		}
+	}
+
+	// Creating an index with an old name takes the name back
+	if err := engine.CreateIndex(config.IndexSettings{Name: "renamed", SearchableFields: []string{"title"}}); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if _, _, ok := engine.ResolveRenamedIndex("renamed"); ok {
+		t.Error("Expected the alias to be dropped when an index takes its name")
+	}
+
+	// Deleting the index drops the aliases routing to it
+	if err := engine.DeleteIndex("renamed-again"); err != nil {
+		t.Fatalf("DeleteIndex() error = %v", err)
+	}
+	if _, _, ok := engine.ResolveRenamedIndex("test-batch-index"); ok {
+		t.Error("Expected the alias to be dropped when its index is deleted")
+	}
+
+	// A zero grace period disables aliases
+	engine.SetRenameGracePeriod(0)
+	if err := engine.RenameIndex("renamed", "renamed-without-alias"); err != nil {
+		t.Fatalf("RenameIndex() error = %v", err)
+	}
+	if _, _, ok := engine.ResolveRenamedIndex("renamed"); ok {
+		t.Error("Expected no alias with a zero grace period")
+	}
+}
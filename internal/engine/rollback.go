@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// RollbackAsync reverses the last ops document upserts and deletes of an index asynchronously,
+// restoring previous document versions. Only operations still held in the index's bounded
+// operation log can be reversed; the log is kept in memory and starts empty after a restart.
+func (e *Engine) RollbackAsync(indexName string, ops int) (string, error) {
+	if err := e.checkWritable("roll back index"); err != nil {
+		return "", err
+	}
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return "", e.missingIndexError(indexName)
+	}
+
+	if ops <= 0 {
+		return "", errors.NewValidationError("ops", "must be a positive number")
+	}
+	if available := instance.indexer.OperationLogLength(); ops > available {
+		return "", errors.NewValidationError("ops", fmt.Sprintf("only %d operation(s) can be rolled back", available))
+	}
+
+	jobID := e.jobManager.CreateJob(model.JobTypeRollback, indexName, map[string]string{
+		"operation": "rollback",
+		"ops":       fmt.Sprintf("%d", ops),
+	})
+
+	err := e.jobManager.ExecuteJob(jobID, func(ctx context.Context, job *model.Job) error {
+		return e.executeRollbackJob(ctx, indexName, ops, jobID)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start rollback job: %w", err)
+	}
+
+	return jobID, nil
+}
+
+// executeRollbackJob executes the rollback job.
+func (e *Engine) executeRollbackJob(ctx context.Context, indexName string, ops int, jobID string) error {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+
+	if !exists {
+		return e.missingIndexError(indexName)
+	}
+
+	e.jobManager.UpdateJobProgress(jobID, 0, ops, "Rolling back operations")
+
+	if err := instance.Rollback(ops); err != nil {
+		return fmt.Errorf("failed to roll back %d operation(s) on index '%s': %w", ops, indexName, err)
+	}
+
+	e.jobManager.UpdateJobProgress(jobID, ops, ops, "Operations rolled back, persisting to disk...")
+
+	// Persist the updated index
+	e.mu.RLock()
+	err := e.persistUpdatedIndexUnsafe(ctx, indexName, *instance.settings, instance)
+	e.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to persist updated index '%s': %w", indexName, err)
+	}
+
+	e.recordJobSeq(jobID, instance)
+	slog.Info("operations rolled back", "index", indexName, "operations", ops)
+	return nil
+}
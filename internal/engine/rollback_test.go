@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestEngine_RollbackAsync(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+
+	jobID, err := engine.AddDocumentsAsync("test-batch-index", []model.Document{
+		{"documentID": "1", "title": "Corrupted Entry"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+	if job := waitForJob(t, engine, jobID); job.Status != model.JobStatusCompleted {
+		t.Fatalf("Expected add job to complete, got %s: %s", job.Status, job.Error)
+	}
+	if total := searchTotal(t, indexAccessor, "corrupted"); total != 1 {
+		t.Fatalf("Expected corrupted document to be searchable, got %d hits", total)
+	}
+
+	jobID, err = engine.RollbackAsync("test-batch-index", 1)
+	if err != nil {
+		t.Fatalf("Failed to start rollback: %v", err)
+	}
+	job := waitForJob(t, engine, jobID)
+	if job.Status != model.JobStatusCompleted {
+		t.Fatalf("Expected rollback job to complete, got %s: %s", job.Status, job.Error)
+	}
+	if job.Type != model.JobTypeRollback {
+		t.Errorf("Expected job type %s, got %s", model.JobTypeRollback, job.Type)
+	}
+
+	if total := searchTotal(t, indexAccessor, "corrupted"); total != 0 {
+		t.Errorf("Expected corrupted version to be gone, got %d hits", total)
+	}
+	if total := searchTotal(t, indexAccessor, "catalog"); total != 1 {
+		t.Errorf("Expected previous version to be restored, got %d hits", total)
+	}
+
+	// Only logged operations can be rolled back
+	if _, err := engine.RollbackAsync("test-batch-index", 100); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+	if _, err := engine.RollbackAsync("missing-index", 1); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected ErrIndexNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/rules"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// MaxImportedRules is the most rules a bulk import can hold
+const MaxImportedRules = 10000
+
+// ListRules returns the merchandising rules of an index, in creation order unless the query sorts
+// them otherwise. Ties are listed in creation order. All rules are returned unless the query sets
+// a page or page size.
+func (e *Engine) ListRules(indexName string, query services.RuleListQuery) (model.RuleList, error) {
+	if err := e.requireIndex(indexName); err != nil {
+		return model.RuleList{}, err
+	}
+	var less func(a, b model.Rule) int
+	switch query.Sort {
+	case "", "created_at":
+	case "updated_at":
+		less = func(a, b model.Rule) int { return a.UpdatedAt.Compare(b.UpdatedAt) }
+	case "id":
+		less = func(a, b model.Rule) int { return strings.Compare(a.ID, b.ID) }
+	default:
+		return model.RuleList{}, errors.NewValidationError("sort", "must be created_at, updated_at or id")
+	}
+	if query.Order != "" && query.Order != "asc" && query.Order != "desc" {
+		return model.RuleList{}, errors.NewValidationError("order", "must be asc or desc")
+	}
+	if query.Page < 0 {
+		return model.RuleList{}, errors.NewValidationError("page", "must be at least 1")
+	}
+	if query.PageSize < 0 {
+		return model.RuleList{}, errors.NewValidationError("page_size", "must be at least 1")
+	}
+
+	list := e.ruleStore.ListRules(indexName) // In creation order
+	if less != nil {
+		sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) < 0 })
+	}
+	if query.Order == "desc" {
+		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+			list[i], list[j] = list[j], list[i]
+		}
+	}
+
+	result := model.RuleList{Rules: list, Total: len(list)}
+	if query.Page != 0 || query.PageSize != 0 {
+		result.Page, result.PageSize = max(query.Page, 1), query.PageSize
+		if result.PageSize == 0 {
+			result.PageSize = 10
+		}
+		start := min((result.Page-1)*result.PageSize, len(list))
+		result.Rules = list[start:min(start+result.PageSize, len(list))]
+		result.Pages = (len(list) + result.PageSize - 1) / result.PageSize
+	}
+	return result, nil
+}
+
+// GetRule returns a single rule of an index.
+func (e *Engine) GetRule(indexName, ruleID string) (model.Rule, error) {
+	if err := e.requireIndex(indexName); err != nil {
+		return model.Rule{}, err
+	}
+	return e.ruleStore.GetRule(indexName, ruleID)
+}
+
+// CreateRule validates and stores a new rule for an index. The rule is assigned a new ID and
+// applies to searches as soon as it is stored.
+func (e *Engine) CreateRule(indexName string, rule model.Rule) (model.Rule, error) {
+	if err := e.checkWritable("create rule"); err != nil {
+		return model.Rule{}, err
+	}
+	if err := e.requireIndex(indexName); err != nil {
+		return model.Rule{}, err
+	}
+	if err := rules.ValidateRule(rule); err != nil {
+		return model.Rule{}, err
+	}
+
+	now := time.Now()
+	rule.ID = uuid.New().String()
+	rule.IndexName = indexName
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	if rule.Condition.Match == "" {
+		rule.Condition.Match = model.RuleMatchExact
+	}
+
+	if err := e.ruleStore.SaveRule(rule); err != nil {
+		return model.Rule{}, err
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces the condition, actions, validity window and description of an existing rule.
+func (e *Engine) UpdateRule(indexName, ruleID string, rule model.Rule) (model.Rule, error) {
+	if err := e.checkWritable("update rule"); err != nil {
+		return model.Rule{}, err
+	}
+	if err := e.requireIndex(indexName); err != nil {
+		return model.Rule{}, err
+	}
+	existing, err := e.ruleStore.GetRule(indexName, ruleID)
+	if err != nil {
+		return model.Rule{}, err
+	}
+	if err := rules.ValidateRule(rule); err != nil {
+		return model.Rule{}, err
+	}
+
+	rule.ID = existing.ID
+	rule.IndexName = indexName
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now()
+	if rule.Condition.Match == "" {
+		rule.Condition.Match = model.RuleMatchExact
+	}
+
+	if err := e.ruleStore.SaveRule(rule); err != nil {
+		return model.Rule{}, err
+	}
+	return rule, nil
+}
+
+// ImportRules validates and stores many rules of an index at once, such as those exported from
+// another environment. Imported rules keep their ID, replacing the rule of the index with the same
+// ID, and their creation time; rules without an ID get a new one. With replace, rules of the index
+// missing from the import are deleted. Nothing is stored unless every rule is valid.
+func (e *Engine) ImportRules(indexName string, imported []model.Rule, replace bool) (model.RuleImport, error) {
+	if err := e.checkWritable("import rules"); err != nil {
+		return model.RuleImport{}, err
+	}
+	if err := e.requireIndex(indexName); err != nil {
+		return model.RuleImport{}, err
+	}
+	if len(imported) > MaxImportedRules {
+		return model.RuleImport{}, errors.NewValidationError("rules", fmt.Sprintf("cannot import more than %d rules at once", MaxImportedRules))
+	}
+
+	existing := make(map[string]model.Rule)
+	for _, rule := range e.ruleStore.ListRules(indexName) {
+		existing[rule.ID] = rule
+	}
+	final := make(map[string]model.Rule, len(existing)+len(imported))
+	if !replace {
+		for id, rule := range existing {
+			final[id] = rule
+		}
+	}
+
+	var result model.RuleImport
+	now := time.Now()
+	seen := make(map[string]bool, len(imported))
+	for i, rule := range imported {
+		if err := rules.ValidateRule(rule); err != nil {
+			if validationErr, ok := err.(*errors.ValidationError); ok {
+				return model.RuleImport{}, errors.NewValidationError(fmt.Sprintf("rules[%d].%s", i, validationErr.Field), validationErr.Message)
+			}
+			return model.RuleImport{}, err
+		}
+		if rule.ID == "" {
+			rule.ID = uuid.New().String()
+		} else if seen[rule.ID] {
+			return model.RuleImport{}, errors.NewValidationError(fmt.Sprintf("rules[%d].id", i), fmt.Sprintf("'%s' is repeated", rule.ID))
+		}
+		seen[rule.ID] = true
+
+		rule.IndexName = indexName
+		if rule.CreatedAt.IsZero() {
+			rule.CreatedAt = now.Add(time.Duration(i)) // New rules list in the order of the import
+		}
+		rule.UpdatedAt = now
+		if rule.Condition.Match == "" {
+			rule.Condition.Match = model.RuleMatchExact
+		}
+		if _, exists := existing[rule.ID]; exists {
+			result.Updated++
+		} else {
+			result.Created++
+		}
+		final[rule.ID] = rule
+	}
+	if replace {
+		for id := range existing {
+			if !seen[id] {
+				result.Deleted++
+			}
+		}
+	}
+
+	list := make([]model.Rule, 0, len(final))
+	for _, rule := range final {
+		list = append(list, rule)
+	}
+	if err := e.ruleStore.ReplaceIndexRules(indexName, list); err != nil {
+		return model.RuleImport{}, err
+	}
+	result.Total = len(list)
+	return result, nil
+}
+
+// DeleteRule removes a rule from an index.
+func (e *Engine) DeleteRule(indexName, ruleID string) error {
+	if err := e.checkWritable("delete rule"); err != nil {
+		return err
+	}
+	if err := e.requireIndex(indexName); err != nil {
+		return err
+	}
+	return e.ruleStore.DeleteRule(indexName, ruleID)
+}
+
+// requireIndex returns an IndexNotFoundError if the index does not exist.
+func (e *Engine) requireIndex(indexName string) error {
+	e.mu.RLock()
+	_, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return e.missingIndexError(indexName)
+	}
+	return nil
+}
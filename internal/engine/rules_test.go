@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestRules(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	const indexName = "test-batch-index"
+
+	pin := model.Rule{
+		Condition: model.RuleCondition{Query: "discontinued product"},
+		Actions:   []model.RuleAction{{Type: model.RuleActionPin, DocumentIDs: []string{"1"}}},
+	}
+
+	if _, err := engine.CreateRule("missing-index", pin); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("CreateRule() on missing index error = %v, want ErrIndexNotFound", err)
+	}
+	if _, err := engine.CreateRule(indexName, model.Rule{}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("CreateRule() of invalid rule error = %v, want ErrInvalidInput", err)
+	}
+
+	created, err := engine.CreateRule(indexName, pin)
+	if err != nil {
+		t.Fatalf("CreateRule() error = %v", err)
+	}
+	if created.ID == "" || created.IndexName != indexName || created.Condition.Match != model.RuleMatchExact {
+		t.Errorf("CreateRule() = %+v, want ID, index name and default match type set", created)
+	}
+
+	// The rule applies to searches right away
+	result, err := indexAccessor.Search(services.SearchQuery{QueryString: "discontinued product", PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(result.AppliedRules) != 1 || result.AppliedRules[0].RuleID != created.ID {
+		t.Errorf("AppliedRules = %+v, want the created rule", result.AppliedRules)
+	}
+
+	update := pin
+	update.Actions = []model.RuleAction{{Type: model.RuleActionHide, DocumentIDs: []string{"2"}}}
+	updated, err := engine.UpdateRule(indexName, created.ID, update)
+	if err != nil {
+		t.Fatalf("UpdateRule() error = %v", err)
+	}
+	if updated.ID != created.ID || !updated.CreatedAt.Equal(created.CreatedAt) || updated.Actions[0].Type != model.RuleActionHide {
+		t.Errorf("UpdateRule() = %+v, want same ID and creation time with new actions", updated)
+	}
+
+	// Rules follow their index through renames and are removed with it
+	if err := engine.RenameIndex(indexName, "renamed-index"); err != nil {
+		t.Fatalf("RenameIndex() error = %v", err)
+	}
+	if _, err := engine.GetRule("renamed-index", created.ID); err != nil {
+		t.Errorf("GetRule() after rename error = %v", err)
+	}
+	if err := engine.DeleteIndex("renamed-index"); err != nil {
+		t.Fatalf("DeleteIndex() error = %v", err)
+	}
+	if got := len(engine.ruleStore.ListRules("renamed-index")); got != 0 {
+		t.Errorf("%d rules left after deleting the index, want 0", got)
+	}
+}
+
+func TestRuleListAndImport(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	const indexName = "test-batch-index"
+
+	rule := func(id, query string) model.Rule {
+		return model.Rule{
+			ID:        id,
+			Condition: model.RuleCondition{Query: query},
+			Actions:   []model.RuleAction{{Type: model.RuleActionHide, DocumentIDs: []string{"1"}}},
+		}
+	}
+
+	if _, err := engine.ImportRules(indexName, []model.Rule{rule("a", "one"), {}}, false); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("ImportRules() of invalid rule error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := engine.ImportRules(indexName, []model.Rule{rule("a", "one"), rule("a", "two")}, false); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("ImportRules() of repeated IDs error = %v, want ErrInvalidInput", err)
+	}
+
+	result, err := engine.ImportRules(indexName, []model.Rule{rule("c", "one"), rule("a", "two"), rule("", "three")}, false)
+	if err != nil {
+		t.Fatalf("ImportRules() error = %v", err)
+	}
+	if result != (model.RuleImport{Created: 3, Total: 3}) {
+		t.Errorf("ImportRules() = %+v, want 3 created", result)
+	}
+
+	list, err := engine.ListRules(indexName, services.RuleListQuery{})
+	if err != nil {
+		t.Fatalf("ListRules() error = %v", err)
+	}
+	if list.Total != 3 || len(list.Rules) != 3 || list.Rules[0].ID != "c" || list.Rules[1].ID != "a" || list.Rules[2].Condition.Match != model.RuleMatchExact {
+		t.Errorf("ListRules() = %+v, want the 3 rules in import order", list)
+	}
+
+	page, err := engine.ListRules(indexName, services.RuleListQuery{Sort: "id", Order: "desc", Page: 1, PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListRules() page error = %v", err)
+	}
+	if page.Total != 3 || page.Pages != 2 || len(page.Rules) != 2 || page.Rules[0].ID < page.Rules[1].ID {
+		t.Errorf("ListRules() page = %+v, want the first 2 of 3 rules by descending ID", page)
+	}
+	if _, err := engine.ListRules(indexName, services.RuleListQuery{Order: "up"}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("ListRules() with unknown order error = %v, want ErrInvalidInput", err)
+	}
+
+	// Replacing keeps the imported rules only
+	result, err = engine.ImportRules(indexName, []model.Rule{rule("a", "updated"), rule("d", "four")}, true)
+	if err != nil {
+		t.Fatalf("ImportRules() replace error = %v", err)
+	}
+	if result != (model.RuleImport{Created: 1, Updated: 1, Deleted: 2, Total: 2}) {
+		t.Errorf("ImportRules() replace = %+v, want 1 created, 1 updated and 2 deleted", result)
+	}
+	updated, err := engine.GetRule(indexName, "a")
+	if err != nil || updated.Condition.Query != "updated" {
+		t.Errorf("GetRule() = %+v, %v, want the imported version of the rule", updated, err)
+	}
+}
@@ -0,0 +1,270 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// safeModeAlertTimeout bounds how long posting a revert alert to the safe mode webhook may take.
+const safeModeAlertTimeout = 10 * time.Second
+
+// safeModeMonitor watches the searches of an index after a settings update under safe mode, and
+// hands the update over to be reverted once a rate exceeds its maximum.
+type safeModeMonitor struct {
+	mu          sync.Mutex
+	config      config.SafeMode
+	previous    config.IndexSettings // Settings in effect before the update, restored on a spike
+	applied     config.IndexSettings // Settings applied by the update
+	startedAt   time.Time
+	state       model.SafeModeState
+	searches    int64
+	errors      int64
+	zeroResults int64
+	endedAt     time.Time
+	reason      string
+	revertJobID string
+
+	stop    chan struct{}          // Closed when monitoring ends
+	onSpike func(*safeModeMonitor) // Called once, in its own goroutine, when the monitor ends with a spike
+}
+
+// startSafeMode watches the index's searches after a settings update replacing previous, if
+// previous has safe mode enabled. Monitoring of an earlier update is superseded either way. The
+// caller must hold e.mu.
+func (e *Engine) startSafeMode(instance *IndexInstance, previous config.IndexSettings) {
+	if previous.SafeMode == nil {
+		if monitor := instance.safeMode.Load(); monitor != nil {
+			monitor.end(model.SafeModeSuperseded)
+		}
+		return
+	}
+
+	monitor := &safeModeMonitor{
+		config:    *previous.SafeMode,
+		previous:  previous,
+		applied:   *instance.settings,
+		startedAt: time.Now(),
+		state:     model.SafeModeMonitoring,
+		stop:      make(chan struct{}),
+		onSpike: func(monitor *safeModeMonitor) {
+			e.revertSettings(instance, monitor)
+		},
+	}
+	instance.replaceSafeMode(monitor)
+	go monitor.watch()
+
+	slog.Info("safe mode watching searches after a settings update", "index", instance.settings.Name, "window", monitor.config.Window())
+}
+
+// revertSettings restores the settings in effect before the update the monitor watched, unless the
+// index was deleted or its settings changed again since, and sends the revert alert.
+func (e *Engine) revertSettings(instance *IndexInstance, monitor *safeModeMonitor) {
+	var name string
+	var current config.IndexSettings
+	e.mu.RLock()
+	for indexName, candidate := range e.indexes {
+		if candidate == instance {
+			name, current = indexName, *instance.settings
+		}
+	}
+	e.mu.RUnlock()
+
+	if name == "" {
+		return
+	}
+	// The index may have been renamed since the update
+	current.Name = monitor.applied.Name
+	if !reflect.DeepEqual(current, monitor.applied) {
+		slog.Warn("safe mode did not revert settings that changed again after the update", "index", name, "reason", monitor.reason)
+		return
+	}
+
+	previous := monitor.previous
+	previous.Name = name
+	jobID, err := e.submitSettingsUpdate(name, previous, false)
+	if err != nil {
+		slog.Error("safe mode failed to revert settings", "index", name, "error", err)
+		return
+	}
+
+	monitor.mu.Lock()
+	monitor.revertJobID = jobID
+	stats := monitor.statsUnsafe()
+	monitor.mu.Unlock()
+
+	slog.Warn("safe mode reverting settings", "index", name, "job_id", jobID, "reason", stats.Reason)
+	if monitor.config.WebhookURL != "" {
+		postSafeModeAlert(monitor.config.WebhookURL, model.SafeModeAlert{
+			Event:          "settings_reverted",
+			IndexName:      name,
+			Reason:         stats.Reason,
+			Searches:       stats.Searches,
+			ErrorRate:      stats.ErrorRate,
+			ZeroResultRate: stats.ZeroResultRate,
+			RevertJobID:    jobID,
+			RevertedAt:     *stats.EndedAt,
+		})
+	}
+}
+
+// postSafeModeAlert posts a revert alert to a webhook. Failures are logged only.
+func postSafeModeAlert(url string, alert model.SafeModeAlert) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		slog.Warn("failed to encode safe mode alert", "error", err)
+		return
+	}
+	client := &http.Client{Timeout: safeModeAlertTimeout}
+	response, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		slog.Warn("failed to post safe mode alert", "url", url, "error", err)
+		return
+	}
+	_ = response.Body.Close()
+	if response.StatusCode >= 300 {
+		slog.Warn("safe mode webhook answered with an error status", "url", url, "status", response.StatusCode)
+	}
+}
+
+// watch ends the monitoring as passed once the window elapses, unless it ended before.
+func (m *safeModeMonitor) watch() {
+	timer := time.NewTimer(m.config.Window())
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		m.end(model.SafeModePassed)
+	case <-m.stop:
+	}
+}
+
+// record counts one search, and ends the monitoring with a spike once a rate exceeds its maximum.
+func (m *safeModeMonitor) record(failed bool, zeroResults bool) {
+	m.mu.Lock()
+	if m.state != model.SafeModeMonitoring {
+		m.mu.Unlock()
+		return
+	}
+	m.searches++
+	if failed {
+		m.errors++
+	} else if zeroResults {
+		m.zeroResults++
+	}
+
+	reason := m.spikeUnsafe()
+	if reason == "" {
+		m.mu.Unlock()
+		return
+	}
+	m.reason = reason
+	m.endUnsafe(model.SafeModeReverted)
+	m.mu.Unlock()
+
+	go m.onSpike(m)
+}
+
+// spikeUnsafe describes the rate exceeding its maximum, or returns "" if none does yet. The
+// caller must hold m.mu.
+func (m *safeModeMonitor) spikeUnsafe() string {
+	if m.searches < int64(m.config.Searches()) {
+		return ""
+	}
+	if rate := ratio(m.errors, m.searches); rate > m.config.ErrorRate() {
+		return fmt.Sprintf("error rate %.1f%% over %d searches exceeded the maximum of %.1f%%", rate*100, m.searches, m.config.ErrorRate()*100)
+	}
+	if rate := ratio(m.zeroResults, m.searches); rate > m.config.ZeroResultRate() {
+		return fmt.Sprintf("zero-result rate %.1f%% over %d searches exceeded the maximum of %.1f%%", rate*100, m.searches, m.config.ZeroResultRate()*100)
+	}
+	return ""
+}
+
+// end ends the monitoring in the given state, unless it already ended.
+func (m *safeModeMonitor) end(state model.SafeModeState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state == model.SafeModeMonitoring {
+		m.endUnsafe(state)
+	}
+}
+
+// endUnsafe ends the monitoring in the given state. The caller must hold m.mu.
+func (m *safeModeMonitor) endUnsafe(state model.SafeModeState) {
+	m.state = state
+	m.endedAt = time.Now()
+	close(m.stop)
+}
+
+// statsUnsafe returns a snapshot of the monitoring. The caller must hold m.mu.
+func (m *safeModeMonitor) statsUnsafe() model.SafeModeStats {
+	stats := model.SafeModeStats{
+		State:          m.state,
+		StartedAt:      m.startedAt,
+		WindowMs:       m.config.Window().Milliseconds(),
+		Searches:       m.searches,
+		Errors:         m.errors,
+		ZeroResults:    m.zeroResults,
+		ErrorRate:      ratio(m.errors, m.searches),
+		ZeroResultRate: ratio(m.zeroResults, m.searches),
+		Reason:         m.reason,
+		RevertJobID:    m.revertJobID,
+	}
+	if !m.endedAt.IsZero() {
+		endedAt := m.endedAt
+		stats.EndedAt = &endedAt
+	}
+	return stats
+}
+
+// ratio returns part over total, or 0 when total is 0.
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// recordSafeModeSearch counts a search of the index for the monitoring of its latest settings
+// update, if it is being watched.
+func (i *IndexInstance) recordSafeModeSearch(failed bool, zeroResults bool) {
+	if monitor := i.safeMode.Load(); monitor != nil {
+		monitor.record(failed, zeroResults)
+	}
+}
+
+// replaceSafeMode makes monitor watch the index's searches, superseding the monitoring of an
+// earlier settings update.
+func (i *IndexInstance) replaceSafeMode(monitor *safeModeMonitor) {
+	if old := i.safeMode.Swap(monitor); old != nil {
+		old.end(model.SafeModeSuperseded)
+	}
+}
+
+// closeSafeMode stops watching the index's searches, e.g. when the index is deleted.
+func (i *IndexInstance) closeSafeMode() {
+	if monitor := i.safeMode.Swap(nil); monitor != nil {
+		monitor.end(model.SafeModeSuperseded)
+	}
+}
+
+// SafeModeStats describes the monitoring of the index's latest settings update under safe mode,
+// or returns nil if no update was monitored.
+func (i *IndexInstance) SafeModeStats() *model.SafeModeStats {
+	monitor := i.safeMode.Load()
+	if monitor == nil {
+		return nil
+	}
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	stats := monitor.statsUnsafe()
+	return &stats
+}
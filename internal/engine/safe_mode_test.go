@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// updateSettingsAndWait updates the settings of the batch test index asynchronously and waits for the job.
+func updateSettingsAndWait(t *testing.T, engine *Engine, settings config.IndexSettings) {
+	t.Helper()
+	jobID, err := engine.UpdateIndexSettingsWithAsyncReindex("test-batch-index", settings)
+	if err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if job := waitForJob(t, engine, jobID); job.Status != model.JobStatusCompleted {
+		t.Fatalf("Settings update failed: %s", job.Error)
+	}
+}
+
+func TestEngine_SafeModeRevertsOnZeroResultSpike(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+
+	alerts := make(chan model.SafeModeAlert, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert model.SafeModeAlert
+		_ = json.NewDecoder(r.Body).Decode(&alert)
+		alerts <- alert
+	}))
+	defer webhook.Close()
+
+	settings := instance.Settings()
+	settings.SafeMode = &config.SafeMode{WindowMs: 60000, MinSearches: 4, MaxZeroResultRate: 0.5, WebhookURL: webhook.URL}
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to enable safe mode: %v", err)
+	}
+	if stats := instance.SafeModeStats(); stats != nil {
+		t.Fatalf("Expected no monitoring before an asynchronous settings update, got %+v", stats)
+	}
+
+	updated := settings
+	updated.DefaultPageSize = 3
+	updateSettingsAndWait(t, engine, updated)
+	if stats := instance.SafeModeStats(); stats == nil || stats.State != model.SafeModeMonitoring {
+		t.Fatalf("Expected the update to be monitored, got %+v", stats)
+	}
+
+	// 2 of 4 searches without results stay within the 50% maximum; the fifth exceeds it
+	for _, query := range []string{"catalog", "product", "missing", "absent"} {
+		searchTotal(t, indexAccessor, query)
+	}
+	if stats := instance.SafeModeStats(); stats.State != model.SafeModeMonitoring || stats.ZeroResults != 2 {
+		t.Fatalf("Expected monitoring to go on at a 50%% zero-result rate, got %+v", stats)
+	}
+	searchTotal(t, indexAccessor, "nowhere")
+
+	var alert model.SafeModeAlert
+	select {
+	case alert = <-alerts:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a revert alert to be posted to the webhook")
+	}
+	if alert.Event != "settings_reverted" || alert.IndexName != "test-batch-index" || alert.Searches != 5 || alert.ZeroResultRate != 0.6 || alert.RevertJobID == "" {
+		t.Errorf("Unexpected alert: %+v", alert)
+	}
+
+	job := waitForJob(t, engine, alert.RevertJobID)
+	if job.Status != model.JobStatusCompleted || job.Metadata["trigger"] != "safe_mode_revert" {
+		t.Fatalf("Expected a completed revert job, got %+v", job)
+	}
+	if pageSize := instance.Settings().DefaultPageSize; pageSize != settings.DefaultPageSize {
+		t.Errorf("Expected the previous default page size %d to be restored, got %d", settings.DefaultPageSize, pageSize)
+	}
+	stats := instance.SafeModeStats()
+	if stats.State != model.SafeModeReverted || stats.RevertJobID != alert.RevertJobID || stats.Reason == "" || stats.EndedAt == nil {
+		t.Errorf("Expected the monitoring to report the revert, got %+v", stats)
+	}
+
+	// The revert itself is not monitored
+	searchTotal(t, indexAccessor, "missing")
+	if after := instance.SafeModeStats(); after.Searches != stats.Searches {
+		t.Errorf("Expected searches after the revert not to be counted, got %+v", after)
+	}
+}
+
+func TestEngine_SafeModeWindow(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+
+	settings := instance.Settings()
+	settings.SafeMode = &config.SafeMode{WindowMs: 60000, MinSearches: 1}
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to enable safe mode: %v", err)
+	}
+
+	settings.DefaultPageSize = 3
+	updateSettingsAndWait(t, engine, settings)
+	first := instance.safeMode.Load()
+
+	// A later update supersedes the monitoring of the first one
+	settings.SafeMode = &config.SafeMode{WindowMs: 20, MinSearches: 1}
+	updateSettingsAndWait(t, engine, settings)
+	first.mu.Lock()
+	firstState := first.state
+	first.mu.Unlock()
+	if firstState != model.SafeModeSuperseded {
+		t.Errorf("Expected the first monitoring to be superseded, got %s", firstState)
+	}
+
+	// The second update is monitored with the safe mode in effect before it, for a minute
+	searchTotal(t, indexAccessor, "catalog")
+	if stats := instance.SafeModeStats(); stats.State != model.SafeModeMonitoring || stats.WindowMs != 60000 || stats.Searches != 1 {
+		t.Fatalf("Expected the second update to be monitored for a minute, got %+v", stats)
+	}
+
+	// The third is monitored for 20ms, and passes when no search fails
+	settings.DefaultPageSize = 4
+	updateSettingsAndWait(t, engine, settings)
+	deadline := time.Now().Add(2 * time.Second)
+	for instance.SafeModeStats().State != model.SafeModePassed {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the monitoring to pass after its window, got %+v", instance.SafeModeStats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := indexAccessor.Search(services.SearchQuery{QueryString: "missing"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if stats := instance.SafeModeStats(); stats.Searches != 0 || instance.Settings().DefaultPageSize != 4 {
+		t.Errorf("Expected searches after the window not to be counted, got %+v", stats)
+	}
+}
@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// searchExportRetention is how long export files are kept, as long as the jobs that wrote them
+const searchExportRetention = 24 * time.Hour
+
+// searchExport is the file written by a search export job.
+type searchExport struct {
+	info model.SearchExport
+	path string // Set once the job has written the file
+}
+
+// ExportSearchAsync writes every hit of a query to a CSV or NDJSON file in the background, keeping
+// only the given document fields when any are given. CSV exports without fields have a column for
+// the document ID and each searchable field. The query is run once first, so invalid queries are
+// rejected before the job is started. The file can be downloaded with OpenSearchExport.
+func (e *Engine) ExportSearchAsync(indexName string, query services.SearchQuery, format model.SearchExportFormat, fields []string) (string, error) {
+	if err := e.checkWritable("export search"); err != nil {
+		return "", err
+	}
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return "", e.missingIndexError(indexName)
+	}
+
+	switch format {
+	case "":
+		format = model.SearchExportCSV
+	case model.SearchExportCSV, model.SearchExportNDJSON:
+	default:
+		return "", errors.NewValidationError("format", fmt.Sprintf("'%s' is not supported; supported: csv, ndjson", format))
+	}
+	for _, field := range fields {
+		if strings.TrimSpace(field) == "" {
+			return "", errors.NewValidationError("fields", "cannot contain an empty field")
+		}
+	}
+	if format == model.SearchExportCSV && len(fields) == 0 {
+		fields = append([]string{"documentID"}, instance.Settings().SearchableFields...)
+	}
+	if len(fields) > 0 {
+		query.RetrievableFields = fields // Exported fields are retrieved even if the index leaves them out by default
+	}
+	query.IDsOnly = false // Exports write the documents of the hits
+
+	probe := query
+	probe.Page, probe.PageSize = 1, 1
+	if _, err := instance.Search(probe); err != nil {
+		return "", err
+	}
+
+	jobID := e.jobManager.CreateJob(model.JobTypeSearchExport, indexName, map[string]string{
+		"operation": "search_export",
+		"format":    string(format),
+		"query":     query.QueryString,
+	})
+
+	e.exportsMu.Lock()
+	e.removeExpiredExportsUnsafe(time.Now())
+	e.exports[jobID] = &searchExport{info: model.SearchExport{
+		JobID:     jobID,
+		IndexName: indexName,
+		Format:    format,
+		Fields:    fields,
+		CreatedAt: time.Now(),
+	}}
+	e.exportsMu.Unlock()
+
+	err := e.jobManager.ExecuteJob(jobID, func(ctx context.Context, job *model.Job) error {
+		return e.executeSearchExportJob(ctx, indexName, query, format, fields, jobID)
+	})
+	if err != nil {
+		e.exportsMu.Lock()
+		delete(e.exports, jobID)
+		e.exportsMu.Unlock()
+		return "", fmt.Errorf("failed to start search export job: %w", err)
+	}
+
+	return jobID, nil
+}
+
+// executeSearchExportJob executes the search export job.
+func (e *Engine) executeSearchExportJob(_ context.Context, indexName string, query services.SearchQuery, format model.SearchExportFormat, fields []string, jobID string) error {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return e.missingIndexError(indexName)
+	}
+
+	result, err := instance.searchAll(query)
+	if err != nil {
+		return fmt.Errorf("failed to search index '%s': %w", indexName, err)
+	}
+	e.jobManager.UpdateJobProgress(jobID, 0, len(result.Hits), "Writing hits")
+
+	file, err := os.CreateTemp("", "search-export-*."+string(format))
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	err = writeSearchExport(writer, format, fields, result.Hits)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	var size int64
+	if err == nil {
+		var stat os.FileInfo
+		if stat, err = os.Stat(file.Name()); err == nil {
+			size = stat.Size()
+		}
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	e.exportsMu.Lock()
+	export, tracked := e.exports[jobID]
+	if tracked {
+		export.path = file.Name()
+		export.info.Hits = len(result.Hits)
+		export.info.Bytes = size
+	}
+	e.exportsMu.Unlock()
+	if !tracked {
+		_ = os.Remove(file.Name())
+	}
+
+	e.jobManager.UpdateJobProgress(jobID, len(result.Hits), len(result.Hits), "Export written")
+	slog.Info("search results exported", "index", indexName, "hits", len(result.Hits), "format", format, "bytes", size)
+	return nil
+}
+
+// OpenSearchExport opens the file written by a completed search export job of the index. The caller
+// must close it.
+func (e *Engine) OpenSearchExport(indexName, jobID string) (model.SearchExport, io.ReadCloser, error) {
+	e.exportsMu.Lock()
+	e.removeExpiredExportsUnsafe(time.Now())
+	export, exists := e.exports[jobID]
+	var info model.SearchExport
+	var path string
+	if exists {
+		info, path = export.info, export.path
+	}
+	e.exportsMu.Unlock()
+
+	if !exists || info.IndexName != indexName {
+		return model.SearchExport{}, nil, errors.NewJobNotFoundError(jobID)
+	}
+	if path == "" {
+		job, err := e.jobManager.GetJob(jobID)
+		if err == nil && job.Status == model.JobStatusFailed {
+			return model.SearchExport{}, nil, errors.NewValidationError("job", fmt.Sprintf("search export job '%s' failed: %s", jobID, job.Error))
+		}
+		return model.SearchExport{}, nil, errors.NewValidationError("job", fmt.Sprintf("search export job '%s' has not completed yet", jobID))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return model.SearchExport{}, nil, fmt.Errorf("failed to open export file of job '%s': %w", jobID, err)
+	}
+	return info, file, nil
+}
+
+// removeExpiredExportsUnsafe deletes the exports older than searchExportRetention and their files.
+func (e *Engine) removeExpiredExportsUnsafe(now time.Time) {
+	for jobID, export := range e.exports {
+		if now.Sub(export.info.CreatedAt) <= searchExportRetention {
+			continue
+		}
+		if export.path != "" {
+			if err := os.Remove(export.path); err != nil && !os.IsNotExist(err) {
+				slog.Warn("failed to remove export file", "path", export.path, "error", err)
+			}
+		}
+		delete(e.exports, jobID)
+	}
+}
+
+// writeSearchExport writes the hits in the format, keeping only the given fields of each document
+// when any are given.
+func writeSearchExport(w io.Writer, format model.SearchExportFormat, fields []string, hits []services.HitResult) error {
+	if format == model.SearchExportNDJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		for _, hit := range hits {
+			doc := hit.Document
+			if len(fields) > 0 {
+				doc = make(model.Document, len(fields))
+				for _, field := range fields {
+					if value, ok := hit.Document[field]; ok {
+						doc[field] = value
+					}
+				}
+			}
+			if err := encoder.Encode(doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(fields); err != nil {
+		return err
+	}
+	record := make([]string, len(fields))
+	for _, hit := range hits {
+		for i, field := range fields {
+			record[i] = csvValue(hit.Document[field])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvValue formats a document value as a CSV cell: strings as they are, missing values empty, and
+// other values as JSON.
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
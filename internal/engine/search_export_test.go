@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func readSearchExport(t *testing.T, engine *Engine, jobID string) (model.SearchExport, string) {
+	t.Helper()
+	if job := waitForJob(t, engine, jobID); job.Status != model.JobStatusCompleted {
+		t.Fatalf("Expected the export job to complete, got %s: %s", job.Status, job.Error)
+	}
+	export, file, err := engine.OpenSearchExport("test-batch-index", jobID)
+	if err != nil {
+		t.Fatalf("Failed to open export: %v", err)
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	return export, string(content)
+}
+
+func TestEngine_ExportSearch(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	t.Cleanup(func() {
+		engine.exportsMu.Lock()
+		defer engine.exportsMu.Unlock()
+		for _, export := range engine.exports {
+			_ = os.Remove(export.path)
+		}
+	})
+	if err := indexAccessor.AddDocuments([]model.Document{
+		{"documentID": "3", "title": "Catalog, \"Spring\" Edition", "year": 2024, "tags": []interface{}{"new"}},
+	}); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	jobID, err := engine.ExportSearchAsync("test-batch-index", services.SearchQuery{QueryString: "catalog"}, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to start export: %v", err)
+	}
+	export, content := readSearchExport(t, engine, jobID)
+	if export.Format != model.SearchExportCSV || export.Hits != 2 || export.Bytes != int64(len(content)) {
+		t.Errorf("Unexpected export: %+v", export)
+	}
+	for _, row := range []string{"documentID,title\n", "1,Old Catalog Entry\n", "3,\"Catalog, \"\"Spring\"\" Edition\"\n"} {
+		if !contains(content, row) {
+			t.Errorf("Expected CSV row %q in:\n%s", row, content)
+		}
+	}
+
+	jobID, err = engine.ExportSearchAsync("test-batch-index", services.SearchQuery{QueryString: "spring"}, model.SearchExportCSV, []string{"documentID", "year", "tags", "missing"})
+	if err != nil {
+		t.Fatalf("Failed to start export: %v", err)
+	}
+	if _, content = readSearchExport(t, engine, jobID); content != "documentID,year,tags,missing\n3,2024,\"[\"\"new\"\"]\",\n" {
+		t.Errorf("Unexpected CSV export:\n%s", content)
+	}
+
+	jobID, err = engine.ExportSearchAsync("test-batch-index", services.SearchQuery{QueryString: "spring"}, model.SearchExportNDJSON, []string{"documentID", "year"})
+	if err != nil {
+		t.Fatalf("Failed to start export: %v", err)
+	}
+	if _, content = readSearchExport(t, engine, jobID); content != "{\"documentID\":\"3\",\"year\":2024}\n" {
+		t.Errorf("Unexpected NDJSON export:\n%s", content)
+	}
+
+	if _, _, err := engine.OpenSearchExport("other-index", jobID); !errors.Is(err, internalErrors.ErrJobNotFound) {
+		t.Errorf("Expected job not found for an export of another index, got %v", err)
+	}
+
+	// Exports are removed with their jobs
+	engine.exportsMu.Lock()
+	path := engine.exports[jobID].path
+	engine.removeExpiredExportsUnsafe(time.Now().Add(searchExportRetention + time.Minute))
+	engine.exportsMu.Unlock()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the expired export file to be removed, got %v", err)
+	}
+	if _, _, err := engine.OpenSearchExport("test-batch-index", jobID); !errors.Is(err, internalErrors.ErrJobNotFound) {
+		t.Errorf("Expected job not found for an expired export, got %v", err)
+	}
+}
+
+func TestEngine_ExportSearchErrors(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+
+	if _, err := engine.ExportSearchAsync("missing", services.SearchQuery{QueryString: "catalog"}, "", nil); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected index not found, got %v", err)
+	}
+	if _, err := engine.ExportSearchAsync("test-batch-index", services.SearchQuery{QueryString: "catalog"}, "xlsx", nil); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected invalid input for an unsupported format, got %v", err)
+	}
+	if _, err := engine.ExportSearchAsync("test-batch-index", services.SearchQuery{QueryString: "catalog"}, "", []string{" "}); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected invalid input for an empty field, got %v", err)
+	}
+	if _, err := engine.ExportSearchAsync("test-batch-index", services.SearchQuery{QueryString: "catalog", RestrictSearchableFields: []string{"body"}}, "", nil); !errors.Is(err, internalErrors.ErrInvalidQuery) {
+		t.Errorf("Expected an invalid query to be rejected before the job starts, got %v", err)
+	}
+	if _, _, err := engine.OpenSearchExport("test-batch-index", "unknown-job"); !errors.Is(err, internalErrors.ErrJobNotFound) {
+		t.Errorf("Expected job not found for an unknown job, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/persistence"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+const (
+	// segmentsDir holds the segment files of an index with segment storage
+	segmentsDir = "segments"
+	// segmentManifestFile lists the segments of an index with segment storage. It replaces
+	// invertedIndexFile for those indexes.
+	segmentManifestFile = "segments.gob"
+	segmentFileSuffix   = ".seg"
+)
+
+// segmentManifest lists the segments of an index with segment storage, and the field lengths of
+// its documents, so the index is loaded by mapping its segments without reading posting lists.
+type segmentManifest struct {
+	Segments     []string                  // File names in segmentsDir, oldest first
+	FieldLengths map[uint32]map[string]int // Words per field, by document, when the index was last persisted
+}
+
+// segmentState tracks the on-disk segments of an index with segment storage.
+type segmentState struct {
+	mu           sync.Mutex // Serializes flushes, merges and manifest writes
+	listed       []string   // Segments listed in the manifest last written or loaded
+	fieldLengths map[uint32]map[string]int
+	merging      atomic.Bool
+	merges       sync.WaitGroup // Background merges in progress
+	mergeCount   atomic.Int64
+}
+
+// newSegmentPath returns the path of a new segment file of the index at indexPath. Segment files
+// are named after their creation time, so names are never reused.
+func newSegmentPath(indexPath string) string {
+	return filepath.Join(indexPath, segmentsDir, fmt.Sprintf("segment-%d%s", time.Now().UnixNano(), segmentFileSuffix))
+}
+
+// loadSegments maps the segments listed in the manifest of the index at indexPath. It returns
+// os.ErrNotExist when the index has no manifest, e.g. when segment storage was just enabled.
+func (i *IndexInstance) loadSegments(indexPath string) error {
+	var manifest segmentManifest
+	if err := persistence.LoadGob(filepath.Join(indexPath, segmentManifestFile), &manifest); err != nil {
+		return err
+	}
+	paths := make([]string, len(manifest.Segments))
+	for j, name := range manifest.Segments {
+		paths[j] = filepath.Join(indexPath, segmentsDir, name)
+	}
+	if err := i.InvertedIndex.OpenSegments(paths); err != nil {
+		return err
+	}
+	i.InvertedIndex.LoadFieldLengths(manifest.FieldLengths)
+	i.segments.listed = manifest.Segments
+	i.segments.fieldLengths = manifest.FieldLengths
+	return nil
+}
+
+// persistSegmentsUnsafe writes the changes buffered in the inverted index to a new segment and
+// lists it in the manifest. The caller must keep writers out.
+func (i *IndexInstance) persistSegmentsUnsafe(indexPath string) error {
+	i.segments.mu.Lock()
+	defer i.segments.mu.Unlock()
+
+	dir := filepath.Join(indexPath, segmentsDir)
+	if err := os.MkdirAll(dir, dataDirPerm); err != nil {
+		return fmt.Errorf("failed to create segments directory: %w", err)
+	}
+	// Segments of another directory, e.g. before the index was renamed, are written anew
+	for _, name := range i.InvertedIndex.SegmentNames() {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			i.InvertedIndex.MaterializeSegments()
+			i.segments.listed = nil
+			break
+		}
+	}
+	if _, err := i.InvertedIndex.FlushSegment(newSegmentPath(indexPath)); err != nil {
+		return fmt.Errorf("failed to flush segment: %w", err)
+	}
+	i.segments.fieldLengths = i.InvertedIndex.AllFieldLengths()
+	if err := i.writeSegmentManifestUnsafe(indexPath); err != nil {
+		return err
+	}
+	// The segments now hold the whole inverted index
+	if err := os.Remove(filepath.Join(indexPath, invertedIndexFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("failed to remove inverted index file", "path", indexPath, "error", err)
+	}
+	return nil
+}
+
+// writeSegmentManifestUnsafe writes the manifest listing the current segments and removes the
+// segment files the previous manifest listed that are no longer used. The caller must hold
+// i.segments.mu.
+func (i *IndexInstance) writeSegmentManifestUnsafe(indexPath string) error {
+	manifest := segmentManifest{Segments: i.InvertedIndex.SegmentNames(), FieldLengths: i.segments.fieldLengths}
+	if err := persistence.SaveGob(filepath.Join(indexPath, segmentManifestFile), manifest); err != nil {
+		return fmt.Errorf("failed to save segment manifest: %w", err)
+	}
+
+	// Segments replaced by a merge, or dropped when the index was cleared, are not listed anymore
+	for _, name := range i.segments.listed {
+		if slices.Contains(manifest.Segments, name) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(indexPath, segmentsDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to remove segment", "segment", name, "error", err)
+		}
+	}
+	i.segments.listed = manifest.Segments
+	return nil
+}
+
+// removeSegmentsUnsafe removes the manifest and segment files of an index whose segment storage
+// was disabled, once its inverted index is saved as a whole. The caller must keep writers out.
+func (i *IndexInstance) removeSegmentsUnsafe(indexPath string) {
+	i.segments.mu.Lock()
+	defer i.segments.mu.Unlock()
+	if err := os.Remove(filepath.Join(indexPath, segmentManifestFile)); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to remove segment manifest", "path", indexPath, "error", err)
+		}
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(indexPath, segmentsDir)); err != nil {
+		slog.Warn("failed to remove segments", "path", indexPath, "error", err)
+	}
+}
+
+// syncSegmentStorage reads the segments back into memory when segment storage is disabled. It is
+// called whenever the search service is created.
+func (i *IndexInstance) syncSegmentStorage() {
+	if i.settings.SegmentStorage != nil || i.indexer == nil {
+		return
+	}
+	_ = i.indexer.Freeze(func() error {
+		i.InvertedIndex.MaterializeSegments()
+		return nil
+	})
+}
+
+// scheduleSegmentMerge starts a background merge of the index's segments when there are more than
+// limit and no merge is running.
+func (i *IndexInstance) scheduleSegmentMerge(indexPath string, limit int) {
+	if len(i.InvertedIndex.SegmentNames()) <= limit {
+		return
+	}
+	if !i.segments.merging.CompareAndSwap(false, true) {
+		return
+	}
+	i.segments.merges.Add(1)
+	go func() {
+		defer i.segments.merges.Done()
+		defer i.segments.merging.Store(false)
+		if err := i.mergeSegments(indexPath); err != nil {
+			slog.Warn("failed to merge segments", "path", indexPath, "error", err)
+		}
+	}()
+}
+
+// mergeSegments merges the index's segments into one and lists it in the manifest. Writes and
+// searches proceed while the merged segment is written.
+func (i *IndexInstance) mergeSegments(indexPath string) error {
+	start := time.Now()
+	replaced, err := i.InvertedIndex.MergeSegments(newSegmentPath(indexPath))
+	if err != nil || len(replaced) == 0 {
+		return err
+	}
+
+	i.segments.mu.Lock()
+	defer i.segments.mu.Unlock()
+	if len(i.InvertedIndex.SegmentNames()) == 0 {
+		// Segment storage was disabled meanwhile
+		return nil
+	}
+	if err := i.writeSegmentManifestUnsafe(indexPath); err != nil {
+		return err
+	}
+	i.segments.mergeCount.Add(1)
+	slog.Info("segments merged", "path", indexPath, "segments", len(replaced), "duration", time.Since(start))
+	return nil
+}
+
+// waitForSegmentMerges waits for background merges of the index's segments to finish, e.g. before
+// its directory is removed.
+func (i *IndexInstance) waitForSegmentMerges() {
+	i.segments.merges.Wait()
+}
+
+// SegmentStorageStats describes the index's segments, or returns nil if it has segment storage
+// disabled.
+func (i *IndexInstance) SegmentStorageStats() *model.SegmentStorageStats {
+	if i.settings.SegmentStorage == nil {
+		return nil
+	}
+	segments, size, bufferedTerms := i.InvertedIndex.SegmentStats()
+	return &model.SegmentStorageStats{
+		Segments:      segments,
+		SegmentBytes:  size,
+		BufferedTerms: bufferedTerms,
+		Merges:        i.segments.mergeCount.Load(),
+		Merging:       i.segments.merging.Load(),
+	}
+}
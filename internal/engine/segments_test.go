@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestEngine_SegmentStorage(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+	indexPath := filepath.Join(engine.dataDir, "test-batch-index")
+
+	settings := instance.Settings()
+	settings.SegmentStorage = &config.SegmentStorage{MaxSegments: 1}
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if stats := instance.SegmentStorageStats(); stats == nil || stats.Segments != 1 || stats.BufferedTerms != 0 {
+		t.Fatalf("Expected the inverted index to be flushed to one segment, got %+v", stats)
+	}
+	if _, err := os.Stat(filepath.Join(indexPath, invertedIndexFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the inverted index file to be replaced by segments, got %v", err)
+	}
+
+	// A second segment exceeds the limit, so the segments are merged in the background
+	if err := indexAccessor.AddDocuments([]model.Document{{"documentID": "3", "title": "Catalog Supplement"}}); err != nil {
+		t.Fatalf("Failed to add document: %v", err)
+	}
+	if err := engine.PersistIndexData("test-batch-index"); err != nil {
+		t.Fatalf("Failed to persist index: %v", err)
+	}
+	instance.waitForSegmentMerges()
+	if stats := instance.SegmentStorageStats(); stats.Segments != 1 || stats.Merges != 1 {
+		t.Errorf("Expected the segments to be merged into one, got %+v", stats)
+	}
+	if total := searchTotal(t, indexAccessor, "catalog"); total != 2 {
+		t.Errorf("Expected both catalog documents to be found in the merged segment, got %d hits", total)
+	}
+
+	// Deletions hide the terms of the segments until they are flushed
+	if err := indexAccessor.DeleteDocument("1"); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+	if total := searchTotal(t, indexAccessor, "catalog"); total != 1 {
+		t.Errorf("Expected the deleted document to be hidden, got %d hits", total)
+	}
+	if err := engine.PersistIndexData("test-batch-index"); err != nil {
+		t.Fatalf("Failed to persist index: %v", err)
+	}
+	instance.waitForSegmentMerges()
+	files, err := os.ReadDir(filepath.Join(indexPath, segmentsDir))
+	if err != nil {
+		t.Fatalf("Failed to read segments directory: %v", err)
+	}
+	if stats := instance.SegmentStorageStats(); len(files) != stats.Segments {
+		t.Errorf("Expected only the segments in use to be kept, got %d files for %+v", len(files), stats)
+	}
+
+	// Segments are mapped when the index is loaded again
+	reloaded := NewEngine(engine.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	reloadedAccessor, err := reloaded.GetIndex("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to get reloaded index: %v", err)
+	}
+	reloadedInstance := reloadedAccessor.(*IndexInstance)
+	if stats := reloadedInstance.SegmentStorageStats(); stats == nil || stats.Segments == 0 || stats.BufferedTerms != 0 {
+		t.Errorf("Expected the reloaded index to serve posting lists from segments, got %+v", stats)
+	}
+	if total := searchTotal(t, reloadedAccessor, "catalog"); total != 1 {
+		t.Errorf("Expected the reloaded index to find the remaining catalog document, got %d hits", total)
+	}
+	if frequency := reloadedInstance.InvertedIndex.DocumentFrequency("catalog"); frequency != 1 {
+		t.Errorf("Expected a document frequency of 1 for 'catalog', got %d", frequency)
+	}
+	if length := reloadedInstance.InvertedIndex.DocumentFieldLength(2, "title"); length != 2 {
+		t.Errorf("Expected the field lengths to be reloaded, got %d words", length)
+	}
+
+	// Disabling segment storage reads the posting lists back into memory
+	settings.SegmentStorage = nil
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if instance.SegmentStorageStats() != nil || len(instance.InvertedIndex.SegmentNames()) != 0 {
+		t.Error("Expected the segments to be dropped once segment storage is disabled")
+	}
+	if _, err := os.Stat(filepath.Join(indexPath, segmentsDir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the segments directory to be removed, got %v", err)
+	}
+	if total := searchTotal(t, indexAccessor, "catalog"); total != 1 {
+		t.Errorf("Expected the catalog document to be found in memory, got %d hits", total)
+	}
+}
+
+func TestEngine_SegmentStorageRename(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+
+	settings := instance.Settings()
+	settings.SegmentStorage = &config.SegmentStorage{}
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if err := engine.RenameIndex("test-batch-index", "renamed-index"); err != nil {
+		t.Fatalf("Failed to rename index: %v", err)
+	}
+
+	// The segments of the old directory are written to the new one
+	reloaded := NewEngine(engine.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	reloadedAccessor, err := reloaded.GetIndex("renamed-index")
+	if err != nil {
+		t.Fatalf("Failed to get renamed index: %v", err)
+	}
+	if total := searchTotal(t, reloadedAccessor, "catalog"); total != 1 {
+		t.Errorf("Expected the renamed index to find the document, got %d hits", total)
+	}
+}
@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"strconv"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// seqMetadataKey is the job metadata entry holding the sequence number of the index once a write job applied its documents
+const seqMetadataKey = "seq"
+
+// addedCountMetadataKey is the job metadata entry holding the number of documents an add documents job added, fewer than requested if it stopped early
+const addedCountMetadataKey = "added_count"
+
+// Seq returns the sequence number of the latest document mutation of the index.
+func (i *IndexInstance) Seq() uint64 {
+	return i.DocumentStore.Seq()
+}
+
+// searchableSeq returns the sequence number of the latest document mutation searches see. With a
+// read replica, it is the one of the last write merged into the replica.
+func (i *IndexInstance) searchableSeq() uint64 {
+	i.replicaMu.Lock()
+	replica := i.replica
+	i.replicaMu.Unlock()
+
+	if replica == nil {
+		return i.Seq()
+	}
+	return replica.documentStore.Seq()
+}
+
+// IndexSeq returns the sequence numbers of an index, so clients can check that their writes are
+// searchable before reading them back.
+func (e *Engine) IndexSeq(indexName string) (model.IndexSeq, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.IndexSeq{}, e.missingIndexError(indexName)
+	}
+
+	// Read the searchable sequence number first, so it is never ahead of the latest one
+	searchable := instance.searchableSeq()
+	return model.IndexSeq{IndexName: indexName, Seq: instance.Seq(), SearchableSeq: searchable}, nil
+}
+
+// recordJobSeq stores the sequence number of the index in the metadata of a write job that applied
+// its documents. The writes of the job have a sequence number up to it.
+func (e *Engine) recordJobSeq(jobID string, instance *IndexInstance) {
+	e.jobManager.SetJobMetadata(jobID, seqMetadataKey, strconv.FormatUint(instance.Seq(), 10))
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestEngine_IndexSeq(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+
+	assertSeq := func(t *testing.T, want uint64) {
+		t.Helper()
+		seq, err := engine.IndexSeq("test-batch-index")
+		if err != nil {
+			t.Fatalf("Failed to get sequence numbers: %v", err)
+		}
+		if seq.Seq != want || seq.SearchableSeq != want {
+			t.Errorf("Expected seq and searchable_seq %d, got %+v", want, seq)
+		}
+	}
+	assertJobSeq := func(t *testing.T, jobID string, want uint64) {
+		t.Helper()
+		job := waitForJob(t, engine, jobID)
+		if job.Status != model.JobStatusCompleted {
+			t.Fatalf("Expected job to complete, got %s: %s", job.Status, job.Error)
+		}
+		if got := job.Metadata[seqMetadataKey]; got != fmt.Sprint(want) {
+			t.Errorf("Expected job seq %d, got %q", want, got)
+		}
+	}
+
+	// The two documents of the test index were the first two mutations
+	assertSeq(t, 2)
+
+	jobID, err := engine.AddDocumentsAsync("test-batch-index", []model.Document{{"documentID": "1", "title": "Updated Entry"}})
+	if err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+	assertJobSeq(t, jobID, 3)
+
+	jobID, err = engine.DeleteDocumentAsync("test-batch-index", "2")
+	if err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+	assertJobSeq(t, jobID, 4)
+
+	jobID, err = engine.RollbackAsync("test-batch-index", 1)
+	if err != nil {
+		t.Fatalf("Failed to start rollback: %v", err)
+	}
+	assertJobSeq(t, jobID, 5)
+
+	// Clearing the index is one mutation and sequence numbers keep increasing afterwards
+	jobID, err = engine.DeleteAllDocumentsAsync("test-batch-index")
+	if err != nil {
+		t.Fatalf("Failed to delete all documents: %v", err)
+	}
+	assertJobSeq(t, jobID, 6)
+
+	report, err := engine.BulkIngest("test-batch-index", strings.NewReader(`{"documentID": "a", "title": "First"}
+{"documentID": "b", "title": "Second"}
+`))
+	if err != nil {
+		t.Fatalf("Failed to ingest documents: %v", err)
+	}
+	if report.Seq != 8 {
+		t.Errorf("Expected bulk ingest seq 8, got %d", report.Seq)
+	}
+	assertSeq(t, 8)
+
+	// Sequence numbers are persisted with the documents
+	reloaded := NewEngine(engine.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	seq, err := reloaded.IndexSeq("test-batch-index")
+	if err != nil || seq.Seq != 8 {
+		t.Errorf("Expected reloaded seq 8, got %+v (err: %v)", seq, err)
+	}
+
+	if _, err := engine.IndexSeq("missing-index"); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected ErrIndexNotFound, got %v", err)
+	}
+}
@@ -6,12 +6,14 @@ import (
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/internal/indexing"
-	"github.com/gcbaptista/go-search-engine/internal/search"
 	"github.com/gcbaptista/go-search-engine/model"
 )
 
 // UpdateIndexSettings updates the settings for an index.
 func (e *Engine) UpdateIndexSettings(name string, newSettings config.IndexSettings) error {
+	if err := e.checkWritable("update index settings"); err != nil {
+		return err
+	}
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -24,18 +26,21 @@ func (e *Engine) UpdateIndexSettings(name string, newSettings config.IndexSettin
 	*instance.settings = newSettings
 
 	// Recreate search service with new settings
-	searchService, err := search.NewService(instance.InvertedIndex, instance.DocumentStore, instance.settings)
+	searchService, err := e.newSearchServiceUnsafe(instance)
 	if err != nil {
 		return fmt.Errorf("failed to create search service with new settings: %w", err)
 	}
 	instance.SetSearcher(searchService)
 
 	// Persist updated settings
-	return e.persistUpdatedIndexUnsafe(name, newSettings, instance)
+	return e.persistUpdatedIndexUnsafe(context.Background(), name, newSettings, instance)
 }
 
 // UpdateIndexSettingsWithReindex updates settings and performs a full reindex.
 func (e *Engine) UpdateIndexSettingsWithReindex(name string, newSettings config.IndexSettings) error {
+	if err := e.checkWritable("update index settings"); err != nil {
+		return err
+	}
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -44,37 +49,38 @@ func (e *Engine) UpdateIndexSettingsWithReindex(name string, newSettings config.
 		return fmt.Errorf("index named '%s' not found", name)
 	}
 
-	// Extract all documents before reindexing
-	docs := e.extractAllDocumentsUnsafe(instance)
-
-	// Clear the index
-	if err := instance.DeleteAllDocuments(); err != nil {
-		return fmt.Errorf("failed to clear index for reindexing: %w", err)
-	}
-
 	// Update settings
 	*instance.settings = newSettings
 
 	// Recreate search service with new settings
-	searchService, err := search.NewService(instance.InvertedIndex, instance.DocumentStore, instance.settings)
+	searchService, err := e.newSearchServiceUnsafe(instance)
 	if err != nil {
 		return fmt.Errorf("failed to create search service with new settings: %w", err)
 	}
 	instance.SetSearcher(searchService)
 
-	// Re-add all documents
-	if len(docs) > 0 {
-		if err := instance.AddDocuments(docs); err != nil {
-			return fmt.Errorf("failed to re-add documents during reindexing: %w", err)
-		}
+	// Rebuild the index from the stored documents
+	if err := instance.BulkReindex(indexing.DefaultBulkIndexingConfig()); err != nil {
+		return fmt.Errorf("failed to re-add documents during reindexing: %w", err)
 	}
 
 	// Persist updated index
-	return e.persistUpdatedIndexUnsafe(name, newSettings, instance)
+	return e.persistUpdatedIndexUnsafe(context.Background(), name, newSettings, instance)
 }
 
 // UpdateIndexSettingsWithAsyncReindex updates settings and performs async reindexing if needed.
+// If the index has safe mode enabled, its searches are watched once the update completes.
 func (e *Engine) UpdateIndexSettingsWithAsyncReindex(name string, newSettings config.IndexSettings) (string, error) {
+	if err := e.checkWritable("update index settings"); err != nil {
+		return "", err
+	}
+	return e.submitSettingsUpdate(name, newSettings, true)
+}
+
+// submitSettingsUpdate starts the job applying newSettings, a reindex job if they require it. When
+// monitored, the safe mode of the settings replaced, if any, watches the searches after the job;
+// safe mode reverts are not monitored.
+func (e *Engine) submitSettingsUpdate(name string, newSettings config.IndexSettings, monitored bool) (string, error) {
 	e.mu.RLock()
 	instance, exists := e.indexes[name]
 	if !exists {
@@ -87,12 +93,10 @@ func (e *Engine) UpdateIndexSettingsWithAsyncReindex(name string, newSettings co
 	// Check if full reindexing is required
 	if e.requiresFullReindexing(oldSettings, newSettings) {
 		// Submit async reindex job
-		jobID := e.jobManager.CreateJob(model.JobTypeReindex, name, map[string]string{
-			"operation": "settings_update_with_reindex",
-		})
+		jobID := e.jobManager.CreateJob(model.JobTypeReindex, name, settingsJobMetadata("settings_update_with_reindex", monitored))
 
 		err := e.jobManager.ExecuteJob(jobID, func(ctx context.Context, job *model.Job) error {
-			return e.executeReindexJob(ctx, name, newSettings, jobID)
+			return e.executeReindexJob(ctx, name, newSettings, jobID, monitored)
 		})
 		if err != nil {
 			return "", fmt.Errorf("failed to start reindex job: %w", err)
@@ -102,12 +106,10 @@ func (e *Engine) UpdateIndexSettingsWithAsyncReindex(name string, newSettings co
 	}
 
 	// For search-time settings, submit a lighter job
-	jobID := e.jobManager.CreateJob(model.JobTypeUpdateSettings, name, map[string]string{
-		"operation": "search_time_settings_update",
-	})
+	jobID := e.jobManager.CreateJob(model.JobTypeUpdateSettings, name, settingsJobMetadata("search_time_settings_update", monitored))
 
 	err := e.jobManager.ExecuteJob(jobID, func(ctx context.Context, job *model.Job) error {
-		return e.executeSearchTimeSettingsUpdateJob(ctx, name, newSettings, jobID)
+		return e.executeSearchTimeSettingsUpdateJob(ctx, name, newSettings, jobID, monitored)
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to start settings update job: %w", err)
@@ -116,6 +118,15 @@ func (e *Engine) UpdateIndexSettingsWithAsyncReindex(name string, newSettings co
 	return jobID, nil
 }
 
+// settingsJobMetadata returns the metadata of a settings update job, marking safe mode reverts.
+func settingsJobMetadata(operation string, monitored bool) map[string]string {
+	metadata := map[string]string{"operation": operation}
+	if !monitored {
+		metadata["trigger"] = "safe_mode_revert"
+	}
+	return metadata
+}
+
 // requiresFullReindexing determines if settings changes require full reindexing.
 func (e *Engine) requiresFullReindexing(oldSettings, newSettings config.IndexSettings) bool {
 	// Check if core indexing settings changed
@@ -134,11 +145,38 @@ func (e *Engine) requiresFullReindexing(oldSettings, newSettings config.IndexSet
 	if oldSettings.MinWordSizeFor2Typos != newSettings.MinWordSizeFor2Typos {
 		return true
 	}
+	if oldSettings.Locale != newSettings.Locale {
+		return true
+	}
+	if !languageDetectionEqual(oldSettings.LanguageDetection, newSettings.LanguageDetection) {
+		return true
+	}
+	if !stopWordsEqual(oldSettings.StopWords, newSettings.StopWords) {
+		return true
+	}
+	if oldSettings.CompoundWords != newSettings.CompoundWords {
+		return true
+	}
+	if oldSettings.WordCharacters != newSettings.WordCharacters {
+		return true
+	}
+	if oldSettings.Stemming != newSettings.Stemming {
+		return true
+	}
+	if oldSettings.TextNormalization != newSettings.TextNormalization {
+		return true
+	}
+	if !slicesEqual(oldSettings.CJKFields, newSettings.CJKFields) {
+		return true
+	}
+	if !slicesEqual(oldSettings.NestedFields(), newSettings.NestedFields()) {
+		return true
+	}
 	return false
 }
 
 // executeSearchTimeSettingsUpdateJob executes a search-time settings update job.
-func (e *Engine) executeSearchTimeSettingsUpdateJob(_ context.Context, name string, newSettings config.IndexSettings, _ string) error {
+func (e *Engine) executeSearchTimeSettingsUpdateJob(ctx context.Context, name string, newSettings config.IndexSettings, _ string, monitored bool) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -148,21 +186,28 @@ func (e *Engine) executeSearchTimeSettingsUpdateJob(_ context.Context, name stri
 	}
 
 	// Update settings
+	previous := *instance.settings
 	*instance.settings = newSettings
 
 	// Recreate search service with new settings
-	searchService, err := search.NewService(instance.InvertedIndex, instance.DocumentStore, instance.settings)
+	searchService, err := e.newSearchServiceUnsafe(instance)
 	if err != nil {
 		return fmt.Errorf("failed to create search service with new settings: %w", err)
 	}
 	instance.SetSearcher(searchService)
 
 	// Persist updated settings
-	return e.persistUpdatedIndexUnsafe(name, newSettings, instance)
+	if err := e.persistUpdatedIndexUnsafe(ctx, name, newSettings, instance); err != nil {
+		return err
+	}
+	if monitored {
+		e.startSafeMode(instance, previous)
+	}
+	return nil
 }
 
 // executeReindexJob executes a full reindex job using optimized bulk operations.
-func (e *Engine) executeReindexJob(ctx context.Context, name string, newSettings config.IndexSettings, jobID string) error {
+func (e *Engine) executeReindexJob(ctx context.Context, name string, newSettings config.IndexSettings, jobID string, monitored bool) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -172,10 +217,11 @@ func (e *Engine) executeReindexJob(ctx context.Context, name string, newSettings
 	}
 
 	// Update settings first
+	previous := *instance.settings
 	*instance.settings = newSettings
 
 	// Recreate search service with new settings
-	searchService, err := search.NewService(instance.InvertedIndex, instance.DocumentStore, instance.settings)
+	searchService, err := e.newSearchServiceUnsafe(instance)
 	if err != nil {
 		return fmt.Errorf("failed to create search service with new settings: %w", err)
 	}
@@ -195,7 +241,13 @@ func (e *Engine) executeReindexJob(ctx context.Context, name string, newSettings
 	}
 
 	// Persist updated index
-	return e.persistUpdatedIndexUnsafe(name, newSettings, instance)
+	if err := e.persistUpdatedIndexUnsafe(ctx, name, newSettings, instance); err != nil {
+		return err
+	}
+	if monitored {
+		e.startSafeMode(instance, previous)
+	}
+	return nil
 }
 
 // Helper function to compare string slices
@@ -217,9 +269,25 @@ func rankingCriteriaEqual(a, b []config.RankingCriterion) bool {
 		return false
 	}
 	for i := range a {
-		if a[i].Field != b[i].Field || a[i].Order != b[i].Order {
+		if a[i].Field != b[i].Field || a[i].Order != b[i].Order || a[i].Selector != b[i].Selector {
 			return false
 		}
 	}
 	return true
 }
+
+// Helper function to compare language detection settings
+func languageDetectionEqual(a, b *config.LanguageDetection) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return slicesEqual(a.Fields, b.Fields) && slicesEqual(a.Languages, b.Languages) && a.LanguageFieldName() == b.LanguageFieldName()
+}
+
+// stopWordsEqual compares stop-word settings, which decide the words indexed for each field.
+func stopWordsEqual(a, b *config.StopWords) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return slicesEqual(a.List(), b.List()) && a.KeepInPhrases == b.KeepInPhrases
+}
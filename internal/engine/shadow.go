@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// maxConcurrentShadowSearches bounds the shadow searches running at once, so a slow candidate
+// cannot pile up work behind live traffic. Sampled searches beyond it are dropped.
+const maxConcurrentShadowSearches = 8
+
+// shadowState holds the configuration and running totals of shadow mode for one index.
+type shadowState struct {
+	mu                   sync.Mutex
+	indexName            string
+	config               model.ShadowConfig
+	startedAt            time.Time
+	sampled              int64
+	failed               int64
+	dropped              int64
+	hitCountChanged      int64
+	primaryLatency       time.Duration
+	candidateLatency     time.Duration
+	primaryHits          int64
+	candidateHits        int64
+	primaryZeroResults   int64
+	candidateZeroResults int64
+}
+
+// stats returns a snapshot of the shadow metrics. The caller must hold s.mu.
+func (s *shadowState) stats() model.ShadowStats {
+	stats := model.ShadowStats{
+		IndexName:              s.indexName,
+		CandidateIndex:         s.config.CandidateIndex,
+		SamplePercentage:       s.config.SamplePercentage,
+		StartedAt:              s.startedAt,
+		SampledQueries:         s.sampled,
+		FailedQueries:          s.failed,
+		DroppedQueries:         s.dropped,
+		HitCountChangedQueries: s.hitCountChanged,
+		Primary:                model.ShadowSideStats{ZeroResultQueries: s.primaryZeroResults},
+		Candidate:              model.ShadowSideStats{ZeroResultQueries: s.candidateZeroResults},
+	}
+	if s.sampled > 0 {
+		n := float64(s.sampled)
+		stats.Primary.AvgLatencyMs = float64(s.primaryLatency.Microseconds()) / 1000 / n
+		stats.Primary.AvgHits = float64(s.primaryHits) / n
+		stats.Candidate.AvgLatencyMs = float64(s.candidateLatency.Microseconds()) / 1000 / n
+		stats.Candidate.AvgHits = float64(s.candidateHits) / n
+	}
+	return stats
+}
+
+// record adds one search compared on both indexes.
+func (s *shadowState) record(primary services.SearchResult, primaryLatency time.Duration, candidate services.SearchResult, candidateLatency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sampled++
+	s.primaryLatency += primaryLatency
+	s.candidateLatency += candidateLatency
+	s.primaryHits += int64(primary.Total)
+	s.candidateHits += int64(candidate.Total)
+	if primary.Total == 0 {
+		s.primaryZeroResults++
+	}
+	if candidate.Total == 0 {
+		s.candidateZeroResults++
+	}
+	if primary.Total != candidate.Total {
+		s.hitCountChanged++
+	}
+}
+
+// EnableShadow starts mirroring a sample of the index's live searches to a candidate index, for
+// example a copy of the index with new settings. Enabling shadow mode again replaces the
+// configuration and resets the metrics. Shadow mode is kept in memory only.
+func (e *Engine) EnableShadow(indexName string, config model.ShadowConfig) (model.ShadowStats, error) {
+	e.mu.RLock()
+	_, indexExists := e.indexes[indexName]
+	_, candidateExists := e.indexes[config.CandidateIndex]
+	e.mu.RUnlock()
+
+	if !indexExists {
+		return model.ShadowStats{}, e.missingIndexError(indexName)
+	}
+	if config.CandidateIndex == "" {
+		return model.ShadowStats{}, errors.NewValidationError("candidate_index", "is required")
+	}
+	if config.CandidateIndex == indexName {
+		return model.ShadowStats{}, errors.NewValidationError("candidate_index", "must be different from the live index")
+	}
+	if !candidateExists {
+		return model.ShadowStats{}, e.missingIndexError(config.CandidateIndex)
+	}
+	if config.SamplePercentage <= 0 || config.SamplePercentage > 100 {
+		return model.ShadowStats{}, errors.NewValidationError("sample_percentage", "must be greater than 0 and at most 100")
+	}
+
+	state := &shadowState{indexName: indexName, config: config, startedAt: time.Now()}
+	stats := state.stats()
+
+	e.shadowsMu.Lock()
+	e.shadows[indexName] = state
+	e.shadowsMu.Unlock()
+
+	slog.Info("shadow mode enabled", "index", indexName, "candidate_index", config.CandidateIndex, "sample_percentage", config.SamplePercentage)
+	return stats, nil
+}
+
+// GetShadowStats returns the metrics collected since shadow mode was enabled for an index.
+func (e *Engine) GetShadowStats(indexName string) (model.ShadowStats, error) {
+	state, err := e.getShadow(indexName)
+	if err != nil {
+		return model.ShadowStats{}, err
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.stats(), nil
+}
+
+// DisableShadow stops shadow mode for an index and returns its final metrics.
+func (e *Engine) DisableShadow(indexName string) (model.ShadowStats, error) {
+	e.shadowsMu.Lock()
+	state, exists := e.shadows[indexName]
+	delete(e.shadows, indexName)
+	e.shadowsMu.Unlock()
+
+	if !exists {
+		return model.ShadowStats{}, errors.NewShadowNotFoundError(indexName)
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.stats(), nil
+}
+
+// ShadowSearch runs a live search again against the index's shadow candidate when it is sampled.
+// It returns immediately: the candidate search runs in the background and only its metrics are kept.
+func (e *Engine) ShadowSearch(indexName string, query services.SearchQuery, result services.SearchResult, latency time.Duration) {
+	e.shadowsMu.RLock()
+	state, exists := e.shadows[indexName]
+	e.shadowsMu.RUnlock()
+	if !exists || rand.Float64()*100 >= state.config.SamplePercentage {
+		return
+	}
+
+	select {
+	case e.shadowSlots <- struct{}{}:
+	default:
+		state.mu.Lock()
+		state.dropped++
+		state.mu.Unlock()
+		return
+	}
+
+	go func() {
+		defer func() { <-e.shadowSlots }()
+
+		candidate, err := e.GetIndex(state.config.CandidateIndex)
+		if err == nil {
+			start := time.Now()
+			var candidateResult services.SearchResult
+			if candidateResult, err = candidate.Search(query); err == nil {
+				state.record(result, latency, candidateResult, time.Since(start))
+				return
+			}
+		}
+
+		state.mu.Lock()
+		state.failed++
+		state.mu.Unlock()
+	}()
+}
+
+// disableShadowsOf stops shadow mode wherever the index is the live index or the candidate.
+// It is called when the index is deleted, renamed or closed.
+func (e *Engine) disableShadowsOf(name string) {
+	e.shadowsMu.Lock()
+	defer e.shadowsMu.Unlock()
+
+	for indexName, state := range e.shadows {
+		if indexName == name || state.config.CandidateIndex == name {
+			delete(e.shadows, indexName)
+			slog.Info("shadow mode disabled because an index was deleted, renamed or closed", "index", indexName, "removed_index", name)
+		}
+	}
+}
+
+// getShadow returns the shadow state of an index.
+func (e *Engine) getShadow(indexName string) (*shadowState, error) {
+	e.shadowsMu.RLock()
+	defer e.shadowsMu.RUnlock()
+
+	state, exists := e.shadows[indexName]
+	if !exists {
+		return nil, errors.NewShadowNotFoundError(indexName)
+	}
+	return state, nil
+}
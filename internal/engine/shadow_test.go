@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// waitForShadowQueries polls the shadow stats until n searches were compared or failed.
+func waitForShadowQueries(t *testing.T, engine *Engine, indexName string, n int64) model.ShadowStats {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		stats, err := engine.GetShadowStats(indexName)
+		if err != nil {
+			t.Fatalf("GetShadowStats() error = %v", err)
+		}
+		if stats.SampledQueries+stats.FailedQueries+stats.DroppedQueries >= n {
+			return stats
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Shadow searches of index %s did not finish within timeout", indexName)
+	return model.ShadowStats{}
+}
+
+func TestShadowMode(t *testing.T) {
+	engine, live := newBatchTestEngine(t)
+	const indexName = "test-batch-index"
+
+	// The candidate only knows one of the live documents
+	candidateSettings := config.IndexSettings{
+		Name:                 "test-batch-candidate",
+		SearchableFields:     []string{"title"},
+		MinWordSizeFor1Typo:  4,
+		MinWordSizeFor2Typos: 8,
+	}
+	if err := engine.CreateIndex(candidateSettings); err != nil {
+		t.Fatalf("Failed to create candidate index: %v", err)
+	}
+	candidate, _ := engine.GetIndex(candidateSettings.Name)
+	if err := candidate.AddDocuments([]model.Document{{"documentID": "1", "title": "Old Catalog Entry"}}); err != nil {
+		t.Fatalf("Failed to add candidate documents: %v", err)
+	}
+
+	invalid := []model.ShadowConfig{
+		{CandidateIndex: indexName, SamplePercentage: 50},
+		{CandidateIndex: candidateSettings.Name, SamplePercentage: 0},
+		{CandidateIndex: candidateSettings.Name, SamplePercentage: 150},
+	}
+	for _, shadowConfig := range invalid {
+		if _, err := engine.EnableShadow(indexName, shadowConfig); !errors.Is(err, internalErrors.ErrInvalidInput) {
+			t.Errorf("EnableShadow(%+v) error = %v, want ErrInvalidInput", shadowConfig, err)
+		}
+	}
+	if _, err := engine.EnableShadow(indexName, model.ShadowConfig{CandidateIndex: "missing", SamplePercentage: 50}); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("EnableShadow() with missing candidate error = %v, want ErrIndexNotFound", err)
+	}
+
+	if _, err := engine.EnableShadow(indexName, model.ShadowConfig{CandidateIndex: candidateSettings.Name, SamplePercentage: 100}); err != nil {
+		t.Fatalf("EnableShadow() error = %v", err)
+	}
+
+	for _, queryString := range []string{"discontinued product", "catalog"} {
+		query := services.SearchQuery{QueryString: queryString, PageSize: 10}
+		result, err := live.Search(query)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		engine.ShadowSearch(indexName, query, result, time.Millisecond)
+	}
+
+	stats := waitForShadowQueries(t, engine, indexName, 2)
+	if stats.SampledQueries != 2 || stats.FailedQueries != 0 {
+		t.Fatalf("stats = %+v, want 2 sampled queries without failures", stats)
+	}
+	if stats.HitCountChangedQueries != 1 || stats.Candidate.ZeroResultQueries != 1 || stats.Primary.ZeroResultQueries != 0 {
+		t.Errorf("stats = %+v, want the discontinued product query to lose its hit on the candidate", stats)
+	}
+	if stats.Primary.AvgHits != 1 || stats.Candidate.AvgHits != 0.5 {
+		t.Errorf("average hits = %v / %v, want 1 / 0.5", stats.Primary.AvgHits, stats.Candidate.AvgHits)
+	}
+
+	// Deleting the candidate stops shadow mode
+	if err := engine.DeleteIndex(candidateSettings.Name); err != nil {
+		t.Fatalf("DeleteIndex() error = %v", err)
+	}
+	if _, err := engine.DisableShadow(indexName); !errors.Is(err, internalErrors.ErrShadowNotFound) {
+		t.Errorf("DisableShadow() after deleting the candidate error = %v, want ErrShadowNotFound", err)
+	}
+}
@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/indexing"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/store"
+)
+
+const (
+	// snapshotMagic identifies snapshot archives, so other files are rejected before decoding them
+	snapshotMagic = "go-search-engine/snapshot"
+	// snapshotFormatVersion is the version of the archive format written by SnapshotIndex.
+	// Archives of newer versions cannot be restored. Version 2 added the index rules.
+	snapshotFormatVersion = 2
+)
+
+// snapshotHeader is the first record of a snapshot archive. It is followed by the index settings,
+// the inverted index, the document store and the rules, all gob-encoded in one gzip stream.
+type snapshotHeader struct {
+	Magic         string
+	FormatVersion int
+	SourceIndex   string
+	CreatedAt     time.Time
+	DocumentCount int
+	TermCount     int
+	RuleCount     int
+}
+
+// SnapshotIndex writes an archive of an index's settings, inverted index, document store and rules.
+// Writes only wait while the index is copied: the copy shares the posting lists and documents,
+// which are never modified once stored, so it is a consistent view of the index that is checked
+// and written while writes proceed.
+func (e *Engine) SnapshotIndex(indexName string, w io.Writer) (model.SnapshotInfo, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.SnapshotInfo{}, e.missingIndexError(indexName)
+	}
+	if instance.indexer == nil {
+		return model.SnapshotInfo{}, fmt.Errorf("indexer service not initialized for index '%s'", indexName)
+	}
+
+	var (
+		header   snapshotHeader
+		settings config.IndexSettings
+		invIndex *index.InvertedIndex
+		docStore *store.DocumentStore
+		rules    []model.Rule
+	)
+	err := instance.indexer.Freeze(func() error {
+		settings = instance.Settings()
+		invIndex = instance.InvertedIndex.Clone()
+		docStore = instance.DocumentStore.Clone()
+		rules = e.ruleStore.ListRules(indexName)
+		header = snapshotHeader{
+			Magic:         snapshotMagic,
+			FormatVersion: snapshotFormatVersion,
+			SourceIndex:   indexName,
+			CreatedAt:     time.Now().UTC(),
+			DocumentCount: docStore.Len(),
+			TermCount:     invIndex.Len(),
+			RuleCount:     len(rules),
+		}
+		return nil
+	})
+	if err != nil {
+		return model.SnapshotInfo{}, err
+	}
+	// The copy keeps the settings it was taken with, even if the index's settings change meanwhile
+	invIndex.Settings = &settings
+
+	// Nothing is written before the copy is checked, so a failed check is reported to the caller
+	if err := verifySnapshot(invIndex, docStore); err != nil {
+		return model.SnapshotInfo{}, fmt.Errorf("snapshot of index '%s' failed its consistency check: %w", indexName, err)
+	}
+
+	archive := gzip.NewWriter(w)
+	encoder := gob.NewEncoder(archive)
+	for _, record := range []interface{}{header, settings, invIndex, docStore, rules} {
+		if err := encoder.Encode(record); err != nil {
+			return model.SnapshotInfo{}, fmt.Errorf("failed to write snapshot of index '%s': %w", indexName, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return model.SnapshotInfo{}, fmt.Errorf("failed to write snapshot of index '%s': %w", indexName, err)
+	}
+
+	slog.Info("snapshot written", "index", indexName, "documents", header.DocumentCount, "terms", header.TermCount, "rules", header.RuleCount)
+	return header.info(indexName), nil
+}
+
+// verifySnapshot cross-checks the copy of an index taken by SnapshotIndex: every posting must
+// belong to a stored document and every document must be reachable by its ID.
+func verifySnapshot(invIndex *index.InvertedIndex, docStore *store.DocumentStore) error {
+	checker, err := indexing.NewService(invIndex, docStore)
+	if err != nil {
+		return err
+	}
+	report := checker.Verify(false)
+	if !report.Healthy {
+		return fmt.Errorf("found %v; verify the index with repair before taking a snapshot", report.IssueCounts)
+	}
+	return nil
+}
+
+// RestoreIndex creates an index from a snapshot archive written by SnapshotIndex, under a name that
+// is not in use. The index keeps the settings it had when the snapshot was taken.
+func (e *Engine) RestoreIndex(indexName string, r io.Reader) (model.SnapshotInfo, error) {
+	if err := e.checkWritable("restore index"); err != nil {
+		return model.SnapshotInfo{}, err
+	}
+	e.mu.RLock()
+	_, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if exists {
+		return model.SnapshotInfo{}, errors.NewIndexAlreadyExistsError(indexName)
+	}
+
+	archive, err := gzip.NewReader(r)
+	if err != nil {
+		return model.SnapshotInfo{}, errors.NewValidationError("snapshot", "not a snapshot archive")
+	}
+	defer archive.Close()
+	decoder := gob.NewDecoder(archive)
+
+	var header snapshotHeader
+	if err := decoder.Decode(&header); err != nil || header.Magic != snapshotMagic {
+		return model.SnapshotInfo{}, errors.NewValidationError("snapshot", "not a snapshot archive")
+	}
+	if header.FormatVersion > snapshotFormatVersion {
+		return model.SnapshotInfo{}, errors.NewValidationError("snapshot", fmt.Sprintf("archive format version %d is newer than the supported version %d", header.FormatVersion, snapshotFormatVersion))
+	}
+
+	var settings config.IndexSettings
+	if err := decoder.Decode(&settings); err != nil {
+		return model.SnapshotInfo{}, errors.NewValidationError("snapshot", fmt.Sprintf("corrupted index settings: %v", err))
+	}
+	settings.Name = indexName
+
+	invIndex := index.NewInvertedIndex(&settings)
+	if err := decoder.Decode(invIndex); err != nil {
+		return model.SnapshotInfo{}, errors.NewValidationError("snapshot", fmt.Sprintf("corrupted inverted index: %v", err))
+	}
+	docStore := &store.DocumentStore{}
+	if err := decoder.Decode(docStore); err != nil {
+		return model.SnapshotInfo{}, errors.NewValidationError("snapshot", fmt.Sprintf("corrupted document store: %v", err))
+	}
+	// Archives of version 1 have no rules
+	var rules []model.Rule
+	if header.FormatVersion >= 2 {
+		if err := decoder.Decode(&rules); err != nil {
+			return model.SnapshotInfo{}, errors.NewValidationError("snapshot", fmt.Sprintf("corrupted rules: %v", err))
+		}
+	}
+
+	indexerService, err := indexing.NewService(invIndex, docStore)
+	if err != nil {
+		return model.SnapshotInfo{}, fmt.Errorf("failed to create indexer service for restored index '%s': %w", indexName, err)
+	}
+	instance := &IndexInstance{
+		settings:      &settings,
+		InvertedIndex: invIndex,
+		DocumentStore: docStore,
+		indexer:       indexerService,
+	}
+	instance.markUpdated(time.Now())
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Decoding ran without the lock, so the name may have been taken meanwhile
+	if e.indexExistsUnsafe(indexName) {
+		return model.SnapshotInfo{}, errors.NewIndexAlreadyExistsError(indexName)
+	}
+	if err := e.aliasConflictUnsafe(indexName); err != nil {
+		return model.SnapshotInfo{}, err
+	}
+	searchService, err := e.newSearchServiceUnsafe(instance)
+	if err != nil {
+		return model.SnapshotInfo{}, fmt.Errorf("failed to create search service for restored index '%s': %w", indexName, err)
+	}
+	instance.SetSearcher(searchService)
+
+	for _, rule := range rules {
+		rule.IndexName = indexName
+		if err := e.ruleStore.SaveRule(rule); err != nil {
+			_ = e.ruleStore.DeleteIndexRules(indexName)
+			instance.closeReadReplica()
+			instance.closeCacheWarmer()
+			return model.SnapshotInfo{}, fmt.Errorf("failed to restore rules of index '%s': %w", indexName, err)
+		}
+	}
+	if err := e.persistUpdatedIndexUnsafe(context.Background(), indexName, settings, instance); err != nil {
+		_ = e.ruleStore.DeleteIndexRules(indexName)
+		instance.closeReadReplica()
+		instance.closeCacheWarmer()
+		return model.SnapshotInfo{}, fmt.Errorf("failed to persist restored index '%s': %w", indexName, err)
+	}
+
+	e.indexes[indexName] = instance
+	e.dropRenameAliasesUnsafe(indexName)
+	slog.Info("index restored from snapshot", "index", indexName, "source_index", header.SourceIndex)
+	return header.info(indexName), nil
+}
+
+// info describes the snapshot of the header for the given index.
+func (h snapshotHeader) info(indexName string) model.SnapshotInfo {
+	return model.SnapshotInfo{
+		IndexName:     indexName,
+		SourceIndex:   h.SourceIndex,
+		FormatVersion: h.FormatVersion,
+		CreatedAt:     h.CreatedAt,
+		DocumentCount: h.DocumentCount,
+		TermCount:     h.TermCount,
+		RuleCount:     h.RuleCount,
+	}
+}
@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/index"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestEngine_SnapshotAndRestore(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	settings := indexAccessor.Settings()
+	settings.DocumentCompression = &config.Compression{MinDocumentBytes: 1}
+	if err := engine.UpdateIndexSettings("test-batch-index", settings); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	var archive bytes.Buffer
+	info, err := engine.SnapshotIndex("test-batch-index", &archive)
+	if err != nil {
+		t.Fatalf("Failed to snapshot index: %v", err)
+	}
+	if info.DocumentCount != 2 || info.TermCount == 0 || info.FormatVersion != snapshotFormatVersion {
+		t.Errorf("Unexpected snapshot info: %+v", info)
+	}
+
+	// Writes after the snapshot are not part of it
+	if err := indexAccessor.DeleteDocument("1"); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+
+	// A second engine stands in for another server
+	target, _ := newBatchTestEngine(t)
+	restored, err := target.RestoreIndex("restored-index", bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to restore index: %v", err)
+	}
+	if restored.IndexName != "restored-index" || restored.SourceIndex != "test-batch-index" || restored.DocumentCount != 2 {
+		t.Errorf("Unexpected restore info: %+v", restored)
+	}
+
+	restoredIndex, err := target.GetIndex("restored-index")
+	if err != nil {
+		t.Fatalf("Failed to get restored index: %v", err)
+	}
+	if restoredSettings := restoredIndex.Settings(); restoredSettings.Name != "restored-index" || restoredSettings.DocumentCompression == nil {
+		t.Errorf("Expected the snapshot settings under the new name, got %+v", restoredSettings)
+	}
+	result, err := restoredIndex.Search(services.SearchQuery{QueryString: "catalog"})
+	if err != nil || result.Total != 1 {
+		t.Errorf("Expected the restored index to find the snapshot documents, got %d hits (err: %v)", result.Total, err)
+	}
+
+	// The restored index is persisted like any other
+	reloaded := NewEngine(target.dataDir)
+	t.Cleanup(reloaded.jobManager.Stop)
+	if _, err := reloaded.GetIndex("restored-index"); err != nil {
+		t.Errorf("Expected the restored index to be loaded from disk: %v", err)
+	}
+}
+
+// stallingWriter blocks its first write until released, to hold a snapshot in the middle of
+// writing its archive.
+type stallingWriter struct {
+	bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+}
+
+func (w *stallingWriter) Write(p []byte) (int, error) {
+	if w.started != nil {
+		close(w.started)
+		w.started = nil
+		<-w.release
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestEngine_SnapshotDuringWrites(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	if _, err := engine.CreateRule("test-batch-index", model.Rule{
+		Condition: model.RuleCondition{Query: "discontinued"},
+		Actions:   []model.RuleAction{{Type: model.RuleActionPin, DocumentIDs: []string{"1"}}},
+	}); err != nil {
+		t.Fatalf("Failed to create rule: %v", err)
+	}
+
+	archive := &stallingWriter{started: make(chan struct{}), release: make(chan struct{})}
+	started := archive.started
+	snapshotErr := make(chan error, 1)
+	go func() {
+		_, err := engine.SnapshotIndex("test-batch-index", archive)
+		snapshotErr <- err
+	}()
+	<-started
+
+	// The archive is being written, and writes to the index still go through
+	written := make(chan error, 1)
+	go func() {
+		if err := indexAccessor.AddDocuments([]model.Document{{"documentID": "3", "title": "New Catalog Entry"}}); err != nil {
+			written <- err
+			return
+		}
+		written <- indexAccessor.DeleteDocument("1")
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Fatalf("Failed to write during the snapshot: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Writes waited for the snapshot archive to be written")
+	}
+	close(archive.release)
+	if err := <-snapshotErr; err != nil {
+		t.Fatalf("Failed to snapshot index: %v", err)
+	}
+
+	// The archive holds the index as it was when the snapshot started
+	restored, err := engine.RestoreIndex("restored-index", bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to restore index: %v", err)
+	}
+	if restored.DocumentCount != 2 || restored.RuleCount != 1 {
+		t.Errorf("Unexpected restore info: %+v", restored)
+	}
+	restoredIndex, err := engine.GetIndex("restored-index")
+	if err != nil {
+		t.Fatalf("Failed to get restored index: %v", err)
+	}
+	if total := searchTotal(t, restoredIndex, "catalog"); total != 1 {
+		t.Errorf("Expected only the document indexed before the snapshot, got %d hits", total)
+	}
+	if list, _ := engine.ListRules("restored-index", services.RuleListQuery{}); len(list.Rules) != 1 || list.Rules[0].IndexName != "restored-index" {
+		t.Errorf("Expected the rule to be restored with the index, got %+v", list.Rules)
+	}
+}
+
+func TestEngine_SnapshotConsistencyCheck(t *testing.T) {
+	engine, indexAccessor := newBatchTestEngine(t)
+	instance := indexAccessor.(*IndexInstance)
+	instance.InvertedIndex.Set("ghost", index.PostingList{{DocID: 999, FieldName: "title", IsFullWord: true}})
+
+	var archive bytes.Buffer
+	if _, err := engine.SnapshotIndex("test-batch-index", &archive); err == nil {
+		t.Fatal("Expected the snapshot of an index with orphaned postings to fail")
+	}
+	if archive.Len() != 0 {
+		t.Errorf("Expected nothing to be written after a failed check, got %d bytes", archive.Len())
+	}
+}
+
+func TestEngine_RestoreErrors(t *testing.T) {
+	engine, _ := newBatchTestEngine(t)
+	var archive bytes.Buffer
+	if _, err := engine.SnapshotIndex("test-batch-index", &archive); err != nil {
+		t.Fatalf("Failed to snapshot index: %v", err)
+	}
+
+	if _, err := engine.SnapshotIndex("missing", &bytes.Buffer{}); !errors.Is(err, internalErrors.ErrIndexNotFound) {
+		t.Errorf("Expected index not found for a missing index, got %v", err)
+	}
+	if _, err := engine.RestoreIndex("test-batch-index", bytes.NewReader(archive.Bytes())); !errors.Is(err, internalErrors.ErrIndexAlreadyExists) {
+		t.Errorf("Expected index already exists when restoring over an index, got %v", err)
+	}
+	if _, err := engine.RestoreIndex("from-garbage", bytes.NewReader([]byte("not an archive"))); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected invalid input for a file that is not an archive, got %v", err)
+	}
+	if _, err := engine.RestoreIndex("truncated", bytes.NewReader(archive.Bytes()[:archive.Len()/2])); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected invalid input for a truncated archive, got %v", err)
+	}
+
+	var future bytes.Buffer
+	writer := gzip.NewWriter(&future)
+	if err := gob.NewEncoder(writer).Encode(snapshotHeader{Magic: snapshotMagic, FormatVersion: snapshotFormatVersion + 1}); err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+	_ = writer.Close()
+	if _, err := engine.RestoreIndex("from-future", &future); !errors.Is(err, internalErrors.ErrInvalidInput) {
+		t.Errorf("Expected invalid input for a newer archive format, got %v", err)
+	}
+
+	for _, name := range []string{"from-garbage", "truncated", "from-future"} {
+		if _, err := engine.GetIndex(name); err == nil {
+			t.Errorf("Expected no index to be created by a failed restore, found %q", name)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// Spellcheck suggests corrections for the words of a query that are not in the index, so that a
+// "did you mean" hint can be shown before the query is searched.
+func (e *Engine) Spellcheck(indexName string, request model.SpellcheckRequest) (model.SpellcheckResult, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.SpellcheckResult{}, e.missingIndexError(indexName)
+	}
+
+	if strings.TrimSpace(request.Query) == "" {
+		return model.SpellcheckResult{}, errors.NewValidationError("query", "is required")
+	}
+	if instance.searcher == nil {
+		return model.SpellcheckResult{}, fmt.Errorf("search service not initialized for index '%s'", indexName)
+	}
+	return instance.searcher.Spellcheck(request), nil
+}
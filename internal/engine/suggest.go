@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 100
+)
+
+// Suggest completes a typed prefix with the most frequent indexed words or values of the index's
+// suggestion field, for typeahead without running a search.
+func (e *Engine) Suggest(indexName string, request model.SuggestRequest) (model.SuggestResult, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.SuggestResult{}, e.missingIndexError(indexName)
+	}
+
+	if request.Limit < 0 {
+		return model.SuggestResult{}, errors.NewValidationError("limit", "must not be negative")
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultSuggestLimit
+	}
+	request.Limit = min(request.Limit, maxSuggestLimit)
+	if instance.searcher == nil {
+		return model.SuggestResult{}, fmt.Errorf("search service not initialized for index '%s'", indexName)
+	}
+	return instance.searcher.Suggest(request), nil
+}
@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+const (
+	defaultTermsLimit = 20
+	maxTermsLimit     = 1000
+)
+
+// Terms lists the indexed terms of an index starting with a prefix, with their document counts,
+// so its vocabulary can be explored and completions suggested without searching.
+func (e *Engine) Terms(indexName string, request model.TermsRequest) (model.TermsResult, error) {
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.TermsResult{}, e.missingIndexError(indexName)
+	}
+
+	if request.Limit < 0 {
+		return model.TermsResult{}, errors.NewValidationError("limit", "must not be negative")
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultTermsLimit
+	}
+	request.Limit = min(request.Limit, maxTermsLimit)
+	if request.Field != "" && !slices.Contains(instance.settings.SearchableFields, request.Field) {
+		return model.TermsResult{}, errors.NewValidationError("field", fmt.Sprintf("'%s' is not a searchable field", request.Field))
+	}
+	if instance.searcher == nil {
+		return model.TermsResult{}, fmt.Errorf("search service not initialized for index '%s'", indexName)
+	}
+	return instance.searcher.Terms(request), nil
+}
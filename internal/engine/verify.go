@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// VerifyIndex cross-checks an index's document store against its inverted index. With repair,
+// the issues found are fixed and the repaired index is persisted.
+func (e *Engine) VerifyIndex(indexName string, repair bool) (model.IntegrityReport, error) {
+	if repair {
+		if err := e.checkWritable("repair index"); err != nil {
+			return model.IntegrityReport{}, err
+		}
+	}
+	e.mu.RLock()
+	instance, exists := e.indexes[indexName]
+	e.mu.RUnlock()
+	if !exists {
+		return model.IntegrityReport{}, e.missingIndexError(indexName)
+	}
+	if instance.indexer == nil {
+		return model.IntegrityReport{}, fmt.Errorf("indexer service not initialized for index '%s'", indexName)
+	}
+
+	report := instance.indexer.Verify(repair)
+	if !report.Repaired {
+		return report, nil
+	}
+
+	e.mu.RLock()
+	err := e.persistUpdatedIndexUnsafe(context.Background(), indexName, *instance.settings, instance)
+	e.mu.RUnlock()
+	if err != nil {
+		return report, fmt.Errorf("failed to persist repaired index '%s': %w", indexName, err)
+	}
+	return report, nil
+}
@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code is a stable, machine-readable identifier of an error condition, returned to API clients
+// so they can branch on it instead of parsing error messages
+type Code string
+
+// Client error codes (4xx)
+const (
+	CodeValidationFailed     Code = "VALIDATION_FAILED"
+	CodeIndexNotFound        Code = "INDEX_NOT_FOUND"
+	CodeDocumentNotFound     Code = "DOCUMENT_NOT_FOUND"
+	CodeJobNotFound          Code = "JOB_NOT_FOUND"
+	CodeBatchNotFound        Code = "BATCH_NOT_FOUND"
+	CodeRuleNotFound         Code = "RULE_NOT_FOUND"
+	CodeShadowNotFound       Code = "SHADOW_NOT_FOUND"
+	CodeAPIKeyNotFound       Code = "API_KEY_NOT_FOUND"
+	CodeAliasNotFound        Code = "ALIAS_NOT_FOUND"
+	CodeIndexExists          Code = "INDEX_ALREADY_EXISTS"
+	CodeInvalidRequest       Code = "INVALID_REQUEST"
+	CodeInvalidJSON          Code = "INVALID_JSON"
+	CodeInvalidQuery         Code = "INVALID_QUERY"
+	CodeSameName             Code = "SAME_NAME_PROVIDED"
+	CodeIdempotencyKeyReused Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeReadOnly             Code = "READ_ONLY"
+	CodeIndexClosed          Code = "INDEX_CLOSED" // The index is closed and must be opened first
+	CodeIndexBusy            Code = "INDEX_BUSY"   // The index has jobs or write batches in progress
+	CodeJobFinished          Code = "JOB_FINISHED" // The job has finished, so it cannot be cancelled
+	CodeUnauthorized         Code = "UNAUTHORIZED" // No API key, or an unknown or expired one
+	CodeForbidden            Code = "FORBIDDEN"    // The API key does not allow the request
+	CodeRateLimited          Code = "RATE_LIMITED" // The client sent more requests than its rate limit
+)
+
+// Server error codes (5xx)
+const (
+	CodeInternalError      Code = "INTERNAL_ERROR"
+	CodeIndexingFailed     Code = "INDEXING_FAILED"
+	CodeSearchFailed       Code = "SEARCH_FAILED"
+	CodePersistenceFailed  Code = "PERSISTENCE_FAILED"
+	CodeJobExecutionFailed Code = "JOB_EXECUTION_FAILED"
+	CodeNotImplemented     Code = "NOT_IMPLEMENTED"
+)
+
+// Kind describes how an error code is reported: the HTTP status it is sent with and whether the
+// same request may succeed when retried later
+type Kind struct {
+	Code       Code
+	HTTPStatus int
+	Retryable  bool
+}
+
+// kinds is the error taxonomy. Client errors are never retryable, as the request itself must change,
+// except rate limiting, which the same request passes once the client slows down; server errors
+// are, except for features the engine does not implement.
+var kinds = map[Code]Kind{
+	CodeValidationFailed:     {CodeValidationFailed, http.StatusBadRequest, false},
+	CodeIndexNotFound:        {CodeIndexNotFound, http.StatusNotFound, false},
+	CodeDocumentNotFound:     {CodeDocumentNotFound, http.StatusNotFound, false},
+	CodeJobNotFound:          {CodeJobNotFound, http.StatusNotFound, false},
+	CodeBatchNotFound:        {CodeBatchNotFound, http.StatusNotFound, false},
+	CodeRuleNotFound:         {CodeRuleNotFound, http.StatusNotFound, false},
+	CodeShadowNotFound:       {CodeShadowNotFound, http.StatusNotFound, false},
+	CodeAPIKeyNotFound:       {CodeAPIKeyNotFound, http.StatusNotFound, false},
+	CodeAliasNotFound:        {CodeAliasNotFound, http.StatusNotFound, false},
+	CodeIndexExists:          {CodeIndexExists, http.StatusConflict, false},
+	CodeInvalidRequest:       {CodeInvalidRequest, http.StatusBadRequest, false},
+	CodeInvalidJSON:          {CodeInvalidJSON, http.StatusBadRequest, false},
+	CodeInvalidQuery:         {CodeInvalidQuery, http.StatusBadRequest, false},
+	CodeSameName:             {CodeSameName, http.StatusBadRequest, false},
+	CodeIdempotencyKeyReused: {CodeIdempotencyKeyReused, http.StatusConflict, false},
+	CodeReadOnly:             {CodeReadOnly, http.StatusForbidden, false},
+	CodeIndexClosed:          {CodeIndexClosed, http.StatusConflict, false},
+	CodeIndexBusy:            {CodeIndexBusy, http.StatusConflict, false},
+	CodeJobFinished:          {CodeJobFinished, http.StatusConflict, false},
+	CodeUnauthorized:         {CodeUnauthorized, http.StatusUnauthorized, false},
+	CodeForbidden:            {CodeForbidden, http.StatusForbidden, false},
+	CodeRateLimited:          {CodeRateLimited, http.StatusTooManyRequests, true},
+
+	CodeInternalError:      {CodeInternalError, http.StatusInternalServerError, true},
+	CodeIndexingFailed:     {CodeIndexingFailed, http.StatusInternalServerError, true},
+	CodeSearchFailed:       {CodeSearchFailed, http.StatusInternalServerError, true},
+	CodePersistenceFailed:  {CodePersistenceFailed, http.StatusInternalServerError, true},
+	CodeJobExecutionFailed: {CodeJobExecutionFailed, http.StatusInternalServerError, true},
+	CodeNotImplemented:     {CodeNotImplemented, http.StatusNotImplemented, false},
+}
+
+// KindOf returns the kind of an error code. Unknown codes are reported as internal errors.
+func KindOf(code Code) Kind {
+	if kind, ok := kinds[code]; ok {
+		return kind
+	}
+	return Kind{Code: code, HTTPStatus: http.StatusInternalServerError, Retryable: true}
+}
+
+// sentinelCodes maps the sentinel errors to their codes, in the order they are checked
+var sentinelCodes = []struct {
+	err  error
+	code Code
+}{
+	{ErrIndexNotFound, CodeIndexNotFound},
+	{ErrIndexAlreadyExists, CodeIndexExists},
+	{ErrDocumentNotFound, CodeDocumentNotFound},
+	{ErrJobNotFound, CodeJobNotFound},
+	{ErrBatchNotFound, CodeBatchNotFound},
+	{ErrRuleNotFound, CodeRuleNotFound},
+	{ErrShadowNotFound, CodeShadowNotFound},
+	{ErrAPIKeyNotFound, CodeAPIKeyNotFound},
+	{ErrAliasNotFound, CodeAliasNotFound},
+	{ErrSameName, CodeSameName},
+	{ErrIdempotencyKeyReused, CodeIdempotencyKeyReused},
+	{ErrReadOnly, CodeReadOnly},
+	{ErrIndexClosed, CodeIndexClosed},
+	{ErrIndexBusy, CodeIndexBusy},
+	{ErrJobFinished, CodeJobFinished},
+	{ErrInvalidQuery, CodeInvalidQuery},
+	{ErrInvalidInput, CodeValidationFailed},
+}
+
+// Classify returns the code of the error condition an error wraps, or the fallback code when it
+// wraps none of the sentinel errors.
+func Classify(err error, fallback Code) Code {
+	for _, sentinel := range sentinelCodes {
+		if errors.Is(err, sentinel.err) {
+			return sentinel.code
+		}
+	}
+	return fallback
+}
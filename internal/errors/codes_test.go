@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		code       Code
+		httpStatus int
+		retryable  bool
+	}{
+		{CodeValidationFailed, http.StatusBadRequest, false},
+		{CodeIndexNotFound, http.StatusNotFound, false},
+		{CodeIndexExists, http.StatusConflict, false},
+		{CodeReadOnly, http.StatusForbidden, false},
+		{CodeIndexClosed, http.StatusConflict, false},
+		{CodeUnauthorized, http.StatusUnauthorized, false},
+		{CodeForbidden, http.StatusForbidden, false},
+		{CodeRateLimited, http.StatusTooManyRequests, true},
+		{CodeInvalidQuery, http.StatusBadRequest, false},
+		{CodeSearchFailed, http.StatusInternalServerError, true},
+		{CodePersistenceFailed, http.StatusInternalServerError, true},
+		{CodeNotImplemented, http.StatusNotImplemented, false},
+		{Code("UNKNOWN"), http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		kind := KindOf(tt.code)
+		if kind.Code != tt.code || kind.HTTPStatus != tt.httpStatus || kind.Retryable != tt.retryable {
+			t.Errorf("KindOf(%s) = %+v, expected status %d and retryable %v", tt.code, kind, tt.httpStatus, tt.retryable)
+		}
+	}
+}
+
+func TestKindsAreConsistent(t *testing.T) {
+	for code, kind := range kinds {
+		if kind.Code != code {
+			t.Errorf("Kind of %s has code %s", code, kind.Code)
+		}
+		if kind.HTTPStatus < 400 || kind.HTTPStatus > 599 {
+			t.Errorf("Kind of %s has non-error status %d", code, kind.HTTPStatus)
+		}
+		if kind.HTTPStatus < 500 && kind.Retryable && kind.HTTPStatus != http.StatusTooManyRequests {
+			t.Errorf("Client error %s should not be retryable", code)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected Code
+	}{
+		{"index not found", NewIndexNotFoundError("movies"), CodeIndexNotFound},
+		{"index closed", NewIndexClosedError("movies"), CodeIndexClosed},
+		{"wrapped index busy", fmt.Errorf("close failed: %w", NewIndexBusyError("movies", "1 job in progress")), CodeIndexBusy},
+		{"job finished", NewJobFinishedError("job-1", "completed"), CodeJobFinished},
+		{"wrapped document not found", fmt.Errorf("failed to delete: %w", NewDocumentNotFoundError("doc1")), CodeDocumentNotFound},
+		{"wrapped invalid query", fmt.Errorf("error executing query 'q1': %w", NewInvalidQueryError("bad field")), CodeInvalidQuery},
+		{"validation error", NewValidationError("name", "is required"), CodeValidationFailed},
+		{"same name", NewSameNameError("movies"), CodeSameName},
+		{"API key not found", NewAPIKeyNotFoundError("key1"), CodeAPIKeyNotFound},
+		{"wrapped read-only", fmt.Errorf("failed to create index: %w", NewReadOnlyError("create index")), CodeReadOnly},
+		{"unknown error", errors.New("disk full"), CodeSearchFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := Classify(tt.err, CodeSearchFailed); code != tt.expected {
+				t.Errorf("Expected code %s, got %s", tt.expected, code)
+			}
+		})
+	}
+}
@@ -24,6 +24,39 @@ var (
 
 	// ErrSameName is returned when trying to rename to the same name
 	ErrSameName = errors.New("same name provided")
+
+	// ErrBatchNotFound is returned when a write batch is not found or has expired
+	ErrBatchNotFound = errors.New("batch not found")
+
+	// ErrRuleNotFound is returned when a rule is not found
+	ErrRuleNotFound = errors.New("rule not found")
+
+	// ErrShadowNotFound is returned when shadow mode is not enabled for an index
+	ErrShadowNotFound = errors.New("shadow mode not enabled")
+
+	// ErrAPIKeyNotFound is returned when an API key is not found
+	ErrAPIKeyNotFound = errors.New("API key not found")
+
+	// ErrAliasNotFound is returned when an index alias is not found
+	ErrAliasNotFound = errors.New("alias not found")
+
+	// ErrInvalidQuery is returned when a search query is well-formed but cannot be run against an index
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrIdempotencyKeyReused is returned when an idempotency key is sent again with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused")
+
+	// ErrReadOnly is returned when a write is attempted on an engine that opened its data directory read-only
+	ErrReadOnly = errors.New("read-only engine")
+
+	// ErrIndexClosed is returned when an index was closed, so its data is not loaded in memory
+	ErrIndexClosed = errors.New("index closed")
+
+	// ErrIndexBusy is returned when closing an index with jobs or write batches in progress
+	ErrIndexBusy = errors.New("index busy")
+
+	// ErrJobFinished is returned when cancelling a job that has already finished
+	ErrJobFinished = errors.New("job finished")
 )
 
 // IndexNotFoundError represents an index not found error with context
@@ -128,6 +161,24 @@ func NewValidationError(field, message string) *ValidationError {
 	return &ValidationError{Field: field, Message: message}
 }
 
+// InvalidQueryError represents a search query that cannot be run against an index
+type InvalidQueryError struct {
+	Message string
+}
+
+func (e *InvalidQueryError) Error() string {
+	return e.Message
+}
+
+func (e *InvalidQueryError) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
+
+// NewInvalidQueryError creates a new InvalidQueryError with a formatted message
+func NewInvalidQueryError(format string, args ...interface{}) *InvalidQueryError {
+	return &InvalidQueryError{Message: fmt.Sprintf(format, args...)}
+}
+
 // SameNameError represents an error when trying to rename to the same name
 type SameNameError struct {
 	Name string
@@ -145,3 +196,187 @@ func (e *SameNameError) Is(target error) bool {
 func NewSameNameError(name string) *SameNameError {
 	return &SameNameError{Name: name}
 }
+
+// BatchNotFoundError represents a write batch not found error with context
+type BatchNotFoundError struct {
+	BatchID string
+}
+
+func (e *BatchNotFoundError) Error() string {
+	return fmt.Sprintf("batch with ID '%s' not found", e.BatchID)
+}
+
+func (e *BatchNotFoundError) Is(target error) bool {
+	return target == ErrBatchNotFound
+}
+
+// NewBatchNotFoundError creates a new BatchNotFoundError
+func NewBatchNotFoundError(batchID string) *BatchNotFoundError {
+	return &BatchNotFoundError{BatchID: batchID}
+}
+
+// RuleNotFoundError represents a rule not found error with context
+type RuleNotFoundError struct {
+	RuleID    string
+	IndexName string
+}
+
+func (e *RuleNotFoundError) Error() string {
+	return fmt.Sprintf("rule with ID '%s' not found in index '%s'", e.RuleID, e.IndexName)
+}
+
+func (e *RuleNotFoundError) Is(target error) bool {
+	return target == ErrRuleNotFound
+}
+
+// NewRuleNotFoundError creates a new RuleNotFoundError
+func NewRuleNotFoundError(ruleID, indexName string) *RuleNotFoundError {
+	return &RuleNotFoundError{RuleID: ruleID, IndexName: indexName}
+}
+
+// ShadowNotFoundError represents an index without shadow mode enabled
+type ShadowNotFoundError struct {
+	IndexName string
+}
+
+func (e *ShadowNotFoundError) Error() string {
+	return fmt.Sprintf("shadow mode is not enabled for index '%s'", e.IndexName)
+}
+
+func (e *ShadowNotFoundError) Is(target error) bool {
+	return target == ErrShadowNotFound
+}
+
+// NewShadowNotFoundError creates a new ShadowNotFoundError
+func NewShadowNotFoundError(indexName string) *ShadowNotFoundError {
+	return &ShadowNotFoundError{IndexName: indexName}
+}
+
+// APIKeyNotFoundError represents an API key not found error with context
+type APIKeyNotFoundError struct {
+	KeyID string
+}
+
+func (e *APIKeyNotFoundError) Error() string {
+	return fmt.Sprintf("API key with ID '%s' not found", e.KeyID)
+}
+
+func (e *APIKeyNotFoundError) Is(target error) bool {
+	return target == ErrAPIKeyNotFound
+}
+
+// NewAPIKeyNotFoundError creates a new APIKeyNotFoundError
+func NewAPIKeyNotFoundError(keyID string) *APIKeyNotFoundError {
+	return &APIKeyNotFoundError{KeyID: keyID}
+}
+
+// AliasNotFoundError represents an index alias not found error with context
+type AliasNotFoundError struct {
+	Alias string
+}
+
+func (e *AliasNotFoundError) Error() string {
+	return fmt.Sprintf("alias named '%s' not found", e.Alias)
+}
+
+func (e *AliasNotFoundError) Is(target error) bool {
+	return target == ErrAliasNotFound
+}
+
+// NewAliasNotFoundError creates a new AliasNotFoundError
+func NewAliasNotFoundError(alias string) *AliasNotFoundError {
+	return &AliasNotFoundError{Alias: alias}
+}
+
+// IdempotencyKeyReusedError represents an idempotency key already used for a different request
+type IdempotencyKeyReusedError struct {
+	Key       string
+	IndexName string
+}
+
+func (e *IdempotencyKeyReusedError) Error() string {
+	return fmt.Sprintf("idempotency key '%s' was already used for a different request on index '%s'", e.Key, e.IndexName)
+}
+
+func (e *IdempotencyKeyReusedError) Is(target error) bool {
+	return target == ErrIdempotencyKeyReused
+}
+
+// NewIdempotencyKeyReusedError creates a new IdempotencyKeyReusedError
+func NewIdempotencyKeyReusedError(key, indexName string) *IdempotencyKeyReusedError {
+	return &IdempotencyKeyReusedError{Key: key, IndexName: indexName}
+}
+
+// ReadOnlyError represents a write attempted on an engine that opened its data directory read-only
+type ReadOnlyError struct {
+	Operation string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("cannot %s: the engine opened its data directory read-only", e.Operation)
+}
+
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnly
+}
+
+// NewReadOnlyError creates a new ReadOnlyError
+func NewReadOnlyError(operation string) *ReadOnlyError {
+	return &ReadOnlyError{Operation: operation}
+}
+
+// IndexClosedError represents an operation on an index that was closed
+type IndexClosedError struct {
+	IndexName string
+}
+
+func (e *IndexClosedError) Error() string {
+	return fmt.Sprintf("index named '%s' is closed; open it first", e.IndexName)
+}
+
+func (e *IndexClosedError) Is(target error) bool {
+	return target == ErrIndexClosed
+}
+
+// NewIndexClosedError creates a new IndexClosedError
+func NewIndexClosedError(indexName string) *IndexClosedError {
+	return &IndexClosedError{IndexName: indexName}
+}
+
+// IndexBusyError represents an index that cannot be closed while work on it is in progress
+type IndexBusyError struct {
+	IndexName string
+	Reason    string
+}
+
+func (e *IndexBusyError) Error() string {
+	return fmt.Sprintf("index named '%s' cannot be closed: %s", e.IndexName, e.Reason)
+}
+
+func (e *IndexBusyError) Is(target error) bool {
+	return target == ErrIndexBusy
+}
+
+// NewIndexBusyError creates a new IndexBusyError
+func NewIndexBusyError(indexName, reason string) *IndexBusyError {
+	return &IndexBusyError{IndexName: indexName, Reason: reason}
+}
+
+// JobFinishedError represents a job that cannot be cancelled because it has finished
+type JobFinishedError struct {
+	JobID  string
+	Status string
+}
+
+func (e *JobFinishedError) Error() string {
+	return fmt.Sprintf("job with ID '%s' cannot be cancelled: it is %s", e.JobID, e.Status)
+}
+
+func (e *JobFinishedError) Is(target error) bool {
+	return target == ErrJobFinished
+}
+
+// NewJobFinishedError creates a new JobFinishedError
+func NewJobFinishedError(jobID, status string) *JobFinishedError {
+	return &JobFinishedError{JobID: jobID, Status: status}
+}
@@ -128,6 +128,93 @@ func TestSameNameError(t *testing.T) {
 	}
 }
 
+func TestBatchNotFoundError(t *testing.T) {
+	err := NewBatchNotFoundError("batch-123")
+
+	expectedMsg := "batch with ID 'batch-123' not found"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
+	}
+
+	// Test Is() method
+	if !errors.Is(err, ErrBatchNotFound) {
+		t.Error("Expected error to match ErrBatchNotFound sentinel")
+	}
+}
+
+func TestRuleNotFoundError(t *testing.T) {
+	err := NewRuleNotFoundError("rule-123", "movies")
+
+	expectedMsg := "rule with ID 'rule-123' not found in index 'movies'"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
+	}
+
+	// Test Is() method
+	if !errors.Is(err, ErrRuleNotFound) {
+		t.Error("Expected error to match ErrRuleNotFound sentinel")
+	}
+}
+
+func TestShadowNotFoundError(t *testing.T) {
+	err := NewShadowNotFoundError("movies")
+
+	expectedMsg := "shadow mode is not enabled for index 'movies'"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
+	}
+
+	// Test Is() method
+	if !errors.Is(err, ErrShadowNotFound) {
+		t.Error("Expected error to match ErrShadowNotFound sentinel")
+	}
+}
+
+func TestAliasNotFoundError(t *testing.T) {
+	err := NewAliasNotFoundError("movies")
+
+	expectedMsg := "alias named 'movies' not found"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
+	}
+
+	// Test Is() method
+	if !errors.Is(err, ErrAliasNotFound) {
+		t.Error("Expected error to match ErrAliasNotFound sentinel")
+	}
+}
+
+func TestIdempotencyKeyReusedError(t *testing.T) {
+	err := NewIdempotencyKeyReusedError("import-42", "movies")
+
+	expectedMsg := "idempotency key 'import-42' was already used for a different request on index 'movies'"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
+	}
+
+	// Test Is() method
+	if !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Error("Expected error to match ErrIdempotencyKeyReused sentinel")
+	}
+}
+
+func TestInvalidQueryError(t *testing.T) {
+	err := NewInvalidQueryError("facet field '%s' is not configured as a filterable field", "genre")
+
+	expectedMsg := "facet field 'genre' is not configured as a filterable field"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
+	}
+
+	// Test Is() method
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Error("Expected error to match ErrInvalidQuery sentinel")
+	}
+	if errors.Is(err, ErrInvalidInput) {
+		t.Error("Error should not match ErrInvalidInput")
+	}
+}
+
 func TestErrorChaining(t *testing.T) {
 	// Test that our custom errors can be wrapped and unwrapped
 	originalErr := NewIndexNotFoundError("test-index")
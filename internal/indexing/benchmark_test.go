@@ -41,10 +41,7 @@ func createTestService() *Service {
 		NextID:                 0,
 	}
 
-	invIndex := &index.InvertedIndex{
-		Index:    make(map[string]index.PostingList),
-		Settings: settings,
-	}
+	invIndex := index.NewInvertedIndex(settings)
 
 	service, _ := NewService(invIndex, docStore)
 	return service
@@ -2,7 +2,8 @@ package indexing
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
 	"runtime"
 	"sort"
 	"strings"
@@ -43,10 +44,12 @@ type BulkIndexer struct {
 	pendingUpdates  map[string][]index.PostingEntry // Token -> pending entries
 	pendingDocs     map[uint32]model.Document       // Pending document updates
 	pendingMappings map[string]uint32               // Pending ID mappings
+	pendingLengths  map[uint32]map[string]int       // Pending field lengths by document
 	mu              sync.RWMutex
 	lastFlush       time.Time
 	processedCount  int
 	totalCount      int
+	logOperations   bool // Whether flushed documents are recorded in the service's operation log and assigned sequence numbers
 }
 
 // NewBulkIndexer creates a new bulk indexer with the given configuration
@@ -56,12 +59,15 @@ func NewBulkIndexer(service *Service, config BulkIndexingConfig) *BulkIndexer {
 		config:          config,
 		pendingUpdates:  make(map[string][]index.PostingEntry),
 		pendingDocs:     make(map[uint32]model.Document),
+		logOperations:   true,
 		pendingMappings: make(map[string]uint32),
+		pendingLengths:  make(map[uint32]map[string]int),
 		lastFlush:       time.Now(),
 	}
 }
 
-// BulkAddDocuments efficiently adds a large number of documents using parallel processing
+// BulkAddDocuments efficiently adds a large number of documents using parallel processing.
+// The caller must hold the service's write lock.
 func (bi *BulkIndexer) BulkAddDocuments(docs []model.Document) error {
 	bi.totalCount = len(docs)
 	bi.processedCount = 0
@@ -70,7 +76,7 @@ func (bi *BulkIndexer) BulkAddDocuments(docs []model.Document) error {
 		return nil
 	}
 
-	log.Printf("Starting bulk indexing of %d documents with %d workers", len(docs), bi.config.WorkerCount)
+	slog.Debug("bulk indexing started", "documents", len(docs), "workers", bi.config.WorkerCount)
 	start := time.Now()
 
 	// Create worker pool
@@ -122,8 +128,8 @@ func (bi *BulkIndexer) BulkAddDocuments(docs []model.Document) error {
 	}
 
 	duration := time.Since(start)
-	log.Printf("Bulk indexing completed: %d documents in %v (%.2f docs/sec)",
-		len(docs), duration, float64(len(docs))/duration.Seconds())
+	slog.Info("bulk indexing completed", "documents", len(docs), "duration", duration,
+		"docs_per_sec", float64(len(docs))/duration.Seconds())
 
 	return nil
 }
@@ -133,6 +139,7 @@ type bulkProcessResult struct {
 	tokenUpdates map[string][]index.PostingEntry
 	docUpdates   map[uint32]model.Document
 	idMappings   map[string]uint32
+	fieldLengths map[uint32]map[string]int
 	processed    int
 }
 
@@ -159,10 +166,12 @@ func (bi *BulkIndexer) processBatch(docs []model.Document) (*bulkProcessResult,
 		tokenUpdates: make(map[string][]index.PostingEntry),
 		docUpdates:   make(map[uint32]model.Document),
 		idMappings:   make(map[string]uint32),
+		fieldLengths: make(map[uint32]map[string]int),
 		processed:    len(docs),
 	}
 
 	settings := bi.service.invertedIndex.Settings
+	analyzer := bi.service.analyzer()
 
 	// Pre-allocate internal IDs for this batch to avoid contention
 	bi.service.documentStore.Mu.Lock()
@@ -202,12 +211,18 @@ func (bi *BulkIndexer) processBatch(docs []model.Document) (*bulkProcessResult,
 	bi.service.documentStore.Mu.Unlock()
 
 	// Process documents without holding locks
+	nestedFields := settings.NestedFields()
 	for _, doc := range docs {
 		docIDStr := strings.TrimSpace(doc["documentID"].(string))
 		internalID := batchIDMappings[docIDStr]
 
+		doc = maps.Clone(doc)
+		flattenNestedFields(doc, nestedFields)
+		routeLanguage(doc, settings.LanguageDetection)
 		result.docUpdates[internalID] = doc
 		result.idMappings[docIDStr] = internalID
+		fieldLengths := make(map[string]int)
+		result.fieldLengths[internalID] = fieldLengths
 
 		// Process each searchable field
 		for _, fieldName := range settings.SearchableFields {
@@ -216,12 +231,12 @@ func (bi *BulkIndexer) processBatch(docs []model.Document) (*bulkProcessResult,
 				continue
 			}
 
-			textContent := bi.extractTextContent(fieldVal)
+			textContent := extractTextContent(fieldVal)
 			if strings.TrimSpace(textContent) == "" {
 				continue
 			}
 
-			tokens := generateTokensForField(textContent, fieldName, settings)
+			tokens := analyzer.FieldTokens(textContent, fieldName)
 			if len(tokens) == 0 {
 				continue
 			}
@@ -232,12 +247,18 @@ func (bi *BulkIndexer) processBatch(docs []model.Document) (*bulkProcessResult,
 				termFrequencies[token]++
 			}
 
+			positions := analyzer.FieldWordPositions(textContent, fieldName)
+			fieldLengths[fieldName] = wordCount(positions)
+
 			// Create posting entries for each unique token
 			for token, freq := range termFrequencies {
+				termPositions, isFullWord := positions[token]
 				entry := index.PostingEntry{
-					DocID:     internalID,
-					FieldName: fieldName,
-					Score:     float64(freq),
+					DocID:      internalID,
+					FieldName:  fieldName,
+					Score:      float64(freq),
+					IsFullWord: isFullWord,
+					Positions:  termPositions,
 				}
 				result.tokenUpdates[token] = append(result.tokenUpdates[token], entry)
 			}
@@ -268,6 +289,9 @@ func (bi *BulkIndexer) resultCollector(resultChan <-chan *bulkProcessResult, don
 		for extID, intID := range result.idMappings {
 			bi.pendingMappings[extID] = intID
 		}
+		for id, lengths := range result.fieldLengths {
+			bi.pendingLengths[id] = lengths
+		}
 
 		bi.processedCount += result.processed
 		bi.mu.Unlock()
@@ -286,13 +310,24 @@ func (bi *BulkIndexer) resultCollector(resultChan <-chan *bulkProcessResult, don
 
 		if shouldFlush {
 			if err := bi.flush(); err != nil {
-				log.Printf("Error during flush: %v", err)
+				slog.Error("bulk indexing flush failed", "error", err)
 			}
 		}
 	}
 }
 
-// flush applies all pending updates to the actual index
+// stagedFlush holds the pending updates of a flush, with the posting lists already merged with
+// the ones in the index, so committing them cannot fail half-way
+type stagedFlush struct {
+	docs     map[uint32]model.Document
+	mappings map[string]uint32
+	lengths  map[uint32]map[string]int
+	postings map[string]index.PostingList // Merged posting list by term
+}
+
+// flush applies all pending updates to the actual index. The updates are staged first and then
+// committed documents first, so posting lists never reference documents that are not stored,
+// neither for searches running during the flush nor if the flush is interrupted.
 func (bi *BulkIndexer) flush() error {
 	bi.mu.Lock()
 	defer bi.mu.Unlock()
@@ -301,43 +336,86 @@ func (bi *BulkIndexer) flush() error {
 		return nil
 	}
 
-	log.Printf("Flushing %d token updates and %d document updates",
-		len(bi.pendingUpdates), len(bi.pendingDocs))
+	slog.Debug("flushing bulk indexing updates", "token_updates", len(bi.pendingUpdates), "document_updates", len(bi.pendingDocs))
 
-	// Acquire locks for the flush operation
-	bi.service.documentStore.Mu.Lock()
-	bi.service.invertedIndex.Mu.Lock()
-	defer bi.service.documentStore.Mu.Unlock()
-	defer bi.service.invertedIndex.Mu.Unlock()
+	// Searches keep running during the flush; they only wait for the documents and term shards being updated
+	bi.service.invertedIndex.Mu.RLock()
+	defer bi.service.invertedIndex.Mu.RUnlock()
 
-	// Apply document updates
-	for id, doc := range bi.pendingDocs {
-		bi.service.documentStore.Docs[id] = doc
+	staged := bi.stageUnsafe()
+	if bi.logOperations {
+		bi.recordPendingOperations()
 	}
-
-	// Apply ID mappings
-	for extID, intID := range bi.pendingMappings {
-		bi.service.documentStore.ExternalIDtoInternalID[extID] = intID
-	}
-
-	// Apply token updates efficiently
-	for token, newEntries := range bi.pendingUpdates {
-		currentList := bi.service.invertedIndex.Index[token]
-
-		// Merge and sort the posting list
-		mergedList := bi.mergePostingLists(currentList, newEntries)
-		bi.service.invertedIndex.Index[token] = mergedList
+	bi.commit(staged)
+	if bi.logOperations {
+		bi.service.documentStore.AdvanceSeq(uint64(len(staged.docs)))
 	}
 
 	// Clear pending updates
 	bi.pendingUpdates = make(map[string][]index.PostingEntry)
 	bi.pendingDocs = make(map[uint32]model.Document)
 	bi.pendingMappings = make(map[string]uint32)
+	bi.pendingLengths = make(map[uint32]map[string]int)
 	bi.lastFlush = time.Now()
 
 	return nil
 }
 
+// stageUnsafe merges the pending token updates with the posting lists in the index, without
+// modifying the index. The caller must hold bi.mu and the service's write lock, so the posting
+// lists don't change until the staged updates are committed.
+func (bi *BulkIndexer) stageUnsafe() stagedFlush {
+	staged := stagedFlush{
+		docs:     bi.pendingDocs,
+		mappings: bi.pendingMappings,
+		lengths:  bi.pendingLengths,
+		postings: make(map[string]index.PostingList, len(bi.pendingUpdates)),
+	}
+	for token, newEntries := range bi.pendingUpdates {
+		currentList, _ := bi.service.invertedIndex.Get(token)
+		staged.postings[token] = bi.mergePostingLists(currentList, newEntries)
+	}
+	return staged
+}
+
+// commit applies staged updates to the document store and then to the inverted index.
+func (bi *BulkIndexer) commit(staged stagedFlush) {
+	bi.service.documentStore.Mu.Lock()
+	for id, doc := range staged.docs {
+		bi.service.documentStore.SetUnsafe(id, doc)
+	}
+	for extID, intID := range staged.mappings {
+		bi.service.documentStore.ExternalIDtoInternalID[extID] = intID
+		bi.service.changes.touchDocument(extID)
+	}
+	bi.service.documentStore.Mu.Unlock()
+	for id, lengths := range staged.lengths {
+		bi.service.invertedIndex.SetFieldLengths(id, lengths)
+	}
+
+	for token, mergedList := range staged.postings {
+		bi.service.invertedIndex.Set(token, mergedList)
+		bi.service.changes.touchTerm(token)
+	}
+}
+
+// recordPendingOperations logs the pending documents, in internal ID order, with the version they replace.
+// The caller must hold bi.mu and the service's write lock.
+func (bi *BulkIndexer) recordPendingOperations() {
+	ids := make([]uint32, 0, len(bi.pendingDocs))
+	for id := range bi.pendingDocs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		doc := bi.pendingDocs[id]
+		docID, _ := doc["documentID"].(string)
+		previous, _ := bi.service.documentStore.Get(id)
+		bi.service.oplog.record(strings.TrimSpace(docID), previous)
+	}
+}
+
 // mergePostingLists efficiently merges two posting lists while maintaining sort order
 func (bi *BulkIndexer) mergePostingLists(existing, new []index.PostingEntry) index.PostingList {
 	if len(existing) == 0 {
@@ -393,7 +471,7 @@ func (bi *BulkIndexer) mergePostingLists(existing, new []index.PostingEntry) ind
 }
 
 // extractTextContent extracts text content from various field types
-func (bi *BulkIndexer) extractTextContent(fieldVal interface{}) string {
+func extractTextContent(fieldVal interface{}) string {
 	switch v := fieldVal.(type) {
 	case string:
 		return v
@@ -425,43 +503,92 @@ func (bi *BulkIndexer) estimateMemoryUsage() int {
 
 // BulkReindex performs an optimized reindexing operation
 func (s *Service) BulkReindex(config BulkIndexingConfig) error {
-	log.Printf("Starting bulk reindex operation")
+	slog.Debug("bulk reindex started")
 	start := time.Now()
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
 	// Extract all documents efficiently
-	s.documentStore.Mu.RLock()
-	docs := make([]model.Document, 0, len(s.documentStore.Docs))
-	for _, doc := range s.documentStore.Docs {
+	docs := make([]model.Document, 0, s.documentStore.Len())
+	s.documentStore.Range(func(_ uint32, doc model.Document) bool {
 		docs = append(docs, doc)
-	}
-	s.documentStore.Mu.RUnlock()
+		return true
+	})
 
 	if len(docs) == 0 {
-		log.Printf("No documents to reindex")
+		slog.Debug("no documents to reindex")
 		return nil
 	}
 
-	log.Printf("Extracted %d documents for reindexing", len(docs))
+	slog.Debug("documents extracted for reindexing", "documents", len(docs))
 
 	// Clear the index efficiently
-	s.documentStore.Mu.Lock()
 	s.invertedIndex.Mu.Lock()
-	s.documentStore.Docs = make(map[uint32]model.Document)
-	s.documentStore.ExternalIDtoInternalID = make(map[string]uint32)
-	s.documentStore.NextID = 0
-	s.invertedIndex.Index = make(map[string]index.PostingList)
-	s.documentStore.Mu.Unlock()
+	s.documentStore.Reset()
+	s.invertedIndex.Reset()
+	s.changes.touchAll()
 	s.invertedIndex.Mu.Unlock()
 
-	// Use bulk indexer for efficient re-indexing
+	// Use bulk indexer for efficient re-indexing. Documents keep their content, so nothing is logged for rollback.
 	bulkIndexer := NewBulkIndexer(s, config)
+	bulkIndexer.logOperations = false
 	if err := bulkIndexer.BulkAddDocuments(docs); err != nil {
 		return fmt.Errorf("bulk reindex failed: %w", err)
 	}
 
 	duration := time.Since(start)
-	log.Printf("Bulk reindex completed: %d documents in %v (%.2f docs/sec)",
-		len(docs), duration, float64(len(docs))/duration.Seconds())
+	slog.Info("bulk reindex completed", "documents", len(docs), "duration", duration,
+		"docs_per_sec", float64(len(docs))/duration.Seconds())
 
 	return nil
 }
+
+// BulkAddDocuments indexes documents with the bulk indexer. The bulk indexer merges postings by
+// document and field and cannot remove the words a document no longer contains, so documents
+// already in the index are updated one by one instead, like AddDocuments does. When a document ID
+// appears more than once, its last document is indexed.
+func (s *Service) BulkAddDocuments(docs []model.Document, config BulkIndexingConfig) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	lastPosition := make(map[string]int, len(docs))
+	for i, doc := range docs {
+		docID, ok := doc["documentID"].(string)
+		if !ok || strings.TrimSpace(docID) == "" {
+			return fmt.Errorf("document at position %d has no valid documentID", i)
+		}
+		lastPosition[strings.TrimSpace(docID)] = i
+	}
+
+	newDocs := make([]model.Document, 0, len(lastPosition))
+	updates := make([]model.Document, 0)
+	for i, doc := range docs {
+		docID := strings.TrimSpace(doc["documentID"].(string))
+		if lastPosition[docID] != i {
+			continue
+		}
+		if _, exists := s.documentStore.ExternalIDtoInternalID[docID]; exists {
+			updates = append(updates, doc)
+		} else {
+			newDocs = append(newDocs, doc)
+		}
+	}
+
+	if len(updates) > 0 {
+		analyzer := s.analyzer()
+		s.invertedIndex.Mu.RLock()
+		for _, doc := range updates {
+			if err := s.addSingleDocumentUnsafe(doc, analyzer); err != nil {
+				s.invertedIndex.Mu.RUnlock()
+				return fmt.Errorf("failed to update document ID %v: %w", doc["documentID"], err)
+			}
+		}
+		s.invertedIndex.Mu.RUnlock()
+	}
+
+	if len(newDocs) == 0 {
+		return nil
+	}
+	return NewBulkIndexer(s, config).BulkAddDocuments(newDocs)
+}
@@ -0,0 +1,125 @@
+package indexing
+
+import (
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// changeSet records the terms and documents that writes changed since the last delta was collected.
+// Like the operation log, it is guarded by the service's write lock.
+type changeSet struct {
+	terms     map[string]struct{}
+	documents map[string]struct{} // External document IDs
+	all       bool                // Everything may have changed, e.g. after all documents were deleted
+}
+
+func newChangeSet() *changeSet {
+	return &changeSet{
+		terms:     make(map[string]struct{}),
+		documents: make(map[string]struct{}),
+	}
+}
+
+// touchTerm records that the posting list of a term changed. It is a no-op when changes are not tracked.
+func (c *changeSet) touchTerm(term string) {
+	if c != nil && !c.all {
+		c.terms[term] = struct{}{}
+	}
+}
+
+// touchDocument records that a document or its ID mapping changed. It is a no-op when changes are not tracked.
+func (c *changeSet) touchDocument(documentID string) {
+	if c != nil && !c.all {
+		c.documents[documentID] = struct{}{}
+	}
+}
+
+// touchAll records that the whole index may have changed. It is a no-op when changes are not tracked.
+func (c *changeSet) touchAll() {
+	if c != nil {
+		c.all = true
+		clear(c.terms)
+		clear(c.documents)
+	}
+}
+
+// Delta holds the postings and documents changed since the previous delta was collected.
+type Delta struct {
+	Full      bool                         // The whole index changed: Postings and Documents hold all of it
+	Postings  map[string]index.PostingList // Posting lists by term; nil for removed terms
+	Documents map[string]DocumentChange    // Changed documents by external ID
+	NextID    uint32
+	Seq       uint64 // Sequence number of the latest document mutation included
+}
+
+// DocumentChange is the state of a changed document in a Delta.
+type DocumentChange struct {
+	InternalID   uint32
+	Document     model.Document // nil when the document was deleted
+	FieldLengths map[string]int // Words per searchable field of the document
+}
+
+// Empty reports whether nothing changed.
+func (d Delta) Empty() bool {
+	return !d.Full && len(d.Postings) == 0 && len(d.Documents) == 0
+}
+
+// TrackChanges starts or stops recording the terms and documents changed by writes. The first
+// delta collected after tracking starts is full.
+func (s *Service) TrackChanges(enabled bool) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if !enabled {
+		s.changes = nil
+		return
+	}
+	s.changes = newChangeSet()
+	s.changes.all = true
+}
+
+// CollectDelta returns the postings and documents changed since the previous delta and starts
+// recording anew. Both are shared with the index rather than copied: stored posting lists and
+// documents are replaced, never modified.
+func (s *Service) CollectDelta() Delta {
+	s.writeMu.Lock()
+	s.invertedIndex.Mu.RLock()
+	defer s.writeMu.Unlock()
+	defer s.invertedIndex.Mu.RUnlock()
+
+	delta := Delta{NextID: s.documentStore.NextID, Seq: s.documentStore.Seq()}
+	if s.changes == nil {
+		return delta
+	}
+
+	if s.changes.all {
+		delta.Full = true
+		delta.Postings = make(map[string]index.PostingList, s.invertedIndex.Len())
+		s.invertedIndex.Range(func(term string, postings index.PostingList) bool {
+			delta.Postings[term] = postings
+			return true
+		})
+		delta.Documents = make(map[string]DocumentChange, len(s.documentStore.ExternalIDtoInternalID))
+		for documentID, internalID := range s.documentStore.ExternalIDtoInternalID {
+			doc, _ := s.documentStore.Get(internalID)
+			delta.Documents[documentID] = DocumentChange{InternalID: internalID, Document: doc, FieldLengths: s.invertedIndex.FieldLengths(internalID)}
+		}
+	} else {
+		delta.Postings = make(map[string]index.PostingList, len(s.changes.terms))
+		for term := range s.changes.terms {
+			delta.Postings[term], _ = s.invertedIndex.Get(term)
+		}
+		delta.Documents = make(map[string]DocumentChange, len(s.changes.documents))
+		for documentID := range s.changes.documents {
+			change := DocumentChange{}
+			if internalID, exists := s.documentStore.ExternalIDtoInternalID[documentID]; exists {
+				doc, _ := s.documentStore.Get(internalID)
+				change = DocumentChange{InternalID: internalID, Document: doc, FieldLengths: s.invertedIndex.FieldLengths(internalID)}
+			}
+			delta.Documents[documentID] = change
+		}
+	}
+
+	s.changes = newChangeSet()
+	return delta
+}
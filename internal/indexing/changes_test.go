@@ -0,0 +1,66 @@
+package indexing
+
+import (
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestCollectDelta(t *testing.T) {
+	s := newRollbackTestService(t)
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc1", "title": "Alpha"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	if delta := s.CollectDelta(); !delta.Empty() {
+		t.Fatalf("CollectDelta() without tracking = %+v, want empty", delta)
+	}
+
+	s.TrackChanges(true)
+	delta := s.CollectDelta()
+	if !delta.Full || len(delta.Documents) != 1 || delta.Postings["alpha"] == nil {
+		t.Fatalf("First CollectDelta() = %+v, want the full index", delta)
+	}
+	if delta := s.CollectDelta(); !delta.Empty() {
+		t.Fatalf("CollectDelta() without writes = %+v, want empty", delta)
+	}
+
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc2", "title": "Beta"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	if err := s.DeleteDocument("doc1"); err != nil {
+		t.Fatalf("DeleteDocument() error = %v", err)
+	}
+
+	delta = s.CollectDelta()
+	if delta.Full {
+		t.Fatal("CollectDelta() after writes is full, want only the changes")
+	}
+	if change, ok := delta.Documents["doc1"]; !ok || change.Document != nil {
+		t.Errorf("Delta for deleted doc1 = %+v, want a deletion", change)
+	}
+	if change, ok := delta.Documents["doc2"]; !ok || change.Document["title"] != "Beta" {
+		t.Errorf("Delta for added doc2 = %+v, want the document", change)
+	}
+	if postings, ok := delta.Postings["alpha"]; !ok || postings != nil {
+		t.Errorf("Delta postings for removed term alpha = %v, want nil", postings)
+	}
+	if len(delta.Postings["beta"]) != 1 {
+		t.Errorf("Delta postings for beta = %v, want one posting", delta.Postings["beta"])
+	}
+
+	// Copies are not affected by later writes
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc3", "title": "Beta"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	if len(delta.Postings["beta"]) != 1 {
+		t.Errorf("Collected postings changed after a write: %v", delta.Postings["beta"])
+	}
+
+	if err := s.DeleteAllDocuments(); err != nil {
+		t.Fatalf("DeleteAllDocuments() error = %v", err)
+	}
+	if delta := s.CollectDelta(); !delta.Full || len(delta.Documents) != 0 {
+		t.Errorf("CollectDelta() after deleting all documents = %+v, want an empty full delta", delta)
+	}
+}
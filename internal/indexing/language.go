@@ -0,0 +1,50 @@
+package indexing
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/langdetect"
+	"github.com/gcbaptista/go-search-engine/internal/tokenizer"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// routeLanguage stores the language of a document in its language field and copies the text of
+// the detection fields to the per-language fields of that language. A language already set on the
+// document is kept when it is one of the candidates, so clients can override detection and stored
+// documents keep their language when reindexed. Per-language fields of other languages are
+// removed, and documents whose language is not detected get no per-language fields.
+func routeLanguage(doc model.Document, detection *config.LanguageDetection) {
+	if detection == nil {
+		return
+	}
+	languageField := detection.LanguageFieldName()
+
+	language, _ := doc[languageField].(string)
+	if !slices.Contains(detection.Languages, language) {
+		var parts []string
+		for _, field := range detection.Fields {
+			if text := extractTextContent(doc[field]); strings.TrimSpace(text) != "" {
+				parts = append(parts, text)
+			}
+		}
+		language = langdetect.Detect(strings.Join(parts, " "), detection.Languages)
+	}
+
+	for _, field := range detection.Fields {
+		for _, candidate := range detection.Languages {
+			delete(doc, tokenizer.LanguageField(field, candidate))
+		}
+	}
+	if language == "" {
+		return
+	}
+
+	doc[languageField] = language
+	for _, field := range detection.Fields {
+		if value, exists := doc[field]; exists {
+			doc[tokenizer.LanguageField(field, language)] = value
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package indexing
+
+import (
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// flattenNestedFields stores the values of the dot-notation paths of a document's nested objects
+// under the path itself, so "credits.cast.name" holds the names of the cast of {"credits": {"cast":
+// [{"name": ...}]}}. Arrays of objects contribute the values of all their objects, as an array; a
+// path through objects only keeps its value as is. A path whose first segment is not an object or
+// array in the document is left alone, so documents can still set dotted fields themselves, and a
+// path that resolves to nothing is removed.
+func flattenNestedFields(doc model.Document, paths []string) {
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		switch doc[segments[0]].(type) {
+		case map[string]interface{}, model.Document, []interface{}, []map[string]interface{}:
+		default:
+			continue
+		}
+		if value, found := resolvePath(doc[segments[0]], segments[1:]); found {
+			doc[path] = value
+		} else {
+			delete(doc, path)
+		}
+	}
+}
+
+// resolvePath returns the value at the path of segments below value, descending into every object
+// of arrays along the way.
+func resolvePath(value interface{}, segments []string) (interface{}, bool) {
+	if len(segments) == 0 {
+		return value, true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, exists := v[segments[0]]
+		if !exists {
+			return nil, false
+		}
+		return resolvePath(child, segments[1:])
+	case model.Document:
+		return resolvePath(map[string]interface{}(v), segments)
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return resolvePath(items, segments)
+	case []interface{}:
+		var values []interface{}
+		for _, item := range v {
+			found, ok := resolvePath(item, segments)
+			if !ok {
+				continue
+			}
+			if list, isList := found.([]interface{}); isList {
+				values = append(values, list...)
+			} else {
+				values = append(values, found)
+			}
+		}
+		return values, len(values) > 0
+	}
+	return nil, false
+}
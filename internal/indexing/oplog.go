@@ -0,0 +1,85 @@
+package indexing
+
+import (
+	"fmt"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// DefaultOperationLogSize is the number of document operations kept for rollback per index.
+const DefaultOperationLogSize = 1000
+
+// operationRecord captures the state of a document before an upsert or delete changed it.
+type operationRecord struct {
+	documentID string
+	previous   model.Document // Version before the operation; nil if the operation created the document
+}
+
+// operationLog is a bounded log of document operations, oldest first.
+// It is guarded by the service's write lock.
+type operationLog struct {
+	records []operationRecord
+	size    int
+	paused  bool // Set while operations are being reversed so the reversal is not logged
+}
+
+func newOperationLog(size int) *operationLog {
+	return &operationLog{size: size}
+}
+
+// record appends an operation, discarding the oldest one when the log is full.
+func (l *operationLog) record(documentID string, previous model.Document) {
+	if l.paused || l.size <= 0 {
+		return
+	}
+	if len(l.records) == l.size {
+		copy(l.records, l.records[1:])
+		l.records = l.records[:len(l.records)-1]
+	}
+	l.records = append(l.records, operationRecord{documentID: documentID, previous: previous})
+}
+
+// OperationLogLength returns the number of document operations that can currently be rolled back.
+func (s *Service) OperationLogLength() int {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return len(s.oplog.records)
+}
+
+// Rollback reverses the last n document operations, newest first: updated and deleted documents
+// are restored to their previous version and created documents are removed. Like ApplyBatch, the
+// whole rollback becomes visible at once.
+func (s *Service) Rollback(n int) error {
+	s.writeMu.Lock()
+	s.invertedIndex.Mu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.invertedIndex.Mu.Unlock()
+
+	if n <= 0 {
+		return errors.NewValidationError("ops", "must be a positive number")
+	}
+	if n > len(s.oplog.records) {
+		return errors.NewValidationError("ops", fmt.Sprintf("only %d operation(s) can be rolled back", len(s.oplog.records)))
+	}
+
+	s.oplog.paused = true
+	defer func() { s.oplog.paused = false }()
+
+	analyzer := s.analyzer()
+	reversed := s.oplog.records[len(s.oplog.records)-n:]
+	for i := len(reversed) - 1; i >= 0; i-- {
+		record := reversed[i]
+		if record.previous != nil {
+			if err := s.addSingleDocumentUnsafe(record.previous, analyzer); err != nil {
+				return fmt.Errorf("failed to restore document ID %s: %w", record.documentID, err)
+			}
+		} else if _, exists := s.documentStore.ExternalIDtoInternalID[record.documentID]; exists {
+			if err := s.deleteDocumentUnsafe(record.documentID, analyzer); err != nil {
+				return fmt.Errorf("failed to remove document ID %s: %w", record.documentID, err)
+			}
+		}
+	}
+	s.oplog.records = s.oplog.records[:len(s.oplog.records)-n]
+	return nil
+}
@@ -0,0 +1,130 @@
+package indexing
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/index"
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/store"
+)
+
+func newRollbackTestService(t *testing.T) *Service {
+	t.Helper()
+	invIdx := index.NewInvertedIndex(newTestSettings())
+	docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
+	s, err := NewService(invIdx, docStore)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	return s
+}
+
+func storedTitle(s *Service, docID string) (string, bool) {
+	internalID, exists := s.documentStore.ExternalIDtoInternalID[docID]
+	if !exists {
+		return "", false
+	}
+	title, _ := s.documentStore.Docs[internalID]["title"].(string)
+	return title, true
+}
+
+func TestRollback(t *testing.T) {
+	s := newRollbackTestService(t)
+
+	if err := s.AddDocuments([]model.Document{
+		{"documentID": "doc1", "title": "Original Title"},
+		{"documentID": "doc2", "title": "Second Document"},
+	}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	// A bad ingestion run: one update, one delete and one new document
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc1", "title": "Corrupted"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	if err := s.DeleteDocument("doc2"); err != nil {
+		t.Fatalf("DeleteDocument() error = %v", err)
+	}
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc3", "title": "Unwanted"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	if got := s.OperationLogLength(); got != 5 {
+		t.Fatalf("OperationLogLength() = %d, want 5", got)
+	}
+
+	if err := s.Rollback(3); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	if title, _ := storedTitle(s, "doc1"); title != "Original Title" {
+		t.Errorf("doc1 title = %q, want %q", title, "Original Title")
+	}
+	if title, exists := storedTitle(s, "doc2"); !exists || title != "Second Document" {
+		t.Errorf("doc2 = %q (exists %v), want restored document", title, exists)
+	}
+	if _, exists := storedTitle(s, "doc3"); exists {
+		t.Error("doc3 should have been removed")
+	}
+
+	// The inverted index follows the restored content
+	if _, exists := s.invertedIndex.Get("corrupted"); exists {
+		t.Error("token 'corrupted' should no longer be indexed")
+	}
+	if _, exists := s.invertedIndex.Get("original"); !exists {
+		t.Error("token 'original' should be indexed again")
+	}
+
+	// Reversed operations leave the log and the reversal itself is not logged
+	if got := s.OperationLogLength(); got != 2 {
+		t.Errorf("OperationLogLength() after rollback = %d, want 2", got)
+	}
+}
+
+func TestRollback_Validation(t *testing.T) {
+	s := newRollbackTestService(t)
+
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc1", "title": "Title"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	for _, n := range []int{0, 2} {
+		if err := s.Rollback(n); !errors.Is(err, internalErrors.ErrInvalidInput) {
+			t.Errorf("Rollback(%d) error = %v, want ErrInvalidInput", n, err)
+		}
+	}
+
+	// Deleting all documents discards the log
+	if err := s.DeleteAllDocuments(); err != nil {
+		t.Fatalf("DeleteAllDocuments() error = %v", err)
+	}
+	if got := s.OperationLogLength(); got != 0 {
+		t.Errorf("OperationLogLength() after DeleteAllDocuments = %d, want 0", got)
+	}
+}
+
+func TestRollback_BoundedLog(t *testing.T) {
+	s := newRollbackTestService(t)
+
+	docs := make([]model.Document, DefaultOperationLogSize+50)
+	for i := range docs {
+		docs[i] = model.Document{"documentID": fmt.Sprintf("doc%d", i), "title": "Bulk"}
+	}
+	// More than 100 documents go through the bulk indexer
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	if got := s.OperationLogLength(); got != DefaultOperationLogSize {
+		t.Errorf("OperationLogLength() = %d, want %d", got, DefaultOperationLogSize)
+	}
+
+	// Reindexing does not change documents and is not logged
+	if err := s.BulkReindex(DefaultBulkIndexingConfig()); err != nil {
+		t.Fatalf("BulkReindex() error = %v", err)
+	}
+	if got := s.OperationLogLength(); got != DefaultOperationLogSize {
+		t.Errorf("OperationLogLength() after reindex = %d, want %d", got, DefaultOperationLogSize)
+	}
+}
@@ -2,7 +2,9 @@ package indexing
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
+	"sync"
 	"time"
 
 	"sort"
@@ -18,9 +20,16 @@ import (
 
 // Service implements the indexing logic for a single index.
 // It fulfills the services.Indexer interface.
+//
+// Writes are serialized by writeMu, so they can read the document store without its lock. Most
+// writes hold the inverted index lock shared, like searches, and only lock the term shards and
+// documents they change; write batches and clearing the index hold it exclusively.
 type Service struct {
+	writeMu       sync.Mutex // Serializes writes; guards oplog and changes
 	invertedIndex *index.InvertedIndex
 	documentStore *store.DocumentStore
+	oplog         *operationLog // Recent document operations, kept for rollback
+	changes       *changeSet    // Terms and documents changed since the last delta; nil unless a read replica tracks them
 	// settings are accessible via invertedIndex.Settings
 }
 
@@ -34,10 +43,6 @@ func NewService(invertedIndex *index.InvertedIndex, documentStore *store.Documen
 	if documentStore == nil {
 		return nil, fmt.Errorf("document store cannot be nil")
 	}
-	if invertedIndex.Index == nil {
-		// Initialize the map if it's nil to prevent panics later
-		invertedIndex.Index = make(map[string]index.PostingList)
-	}
 	if documentStore.Docs == nil {
 		documentStore.Docs = make(map[uint32]model.Document)
 	}
@@ -50,6 +55,7 @@ func NewService(invertedIndex *index.InvertedIndex, documentStore *store.Documen
 	return &Service{
 		invertedIndex: invertedIndex,
 		documentStore: documentStore,
+		oplog:         newOperationLog(DefaultOperationLogSize),
 	}, nil
 }
 
@@ -64,6 +70,8 @@ func (s *Service) AddDocuments(docs []model.Document) error {
 		config.BatchSize = 500
 		config.WorkerCount = 2 // Use fewer workers to avoid overwhelming the system
 
+		s.writeMu.Lock()
+		defer s.writeMu.Unlock()
 		bulkIndexer := NewBulkIndexer(s, config)
 		return bulkIndexer.BulkAddDocuments(docs)
 	}
@@ -93,14 +101,15 @@ func (s *Service) AddDocuments(docs []model.Document) error {
 	return nil
 }
 
-// addDocumentMicroBatch processes a very small batch of documents with minimal lock time
+// addDocumentMicroBatch processes a very small batch of documents with minimal lock time.
+// Searches run alongside it, only waiting for the term shards it is updating.
 func (s *Service) addDocumentMicroBatch(docs []model.Document) error {
-	// Acquire locks for the micro-batch operation
-	s.documentStore.Mu.Lock()
-	s.invertedIndex.Mu.Lock()
-	defer s.documentStore.Mu.Unlock()
-	defer s.invertedIndex.Mu.Unlock()
+	s.writeMu.Lock()
+	s.invertedIndex.Mu.RLock()
+	defer s.writeMu.Unlock()
+	defer s.invertedIndex.Mu.RUnlock()
 
+	analyzer := s.analyzer()
 	for _, doc := range docs {
 		// Extract documentID string from doc map for error reporting if addSingleDocumentUnsafe fails
 		// This is a bit redundant with the extraction inside addSingleDocumentUnsafe, but useful for top-level error context.
@@ -110,7 +119,7 @@ func (s *Service) addDocumentMicroBatch(docs []model.Document) error {
 				docIDForErrorReporting = idStr
 			}
 		}
-		if err := s.addSingleDocumentUnsafe(doc); err != nil {
+		if err := s.addSingleDocumentUnsafe(doc, analyzer); err != nil {
 			// Return on first error
 			return fmt.Errorf("failed to add document ID %s: %w", docIDForErrorReporting, err)
 		}
@@ -119,8 +128,8 @@ func (s *Service) addDocumentMicroBatch(docs []model.Document) error {
 }
 
 // addSingleDocumentUnsafe handles the processing and indexing of a single document.
-// It assumes that the caller already holds locks on documentStore and invertedIndex.
-func (s *Service) addSingleDocumentUnsafe(doc model.Document) error {
+// It assumes that the caller holds s.writeMu and the inverted index lock, shared or exclusive.
+func (s *Service) addSingleDocumentUnsafe(doc model.Document, analyzer *tokenizer.Analyzer) error {
 	// Attempt to get documentID from the document map, or expect it to be handled by API layer.
 	// For DocumentStore, the documentID is the external ID.
 	docIDValue, docIDExists := doc["documentID"] // Check if "documentID" key exists
@@ -148,19 +157,18 @@ func (s *Service) addSingleDocumentUnsafe(doc model.Document) error {
 	if exists {
 		isUpdate = true
 		// It's an update, retrieve the old document for cleanup
-		if doc, ok := s.documentStore.Docs[internalID]; ok {
+		if doc, ok := s.documentStore.Get(internalID); ok {
 			oldDoc = doc
 		} else {
 			// This case should ideally not happen if ExternalIDtoInternalID and Docs are consistent
 			// If it does, we can't clean up old tokens effectively based on old content.
 			// Proceeding will just overwrite/add new tokens.
-			log.Printf("Warning: Document with internalID %d found in ExternalIDtoInternalID but not in Docs. Cannot clean up old tokens for documentID %s.\n", internalID, docIDStr)
+			slog.Warn("document ID mapped but not stored, cannot clean up its old tokens", "internal_id", internalID, "document_id", docIDStr)
 		}
 	} else {
-		internalID = s.documentStore.NextID
-		s.documentStore.ExternalIDtoInternalID[docIDStr] = internalID
-		s.documentStore.NextID++
+		internalID = s.documentStore.AllocateID()
 	}
+	s.changes.touchDocument(docIDStr)
 
 	// 2. If it's an update and we have the old document, clean up its old tokens
 	if isUpdate && oldDoc != nil {
@@ -189,39 +197,38 @@ func (s *Service) addSingleDocumentUnsafe(doc model.Document) error {
 					continue
 				}
 
-				oldTokens := generateTokensForField(oldTextContent, fieldName, settings)
+				oldTokens := analyzer.FieldTokens(oldTextContent, fieldName)
 				uniqueOldTokens := make(map[string]struct{})
 				for _, token := range oldTokens {
 					uniqueOldTokens[token] = struct{}{}
 				}
 
 				for oldToken := range uniqueOldTokens {
-					if postingList, ok := s.invertedIndex.Index[oldToken]; ok {
-						newList := make(index.PostingList, 0, len(postingList))
-						for _, entry := range postingList {
-							if entry.DocID != internalID || entry.FieldName != fieldName {
-								newList = append(newList, entry)
-							}
-						}
-						if len(newList) == 0 {
-							delete(s.invertedIndex.Index, oldToken)
-						} else {
-							s.invertedIndex.Index[oldToken] = newList
-						}
-					}
+					s.removePostingUnsafe(oldToken, internalID, fieldName)
 				}
 			}
 		}
 	}
 
+	// The stored document is a copy, so values are returned exactly as ingested even if the caller
+	// reuses its map, and derived fields are not added to the caller's document
+	doc = maps.Clone(doc)
+	flattenNestedFields(doc, settings.NestedFields())
+	routeLanguage(doc, settings.LanguageDetection)
+
 	// Store/Update the full document in the document store *after* potential cleanup based on its old version
-	s.documentStore.Docs[internalID] = doc
+	s.documentStore.Put(docIDStr, internalID, doc)
+	s.oplog.record(docIDStr, oldDoc)
 
 	// 3. Process searchable fields specified in index settings for the new/updated document
+	fieldLengths := make(map[string]int)
 	for _, fieldName := range settings.SearchableFields {
 		fieldVal, fieldExists := doc[fieldName]
 		if !fieldExists {
-			log.Printf("Warning: Searchable field '%s' not found in document documentID '%s'.\n", fieldName, docIDStr)
+			// Per-language fields only exist on documents of their language
+			if !analyzer.IsLanguageField(fieldName) {
+				slog.Debug("searchable field not found in document", "field", fieldName, "document_id", docIDStr)
+			}
 			continue
 		}
 
@@ -240,7 +247,7 @@ func (s *Service) addSingleDocumentUnsafe(doc model.Document) error {
 		case []string: // If it was explicitly a []string
 			textContent = strings.Join(v, " ")
 		default:
-			log.Printf("Warning: Searchable field '%s' in document documentID '%s' has unhandled type %T.\n", fieldName, docIDStr, fieldVal)
+			slog.Warn("searchable field has an unhandled type", "field", fieldName, "document_id", docIDStr, "type", fmt.Sprintf("%T", fieldVal))
 			continue
 		}
 
@@ -248,7 +255,7 @@ func (s *Service) addSingleDocumentUnsafe(doc model.Document) error {
 			continue // Skip if the field yields no text content
 		}
 
-		tokens := generateTokensForField(textContent, fieldName, settings)
+		tokens := analyzer.FieldTokens(textContent, fieldName)
 
 		if len(tokens) == 0 {
 			continue // Skip if tokenization yields no tokens
@@ -259,32 +266,30 @@ func (s *Service) addSingleDocumentUnsafe(doc model.Document) error {
 		for _, token := range tokens {
 			termFrequencies[token]++
 		}
+		positions := analyzer.FieldWordPositions(textContent, fieldName)
+		fieldLengths[fieldName] = wordCount(positions)
 
 		// 4. Update Inverted Index for each unique token with its frequency in this field
 		for token, freqInField := range termFrequencies {
+			termPositions, isFullWord := positions[token]
 			newPostingEntry := index.PostingEntry{
-				DocID:     internalID,
-				FieldName: fieldName,            // Store the field name
-				Score:     float64(freqInField), // Term frequency within this specific field
+				DocID:      internalID,
+				FieldName:  fieldName,            // Store the field name
+				Score:      float64(freqInField), // Term frequency within this specific field
+				IsFullWord: isFullWord,
+				Positions:  termPositions, // Word positions for phrase and proximity matching
 			}
 
-			currentPostingList := s.invertedIndex.Index[token]
-
-			// Check if an entry for this DocID and FieldName already exists for this token.
-			// This is important if re-indexing or if a document update occurs.
-			existingIdx := -1
-			for i, entry := range currentPostingList {
-				if entry.DocID == internalID && entry.FieldName == fieldName {
-					existingIdx = i
-					break
+			// Posting lists are shared with running searches, so the updated list is built as a copy.
+			// An existing entry for this DocID and FieldName is left out to re-insert the updated one.
+			storedPostingList, _ := s.invertedIndex.Get(token)
+			currentPostingList := make(index.PostingList, 0, len(storedPostingList)+1)
+			for _, entry := range storedPostingList {
+				if entry.DocID != internalID || entry.FieldName != fieldName {
+					currentPostingList = append(currentPostingList, entry)
 				}
 			}
 
-			if existingIdx != -1 {
-				// Remove existing entry to re-insert the updated one.
-				currentPostingList = append(currentPostingList[:existingIdx], currentPostingList[existingIdx+1:]...)
-			}
-
 			// Find the correct insertion point to keep the list sorted by Score (descending),
 			// then by DocID (ascending), then by FieldName (ascending).
 			insertionIdx := sort.Search(len(currentPostingList), func(i int) bool {
@@ -300,54 +305,149 @@ func (s *Service) addSingleDocumentUnsafe(doc model.Document) error {
 			currentPostingList = append(currentPostingList, index.PostingEntry{})        // Allocate space
 			copy(currentPostingList[insertionIdx+1:], currentPostingList[insertionIdx:]) // Shift elements
 			currentPostingList[insertionIdx] = newPostingEntry                           // Insert
-			s.invertedIndex.Index[token] = currentPostingList
+			s.invertedIndex.Set(token, currentPostingList)
+			s.changes.touchTerm(token)
 		}
 	}
+	s.invertedIndex.SetFieldLengths(internalID, fieldLengths)
+	s.documentStore.AdvanceSeq(1)
 	return nil
 }
 
-// generateTokensForField decides whether to use n-grams based on field-specific settings.
-func generateTokensForField(text string, fieldName string, settings *config.IndexSettings) []string {
-	// Check if the current field is in the list of fields where prefix search should be disabled
-	for _, noPrefixField := range settings.FieldsWithoutPrefixSearch {
-		if fieldName == noPrefixField {
-			return tokenizer.Tokenize(text) // Use regular tokenization (whole words)
+// removePostingUnsafe removes a document field's entry from the posting list of a token, and the
+// token itself once no entries remain. The caller must hold s.writeMu and the inverted index lock.
+func (s *Service) removePostingUnsafe(token string, internalID uint32, fieldName string) {
+	postingList, ok := s.invertedIndex.Get(token)
+	if !ok {
+		return
+	}
+	s.changes.touchTerm(token)
+	newList := make(index.PostingList, 0, len(postingList))
+	for _, entry := range postingList {
+		// Keep entries that don't match this document and field
+		if entry.DocID != internalID || entry.FieldName != fieldName {
+			newList = append(newList, entry)
 		}
 	}
+	// If no entries remain for this token, remove the token entirely
+	if len(newList) == 0 {
+		s.invertedIndex.Delete(token)
+	} else {
+		s.invertedIndex.Set(token, newList)
+	}
+}
+
+// wordCount returns the number of words of a field from the positions of its words.
+func wordCount(positions map[string][]int) int {
+	count := 0
+	for _, wordPositions := range positions {
+		count += len(wordPositions)
+	}
+	return count
+}
 
-	// If not disabled for this field, use prefix n-grams
-	return tokenizer.TokenizeWithPrefixNGrams(text)
+// analyzer returns the analyzer matching the index's current settings.
+// Settings can change between operations, so callers create one per operation.
+func (s *Service) analyzer() *tokenizer.Analyzer {
+	return tokenizer.NewAnalyzer(s.invertedIndex.Settings)
 }
 
 // DeleteAllDocuments removes all documents from the index, clearing both the document store and inverted index.
 // This satisfies the services.Indexer interface.
 func (s *Service) DeleteAllDocuments() error {
 	// Acquire locks for the entire operation
-	s.documentStore.Mu.Lock()
+	s.writeMu.Lock()
 	s.invertedIndex.Mu.Lock()
-	defer s.documentStore.Mu.Unlock()
+	defer s.writeMu.Unlock()
 	defer s.invertedIndex.Mu.Unlock()
 
-	// Clear the document store
-	s.documentStore.Docs = make(map[uint32]model.Document)
-	s.documentStore.ExternalIDtoInternalID = make(map[string]uint32)
-	s.documentStore.NextID = 0
+	// Clear the document store. Clearing is a single mutation with its own sequence number.
+	s.documentStore.Reset()
+	s.documentStore.AdvanceSeq(1)
 
 	// Clear the inverted index
-	s.invertedIndex.Index = make(map[string]index.PostingList)
+	s.invertedIndex.Reset()
+	s.changes.touchAll()
+
+	// Earlier operations cannot be reversed once their documents are gone
+	s.oplog.records = nil
 
 	return nil
 }
 
+// Freeze runs fn while writes are held off, so the inverted index and document store can be read
+// in a consistent state, e.g. to snapshot them. Searches keep running.
+func (s *Service) Freeze(fn func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return fn()
+}
+
+// SetDocumentCompression changes how the document store compresses documents and converts the
+// stored documents. Writes wait for the conversion; searches only for the document store lock.
+func (s *Service) SetDocumentCompression(settings *config.Compression) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.documentStore.SetCompression(settings)
+}
+
+// SetSortableFields changes the fields the document store builds sort keys of and rebuilds the sort
+// keys of the stored documents. Writes wait for the rebuild.
+func (s *Service) SetSortableFields(fields []string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.documentStore.SetSortableFields(fields)
+}
+
 // DeleteDocument removes a specific document from the index by its external ID.
 // This satisfies the services.Indexer interface.
 func (s *Service) DeleteDocument(docID string) error {
-	// Acquire locks for the entire operation
-	s.documentStore.Mu.Lock()
+	s.writeMu.Lock()
+	s.invertedIndex.Mu.RLock()
+	defer s.writeMu.Unlock()
+	defer s.invertedIndex.Mu.RUnlock()
+
+	return s.deleteDocumentUnsafe(docID, s.analyzer())
+}
+
+// ApplyBatch deletes and upserts documents as a single unit: the inverted index lock is held
+// exclusively for the whole batch, so searches observe either none or all of its changes. The batch
+// is validated before anything is modified, so an invalid batch leaves the index untouched.
+func (s *Service) ApplyBatch(upserts []model.Document, deletes []string) error {
+	s.writeMu.Lock()
 	s.invertedIndex.Mu.Lock()
-	defer s.documentStore.Mu.Unlock()
+	defer s.writeMu.Unlock()
 	defer s.invertedIndex.Mu.Unlock()
 
+	for i, doc := range upserts {
+		docID, ok := doc["documentID"].(string)
+		if !ok || strings.TrimSpace(docID) == "" {
+			return fmt.Errorf("document at position %d in batch has no valid documentID", i)
+		}
+	}
+	for _, docID := range deletes {
+		if _, exists := s.documentStore.ExternalIDtoInternalID[docID]; !exists {
+			return errors.NewDocumentNotFoundError(docID)
+		}
+	}
+
+	analyzer := s.analyzer()
+	for _, docID := range deletes {
+		if err := s.deleteDocumentUnsafe(docID, analyzer); err != nil {
+			return fmt.Errorf("failed to delete document ID %s: %w", docID, err)
+		}
+	}
+	for _, doc := range upserts {
+		if err := s.addSingleDocumentUnsafe(doc, analyzer); err != nil {
+			return fmt.Errorf("failed to add document ID %v: %w", doc["documentID"], err)
+		}
+	}
+	return nil
+}
+
+// deleteDocumentUnsafe removes a document and its tokens.
+// It assumes that the caller holds s.writeMu and the inverted index lock, shared or exclusive.
+func (s *Service) deleteDocumentUnsafe(docID string, analyzer *tokenizer.Analyzer) error {
 	// Check if the document exists
 	internalID, exists := s.documentStore.ExternalIDtoInternalID[docID]
 	if !exists {
@@ -355,11 +455,14 @@ func (s *Service) DeleteDocument(docID string) error {
 	}
 
 	// Get the document to clean up its tokens
-	doc, docExists := s.documentStore.Docs[internalID]
+	doc, docExists := s.documentStore.Get(internalID)
 	if !docExists {
 		// This case should ideally not happen if ExternalIDtoInternalID and Docs are consistent
 		// Clean up the mapping and return error
+		s.documentStore.Mu.Lock()
 		delete(s.documentStore.ExternalIDtoInternalID, docID)
+		s.documentStore.Mu.Unlock()
+		s.changes.touchDocument(docID)
 		return fmt.Errorf("document with ID '%s' found in mapping but not in store (inconsistent state)", docID)
 	}
 
@@ -392,7 +495,7 @@ func (s *Service) DeleteDocument(docID string) error {
 			}
 
 			// Generate tokens for this field
-			tokens := generateTokensForField(textContent, fieldName, settings)
+			tokens := analyzer.FieldTokens(textContent, fieldName)
 			uniqueTokens := make(map[string]struct{})
 			for _, token := range tokens {
 				uniqueTokens[token] = struct{}{}
@@ -400,28 +503,17 @@ func (s *Service) DeleteDocument(docID string) error {
 
 			// Remove document from posting lists for each token
 			for token := range uniqueTokens {
-				if postingList, ok := s.invertedIndex.Index[token]; ok {
-					newList := make(index.PostingList, 0, len(postingList))
-					for _, entry := range postingList {
-						// Keep entries that don't match this document and field
-						if entry.DocID != internalID || entry.FieldName != fieldName {
-							newList = append(newList, entry)
-						}
-					}
-					// If no entries remain for this token, remove the token entirely
-					if len(newList) == 0 {
-						delete(s.invertedIndex.Index, token)
-					} else {
-						s.invertedIndex.Index[token] = newList
-					}
-				}
+				s.removePostingUnsafe(token, internalID, fieldName)
 			}
 		}
 	}
 
 	// Remove document from document store
-	delete(s.documentStore.Docs, internalID)
-	delete(s.documentStore.ExternalIDtoInternalID, docID)
+	s.documentStore.Remove(docID, internalID)
+	s.documentStore.AdvanceSeq(1)
+	s.invertedIndex.DeleteFieldLengths(internalID)
+	s.changes.touchDocument(docID)
+	s.oplog.record(docID, doc)
 
 	return nil
 }
@@ -1,10 +1,14 @@
 package indexing
 
 import (
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/index"
@@ -28,7 +32,7 @@ func newTestSettings() *config.IndexSettings {
 
 func TestNewService(t *testing.T) {
 	t.Run("valid initialization", func(t *testing.T) {
-		invIdx := &index.InvertedIndex{Settings: newTestSettings(), Index: make(map[string]index.PostingList)}
+		invIdx := index.NewInvertedIndex(newTestSettings())
 		docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
 		_, err := NewService(invIdx, docStore)
 		if err != nil {
@@ -62,14 +66,15 @@ func TestNewService(t *testing.T) {
 	})
 
 	t.Run("inverted index maps initialized if nil", func(t *testing.T) {
-		invIdx := &index.InvertedIndex{Settings: newTestSettings()} // Index map is nil
+		invIdx := &index.InvertedIndex{Settings: newTestSettings()} // Term shards are nil
 		docStore := &store.DocumentStore{}                          // Docs and ExternalIDtoInternalID maps are nil
 		s, err := NewService(invIdx, docStore)
 		if err != nil {
 			t.Fatalf("NewService() error = %v", err)
 		}
-		if s.invertedIndex.Index == nil {
-			t.Error("s.invertedIndex.Index was not initialized")
+		s.invertedIndex.Set("term", index.PostingList{{DocID: 1, FieldName: "title", Score: 1}})
+		if got := postingsOf(s.invertedIndex, "term"); len(got) != 1 {
+			t.Errorf("Expected the zero value inverted index to store terms, got %v", got)
 		}
 		if s.documentStore.Docs == nil {
 			t.Error("s.documentStore.Docs was not initialized")
@@ -81,6 +86,22 @@ func TestNewService(t *testing.T) {
 }
 
 // Helper to check posting lists, ensuring they are sorted by score (desc)
+// postingsOf returns the posting list of a term, or nil if the term is not indexed.
+func postingsOf(ii *index.InvertedIndex, term string) index.PostingList {
+	postings, _ := ii.Get(term)
+	return postings
+}
+
+// termPostings returns a copy of the whole term dictionary.
+func termPostings(ii *index.InvertedIndex) map[string]index.PostingList {
+	postings := make(map[string]index.PostingList, ii.Len())
+	ii.Range(func(term string, list index.PostingList) bool {
+		postings[term] = list
+		return true
+	})
+	return postings
+}
+
 func checkPostingList(t *testing.T, term string, pl index.PostingList, expectedEntries []index.PostingEntry) {
 	t.Helper()
 	if len(pl) != len(expectedEntries) {
@@ -169,7 +190,7 @@ func TestAddDocuments(t *testing.T) {
 
 	t.Run("add multiple documents, ngrams for title, no ngrams for desc/tags", func(t *testing.T) {
 		settings := newTestSettings() // title=ngram, desc/tags=no-ngram
-		invIdx := &index.InvertedIndex{Settings: settings, Index: make(map[string]index.PostingList)}
+		invIdx := index.NewInvertedIndex(settings)
 		docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
 		s, _ := NewService(invIdx, docStore)
 
@@ -203,17 +224,17 @@ func TestAddDocuments(t *testing.T) {
 		// Tags: ["sci-fi", "action"] (ngrams disabled) -> "sci", "fi", "action"
 
 		// Check "the"
-		checkPostingList(t, "the", invIdx.Index["the"], []index.PostingEntry{
-			{DocID: 0, FieldName: "title", Score: 1.0},       // from baseDoc1 title
-			{DocID: 0, FieldName: "description", Score: 1.0}, // from baseDoc1 description
-			{DocID: 1, FieldName: "title", Score: 1.0},       // from baseDoc2 title
+		checkPostingList(t, "the", postingsOf(invIdx, "the"), []index.PostingEntry{
+			{DocID: 0, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{0}},       // from baseDoc1 title
+			{DocID: 0, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{4}}, // from baseDoc1 description
+			{DocID: 1, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{0}},       // from baseDoc2 title
 		})
 		// Check "matrix" (title, ngrams)
-		checkPostingList(t, "matrix", invIdx.Index["matrix"], []index.PostingEntry{
-			{DocID: 0, FieldName: "title", Score: 1.0}, // baseDoc1
-			{DocID: 1, FieldName: "title", Score: 1.0}, // baseDoc2
+		checkPostingList(t, "matrix", postingsOf(invIdx, "matrix"), []index.PostingEntry{
+			{DocID: 0, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{1}}, // baseDoc1
+			{DocID: 1, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{1}}, // baseDoc2
 		})
-		checkPostingList(t, "m", invIdx.Index["m"], []index.PostingEntry{
+		checkPostingList(t, "m", postingsOf(invIdx, "m"), []index.PostingEntry{
 			{DocID: 0, FieldName: "title", Score: 1.0}, // from matrix (doc0)
 			{DocID: 1, FieldName: "title", Score: 1.0}, // from matrix (doc1)
 			// {DocID: 1, FieldName: "description", Score: 1.0}, // from "more" (doc1 desc) - This setting has ngrams off for desc
@@ -224,34 +245,34 @@ func TestAddDocuments(t *testing.T) {
 		// Description: "Neo learns more." (ngrams disabled) -> "neo", "learns", "more"
 		// Tags: ["sci-fi", "sequel", "action"] (ngrams disabled) -> "sci", "fi", "sequel", "action"
 
-		checkPostingList(t, "reloaded", invIdx.Index["reloaded"], []index.PostingEntry{
-			{DocID: 1, FieldName: "title", Score: 1.0},
+		checkPostingList(t, "reloaded", postingsOf(invIdx, "reloaded"), []index.PostingEntry{
+			{DocID: 1, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{2}},
 		})
-		checkPostingList(t, "r", invIdx.Index["r"], []index.PostingEntry{ // Ngram from "reloaded"
+		checkPostingList(t, "r", postingsOf(invIdx, "r"), []index.PostingEntry{ // Ngram from "reloaded"
 			{DocID: 1, FieldName: "title", Score: 1.0},
 		})
-		checkPostingList(t, "neo", invIdx.Index["neo"], []index.PostingEntry{
-			{DocID: 1, FieldName: "description", Score: 1.0},
+		checkPostingList(t, "neo", postingsOf(invIdx, "neo"), []index.PostingEntry{
+			{DocID: 1, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{0}},
 		})
-		checkPostingList(t, "learns", invIdx.Index["learns"], []index.PostingEntry{
-			{DocID: 0, FieldName: "description", Score: 1.0}, // from baseDoc1
-			{DocID: 1, FieldName: "description", Score: 1.0}, // from baseDoc2
+		checkPostingList(t, "learns", postingsOf(invIdx, "learns"), []index.PostingEntry{
+			{DocID: 0, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{2}}, // from baseDoc1
+			{DocID: 1, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{1}}, // from baseDoc2
 		})
-		checkPostingList(t, "sequel", invIdx.Index["sequel"], []index.PostingEntry{
-			{DocID: 1, FieldName: "tags", Score: 1.0},
+		checkPostingList(t, "sequel", postingsOf(invIdx, "sequel"), []index.PostingEntry{
+			{DocID: 1, FieldName: "tags", Score: 1.0, IsFullWord: true, Positions: []int{2}},
 		})
-		checkPostingList(t, "action", invIdx.Index["action"], []index.PostingEntry{
-			{DocID: 0, FieldName: "tags", Score: 1.0}, // from baseDoc1
-			{DocID: 1, FieldName: "tags", Score: 1.0}, // from baseDoc2
+		checkPostingList(t, "action", postingsOf(invIdx, "action"), []index.PostingEntry{
+			{DocID: 0, FieldName: "tags", Score: 1.0, IsFullWord: true, Positions: []int{2}}, // from baseDoc1
+			{DocID: 1, FieldName: "tags", Score: 1.0, IsFullWord: true, Positions: []int{3}}, // from baseDoc2
 		})
 		// This term "more" from baseDoc2 description (no ngrams for description)
 		// Should not have "m" or "mo" from "more" if ngrams are off for description.
-		checkPostingList(t, "more", invIdx.Index["more"], []index.PostingEntry{
-			{DocID: 1, FieldName: "description", Score: 1.0},
+		checkPostingList(t, "more", postingsOf(invIdx, "more"), []index.PostingEntry{
+			{DocID: 1, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{2}},
 		})
 		// The 'm' from 'more' (desc, no ngrams) should not be here.
 		// 'm' should only come from 'matrix' (title, ngrams enabled)
-		plM := invIdx.Index["m"]
+		plM := postingsOf(invIdx, "m")
 		var foundMFromMore bool
 		for _, p := range plM {
 			if p.DocID == 1 && p.FieldName == "description" {
@@ -268,7 +289,7 @@ func TestAddDocuments(t *testing.T) {
 		settings := newTestSettings()
 		// Ngrams on description, NOT on title/tags
 		settings.FieldsWithoutPrefixSearch = []string{"title", "tags"}
-		invIdx := &index.InvertedIndex{Settings: settings, Index: make(map[string]index.PostingList)}
+		invIdx := index.NewInvertedIndex(settings)
 		docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
 		s, _ := NewService(invIdx, docStore)
 
@@ -299,30 +320,30 @@ func TestAddDocuments(t *testing.T) {
 
 		// Inverted Index checks
 		// "movie": title(d0,TF1), desc(d0,TF1), title(d1,TF1), desc(d1,TF1), tags(d1,TF1)
-		checkPostingList(t, "movie", invIdx.Index["movie"], []index.PostingEntry{
-			{DocID: 0, FieldName: "title", Score: 1.0},
-			{DocID: 0, FieldName: "description", Score: 1.0},
-			{DocID: 1, FieldName: "title", Score: 1.0},
-			{DocID: 1, FieldName: "description", Score: 1.0},
-			{DocID: 1, FieldName: "tags", Score: 1.0},
+		checkPostingList(t, "movie", postingsOf(invIdx, "movie"), []index.PostingEntry{
+			{DocID: 0, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{0}},
+			{DocID: 0, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{2}},
+			{DocID: 1, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{0}},
+			{DocID: 1, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{2}},
+			{DocID: 1, FieldName: "tags", Score: 1.0, IsFullWord: true, Positions: []int{1}},
 		})
 		// "alpha": title(d0,TF1), desc(d0,TF1)
-		checkPostingList(t, "alpha", invIdx.Index["alpha"], []index.PostingEntry{
-			{DocID: 0, FieldName: "title", Score: 1.0},
-			{DocID: 0, FieldName: "description", Score: 1.0},
+		checkPostingList(t, "alpha", postingsOf(invIdx, "alpha"), []index.PostingEntry{
+			{DocID: 0, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{1}},
+			{DocID: 0, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{0}},
 		})
 		// Ngram "a" from description "Alpha test movie." of doc0 (ngrams on for description)
-		checkPostingList(t, "a", invIdx.Index["a"], []index.PostingEntry{
+		checkPostingList(t, "a", postingsOf(invIdx, "a"), []index.PostingEntry{
 			{DocID: 0, FieldName: "description", Score: 1.0}, // From alpha
 		})
 		// Ngram "b" from description "Bravo test movie." of doc1 (ngrams on for description)
-		checkPostingList(t, "b", invIdx.Index["b"], []index.PostingEntry{
+		checkPostingList(t, "b", postingsOf(invIdx, "b"), []index.PostingEntry{
 			{DocID: 1, FieldName: "description", Score: 1.0}, // From bravo
 		})
 		// Token "t" from title "Movie Alpha" should NOT exist if ngrams off for title.
 		// Only from "test" in description (doc0, doc1) and "test" in tags (doc0, doc1 - but tags also no ngrams)
 		// So "t" should only come from description's "test".
-		plT := invIdx.Index["t"]
+		plT := postingsOf(invIdx, "t")
 		var foundTFromTitleOrTags bool
 		for _, p := range plT {
 			if p.FieldName == "title" || p.FieldName == "tags" {
@@ -356,36 +377,36 @@ func TestAddDocuments(t *testing.T) {
 		}
 
 		// Check "alpha" after update
-		checkPostingList(t, "alpha", invIdx.Index["alpha"], []index.PostingEntry{
-			{DocID: 0, FieldName: "title", Score: 1.0},       // from updatedDoc1 title
-			{DocID: 0, FieldName: "description", Score: 1.0}, // from updatedDoc1 description
+		checkPostingList(t, "alpha", postingsOf(invIdx, "alpha"), []index.PostingEntry{
+			{DocID: 0, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{1}},       // from updatedDoc1 title
+			{DocID: 0, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{0}}, // from updatedDoc1 description
 		})
 		// Check "movie" after update
-		checkPostingList(t, "movie", invIdx.Index["movie"], []index.PostingEntry{
-			{DocID: 0, FieldName: "title", Score: 1.0}, // From updatedDoc1 title
+		checkPostingList(t, "movie", postingsOf(invIdx, "movie"), []index.PostingEntry{
+			{DocID: 0, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{0}}, // From updatedDoc1 title
 			// Doc0 description no longer has "movie"
-			{DocID: 1, FieldName: "title", Score: 1.0},       // From doc2 title
-			{DocID: 1, FieldName: "description", Score: 1.0}, // From doc2 description (still has "movie", ngrams on)
-			{DocID: 1, FieldName: "tags", Score: 1.0},        // From doc2 tags
+			{DocID: 1, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{0}},       // From doc2 title
+			{DocID: 1, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{2}}, // From doc2 description (still has "movie", ngrams on)
+			{DocID: 1, FieldName: "tags", Score: 1.0, IsFullWord: true, Positions: []int{1}},        // From doc2 tags
 		})
 		// Ngram "i" from description "is" of updatedDoc1 (description has ngrams)
-		checkPostingList(t, "i", invIdx.Index["i"], []index.PostingEntry{
+		checkPostingList(t, "i", postingsOf(invIdx, "i"), []index.PostingEntry{
 			{DocID: 0, FieldName: "description", Score: 1.0},
 		})
 		// "remixed" from updatedDoc1 title (no ngrams for title)
-		checkPostingList(t, "remixed", invIdx.Index["remixed"], []index.PostingEntry{
-			{DocID: 0, FieldName: "title", Score: 1.0},
+		checkPostingList(t, "remixed", postingsOf(invIdx, "remixed"), []index.PostingEntry{
+			{DocID: 0, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{2}},
 		})
 		// "test" should now only have entries for doc1 (internal ID 1) from its description and tags
-		checkPostingList(t, "test", invIdx.Index["test"], []index.PostingEntry{
-			{DocID: 1, FieldName: "description", Score: 1.0}, // from doc2 description
-			{DocID: 1, FieldName: "tags", Score: 1.0},        // from doc2 tags
+		checkPostingList(t, "test", postingsOf(invIdx, "test"), []index.PostingEntry{
+			{DocID: 1, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{1}}, // from doc2 description
+			{DocID: 1, FieldName: "tags", Score: 1.0, IsFullWord: true, Positions: []int{0}},        // from doc2 tags
 		})
 	})
 
 	t.Run("documentID handling", func(t *testing.T) {
 		settings := newTestSettings()
-		invIdx := &index.InvertedIndex{Settings: settings, Index: make(map[string]index.PostingList)}
+		invIdx := index.NewInvertedIndex(settings)
 		docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
 		s, _ := NewService(invIdx, docStore)
 
@@ -448,7 +469,7 @@ func TestAddDocuments(t *testing.T) {
 		settings := newTestSettings()
 		settings.FieldsWithoutPrefixSearch = []string{} // Ngrams for ALL searchable fields
 		settings.SearchableFields = []string{"name", "categories", "notes"}
-		invIdx := &index.InvertedIndex{Settings: settings, Index: make(map[string]index.PostingList)}
+		invIdx := index.NewInvertedIndex(settings)
 		docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
 		s, _ := NewService(invIdx, docStore)
 
@@ -465,35 +486,35 @@ func TestAddDocuments(t *testing.T) {
 		}
 
 		// Name: "Product X" -> "product", "p", "pr", ..., "x" (all ngrams)
-		checkPostingList(t, "product", invIdx.Index["product"], []index.PostingEntry{{DocID: 0, FieldName: "name", Score: 1.0}})
-		checkPostingList(t, "p", invIdx.Index["p"], []index.PostingEntry{{DocID: 0, FieldName: "name", Score: 1.0}}) // Ngram of "product"
-		checkPostingList(t, "x", invIdx.Index["x"], []index.PostingEntry{{DocID: 0, FieldName: "name", Score: 1.0}}) // Full token "x" and its ngrams (just "x")
+		checkPostingList(t, "product", postingsOf(invIdx, "product"), []index.PostingEntry{{DocID: 0, FieldName: "name", Score: 1.0, IsFullWord: true, Positions: []int{0}}})
+		checkPostingList(t, "p", postingsOf(invIdx, "p"), []index.PostingEntry{{DocID: 0, FieldName: "name", Score: 1.0}})                                        // Ngram of "product"
+		checkPostingList(t, "x", postingsOf(invIdx, "x"), []index.PostingEntry{{DocID: 0, FieldName: "name", Score: 1.0, IsFullWord: true, Positions: []int{1}}}) // Full token "x" and its ngrams (just "x")
 
 		// Categories: "tech gadget" -> "tech", "t", ..., "gadget", "g", ... (all ngrams)
-		checkPostingList(t, "tech", invIdx.Index["tech"], []index.PostingEntry{{DocID: 0, FieldName: "categories", Score: 1.0}})
+		checkPostingList(t, "tech", postingsOf(invIdx, "tech"), []index.PostingEntry{{DocID: 0, FieldName: "categories", Score: 1.0, IsFullWord: true, Positions: []int{0}}})
 		// "t" from "tech" (categories)
-		checkPostingList(t, "t", invIdx.Index["t"], []index.PostingEntry{
+		checkPostingList(t, "t", postingsOf(invIdx, "t"), []index.PostingEntry{
 			{DocID: 0, FieldName: "categories", Score: 1.0}, // from tech
 		})
-		checkPostingList(t, "gadget", invIdx.Index["gadget"], []index.PostingEntry{{DocID: 0, FieldName: "categories", Score: 1.0}})
+		checkPostingList(t, "gadget", postingsOf(invIdx, "gadget"), []index.PostingEntry{{DocID: 0, FieldName: "categories", Score: 1.0, IsFullWord: true, Positions: []int{1}}})
 
 		// Notes: "cool feature" -> "cool", "c", ..., "feature", "f", ... (all ngrams)
-		checkPostingList(t, "cool", invIdx.Index["cool"], []index.PostingEntry{{DocID: 0, FieldName: "notes", Score: 1.0}})
+		checkPostingList(t, "cool", postingsOf(invIdx, "cool"), []index.PostingEntry{{DocID: 0, FieldName: "notes", Score: 1.0, IsFullWord: true, Positions: []int{0}}})
 		// "c" from "cool" (notes) - "tech" does not produce a standalone "c" ngram
-		checkPostingList(t, "c", invIdx.Index["c"], []index.PostingEntry{
+		checkPostingList(t, "c", postingsOf(invIdx, "c"), []index.PostingEntry{
 			{DocID: 0, FieldName: "notes", Score: 1.0}, // from cool
 		})
-		checkPostingList(t, "feature", invIdx.Index["feature"], []index.PostingEntry{{DocID: 0, FieldName: "notes", Score: 1.0}})
+		checkPostingList(t, "feature", postingsOf(invIdx, "feature"), []index.PostingEntry{{DocID: 0, FieldName: "notes", Score: 1.0, IsFullWord: true, Positions: []int{1}}})
 
 		// Ignored field
-		if _, exists := invIdx.Index["ignored"]; exists {
+		if _, exists := invIdx.Get("ignored"); exists {
 			t.Error("Token 'ignored' from non-searchable field found in index")
 		}
 	})
 
 	t.Run("document with field having empty string content", func(t *testing.T) {
 		settings := newTestSettings()
-		invIdx := &index.InvertedIndex{Settings: settings, Index: make(map[string]index.PostingList)}
+		invIdx := index.NewInvertedIndex(settings)
 		docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
 		s, _ := NewService(invIdx, docStore)
 
@@ -510,7 +531,7 @@ func TestAddDocuments(t *testing.T) {
 		}
 
 		// Check that description field did not add any tokens
-		for token, pl := range invIdx.Index {
+		for token, pl := range termPostings(invIdx) {
 			for _, entry := range pl {
 				if entry.FieldName == "description" {
 					t.Errorf("Found token '%s' from empty description field: %v", token, entry)
@@ -518,13 +539,13 @@ func TestAddDocuments(t *testing.T) {
 			}
 		}
 		// Ensure title and tags are indexed
-		if _, ok := invIdx.Index["title"]; !ok { // "title" token from "Title Present" (ngrams for title)
+		if _, ok := invIdx.Get("title"); !ok { // "title" token from "Title Present" (ngrams for title)
 			t.Error("Token 'title' not found from title field")
 		}
-		if _, ok := invIdx.Index["present"]; !ok { // "present" token from "Title Present" (ngrams for title)
+		if _, ok := invIdx.Get("present"); !ok { // "present" token from "Title Present" (ngrams for title)
 			t.Error("Token 'present' not found from title field")
 		}
-		if _, ok := invIdx.Index["tag1"]; !ok { // "tag1" token from tags (no ngrams for tags by default)
+		if _, ok := invIdx.Get("tag1"); !ok { // "tag1" token from tags (no ngrams for tags by default)
 			t.Error("Token 'tag1' not found from tags field")
 		}
 	})
@@ -532,7 +553,7 @@ func TestAddDocuments(t *testing.T) {
 	t.Run("document with non-existent searchable field", func(t *testing.T) {
 		settings := newTestSettings()
 		settings.SearchableFields = []string{"title", "author"} // 'author' may not exist
-		invIdx := &index.InvertedIndex{Settings: settings, Index: make(map[string]index.PostingList)}
+		invIdx := index.NewInvertedIndex(settings)
 		docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
 		s, _ := NewService(invIdx, docStore)
 
@@ -547,14 +568,14 @@ func TestAddDocuments(t *testing.T) {
 			t.Fatalf("AddDocuments error: %v", err)
 		}
 
-		if _, ok := invIdx.Index["good"]; !ok { // "good" from title (ngrams on for title by default)
+		if _, ok := invIdx.Get("good"); !ok { // "good" from title (ngrams on for title by default)
 			t.Error("Token 'good' from title not found when other searchable field is missing")
 		}
-		if _, ok := invIdx.Index["g"]; !ok { // ngram "g" from "good"
+		if _, ok := invIdx.Get("g"); !ok { // ngram "g" from "good"
 			t.Error("Ngram 'g' from title not found")
 		}
 
-		for token, pl := range invIdx.Index {
+		for token, pl := range termPostings(invIdx) {
 			for _, entry := range pl {
 				if entry.FieldName == "author" {
 					t.Errorf("Found token '%s' from missing 'author' field: %v", token, entry)
@@ -563,3 +584,314 @@ func TestAddDocuments(t *testing.T) {
 		}
 	})
 }
+
+func TestAddDocumentsWithLanguageDetection(t *testing.T) {
+	settings := newTestSettings()
+	settings.SearchableFields = []string{"title", "title.en", "title.de"}
+	settings.LanguageDetection = &config.LanguageDetection{Fields: []string{"title"}, Languages: []string{"en", "de"}}
+	invIdx := index.NewInvertedIndex(settings)
+	docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
+	s, _ := NewService(invIdx, docStore)
+
+	docs := []model.Document{
+		{"documentID": "de_doc", "title": "Der Müller und die Mühle"},
+		{"documentID": "en_doc", "title": "The Miller and the Mill"},
+		{"documentID": "override_doc", "title": "Der Müller", "language": "en"},
+		{"documentID": "unknown_doc", "title": "Matrix"},
+	}
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	tests := []struct {
+		documentID   string
+		wantLanguage string
+		wantField    string
+	}{
+		{"de_doc", "de", "title.de"},
+		{"en_doc", "en", "title.en"},
+		{"override_doc", "en", "title.en"},
+		{"unknown_doc", "", ""},
+	}
+	for _, tt := range tests {
+		doc := docStore.Docs[docStore.ExternalIDtoInternalID[tt.documentID]]
+		if language, _ := doc["language"].(string); language != tt.wantLanguage {
+			t.Errorf("document %s: language = %q, want %q", tt.documentID, language, tt.wantLanguage)
+		}
+		for _, field := range []string{"title.en", "title.de"} {
+			_, exists := doc[field]
+			if want := field == tt.wantField; exists != want {
+				t.Errorf("document %s: field %s present = %v, want %v", tt.documentID, field, exists, want)
+			}
+		}
+	}
+
+	entries := postingsOf(invIdx, "mueller")
+	if len(entries) != 1 || entries[0].DocID != docStore.ExternalIDtoInternalID["de_doc"] || entries[0].FieldName != "title.de" {
+		t.Errorf("Expected 'mueller' to be indexed from title.de of de_doc only, got %+v", entries)
+	}
+}
+
+func TestAddDocumentsWithNestedFields(t *testing.T) {
+	settings := newTestSettings()
+	settings.SearchableFields = []string{"title", "credits.cast.name"}
+	settings.FilterableFields = []string{"credits.cast.role"}
+	invIdx := index.NewInvertedIndex(settings)
+	docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
+	s, _ := NewService(invIdx, docStore)
+
+	var docs []model.Document
+	if err := json.Unmarshal([]byte(`[
+		{"documentID": "matrix", "title": "The Matrix", "details": {"year": 1999},
+		 "credits": {"cast": [{"name": "Keanu Reeves", "role": "Neo"}, {"name": "Carrie-Anne Moss", "role": "Trinity"}]}},
+		{"documentID": "no_cast", "title": "Untitled", "credits": {"cast": []}},
+		{"documentID": "flat", "title": "Flat", "details.year": 2001}
+	]`), &docs); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	matrix := docStore.Docs[docStore.ExternalIDtoInternalID["matrix"]]
+	if want := []interface{}{"Keanu Reeves", "Carrie-Anne Moss"}; !reflect.DeepEqual(matrix["credits.cast.name"], want) {
+		t.Errorf("credits.cast.name = %v, want %v", matrix["credits.cast.name"], want)
+	}
+	if want := []interface{}{"Neo", "Trinity"}; !reflect.DeepEqual(matrix["credits.cast.role"], want) {
+		t.Errorf("credits.cast.role = %v, want %v", matrix["credits.cast.role"], want)
+	}
+	if _, exists := matrix["details.year"]; exists {
+		t.Error("Expected details.year to be resolved only once it is in the settings")
+	}
+	if _, exists := docStore.Docs[docStore.ExternalIDtoInternalID["no_cast"]]["credits.cast.name"]; exists {
+		t.Error("Expected no credits.cast.name on a document without cast")
+	}
+	entries := postingsOf(invIdx, "keanu")
+	if len(entries) != 1 || entries[0].FieldName != "credits.cast.name" {
+		t.Errorf("Expected 'keanu' to be indexed from credits.cast.name, got %+v", entries)
+	}
+
+	// Paths added to the settings are resolved when the index is rebuilt
+	settings.FilterableFields = append(settings.FilterableFields, "details.year")
+	if err := s.BulkReindex(DefaultBulkIndexingConfig()); err != nil {
+		t.Fatalf("BulkReindex() error = %v", err)
+	}
+	if year := docStore.Docs[docStore.ExternalIDtoInternalID["matrix"]]["details.year"]; year != float64(1999) {
+		t.Errorf("details.year after reindex = %v, want 1999", year)
+	}
+	if year := docStore.Docs[docStore.ExternalIDtoInternalID["flat"]]["details.year"]; year != float64(2001) {
+		t.Errorf("Expected a dotted field set by the document to be kept, got %v", year)
+	}
+}
+
+func TestAddDocumentsStoresCopy(t *testing.T) {
+	settings := newTestSettings()
+	settings.LanguageDetection = &config.LanguageDetection{Fields: []string{"title"}, Languages: []string{"en", "de"}}
+	invIdx := index.NewInvertedIndex(settings)
+	docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
+	s, _ := NewService(invIdx, docStore)
+
+	doc := model.Document{"documentID": "doc1", "title": "Der Müller und die Mühle"}
+	if err := s.AddDocuments([]model.Document{doc}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	if len(doc) != 2 {
+		t.Errorf("Expected the caller's document to be left unchanged, got %v", doc)
+	}
+
+	doc["title"] = "Changed"
+	if stored := docStore.Docs[0]; stored["title"] != "Der Müller und die Mühle" || stored["title.de"] != "Der Müller und die Mühle" {
+		t.Errorf("Expected the stored document to keep the ingested values, got %v", stored)
+	}
+}
+
+func TestWordPositions(t *testing.T) {
+	doc := model.Document{"documentID": "doc1", "title": "New York, New York", "description": "Visit new places"}
+	bulkDoc := model.Document{"documentID": "doc2", "title": "Brave New World"}
+
+	invIdx := index.NewInvertedIndex(newTestSettings())
+	docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
+	s, _ := NewService(invIdx, docStore)
+	if err := s.AddDocuments([]model.Document{doc}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	if err := NewBulkIndexer(s, DefaultBulkIndexingConfig()).BulkAddDocuments([]model.Document{bulkDoc}); err != nil {
+		t.Fatalf("BulkAddDocuments() error = %v", err)
+	}
+
+	checkPostingList(t, "new", postingsOf(invIdx, "new"), []index.PostingEntry{
+		{DocID: 0, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{0, 2}},
+		{DocID: 0, FieldName: "description", Score: 1.0, IsFullWord: true, Positions: []int{1}},
+		{DocID: 1, FieldName: "title", Score: 1.0, IsFullWord: true, Positions: []int{1}},
+	})
+	// Prefix n-grams that are not whole words have no positions
+	checkPostingList(t, "yo", postingsOf(invIdx, "yo"), []index.PostingEntry{
+		{DocID: 0, FieldName: "title", Score: 1.0},
+	})
+}
+
+func TestWritesRunAlongsideSearches(t *testing.T) {
+	invIdx := index.NewInvertedIndex(newTestSettings())
+	docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
+	s, _ := NewService(invIdx, docStore)
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc1", "title": "First Entry"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	// A running search holds the inverted index lock shared
+	invIdx.Mu.RLock()
+	done := make(chan error, 1)
+	go func() {
+		if err := s.AddDocuments([]model.Document{{"documentID": "doc2", "title": "Second Entry"}}); err != nil {
+			done <- err
+			return
+		}
+		done <- s.DeleteDocument("doc1")
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		invIdx.Mu.RUnlock()
+		t.Fatal("Expected document writes not to wait for running searches")
+	}
+	if got := postingsOf(invIdx, "second"); len(got) != 1 {
+		t.Errorf("Expected the write to be visible to the running search, got %v", got)
+	}
+
+	// Write batches must appear atomic, so they wait for running searches
+	go func() {
+		done <- s.ApplyBatch([]model.Document{{"documentID": "doc3", "title": "Third Entry"}}, nil)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Expected the write batch to wait for the running search")
+	case <-time.After(50 * time.Millisecond):
+	}
+	invIdx.Mu.RUnlock()
+	if err := <-done; err != nil {
+		t.Fatalf("ApplyBatch() error = %v", err)
+	}
+	if _, exists := invIdx.Get("third"); !exists {
+		t.Error("Expected the write batch to be applied once the search finished")
+	}
+}
+
+func TestConcurrentReadsDuringWrites(t *testing.T) {
+	invIdx := index.NewInvertedIndex(newTestSettings())
+	docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
+	s, _ := NewService(invIdx, docStore)
+
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Read the way searches do: postings by term, then documents one at a time
+				invIdx.Mu.RLock()
+				postings, _ := invIdx.Get("entry")
+				for _, entry := range postings {
+					if doc, exists := docStore.Get(entry.DocID); exists && doc["title"] == nil {
+						t.Errorf("Expected stored documents to be complete, got %v", doc)
+					}
+				}
+				invIdx.Mu.RUnlock()
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		docID := fmt.Sprintf("doc%d", i%10)
+		if err := s.AddDocuments([]model.Document{{"documentID": docID, "title": fmt.Sprintf("Entry %d", i)}}); err != nil {
+			t.Fatalf("AddDocuments() error = %v", err)
+		}
+		if i%7 == 0 {
+			if err := s.DeleteDocument(docID); err != nil {
+				t.Fatalf("DeleteDocument() error = %v", err)
+			}
+		}
+	}
+	close(stop)
+	readers.Wait()
+
+	if report := s.Verify(false); !report.Healthy {
+		t.Errorf("Expected a consistent index after concurrent writes, got %+v", report.Issues)
+	}
+}
+
+func TestIndexStatistics(t *testing.T) {
+	invIdx := index.NewInvertedIndex(newTestSettings())
+	docStore := &store.DocumentStore{Docs: make(map[uint32]model.Document), ExternalIDtoInternalID: make(map[string]uint32)}
+	s, _ := NewService(invIdx, docStore)
+	docs := []model.Document{
+		{"documentID": "doc1", "title": "New York, New York", "description": "Visit new places"},
+		{"documentID": "doc2", "title": "Brave New World"},
+	}
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	// "new" is in two fields of doc1 but counted once
+	if df := invIdx.DocumentFrequency("new"); df != 2 {
+		t.Errorf("Expected 'new' in 2 documents, got %d", df)
+	}
+	// Prefix n-grams count the documents with matching words
+	if df := invIdx.DocumentFrequency("bra"); df != 1 {
+		t.Errorf("Expected 'bra' in 1 document, got %d", df)
+	}
+	if length := invIdx.FieldLength("title"); length != 7 {
+		t.Errorf("Expected 7 words in titles, got %d", length)
+	}
+	if average := invIdx.AverageFieldLength("description", docStore.Len()); average != 1.5 {
+		t.Errorf("Expected 1.5 description words per document, got %v", average)
+	}
+	if length := invIdx.DocumentFieldLength(1, "title"); length != 3 {
+		t.Errorf("Expected 3 words in the title of doc2, got %d", length)
+	}
+
+	// Updates and deletes adjust the statistics instead of leaving the old words counted
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc1", "title": "York"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	if err := s.DeleteDocument("doc2"); err != nil {
+		t.Fatalf("DeleteDocument() error = %v", err)
+	}
+	if df := invIdx.DocumentFrequency("new"); df != 0 {
+		t.Errorf("Expected 'new' in no documents, got %d", df)
+	}
+	if df := invIdx.DocumentFrequency("york"); df != 1 {
+		t.Errorf("Expected 'york' in 1 document, got %d", df)
+	}
+	if length, description := invIdx.FieldLength("title"), invIdx.FieldLength("description"); length != 1 || description != 0 {
+		t.Errorf("Expected 1 title word and no description words, got %d and %d", length, description)
+	}
+
+	// Statistics are rebuilt when an index is loaded
+	encoded, err := invIdx.GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode() error = %v", err)
+	}
+	loaded := &index.InvertedIndex{}
+	if err := loaded.GobDecode(encoded); err != nil {
+		t.Fatalf("GobDecode() error = %v", err)
+	}
+	if df, length := loaded.DocumentFrequency("york"), loaded.FieldLength("title"); df != 1 || length != 1 {
+		t.Errorf("Expected the loaded index to have the same statistics, got frequency %d and title length %d", df, length)
+	}
+
+	if err := s.DeleteAllDocuments(); err != nil {
+		t.Fatalf("DeleteAllDocuments() error = %v", err)
+	}
+	if df, length := invIdx.DocumentFrequency("york"), invIdx.FieldLength("title"); df != 0 || length != 0 {
+		t.Errorf("Expected no statistics after clearing the index, got frequency %d and title length %d", df, length)
+	}
+}
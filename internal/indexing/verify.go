@@ -0,0 +1,147 @@
+package indexing
+
+import (
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// maxReportedIssues bounds the issues listed in an integrity report; all issues are counted.
+const maxReportedIssues = 100
+
+// Verify cross-checks the document store, its document ID mappings and the inverted index
+// postings. With repair, the issues are fixed as they are found:
+//   - dangling mappings and mappings of other document IDs to a document are removed
+//   - missing mappings are added, and unreachable documents are deleted
+//   - a stale next internal ID is moved past the highest stored ID
+//   - orphaned and duplicate postings are removed, including the postings of deleted documents
+func (s *Service) Verify(repair bool) model.IntegrityReport {
+	// Holding the write lock keeps writes out, so the index is checked in a consistent state
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if repair {
+		s.invertedIndex.Mu.Lock()
+		s.documentStore.Mu.Lock()
+		defer s.invertedIndex.Mu.Unlock()
+		defer s.documentStore.Mu.Unlock()
+	} else {
+		s.invertedIndex.Mu.RLock()
+		defer s.invertedIndex.Mu.RUnlock()
+	}
+
+	report := model.IntegrityReport{
+		IndexName:   s.invertedIndex.Settings.Name,
+		IssueCounts: make(map[model.IntegrityIssueType]int),
+		Issues:      []model.IntegrityIssue{},
+	}
+	addIssue := func(issue model.IntegrityIssue) {
+		report.IssueCounts[issue.Type]++
+		if len(report.Issues) < maxReportedIssues {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+	docs := make(map[uint32]model.Document, len(s.documentStore.Docs))
+	s.documentStore.RangeUnsafe(func(internalID uint32, doc model.Document) bool {
+		docs[internalID] = doc
+		return true
+	})
+	mappings := s.documentStore.ExternalIDtoInternalID
+
+	for externalID, internalID := range mappings {
+		doc, exists := docs[internalID]
+		if !exists {
+			addIssue(model.IntegrityIssue{Type: model.IntegrityDanglingMapping, DocumentID: externalID, InternalID: internalID})
+		} else if documentID, _ := doc.GetDocumentID(); documentID != externalID {
+			addIssue(model.IntegrityIssue{Type: model.IntegrityDuplicateInternalID, DocumentID: externalID, InternalID: internalID})
+		} else {
+			continue
+		}
+		if repair {
+			delete(mappings, externalID)
+		}
+	}
+
+	var highestID uint32
+	removedDocs := make(map[uint32]struct{})
+	for internalID, doc := range docs {
+		highestID = max(highestID, internalID)
+		documentID, _ := doc.GetDocumentID()
+		mappedID, mapped := mappings[documentID]
+		switch {
+		case documentID != "" && !mapped:
+			addIssue(model.IntegrityIssue{Type: model.IntegrityMissingMapping, DocumentID: documentID, InternalID: internalID})
+			if repair {
+				mappings[documentID] = internalID
+			}
+		case documentID == "" || mappedID != internalID:
+			addIssue(model.IntegrityIssue{Type: model.IntegrityUnreachableDocument, DocumentID: documentID, InternalID: internalID})
+			if repair {
+				delete(docs, internalID)
+				s.documentStore.DeleteUnsafe(internalID)
+				removedDocs[internalID] = struct{}{}
+			}
+		}
+	}
+	if len(docs) > 0 && s.documentStore.NextID <= highestID {
+		addIssue(model.IntegrityIssue{Type: model.IntegrityStaleNextID, InternalID: s.documentStore.NextID})
+		if repair {
+			s.documentStore.NextID = highestID + 1
+		}
+	}
+
+	repairedPostings := make(map[string]index.PostingList)
+	orphanedDocs := make(map[uint32]struct{})
+	s.invertedIndex.Range(func(term string, postings index.PostingList) bool {
+		type docField struct {
+			docID uint32
+			field string
+		}
+		seen := make(map[docField]struct{}, len(postings))
+		kept := postings[:0:0]
+		for _, entry := range postings {
+			key := docField{entry.DocID, entry.FieldName}
+			if _, exists := docs[entry.DocID]; !exists {
+				orphanedDocs[entry.DocID] = struct{}{}
+				if _, removed := removedDocs[entry.DocID]; !removed {
+					addIssue(model.IntegrityIssue{Type: model.IntegrityOrphanedPosting, InternalID: entry.DocID, Term: term, Field: entry.FieldName})
+				}
+				continue
+			}
+			if _, duplicate := seen[key]; duplicate {
+				addIssue(model.IntegrityIssue{Type: model.IntegrityDuplicatePosting, InternalID: entry.DocID, Term: term, Field: entry.FieldName})
+				continue
+			}
+			seen[key] = struct{}{}
+			kept = append(kept, entry)
+		}
+		if repair && len(kept) != len(postings) {
+			repairedPostings[term] = kept
+		}
+		return true
+	})
+	// Shards can't be modified while they are ranged over, so repairs are applied afterwards
+	for term, kept := range repairedPostings {
+		if len(kept) == 0 {
+			s.invertedIndex.Delete(term)
+		} else {
+			s.invertedIndex.Set(term, kept)
+		}
+	}
+	if repair {
+		// Documents that are gone no longer count towards the field lengths
+		for internalID := range removedDocs {
+			s.invertedIndex.DeleteFieldLengths(internalID)
+		}
+		for internalID := range orphanedDocs {
+			s.invertedIndex.DeleteFieldLengths(internalID)
+		}
+	}
+
+	report.Documents = len(docs)
+	report.Terms = s.invertedIndex.Len()
+	report.Healthy = len(report.IssueCounts) == 0
+	report.Repaired = repair && !report.Healthy
+	if report.Repaired {
+		s.changes.touchAll()
+	}
+	return report
+}
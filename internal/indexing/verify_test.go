@@ -0,0 +1,101 @@
+package indexing
+
+import (
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestVerify(t *testing.T) {
+	s := newRollbackTestService(t)
+	if err := s.AddDocuments([]model.Document{
+		{"documentID": "doc1", "title": "Alpha"},
+		{"documentID": "doc2", "title": "Beta"},
+		{"documentID": "doc3", "title": "Gamma"},
+	}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	if report := s.Verify(false); !report.Healthy || len(report.Issues) != 0 {
+		t.Fatalf("Verify() on a consistent index = %+v, want healthy", report)
+	}
+
+	store := s.documentStore
+	doc3ID := store.ExternalIDtoInternalID["doc3"]
+	// Corrupt the index: drop doc3 without its postings, point a stale ID at doc1,
+	// lose doc2's mapping, duplicate a posting and rewind the ID counter.
+	delete(store.Docs, doc3ID)
+	store.ExternalIDtoInternalID["stale"] = store.ExternalIDtoInternalID["doc1"]
+	delete(store.ExternalIDtoInternalID, "doc2")
+	s.invertedIndex.Set("alpha", append(postingsOf(s.invertedIndex, "alpha"), postingsOf(s.invertedIndex, "alpha")[0]))
+	store.NextID = 0
+
+	report := s.Verify(false)
+	want := map[model.IntegrityIssueType]int{
+		model.IntegrityDanglingMapping:     1,
+		model.IntegrityDuplicateInternalID: 1,
+		model.IntegrityMissingMapping:      1,
+		model.IntegrityStaleNextID:         1,
+		model.IntegrityDuplicatePosting:    1,
+	}
+	if report.Healthy || report.Repaired {
+		t.Errorf("Verify(false) Healthy = %v, Repaired = %v, want false, false", report.Healthy, report.Repaired)
+	}
+	for issueType, count := range want {
+		if report.IssueCounts[issueType] != count {
+			t.Errorf("IssueCounts[%s] = %d, want %d", issueType, report.IssueCounts[issueType], count)
+		}
+	}
+	if report.IssueCounts[model.IntegrityOrphanedPosting] == 0 {
+		t.Errorf("expected orphaned postings for the dropped document, got %v", report.IssueCounts)
+	}
+	if _, exists := store.ExternalIDtoInternalID["stale"]; !exists {
+		t.Error("Verify(false) should not modify the index")
+	}
+
+	report = s.Verify(true)
+	if !report.Repaired {
+		t.Errorf("Verify(true) Repaired = false, want true")
+	}
+	if report = s.Verify(false); !report.Healthy {
+		t.Fatalf("Verify() after repair = %+v, want healthy", report)
+	}
+	if _, exists := store.ExternalIDtoInternalID["doc2"]; !exists {
+		t.Error("expected the doc2 mapping to be restored")
+	}
+	if _, exists := s.invertedIndex.Get("gamma"); exists {
+		t.Error("expected the postings of the dropped document to be removed")
+	}
+	if got := len(postingsOf(s.invertedIndex, "alpha")); got != 1 {
+		t.Errorf("len(postings[alpha]) = %d, want 1", got)
+	}
+	if store.NextID != 2 {
+		t.Errorf("NextID = %d, want 2", store.NextID)
+	}
+}
+
+func TestVerifyRemovesUnreachableDocuments(t *testing.T) {
+	s := newRollbackTestService(t)
+	if err := s.AddDocuments([]model.Document{{"documentID": "doc1", "title": "Alpha"}}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	// A second copy of doc1 that its document ID does not map to
+	s.documentStore.Docs[5] = model.Document{"documentID": "doc1", "title": "Delta"}
+	s.documentStore.NextID = 6
+	s.invertedIndex.Set("delta", index.PostingList{{DocID: 5, FieldName: "title", Score: 1, IsFullWord: true}})
+
+	report := s.Verify(true)
+	if report.IssueCounts[model.IntegrityUnreachableDocument] != 1 || report.IssueCounts[model.IntegrityOrphanedPosting] != 0 {
+		t.Errorf("IssueCounts = %v, want one unreachable document and no orphaned postings", report.IssueCounts)
+	}
+	if _, exists := s.documentStore.Docs[5]; exists {
+		t.Error("expected the unreachable document to be deleted")
+	}
+	if _, exists := s.invertedIndex.Get("delta"); exists {
+		t.Error("expected the unreachable document's postings to be removed")
+	}
+	if report.Documents != 1 {
+		t.Errorf("Documents = %d, want 1", report.Documents)
+	}
+}
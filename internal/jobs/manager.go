@@ -3,39 +3,62 @@ package jobs
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/tracing"
 	"github.com/gcbaptista/go-search-engine/model"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// DefaultRetention is how long the records of finished jobs are kept by default
+const DefaultRetention = 24 * time.Hour
+
+// maxCleanupInterval is the longest time between two cleanups of finished jobs
+const maxCleanupInterval = time.Hour
+
 // Manager handles background job execution and tracking
 type Manager struct {
-	mu       sync.RWMutex
-	jobs     map[string]*model.Job
-	workers  chan struct{} // Limits concurrent jobs
-	stopChan chan struct{}
-	wg       sync.WaitGroup
-	metrics  *JobMetrics
+	mu        sync.RWMutex
+	jobs      map[string]*model.Job
+	cancels   map[string]context.CancelFunc // Cancel the context of scheduled jobs, by job ID
+	retention time.Duration                 // How long finished jobs are kept; 0 keeps them
+	workers   chan struct{}                 // Limits concurrent jobs
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+	metrics   *JobMetrics
 }
 
 // NewManager creates a new job manager with specified worker count
 func NewManager(maxWorkers int) *Manager {
 	return &Manager{
-		jobs:     make(map[string]*model.Job),
-		workers:  make(chan struct{}, maxWorkers),
-		stopChan: make(chan struct{}),
-		metrics:  NewJobMetrics(),
+		jobs:      make(map[string]*model.Job),
+		cancels:   make(map[string]context.CancelFunc),
+		retention: DefaultRetention,
+		workers:   make(chan struct{}, maxWorkers),
+		stopChan:  make(chan struct{}),
+		metrics:   NewJobMetrics(),
 	}
 }
 
+// SetRetention sets how long the records of finished jobs are kept before they are cleaned up.
+// A retention of 0 keeps them until they are cleaned up explicitly.
+func (m *Manager) SetRetention(retention time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = retention
+}
+
 // Start begins the job manager and starts background cleanup
 func (m *Manager) Start() {
-	log.Printf("Job manager started with %d max workers", cap(m.workers))
+	slog.Info("job manager started", "max_workers", cap(m.workers))
 
 	// Start cleanup routine
 	go m.cleanupRoutine()
@@ -43,9 +66,12 @@ func (m *Manager) Start() {
 
 // Stop gracefully shuts down the job manager
 func (m *Manager) Stop() {
+	// Closed under the lock, so no job is scheduled while the manager waits for the running ones
+	m.mu.Lock()
 	close(m.stopChan)
+	m.mu.Unlock()
 	m.wg.Wait()
-	log.Printf("Job manager stopped")
+	slog.Info("job manager stopped")
 }
 
 // CreateJob creates a new job and returns its ID
@@ -64,7 +90,7 @@ func (m *Manager) CreateJob(jobType model.JobType, indexName string, metadata ma
 
 	m.jobs[job.ID] = job
 	m.metrics.RecordJobCreated(jobType)
-	log.Printf("Created job %s (type: %s) for index '%s'", job.ID, job.Type, job.IndexName)
+	slog.Info("job created", "job_id", job.ID, "type", job.Type, "index", job.IndexName)
 	return job.ID
 }
 
@@ -79,12 +105,18 @@ func (m *Manager) GetJob(jobID string) (*model.Job, error) {
 	}
 
 	// Return a copy to avoid race conditions
+	return copyJob(job), nil
+}
+
+// copyJob returns a copy of a job that does not share its progress. Metadata maps are replaced
+// rather than modified, so the copy can share them. The caller must hold m.mu.
+func copyJob(job *model.Job) *model.Job {
 	jobCopy := *job
 	if job.Progress != nil {
 		progressCopy := *job.Progress
 		jobCopy.Progress = &progressCopy
 	}
-	return &jobCopy, nil
+	return &jobCopy
 }
 
 // ListJobs returns all jobs for a specific index, optionally filtered by status
@@ -97,19 +129,35 @@ func (m *Manager) ListJobs(indexName string, status *model.JobStatus) []*model.J
 		if job.IndexName == indexName {
 			if status == nil || job.Status == *status {
 				// Return a copy
-				jobCopy := *job
-				if job.Progress != nil {
-					progressCopy := *job.Progress
-					jobCopy.Progress = &progressCopy
-				}
-				result = append(result, &jobCopy)
+				result = append(result, copyJob(job))
 			}
 		}
 	}
 	return result
 }
 
-// ExecuteJob runs a job function in a goroutine with proper tracking
+// ListAllJobs returns the jobs of all indexes selected by the filter, newest first
+func (m *Manager) ListAllJobs(filter model.JobFilter) []*model.Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := []*model.Job{}
+	for _, job := range m.jobs {
+		if filter.Matches(job) {
+			result = append(result, copyJob(job))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// ExecuteJob schedules a job to run in the background and returns at once. The job stays pending
+// until a worker is free; the context it runs with is cancelled when the job is cancelled.
 func (m *Manager) ExecuteJob(jobID string, jobFunc func(ctx context.Context, job *model.Job) error) error {
 	m.mu.Lock()
 	job, exists := m.jobs[jobID]
@@ -123,31 +171,57 @@ func (m *Manager) ExecuteJob(jobID string, jobFunc func(ctx context.Context, job
 		return fmt.Errorf("job with ID '%s' is not in pending status (current: %s)", jobID, job.Status)
 	}
 
-	oldStatus := job.Status
-	job.Status = model.JobStatusRunning
-	now := time.Now()
-	job.StartedAt = &now
-	m.metrics.RecordJobStatusChange(oldStatus, job.Status)
-	m.mu.Unlock()
-
-	// Acquire worker slot
 	select {
-	case m.workers <- struct{}{}:
-		// Got worker slot
 	case <-m.stopChan:
+		m.mu.Unlock()
 		m.updateJobStatus(jobID, model.JobStatusCancelled, "Job manager shutting down")
 		return fmt.Errorf("job manager is shutting down")
+	default:
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancels[jobID] = cancel
 	m.wg.Add(1)
+	m.mu.Unlock()
+
 	go func() {
 		defer func() {
-			<-m.workers // Release worker slot
+			m.mu.Lock()
+			delete(m.cancels, jobID)
+			m.mu.Unlock()
+			cancel()
 			m.wg.Done()
 		}()
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+		// Acquire worker slot
+		select {
+		case m.workers <- struct{}{}:
+			defer func() { <-m.workers }() // Release worker slot
+		case <-ctx.Done():
+			return // Cancelled while pending
+		case <-m.stopChan:
+			m.updateJobStatus(jobID, model.JobStatusCancelled, "Job manager shutting down")
+			return
+		}
+
+		m.mu.Lock()
+		if job.Status != model.JobStatusPending {
+			// Cancelled while it waited for the worker slot
+			m.mu.Unlock()
+			return
+		}
+		job.Status = model.JobStatusRunning
+		now := time.Now()
+		job.StartedAt = &now
+		m.metrics.RecordJobStatusChange(model.JobStatusPending, job.Status)
+		m.mu.Unlock()
+
+		// Jobs outlive the requests that create them, so each job is traced on its own
+		ctx, span := tracing.Start(ctx, "job "+string(job.Type),
+			attribute.String("job_id", jobID),
+			attribute.String("job_type", string(job.Type)),
+			attribute.String("index", job.IndexName),
+		)
 
 		startTime := time.Now()
 
@@ -155,22 +229,60 @@ func (m *Manager) ExecuteJob(jobID string, jobFunc func(ctx context.Context, job
 		err := jobFunc(ctx, job)
 
 		executionTime := time.Since(startTime)
+		tracing.End(span, err)
 
-		// Update job status and metrics based on result
-		if err != nil {
-			m.updateJobStatus(jobID, model.JobStatusFailed, err.Error())
-			m.metrics.RecordJobFailed(job.Type)
-			log.Printf("Job %s failed after %v: %v", jobID, executionTime, err)
-		} else {
+		// Update job status and metrics based on result; jobs that complete although they were
+		// cancelled are reported as completed, as their work was done
+		switch {
+		case err == nil:
 			m.updateJobStatus(jobID, model.JobStatusCompleted, "")
 			m.metrics.RecordJobCompleted(job.Type, executionTime)
-			log.Printf("Job %s completed successfully in %v", jobID, executionTime)
+			slog.Info("job completed", "job_id", jobID, "duration", executionTime)
+		case ctx.Err() != nil:
+			m.updateJobStatus(jobID, model.JobStatusCancelled, err.Error())
+			slog.Info("job cancelled", "job_id", jobID, "duration", executionTime, "error", err)
+		default:
+			m.updateJobStatus(jobID, model.JobStatusFailed, err.Error())
+			m.metrics.RecordJobFailed(job.Type)
+			slog.Error("job failed", "job_id", jobID, "duration", executionTime, "error", err)
 		}
 	}()
 
 	return nil
 }
 
+// CancelJob cancels a job. A pending job is cancelled at once; a running job is asked to stop,
+// which jobs do at points where stopping leaves the index consistent, such as between chunks of
+// documents. Jobs that finish their work before such a point complete anyway. Finished jobs
+// cannot be cancelled.
+func (m *Manager) CancelJob(jobID string) (*model.Job, error) {
+	m.mu.Lock()
+	job, exists := m.jobs[jobID]
+	if !exists {
+		m.mu.Unlock()
+		return nil, errors.NewJobNotFoundError(jobID)
+	}
+	if job.IsFinished() {
+		m.mu.Unlock()
+		return nil, errors.NewJobFinishedError(jobID, string(job.Status))
+	}
+
+	cancel := m.cancels[jobID]
+	status := job.Status
+	if status == model.JobStatusPending {
+		m.setStatusUnsafe(job, model.JobStatusCancelled, "Job cancelled before it started")
+	} else {
+		job.CancelRequested = true
+	}
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	slog.Info("job cancellation requested", "job_id", jobID, "status", status)
+	return m.GetJob(jobID)
+}
+
 // UpdateJobProgress updates the progress of a running job
 func (m *Manager) UpdateJobProgress(jobID string, current, total int, message string) {
 	m.mu.Lock()
@@ -187,9 +299,29 @@ func (m *Manager) UpdateJobProgress(jobID string, current, total int, message st
 
 	job.Progress.Current = current
 	job.Progress.Total = total
+	job.Progress.Percentage = math.Round(job.Progress.GetProgressPercentage()*10) / 10
 	job.Progress.Message = message
 }
 
+// SetJobMetadata sets a metadata entry of a job, e.g. a result only known once the job has run.
+// The metadata map is replaced rather than modified, as copies returned by GetJob share it.
+func (m *Manager) SetJobMetadata(jobID, key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.jobs[jobID]
+	if !exists {
+		return
+	}
+
+	metadata := maps.Clone(job.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	metadata[key] = value
+	job.Metadata = metadata
+}
+
 // updateJobStatus updates the status of a job (internal method)
 func (m *Manager) updateJobStatus(jobID string, status model.JobStatus, errorMsg string) {
 	m.mu.Lock()
@@ -199,14 +331,19 @@ func (m *Manager) updateJobStatus(jobID string, status model.JobStatus, errorMsg
 	if !exists {
 		return
 	}
+	m.setStatusUnsafe(job, status, errorMsg)
+}
 
+// setStatusUnsafe sets the status of a job, with its completion time once it is finished. The
+// caller must hold m.mu.
+func (m *Manager) setStatusUnsafe(job *model.Job, status model.JobStatus, errorMsg string) {
 	oldStatus := job.Status
 	job.Status = status
 	if errorMsg != "" {
 		job.Error = errorMsg
 	}
 
-	if status == model.JobStatusCompleted || status == model.JobStatusFailed || status == model.JobStatusCancelled {
+	if job.IsFinished() {
 		now := time.Now()
 		job.CompletedAt = &now
 	}
@@ -214,40 +351,63 @@ func (m *Manager) updateJobStatus(jobID string, status model.JobStatus, errorMsg
 	m.metrics.RecordJobStatusChange(oldStatus, status)
 }
 
-// cleanupRoutine runs periodic job cleanup
+// cleanupRoutine periodically removes the finished jobs older than the retention
 func (m *Manager) cleanupRoutine() {
-	ticker := time.NewTicker(1 * time.Hour) // Cleanup every hour
-	defer ticker.Stop()
+	timer := time.NewTimer(m.cleanupInterval())
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			// Clean up completed jobs older than 24 hours
-			m.CleanupOldJobs(24 * time.Hour)
+		case <-timer.C:
+			m.mu.RLock()
+			retention := m.retention
+			m.mu.RUnlock()
+			if retention > 0 {
+				m.CleanupJobs(retention)
+			}
+			timer.Reset(m.cleanupInterval())
 		case <-m.stopChan:
 			return
 		}
 	}
 }
 
+// cleanupInterval returns the time until the next cleanup: at most an hour, and less with a
+// shorter retention so jobs are not kept much longer than it.
+func (m *Manager) cleanupInterval() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.retention > 0 && m.retention < maxCleanupInterval {
+		return m.retention
+	}
+	return maxCleanupInterval
+}
+
 // CleanupOldJobs removes completed jobs older than the specified duration
 func (m *Manager) CleanupOldJobs(maxAge time.Duration) {
+	m.CleanupJobs(maxAge)
+}
+
+// CleanupJobs removes the finished jobs that completed more than olderThan ago and returns how
+// many were removed. Pending and running jobs are never removed.
+func (m *Manager) CleanupJobs(olderThan time.Duration) int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	cutoff := time.Now().Add(-maxAge)
+	cutoff := time.Now().Add(-olderThan)
 	cleaned := 0
 
 	for jobID, job := range m.jobs {
-		if job.CompletedAt != nil && job.CompletedAt.Before(cutoff) {
+		if job.IsFinished() && job.CompletedAt != nil && !job.CompletedAt.After(cutoff) {
 			delete(m.jobs, jobID)
 			cleaned++
 		}
 	}
 
 	if cleaned > 0 {
-		log.Printf("Cleaned up %d old jobs", cleaned)
+		slog.Info("old jobs cleaned up", "jobs", cleaned)
 	}
+	return cleaned
 }
 
 // GetMetrics returns current job performance metrics
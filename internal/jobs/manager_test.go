@@ -2,9 +2,12 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
 	"github.com/gcbaptista/go-search-engine/model"
 )
 
@@ -78,5 +81,163 @@ func TestJobManager_ExecuteJob(t *testing.T) {
 		if job.Progress.Total != 100 {
 			t.Errorf("Expected progress total 100, got %d", job.Progress.Total)
 		}
+		if job.Progress.Percentage != 100 {
+			t.Errorf("Expected progress percentage 100, got %g", job.Progress.Percentage)
+		}
+	}
+}
+
+// waitForStatus polls a job until it has the status, failing the test if it does not get it.
+func waitForStatus(t *testing.T, manager *Manager, jobID string, status model.JobStatus) *model.Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		job, err := manager.GetJob(jobID)
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected job status %s, got %s", status, job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestJobManager_CancelJob(t *testing.T) {
+	manager := NewManager(1)
+	manager.Start()
+	defer manager.Stop()
+
+	// The running job takes the only worker, so the queued job stays pending
+	started := make(chan struct{})
+	runningID := manager.CreateJob(model.JobTypeAddDocuments, "test-index", nil)
+	if err := manager.ExecuteJob(runningID, func(ctx context.Context, job *model.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}); err != nil {
+		t.Fatalf("Failed to execute job: %v", err)
+	}
+	<-started
+
+	var ran atomic.Bool
+	pendingID := manager.CreateJob(model.JobTypeReindex, "test-index", nil)
+	if err := manager.ExecuteJob(pendingID, func(ctx context.Context, job *model.Job) error {
+		ran.Store(true)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to execute job: %v", err)
+	}
+
+	job, err := manager.CancelJob(pendingID)
+	if err != nil {
+		t.Fatalf("Failed to cancel pending job: %v", err)
+	}
+	if job.Status != model.JobStatusCancelled || job.CompletedAt == nil {
+		t.Errorf("Expected the pending job to be cancelled at once, got %s", job.Status)
+	}
+
+	job, err = manager.CancelJob(runningID)
+	if err != nil {
+		t.Fatalf("Failed to cancel running job: %v", err)
+	}
+	if !job.CancelRequested {
+		t.Error("Expected cancellation to be requested for the running job")
+	}
+	waitForStatus(t, manager, runningID, model.JobStatusCancelled)
+
+	// The worker is free again, but the cancelled job never runs
+	time.Sleep(20 * time.Millisecond)
+	if ran.Load() {
+		t.Error("Expected the cancelled pending job not to run")
+	}
+
+	if _, err := manager.CancelJob(runningID); !errors.Is(err, internalErrors.ErrJobFinished) {
+		t.Errorf("Expected cancelling a finished job to fail as finished, got %v", err)
+	}
+	if _, err := manager.CancelJob("missing-job"); !errors.Is(err, internalErrors.ErrJobNotFound) {
+		t.Errorf("Expected cancelling a missing job to fail as not found, got %v", err)
+	}
+	if metrics := manager.GetMetrics(); metrics.JobsFailed != 0 {
+		t.Errorf("Expected cancelled jobs not to count as failed, got %d failures", metrics.JobsFailed)
+	}
+}
+
+func TestJobManager_ListAllJobs(t *testing.T) {
+	manager := NewManager(2)
+	defer manager.Stop()
+
+	first := manager.CreateJob(model.JobTypeReindex, "index-a", nil)
+	time.Sleep(time.Millisecond)
+	second := manager.CreateJob(model.JobTypeAddDocuments, "index-b", nil)
+	time.Sleep(time.Millisecond)
+	third := manager.CreateJob(model.JobTypeAddDocuments, "index-a", nil)
+	if _, err := manager.CancelJob(second); err != nil {
+		t.Fatalf("Failed to cancel job: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter model.JobFilter
+		want   []string
+	}{
+		{"all jobs newest first", model.JobFilter{}, []string{third, second, first}},
+		{"by index", model.JobFilter{IndexName: "index-a"}, []string{third, first}},
+		{"by type", model.JobFilter{Type: model.JobTypeAddDocuments}, []string{third, second}},
+		{"by status", model.JobFilter{Status: model.JobStatusCancelled}, []string{second}},
+		{"no match", model.JobFilter{IndexName: "index-c"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := manager.ListAllJobs(tt.filter)
+			got := make([]string, 0, len(jobs))
+			for _, job := range jobs {
+				got = append(got, job.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ListAllJobs() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ListAllJobs() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestJobManager_CleanupJobs(t *testing.T) {
+	manager := NewManager(1)
+	manager.SetRetention(20 * time.Millisecond)
+	manager.Start()
+	defer manager.Stop()
+
+	finished := manager.CreateJob(model.JobTypeReindex, "test-index", nil)
+	if err := manager.ExecuteJob(finished, func(ctx context.Context, job *model.Job) error { return nil }); err != nil {
+		t.Fatalf("Failed to execute job: %v", err)
+	}
+	waitForStatus(t, manager, finished, model.JobStatusCompleted)
+	pending := manager.CreateJob(model.JobTypeReindex, "test-index", nil)
+
+	if removed := manager.CleanupJobs(time.Hour); removed != 0 {
+		t.Errorf("Expected no job to have finished an hour ago, removed %d", removed)
+	}
+
+	// The cleanup routine removes the finished job once it is older than the retention
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := manager.GetJob(finished); errors.Is(err, internalErrors.ErrJobNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the finished job to be cleaned up after the retention")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := manager.GetJob(pending); err != nil {
+		t.Errorf("Expected the pending job to be kept, got %v", err)
 	}
 }
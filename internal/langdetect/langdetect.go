@@ -0,0 +1,94 @@
+// Package langdetect guesses the language of short texts, such as product titles and descriptions,
+// from the frequent words and letters of each supported language.
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// commonWords lists frequent function words per language. They make up a large share of any
+// sentence, so a handful of them is usually enough to tell the languages apart.
+var commonWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "is", "for", "with", "on", "that", "this", "are", "it", "by", "from", "at", "be", "or", "an", "your", "you", "not", "was", "has", "have", "new"},
+	"de": {"der", "die", "das", "und", "ist", "mit", "für", "von", "den", "dem", "des", "ein", "eine", "einer", "nicht", "auf", "zu", "im", "sich", "auch", "wie", "oder", "aus", "bei", "nach", "neue"},
+	"fr": {"le", "la", "les", "et", "des", "du", "un", "une", "est", "pour", "avec", "dans", "sur", "pas", "que", "qui", "au", "aux", "ce", "cette", "sont", "par", "ou", "nouveau", "nouvelle", "très"},
+	"es": {"el", "los", "las", "y", "del", "un", "una", "es", "para", "con", "por", "en", "que", "se", "su", "sus", "al", "lo", "como", "más", "pero", "muy", "está", "nuevo", "nueva", "sin"},
+	"it": {"il", "lo", "gli", "le", "e", "di", "del", "della", "un", "una", "è", "per", "con", "che", "non", "sono", "nel", "nella", "alla", "dei", "delle", "anche", "come", "più", "nuovo", "nuova"},
+	"pt": {"o", "os", "as", "e", "do", "da", "dos", "das", "um", "uma", "é", "para", "com", "em", "no", "na", "que", "não", "por", "mais", "ao", "seu", "sua", "são", "novo", "nova"},
+	"nl": {"de", "het", "een", "en", "van", "is", "voor", "met", "op", "niet", "dat", "die", "zijn", "aan", "ook", "bij", "uit", "naar", "als", "maar", "wordt", "deze", "nieuw", "nieuwe", "zonder", "onze"},
+}
+
+// distinctiveLetters lists letters that are frequent in one supported language only.
+var distinctiveLetters = map[rune]string{
+	'ß': "de", 'ä': "de", 'ö': "de", 'ü': "de",
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ã': "pt", 'õ': "pt",
+	'œ': "fr", 'ù': "fr", 'û': "fr", 'ë': "fr",
+	'ì': "it", 'ò': "it",
+	'ĳ': "nl",
+}
+
+// letterWeight is the score of a distinctive letter relative to a common word.
+const letterWeight = 0.5
+
+var commonWordSets = buildWordSets()
+
+func buildWordSets() map[string]map[string]struct{} {
+	sets := make(map[string]map[string]struct{}, len(commonWords))
+	for language, words := range commonWords {
+		set := make(map[string]struct{}, len(words))
+		for _, word := range words {
+			set[word] = struct{}{}
+		}
+		sets[language] = set
+	}
+	return sets
+}
+
+// IsSupported reports whether a language (ISO 639-1 code, e.g. "de") can be detected.
+func IsSupported(language string) bool {
+	_, ok := commonWords[language]
+	return ok
+}
+
+// Detect returns the candidate language that best explains the text, or "" when no candidate
+// stands out, for example when the text has no common words or two languages score the same.
+// Without candidates, every supported language is considered.
+func Detect(text string, candidates []string) string {
+	if len(candidates) == 0 {
+		for language := range commonWords {
+			candidates = append(candidates, language)
+		}
+	}
+
+	scores := make(map[string]float64, len(candidates))
+	lowered := strings.ToLower(text)
+	for _, word := range strings.FieldsFunc(lowered, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, language := range candidates {
+			if _, common := commonWordSets[language][word]; common {
+				scores[language]++
+			}
+		}
+	}
+	for _, r := range lowered {
+		if language, distinctive := distinctiveLetters[r]; distinctive {
+			scores[language] += letterWeight
+		}
+	}
+
+	best, bestScore, tied := "", 0.0, false
+	for _, language := range candidates {
+		score := scores[language]
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore && score > 0 && language != best:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,38 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		candidates []string
+		want       string
+	}{
+		{"english", "The Lord of the Rings", nil, "en"},
+		{"german", "Der Herr der Ringe", nil, "de"},
+		{"french", "Le Seigneur des anneaux", nil, "fr"},
+		{"spanish", "El señor de los anillos", nil, "es"},
+		{"italian", "Il Signore degli Anelli e la compagnia dell'anello", nil, "it"},
+		{"portuguese", "O Senhor dos Anéis: a irmandade do anel", nil, "pt"},
+		{"dutch", "De heer van de ringen", nil, "nl"},
+		{"distinctive letters", "Straße", nil, "de"},
+		{"no common words", "Matrix", nil, ""},
+		{"tie between candidates", "Un momento", []string{"es", "fr"}, ""},
+		{"restricted to candidates", "Der Herr der Ringe", []string{"en", "fr"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text, tt.candidates); got != tt.want {
+				t.Errorf("Detect(%q, %v) = %q, want %q", tt.text, tt.candidates, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSupported(t *testing.T) {
+	if !IsSupported("de") || IsSupported("ja") || IsSupported("") {
+		t.Error("Expected only languages with a profile to be supported")
+	}
+}
@@ -0,0 +1,84 @@
+// Package logging configures the structured logger of the server and carries the ID of the
+// request being served through contexts, so the logs of a request can be told apart from those of
+// the requests running alongside it.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats of the log output
+const (
+	FormatText = "text" // key=value pairs, easy to read in a terminal
+	FormatJSON = "json" // One JSON object per line, for log collectors
+)
+
+// RequestIDKey is the attribute that holds the ID of the request a log entry belongs to
+const RequestIDKey = "request_id"
+
+// requestIDContextKey is the context key of the ID of the request being served
+type requestIDContextKey struct{}
+
+// ParseLevel returns the level named by debug, info, warn or error, case-insensitively.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("log level '%s' is not one of debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// New returns a logger writing entries of the level and above to w, in the format.
+func New(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(format) {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return nil, fmt.Errorf("log format '%s' is not one of text or json", format)
+}
+
+// Setup makes a logger of the level and format, writing to w, the default logger. Output of the
+// standard log package goes through it as well, at the info level.
+func Setup(w io.Writer, levelName, format string) error {
+	level, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	logger, err := New(w, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request being served.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestID returns the ID of the request ctx serves, or "" outside of requests.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// FromContext returns the default logger, with the ID of the request ctx serves on every entry.
+func FromContext(ctx context.Context) *slog.Logger {
+	return WithRequest(RequestID(ctx))
+}
+
+// WithRequest returns the default logger, with the request ID on every entry unless it is empty.
+func WithRequest(requestID string) *slog.Logger {
+	if requestID == "" {
+		return slog.Default()
+	}
+	return slog.Default().With(RequestIDKey, requestID)
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, slog.LevelWarn, FormatJSON)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	logger.Info("dropped below the level")
+	logger.Warn("slow query", "index", "movies", "took_ms", 1200)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Output %q is not one JSON entry: %v", buf.String(), err)
+	}
+	if entry["msg"] != "slow query" || entry["index"] != "movies" || entry["took_ms"] != 1200.0 {
+		t.Errorf("entry = %v, want the warning with its attributes", entry)
+	}
+
+	if _, err := New(&buf, slog.LevelInfo, "xml"); err == nil {
+		t.Error("New() with an unknown format succeeded, want an error")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{"debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warn": slog.LevelWarn, "error": slog.LevelError} {
+		if level, err := ParseLevel(name); err != nil || level != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", name, level, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(\"verbose\") succeeded, want an error")
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	if err := Setup(&buf, "info", FormatText); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if id := RequestID(ctx); id != "" {
+		t.Errorf("RequestID() outside of requests = %q, want empty", id)
+	}
+	FromContext(ctx).Info("background work")
+	if strings.Contains(buf.String(), RequestIDKey) {
+		t.Errorf("entry outside of requests %q has a request ID", buf.String())
+	}
+
+	ctx = WithRequestID(ctx, "req-42")
+	if id := RequestID(ctx); id != "req-42" {
+		t.Errorf("RequestID() = %q, want req-42", id)
+	}
+	FromContext(ctx).Info("serving")
+	if !strings.Contains(buf.String(), "request_id=req-42") {
+		t.Errorf("output %q lacks the request ID", buf.String())
+	}
+}
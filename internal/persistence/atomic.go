@@ -0,0 +1,136 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteFileAtomic writes data to a temporary file next to filePath, flushes it to disk and renames
+// it over filePath, so the file is either left as it was or fully replaced, even if the process or
+// machine stops half-way. The directory is created if needed and flushed after the rename.
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	return WriteFileAtomicWithBackups(filePath, data, perm, 0)
+}
+
+// WriteFileAtomicWithBackups is WriteFileAtomic keeping the current version of filePath as the
+// most recent of its count backups, as RotateBackups does. The backups are only rotated once the
+// new version is flushed to disk, so a failed write leaves filePath and its backups as they were.
+func WriteFileAtomicWithBackups(filePath string, data []byte, perm os.FileMode, count int) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+	tempPath := file.Name()
+	defer func() {
+		// Only left behind when writing failed
+		_ = os.Remove(tempPath)
+	}()
+	if err := file.Chmod(perm); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to sync file %s: %w", filePath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := RotateBackups(filePath, count); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, filePath); err != nil {
+		return fmt.Errorf("failed to replace file %s: %w", filePath, err)
+	}
+	return syncDir(dir)
+}
+
+// BackupPath returns the path of the n-th backup of a file, 1 being the most recent.
+func BackupPath(filePath string, n int) string {
+	return filePath + "." + strconv.Itoa(n)
+}
+
+// RotateBackups makes filePath the most recent of its count backups: the existing backups move one
+// place down, the oldest is removed and filePath is linked, or copied, to its first backup, so
+// filePath itself stays in place until it is replaced. Missing files are skipped, and a count below
+// 1 keeps no backups.
+func RotateBackups(filePath string, count int) error {
+	if count < 1 {
+		return nil
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to back up file %s: %w", filePath, err)
+	}
+
+	if err := os.Remove(BackupPath(filePath, count)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove backup of %s: %w", filePath, err)
+	}
+	for n := count - 1; n >= 1; n-- {
+		if err := os.Rename(BackupPath(filePath, n), BackupPath(filePath, n+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate backup of %s: %w", filePath, err)
+		}
+	}
+	if err := linkOrCopy(filePath, BackupPath(filePath, 1)); err != nil {
+		return fmt.Errorf("failed to back up file %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// linkOrCopy creates a hard link to a file, or a flushed copy of it where links are not supported.
+func linkOrCopy(filePath, linkPath string) error {
+	if err := os.Link(filePath, linkPath); err == nil {
+		return nil
+	}
+
+	source, err := os.Open(filePath) // #nosec G304 -- filePath is controlled by application, not user input
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	info, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	target, err := os.OpenFile(linkPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm()) // #nosec G304 -- linkPath is controlled by application, not user input
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		_ = target.Close()
+		return err
+	}
+	if err := target.Sync(); err != nil {
+		_ = target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// syncDir flushes a directory, so the files renamed into it survive a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir) // #nosec G304 -- dir is controlled by application, not user input
+	if err != nil {
+		return fmt.Errorf("failed to open directory %s: %w", dir, err)
+	}
+	if err := d.Sync(); err != nil {
+		_ = d.Close()
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+	return d.Close()
+}
@@ -3,12 +3,14 @@ package persistence
 import (
 	"encoding/gob"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 )
 
 // SaveGob encodes the given object using gob and saves it to the specified filePath.
-// It creates necessary directories if they don't exist.
+// It creates necessary directories if they don't exist. The object is written to a temporary file
+// that then replaces filePath, so processes reading the file never see it partially written.
 func SaveGob(filePath string, object interface{}) error {
 	// Ensure the directory exists
 	dir := filepath.Dir(filePath)
@@ -16,21 +18,32 @@ func SaveGob(filePath string, object interface{}) error {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	file, err := os.Create(filePath) // #nosec G304 -- filePath is controlled by application, not user input
+	file, err := os.CreateTemp(dir, filepath.Base(filePath)+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create file %s: %w", filePath, err)
 	}
+	tempPath := file.Name()
 	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			// Log the error but don't override the main error
-			fmt.Printf("Warning: failed to close file %s: %v\n", filePath, closeErr)
-		}
+		// Only left behind when writing failed
+		_ = os.Remove(tempPath)
 	}()
+	// Temporary files are only readable by their owner; other processes may open the data read-only
+	if err := file.Chmod(0644); err != nil { // #nosec G302 -- index files are not secret
+		_ = file.Close()
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
 
 	encoder := gob.NewEncoder(file)
 	if err := encoder.Encode(object); err != nil {
+		_ = file.Close()
 		return fmt.Errorf("failed to gob encode to file %s: %w", filePath, err)
 	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := os.Rename(tempPath, filePath); err != nil {
+		return fmt.Errorf("failed to replace file %s: %w", filePath, err)
+	}
 	return nil
 }
 
@@ -49,7 +62,7 @@ func LoadGob(filePath string, objectPointer interface{}) error {
 	defer func() {
 		if closeErr := file.Close(); closeErr != nil {
 			// Log the error but don't override the main error
-			fmt.Printf("Warning: failed to close file %s: %v\n", filePath, closeErr)
+			slog.Warn("failed to close file", "path", filePath, "error", closeErr)
 		}
 	}()
 
@@ -0,0 +1,114 @@
+// Package rewrite provides the built-in query rewriters, enabled per index by its settings.
+package rewrite
+
+import (
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/internal/tokenizer"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SynonymRewriter replaces query tokens with their configured synonyms.
+// Replacements are one-way: "tv" -> "television" does not imply the reverse.
+type SynonymRewriter struct {
+	synonyms map[string][]string
+}
+
+// NewSynonymRewriter creates a rewriter from a map of term to replacement text.
+// Both keys and replacements are tokenized, so "sci-fi" -> "science fiction" works as expected.
+func NewSynonymRewriter(synonyms map[string]string) *SynonymRewriter {
+	normalized := make(map[string][]string, len(synonyms))
+	for term, replacement := range synonyms {
+		termTokens := tokenizer.Tokenize(term)
+		replacementTokens := tokenizer.Tokenize(replacement)
+		if len(termTokens) != 1 || len(replacementTokens) == 0 {
+			continue
+		}
+		normalized[termTokens[0]] = replacementTokens
+	}
+	return &SynonymRewriter{synonyms: normalized}
+}
+
+// Name returns the rewriter name.
+func (r *SynonymRewriter) Name() string { return "synonyms" }
+
+// Rewrite replaces every token that has a synonym with its replacement tokens.
+func (r *SynonymRewriter) Rewrite(_ services.RewriteContext, query services.ParsedQuery) (services.ParsedQuery, error) {
+	tokens := make([]string, 0, len(query.Tokens))
+	for _, token := range query.Tokens {
+		if replacement, ok := r.synonyms[token]; ok {
+			tokens = append(tokens, replacement...)
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	query.Tokens = tokens
+	return query, nil
+}
+
+// SpellCorrectionRewriter replaces tokens that are unknown to the index with the closest indexed term.
+type SpellCorrectionRewriter struct {
+	maxDistance   int
+	minWordLength int
+}
+
+// NewSpellCorrectionRewriter creates a rewriter correcting unknown tokens of at least
+// minWordLength characters to indexed terms within maxDistance edits.
+func NewSpellCorrectionRewriter(maxDistance, minWordLength int) *SpellCorrectionRewriter {
+	return &SpellCorrectionRewriter{maxDistance: maxDistance, minWordLength: minWordLength}
+}
+
+// Name returns the rewriter name.
+func (r *SpellCorrectionRewriter) Name() string { return "spell_correction" }
+
+// Rewrite corrects tokens that do not exist in the index vocabulary.
+func (r *SpellCorrectionRewriter) Rewrite(ctx services.RewriteContext, query services.ParsedQuery) (services.ParsedQuery, error) {
+	if ctx.Vocabulary == nil || r.maxDistance <= 0 {
+		return query, nil
+	}
+	tokens := make([]string, len(query.Tokens))
+	for i, token := range query.Tokens {
+		tokens[i] = token
+		if len([]rune(token)) < r.minWordLength || ctx.Vocabulary.HasTerm(token) {
+			continue
+		}
+		if corrected, ok := ctx.Vocabulary.ClosestTerm(token, r.maxDistance); ok {
+			tokens[i] = corrected
+		}
+	}
+	query.Tokens = tokens
+	return query, nil
+}
+
+// StopWordsRewriter drops stop words from queries.
+// A query made only of stop words is left untouched so it still returns results.
+type StopWordsRewriter struct {
+	stopWords map[string]struct{}
+}
+
+// NewStopWordsRewriter creates a rewriter removing the given stop words.
+func NewStopWordsRewriter(stopWords []string) *StopWordsRewriter {
+	set := make(map[string]struct{}, len(stopWords))
+	for _, word := range stopWords {
+		set[strings.ToLower(strings.TrimSpace(word))] = struct{}{}
+	}
+	return &StopWordsRewriter{stopWords: set}
+}
+
+// Name returns the rewriter name.
+func (r *StopWordsRewriter) Name() string { return "stop_words" }
+
+// Rewrite removes stop words from the query tokens.
+func (r *StopWordsRewriter) Rewrite(_ services.RewriteContext, query services.ParsedQuery) (services.ParsedQuery, error) {
+	tokens := make([]string, 0, len(query.Tokens))
+	for _, token := range query.Tokens {
+		if _, isStopWord := r.stopWords[token]; !isStopWord {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return query, nil
+	}
+	query.Tokens = tokens
+	return query, nil
+}
@@ -0,0 +1,71 @@
+package rewrite
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+type fakeVocabulary struct {
+	terms       map[string]bool
+	corrections map[string]string
+}
+
+func (v fakeVocabulary) HasTerm(term string) bool { return v.terms[term] }
+
+func (v fakeVocabulary) ClosestTerm(term string, _ int) (string, bool) {
+	corrected, ok := v.corrections[term]
+	return corrected, ok
+}
+
+func TestSynonymRewriter(t *testing.T) {
+	rewriter := NewSynonymRewriter(map[string]string{"tv": "television", "sci-fi": "science fiction"})
+
+	result, err := rewriter.Rewrite(services.RewriteContext{}, services.ParsedQuery{Tokens: []string{"tv", "shows"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"television", "shows"}; !reflect.DeepEqual(result.Tokens, expected) {
+		t.Errorf("expected %v, got %v", expected, result.Tokens)
+	}
+
+	// Multi-token keys are ignored because rewriting works token by token
+	result, _ = rewriter.Rewrite(services.RewriteContext{}, services.ParsedQuery{Tokens: []string{"sci", "fi"}})
+	if expected := []string{"sci", "fi"}; !reflect.DeepEqual(result.Tokens, expected) {
+		t.Errorf("expected %v, got %v", expected, result.Tokens)
+	}
+}
+
+func TestSpellCorrectionRewriter(t *testing.T) {
+	vocabulary := fakeVocabulary{
+		terms:       map[string]bool{"matrix": true},
+		corrections: map[string]string{"matirx": "matrix", "teh": "the"},
+	}
+	rewriter := NewSpellCorrectionRewriter(2, 4)
+
+	result, err := rewriter.Rewrite(services.RewriteContext{Vocabulary: vocabulary}, services.ParsedQuery{Tokens: []string{"teh", "matirx", "matrix"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// "teh" is shorter than the minimum word length and is kept as-is
+	if expected := []string{"teh", "matrix", "matrix"}; !reflect.DeepEqual(result.Tokens, expected) {
+		t.Errorf("expected %v, got %v", expected, result.Tokens)
+	}
+}
+
+func TestStopWordsRewriter(t *testing.T) {
+	rewriter := NewStopWordsRewriter(config.DefaultEnglishStopWords)
+
+	result, _ := rewriter.Rewrite(services.RewriteContext{}, services.ParsedQuery{Tokens: []string{"the", "office"}})
+	if expected := []string{"office"}; !reflect.DeepEqual(result.Tokens, expected) {
+		t.Errorf("expected %v, got %v", expected, result.Tokens)
+	}
+
+	// A query made only of stop words is left untouched
+	result, _ = rewriter.Rewrite(services.RewriteContext{}, services.ParsedQuery{Tokens: []string{"the", "a"}})
+	if expected := []string{"the", "a"}; !reflect.DeepEqual(result.Tokens, expected) {
+		t.Errorf("expected %v, got %v", expected, result.Tokens)
+	}
+}
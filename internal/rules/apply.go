@@ -0,0 +1,219 @@
+package rules
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// Matches reports whether a rule applies to a query. The condition and the query are compared
+// as analyzed tokens, so casing and punctuation do not matter.
+func Matches(rule model.Rule, queryTokens []string, tokenize func(string) []string) bool {
+	conditionTokens := tokenize(rule.Condition.Query)
+	if len(conditionTokens) == 0 {
+		return true
+	}
+
+	if rule.Condition.Match == model.RuleMatchContains {
+		for start := 0; start+len(conditionTokens) <= len(queryTokens); start++ {
+			if tokensEqual(queryTokens[start:start+len(conditionTokens)], conditionTokens) {
+				return true
+			}
+		}
+		return false
+	}
+	return tokensEqual(queryTokens, conditionTokens)
+}
+
+// Active reports whether a rule applies at a given time: from its ValidFrom, included, until its
+// ValidUntil, excluded. Rules without a validity window are always active.
+func Active(rule model.Rule, now time.Time) bool {
+	if rule.ValidFrom != nil && now.Before(*rule.ValidFrom) {
+		return false
+	}
+	return rule.ValidUntil == nil || now.Before(*rule.ValidUntil)
+}
+
+// Rewrite applies the rewrite_query actions of matching rules to a query string before it runs and
+// reports which rules changed it. A replace action replaces the whole query and an append action
+// adds its query to the end; each action rewrites the query left by the previous ones.
+func Rewrite(matched []model.Rule, queryString string) (string, []services.AppliedRule) {
+	var applied []services.AppliedRule
+	for _, rule := range matched {
+		for _, action := range rule.Actions {
+			if action.Type != model.RuleActionRewriteQuery {
+				continue
+			}
+			if action.Mode == model.RuleRewriteAppend {
+				queryString = strings.TrimSpace(queryString + " " + action.Query)
+			} else {
+				queryString = action.Query
+			}
+			applied = append(applied, services.AppliedRule{RuleID: rule.ID, Action: action.Type, Query: queryString})
+		}
+	}
+	return queryString, applied
+}
+
+// AddFilters adds the conditions of the add_filter actions of matching rules to a query's filters
+// and reports which rules added them. Documents must match both the added conditions and the
+// query's filters, which are left untouched and nested as the first group of the result.
+func AddFilters(matched []model.Rule, filters *services.Filters) (*services.Filters, []services.AppliedRule) {
+	var conditions []services.FilterCondition
+	var applied []services.AppliedRule
+	for _, rule := range matched {
+		for _, action := range rule.Actions {
+			if action.Type != model.RuleActionAddFilter || action.Filter == nil {
+				continue
+			}
+			conditions = append(conditions, services.FilterCondition{
+				Field:    action.Filter.Field,
+				Operator: action.Filter.Operator,
+				Value:    action.Filter.Value,
+			})
+			applied = append(applied, services.AppliedRule{RuleID: rule.ID, Action: action.Type})
+		}
+	}
+	if len(conditions) == 0 {
+		return filters, nil
+	}
+
+	combined := &services.Filters{Operator: "AND", Filters: conditions}
+	if filters != nil {
+		combined.Groups = []services.Filters{*filters}
+	}
+	return combined, applied
+}
+
+// Boost applies the boost actions of matching rules to the scores of hits that are not ranked yet
+// and reports which rules changed them. A boosted score is multiplied by the action's Multiplier,
+// when set, and then the action's Addend is added to it.
+func Boost(matched []model.Rule, hits []services.HitResult) []services.AppliedRule {
+	var applied []services.AppliedRule
+	var positions map[string]int
+
+	for _, rule := range matched {
+		for _, action := range rule.Actions {
+			if action.Type != model.RuleActionBoost {
+				continue
+			}
+			if positions == nil {
+				positions = make(map[string]int, len(hits))
+				for i, hit := range hits {
+					if documentID, ok := hit.Document.GetDocumentID(); ok {
+						positions[documentID] = i
+					}
+				}
+			}
+			var affected []string
+			for _, documentID := range action.DocumentIDs {
+				position, found := positions[documentID]
+				if !found {
+					continue
+				}
+				if action.Multiplier != 0 {
+					hits[position].Score *= action.Multiplier
+				}
+				hits[position].Score += action.Addend
+				affected = append(affected, documentID)
+			}
+			if len(affected) > 0 {
+				applied = append(applied, services.AppliedRule{RuleID: rule.ID, Action: action.Type, DocumentIDs: affected})
+			}
+		}
+	}
+	return applied
+}
+
+// Apply applies the actions of matching rules to ranked hits and reports which rules changed them.
+// Hide actions run first, so a document both pinned and hidden stays hidden. Pinned documents
+// that are not among the hits are fetched with lookup, which returns false for documents that
+// do not exist or must not be shown.
+func Apply(matched []model.Rule, hits []services.HitResult, lookup func(documentID string) (services.HitResult, bool)) ([]services.HitResult, []services.AppliedRule) {
+	var applied []services.AppliedRule
+	hidden := make(map[string]struct{})
+
+	for _, rule := range matched {
+		for _, action := range rule.Actions {
+			if action.Type != model.RuleActionHide {
+				continue
+			}
+			var affected []string
+			for _, documentID := range action.DocumentIDs {
+				hidden[documentID] = struct{}{}
+				if position := hitPosition(hits, documentID); position >= 0 {
+					hits = append(hits[:position], hits[position+1:]...)
+					affected = append(affected, documentID)
+				}
+			}
+			if len(affected) > 0 {
+				applied = append(applied, services.AppliedRule{RuleID: rule.ID, Action: action.Type, DocumentIDs: affected})
+			}
+		}
+	}
+
+	for _, rule := range matched {
+		for _, action := range rule.Actions {
+			if action.Type != model.RuleActionPin {
+				continue
+			}
+			target := action.Position - 1
+			if target < 0 {
+				target = 0
+			}
+			var affected []string
+			for _, documentID := range action.DocumentIDs {
+				if _, isHidden := hidden[documentID]; isHidden {
+					continue
+				}
+				var hit services.HitResult
+				if position := hitPosition(hits, documentID); position >= 0 {
+					hit = hits[position]
+					hits = append(hits[:position], hits[position+1:]...)
+				} else if fetched, ok := lookup(documentID); ok {
+					hit = fetched
+				} else {
+					continue
+				}
+
+				insertAt := target
+				if insertAt > len(hits) {
+					insertAt = len(hits)
+				}
+				hits = append(hits, services.HitResult{})
+				copy(hits[insertAt+1:], hits[insertAt:])
+				hits[insertAt] = hit
+				target = insertAt + 1
+				affected = append(affected, documentID)
+			}
+			if len(affected) > 0 {
+				applied = append(applied, services.AppliedRule{RuleID: rule.ID, Action: action.Type, DocumentIDs: affected})
+			}
+		}
+	}
+	return hits, applied
+}
+
+// hitPosition returns the index of the hit for documentID, or -1.
+func hitPosition(hits []services.HitResult, documentID string) int {
+	for i, hit := range hits {
+		if id, ok := hit.Document.GetDocumentID(); ok && id == documentID {
+			return i
+		}
+	}
+	return -1
+}
+
+func tokensEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,273 @@
+package rules
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func hitIDs(hits []services.HitResult) []string {
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i], _ = hit.Document.GetDocumentID()
+	}
+	return ids
+}
+
+func hitsFor(ids ...string) []services.HitResult {
+	hits := make([]services.HitResult, len(ids))
+	for i, id := range ids {
+		hits[i] = services.HitResult{Document: model.Document{"documentID": id}}
+	}
+	return hits
+}
+
+func TestMatches(t *testing.T) {
+	tokenize := func(s string) []string { return strings.Fields(strings.ToLower(s)) }
+
+	tests := []struct {
+		name      string
+		condition model.RuleCondition
+		query     string
+		want      bool
+	}{
+		{"exact match", model.RuleCondition{Query: "Running Shoes"}, "running shoes", true},
+		{"exact does not match longer query", model.RuleCondition{Query: "shoes"}, "running shoes", false},
+		{"contains matches sequence", model.RuleCondition{Query: "running shoes", Match: model.RuleMatchContains}, "red running shoes sale", true},
+		{"contains requires order", model.RuleCondition{Query: "running shoes", Match: model.RuleMatchContains}, "shoes running", false},
+		{"empty condition matches everything", model.RuleCondition{}, "anything", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := model.Rule{Condition: tt.condition}
+			if got := Matches(rule, tokenize(tt.query), tokenize); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActive(t *testing.T) {
+	now := time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC)
+	christmas := time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)
+	boxingDay := time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		validFrom  *time.Time
+		validUntil *time.Time
+		at         time.Time
+		want       bool
+	}{
+		{"no window", nil, nil, now, true},
+		{"before the window", &christmas, &boxingDay, now, false},
+		{"start of the window", &christmas, &boxingDay, christmas, true},
+		{"end of the window", &christmas, &boxingDay, boxingDay, false},
+		{"open-ended window", &christmas, nil, boxingDay, true},
+		{"expired", nil, &christmas, boxingDay, false},
+		{"not expired yet", nil, &christmas, now, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := model.Rule{ValidFrom: tt.validFrom, ValidUntil: tt.validUntil}
+			if got := Active(rule, tt.at); got != tt.want {
+				t.Errorf("Active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	lookup := func(documentID string) (services.HitResult, bool) {
+		if documentID == "sponsored" {
+			return services.HitResult{Document: model.Document{"documentID": documentID}}, true
+		}
+		return services.HitResult{}, false
+	}
+
+	matched := []model.Rule{
+		{
+			ID: "promo",
+			Actions: []model.RuleAction{
+				{Type: model.RuleActionPin, DocumentIDs: []string{"sponsored", "missing", "c"}, Position: 2},
+			},
+		},
+		{
+			ID: "cleanup",
+			Actions: []model.RuleAction{
+				{Type: model.RuleActionHide, DocumentIDs: []string{"b", "not-a-hit"}},
+			},
+		},
+	}
+
+	hits, applied := Apply(matched, hitsFor("a", "b", "c", "d"), lookup)
+
+	if got, want := hitIDs(hits), []string{"a", "sponsored", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hits = %v, want %v", got, want)
+	}
+
+	wantApplied := []services.AppliedRule{
+		{RuleID: "cleanup", Action: model.RuleActionHide, DocumentIDs: []string{"b"}},
+		{RuleID: "promo", Action: model.RuleActionPin, DocumentIDs: []string{"sponsored", "c"}},
+	}
+	if !reflect.DeepEqual(applied, wantApplied) {
+		t.Errorf("applied = %+v, want %+v", applied, wantApplied)
+	}
+}
+
+func TestBoost(t *testing.T) {
+	matched := []model.Rule{
+		{
+			ID: "featured",
+			Actions: []model.RuleAction{
+				{Type: model.RuleActionBoost, DocumentIDs: []string{"b", "not-a-hit"}, Multiplier: 3},
+				{Type: model.RuleActionHide, DocumentIDs: []string{"c"}},
+			},
+		},
+		{
+			ID: "clearance",
+			Actions: []model.RuleAction{
+				{Type: model.RuleActionBoost, DocumentIDs: []string{"b", "c"}, Addend: 0.5},
+			},
+		},
+	}
+
+	hits := hitsFor("a", "b", "c")
+	for i := range hits {
+		hits[i].Score = 1
+	}
+	applied := Boost(matched, hits)
+
+	var scores []float64
+	for _, hit := range hits {
+		scores = append(scores, hit.Score)
+	}
+	if want := []float64{1, 3.5, 1.5}; !reflect.DeepEqual(scores, want) {
+		t.Errorf("scores = %v, want %v", scores, want)
+	}
+	wantApplied := []services.AppliedRule{
+		{RuleID: "featured", Action: model.RuleActionBoost, DocumentIDs: []string{"b"}},
+		{RuleID: "clearance", Action: model.RuleActionBoost, DocumentIDs: []string{"b", "c"}},
+	}
+	if !reflect.DeepEqual(applied, wantApplied) {
+		t.Errorf("applied = %+v, want %+v", applied, wantApplied)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	matched := []model.Rule{
+		{
+			ID: "synonym",
+			Actions: []model.RuleAction{
+				{Type: model.RuleActionRewriteQuery, Query: "children shoes"},
+				{Type: model.RuleActionHide, DocumentIDs: []string{"c"}},
+			},
+		},
+		{
+			ID:      "brand",
+			Actions: []model.RuleAction{{Type: model.RuleActionRewriteQuery, Query: "acme", Mode: model.RuleRewriteAppend}},
+		},
+	}
+
+	queryString, applied := Rewrite(matched, "kids shoes")
+	if queryString != "children shoes acme" {
+		t.Errorf("queryString = %q, want %q", queryString, "children shoes acme")
+	}
+	wantApplied := []services.AppliedRule{
+		{RuleID: "synonym", Action: model.RuleActionRewriteQuery, Query: "children shoes"},
+		{RuleID: "brand", Action: model.RuleActionRewriteQuery, Query: "children shoes acme"},
+	}
+	if !reflect.DeepEqual(applied, wantApplied) {
+		t.Errorf("applied = %+v, want %+v", applied, wantApplied)
+	}
+
+	if queryString, applied := Rewrite(matched[:0], "kids shoes"); queryString != "kids shoes" || applied != nil {
+		t.Errorf("Rewrite() without rules = %q, %+v, want the query unchanged", queryString, applied)
+	}
+}
+
+func TestAddFilters(t *testing.T) {
+	matched := []model.Rule{{
+		ID: "kids",
+		Actions: []model.RuleAction{
+			{Type: model.RuleActionAddFilter, Filter: &model.RuleFilter{Field: "rating", Value: "PG"}},
+		},
+	}}
+	condition := services.FilterCondition{Field: "rating", Value: "PG"}
+
+	filters, applied := AddFilters(matched, nil)
+	if want := (&services.Filters{Operator: "AND", Filters: []services.FilterCondition{condition}}); !reflect.DeepEqual(filters, want) {
+		t.Errorf("filters = %+v, want %+v", filters, want)
+	}
+	if want := []services.AppliedRule{{RuleID: "kids", Action: model.RuleActionAddFilter}}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %+v, want %+v", applied, want)
+	}
+
+	queryFilters := &services.Filters{Operator: "OR", Filters: []services.FilterCondition{{Field: "year", Operator: "_gte", Value: 2020}}}
+	filters, _ = AddFilters(matched, queryFilters)
+	want := &services.Filters{Operator: "AND", Filters: []services.FilterCondition{condition}, Groups: []services.Filters{*queryFilters}}
+	if !reflect.DeepEqual(filters, want) {
+		t.Errorf("filters = %+v, want %+v", filters, want)
+	}
+
+	if filters, applied := AddFilters(nil, queryFilters); filters != queryFilters || applied != nil {
+		t.Errorf("AddFilters() without rules = %+v, %+v, want the filters unchanged", filters, applied)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := model.Rule{Actions: []model.RuleAction{{Type: model.RuleActionHide, DocumentIDs: []string{"doc1"}}}}
+	if err := ValidateRule(valid); err != nil {
+		t.Errorf("ValidateRule() of a valid rule error = %v", err)
+	}
+	boost := model.Rule{Actions: []model.RuleAction{{Type: model.RuleActionBoost, DocumentIDs: []string{"doc1"}, Multiplier: 2}}}
+	if err := ValidateRule(boost); err != nil {
+		t.Errorf("ValidateRule() of a valid boost rule error = %v", err)
+	}
+	queryActions := model.Rule{Actions: []model.RuleAction{
+		{Type: model.RuleActionRewriteQuery, Query: "children", Mode: model.RuleRewriteAppend},
+		{Type: model.RuleActionAddFilter, Filter: &model.RuleFilter{Field: "rating", Operator: "_lte", Value: 2}},
+	}}
+	if err := ValidateRule(queryActions); err != nil {
+		t.Errorf("ValidateRule() of valid query actions error = %v", err)
+	}
+
+	earlier, later := time.Now(), time.Now().Add(time.Hour)
+	scheduled := model.Rule{Actions: valid.Actions, ValidFrom: &earlier, ValidUntil: &later}
+	if err := ValidateRule(scheduled); err != nil {
+		t.Errorf("ValidateRule() of a scheduled rule error = %v", err)
+	}
+
+	invalid := []model.Rule{
+		{},
+		{Condition: model.RuleCondition{Match: "fuzzy"}, Actions: valid.Actions},
+		{Actions: []model.RuleAction{{Type: "promote", DocumentIDs: []string{"doc1"}}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionBoost, DocumentIDs: []string{"doc1"}}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionBoost, DocumentIDs: []string{"doc1"}, Multiplier: -1}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionPin, DocumentIDs: []string{"doc1"}, Addend: 1}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionPin}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionHide, DocumentIDs: []string{"doc1"}, Position: 2}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionPin, DocumentIDs: []string{" "}}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionRewriteQuery}}},
+		{Actions: valid.Actions, ValidFrom: &later, ValidUntil: &earlier},
+		{Actions: valid.Actions, ValidFrom: &earlier, ValidUntil: &earlier},
+		{Actions: []model.RuleAction{{Type: model.RuleActionRewriteQuery, Query: "kids", Mode: "prepend"}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionRewriteQuery, Query: "kids", DocumentIDs: []string{"doc1"}}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionHide, DocumentIDs: []string{"doc1"}, Query: "kids"}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionAddFilter}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionAddFilter, Filter: &model.RuleFilter{Field: "rating", Operator: "_like", Value: "PG"}}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionAddFilter, Filter: &model.RuleFilter{Field: "rating"}}}},
+		{Actions: []model.RuleAction{{Type: model.RuleActionPin, DocumentIDs: []string{"doc1"}, Filter: &model.RuleFilter{Field: "rating", Value: "PG"}}}},
+	}
+	for i, rule := range invalid {
+		if err := ValidateRule(rule); !errors.Is(err, internalErrors.ErrInvalidInput) {
+			t.Errorf("ValidateRule(invalid[%d]) error = %v, want ErrInvalidInput", i, err)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/persistence"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// DefaultBackups is the number of previous versions of the rules file a FileRuleStore keeps
+const DefaultBackups = 3
+
+// FileRuleStore is a services.RuleStore held in memory and written to a JSON file on every change. The file
+// is replaced atomically, and its previous versions are kept as numbered backups (rules.json.1 being
+// the most recent) that Load falls back to when the file is corrupt.
+type FileRuleStore struct {
+	mu       sync.RWMutex
+	filePath string
+	backups  int                              // Previous versions of the file kept
+	rules    map[string]map[string]model.Rule // Index name -> rule ID -> rule
+}
+
+// NewFileRuleStore creates a rule store backed by the given file, keeping DefaultBackups backups of
+// it. Call Load to read existing rules.
+func NewFileRuleStore(filePath string) *FileRuleStore {
+	return &FileRuleStore{
+		filePath: filePath,
+		backups:  DefaultBackups,
+		rules:    make(map[string]map[string]model.Rule),
+	}
+}
+
+// SetBackups changes the number of previous versions of the rules file kept. Zero keeps none.
+func (s *FileRuleStore) SetBackups(backups int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backups = max(backups, 0)
+}
+
+// Load reads the rules file. A missing file leaves the store empty. When the file cannot be read or
+// parsed, or is missing while backups exist because a write was interrupted, the most recent backup
+// that can be read is loaded instead.
+func (s *FileRuleStore) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := readRulesFile(s.filePath)
+	if err != nil {
+		recovered := false
+		for n := 1; n <= s.backups && !recovered; n++ {
+			backupPath := persistence.BackupPath(s.filePath, n)
+			if backup, backupErr := readRulesFile(backupPath); backupErr == nil {
+				slog.Warn("failed to load rules file, recovered rules from backup", "path", s.filePath, "backup", backupPath, "error", err)
+				stored, recovered = backup, true
+			}
+		}
+		if !recovered {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	s.rules = make(map[string]map[string]model.Rule)
+	for _, rule := range stored {
+		s.putUnsafe(rule)
+	}
+	return nil
+}
+
+// readRulesFile reads and parses a rules file. A missing file is reported with the error of
+// os.ReadFile, for os.IsNotExist.
+func readRulesFile(filePath string) ([]model.Rule, error) {
+	data, err := os.ReadFile(filePath) // #nosec G304 -- filePath is controlled by application, not user input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var stored []model.Rule
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rules: %w", err)
+	}
+	return stored, nil
+}
+
+// ListRules returns the rules of an index in creation order.
+func (s *FileRuleStore) ListRules(indexName string) []model.Rule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	indexRules := s.rules[indexName]
+	list := make([]model.Rule, 0, len(indexRules))
+	for _, rule := range indexRules {
+		list = append(list, rule)
+	}
+	sortRules(list)
+	return list
+}
+
+// GetRule returns a single rule.
+func (s *FileRuleStore) GetRule(indexName, ruleID string) (model.Rule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rule, exists := s.rules[indexName][ruleID]
+	if !exists {
+		return model.Rule{}, errors.NewRuleNotFoundError(ruleID, indexName)
+	}
+	return rule, nil
+}
+
+// SaveRule creates the rule or replaces the existing rule with the same index and ID.
+func (s *FileRuleStore) SaveRule(rule model.Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.rules[rule.IndexName][rule.ID]
+	s.putUnsafe(rule)
+	if err := s.persistUnsafe(); err != nil {
+		if existed {
+			s.putUnsafe(previous)
+		} else {
+			delete(s.rules[rule.IndexName], rule.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteRule removes a single rule.
+func (s *FileRuleStore) DeleteRule(indexName, ruleID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[indexName][ruleID]
+	if !exists {
+		return errors.NewRuleNotFoundError(ruleID, indexName)
+	}
+	delete(s.rules[indexName], ruleID)
+	if err := s.persistUnsafe(); err != nil {
+		s.putUnsafe(rule)
+		return err
+	}
+	return nil
+}
+
+// DeleteIndexRules removes every rule of an index.
+func (s *FileRuleStore) DeleteIndexRules(indexName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rules[indexName]) == 0 {
+		return nil
+	}
+	delete(s.rules, indexName)
+	return s.persistUnsafe()
+}
+
+// ReplaceIndexRules makes the given rules the only rules of an index, written to the rules file
+// once. On failure the index keeps its previous rules.
+func (s *FileRuleStore) ReplaceIndexRules(indexName string, rules []model.Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.rules[indexName]
+	s.rules[indexName] = make(map[string]model.Rule, len(rules))
+	for _, rule := range rules {
+		rule.IndexName = indexName
+		s.rules[indexName][rule.ID] = rule
+	}
+	if err := s.persistUnsafe(); err != nil {
+		if existed {
+			s.rules[indexName] = previous
+		} else {
+			delete(s.rules, indexName)
+		}
+		return err
+	}
+	return nil
+}
+
+// RenameIndexRules moves the rules of an index to its new name.
+func (s *FileRuleStore) RenameIndexRules(oldName, newName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	indexRules := s.rules[oldName]
+	if len(indexRules) == 0 {
+		return nil
+	}
+	delete(s.rules, oldName)
+	for _, rule := range indexRules {
+		rule.IndexName = newName
+		s.putUnsafe(rule)
+	}
+	return s.persistUnsafe()
+}
+
+// putUnsafe stores a rule in memory. The caller must hold s.mu.
+func (s *FileRuleStore) putUnsafe(rule model.Rule) {
+	if s.rules[rule.IndexName] == nil {
+		s.rules[rule.IndexName] = make(map[string]model.Rule)
+	}
+	s.rules[rule.IndexName][rule.ID] = rule
+}
+
+// persistUnsafe writes all rules to the rules file, keeping its current version in the backups.
+// The caller must hold s.mu.
+func (s *FileRuleStore) persistUnsafe() error {
+	all := make([]model.Rule, 0)
+	for _, indexRules := range s.rules {
+		for _, rule := range indexRules {
+			all = append(all, rule)
+		}
+	}
+	sortRules(all)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].IndexName < all[j].IndexName
+	})
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rules: %w", err)
+	}
+
+	// The backups are only rotated once the new version is on disk, so a failed write leaves the
+	// rules file as it was
+	if err := persistence.WriteFileAtomicWithBackups(s.filePath, data, 0600, s.backups); err != nil {
+		return fmt.Errorf("failed to write rules file: %w", err)
+	}
+	return nil
+}
+
+// sortRules orders rules by creation time, then by ID.
+func sortRules(list []model.Rule) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+}
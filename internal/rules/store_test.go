@@ -0,0 +1,172 @@
+package rules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	internalErrors "github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/persistence"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func testRule(id, indexName string, createdAt time.Time) model.Rule {
+	return model.Rule{
+		ID:        id,
+		IndexName: indexName,
+		Condition: model.RuleCondition{Query: "shoes"},
+		Actions:   []model.RuleAction{{Type: model.RuleActionPin, DocumentIDs: []string{"doc1"}}},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func TestFileRuleStore(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "rules.json")
+	s := NewFileRuleStore(filePath)
+	if err := s.Load(); err != nil {
+		t.Fatalf("Load() on missing file error = %v", err)
+	}
+
+	now := time.Now()
+	for _, rule := range []model.Rule{
+		testRule("b", "products", now),
+		testRule("a", "products", now.Add(time.Second)),
+		testRule("c", "articles", now),
+	} {
+		if err := s.SaveRule(rule); err != nil {
+			t.Fatalf("SaveRule(%s) error = %v", rule.ID, err)
+		}
+	}
+
+	list := s.ListRules("products")
+	if len(list) != 2 || list[0].ID != "b" || list[1].ID != "a" {
+		t.Errorf("ListRules() = %v, want rules b, a in creation order", list)
+	}
+
+	if _, err := s.GetRule("articles", "a"); !errors.Is(err, internalErrors.ErrRuleNotFound) {
+		t.Errorf("GetRule() of a rule from another index error = %v, want ErrRuleNotFound", err)
+	}
+
+	if err := s.DeleteRule("products", "b"); err != nil {
+		t.Fatalf("DeleteRule() error = %v", err)
+	}
+	if err := s.DeleteRule("products", "b"); !errors.Is(err, internalErrors.ErrRuleNotFound) {
+		t.Errorf("DeleteRule() twice error = %v, want ErrRuleNotFound", err)
+	}
+
+	if err := s.RenameIndexRules("products", "catalog"); err != nil {
+		t.Fatalf("RenameIndexRules() error = %v", err)
+	}
+	if rule, err := s.GetRule("catalog", "a"); err != nil || rule.IndexName != "catalog" {
+		t.Errorf("GetRule() after rename = %v, %v; want rule a in index catalog", rule, err)
+	}
+
+	// Rules survive a reload from disk
+	reloaded := NewFileRuleStore(filePath)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := len(reloaded.ListRules("catalog")) + len(reloaded.ListRules("articles")); got != 2 {
+		t.Errorf("reloaded %d rules, want 2", got)
+	}
+
+	if err := reloaded.DeleteIndexRules("articles"); err != nil {
+		t.Fatalf("DeleteIndexRules() error = %v", err)
+	}
+	if got := len(reloaded.ListRules("articles")); got != 0 {
+		t.Errorf("ListRules() after DeleteIndexRules = %d rules, want 0", got)
+	}
+}
+
+func TestFileRuleStoreBackups(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "rules.json")
+	s := NewFileRuleStore(filePath)
+	s.SetBackups(2)
+
+	now := time.Now()
+	for i, id := range []string{"a", "b", "c", "d"} {
+		if err := s.SaveRule(testRule(id, "products", now.Add(time.Duration(i)*time.Second))); err != nil {
+			t.Fatalf("SaveRule(%s) error = %v", id, err)
+		}
+	}
+
+	// The file holds every rule and its two backups the versions before the last two writes
+	for path, want := range map[string]int{
+		filePath:                            4,
+		persistence.BackupPath(filePath, 1): 3,
+		persistence.BackupPath(filePath, 2): 2,
+	} {
+		rules, err := readRulesFile(path)
+		if err != nil || len(rules) != want {
+			t.Errorf("%s holds %d rules (error %v), want %d", filepath.Base(path), len(rules), err, want)
+		}
+	}
+	if _, err := os.Stat(persistence.BackupPath(filePath, 3)); !os.IsNotExist(err) {
+		t.Errorf("Expected no third backup, got error %v", err)
+	}
+
+	// A corrupt file is recovered from the most recent backup
+	if err := os.WriteFile(filePath, []byte(`[{"id": "a",`), 0600); err != nil {
+		t.Fatalf("Failed to corrupt rules file: %v", err)
+	}
+	recovered := NewFileRuleStore(filePath)
+	if err := recovered.Load(); err != nil {
+		t.Fatalf("Load() of a corrupt file error = %v", err)
+	}
+	if got := len(recovered.ListRules("products")); got != 3 {
+		t.Errorf("Load() of a corrupt file recovered %d rules, want 3 from the first backup", got)
+	}
+
+	// So is a file missing after an interrupted write, and a corrupt backup is skipped
+	if err := os.Remove(filePath); err != nil {
+		t.Fatalf("Failed to remove rules file: %v", err)
+	}
+	if err := os.WriteFile(persistence.BackupPath(filePath, 1), []byte("not json"), 0600); err != nil {
+		t.Fatalf("Failed to corrupt backup: %v", err)
+	}
+	recovered = NewFileRuleStore(filePath)
+	if err := recovered.Load(); err != nil {
+		t.Fatalf("Load() of a missing file with backups error = %v", err)
+	}
+	if got := len(recovered.ListRules("products")); got != 2 {
+		t.Errorf("Load() of a missing file recovered %d rules, want 2 from the second backup", got)
+	}
+
+	// Without valid backups, the error of the file is reported
+	if err := os.WriteFile(filePath, []byte("not json"), 0600); err != nil {
+		t.Fatalf("Failed to corrupt rules file: %v", err)
+	}
+	unrecoverable := NewFileRuleStore(filePath)
+	unrecoverable.SetBackups(0)
+	if err := unrecoverable.Load(); err == nil {
+		t.Error("Expected Load() of a corrupt file without backups to fail")
+	}
+}
+
+func TestFileRuleStoreFailedWrite(t *testing.T) {
+	// A file name too long for the temporary file written next to it makes writes fail
+	filePath := filepath.Join(t.TempDir(), strings.Repeat("r", 245)+".json")
+	s := NewFileRuleStore(filePath)
+	s.SetBackups(2)
+	original := []byte(`[{"id": "a", "index_name": "products", "condition": {"query": "shoes"}, "actions": [{"type": "pin", "document_ids": ["doc1"]}]}]`)
+	if err := os.WriteFile(filePath, original, 0600); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+	if err := s.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := s.SaveRule(testRule("b", "products", time.Now())); err == nil {
+		t.Fatal("Expected SaveRule() to fail writing the rules file")
+	}
+	if data, err := os.ReadFile(filePath); err != nil || string(data) != string(original) {
+		t.Errorf("Expected the rules file to be unchanged after a failed write, got %q (error %v)", data, err)
+	}
+	if _, err := os.Stat(persistence.BackupPath(filePath, 1)); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup rotated by a failed write, got error %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// ValidateRule checks that a rule is well formed. It returns a *errors.ValidationError describing
+// the first problem found.
+func ValidateRule(rule model.Rule) error {
+	switch rule.Condition.Match {
+	case "", model.RuleMatchExact, model.RuleMatchContains:
+	default:
+		return errors.NewValidationError("condition.match", fmt.Sprintf("unsupported match type '%s' (expected '%s' or '%s')", rule.Condition.Match, model.RuleMatchExact, model.RuleMatchContains))
+	}
+
+	if len(rule.Actions) == 0 {
+		return errors.NewValidationError("actions", "at least one action is required")
+	}
+
+	if rule.ValidFrom != nil && rule.ValidUntil != nil && !rule.ValidUntil.After(*rule.ValidFrom) {
+		return errors.NewValidationError("valid_until", "must be after valid_from")
+	}
+
+	for i, action := range rule.Actions {
+		if err := validateAction(fmt.Sprintf("actions[%d]", i), action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterOperators are the operators of the conditions add_filter actions and API keys can add. The empty
+// operator is picked from the type of the document field.
+var filterOperators = map[string]struct{}{
+	"": {}, "_exact": {}, "_ne": {}, "_gt": {}, "_gte": {}, "_lt": {}, "_lte": {},
+	"_contains": {}, "_ncontains": {}, "_contains_any_of": {},
+}
+
+// validateAction checks a rule action and the options its type supports.
+func validateAction(field string, action model.RuleAction) error {
+	switch action.Type {
+	case model.RuleActionPin, model.RuleActionHide, model.RuleActionBoost:
+		if len(action.DocumentIDs) == 0 {
+			return errors.NewValidationError(field+".document_ids", "at least one document ID is required")
+		}
+		for _, documentID := range action.DocumentIDs {
+			if strings.TrimSpace(documentID) == "" {
+				return errors.NewValidationError(field+".document_ids", "document IDs cannot be empty")
+			}
+		}
+	case model.RuleActionRewriteQuery, model.RuleActionAddFilter:
+		if len(action.DocumentIDs) > 0 {
+			return errors.NewValidationError(field+".document_ids", fmt.Sprintf("is not supported by %s actions", action.Type))
+		}
+	default:
+		return errors.NewValidationError(field+".type", fmt.Sprintf("unsupported action type '%s' (expected '%s', '%s', '%s', '%s' or '%s')", action.Type, model.RuleActionPin, model.RuleActionHide, model.RuleActionBoost, model.RuleActionRewriteQuery, model.RuleActionAddFilter))
+	}
+
+	if action.Type == model.RuleActionPin {
+		if action.Position < 0 {
+			return errors.NewValidationError(field+".position", "must be a positive position")
+		}
+	} else if action.Position != 0 {
+		return errors.NewValidationError(field+".position", "is only supported by pin actions")
+	}
+
+	if action.Type == model.RuleActionBoost {
+		if action.Multiplier < 0 {
+			return errors.NewValidationError(field+".multiplier", "cannot be negative")
+		}
+		if action.Multiplier == 0 && action.Addend == 0 {
+			return errors.NewValidationError(field, "boost actions must set a multiplier or an addend")
+		}
+	} else if action.Multiplier != 0 || action.Addend != 0 {
+		return errors.NewValidationError(field, "multiplier and addend are only supported by boost actions")
+	}
+
+	if action.Type == model.RuleActionRewriteQuery {
+		if strings.TrimSpace(action.Query) == "" {
+			return errors.NewValidationError(field+".query", "is required by rewrite_query actions")
+		}
+		switch action.Mode {
+		case "", model.RuleRewriteReplace, model.RuleRewriteAppend:
+		default:
+			return errors.NewValidationError(field+".mode", fmt.Sprintf("unsupported rewrite mode '%s' (expected '%s' or '%s')", action.Mode, model.RuleRewriteReplace, model.RuleRewriteAppend))
+		}
+	} else if action.Query != "" || action.Mode != "" {
+		return errors.NewValidationError(field, "query and mode are only supported by rewrite_query actions")
+	}
+
+	if action.Type == model.RuleActionAddFilter {
+		if action.Filter == nil {
+			return errors.NewValidationError(field+".filter", "is required by add_filter actions")
+		}
+		if err := ValidateFilter(field+".filter", *action.Filter); err != nil {
+			return err
+		}
+	} else if action.Filter != nil {
+		return errors.NewValidationError(field+".filter", "is only supported by add_filter actions")
+	}
+	return nil
+}
+
+// ValidateFilter checks a filter condition added to queries, by an add_filter action or an API
+// key. field names the condition in the returned *errors.ValidationError.
+func ValidateFilter(field string, filter model.RuleFilter) error {
+	if strings.TrimSpace(filter.Field) == "" {
+		return errors.NewValidationError(field+".field", "is required")
+	}
+	if _, supported := filterOperators[filter.Operator]; !supported {
+		return errors.NewValidationError(field+".operator", fmt.Sprintf("unsupported filter operator '%s'", filter.Operator))
+	}
+	if filter.Value == nil {
+		return errors.NewValidationError(field+".value", "is required")
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// benchmarkDocuments creates documents sharing a small vocabulary, so searches and writes touch
+// the same posting lists.
+func benchmarkDocuments(prefix string, count int) []model.Document {
+	words := []string{"matrix", "galaxy", "pirate", "wizard", "dragon", "empire", "planet", "legend"}
+	docs := make([]model.Document, count)
+	for i := range docs {
+		docs[i] = model.Document{
+			"documentID":  fmt.Sprintf("%s_%d", prefix, i),
+			"title":       fmt.Sprintf("The %s %s %d", words[i%len(words)], words[(i/len(words))%len(words)], i),
+			"description": fmt.Sprintf("A story about a %s and a %s", words[(i+3)%len(words)], words[(i+5)%len(words)]),
+			"popularity":  float64(i % 100),
+		}
+	}
+	return docs
+}
+
+// BenchmarkSearchDuringIngestion measures search latency with and without documents being indexed
+// at the same time. The term dictionary is sharded and posting lists are replaced rather than
+// modified, so searches should not wait on the writer: run it with -blockprofile to check that
+// searches don't show up, as the latency left between the two cases is the CPU they share.
+func BenchmarkSearchDuringIngestion(b *testing.B) {
+	queries := []string{"matrix", "galaxy pirate", "wizard", "dragon empire", "legend"}
+
+	for _, ingesting := range []bool{false, true} {
+		name := "idle"
+		if ingesting {
+			name = "ingesting"
+		}
+		b.Run(name, func(b *testing.B) {
+			searchService, indexerService := setupTestSearchService(b, nil)
+			if err := indexerService.AddDocuments(benchmarkDocuments("seed", 5000)); err != nil {
+				b.Fatalf("Failed to seed index: %v", err)
+			}
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			if ingesting {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for batch := 0; ; batch++ {
+						select {
+						case <-stop:
+							return
+						default:
+						}
+						if err := indexerService.AddDocuments(benchmarkDocuments(fmt.Sprintf("batch%d", batch), 200)); err != nil {
+							b.Errorf("Failed to add documents: %v", err)
+							return
+						}
+					}
+				}()
+			}
+
+			latencies := make([]time.Duration, 0, b.N)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				if _, err := searchService.Search(services.SearchQuery{QueryString: queries[i%len(queries)], Page: 1, PageSize: 10}); err != nil {
+					b.Fatalf("Search failed: %v", err)
+				}
+				latencies = append(latencies, time.Since(start))
+			}
+			b.StopTimer()
+			close(stop)
+			wg.Wait()
+
+			slices.Sort(latencies)
+			b.ReportMetric(float64(latencies[len(latencies)/2].Microseconds()), "p50-µs")
+			b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-µs")
+		})
+	}
+}
+
+// BenchmarkRankingBySortableField measures searches ranked by popularity, with popularity read from
+// the documents and from the sort keys of a sortable field.
+func BenchmarkRankingBySortableField(b *testing.B) {
+	for _, sortable := range []bool{false, true} {
+		name := "document_values"
+		if sortable {
+			name = "sort_keys"
+		}
+		b.Run(name, func(b *testing.B) {
+			settings := newTestIndexSettings()
+			settings.RankingCriteria = []config.RankingCriterion{{Field: "popularity", Order: "desc"}}
+			if sortable {
+				settings.SortableFields = []string{"popularity"}
+			}
+			searchService, indexerService := setupTestSearchService(b, settings)
+			indexerService.SetSortableFields(settings.SortableFields)
+			if err := indexerService.AddDocuments(benchmarkDocuments("doc", 20000)); err != nil {
+				b.Fatalf("Failed to seed index: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := searchService.Search(services.SearchQuery{QueryString: "matrix", Page: 1, PageSize: 10}); err != nil {
+					b.Fatalf("Search failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDeepPagination measures fetching a deep page of a large result set by page number and
+// by search_after cursor.
+func BenchmarkDeepPagination(b *testing.B) {
+	settings := newTestIndexSettings()
+	settings.RankingCriteria = []config.RankingCriterion{{Field: "popularity", Order: "desc"}}
+	settings.SortableFields = []string{"popularity"}
+	searchService, indexerService := setupTestSearchService(b, settings)
+	indexerService.SetSortableFields(settings.SortableFields)
+	if err := indexerService.AddDocuments(benchmarkDocuments("doc", 20000)); err != nil {
+		b.Fatalf("Failed to seed index: %v", err)
+	}
+	previous, err := searchService.Search(services.SearchQuery{QueryString: "matrix", Page: 49, PageSize: 10})
+	if err != nil || previous.NextCursor == "" {
+		b.Fatalf("Search failed: %v", err)
+	}
+
+	for _, query := range []struct {
+		name  string
+		query services.SearchQuery
+	}{
+		{name: "page", query: services.SearchQuery{QueryString: "matrix", Page: 50, PageSize: 10}},
+		{name: "search_after", query: services.SearchQuery{QueryString: "matrix", PageSize: 10, SearchAfter: previous.NextCursor}},
+	} {
+		b.Run(query.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := searchService.Search(query.query); err != nil {
+					b.Fatalf("Search failed: %v", err)
+				}
+			}
+		})
+	}
+}
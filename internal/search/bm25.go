@@ -4,9 +4,15 @@ import (
 	"math"
 
 	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/model"
 	"github.com/gcbaptista/go-search-engine/store"
 )
 
+const (
+	bm25K1 = 1.2  // Controls term frequency saturation
+	bm25B  = 0.75 // Controls how much effect document length has
+)
+
 // BM25Calculator handles BM25 score calculations
 type BM25Calculator struct {
 	invertedIndex *index.InvertedIndex
@@ -25,7 +31,7 @@ func NewBM25Calculator(invIndex *index.InvertedIndex, docStore *store.DocumentSt
 // IDF = log(N / df) where N = total documents, df = documents containing term
 func (calc *BM25Calculator) calculateIDF(term string) float64 {
 	// Get total number of documents
-	totalDocs := float64(len(calc.documentStore.Docs))
+	totalDocs := float64(calc.documentStore.Len())
 	if totalDocs == 0 {
 		return 0.0
 	}
@@ -42,32 +48,18 @@ func (calc *BM25Calculator) calculateIDF(term string) float64 {
 
 // getDocumentFrequency returns the number of documents that contain the given term
 func (calc *BM25Calculator) getDocumentFrequency(term string) int {
-	postingList, exists := calc.invertedIndex.Index[term]
-	if !exists {
-		return 0
-	}
-
-	// Count unique documents (a term might appear in multiple fields of the same document)
-	uniqueDocs := make(map[uint32]bool)
-	for _, entry := range postingList {
-		uniqueDocs[entry.DocID] = true
-	}
-
-	return len(uniqueDocs)
+	// Maintained by the inverted index, counting documents with the term in several fields once
+	return calc.invertedIndex.DocumentFrequency(term)
 }
 
 // CalculateBM25 calculates BM25 score with document length normalization
 // BM25 = IDF * (tf * (k1 + 1)) / (tf + k1 * (1 - b + b * (|d| / avgdl)))
 func (calc *BM25Calculator) CalculateBM25(term string, docID uint32, termFreq float64, searchableFields []string) float64 {
-	// BM25 parameters
-	k1 := 1.2 // Controls term frequency saturation
-	b := 0.75 // Controls how much effect document length has
-
 	// Calculate IDF
 	idf := calc.calculateIDF(term)
 
 	// Get document and calculate its length
-	doc, exists := calc.documentStore.Docs[docID]
+	doc, exists := calc.documentStore.Get(docID)
 	if !exists {
 		return 0.0
 	}
@@ -77,26 +69,43 @@ func (calc *BM25Calculator) CalculateBM25(term string, docID uint32, termFreq fl
 
 	// Calculate BM25 TF component
 	tf := termFreq
-	bm25TF := (tf * (k1 + 1)) / (tf + k1*(1-b+b*(float64(docLength)/avgDocLength)))
+	bm25TF := (tf * (bm25K1 + 1)) / (tf + bm25K1*(1-bm25B+bm25B*(float64(docLength)/avgDocLength)))
 
 	return idf * bm25TF
 }
 
-// getAverageDocumentLength calculates the average document length across all documents
-// This is used for BM25 calculation
-func (calc *BM25Calculator) getAverageDocumentLength(searchableFields []string) float64 {
-	if len(calc.documentStore.Docs) == 0 {
+// FieldScore calculates the BM25 score of a term in one field of a document, normalizing by the
+// length of that field. Document frequencies and field lengths are maintained by the inverted
+// index, so the score is computed in constant time. The IDF is smoothed, log(1 + (N - df + 0.5) /
+// (df + 0.5)), so terms found in every document still score.
+func (calc *BM25Calculator) FieldScore(term string, docID uint32, field string, termFreq float64) float64 {
+	totalDocs := calc.documentStore.Len()
+	docFreq := calc.invertedIndex.DocumentFrequency(term)
+	if totalDocs == 0 || docFreq == 0 {
 		return 0.0
 	}
+	idf := math.Log(1 + (float64(totalDocs-docFreq)+0.5)/(float64(docFreq)+0.5))
+
+	lengthNorm := 1.0
+	if avgFieldLength := calc.invertedIndex.AverageFieldLength(field, totalDocs); avgFieldLength > 0 {
+		fieldLength := float64(calc.invertedIndex.DocumentFieldLength(docID, field))
+		lengthNorm = 1 - bm25B + bm25B*fieldLength/avgFieldLength
+	}
+	return idf * (termFreq * (bm25K1 + 1)) / (termFreq + bm25K1*lengthNorm)
+}
 
+// getAverageDocumentLength calculates the average document length across all documents
+// This is used for BM25 calculation
+func (calc *BM25Calculator) getAverageDocumentLength(searchableFields []string) float64 {
 	totalLength := 0
 	docCount := 0
 
-	for _, doc := range calc.documentStore.Docs {
+	calc.documentStore.Range(func(_ uint32, doc model.Document) bool {
 		docLength := calc.getDocumentLength(doc, searchableFields)
 		totalLength += docLength
 		docCount++
-	}
+		return true
+	})
 
 	if docCount == 0 {
 		return 0.0
@@ -18,10 +18,7 @@ func TestBM25Calculator(t *testing.T) {
 	}
 
 	// Create inverted index and document store
-	invertedIndex := &index.InvertedIndex{
-		Index:    make(map[string]index.PostingList),
-		Settings: settings,
-	}
+	invertedIndex := index.NewInvertedIndex(settings)
 
 	documentStore := &store.DocumentStore{
 		Docs:                   make(map[uint32]model.Document),
@@ -57,22 +54,22 @@ func TestBM25Calculator(t *testing.T) {
 	documentStore.NextID = uint32(len(docs))
 
 	// Manually add some terms to inverted index for testing
-	invertedIndex.Index["quick"] = index.PostingList{
+	invertedIndex.Set("quick", index.PostingList{
 		{DocID: 0, FieldName: "title", Score: 1.0},       // doc1: "quick" appears once in title
 		{DocID: 2, FieldName: "title", Score: 1.0},       // doc3: "quick" appears once in title
 		{DocID: 2, FieldName: "description", Score: 1.0}, // doc3: "quick" appears once in description
-	}
+	})
 
-	invertedIndex.Index["brown"] = index.PostingList{
+	invertedIndex.Set("brown", index.PostingList{
 		{DocID: 0, FieldName: "title", Score: 1.0}, // doc1: "brown" appears once in title
 		{DocID: 1, FieldName: "title", Score: 1.0}, // doc2: "brown" appears once in title
-	}
+	})
 
-	invertedIndex.Index["fox"] = index.PostingList{
+	invertedIndex.Set("fox", index.PostingList{
 		{DocID: 0, FieldName: "title", Score: 1.0},       // doc1: "fox" appears once in title
 		{DocID: 0, FieldName: "description", Score: 1.0}, // doc1: "fox" appears once in description
 		{DocID: 1, FieldName: "description", Score: 1.0}, // doc2: "fox" appears once in description
-	}
+	})
 
 	// Create BM25 calculator
 	bm25Calc := NewBM25Calculator(invertedIndex, documentStore)
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+	"github.com/gcbaptista/go-search-engine/store"
+)
+
+// searchCursor is the position of a hit in the ranking of a search: the values it was ranked by
+// and its document ID. It is sent to clients as NextCursor, and back as SearchAfter, encoded as
+// base64 JSON.
+type searchCursor struct {
+	Score       float64                `json:"s"`
+	FilterScore float64                `json:"f,omitempty"`
+	Fields      map[string]interface{} `json:"v,omitempty"` // Values of the ranking criteria fields the hit has
+	ID          string                 `json:"id"`
+}
+
+// encodeCursor returns the cursor of a hit, or "" if its values cannot be encoded. The hit must
+// have its whole document.
+func (s *Service) encodeCursor(hit services.HitResult) string {
+	cursor := searchCursor{Score: hit.Score, FilterScore: hit.Info.FilterScore}
+	cursor.ID, _ = hit.Document.GetDocumentID()
+	for _, criterion := range s.settings.RankingCriteria {
+		if criterion.Field == "~score" || criterion.Field == "~filters" {
+			continue
+		}
+		if value, exists := hit.Document[criterion.Field]; exists {
+			if cursor.Fields == nil {
+				cursor.Fields = make(map[string]interface{})
+			}
+			cursor.Fields[criterion.Field] = value
+		}
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the rank item a cursor stands for, to compare hits with.
+func (s *Service) decodeCursor(encoded string) (rankItem, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return rankItem{}, errors.NewInvalidQueryError("search_after is not a valid cursor")
+	}
+	var cursor searchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return rankItem{}, errors.NewInvalidQueryError("search_after is not a valid cursor")
+	}
+
+	item := rankItem{score: cursor.Score, filterScore: cursor.FilterScore, doc: cursor.Fields, id: cursor.ID}
+	if fields := s.documentStore.SortableFields(); len(fields) > 0 {
+		item.keys = make([]store.SortKey, len(fields))
+		for i, field := range fields {
+			if value, exists := cursor.Fields[field]; exists {
+				item.keys[i] = store.NewSortKey(value)
+			}
+		}
+	}
+	return item, nil
+}
+
+// rankItemOf returns the rank item of a hit, with the sort keys of its stored document.
+func (s *Service) rankItemOf(hit services.HitResult) rankItem {
+	item := rankItem{score: hit.Score, filterScore: hit.Info.FilterScore, doc: hit.Document}
+	item.id, _ = hit.Document.GetDocumentID()
+	if internalID, found := s.documentStore.Lookup(item.id); found {
+		item.keys = s.documentStore.SortKeys(internalID)
+	}
+	return item
+}
+
+// cursorPosition returns the position in ranked hits of the first hit after a cursor: the hit
+// following the cursor's document, or, if it is no longer a hit, the first hit ranking after it.
+func (s *Service) cursorPosition(hits []services.HitResult, after rankItem, sortablePositions map[string]int) int {
+	for i, hit := range hits {
+		if id, _ := hit.Document.GetDocumentID(); id == after.id {
+			return i + 1
+		}
+	}
+	for i, hit := range hits {
+		if s.ranksBefore(after, s.rankItemOf(hit), sortablePositions) {
+			return i
+		}
+	}
+	return len(hits)
+}
+
+// selectRanked returns, in ranking order, the first k of positions ranked by before, without
+// sorting all of them: each position is compared with the last of the k kept so far, and most
+// are dropped there.
+func selectRanked(positions []int, k int, before func(a, b int) bool) []int {
+	if len(positions) <= k {
+		sort.Slice(positions, func(i, j int) bool { return before(positions[i], positions[j]) })
+		return positions
+	}
+	top := append([]int(nil), positions[:k]...)
+	sort.Slice(top, func(i, j int) bool { return before(top[i], top[j]) })
+	for _, position := range positions[k:] {
+		if !before(position, top[k-1]) {
+			continue
+		}
+		i := sort.Search(k-1, func(i int) bool { return before(position, top[i]) })
+		copy(top[i+1:], top[i:k-1])
+		top[i] = position
+	}
+	return top
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// queryTermRegex matches the terms of a query string: quoted phrases with their proximity operator
+// as a whole, so a hyphen inside a phrase is not taken for an exclusion, or runs of non-spaces.
+var queryTermRegex = regexp.MustCompile(`"[^"]*"(?:~[^\s"]*)?|\S+`)
+
+// parseExclusions extracts the excluded terms of a query string, written with a leading hyphen as
+// in "matrix -reloaded", and adds them to the query's ExcludeTerms. Hyphens inside words, as in
+// "spider-man", do not exclude anything.
+func parseExclusions(query services.SearchQuery) services.SearchQuery {
+	var excluded []string
+	queryString := queryTermRegex.ReplaceAllStringFunc(query.QueryString, func(term string) string {
+		if len(term) > 1 && term[0] == '-' && term[1] != '-' {
+			excluded = append(excluded, term[1:])
+			return ""
+		}
+		return term
+	})
+	if len(excluded) == 0 {
+		return query
+	}
+
+	query.QueryString = strings.Join(strings.Fields(queryString), " ")
+	query.ExcludeTerms = append(append([]string(nil), query.ExcludeTerms...), excluded...)
+	return query
+}
+
+// excludedDocuments returns the documents containing an excluded term of the query in one of the
+// allowed fields. A term that splits into several words excludes the documents containing all of
+// them. Only whole words are excluded, so "-re" does not remove documents about "reloaded". The
+// caller must hold the inverted index lock.
+func (s *Service) excludedDocuments(query services.SearchQuery, isFieldAllowed func(string) bool) map[uint32]bool {
+	if len(query.ExcludeTerms) == 0 {
+		return nil
+	}
+
+	excluded := make(map[uint32]bool)
+	for _, term := range query.ExcludeTerms {
+		var termDocs map[uint32]bool
+		for _, word := range s.analyzer.TokenizeForFields(term, query.RestrictSearchableFields) {
+			wordDocs := make(map[uint32]bool)
+			postings, _ := s.invertedIndex.Get(word)
+			for _, entry := range postings {
+				if entry.IsFullWord && isFieldAllowed(entry.FieldName) && (termDocs == nil || termDocs[entry.DocID]) {
+					wordDocs[entry.DocID] = true
+				}
+			}
+			termDocs = wordDocs
+			if len(termDocs) == 0 {
+				break
+			}
+		}
+		for docID := range termDocs {
+			excluded[docID] = true
+		}
+	}
+	return excluded
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// validateFacets checks that every facet field is configured as a filterable field.
+func (s *Service) validateFacets(facets []string) error {
+	filterableFields := make(map[string]bool, len(s.settings.FilterableFields))
+	for _, field := range s.settings.FilterableFields {
+		filterableFields[field] = true
+	}
+	for _, field := range facets {
+		if !filterableFields[field] {
+			return errors.NewInvalidQueryError("facet field '%s' is not configured as a filterable field in index settings", field)
+		}
+	}
+	return nil
+}
+
+// computeFacets counts, for each facet field, the hits having each of the field's values. Values
+// are read from the stored documents, so fields left out by RetrievableFields are counted as well.
+// Array fields count a hit once for each distinct element.
+func (s *Service) computeFacets(hits []services.HitResult, facets []string) map[string]map[string]int {
+	counts := make(map[string]map[string]int, len(facets))
+	for _, field := range facets {
+		counts[field] = make(map[string]int)
+	}
+
+	for _, hit := range hits {
+		documentID, _ := hit.Document.GetDocumentID()
+		internalID, found := s.documentStore.Lookup(documentID)
+		if !found {
+			continue
+		}
+		doc, found := s.documentStore.Get(internalID)
+		if !found {
+			continue
+		}
+		for _, field := range facets {
+			for _, value := range facetValues(doc[field]) {
+				counts[field][value]++
+			}
+		}
+	}
+	return counts
+}
+
+// facetValues returns the distinct facet values of a field value: the value itself for scalars,
+// or its elements for arrays. Numbers and booleans are formatted as they appear in JSON.
+func facetValues(fieldValue interface{}) []string {
+	var items []interface{}
+	switch v := fieldValue.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		items = v
+	case []string:
+		return distinctStrings(v)
+	default:
+		items = []interface{}{v}
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if value, ok := facetValue(item); ok {
+			values = append(values, value)
+		}
+	}
+	return distinctStrings(values)
+}
+
+// facetValue formats a scalar field value as a facet value. Nested objects and arrays have none.
+func facetValue(item interface{}) (string, bool) {
+	switch v := item.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// distinctStrings returns the values without duplicates, keeping their first occurrences in order.
+func distinctStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	distinct := values[:0:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			distinct = append(distinct, value)
+		}
+	}
+	return distinct
+}
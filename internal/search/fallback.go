@@ -0,0 +1,114 @@
+package search
+
+import (
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// Typo thresholds used by the relax_typos fallback.
+const (
+	relaxedMinWordSizeFor1Typo  = 3
+	relaxedMinWordSizeFor2Typos = 5
+)
+
+// applyFallbacks tries the index's zero-result fallback strategies in order, each one against the
+// original query, and returns the results of the first strategy that finds hits. The empty result
+// is returned when none does. Only relax_typos keeps the query's phrases: the other strategies
+// loosen which words must match, so requiring the phrases would defeat them. The mode is the one
+// of the query's matching strategy.
+func (s *Service) applyFallbacks(query services.SearchQuery, match ruleMatch, tokens []string, phrases []phrase, queryMode matchMode, empty services.SearchResult, startTime time.Time) (services.SearchResult, error) {
+	for _, strategy := range s.settings.ZeroResultFallbacks {
+		fallbackQuery, fallbackTokens, mode, applicable := s.fallbackQuery(strategy, query, tokens, queryMode)
+		if !applicable {
+			continue
+		}
+		fallbackPhrases := phrases
+		if strategy != config.FallbackRelaxTypos {
+			fallbackPhrases = nil
+		}
+
+		result, err := s.execute(fallbackQuery, match, fallbackTokens, fallbackPhrases, mode, startTime)
+		if err != nil {
+			return services.SearchResult{}, err
+		}
+		if result.Total > 0 {
+			result.FallbackStrategy = strategy
+			return result, nil
+		}
+	}
+
+	empty.Took = time.Since(startTime).Milliseconds()
+	return empty, nil
+}
+
+// fallbackQuery relaxes a query searched in a match mode according to a fallback strategy. It
+// reports false when the strategy cannot change the results of the query.
+func (s *Service) fallbackQuery(strategy config.FallbackStrategy, query services.SearchQuery, tokens []string, mode matchMode) (services.SearchQuery, []string, matchMode, bool) {
+	switch strategy {
+	case config.FallbackRelaxTypos:
+		minWordSizeFor1Typo := s.settings.MinWordSizeFor1Typo
+		if query.MinWordSizeFor1Typo != nil {
+			minWordSizeFor1Typo = *query.MinWordSizeFor1Typo
+		}
+		minWordSizeFor2Typos := s.settings.MinWordSizeFor2Typos
+		if query.MinWordSizeFor2Typos != nil {
+			minWordSizeFor2Typos = *query.MinWordSizeFor2Typos
+		}
+		relaxed1, changed1 := relaxTypoThreshold(minWordSizeFor1Typo, relaxedMinWordSizeFor1Typo)
+		relaxed2, changed2 := relaxTypoThreshold(minWordSizeFor2Typos, relaxedMinWordSizeFor2Typos)
+		if !changed1 && !changed2 {
+			return query, tokens, mode, false
+		}
+		query.MinWordSizeFor1Typo = &relaxed1
+		query.MinWordSizeFor2Typos = &relaxed2
+		return query, tokens, mode, true
+
+	case config.FallbackDropRarestToken:
+		if len(tokens) < 2 {
+			return query, tokens, mode, false
+		}
+		rarest := s.rarestToken(tokens)
+		remaining := make([]string, 0, len(tokens)-1)
+		remaining = append(remaining, tokens[:rarest]...)
+		remaining = append(remaining, tokens[rarest+1:]...)
+		return query, remaining, mode, true
+
+	case config.FallbackMatchAny:
+		return query, tokens, matchAnyToken, len(tokens) > 1 && mode != matchAnyToken
+
+	case config.FallbackBrowse:
+		return query, nil, matchAllDocuments, true
+	}
+	return query, tokens, mode, false
+}
+
+// relaxTypoThreshold lowers a minimum word size for typos to the relaxed one. A threshold of 0
+// disables typos and is relaxed too.
+func relaxTypoThreshold(current, relaxed int) (int, bool) {
+	if current > 0 && current <= relaxed {
+		return current, false
+	}
+	return relaxed, true
+}
+
+// rarestToken returns the position of the token found in the fewest documents. Tokens absent
+// from the index are the rarest.
+func (s *Service) rarestToken(tokens []string) int {
+	s.invertedIndex.Mu.RLock()
+	defer s.invertedIndex.Mu.RUnlock()
+
+	rarest, rarestFrequency := 0, -1
+	for i, token := range tokens {
+		documents := make(map[uint32]struct{})
+		postings, _ := s.invertedIndex.Get(token)
+		for _, entry := range postings {
+			documents[entry.DocID] = struct{}{}
+		}
+		if rarestFrequency < 0 || len(documents) < rarestFrequency {
+			rarest, rarestFrequency = i, len(documents)
+		}
+	}
+	return rarest
+}
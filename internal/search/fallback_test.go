@@ -0,0 +1,59 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestZeroResultFallbacks(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Wireless Headphones", "popularity": 50.0},
+		{"documentID": "2", "title": "Wireless Speaker", "popularity": 90.0},
+		{"documentID": "3", "title": "Leather Wallet", "popularity": 70.0},
+	})
+	// Strict typo thresholds so that short misspellings only match once relaxed
+	s.settings.MinWordSizeFor1Typo = 8
+	s.settings.MinWordSizeFor2Typos = 10
+	s.settings.RankingCriteria = []config.RankingCriterion{{Field: "popularity", Order: "desc"}}
+
+	tests := []struct {
+		name         string
+		fallbacks    []config.FallbackStrategy
+		query        string
+		wantStrategy config.FallbackStrategy
+		wantIDs      []string
+	}{
+		{"no fallbacks", nil, "walet", "", nil},
+		{"relax typos", []config.FallbackStrategy{config.FallbackRelaxTypos}, "walet", config.FallbackRelaxTypos, []string{"3"}},
+		{"drop rarest token", []config.FallbackStrategy{config.FallbackDropRarestToken}, "wireless blender", config.FallbackDropRarestToken, []string{"2", "1"}},
+		{"match any", []config.FallbackStrategy{config.FallbackMatchAny}, "speaker wallet", config.FallbackMatchAny, []string{"2", "3"}},
+		{"browse", []config.FallbackStrategy{config.FallbackBrowse}, "blender", config.FallbackBrowse, []string{"2", "3", "1"}},
+		{"first strategy with hits wins", []config.FallbackStrategy{config.FallbackMatchAny, config.FallbackRelaxTypos, config.FallbackBrowse}, "walet", config.FallbackRelaxTypos, []string{"3"}},
+		{"matching queries skip fallbacks", []config.FallbackStrategy{config.FallbackBrowse}, "wallet", "", []string{"3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.settings.ZeroResultFallbacks = tt.fallbacks
+
+			result, err := s.Search(services.SearchQuery{QueryString: tt.query, PageSize: 10})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if result.FallbackStrategy != tt.wantStrategy {
+				t.Errorf("FallbackStrategy = %q, want %q", result.FallbackStrategy, tt.wantStrategy)
+			}
+			if result.Total != len(tt.wantIDs) {
+				t.Fatalf("Total = %d, want %d", result.Total, len(tt.wantIDs))
+			}
+			for i, hit := range result.Hits {
+				if hit.Document["documentID"] != tt.wantIDs[i] {
+					t.Errorf("hit %d = %v, want %s", i, hit.Document["documentID"], tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/tokenizer"
+)
+
+// decimalCommaLanguages write numbers with a decimal comma and dot or space thousand separators
+// ("1.234,56"); the other languages use a decimal point ("1,234.56").
+var decimalCommaLanguages = map[string]bool{
+	"de": true, "fr": true, "es": true, "it": true, "pt": true, "nl": true, "da": true, "sv": true,
+	"nb": true, "nn": true, "no": true, "fi": true, "pl": true, "cs": true, "sk": true, "ru": true,
+	"uk": true, "tr": true, "el": true, "ro": true, "hu": true, "id": true, "vi": true,
+}
+
+// yearFirstLanguages write dates year first ("2024/12/31"); English in the United States writes
+// them month first, and the other locales day first.
+var yearFirstLanguages = map[string]bool{"ja": true, "zh": true, "ko": true, "hu": true, "lt": true}
+
+// Date layouts of string values by the order of their date elements. Single-digit layout elements
+// also accept zero-padded values.
+var (
+	dayFirstLayouts   = []string{"2/1/2006", "2.1.2006", "2-1-2006"}
+	monthFirstLayouts = []string{"1/2/2006", "1-2-2006"}
+	yearFirstLayouts  = []string{"2006/1/2", "2006.1.2"}
+)
+
+// valueFormat is the way numbers and dates are written in the string values of a filter
+// condition or of the documents' field.
+type valueFormat struct {
+	decimalComma bool
+	dateLayouts  []string // Tried after the ISO 8601 formats every value is read with
+}
+
+// localeValueFormat returns the number and date format of a locale.
+func localeValueFormat(locale string) valueFormat {
+	language := tokenizer.PrimaryLanguage(locale)
+	format := valueFormat{decimalComma: decimalCommaLanguages[language]}
+	switch {
+	case yearFirstLanguages[language]:
+		format.dateLayouts = yearFirstLayouts
+	case language == "en" && isUSLocale(locale):
+		format.dateLayouts = monthFirstLayouts
+	default:
+		format.dateLayouts = dayFirstLayouts
+	}
+	return format
+}
+
+// isUSLocale reports whether an English locale is American English, also assumed without a region.
+func isUSLocale(locale string) bool {
+	_, region, _ := strings.Cut(strings.ToLower(strings.TrimSpace(strings.ReplaceAll(locale, "_", "-"))), "-")
+	return region == "" || region == "us"
+}
+
+// fieldValueFormat returns the format of a field's configured FieldFormat: its locale's format,
+// with the date layout of its date format when set.
+func fieldValueFormat(fieldFormat config.FieldFormat) valueFormat {
+	format := valueFormat{}
+	if fieldFormat.Locale != "" {
+		format = localeValueFormat(fieldFormat.Locale)
+	}
+	if layout := fieldFormat.DateLayout(); layout != "" {
+		format.dateLayouts = []string{layout}
+	}
+	return format
+}
+
+// filterValueFormats returns the formats of a field's document values and of the values of a
+// filter condition on it. Documents are read with the field's configured format; filter values
+// with the query's filter locale, or the field's format without one. A nil format leaves values
+// as they are.
+func (s *Service) filterValueFormats(fieldName, filterLocale string) (docFormat, filterFormat *valueFormat) {
+	if fieldFormat, exists := s.settings.FieldFormats[fieldName]; exists {
+		format := fieldValueFormat(fieldFormat)
+		docFormat, filterFormat = &format, &format
+	}
+	if filterLocale != "" {
+		format := localeValueFormat(filterLocale)
+		filterFormat = &format
+	}
+	return docFormat, filterFormat
+}
+
+// parse reads a string value as a date or a number written in the format. Other values, and
+// strings that are neither, are returned unchanged.
+func (f *valueFormat) parse(value interface{}) interface{} {
+	str, isString := value.(string)
+	if f == nil || !isString {
+		if values, isArray := value.([]interface{}); isArray && f != nil {
+			parsed := make([]interface{}, len(values))
+			for i, item := range values {
+				parsed[i] = f.parse(item)
+			}
+			return parsed
+		}
+		return value
+	}
+	str = strings.TrimSpace(str)
+	if t, ok := convertToTime(str); ok {
+		return t
+	}
+	for _, layout := range f.dateLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t
+		}
+	}
+	if number, ok := f.parseNumber(str); ok {
+		return number
+	}
+	return value
+}
+
+// parseNumber reads a number with the format's decimal separator and optional thousand
+// separators, which must group the integer digits by three.
+func (f *valueFormat) parseNumber(str string) (float64, bool) {
+	decimalSeparator, groupSeparators := ".", ",' \u00a0\u202f"
+	if f.decimalComma {
+		decimalSeparator, groupSeparators = ",", ".' \u00a0\u202f"
+	}
+
+	unsigned := strings.TrimLeft(str, "+-")
+	if len(str)-len(unsigned) > 1 {
+		return 0, false
+	}
+	integer, fraction, hasFraction := strings.Cut(unsigned, decimalSeparator)
+	if hasFraction && !isDigits(fraction) {
+		return 0, false
+	}
+
+	groups := strings.FieldsFunc(integer, func(r rune) bool { return strings.ContainsRune(groupSeparators, r) })
+	if len(groups) == 0 || len(strings.Join(groups, "")) == 0 {
+		return 0, false
+	}
+	if len(groups) > 1 {
+		// Separators must be single and consistent: "1.234.567", not "1.23.4" or "1..234"
+		separator := strings.TrimPrefix(integer, groups[0])[:1]
+		if strings.Join(groups, separator) != integer || len(groups[0]) > 3 {
+			return 0, false
+		}
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return 0, false
+			}
+		}
+	}
+	digits := strings.Join(groups, "")
+	if !isDigits(digits) {
+		return 0, false
+	}
+
+	normalized := str[:len(str)-len(unsigned)] + digits
+	if hasFraction {
+		normalized += "." + fraction
+	}
+	number, err := strconv.ParseFloat(normalized, 64)
+	return number, err == nil
+}
+
+// isDigits reports whether a string is a non-empty run of ASCII digits.
+func isDigits(str string) bool {
+	if str == "" {
+		return false
+	}
+	for _, r := range str {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
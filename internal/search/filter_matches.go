@@ -0,0 +1,57 @@
+package search
+
+import (
+	"strconv"
+
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// addMatchedFilters sets on each hit the conditions and groups of the filter expression its
+// document matched, so clients can explain why a hit ranked as it did. The stored document is
+// evaluated, so fields left out by RetrievableFields are taken into account as well.
+func (s *Service) addMatchedFilters(hits []services.HitResult, expr services.Filters, filterLocale string) {
+	for i := range hits {
+		documentID, _ := hits[i].Document.GetDocumentID()
+		internalID, found := s.documentStore.Lookup(documentID)
+		if !found {
+			continue
+		}
+		doc, found := s.documentStore.Get(internalID)
+		if !found {
+			continue
+		}
+		// Pinned hits may not match the expression at all
+		if matches, _ := s.matchFilters(doc, expr, filterLocale); matches {
+			hits[i].Info.MatchedFilters = s.collectMatchedFilters(doc, expr, filterLocale, "", nil)
+		}
+	}
+}
+
+// collectMatchedFilters appends the conditions and groups of a matching filter expression that the
+// document matched, and those matched within the matched groups. Each is reported by its ID or,
+// without one, by its path from the root expression, e.g. "groups[1].filters[0]".
+func (s *Service) collectMatchedFilters(doc model.Document, expr services.Filters, filterLocale, path string, matched []string) []string {
+	for i, condition := range expr.Filters {
+		if s.evaluateFilterCondition(doc, condition, filterLocale) {
+			matched = append(matched, filterMatchName(condition.ID, path+"filters["+strconv.Itoa(i)+"]"))
+		}
+	}
+	for i, group := range expr.Groups {
+		if matches, _ := s.matchFilters(doc, group, filterLocale); matches {
+			groupPath := path + "groups[" + strconv.Itoa(i) + "]"
+			matched = append(matched, filterMatchName(group.ID, groupPath))
+			matched = s.collectMatchedFilters(doc, group, filterLocale, groupPath+".", matched)
+		}
+	}
+	return matched
+}
+
+// filterMatchName returns how a matched condition or group is reported: its ID if it has one,
+// otherwise its path.
+func filterMatchName(id, path string) string {
+	if id != "" {
+		return id
+	}
+	return path
+}
@@ -1,13 +1,21 @@
 package search
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
 )
 
 // filterDocumentFields returns a new document containing only the specified fields.
-// If retrievableFields are empty, returns the full document.
+// If retrievableFields are empty, the index's default retrievable fields are used, and without
+// those the full document is returned.
 // The documentID field is always included regardless of the retrievableFields parameter.
 func (s *Service) filterDocumentFields(doc model.Document, retrievableFields []string) model.Document {
+	if len(retrievableFields) == 0 {
+		retrievableFields = s.settings.DefaultRetrievableFields
+	}
 	if len(retrievableFields) == 0 {
 		return doc
 	}
@@ -33,3 +41,76 @@ func (s *Service) filterDocumentFields(doc model.Document, retrievableFields []s
 
 	return filteredDoc
 }
+
+// MatchesFilters reports whether a document matches a filter expression, as it would when
+// filtering the hits of a search, so documents can be filtered without being searched.
+func (s *Service) MatchesFilters(doc model.Document, expr services.Filters, filterLocale string) bool {
+	matches, _ := s.evaluateFilters(doc, expr, filterLocale)
+	return matches
+}
+
+// matchingDocuments returns the internal IDs of the documents matching all of the filter
+// conditions, e.g. those of an API key, so reads of the index can be limited to them.
+func (s *Service) matchingDocuments(conditions []model.RuleFilter) map[uint32]struct{} {
+	expr := services.Filters{Operator: "AND"}
+	for _, condition := range conditions {
+		expr.Filters = append(expr.Filters, services.FilterCondition{Field: condition.Field, Operator: condition.Operator, Value: condition.Value})
+	}
+	matching := make(map[uint32]struct{})
+	s.documentStore.Range(func(internalID uint32, doc model.Document) bool {
+		if s.MatchesFilters(doc, expr, "") {
+			matching[internalID] = struct{}{}
+		}
+		return true
+	})
+	return matching
+}
+
+// isMatching reports whether a document is among the matching ones; every document is when
+// matching is nil.
+func isMatching(internalID uint32, matching map[uint32]struct{}) bool {
+	if matching == nil {
+		return true
+	}
+	_, matches := matching[internalID]
+	return matches
+}
+
+// reportFieldMatches limits the matched terms reported for a hit to fieldsToReport, when set, and
+// to maxPerField terms per field, when positive. Exact matches are reported before typo matches.
+// It also returns how many matched terms were left out per field.
+func reportFieldMatches(fieldMatches map[string][]string, fieldsToReport []string, maxPerField int) (map[string][]string, map[string]int) {
+	if len(fieldsToReport) == 0 && maxPerField <= 0 {
+		return fieldMatches, nil
+	}
+
+	reported := make(map[string][]string, len(fieldMatches))
+	var omitted map[string]int
+	for fieldName, terms := range fieldMatches {
+		if len(fieldsToReport) > 0 && !slices.Contains(fieldsToReport, fieldName) {
+			continue
+		}
+		if maxPerField <= 0 || len(terms) <= maxPerField {
+			reported[fieldName] = terms
+			continue
+		}
+
+		ordered := make([]string, 0, len(terms))
+		for _, term := range terms {
+			if !strings.HasSuffix(term, "(typo)") {
+				ordered = append(ordered, term)
+			}
+		}
+		for _, term := range terms {
+			if strings.HasSuffix(term, "(typo)") {
+				ordered = append(ordered, term)
+			}
+		}
+		reported[fieldName] = ordered[:maxPerField]
+		if omitted == nil {
+			omitted = make(map[string]int)
+		}
+		omitted[fieldName] = len(terms) - maxPerField
+	}
+	return reported, omitted
+}
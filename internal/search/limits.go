@@ -0,0 +1,62 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// maxTypoResults is the most typo variants of a query token expanded per typo distance
+const maxTypoResults = 500
+
+// timeLimitCheckInterval is the number of candidates evaluated between checks of the time limit
+const timeLimitCheckInterval = 64
+
+// limitCandidates keeps the MaxCandidates candidates with the lowest internal IDs when a search has
+// more, returning a warning about the candidates left out. Internal IDs grow as documents are
+// added, so the same query keeps the same candidates while the index does not change.
+func limitCandidates(candidateDocIDs map[uint32]bool, limits *config.SearchLimits) *services.SearchWarning {
+	if limits == nil || limits.MaxCandidates <= 0 || len(candidateDocIDs) <= limits.MaxCandidates {
+		return nil
+	}
+	candidates := len(candidateDocIDs)
+	docIDs := make([]uint32, 0, candidates)
+	for docID := range candidateDocIDs {
+		docIDs = append(docIDs, docID)
+	}
+	slices.Sort(docIDs)
+	for _, docID := range docIDs[limits.MaxCandidates:] {
+		delete(candidateDocIDs, docID)
+	}
+	return &services.SearchWarning{
+		Code:       services.SearchWarningCandidatesTruncated,
+		Message:    fmt.Sprintf("only %d of %d candidate documents were evaluated", limits.MaxCandidates, candidates),
+		Limit:      limits.MaxCandidates,
+		Evaluated:  limits.MaxCandidates,
+		Candidates: candidates,
+	}
+}
+
+// timeLimitWarning reports a search that stopped evaluating candidates at its time limit.
+func timeLimitWarning(limits *config.SearchLimits, evaluated, candidates int) services.SearchWarning {
+	return services.SearchWarning{
+		Code:       services.SearchWarningTimeLimitExceeded,
+		Message:    fmt.Sprintf("the time limit of %dms was reached after evaluating %d of %d candidate documents", limits.TimeLimitMs, evaluated, candidates),
+		Limit:      limits.TimeLimitMs,
+		Evaluated:  evaluated,
+		Candidates: candidates,
+	}
+}
+
+// typoExpansionWarning reports a query token with more typo variants in the index than are
+// expanded, so documents matching the variants left out are missing from the hits.
+func typoExpansionWarning(token string) services.SearchWarning {
+	return services.SearchWarning{
+		Code:    services.SearchWarningTypoExpansionTruncated,
+		Message: fmt.Sprintf("only the first %d typo variants of '%s' were matched", maxTypoResults, token),
+		Limit:   maxTypoResults,
+		Token:   token,
+	}
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestSearchLimits(t *testing.T) {
+	var docs []model.Document
+	for i := 1; i <= 5; i++ {
+		docs = append(docs, model.Document{"documentID": fmt.Sprint(i), "title": "Running Shoes"})
+	}
+	s := createTestService(t, docs)
+
+	result, err := s.Search(services.SearchQuery{QueryString: "shoes", PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if result.Total != 5 || len(result.Warnings) != 0 {
+		t.Errorf("Search() without limits = %d hits, warnings %+v; want 5 hits and no warnings", result.Total, result.Warnings)
+	}
+
+	s.settings.SearchLimits = &config.SearchLimits{MaxCandidates: 2, TimeLimitMs: 60000}
+	result, err = s.Search(services.SearchQuery{QueryString: "shoes", PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if result.Total != 2 {
+		t.Fatalf("Total = %d, want the 2 candidates evaluated", result.Total)
+	}
+	for _, hit := range result.Hits {
+		if id := hit.Document["documentID"]; id != "1" && id != "2" {
+			t.Errorf("hit %v, want the documents indexed first", id)
+		}
+	}
+	want := services.SearchWarning{Code: services.SearchWarningCandidatesTruncated, Limit: 2, Evaluated: 2, Candidates: 5}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != want.Code || result.Warnings[0].Limit != want.Limit ||
+		result.Warnings[0].Evaluated != want.Evaluated || result.Warnings[0].Candidates != want.Candidates {
+		t.Errorf("Warnings = %+v, want %+v", result.Warnings, want)
+	}
+}
+
+func TestTypoExpansionWarning(t *testing.T) {
+	// More 1-typo variants of the query word than are expanded: every substitution and insertion
+	const word = "wanderlust"
+	var variants []string
+	for i := 0; i <= len(word); i++ {
+		for c := 'a'; c <= 'z'; c++ {
+			if i < len(word) && rune(word[i]) != c {
+				variants = append(variants, word[:i]+string(c)+word[i+1:])
+			}
+			variants = append(variants, word[:i]+string(c)+word[i:])
+		}
+	}
+	s := createTestService(t, []model.Document{{"documentID": "1", "content": strings.Join(variants, " ")}})
+
+	result, err := s.Search(services.SearchQuery{QueryString: word, PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != services.SearchWarningTypoExpansionTruncated ||
+		result.Warnings[0].Token != word || result.Warnings[0].Limit != maxTypoResults {
+		t.Errorf("Warnings = %+v, want one typo expansion warning for %q", result.Warnings, word)
+	}
+}
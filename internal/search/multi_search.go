@@ -3,17 +3,25 @@ package search
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/gcbaptista/go-search-engine/internal/errors"
 	"github.com/gcbaptista/go-search-engine/services"
 )
 
-// MultiSearch executes multiple named search queries in parallel
+// MultiSearch executes multiple named search queries in parallel. With Deduplicate, each query is
+// run over all of its hits, documents matched by an earlier query are removed, and the remaining
+// hits are paginated.
 func (s *Service) MultiSearch(ctx context.Context, multiQuery services.MultiSearchQuery) (*services.MultiSearchResult, error) {
 	startTime := time.Now()
 
 	if len(multiQuery.Queries) == 0 {
-		return nil, fmt.Errorf("at least one query is required")
+		return nil, errors.NewInvalidQueryError("at least one query is required")
+	}
+	pageSize, err := s.pageSize(multiQuery.PageSize)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create channels for parallel execution
@@ -28,25 +36,53 @@ func (s *Service) MultiSearch(ctx context.Context, multiQuery services.MultiSear
 	// Execute queries in parallel
 	for _, namedQuery := range multiQuery.Queries {
 		if namedQuery.Name == "" {
-			return nil, fmt.Errorf("each query must have a non-empty name")
+			return nil, errors.NewInvalidQueryError("each query must have a non-empty name")
 		}
 
 		// Launch goroutine for each query
 		go func(nq services.NamedSearchQuery) {
 			// Convert NamedSearchQuery to SearchQuery
+			page, queryPageSize := multiQuery.Page, pageSize
+			if multiQuery.Deduplicate {
+				page, queryPageSize = 1, math.MaxInt32 // All hits, paginated once duplicates are removed
+			}
 			searchQuery := services.SearchQuery{
 				QueryString:              nq.Query,
 				RestrictSearchableFields: nq.RestrictSearchableFields,
+				ExcludeSearchableFields:  nq.ExcludeSearchableFields,
+				ExcludeTerms:             nq.ExcludeTerms,
+				MatchingStrategy:         nq.MatchingStrategy,
+				PrefixLast:               nq.PrefixLast,
+				RequireSameField:         nq.RequireSameField,
 				RetrievableFields:        nq.RetrievableFields,
 				Filters:                  nq.Filters,
-				Page:                     multiQuery.Page,
-				PageSize:                 multiQuery.PageSize,
+				Page:                     page,
+				PageSize:                 queryPageSize,
 				MinWordSizeFor1Typo:      nq.MinWordSizeFor1Typo,
 				MinWordSizeFor2Typos:     nq.MinWordSizeFor2Typos,
+				Tokens:                   nq.Tokens,
+				NormalizedPreview:        nq.NormalizedPreview,
+				MaxMatchesPerField:       nq.MaxMatchesPerField,
+				FieldsToReport:           nq.FieldsToReport,
+				Facets:                   nq.Facets,
+				FieldWeights:             nq.FieldWeights,
+				QueryTokenDecay:          nq.QueryTokenDecay,
+				Boosts:                   nq.Boosts,
+				FilterLocale:             nq.FilterLocale,
+				Sample:                   nq.Sample,
+				Suggest:                  nq.Suggest,
+				ExplainFilters:           nq.ExplainFilters,
+				IDsOnly:                  nq.IDsOnly && !multiQuery.Deduplicate, // Deduplication needs the hits' documents
 			}
 
-			// Execute the search
-			result, err := s.Search(searchQuery)
+			// Execute the search; the page size has already been checked
+			searchQuery.RequestID = multiQuery.RequestID
+			searchQuery.Context = ctx
+			queryStart := time.Now()
+			result, err := s.search(searchQuery)
+			if err == nil {
+				s.logSlowQuery(searchQuery, result, time.Since(queryStart))
+			}
 
 			// Send result to channel
 			resultChan <- queryResult{
@@ -65,12 +101,24 @@ func (s *Service) MultiSearch(ctx context.Context, multiQuery services.MultiSear
 			if qr.err != nil {
 				return nil, fmt.Errorf("error executing query '%s': %w", qr.name, qr.err)
 			}
+			qr.result.NextCursor = "" // Named queries are paginated by page only
 			results[qr.name] = qr.result
 		case <-ctx.Done():
 			return nil, fmt.Errorf("multi-search cancelled: %w", ctx.Err())
 		}
 	}
 
+	if multiQuery.Deduplicate {
+		deduplicateResults(multiQuery, pageSize, results)
+		for _, namedQuery := range multiQuery.Queries {
+			if result := results[namedQuery.Name]; namedQuery.IDsOnly {
+				result.HitRefs = s.hitRefs(result.Hits)
+				result.Hits = []services.HitResult{}
+				results[namedQuery.Name] = result
+			}
+		}
+	}
+
 	processingTime := time.Since(startTime)
 
 	return &services.MultiSearchResult{
@@ -79,3 +127,34 @@ func (s *Service) MultiSearch(ctx context.Context, multiQuery services.MultiSear
 		ProcessingTimeMs: float64(processingTime.Nanoseconds()) / 1e6,
 	}, nil
 }
+
+// deduplicateResults removes from each query's hits the documents matched by an earlier query, in
+// request order, then paginates the hits. The results must hold all hits of every query.
+func deduplicateResults(multiQuery services.MultiSearchQuery, pageSize int, results map[string]services.SearchResult) {
+	page := multiQuery.Page
+	if page <= 0 {
+		page = 1
+	}
+	seen := make(map[string]struct{})
+	for _, namedQuery := range multiQuery.Queries {
+		result := results[namedQuery.Name]
+		kept := make([]services.HitResult, 0, len(result.Hits))
+		for _, hit := range result.Hits {
+			if documentID, ok := hit.Document.GetDocumentID(); ok {
+				if _, duplicate := seen[documentID]; duplicate {
+					result.DuplicatesRemoved++
+					continue
+				}
+				seen[documentID] = struct{}{}
+			}
+			kept = append(kept, hit)
+		}
+
+		result.Total = len(kept)
+		result.Page = page
+		result.PageSize = pageSize
+		start := min((page-1)*pageSize, len(kept))
+		result.Hits = kept[start:min(start+pageSize, len(kept))]
+		results[namedQuery.Name] = result
+	}
+}
@@ -0,0 +1,148 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// phraseRegex matches a quoted phrase, optionally followed by a proximity operator: "word word"~N.
+// The operator takes anything up to the next space or quote, so a malformed distance is rejected
+// rather than left in the query as a word.
+var phraseRegex = regexp.MustCompile(`"([^"]*)"(~[^\s"]*)?`)
+
+// phrase is a quoted part of a query. Its words must appear in the same field, in query order,
+// each at most maxDistance positions after the previous one. Exact phrases have a maxDistance of 1.
+type phrase struct {
+	words       []string
+	maxDistance int
+}
+
+// parsePhrases extracts the quoted phrases of a query string. The returned query string keeps the
+// words of the phrases without quotes and proximity operators, so they are searched as regular
+// tokens as well and only the documents matching all of them are checked for the phrases. A
+// proximity distance that is not a non-negative integer is a validation error.
+func (s *Service) parsePhrases(query services.SearchQuery) (string, []phrase, error) {
+	var phrases []phrase
+	var err error
+	queryString := phraseRegex.ReplaceAllStringFunc(query.QueryString, func(match string) string {
+		groups := phraseRegex.FindStringSubmatch(match)
+		maxDistance := 1
+		if groups[2] != "" {
+			distance, convErr := strconv.Atoi(groups[2][1:])
+			if convErr != nil || distance < 0 {
+				if err == nil {
+					err = errors.NewValidationError("query", fmt.Sprintf("invalid proximity distance '%s' in %s (expected a non-negative integer)", groups[2][1:], match))
+				}
+				return match
+			}
+			if distance > 1 {
+				maxDistance = distance
+			}
+		}
+		if words := s.analyzer.PhraseWords(groups[1], query.RestrictSearchableFields); len(words) > 0 {
+			phrases = append(phrases, phrase{words: words, maxDistance: maxDistance})
+		}
+		return " " + groups[1] + " "
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return queryString, phrases, nil
+}
+
+// phraseMatcher checks candidate documents against the phrases of a query, using the word
+// positions stored in the postings of the phrase words.
+type phraseMatcher struct {
+	phrases   []phrase
+	positions map[string]map[uint32]map[string][]int // Word -> document -> field -> positions
+}
+
+// newPhraseMatcher collects the positions of the phrase words in the allowed fields. The caller
+// must hold the inverted index lock.
+func (s *Service) newPhraseMatcher(phrases []phrase, isFieldAllowed func(string) bool) *phraseMatcher {
+	matcher := &phraseMatcher{
+		phrases:   phrases,
+		positions: make(map[string]map[uint32]map[string][]int),
+	}
+	for _, p := range phrases {
+		for _, word := range p.words {
+			if _, collected := matcher.positions[word]; collected {
+				continue
+			}
+			positionsByDoc := make(map[uint32]map[string][]int)
+			postings, _ := s.invertedIndex.Get(word)
+			for _, entry := range postings {
+				if !entry.IsFullWord || !isFieldAllowed(entry.FieldName) {
+					continue
+				}
+				positions := entry.Positions
+				if len(positions) == 0 {
+					// Postings indexed before positions were stored; read them from the document
+					positions = s.storedWordPositions(entry.DocID, entry.FieldName, word)
+				}
+				if positionsByDoc[entry.DocID] == nil {
+					positionsByDoc[entry.DocID] = make(map[string][]int)
+				}
+				positionsByDoc[entry.DocID][entry.FieldName] = positions
+			}
+			matcher.positions[word] = positionsByDoc
+		}
+	}
+	return matcher
+}
+
+// storedWordPositions returns the positions of a word in a field of a stored document.
+func (s *Service) storedWordPositions(docID uint32, fieldName, word string) []int {
+	doc, found := s.documentStore.Get(docID)
+	if !found {
+		return nil
+	}
+	return s.analyzer.FieldWordPositions(fieldText(doc[fieldName]), fieldName)[word]
+}
+
+// matches reports whether a document contains all phrases of the query.
+func (m *phraseMatcher) matches(docID uint32) bool {
+	for _, p := range m.phrases {
+		if !m.matchesPhrase(docID, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesPhrase reports whether a field of a document contains the words of a phrase in order,
+// each within the phrase's maximum distance of the previous one.
+func (m *phraseMatcher) matchesPhrase(docID uint32, p phrase) bool {
+	for fieldName, starts := range m.positions[p.words[0]][docID] {
+		reachable := starts
+		for _, word := range p.words[1:] {
+			reachable = followingPositions(reachable, m.positions[word][docID][fieldName], p.maxDistance)
+			if len(reachable) == 0 {
+				break
+			}
+		}
+		if len(reachable) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// followingPositions returns the candidate positions that come after one of the previous positions,
+// at most maxDistance positions later.
+func followingPositions(previous, candidates []int, maxDistance int) []int {
+	var reachable []int
+	for _, position := range candidates {
+		for _, previousPosition := range previous {
+			if previousPosition < position && position-previousPosition <= maxDistance {
+				reachable = append(reachable, position)
+				break
+			}
+		}
+	}
+	return reachable
+}
@@ -0,0 +1,44 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// lastTokenPrefix returns the query token searched as a prefix when the query sets PrefixLast: its
+// last token, unless the query string ends with a space because the user finished typing the word.
+func lastTokenPrefix(query services.SearchQuery, tokens []string) string {
+	if !query.PrefixLast || len(tokens) == 0 {
+		return ""
+	}
+	if len(query.Tokens) == 0 && strings.TrimRightFunc(query.QueryString, unicode.IsSpace) != query.QueryString {
+		return ""
+	}
+	return tokens[len(tokens)-1]
+}
+
+// prefixWordPostings returns the postings of the whole words starting with a token in the allowed
+// fields without prefix n-grams. Fields with n-grams already match the token through its n-gram
+// posting. The caller must hold the inverted index lock.
+func (s *Service) prefixWordPostings(token string, isFieldAllowed func(string) bool) []index.PostingEntry {
+	if len(s.settings.FieldsWithoutPrefixSearch) == 0 {
+		return nil
+	}
+
+	var postings []index.PostingEntry
+	s.invertedIndex.Range(func(term string, termPostings index.PostingList) bool {
+		if len(term) <= len(token) || !strings.HasPrefix(term, token) {
+			return true
+		}
+		for _, entry := range termPostings {
+			if entry.IsFullWord && isFieldAllowed(entry.FieldName) && !s.analyzer.HasPrefixNGrams(entry.FieldName) {
+				postings = append(postings, entry)
+			}
+		}
+		return true
+	})
+	return postings
+}
@@ -0,0 +1,226 @@
+package search
+
+import (
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/store"
+)
+
+// rankItem is what ranking compares of a hit: its scores, its document, the sort keys of its
+// sortable fields and its document ID.
+type rankItem struct {
+	score       float64
+	filterScore float64
+	doc         model.Document
+	keys        []store.SortKey // In the order of the store's sortable fields; nil when there are none
+	id          string
+}
+
+// ranksBefore reports whether itemI ranks before itemJ: by the index's ranking criteria, then by
+// search score, then by document ID, so that hits have a single order and a search cursor can
+// resume it.
+func (s *Service) ranksBefore(itemI, itemJ rankItem, sortablePositions map[string]int) bool {
+	docI := itemI.doc
+	docJ := itemJ.doc
+	keysI, keysJ := itemI.keys, itemJ.keys
+
+	// Apply ranking criteria first
+	for _, criterion := range s.settings.RankingCriteria {
+		// Special case: ~score means use the calculated search relevance score
+		if criterion.Field == "~score" {
+			if itemI.score != itemJ.score {
+				if criterion.Order == "asc" {
+					return itemI.score < itemJ.score
+				} else {
+					return itemI.score > itemJ.score
+				}
+			}
+			continue // If scores are equal, continue to next criterion
+		}
+
+		// Special case: ~filters means use the filter matching score
+		if criterion.Field == "~filters" {
+			filterScoreI := itemI.filterScore
+			filterScoreJ := itemJ.filterScore
+			if filterScoreI != filterScoreJ {
+				if criterion.Order == "asc" {
+					return filterScoreI < filterScoreJ
+				} else {
+					return filterScoreI > filterScoreJ
+				}
+			}
+			continue // If filter scores are equal, continue to next criterion
+		}
+
+		// Arrays of numbers rank by the value their selector picks
+		if criterion.Selector != "" {
+			valI, okI := selectRankingValue(docI[criterion.Field], criterion.Selector)
+			valJ, okJ := selectRankingValue(docJ[criterion.Field], criterion.Selector)
+			if !okI && !okJ {
+				continue
+			}
+			if okI && !okJ {
+				return criterion.Order != "asc"
+			}
+			if !okI && okJ {
+				return criterion.Order == "asc"
+			}
+			if valI != valJ {
+				if criterion.Order == "asc" {
+					return valI < valJ
+				}
+				return valI > valJ
+			}
+			continue
+		}
+
+		if position, sortable := sortablePositions[criterion.Field]; sortable && position < len(keysI) && position < len(keysJ) {
+			keyI, keyJ := keysI[position], keysJ[position]
+			missingI, missingJ := keyI.Kind == store.SortKeyMissing, keyJ.Kind == store.SortKeyMissing
+			if missingI && missingJ {
+				continue
+			}
+			if !missingI && missingJ {
+				return criterion.Order != "asc"
+			}
+			if missingI && !missingJ {
+				return criterion.Order == "asc"
+			}
+			if c := keyI.Compare(keyJ); c != 0 {
+				if criterion.Order == "asc" {
+					return c < 0
+				}
+				return c > 0
+			}
+			continue
+		}
+
+		valI, okI := docI[criterion.Field]
+		valJ, okJ := docJ[criterion.Field]
+
+		if !okI && !okJ {
+			continue
+		}
+		if okI && !okJ {
+			return criterion.Order != "asc"
+		}
+		if !okI && okJ {
+			return criterion.Order == "asc"
+		}
+
+		switch vI := valI.(type) {
+		case string:
+			if vJ, ok := valJ.(string); ok {
+				if vI != vJ {
+					if criterion.Order == "asc" {
+						return vI < vJ
+					} else {
+						return vI > vJ
+					}
+				}
+			}
+		case float64:
+			if vJ, ok := valJ.(float64); ok {
+				if vI != vJ {
+					if criterion.Order == "asc" {
+						return vI < vJ
+					} else {
+						return vI > vJ
+					}
+				}
+			}
+		case int, int8, int16, int32, int64:
+			fI, _ := convertToFloat64(vI)
+			fJ, _ := convertToFloat64(valJ)
+			if fI != fJ {
+				if criterion.Order == "asc" {
+					return fI < fJ
+				} else {
+					return fI > fJ
+				}
+			}
+		case time.Time:
+			if vJ, ok := valJ.(time.Time); ok {
+				if !vI.Equal(vJ) {
+					if criterion.Order == "asc" {
+						return vI.Before(vJ)
+					} else {
+						return vI.After(vJ)
+					}
+				}
+			}
+		default:
+			if strI, isStrI := valI.(string); isStrI {
+				if strJ, isStrJ := valJ.(string); isStrJ {
+					if criterion.Field == "ReleaseDate" { // Example specific field handling
+						tI, errI := time.Parse(time.RFC3339Nano, strI)
+						if errI != nil {
+							tI, _ = time.Parse(time.RFC3339, strI)
+						}
+						tJ, errJ := time.Parse(time.RFC3339Nano, strJ)
+						if errJ != nil {
+							tJ, _ = time.Parse(time.RFC3339, strJ)
+						}
+						if tI.IsZero() || tJ.IsZero() {
+							continue
+						}
+						if !tI.Equal(tJ) {
+							if criterion.Order == "asc" {
+								return tI.Before(tJ)
+							} else {
+								return tI.After(tJ)
+							}
+						}
+					}
+				}
+			}
+			continue
+		}
+	}
+
+	// Fallback: if no ranking criteria resolved the comparison, sort by search score descending
+	if itemI.score != itemJ.score {
+		return itemI.score > itemJ.score
+	}
+
+	return itemI.id < itemJ.id
+}
+
+// selectRankingValue returns the number a ranking selector picks from a field value: an array of
+// numbers, or a single number. It returns false when the value has no numbers.
+func selectRankingValue(value interface{}, selector config.RankingSelector) (float64, bool) {
+	values, isArray := value.([]interface{})
+	if !isArray {
+		values = []interface{}{value}
+	}
+	selected, count := 0.0, 0
+	for _, v := range values {
+		if _, isString := v.(string); isString {
+			continue
+		}
+		number, ok := convertToFloat64(v)
+		if !ok {
+			continue
+		}
+		switch {
+		case count == 0:
+			selected = number
+		case selector == config.RankingSelectorMin:
+			selected = min(selected, number)
+		case selector == config.RankingSelectorMax:
+			selected = max(selected, number)
+		default:
+			selected += number
+		}
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	if selector == config.RankingSelectorAvg {
+		selected /= float64(count)
+	}
+	return selected, true
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/rewrite"
+	"github.com/gcbaptista/go-search-engine/internal/typoutil"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// settingsRewriters returns the built-in rewriters enabled by the settings of an index, in the
+// order they run: synonyms, stop words and spell correction. The analyzer already drops stop words
+// from queries, so the stop-words rewriter only removes the ones brought back by synonyms.
+func settingsRewriters(settings *config.IndexSettings) []services.QueryRewriter {
+	var rewriters []services.QueryRewriter
+	if len(settings.Synonyms) > 0 {
+		rewriters = append(rewriters, rewrite.NewSynonymRewriter(settings.Synonyms))
+		if settings.StopWords != nil {
+			rewriters = append(rewriters, rewrite.NewStopWordsRewriter(settings.StopWords.List()))
+		}
+	}
+	if correction := settings.SpellCorrection; correction != nil {
+		rewriters = append(rewriters, rewrite.NewSpellCorrectionRewriter(correction.Distance(), correction.WordLength()))
+	}
+	return rewriters
+}
+
+// SetQueryRewriters replaces the query rewriters applied before every search.
+func (s *Service) SetQueryRewriters(rewriters []services.QueryRewriter) {
+	s.extensionsMu.Lock()
+	defer s.extensionsMu.Unlock()
+	s.rewriters = append([]services.QueryRewriter(nil), rewriters...)
+}
+
+// rewriteQuery tokenizes the query and runs it through the rewriters enabled by the settings, then
+// the registered ones. When a rewriter changes the tokens, the query string is rebuilt from them.
+func (s *Service) rewriteQuery(query services.SearchQuery) (services.SearchQuery, []string, error) {
+	tokens := s.analyzer.TokenizeForFields(query.QueryString, query.RestrictSearchableFields)
+
+	s.extensionsMu.RLock()
+	rewriters := slices.Concat(s.settingsRewriters, s.rewriters)
+	s.extensionsMu.RUnlock()
+
+	if len(rewriters) == 0 {
+		return query, tokens, nil
+	}
+
+	ctx := services.RewriteContext{
+		IndexName:  s.settings.Name,
+		Settings:   *s.settings,
+		Vocabulary: s,
+	}
+	parsed := services.ParsedQuery{Query: query, Tokens: tokens}
+	for _, rewriter := range rewriters {
+		before := strings.Join(parsed.Tokens, " ")
+		rewritten, err := rewriter.Rewrite(ctx, parsed)
+		if err != nil {
+			return query, nil, fmt.Errorf("query rewriter '%s' failed: %w", rewriter.Name(), err)
+		}
+		if after := strings.Join(rewritten.Tokens, " "); after != before {
+			rewritten.Query.QueryString = after
+		}
+		parsed = rewritten
+	}
+	return parsed.Query, parsed.Tokens, nil
+}
+
+// QueryTokens returns the tokens searched for a query string in the given fields, after analysis
+// and query rewriting.
+func (s *Service) QueryTokens(queryString string, fieldNames []string) ([]string, error) {
+	_, tokens, err := s.rewriteQuery(services.SearchQuery{QueryString: queryString, RestrictSearchableFields: fieldNames})
+	return tokens, err
+}
+
+// HasTerm reports whether the term is present in the inverted index.
+// This satisfies the services.Vocabulary interface.
+func (s *Service) HasTerm(term string) bool {
+	s.invertedIndex.Mu.RLock()
+	defer s.invertedIndex.Mu.RUnlock()
+	_, exists := s.invertedIndex.Get(term)
+	return exists
+}
+
+// ClosestTerm returns the indexed term closest to term, preferring the smallest edit
+// distance and then the longest posting list.
+// This satisfies the services.Vocabulary interface.
+func (s *Service) ClosestTerm(term string, maxDistance int) (string, bool) {
+	s.invertedIndex.Mu.RLock()
+	defer s.invertedIndex.Mu.RUnlock()
+
+	bestTerm := ""
+	bestDistance := maxDistance + 1
+	bestFrequency := 0
+	for _, candidate := range s.invertedIndex.Typos(term, maxDistance, 500) {
+		postings, exists := s.invertedIndex.Get(candidate)
+		if !exists {
+			continue
+		}
+		distance := typoutil.CalculateEditDistance(term, candidate, maxDistance)
+		if distance < bestDistance || (distance == bestDistance && len(postings) > bestFrequency) {
+			bestTerm = candidate
+			bestDistance = distance
+			bestFrequency = len(postings)
+		}
+	}
+	return bestTerm, bestTerm != ""
+}
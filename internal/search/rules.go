@@ -0,0 +1,81 @@
+package search
+
+import (
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/rules"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SetRuleStore sets the store the index's merchandising rules are read from. A nil store disables rules.
+func (s *Service) SetRuleStore(ruleStore services.RuleStore) {
+	s.extensionsMu.Lock()
+	defer s.extensionsMu.Unlock()
+	s.ruleStore = ruleStore
+}
+
+// ruleMatch holds the rules whose condition matches a query, with the query and filter rewrites
+// they already applied to it.
+type ruleMatch struct {
+	rules   []model.Rule
+	applied []services.AppliedRule
+}
+
+// matchingRules returns the index rules that are active now and whose condition matches the query
+// string.
+func (s *Service) matchingRules(queryString string) []model.Rule {
+	s.extensionsMu.RLock()
+	ruleStore := s.ruleStore
+	s.extensionsMu.RUnlock()
+	if ruleStore == nil {
+		return nil
+	}
+
+	indexRules := ruleStore.ListRules(s.settings.Name)
+	if len(indexRules) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	queryTokens := s.analyzer.Tokenize(queryString)
+	var matched []model.Rule
+	for _, rule := range indexRules {
+		if rules.Active(rule, now) && rules.Matches(rule, queryTokens, s.analyzer.Tokenize) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// applyRules applies the pin and hide actions of the matching rules to the ranked hits. Their
+// boost actions are applied to the scores before ranking, by rules.Boost, and their query actions
+// before the query runs, by rules.Rewrite and rules.AddFilters.
+func (s *Service) applyRules(matched []model.Rule, query services.SearchQuery, hits []services.HitResult) ([]services.HitResult, []services.AppliedRule) {
+	if len(matched) == 0 {
+		return hits, nil
+	}
+
+	// Pinned documents that did not match the query are still subject to the query filters
+	lookup := func(documentID string) (services.HitResult, bool) {
+		internalID, exists := s.documentStore.Lookup(documentID)
+		if !exists {
+			return services.HitResult{}, false
+		}
+		doc, exists := s.documentStore.Get(internalID)
+		if !exists {
+			return services.HitResult{}, false
+		}
+		if query.Filters != nil {
+			if matches, _ := s.evaluateFilters(doc, *query.Filters, query.FilterLocale); !matches {
+				return services.HitResult{}, false
+			}
+		}
+		return services.HitResult{
+			Document:     doc,
+			FieldMatches: map[string][]string{},
+		}, true
+	}
+
+	return rules.Apply(matched, hits, lookup)
+}
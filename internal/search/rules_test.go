@@ -0,0 +1,218 @@
+package search
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/rules"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestSearchWithRules(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Trail Running Shoes", "year": 2021},
+		{"documentID": "2", "title": "Road Running Shoes", "year": 2022},
+		{"documentID": "3", "title": "Leather Boots", "year": 2020},
+		{"documentID": "4", "title": "Worn Running Shoes", "year": 2019},
+	})
+
+	ruleStore := rules.NewFileRuleStore(filepath.Join(t.TempDir(), "rules.json"))
+	if err := ruleStore.SaveRule(model.Rule{
+		ID:        "promo",
+		IndexName: "test_multi_search",
+		Condition: model.RuleCondition{Query: "running shoes"},
+		Actions: []model.RuleAction{
+			{Type: model.RuleActionPin, DocumentIDs: []string{"3"}},
+			{Type: model.RuleActionHide, DocumentIDs: []string{"4"}},
+		},
+		CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("SaveRule() error = %v", err)
+	}
+	s.SetRuleStore(ruleStore)
+
+	result, err := s.Search(services.SearchQuery{QueryString: "Running Shoes", PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if result.Total != 3 {
+		t.Errorf("Total = %d, want 3", result.Total)
+	}
+	if len(result.Hits) == 0 || result.Hits[0].Document["documentID"] != "3" {
+		t.Errorf("first hit = %v, want pinned document 3", result.Hits)
+	}
+	for _, hit := range result.Hits {
+		if hit.Document["documentID"] == "4" {
+			t.Error("hidden document 4 should not be returned")
+		}
+	}
+	wantApplied := []services.AppliedRule{
+		{RuleID: "promo", Action: model.RuleActionHide, DocumentIDs: []string{"4"}},
+		{RuleID: "promo", Action: model.RuleActionPin, DocumentIDs: []string{"3"}},
+	}
+	if !reflect.DeepEqual(result.AppliedRules, wantApplied) {
+		t.Errorf("AppliedRules = %+v, want %+v", result.AppliedRules, wantApplied)
+	}
+
+	// Pinned documents must still pass the query filters
+	filtered, err := s.Search(services.SearchQuery{
+		QueryString: "running shoes",
+		PageSize:    10,
+		Filters: &services.Filters{
+			Operator: "AND",
+			Filters:  []services.FilterCondition{{Field: "year", Operator: "_gte", Value: 2021}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Search() with filters error = %v", err)
+	}
+	for _, hit := range filtered.Hits {
+		if hit.Document["documentID"] == "3" {
+			t.Error("pinned document 3 should be excluded by the year filter")
+		}
+	}
+
+	// Queries the rule does not match are left alone
+	other, err := s.Search(services.SearchQuery{QueryString: "boots", PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(other.AppliedRules) != 0 {
+		t.Errorf("AppliedRules = %+v, want none", other.AppliedRules)
+	}
+}
+
+func TestSearchWithBoostRules(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Running Shoes"},
+		{"documentID": "2", "title": "Trail Running Shoes for Mountains"},
+		{"documentID": "3", "title": "Leather Boots"},
+	})
+
+	ruleStore := rules.NewFileRuleStore(filepath.Join(t.TempDir(), "rules.json"))
+	if err := ruleStore.SaveRule(model.Rule{
+		ID:        "trail",
+		IndexName: "test_multi_search",
+		Condition: model.RuleCondition{Query: "running shoes"},
+		Actions: []model.RuleAction{
+			{Type: model.RuleActionBoost, DocumentIDs: []string{"2", "3"}, Multiplier: 2, Addend: 1000},
+		},
+		CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("SaveRule() error = %v", err)
+	}
+	s.SetRuleStore(ruleStore)
+
+	result, err := s.Search(services.SearchQuery{QueryString: "running shoes", PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	var gotIDs []string
+	for _, hit := range result.Hits {
+		gotIDs = append(gotIDs, hit.Document["documentID"].(string))
+	}
+	// Document 3 does not match the query, so the boost cannot add it
+	if want := []string{"2", "1"}; !reflect.DeepEqual(gotIDs, want) {
+		t.Errorf("hit order = %v, want %v", gotIDs, want)
+	}
+	wantApplied := []services.AppliedRule{
+		{RuleID: "trail", Action: model.RuleActionBoost, DocumentIDs: []string{"2"}},
+	}
+	if !reflect.DeepEqual(result.AppliedRules, wantApplied) {
+		t.Errorf("AppliedRules = %+v, want %+v", result.AppliedRules, wantApplied)
+	}
+}
+
+func TestSearchWithQueryRules(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Children Movie", "category": "PG", "year": 2021},
+		{"documentID": "2", "title": "Children Documentary", "category": "R", "year": 2022},
+		{"documentID": "3", "title": "Children Classic", "category": "PG", "year": 1999},
+		{"documentID": "4", "title": "Kids Movie", "category": "PG", "year": 2020},
+	})
+
+	ruleStore := rules.NewFileRuleStore(filepath.Join(t.TempDir(), "rules.json"))
+	if err := ruleStore.SaveRule(model.Rule{
+		ID:        "kids",
+		IndexName: "test_multi_search",
+		Condition: model.RuleCondition{Query: "kids", Match: model.RuleMatchContains},
+		Actions: []model.RuleAction{
+			{Type: model.RuleActionRewriteQuery, Query: "children"},
+			{Type: model.RuleActionAddFilter, Filter: &model.RuleFilter{Field: "category", Value: "PG"}},
+		},
+		CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("SaveRule() error = %v", err)
+	}
+	s.SetRuleStore(ruleStore)
+
+	result, err := s.Search(services.SearchQuery{
+		QueryString: "kids",
+		PageSize:    10,
+		Filters: &services.Filters{
+			Operator: "AND",
+			Filters:  []services.FilterCondition{{Field: "year", Operator: "_gte", Value: 2000}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	var gotIDs []string
+	for _, hit := range result.Hits {
+		gotIDs = append(gotIDs, hit.Document["documentID"].(string))
+	}
+	// The rewritten query does not find document 4, the added filter drops document 2 and the
+	// query's own filter, which still applies, drops document 3
+	if want := []string{"1"}; !reflect.DeepEqual(gotIDs, want) {
+		t.Errorf("hits = %v, want %v", gotIDs, want)
+	}
+	wantApplied := []services.AppliedRule{
+		{RuleID: "kids", Action: model.RuleActionRewriteQuery, Query: "children"},
+		{RuleID: "kids", Action: model.RuleActionAddFilter},
+	}
+	if !reflect.DeepEqual(result.AppliedRules, wantApplied) {
+		t.Errorf("AppliedRules = %+v, want %+v", result.AppliedRules, wantApplied)
+	}
+}
+
+func TestSearchWithScheduledRules(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Holiday Movie"},
+		{"documentID": "2", "title": "Christmas Movie"},
+		{"documentID": "3", "title": "Summer Movie"},
+	})
+
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	ruleStore := rules.NewFileRuleStore(filepath.Join(t.TempDir(), "rules.json"))
+	for _, rule := range []model.Rule{
+		{ID: "current", ValidFrom: &past, ValidUntil: &future, Actions: []model.RuleAction{{Type: model.RuleActionPin, DocumentIDs: []string{"2"}}}},
+		{ID: "upcoming", ValidFrom: &future, Actions: []model.RuleAction{{Type: model.RuleActionHide, DocumentIDs: []string{"1"}}}},
+		{ID: "expired", ValidUntil: &past, Actions: []model.RuleAction{{Type: model.RuleActionHide, DocumentIDs: []string{"3"}}}},
+	} {
+		rule.IndexName = "test_multi_search"
+		rule.Condition = model.RuleCondition{Query: "movie"}
+		rule.CreatedAt = time.Now()
+		if err := ruleStore.SaveRule(rule); err != nil {
+			t.Fatalf("SaveRule() error = %v", err)
+		}
+	}
+	s.SetRuleStore(ruleStore)
+
+	result, err := s.Search(services.SearchQuery{QueryString: "movie", PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if result.Total != 3 {
+		t.Errorf("Total = %d, want 3: rules outside their validity window must not hide documents", result.Total)
+	}
+	wantApplied := []services.AppliedRule{{RuleID: "current", Action: model.RuleActionPin, DocumentIDs: []string{"2"}}}
+	if !reflect.DeepEqual(result.AppliedRules, wantApplied) {
+		t.Errorf("AppliedRules = %+v, want %+v", result.AppliedRules, wantApplied)
+	}
+}
@@ -0,0 +1,28 @@
+package search
+
+import "github.com/gcbaptista/go-search-engine/index"
+
+// maxTokensInOneField returns the most query tokens a document matches, exactly or via typo, within
+// a single field. Tokens spread over a title and a description count once per field, so a query
+// asking for all tokens in one field does not match them.
+func maxTokensInOneField(docID uint32, tokens []string, exactMatches, typoMatches map[string]map[uint32][]index.PostingEntry) int {
+	tokensByField := make(map[string]int)
+	for _, token := range tokens {
+		fields := make(map[string]struct{})
+		for _, entry := range exactMatches[token][docID] {
+			fields[entry.FieldName] = struct{}{}
+		}
+		for _, entry := range typoMatches[token][docID] {
+			fields[entry.FieldName] = struct{}{}
+		}
+		for field := range fields {
+			tokensByField[field]++
+		}
+	}
+
+	most := 0
+	for _, count := range tokensByField {
+		most = max(most, count)
+	}
+	return most
+}
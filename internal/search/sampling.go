@@ -0,0 +1,47 @@
+package search
+
+import (
+	"math"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+)
+
+// validateSample checks that the sample rate of a query is a share of the candidates. A rate of 0
+// disables sampling.
+func validateSample(sample float64) error {
+	if math.IsNaN(sample) || sample < 0 || sample > 1 {
+		return errors.NewInvalidQueryError("sample must be between 0 and 1, got %v", sample)
+	}
+	return nil
+}
+
+// sampling reports whether a query evaluates only a sample of its candidates.
+func sampling(sample float64) bool {
+	return sample > 0 && sample < 1
+}
+
+// inSample reports whether a document is part of the sample evaluated at a sample rate. Documents
+// are picked by a hash of their internal ID, so the same documents are sampled by every query
+// and a smaller sample is a subset of a larger one.
+func inSample(docID uint32, sample float64) bool {
+	// splitmix64 finalizer, spreading consecutive IDs uniformly
+	hash := uint64(docID) + 0x9e3779b97f4a7c15
+	hash = (hash ^ (hash >> 30)) * 0xbf58476d1ce4e5b9
+	hash = (hash ^ (hash >> 27)) * 0x94d049bb133111eb
+	hash ^= hash >> 31
+	return float64(hash>>11)/(1<<53) < sample
+}
+
+// extrapolate estimates the count over all candidates of a count made over a sample.
+func extrapolate(count int, sample float64) int {
+	return int(math.Round(float64(count) / sample))
+}
+
+// extrapolateFacets estimates the facet counts over all candidates of counts made over a sample.
+func extrapolateFacets(facets map[string]map[string]int, sample float64) {
+	for _, counts := range facets {
+		for value, count := range counts {
+			counts[value] = extrapolate(count, sample)
+		}
+	}
+}
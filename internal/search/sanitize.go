@@ -0,0 +1,101 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// sanitizeQuery applies the index's query sanitizer to the raw query string and tokens of a query.
+// Queries are returned unchanged when the index has no sanitizer.
+func (s *Service) sanitizeQuery(query services.SearchQuery) services.SearchQuery {
+	policy := s.settings.QuerySanitizer
+	if policy == nil {
+		return query
+	}
+
+	query.QueryString = sanitizeText(query.QueryString, policy)
+	if len(query.Tokens) > 0 {
+		tokens := make([]services.QueryToken, len(query.Tokens))
+		for i, token := range query.Tokens {
+			token.Token = sanitizeText(token.Token, policy)
+			tokens[i] = token
+		}
+		query.Tokens = tokens
+	}
+	return query
+}
+
+// sanitizeText cleans up raw user input: mapped emoji are replaced by their token and other emoji
+// dropped, control characters become spaces, runs of the same letter are collapsed, and the text
+// is clamped to the maximum query length.
+func sanitizeText(text string, policy *config.QuerySanitizer) string {
+	if len(policy.EmojiTokens) > 0 {
+		text = emojiReplacer(policy.EmojiTokens).Replace(text)
+	}
+
+	maxRepeated := policy.RepeatedCharacters()
+	maxLength := policy.QueryLength()
+
+	var builder strings.Builder
+	var previous rune
+	length, repeated := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			r = ' '
+		case isEmoji(r):
+			continue
+		}
+
+		if r == previous && unicode.IsLetter(r) {
+			repeated++
+			if repeated > maxRepeated {
+				continue
+			}
+		} else {
+			repeated = 1
+		}
+		previous = r
+
+		if length == maxLength {
+			break
+		}
+		builder.WriteRune(r)
+		length++
+	}
+	return builder.String()
+}
+
+// emojiReplacer replaces each mapped emoji by its token surrounded by spaces. Longer emoji
+// sequences are matched first, so a sequence is not split by a mapping of one of its parts.
+func emojiReplacer(emojiTokens map[string]string) *strings.Replacer {
+	emoji := make([]string, 0, len(emojiTokens))
+	for e := range emojiTokens {
+		emoji = append(emoji, e)
+	}
+	sort.Slice(emoji, func(i, j int) bool {
+		if len(emoji[i]) != len(emoji[j]) {
+			return len(emoji[i]) > len(emoji[j])
+		}
+		return emoji[i] < emoji[j]
+	})
+
+	pairs := make([]string, 0, 2*len(emoji))
+	for _, e := range emoji {
+		pairs = append(pairs, e, " "+emojiTokens[e]+" ")
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// isEmoji reports whether a rune is an emoji or a part of an emoji sequence: pictographs and other
+// symbols, skin tone modifiers, variation selectors and the combining keycap.
+func isEmoji(r rune) bool {
+	return unicode.Is(unicode.So, r) ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) ||
+		r == 0xFE0E || r == 0xFE0F ||
+		r == 0x20E3
+}
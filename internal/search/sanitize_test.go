@@ -0,0 +1,62 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		policy config.QuerySanitizer
+		want   string
+	}{
+		{"plain text is unchanged", `"new york" pizza~2`, config.QuerySanitizer{}, `"new york" pizza~2`},
+		{"control characters become spaces", "new\x00york\u200btimes\n", config.QuerySanitizer{}, "new york times "},
+		{"repeated letters are collapsed", "coooooool", config.QuerySanitizer{}, "coool"},
+		{"repeated digits are kept", "1000000", config.QuerySanitizer{MaxRepeatedCharacters: 1}, "1000000"},
+		{"custom repeat limit", "aaaaaaaa bb", config.QuerySanitizer{MaxRepeatedCharacters: 1}, "a b"},
+		{"queries are clamped to the maximum length", "abcdefgh", config.QuerySanitizer{MaxQueryLength: 5}, "abcde"},
+		{"length counts characters, not bytes", "ääää", config.QuerySanitizer{MaxQueryLength: 2, MaxRepeatedCharacters: 5}, "ää"},
+		{"emoji are dropped", "best 🍕👍🏽 ever ✨", config.QuerySanitizer{}, "best  ever "},
+		{"mapped emoji become tokens", "best 🍕 ever", config.QuerySanitizer{EmojiTokens: map[string]string{"🍕": "pizza"}}, "best  pizza  ever"},
+		{"longer emoji sequences are mapped first", "👍🏽", config.QuerySanitizer{EmojiTokens: map[string]string{"👍": "like", "👍🏽": "approve"}}, " approve "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sanitizeText(tt.text, &tt.policy))
+		})
+	}
+}
+
+func TestSearchSanitizesQueries(t *testing.T) {
+	settings := newTestIndexSettings()
+	settings.QuerySanitizer = &config.QuerySanitizer{EmojiTokens: map[string]string{"🍕": "pizza"}}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Pizza Place", "description": "Coool slices"},
+	}))
+
+	for _, queryString := range []string{"🍕", "cooooooooool", "pizza\x07place", "pizza" + strings.Repeat(" ", 1000) + "unrelated"} {
+		result, err := service.Search(services.SearchQuery{QueryString: queryString})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Total, "query %q", queryString)
+	}
+
+	result, err := service.Search(services.SearchQuery{Tokens: []services.QueryToken{{Token: "🍕", Mode: services.TokenMatchExact}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Total, "Structured tokens are sanitized too")
+
+	settings.QuerySanitizer = nil
+	result, err = service.Search(services.SearchQuery{QueryString: "🍕"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Total, "Queries are left alone without a sanitizer")
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SetScorer sets the custom scorer applied to every hit. A nil scorer restores the default scoring.
+func (s *Service) SetScorer(scorer services.Scorer) {
+	s.extensionsMu.Lock()
+	defer s.extensionsMu.Unlock()
+	s.scorer = scorer
+}
+
+// termScore returns the score of a posting entry matched for a term under the index's scoring
+// algorithm, its term frequency or its BM25 score, multiplied by the weight of the entry's field.
+// Typo matches carry their penalty in the term frequency.
+func (s *Service) termScore(term string, entry index.PostingEntry, fieldWeights map[string]float64) float64 {
+	score := entry.Score
+	if s.settings.ScoringAlgorithm == config.ScoringBM25 {
+		score = s.bm25.FieldScore(term, entry.DocID, entry.FieldName, entry.Score)
+	}
+	if weight, weighted := fieldWeights[entry.FieldName]; weighted {
+		score *= weight
+	}
+	return score
+}
+
+// tokenPositionWeights returns the weights of the best scores of the query tokens by position, under
+// the query's token decay or else the index's: each token weighs decay times the token before it,
+// and the weights average 1, so scores keep their scale. It returns nil when every token weighs the
+// same.
+func (s *Service) tokenPositionWeights(queryDecay *float64, tokens int) ([]float64, error) {
+	decay := s.settings.QueryTokenDecay
+	if queryDecay != nil {
+		if *queryDecay <= 0 || *queryDecay > config.MaxQueryTokenDecay {
+			return nil, errors.NewInvalidQueryError("query_token_decay must be greater than 0 and at most %g", float64(config.MaxQueryTokenDecay))
+		}
+		decay = *queryDecay
+	}
+	if decay == 0 || decay == 1 || tokens < 2 {
+		return nil, nil
+	}
+
+	weights := make([]float64, tokens)
+	total := 0.0
+	for i, weight := 0, 1.0; i < tokens; i, weight = i+1, weight*decay {
+		weights[i] = weight
+		total += weight
+	}
+	for i := range weights {
+		weights[i] *= float64(tokens) / total
+	}
+	return weights, nil
+}
+
+// fieldWeights returns the weights of the searchable fields for a query: the query's weights
+// override the index's.
+func (s *Service) fieldWeights(queryWeights map[string]float64) (map[string]float64, error) {
+	if len(queryWeights) == 0 {
+		return s.settings.FieldWeights, nil
+	}
+	weights := maps.Clone(s.settings.FieldWeights)
+	if weights == nil {
+		weights = make(map[string]float64, len(queryWeights))
+	}
+	for field, weight := range queryWeights {
+		if !slices.Contains(s.settings.SearchableFields, field) {
+			return nil, errors.NewInvalidQueryError("weighted field '%s' is not configured as a searchable field in index settings", field)
+		}
+		if weight <= 0 {
+			return nil, errors.NewInvalidQueryError("weight of field '%s' must be greater than 0", field)
+		}
+		weights[field] = weight
+	}
+	return weights, nil
+}
+
+// validateBoosts checks that every boost rule of a query has filter conditions and changes scores.
+func validateBoosts(boosts []services.BoostRule) error {
+	for i, boost := range boosts {
+		if len(boost.Filter.Filters) == 0 && len(boost.Filter.Groups) == 0 {
+			return errors.NewInvalidQueryError("boost %d has no filter conditions", i)
+		}
+		if boost.Multiplier < 0 {
+			return errors.NewInvalidQueryError("multiplier of boost %d cannot be negative", i)
+		}
+		if boost.Multiplier == 0 && boost.Addend == 0 {
+			return errors.NewInvalidQueryError("boost %d must set a multiplier or an addend", i)
+		}
+	}
+	return nil
+}
+
+// boostScore applies the boost rules whose filter the document matches to its score, in order.
+func (s *Service) boostScore(doc model.Document, score float64, boosts []services.BoostRule, filterLocale string) float64 {
+	for _, boost := range boosts {
+		if matches, _ := s.evaluateFilters(doc, boost.Filter, filterLocale); !matches {
+			continue
+		}
+		if boost.Multiplier != 0 {
+			score *= boost.Multiplier
+		}
+		score += boost.Addend
+	}
+	return score
+}
@@ -2,20 +2,27 @@ package search
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/internal/rules"
 	"github.com/gcbaptista/go-search-engine/internal/tokenizer"
+	"github.com/gcbaptista/go-search-engine/internal/tracing"
 	"github.com/gcbaptista/go-search-engine/internal/typoutil"
 	"github.com/gcbaptista/go-search-engine/model"
 	"github.com/gcbaptista/go-search-engine/services"
 	"github.com/gcbaptista/go-search-engine/store"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Service implements the search logic for a single index.
@@ -24,7 +31,21 @@ type Service struct {
 	invertedIndex *index.InvertedIndex
 	documentStore *store.DocumentStore
 	settings      *config.IndexSettings
-	typoFinder    *typoutil.TypoFinder // Typo finder with caching
+	protected     *typoutil.ProtectedWords // Precompiled NonTypoTolerantWords
+	analyzer      *tokenizer.Analyzer      // Analyzer matching the one used at index time
+	bm25          *BM25Calculator          // Scores matches when settings.ScoringAlgorithm is BM25
+	fieldMaxTypos map[string]int           // Most typos allowed per searchable field
+
+	settingsRewriters []services.QueryRewriter // Built-in rewriters enabled by the settings, applied before the registered ones
+
+	extensionsMu sync.RWMutex
+	rewriters    []services.QueryRewriter // Applied in order before every search
+	scorer       services.Scorer          // Optional custom scorer selected by settings.Scorer
+	ruleStore    services.RuleStore       // Optional source of merchandising rules (pins, hides)
+	slowQuery    time.Duration            // Searches taking at least this long are logged as slow; 0 logs none
+
+	typoStats   typoStatsRecorder // Effectiveness of typo expansion across searches
+	suggestions suggestionCache   // Trie completing prefixes for typeahead, built on first use
 }
 
 // NewService creates a new search Service.
@@ -39,43 +60,181 @@ func NewService(invIndex *index.InvertedIndex, docStore *store.DocumentStore, se
 		return nil, fmt.Errorf("settings cannot be nil")
 	}
 
-	// Create indexed terms slice for typo finder
-	indexedTerms := make([]string, 0, len(invIndex.Index))
-	for term := range invIndex.Index {
-		indexedTerms = append(indexedTerms, term)
-	}
-
-	// Initialize typo finder
-	typoFinder := typoutil.NewTypoFinder(indexedTerms)
-	typoFinder.UpdateIndexedTerms(indexedTerms)
-
 	return &Service{
-		invertedIndex: invIndex,
-		documentStore: docStore,
-		settings:      settings,
-		typoFinder:    typoFinder,
+		invertedIndex:     invIndex,
+		documentStore:     docStore,
+		settings:          settings,
+		protected:         typoutil.NewProtectedWords(settings.NonTypoTolerantWords),
+		analyzer:          tokenizer.NewAnalyzer(settings),
+		bm25:              NewBM25Calculator(invIndex, docStore),
+		fieldMaxTypos:     fieldMaxTypos(settings),
+		settingsRewriters: settingsRewriters(settings),
 	}, nil
 }
 
-// UpdateTypoFinder updates the typo finder's indexed terms.
-// This should be called after documents are added to keep the typo finder in sync.
-func (s *Service) UpdateTypoFinder() {
-	// Get current indexed terms
-	indexedTerms := make([]string, 0, len(s.invertedIndex.Index))
-	for term := range s.invertedIndex.Index {
-		indexedTerms = append(indexedTerms, term)
+// Search performs a search operation based on the query. When a query finds no results, the
+// index's zero-result fallback strategies are tried in order until one of them finds hits, and
+// queries that would find some are suggested if the query asks for them.
+// Queries without a page size get the index's default one; larger pages than the index's maximum
+// are rejected.
+func (s *Service) Search(query services.SearchQuery) (services.SearchResult, error) {
+	pageSize, err := s.pageSize(query.PageSize)
+	if err != nil {
+		return services.SearchResult{}, err
+	}
+	query.PageSize = pageSize
+	start := time.Now()
+	result, err := s.search(query)
+	if err == nil {
+		s.logSlowQuery(query, result, time.Since(start))
 	}
+	return result, err
+}
 
-	// Update the typo finder
-	s.typoFinder.UpdateIndexedTerms(indexedTerms)
+// SearchAll performs a search operation and returns all of its hits on a single page, whatever the
+// index's maximum page size. It is meant for background work such as exports, not client requests.
+func (s *Service) SearchAll(query services.SearchQuery) (services.SearchResult, error) {
+	query.Page = 1
+	query.PageSize = math.MaxInt32
+	return s.search(query)
 }
 
-const defaultPageSize = 10
+// pageSize returns the page size of a query, rejecting it when it exceeds the index's maximum.
+func (s *Service) pageSize(pageSize int) (int, error) {
+	if pageSize <= 0 {
+		return s.settings.SearchPageSize(), nil
+	}
+	if limit := s.settings.SearchPageSizeLimit(); pageSize > limit {
+		return 0, errors.NewInvalidQueryError("page size %d exceeds the maximum page size of %d", pageSize, limit)
+	}
+	return pageSize, nil
+}
 
-// Search performs a search operation based on the query.
-func (s *Service) Search(query services.SearchQuery) (services.SearchResult, error) {
+// search performs a search operation without checking the page size. Its steps are traced as
+// spans of the trace of the request the search serves.
+func (s *Service) search(query services.SearchQuery) (result services.SearchResult, err error) {
 	startTime := time.Now()
+	ctx, span := tracing.Start(query.Context, "search",
+		attribute.String("index", s.settings.Name),
+		attribute.Int("page", query.Page),
+		attribute.Int("page_size", query.PageSize),
+	)
+	defer func() {
+		span.SetAttributes(attribute.String("query_id", result.QueryId), attribute.Int("total", result.Total))
+		tracing.End(span, err)
+	}()
+	query.Context = ctx
+
+	mode, err := strategyMatchMode(query.MatchingStrategy)
+	if err != nil {
+		return services.SearchResult{}, err
+	}
 
+	_, tokenizeSpan := tracing.Start(ctx, "search.tokenize")
+	query, match, originalQueryTokens, phrases, err := s.analyzeQuery(query)
+	tokenizeSpan.SetAttributes(attribute.Int("tokens", len(originalQueryTokens)))
+	tracing.End(tokenizeSpan, err)
+	if err != nil {
+		return services.SearchResult{}, err
+	}
+
+	result, err = s.execute(query, match, originalQueryTokens, phrases, mode, startTime)
+	if err != nil || result.Total > 0 || len(originalQueryTokens) == 0 {
+		return result, err
+	}
+	if result, err = s.applyFallbacks(query, match, originalQueryTokens, phrases, mode, result, startTime); err != nil || result.Total > 0 || !query.Suggest {
+		return result, err
+	}
+	if result.Suggestions, err = s.suggestQueries(query, originalQueryTokens, mode, startTime); err != nil {
+		return services.SearchResult{}, err
+	}
+	result.Took = time.Since(startTime).Milliseconds()
+	return result, nil
+}
+
+// analyzeQuery sanitizes a query, applies the rewrites of matching rules and query rewriters, and
+// splits it into the tokens searched, its quoted phrases and excluded terms.
+func (s *Service) analyzeQuery(query services.SearchQuery) (services.SearchQuery, ruleMatch, []string, []phrase, error) {
+	if err := validateTokens(query.Tokens); err != nil {
+		return services.SearchQuery{}, ruleMatch{}, nil, nil, err
+	}
+	query = s.sanitizeQuery(query)
+
+	var err error
+	var originalQueryTokens []string
+	var phrases []phrase
+	var match ruleMatch
+	if len(query.Tokens) > 0 {
+		query, originalQueryTokens = s.structuredQuery(query)
+		match.rules = s.matchingRules(query.QueryString)
+	} else {
+		// Rules match the query as typed; a rewritten query is parsed again, as it may have
+		// phrases and excluded terms of its own
+		var parsed services.SearchQuery
+		var parsedPhrases []phrase
+		if parsed, parsedPhrases, err = s.parseQueryString(query); err != nil {
+			return services.SearchQuery{}, ruleMatch{}, nil, nil, err
+		}
+		match.rules = s.matchingRules(parsed.QueryString)
+		if query.QueryString, match.applied = rules.Rewrite(match.rules, query.QueryString); len(match.applied) > 0 {
+			if parsed, parsedPhrases, err = s.parseQueryString(query); err != nil {
+				return services.SearchQuery{}, ruleMatch{}, nil, nil, err
+			}
+		}
+		query, phrases = parsed, parsedPhrases
+
+		if query, originalQueryTokens, err = s.rewriteQuery(query); err != nil {
+			return services.SearchQuery{}, ruleMatch{}, nil, nil, err
+		}
+	}
+	var filterRules []services.AppliedRule
+	query.Filters, filterRules = rules.AddFilters(match.rules, query.Filters)
+	match.applied = append(match.applied, filterRules...)
+	return query, match, originalQueryTokens, phrases, nil
+}
+
+// parseQueryString extracts the excluded terms and the quoted phrases of a free-text query.
+func (s *Service) parseQueryString(query services.SearchQuery) (services.SearchQuery, []phrase, error) {
+	query = parseExclusions(query)
+	queryString, phrases, err := s.parsePhrases(query)
+	if err != nil {
+		return services.SearchQuery{}, nil, err
+	}
+	query.QueryString = queryString
+	return query, phrases, nil
+}
+
+// strategyMatchMode returns the match mode of a matching strategy. Queries without a strategy
+// match all tokens.
+func strategyMatchMode(strategy services.MatchingStrategy) (matchMode, error) {
+	switch strategy {
+	case "", services.MatchingStrategyAll:
+		return matchAllTokens, nil
+	case services.MatchingStrategyAny:
+		return matchAnyToken, nil
+	case services.MatchingStrategyMost:
+		return matchMostTokens, nil
+	}
+	return matchAllTokens, errors.NewInvalidQueryError("matching strategy '%s' is not one of all, any or most", strategy)
+}
+
+// minMatchedTokens returns how many of a query's tokens candidates must match in a match mode.
+func minMatchedTokens(mode matchMode, tokens int) int {
+	switch mode {
+	case matchAnyToken:
+		return 1
+	case matchMostTokens:
+		return tokens/2 + 1
+	}
+	return tokens
+}
+
+// execute runs a query whose tokens are already analyzed and rewritten. The mode decides which
+// documents are candidates: those matching all, any or most tokens, or all documents. Documents
+// matching only some tokens have their score scaled by the share of tokens they match.
+// Candidates must also contain the quoted phrases of the query and none of its excluded terms, and
+// with RequireSameField match the tokens the mode requires within a single field.
+func (s *Service) execute(query services.SearchQuery, match ruleMatch, originalQueryTokens []string, phrases []phrase, mode matchMode, startTime time.Time) (services.SearchResult, error) {
 	// Determine effective searchable fields based on query and index settings
 	var effectiveSearchableFields []string
 	var isFieldAllowed func(string) bool
@@ -90,7 +249,7 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 		// Validate that restricted fields are a subset of configured searchable fields
 		for _, restrictedField := range query.RestrictSearchableFields {
 			if !configuredFields[restrictedField] {
-				return services.SearchResult{}, fmt.Errorf("restricted searchable field '%s' is not configured as a searchable field in index settings", restrictedField)
+				return services.SearchResult{}, errors.NewInvalidQueryError("restricted searchable field '%s' is not configured as a searchable field in index settings", restrictedField)
 			}
 		}
 
@@ -121,25 +280,99 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 		}
 	}
 
+	if len(query.ExcludeSearchableFields) > 0 {
+		// ExcludeSearchableFields provided - validate and remove them from the effective searchable fields
+		configuredFields := make(map[string]bool)
+		for _, field := range s.settings.SearchableFields {
+			configuredFields[field] = true
+		}
+
+		excludedFields := make(map[string]bool)
+		for _, excludedField := range query.ExcludeSearchableFields {
+			if !configuredFields[excludedField] {
+				return services.SearchResult{}, errors.NewInvalidQueryError("excluded searchable field '%s' is not configured as a searchable field in index settings", excludedField)
+			}
+			excludedFields[excludedField] = true
+		}
+
+		remainingFields := make([]string, 0, len(effectiveSearchableFields))
+		for _, field := range effectiveSearchableFields {
+			if !excludedFields[field] {
+				remainingFields = append(remainingFields, field)
+			}
+		}
+		if len(remainingFields) == 0 {
+			return services.SearchResult{}, errors.NewInvalidQueryError("excluded searchable fields leave no field to search in")
+		}
+		effectiveSearchableFields = remainingFields
+
+		// Recreate field restriction checker without the excluded fields
+		allowedFields := make(map[string]bool)
+		for _, field := range effectiveSearchableFields {
+			allowedFields[field] = true
+		}
+
+		isFieldAllowed = func(fieldName string) bool {
+			return allowedFields[fieldName]
+		}
+	}
+
+	if err := s.validateFacets(query.Facets); err != nil {
+		return services.SearchResult{}, err
+	}
+	fieldWeights, err := s.fieldWeights(query.FieldWeights)
+	if err != nil {
+		return services.SearchResult{}, err
+	}
+	positionWeights, err := s.tokenPositionWeights(query.QueryTokenDecay, len(originalQueryTokens))
+	if err != nil {
+		return services.SearchResult{}, err
+	}
+	if query.Filters != nil {
+		if err := validateFilterScoring(*query.Filters); err != nil {
+			return services.SearchResult{}, err
+		}
+	}
+	if err := validateBoosts(query.Boosts); err != nil {
+		return services.SearchResult{}, err
+	}
+	if err := validateSample(query.Sample); err != nil {
+		return services.SearchResult{}, err
+	}
+	sampled := sampling(query.Sample)
+
 	page := query.Page
 	if page <= 0 {
 		page = 1
 	}
 	pageSize := query.PageSize
 	if pageSize <= 0 {
-		pageSize = defaultPageSize
+		pageSize = s.settings.SearchPageSize()
+	}
+	var after *rankItem
+	if query.SearchAfter != "" {
+		if page > 1 {
+			return services.SearchResult{}, errors.NewInvalidQueryError("search_after cannot be combined with page %d", page)
+		}
+		if sampled {
+			return services.SearchResult{}, errors.NewInvalidQueryError("search_after cannot be combined with sample")
+		}
+		cursor, err := s.decodeCursor(query.SearchAfter)
+		if err != nil {
+			return services.SearchResult{}, err
+		}
+		after = &cursor
 	}
 
-	originalQueryTokens := tokenizer.Tokenize(query.QueryString)
-	if len(originalQueryTokens) == 0 {
+	if len(originalQueryTokens) == 0 && mode != matchAllDocuments {
 		queryUUID := uuid.New().String()
 		return services.SearchResult{Hits: []services.HitResult{}, Total: 0, Page: page, PageSize: pageSize, Took: time.Since(startTime).Milliseconds(), QueryId: queryUUID}, nil
 	}
 
+	// Writes run alongside searches: term lookups only wait for the shard being written, and
+	// documents are read one at a time. Write batches hold the lock exclusively to appear atomic.
 	s.invertedIndex.Mu.RLock()
-	s.documentStore.Mu.RLock()
 	defer s.invertedIndex.Mu.RUnlock()
-	defer s.documentStore.Mu.RUnlock()
 
 	// Per query token, store map of DocID to list of posting entries (can match multiple fields)
 	docMatchesByQueryToken := make(map[string]map[uint32][]index.PostingEntry)
@@ -153,41 +386,56 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 	// Map: originalQueryToken -> docID -> bestTypoDistance
 	bestTypoDistanceByQueryToken := make(map[string]map[uint32]int)
 
+	// Typo expansion counts of this search, added to the service totals once it completes
+	var typoCounts typoCounts
+	// Limits that truncated this search, reported with its results
+	var warnings []services.SearchWarning
+
+	// Tokens of structured queries may carry explicit match modes
+	modes := tokenModes(query.Tokens)
+
+	// With PrefixLast, the last token also matches longer words in fields without prefix n-grams
+	prefixToken := lastTokenPrefix(query, originalQueryTokens)
+
+	candidatesCtx, candidatesSpan := tracing.Start(query.Context, "search.candidates")
+
 	// First pass: collect exact matches for all query tokens
 	for _, queryToken := range originalQueryTokens {
+		exactOnly := modes[queryToken].Mode == services.TokenMatchExact
 		docMatchesByQueryToken[queryToken] = make(map[uint32][]index.PostingEntry)
 		docMatchesByOriginalQueryTokenForTypos[queryToken] = make(map[uint32][]index.PostingEntry)
 		typoTermsMatchedByQueryToken[queryToken] = make(map[uint32][]string)
 		bestTypoDistanceByQueryToken[queryToken] = make(map[uint32]int)
 
 		// 1. Exact matches for the queryToken
-		if postingList, found := s.invertedIndex.Index[queryToken]; found {
+		if postingList, found := s.invertedIndex.Get(queryToken); found {
 			for _, entry := range postingList {
-				if isFieldAllowed(entry.FieldName) {
+				if isFieldAllowed(entry.FieldName) && (!exactOnly || entry.IsFullWord) {
 					docMatchesByQueryToken[queryToken][entry.DocID] = append(docMatchesByQueryToken[queryToken][entry.DocID], entry)
 				}
 			}
 		}
+		if queryToken == prefixToken && !exactOnly {
+			for _, entry := range s.prefixWordPostings(queryToken, isFieldAllowed) {
+				docMatchesByQueryToken[queryToken][entry.DocID] = append(docMatchesByQueryToken[queryToken][entry.DocID], entry)
+			}
+		}
+	}
+
+	// Fields past the typo budget only match exactly, so typos are not expanded beyond the most
+	// tolerant field searched
+	maxFieldTypos := 0
+	for _, field := range effectiveSearchableFields {
+		maxFieldTypos = max(maxFieldTypos, s.fieldMaxTypos[field])
 	}
 
 	// Second pass: apply typo tolerance (skip if document already has exact match for the specific token)
+	_, typoSpan := tracing.Start(candidatesCtx, "search.typo_expansion")
 	for _, queryToken := range originalQueryTokens {
 		// 2. Typo matches for the queryToken
 		// Check if this query token is in the non-typo tolerant words list
-		isNonTypoTolerant := false
-		for _, nonTypoWord := range s.settings.NonTypoTolerantWords {
-			if strings.EqualFold(queryToken, nonTypoWord) {
-				isNonTypoTolerant = true
-				break
-			}
-		}
-
 		// Skip typo matching if this word is in the non-typo tolerant list
-		if !isNonTypoTolerant {
-			// Use dual criteria: stop when either 500 tokens found OR 50ms elapsed
-			maxTypoResults := 500
-			timeLimit := 50 * time.Millisecond
-
+		if !s.protected.Contains(queryToken) {
 			// Use query-level minWordSize settings if provided, otherwise fall back to index settings
 			minWordSizeFor1Typo := s.settings.MinWordSizeFor1Typo
 			if query.MinWordSizeFor1Typo != nil {
@@ -198,39 +446,31 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 			if query.MinWordSizeFor2Typos != nil {
 				minWordSizeFor2Typos = *query.MinWordSizeFor2Typos
 			}
-
-			if minWordSizeFor1Typo > 0 && len(queryToken) >= minWordSizeFor1Typo {
-				typos1 := s.typoFinder.GenerateTyposWithTimeLimit(queryToken, 1, maxTypoResults, timeLimit)
+			oneTypo, twoTypos := allowedTypos(queryToken, modes[queryToken], minWordSizeFor1Typo, minWordSizeFor2Typos, s.settings.NumberTypos)
+			oneTypo = oneTypo && maxFieldTypos >= 1
+			twoTypos = twoTypos && maxFieldTypos >= 2
+			typosTruncated := false
+
+			if oneTypo {
+				typos1 := s.invertedIndex.Typos(queryToken, 1, maxTypoResults)
+				typosTruncated = len(typos1) >= maxTypoResults
+				matchedTypos := 0
 				for _, typoTerm := range typos1 {
 					// Skip if the typo term is the same as the original query token
 					if typoTerm == queryToken {
 						continue
 					}
 
-					// Check if the typo term itself is in the non-typo tolerant words list
-					// or if it's a prefix that could match non-typo tolerant words
-					isTypoTermNonTypoTolerant := false
-					for _, nonTypoWord := range s.settings.NonTypoTolerantWords {
-						if strings.EqualFold(typoTerm, nonTypoWord) {
-							isTypoTermNonTypoTolerant = true
-							break
-						}
-						// Also check if the typo term is a prefix of a non-typo tolerant word
-						// This prevents partial matches like "stal" matching documents with "stalin"
-						if len(typoTerm) >= 3 && strings.HasPrefix(strings.ToLower(nonTypoWord), strings.ToLower(typoTerm)) {
-							isTypoTermNonTypoTolerant = true
-							break
-						}
-					}
-
-					// Skip this typo if it would match a non-typo tolerant word
-					if isTypoTermNonTypoTolerant {
+					// Skip this typo if it equals a non-typo tolerant word or is a prefix of one.
+					// This prevents partial matches like "stal" matching documents with "stalin"
+					if s.protected.BlocksTypoTerm(typoTerm) {
 						continue
 					}
 
-					if postingList, found := s.invertedIndex.Index[typoTerm]; found {
+					typoMatched := false
+					if postingList, found := s.invertedIndex.Get(typoTerm); found {
 						for _, entry := range postingList {
-							if isFieldAllowed(entry.FieldName) {
+							if isFieldAllowed(entry.FieldName) && s.fieldMaxTypos[entry.FieldName] >= 1 {
 								// Skip typo matching for documents that already have exact matches for this specific query token
 								if _, hasExactMatch := docMatchesByQueryToken[queryToken][entry.DocID]; hasExactMatch {
 									continue
@@ -242,6 +482,7 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 									continue // Skip this 1-typo match since we already have an equal or better match
 								}
 
+								typoMatched = true
 								typoEntry := entry
 								typoEntry.Score *= 0.8 // Penalize typo scores slightly
 
@@ -258,41 +499,33 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 							}
 						}
 					}
+					if typoMatched {
+						matchedTypos++
+					}
 				}
+				typoCounts.expanded(1, len(typos1), matchedTypos)
 			}
 
-			if minWordSizeFor2Typos > 0 && len(queryToken) >= minWordSizeFor2Typos {
-				typos2 := s.typoFinder.GenerateTyposWithTimeLimit(queryToken, 2, maxTypoResults, timeLimit)
+			if twoTypos {
+				typos2 := s.invertedIndex.Typos(queryToken, 2, maxTypoResults)
+				typosTruncated = typosTruncated || len(typos2) >= maxTypoResults
+				matchedTypos := 0
 				for _, typoTerm := range typos2 {
 					// Skip if the typo term is the same as the original query token
 					if typoTerm == queryToken {
 						continue
 					}
 
-					// Check if the typo term itself is in the non-typo tolerant words list
-					// or if it's a prefix that could match non-typo tolerant words
-					isTypoTermNonTypoTolerant := false
-					for _, nonTypoWord := range s.settings.NonTypoTolerantWords {
-						if strings.EqualFold(typoTerm, nonTypoWord) {
-							isTypoTermNonTypoTolerant = true
-							break
-						}
-						// Also check if the typo term is a prefix of a non-typo tolerant word
-						// This prevents partial matches like "stal" matching documents with "stalin"
-						if len(typoTerm) >= 3 && strings.HasPrefix(strings.ToLower(nonTypoWord), strings.ToLower(typoTerm)) {
-							isTypoTermNonTypoTolerant = true
-							break
-						}
-					}
-
-					// Skip this typo if it would match a non-typo tolerant word
-					if isTypoTermNonTypoTolerant {
+					// Skip this typo if it equals a non-typo tolerant word or is a prefix of one.
+					// This prevents partial matches like "stal" matching documents with "stalin"
+					if s.protected.BlocksTypoTerm(typoTerm) {
 						continue
 					}
 
-					if postingList, found := s.invertedIndex.Index[typoTerm]; found {
+					typoMatched := false
+					if postingList, found := s.invertedIndex.Get(typoTerm); found {
 						for _, entry := range postingList {
-							if isFieldAllowed(entry.FieldName) {
+							if isFieldAllowed(entry.FieldName) && s.fieldMaxTypos[entry.FieldName] >= 2 {
 								// Skip typo matching for documents that already have exact matches for this specific query token
 								if _, hasExactMatch := docMatchesByQueryToken[queryToken][entry.DocID]; hasExactMatch {
 									continue
@@ -304,6 +537,7 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 									continue // Skip this 2-typo match since we already have an equal or better match
 								}
 
+								typoMatched = true
 								typoEntry := entry
 								typoEntry.Score *= 0.6 // Penalize 2-typo matches more than 1-typo
 
@@ -320,61 +554,141 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 							}
 						}
 					}
+					if typoMatched {
+						matchedTypos++
+					}
 				}
+				typoCounts.expanded(2, len(typos2), matchedTypos)
+			}
+			if typosTruncated {
+				warnings = append(warnings, typoExpansionWarning(queryToken))
 			}
 		}
 	}
 
-	// Find intersection of DocIDs: documents that match ALL originalQueryTokens (either exactly or via typo)
-	intersectedDocIDs := make(map[uint32]bool)
-	if len(originalQueryTokens) > 0 {
-		firstToken := originalQueryTokens[0]
-		// Include docs that matched the first token either exactly or via typo
-		for docID := range docMatchesByQueryToken[firstToken] {
-			intersectedDocIDs[docID] = true
-		}
-		for docID := range docMatchesByOriginalQueryTokenForTypos[firstToken] {
-			intersectedDocIDs[docID] = true // also consider docs that matched via typo
-		}
-
-		for i := 1; i < len(originalQueryTokens); i++ {
-			token := originalQueryTokens[i]
-			currentDocIDsForToken := make(map[uint32]bool)
+	typoSpan.SetAttributes(
+		attribute.Int64("typo_terms", typoCounts.byDistance[0].generated+typoCounts.byDistance[1].generated),
+		attribute.Int64("typo_terms_matched", typoCounts.byDistance[0].matched+typoCounts.byDistance[1].matched),
+	)
+	typoSpan.End()
+
+	// Collect candidate DocIDs according to the match mode
+	candidateDocIDs := make(map[uint32]bool)
+	switch mode {
+	case matchAllDocuments:
+		s.documentStore.Range(func(docID uint32, _ model.Document) bool {
+			candidateDocIDs[docID] = true
+			return true
+		})
+	case matchAnyToken, matchMostTokens:
+		// Documents that match enough originalQueryTokens (either exactly or via typo)
+		matchedTokenCounts := make(map[uint32]int)
+		for _, token := range originalQueryTokens {
 			for docID := range docMatchesByQueryToken[token] {
-				currentDocIDsForToken[docID] = true
+				matchedTokenCounts[docID]++
 			}
 			for docID := range docMatchesByOriginalQueryTokenForTypos[token] {
-				currentDocIDsForToken[docID] = true
+				if _, hasExactMatch := docMatchesByQueryToken[token][docID]; !hasExactMatch {
+					matchedTokenCounts[docID]++
+				}
+			}
+		}
+		required := minMatchedTokens(mode, len(originalQueryTokens))
+		for docID, count := range matchedTokenCounts {
+			if count >= required {
+				candidateDocIDs[docID] = true
+			}
+		}
+	default:
+		// Documents that match ALL originalQueryTokens (either exactly or via typo)
+		if len(originalQueryTokens) > 0 {
+			firstToken := originalQueryTokens[0]
+			// Include docs that matched the first token either exactly or via typo
+			for docID := range docMatchesByQueryToken[firstToken] {
+				candidateDocIDs[docID] = true
+			}
+			for docID := range docMatchesByOriginalQueryTokenForTypos[firstToken] {
+				candidateDocIDs[docID] = true // also consider docs that matched via typo
 			}
 
-			newIntersectedDocIDs := make(map[uint32]bool)
-			for docID := range intersectedDocIDs {
-				if currentDocIDsForToken[docID] {
-					newIntersectedDocIDs[docID] = true
+			for i := 1; i < len(originalQueryTokens); i++ {
+				token := originalQueryTokens[i]
+				currentDocIDsForToken := make(map[uint32]bool)
+				for docID := range docMatchesByQueryToken[token] {
+					currentDocIDsForToken[docID] = true
+				}
+				for docID := range docMatchesByOriginalQueryTokenForTypos[token] {
+					currentDocIDsForToken[docID] = true
+				}
+
+				newIntersectedDocIDs := make(map[uint32]bool)
+				for docID := range candidateDocIDs {
+					if currentDocIDsForToken[docID] {
+						newIntersectedDocIDs[docID] = true
+					}
+				}
+				candidateDocIDs = newIntersectedDocIDs
+				if len(candidateDocIDs) == 0 {
+					break // No common documents left
 				}
-			}
-			intersectedDocIDs = newIntersectedDocIDs
-			if len(intersectedDocIDs) == 0 {
-				break // No common documents left
 			}
 		}
 	}
 
-	// Build final hits from intersectedDocIDs
+	candidatesSpan.SetAttributes(attribute.Int("candidates", len(candidateDocIDs)))
+	candidatesSpan.End()
+
+	// Circuit breakers of the index bound the candidates evaluated and the time spent on them
+	limits := s.settings.SearchLimits
+	if warning := limitCandidates(candidateDocIDs, limits); warning != nil {
+		warnings = append(warnings, *warning)
+	}
+	var timeLimit time.Duration
+	if limits != nil {
+		timeLimit = limits.TimeLimit()
+	}
+
+	// Build final hits from candidateDocIDs
 	// candidateHit type is now defined in types.go
 	finalCandidateHits := make(map[uint32]*candidateHit) // docID -> candidateHit
 
-	for docID := range intersectedDocIDs {
-		doc, found := s.documentStore.Docs[docID]
-		if !found {
-			log.Printf("Warning: Document with internal ID %d in intersection but not in document store.\n", docID)
+	var phrasesMatcher *phraseMatcher
+	if len(phrases) > 0 {
+		phrasesMatcher = s.newPhraseMatcher(phrases, isFieldAllowed)
+	}
+	excludedDocIDs := s.excludedDocuments(query, isFieldAllowed)
+
+	// Candidates are filtered and scored by how well they match the query tokens
+	_, filterSpan := tracing.Start(query.Context, "search.filter")
+	evaluated := 0
+	for docID := range candidateDocIDs {
+		if timeLimit > 0 && evaluated > 0 && evaluated%timeLimitCheckInterval == 0 && time.Since(startTime) >= timeLimit {
+			warnings = append(warnings, timeLimitWarning(limits, evaluated, len(candidateDocIDs)))
+			break
+		}
+		evaluated++
+		if excludedDocIDs[docID] {
+			continue
+		}
+		if sampled && !inSample(docID, query.Sample) {
 			continue
 		}
+		if phrasesMatcher != nil && !phrasesMatcher.matches(docID) {
+			continue
+		}
+		if query.RequireSameField && mode != matchAllDocuments &&
+			maxTokensInOneField(docID, originalQueryTokens, docMatchesByQueryToken, docMatchesByOriginalQueryTokenForTypos) < minMatchedTokens(mode, len(originalQueryTokens)) {
+			continue
+		}
+		doc, found := s.documentStore.Get(docID)
+		if !found {
+			continue // Deleted by a write running alongside this search
+		}
 
 		// Apply filter expression if any
 		var filterScore float64
 		if query.Filters != nil {
-			matches, score := s.evaluateFilters(doc, *query.Filters)
+			matches, score := s.evaluateFilters(doc, *query.Filters, query.FilterLocale)
 			if !matches {
 				continue
 			}
@@ -389,7 +703,7 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 		}
 
 		// Aggregate scores and matched fields for this docID from all query tokens
-		for _, queryToken := range originalQueryTokens {
+		for position, queryToken := range originalQueryTokens {
 			// Track the best score for this query token for this document
 			bestScoreForToken := 0.0
 
@@ -397,8 +711,8 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 			if entries, ok := docMatchesByQueryToken[queryToken][docID]; ok {
 				for _, entry := range entries {
 					if isFieldAllowed(entry.FieldName) {
-						if entry.Score > bestScoreForToken {
-							bestScoreForToken = entry.Score
+						if score := s.termScore(queryToken, entry, fieldWeights); score > bestScoreForToken {
+							bestScoreForToken = score
 						}
 						if _, fieldMapExists := currentHit.matchedQueryTermsByField[entry.FieldName]; !fieldMapExists {
 							currentHit.matchedQueryTermsByField[entry.FieldName] = make(map[string]struct{})
@@ -413,35 +727,62 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 				typoTerms := typoTermsMatchedByQueryToken[queryToken][docID]
 				for i, entry := range entries {
 					if isFieldAllowed(entry.FieldName) {
+						matchedTerm := queryToken // fallback
+						if i < len(typoTerms) {
+							matchedTerm = typoTerms[i]
+						}
 						// Only use typo score if it's better than exact match score
 						// (this should rarely happen, but protects against edge cases)
-						if entry.Score > bestScoreForToken {
-							bestScoreForToken = entry.Score
+						if score := s.termScore(matchedTerm, entry, fieldWeights); score > bestScoreForToken {
+							bestScoreForToken = score
 						}
 						if _, fieldMapExists := currentHit.matchedQueryTermsByField[entry.FieldName]; !fieldMapExists {
 							currentHit.matchedQueryTermsByField[entry.FieldName] = make(map[string]struct{})
 						}
 						// Mark typo matches for display using the actual matched typo term
-						var matchDisplay string
-						if i < len(typoTerms) {
-							matchDisplay = typoTerms[i] + "(typo)"
-						} else {
-							matchDisplay = queryToken + "(typo)" // fallback
-						}
-						currentHit.matchedQueryTermsByField[entry.FieldName][matchDisplay] = struct{}{}
+						currentHit.matchedQueryTermsByField[entry.FieldName][matchedTerm+"(typo)"] = struct{}{}
 					}
 				}
 			}
 
-			// Add the best score for this query token to the total
+			// Add the best score for this query token to the total, weighted by the token's position
+			if positionWeights != nil {
+				bestScoreForToken *= positionWeights[position]
+			}
 			currentHit.score += bestScoreForToken
+			if len(docMatchesByQueryToken[queryToken][docID]) > 0 || len(docMatchesByOriginalQueryTokenForTypos[queryToken][docID]) > 0 {
+				currentHit.matchedTokens++
+			}
+		}
+		if (mode == matchAnyToken || mode == matchMostTokens) && len(originalQueryTokens) > 0 {
+			// Documents matching more of the query tokens rank higher
+			currentHit.score *= float64(currentHit.matchedTokens) / float64(len(originalQueryTokens))
 		}
 		finalCandidateHits[docID] = currentHit
 	}
+	filterSpan.SetAttributes(attribute.Int("evaluated", evaluated), attribute.Int("hits", len(finalCandidateHits)))
+	filterSpan.End()
+
+	_, rankSpan := tracing.Start(query.Context, "search.rank")
+	s.extensionsMu.RLock()
+	scorer := s.scorer
+	s.extensionsMu.RUnlock()
+	scoringCtx := services.ScoringContext{IndexName: s.settings.Name, QueryTokens: originalQueryTokens}
+
+	// Sortable fields have typed sort keys built when their documents were stored, so ranking
+	// criteria on them compare the keys instead of the documents' values
+	sortablePositions := make(map[string]int)
+	for i, field := range s.documentStore.SortableFields() {
+		sortablePositions[field] = i
+	}
+	var hitSortKeys [][]store.SortKey
 
 	// Convert finalCandidateHits map to a slice for sorting
 	finalSelectHits := make([]services.HitResult, 0, len(finalCandidateHits))
-	for _, ch := range finalCandidateHits {
+	for docID, ch := range finalCandidateHits {
+		if len(sortablePositions) > 0 {
+			hitSortKeys = append(hitSortKeys, s.documentStore.SortKeys(docID))
+		}
 		matchedTermsResult := make(map[string][]string)
 		numTyposForHit := 0
 		numberExactWordsForHit := 0
@@ -452,23 +793,8 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 		docFullWordsByField := make(map[string][]string)
 		for _, searchableFieldName := range effectiveSearchableFields {
 			if fieldValue, ok := ch.doc[searchableFieldName]; ok {
-				var textContent string
-				switch v := fieldValue.(type) {
-				case string:
-					textContent = v
-				case []interface{}:
-					var parts []string
-					for _, item := range v {
-						if strItem, isStr := item.(string); isStr {
-							parts = append(parts, strItem)
-						}
-					}
-					textContent = strings.Join(parts, " ")
-				case []string:
-					textContent = strings.Join(v, " ")
-				}
-				if textContent != "" {
-					docFullWordsByField[searchableFieldName] = tokenizer.Tokenize(textContent)
+				if textContent := fieldText(fieldValue); textContent != "" {
+					docFullWordsByField[searchableFieldName] = s.analyzer.IndexedWords(textContent, searchableFieldName)
 				}
 			}
 		}
@@ -506,173 +832,260 @@ func (s *Service) Search(query services.SearchQuery) (services.SearchResult, err
 			NumTypos:         numTyposForHit,
 			NumberExactWords: numberExactWordsForHit,
 			FilterScore:      ch.filterScore,
+			MatchedTokens:    ch.matchedTokens,
 		}
 
+		// Filter scores are blended into relevance so that documents matching more scored filter
+		// conditions rank higher under ~score, not only under ~filters
+		score := ch.score + s.settings.FilterScoreWeight*ch.filterScore
+		if scorer != nil {
+			score = scorer.Score(scoringCtx, services.ScoringCandidate{
+				Document:     ch.doc,
+				MatchedTerms: matchedTermsResult,
+				BaseScore:    score,
+				Info:         hitInfo,
+			})
+		}
+		score = s.boostScore(ch.doc, score, query.Boosts, query.FilterLocale)
+
+		reportedMatches, omittedMatches := reportFieldMatches(matchedTermsResult, query.FieldsToReport, query.MaxMatchesPerField)
+		// Documents keep all their fields for ranking and deduplication; retrievable fields are
+		// applied to the requested page only
 		finalSelectHits = append(finalSelectHits, services.HitResult{
-			Document:     s.filterDocumentFields(ch.doc, query.RetrievableFields),
-			Score:        ch.score,
-			FieldMatches: matchedTermsResult,
-			Info:         hitInfo,
+			Document:            ch.doc,
+			Score:               score,
+			FieldMatches:        reportedMatches,
+			Info:                hitInfo,
+			OmittedFieldMatches: omittedMatches,
 		})
 	}
 
-	// Sort finalSelectHits: Apply ranking criteria first, then by calculated score if no ranking criteria or as fallback
-	sort.SliceStable(finalSelectHits, func(i, j int) bool {
-		itemI := finalSelectHits[i]
-		itemJ := finalSelectHits[j]
-
-		docI := itemI.Document
-		docJ := itemJ.Document
-
-		// Apply ranking criteria first
-		for _, criterion := range s.settings.RankingCriteria {
-			// Special case: ~score means use the calculated search relevance score
-			if criterion.Field == "~score" {
-				if itemI.Score != itemJ.Score {
-					if criterion.Order == "asc" {
-						return itemI.Score < itemJ.Score
-					} else {
-						return itemI.Score > itemJ.Score
-					}
-				}
-				continue // If scores are equal, continue to next criterion
-			}
-
-			// Special case: ~filters means use the filter matching score
-			if criterion.Field == "~filters" {
-				filterScoreI := itemI.Info.FilterScore
-				filterScoreJ := itemJ.Info.FilterScore
-				if filterScoreI != filterScoreJ {
-					if criterion.Order == "asc" {
-						return filterScoreI < filterScoreJ
-					} else {
-						return filterScoreI > filterScoreJ
-					}
-				}
-				continue // If filter scores are equal, continue to next criterion
-			}
-
-			valI, okI := docI[criterion.Field]
-			valJ, okJ := docJ[criterion.Field]
+	// Boost actions of merchandising rules change scores, so they run before ranking
+	boostedRules := rules.Boost(match.rules, finalSelectHits)
+
+	// Sort finalSelectHits: Apply ranking criteria first, then by calculated score if no ranking criteria or as fallback.
+	// Positions are sorted so the hits keep their sort keys.
+	items := make([]rankItem, len(finalSelectHits))
+	for i, hit := range finalSelectHits {
+		items[i] = rankItem{score: hit.Score, filterScore: hit.Info.FilterScore, doc: hit.Document}
+		items[i].id, _ = hit.Document.GetDocumentID()
+		if hitSortKeys != nil {
+			items[i].keys = hitSortKeys[i]
+		}
+	}
+	before := func(a, b int) bool {
+		return s.ranksBefore(items[a], items[b], sortablePositions)
+	}
 
-			if !okI && !okJ {
-				continue
-			}
-			if okI && !okJ {
-				return criterion.Order != "asc"
-			}
-			if !okI && okJ {
-				return criterion.Order == "asc"
-			}
-
-			switch vI := valI.(type) {
-			case string:
-				if vJ, ok := valJ.(string); ok {
-					if vI != vJ {
-						if criterion.Order == "asc" {
-							return vI < vJ
-						} else {
-							return vI > vJ
-						}
-					}
-				}
-			case float64:
-				if vJ, ok := valJ.(float64); ok {
-					if vI != vJ {
-						if criterion.Order == "asc" {
-							return vI < vJ
-						} else {
-							return vI > vJ
-						}
-					}
-				}
-			case int, int8, int16, int32, int64:
-				fI, _ := convertToFloat64(vI)
-				fJ, _ := convertToFloat64(valJ)
-				if fI != fJ {
-					if criterion.Order == "asc" {
-						return fI < fJ
-					} else {
-						return fI > fJ
-					}
-				}
-			case time.Time:
-				if vJ, ok := valJ.(time.Time); ok {
-					if !vI.Equal(vJ) {
-						if criterion.Order == "asc" {
-							return vI.Before(vJ)
-						} else {
-							return vI.After(vJ)
-						}
-					}
-				}
-			default:
-				if strI, isStrI := valI.(string); isStrI {
-					if strJ, isStrJ := valJ.(string); isStrJ {
-						if criterion.Field == "ReleaseDate" { // Example specific field handling
-							tI, errI := time.Parse(time.RFC3339Nano, strI)
-							if errI != nil {
-								tI, _ = time.Parse(time.RFC3339, strI)
-							}
-							tJ, errJ := time.Parse(time.RFC3339Nano, strJ)
-							if errJ != nil {
-								tJ, _ = time.Parse(time.RFC3339, strJ)
-							}
-							if tI.IsZero() || tJ.IsZero() {
-								continue
-							}
-							if !tI.Equal(tJ) {
-								if criterion.Order == "asc" {
-									return tI.Before(tJ)
-								} else {
-									return tI.After(tJ)
-								}
-							}
-						}
-					}
-				}
-				continue
+	// A cursor page only ranks the hits after the cursor, and of them only as many as the page
+	// needs. Deduplication and rules need the whole ranked list, which is then sorted as usual.
+	cursorPaging := after != nil && s.settings.DistinctField == "" && len(match.rules) == 0
+	var cursorHits []services.HitResult
+	hasMore := false
+	if cursorPaging {
+		var positions []int
+		for i := range items {
+			if s.ranksBefore(*after, items[i], sortablePositions) {
+				positions = append(positions, i)
 			}
 		}
-
-		// Fallback: if no ranking criteria resolved the comparison, sort by search score descending
-		if itemI.Score != itemJ.Score {
-			return itemI.Score > itemJ.Score
+		top := selectRanked(positions, pageSize+1, before)
+		hasMore = len(top) > pageSize
+		cursorHits = make([]services.HitResult, 0, min(len(top), pageSize))
+		for _, position := range top[:min(len(top), pageSize)] {
+			cursorHits = append(cursorHits, finalSelectHits[position])
 		}
-
-		return false
-	})
+	} else {
+		order := make([]int, len(finalSelectHits))
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(i, j int) bool {
+			return before(order[i], order[j])
+		})
+		rankedHits := make([]services.HitResult, len(order))
+		for i, position := range order {
+			rankedHits[i] = finalSelectHits[position]
+		}
+		finalSelectHits = rankedHits
+	}
 
 	// Apply deduplication if DistinctField is specified
 	if s.settings.DistinctField != "" {
 		finalSelectHits = s.deduplicateResults(finalSelectHits, s.settings.DistinctField)
 	}
 
+	// Apply merchandising rules to the full ranked list so pins and hides are consistent across pages
+	finalSelectHits, appliedRules := s.applyRules(match.rules, query, finalSelectHits)
+	appliedRules = slices.Concat(match.applied, boostedRules, appliedRules)
+	rankSpan.SetAttributes(attribute.Int("hits", len(finalSelectHits)))
+	rankSpan.End()
+
 	totalHits := len(finalSelectHits)
-	startIndex := (page - 1) * pageSize
-	endIndex := startIndex + pageSize
-	var paginatedHits []services.HitResult
-	if startIndex < totalHits {
-		if endIndex > totalHits {
-			endIndex = totalHits
+	typoCounts.searches = 1
+	typoCounts.totalHits = int64(totalHits)
+	for _, hit := range finalSelectHits {
+		if hit.Info.NumTypos > 0 {
+			typoCounts.hitsViaTypos++
 		}
-		paginatedHits = finalSelectHits[startIndex:endIndex]
+	}
+	s.typoStats.record(typoCounts)
+
+	// Facets count the whole filtered result set, not just the requested page
+	var facets map[string]map[string]int
+	if len(query.Facets) > 0 {
+		facets = s.computeFacets(finalSelectHits, query.Facets)
+	}
+	// Counts of a sampled search are estimated for all candidates; hits come from the sample
+	total := totalHits
+	var sample float64
+	if sampled {
+		total = extrapolate(totalHits, query.Sample)
+		extrapolateFacets(facets, query.Sample)
+		sample = query.Sample
+	}
+
+	var paginatedHits []services.HitResult
+	if cursorPaging {
+		paginatedHits = cursorHits
 	} else {
+		startIndex := (page - 1) * pageSize
+		if after != nil {
+			startIndex = s.cursorPosition(finalSelectHits, *after, sortablePositions)
+		}
+		endIndex := startIndex + pageSize
+		if startIndex < totalHits {
+			if endIndex > totalHits {
+				endIndex = totalHits
+			}
+			paginatedHits = finalSelectHits[startIndex:endIndex]
+		} else {
+			paginatedHits = []services.HitResult{}
+		}
+		hasMore = endIndex < totalHits
+	}
+	// Hits of sampled searches depend on the sample, so they have no cursor to resume
+	var nextCursor string
+	if hasMore && !sampled && len(paginatedHits) > 0 {
+		nextCursor = s.encodeCursor(paginatedHits[len(paginatedHits)-1])
+	}
+
+	// IDs-only searches leave documents out, along with the per-hit details
+	var hitRefs []services.HitRef
+	if query.IDsOnly {
+		hitRefs = s.hitRefs(paginatedHits)
 		paginatedHits = []services.HitResult{}
 	}
+	for i := range paginatedHits {
+		paginatedHits[i].Document = s.filterDocumentFields(paginatedHits[i].Document, query.RetrievableFields)
+	}
+
+	var normalizedQuery string
+	if query.NormalizedPreview {
+		s.addNormalizedPreview(paginatedHits, effectiveSearchableFields)
+		normalizedQuery = strings.Join(originalQueryTokens, " ")
+	}
+	if query.ExplainFilters && query.Filters != nil {
+		s.addMatchedFilters(paginatedHits, *query.Filters, query.FilterLocale)
+	}
 
 	queryUUID := uuid.New().String()
 
 	return services.SearchResult{
-		Hits:     paginatedHits,
-		Total:    totalHits,
-		Page:     page,
-		PageSize: pageSize,
-		Took:     time.Since(startTime).Milliseconds(),
-		QueryId:  queryUUID,
+		Hits:            paginatedHits,
+		HitRefs:         hitRefs,
+		Total:           total,
+		Page:            page,
+		PageSize:        pageSize,
+		Took:            time.Since(startTime).Milliseconds(),
+		QueryId:         queryUUID,
+		AppliedRules:    appliedRules,
+		NormalizedQuery: normalizedQuery,
+		Facets:          facets,
+		Sample:          sample,
+		NextCursor:      nextCursor,
+		Warnings:        warnings,
 	}, nil
 }
 
+// hitRefs returns the ID, score and sort keys of each hit. Sort keys of document fields are read
+// from the stored document, so fields left out by RetrievableFields are included as well.
+func (s *Service) hitRefs(hits []services.HitResult) []services.HitRef {
+	refs := make([]services.HitRef, 0, len(hits))
+	for _, hit := range hits {
+		documentID, _ := hit.Document.GetDocumentID()
+		ref := services.HitRef{ID: documentID, Score: hit.Score}
+		if len(s.settings.RankingCriteria) > 0 {
+			var doc model.Document
+			if internalID, found := s.documentStore.Lookup(documentID); found {
+				doc, _ = s.documentStore.Get(internalID)
+			}
+			ref.SortKeys = make([]interface{}, 0, len(s.settings.RankingCriteria))
+			for _, criterion := range s.settings.RankingCriteria {
+				switch criterion.Field {
+				case "~score":
+					ref.SortKeys = append(ref.SortKeys, hit.Score)
+				case "~filters":
+					ref.SortKeys = append(ref.SortKeys, hit.Info.FilterScore)
+				default:
+					if criterion.Selector == "" {
+						ref.SortKeys = append(ref.SortKeys, doc[criterion.Field])
+					} else if selected, ok := selectRankingValue(doc[criterion.Field], criterion.Selector); ok {
+						ref.SortKeys = append(ref.SortKeys, selected)
+					} else {
+						ref.SortKeys = append(ref.SortKeys, nil)
+					}
+				}
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// addNormalizedPreview sets the normalized text of the searchable fields on each hit, taken from
+// the stored document so that fields left out by RetrievableFields are previewed as well.
+func (s *Service) addNormalizedPreview(hits []services.HitResult, searchableFields []string) {
+	for i := range hits {
+		documentID, _ := hits[i].Document.GetDocumentID()
+		internalID, found := s.documentStore.Lookup(documentID)
+		if !found {
+			continue
+		}
+		doc, found := s.documentStore.Get(internalID)
+		if !found {
+			continue
+		}
+		normalized := make(map[string]string)
+		for _, fieldName := range searchableFields {
+			if text := fieldText(doc[fieldName]); text != "" {
+				normalized[fieldName] = strings.Join(s.analyzer.FieldWords(text, fieldName), " ")
+			}
+		}
+		hits[i].Normalized = normalized
+	}
+}
+
+// fieldText returns the searchable text of a field value: a string, or the strings of an array
+// joined by spaces. Other values have no searchable text.
+func fieldText(fieldValue interface{}) string {
+	switch v := fieldValue.(type) {
+	case string:
+		return v
+	case []interface{}:
+		var parts []string
+		for _, item := range v {
+			if strItem, isStr := item.(string); isStr {
+				parts = append(parts, strItem)
+			}
+		}
+		return strings.Join(parts, " ")
+	case []string:
+		return strings.Join(v, " ")
+	}
+	return ""
+}
+
 // deduplicateResults removes duplicate documents based on the specified field.
 // It keeps the first occurrence (highest scoring) of each unique field value.
 func (s *Service) deduplicateResults(hits []services.HitResult, distinctField string) []services.HitResult {
@@ -714,88 +1127,121 @@ func (s *Service) deduplicateResults(hits []services.HitResult, distinctField st
 	return deduplicated
 }
 
-// evaluateFilters evaluates a complex filter expression with AND/OR logic
-func (s *Service) evaluateFilters(doc model.Document, expr services.Filters) (bool, float64) {
-	// Handle individual filter conditions
-	conditionResults := make([]bool, len(expr.Filters))
-	conditionScores := make([]float64, len(expr.Filters))
-	for i, condition := range expr.Filters {
-		matches := s.evaluateFilterCondition(doc, condition)
-		conditionResults[i] = matches
-		if matches {
-			conditionScores[i] = condition.Score
-		}
-	}
-
-	// Handle nested groups
-	groupResults := make([]bool, len(expr.Groups))
-	groupScores := make([]float64, len(expr.Groups))
-	for i, group := range expr.Groups {
-		matches, score := s.evaluateFilters(doc, group)
-		groupResults[i] = matches
-		if matches {
-			groupScores[i] = score
-		}
-	}
-
-	// Combine all results based on operator
-	allResults := append(conditionResults, groupResults...)
-	allScores := append(conditionScores, groupScores...)
+// evaluateFilters evaluates a complex filter expression with AND/OR logic. The score of a matching
+// expression combines the scores of its matched conditions and groups as set by its aggregation,
+// multiplied by its weight. An optional expression matches every document, and only scores those
+// it would match.
+func (s *Service) evaluateFilters(doc model.Document, expr services.Filters, filterLocale string) (bool, float64) {
+	matches, score := s.matchFilters(doc, expr, filterLocale)
+	return matches || expr.Optional, score
+}
 
-	if len(allResults) == 0 {
+// matchFilters evaluates a filter expression regardless of whether it is optional itself. Its
+// optional conditions and groups never decide whether it matches, only add to its score.
+func (s *Service) matchFilters(doc model.Document, expr services.Filters, filterLocale string) (bool, float64) {
+	if len(expr.Filters) == 0 && len(expr.Groups) == 0 {
 		return true, 0.0 // Empty expression matches with no score
 	}
 
+	and := false
 	switch strings.ToUpper(expr.Operator) {
 	case "OR", "":
-		// OR logic: at least one condition must match
-		// Return score only from matching conditions
-		totalScore := 0.0
-		hasMatch := false
-		for i, result := range allResults {
-			if result {
-				hasMatch = true
-				totalScore += allScores[i]
-			}
+	case "AND":
+		and = true
+	default:
+		slog.Warn("unknown filter expression operator, defaulting to OR", "index", s.settings.Name, "operator", expr.Operator)
+	}
+
+	// AND logic: all required conditions must match; OR logic: at least one must, unless all are
+	// optional. Scores are only taken from matching conditions and groups.
+	matchedScores := make([]float64, 0, len(expr.Filters)+len(expr.Groups))
+	required, requiredMatched := 0, 0
+	for _, condition := range expr.Filters {
+		matches := s.evaluateFilterCondition(doc, condition, filterLocale)
+		if matches {
+			matchedScores = append(matchedScores, condition.Score)
 		}
-		if hasMatch {
-			return true, totalScore
+		if condition.Optional {
+			continue
 		}
-		return false, 0.0
-	case "AND":
-		// AND logic: all conditions must match
-		// Return score from all conditions only if all match
-		for _, result := range allResults {
-			if !result {
-				return false, 0.0
-			}
+		required++
+		if matches {
+			requiredMatched++
+		} else if and {
+			return false, 0.0
 		}
-		// All matched, sum all scores
-		totalScore := 0.0
-		for _, score := range allScores {
-			totalScore += score
+	}
+	for _, group := range expr.Groups {
+		matches, score := s.matchFilters(doc, group, filterLocale)
+		if matches {
+			matchedScores = append(matchedScores, score)
 		}
-		return true, totalScore
-	default:
-		log.Printf("Warning: Unknown filter expression operator '%s', defaulting to OR", expr.Operator)
-		// Default to OR logic
-		totalScore := 0.0
-		hasMatch := false
-		for i, result := range allResults {
-			if result {
-				hasMatch = true
-				totalScore += allScores[i]
-			}
+		if group.Optional {
+			continue
 		}
-		if hasMatch {
-			return true, totalScore
+		required++
+		if matches {
+			requiredMatched++
+		} else if and {
+			return false, 0.0
 		}
+	}
+	if !and && required > 0 && requiredMatched == 0 {
 		return false, 0.0
 	}
+	if len(matchedScores) == 0 {
+		return true, 0.0
+	}
+
+	score := aggregateFilterScores(matchedScores, expr.Aggregation)
+	if expr.Weight != 0 {
+		score *= expr.Weight
+	}
+	return true, score
+}
+
+// aggregateFilterScores combines the scores of the matched conditions and groups of a filter
+// expression. There is at least one score.
+func aggregateFilterScores(scores []float64, aggregation services.FilterAggregation) float64 {
+	switch aggregation {
+	case services.FilterAggregationMax:
+		return slices.Max(scores)
+	case services.FilterAggregationAvg:
+		total := 0.0
+		for _, score := range scores {
+			total += score
+		}
+		return total / float64(len(scores))
+	default:
+		total := 0.0
+		for _, score := range scores {
+			total += score
+		}
+		return total
+	}
+}
+
+// validateFilterScoring checks the aggregations and weights of a filter expression and its groups.
+func validateFilterScoring(expr services.Filters) error {
+	switch expr.Aggregation {
+	case "", services.FilterAggregationSum, services.FilterAggregationMax, services.FilterAggregationAvg:
+	default:
+		return errors.NewInvalidQueryError("unsupported filter aggregation '%s' (expected 'sum', 'max' or 'avg')", expr.Aggregation)
+	}
+	if expr.Weight < 0 {
+		return errors.NewInvalidQueryError("filter group weight cannot be negative")
+	}
+	for _, group := range expr.Groups {
+		if err := validateFilterScoring(group); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// evaluateFilterCondition evaluates a single filter condition
-func (s *Service) evaluateFilterCondition(doc model.Document, condition services.FilterCondition) bool {
+// evaluateFilterCondition evaluates a single filter condition. String numbers and dates are read
+// with the field's configured format and the query's filter locale (see filterValueFormats).
+func (s *Service) evaluateFilterCondition(doc model.Document, condition services.FilterCondition, filterLocale string) bool {
 	filterableFieldsMap := make(map[string]struct{})
 	for _, field := range s.settings.FilterableFields {
 		filterableFieldsMap[field] = struct{}{}
@@ -821,12 +1267,12 @@ func (s *Service) evaluateFilterCondition(doc model.Document, condition services
 	}
 
 	if _, isFilterable := filterableFieldsMap[fieldName]; !isFilterable {
-		log.Printf("Warning (Index: %s): Field '%s' in filter expression is not designated as filterable in settings, but will be evaluated.\n", s.settings.Name, fieldName)
+		slog.Debug("filtering on a field that is not filterable", "index", s.settings.Name, "field", fieldName)
 	}
 
 	docFieldValInterface, docFieldExists := doc[fieldName]
 	if !docFieldExists {
-		log.Printf("Warning (Index: %s, Field: %s): Field not found in document for filter condition. Criterion fails.\n", s.settings.Name, fieldName)
+		slog.Debug("filtered field not found in document, condition fails", "index", s.settings.Name, "field", fieldName)
 		return false
 	}
 
@@ -844,6 +1290,17 @@ func (s *Service) evaluateFilterCondition(doc model.Document, condition services
 		}
 	}
 
+	switch operator {
+	case "_exact", "_ne", "_gt", "_gte", "_lt", "_lte":
+		docFormat, filterFormat := s.filterValueFormats(fieldName, filterLocale)
+		concreteDocFieldVal = docFormat.parse(concreteDocFieldVal)
+		// String document values are compared as strings, so a filter value that merely looks like a
+		// localized number still matches them
+		if _, isString := concreteDocFieldVal.(string); !isString {
+			filterVal = filterFormat.parse(filterVal)
+		}
+	}
+
 	return applyFilterLogic(concreteDocFieldVal, operator, filterVal, fieldName, s.settings.Name)
 }
 
@@ -904,7 +1361,7 @@ func applyFilterLogic(docFieldVal interface{}, operator string, filterValue inte
 	case "_contains_any_of":
 		return applyContainsAnyOfFilter(docFieldVal, filterValue)
 	default:
-		log.Printf("Warning: Unknown filter operator '%s' for field '%s' in index '%s'. Treating as equality.", operator, fieldNameForDebug, indexNameForDebug)
+		slog.Warn("unknown filter operator, treating as equality", "index", indexNameForDebug, "field", fieldNameForDebug, "operator", operator)
 		return applyEqualityFilter(docFieldVal, filterValue)
 	}
 }
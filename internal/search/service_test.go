@@ -2,12 +2,14 @@ package search
 
 import (
 	"context"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
 	"github.com/gcbaptista/go-search-engine/internal/indexing"
 	"github.com/gcbaptista/go-search-engine/model"
 	"github.com/gcbaptista/go-search-engine/services"
@@ -31,16 +33,13 @@ func newTestIndexSettings() *config.IndexSettings {
 
 // setupTestSearchService creates a new search service with an indexing service
 // to easily add documents for testing search functionality.
-func setupTestSearchService(t *testing.T, settings *config.IndexSettings) (*Service, *indexing.Service) {
+func setupTestSearchService(t testing.TB, settings *config.IndexSettings) (*Service, *indexing.Service) {
 	t.Helper()
 	if settings == nil {
 		settings = newTestIndexSettings()
 	}
 
-	invIdx := &index.InvertedIndex{
-		Index:    make(map[string]index.PostingList),
-		Settings: settings,
-	}
+	invIdx := index.NewInvertedIndex(settings)
 	docStore := &store.DocumentStore{
 		Docs:                   make(map[uint32]model.Document),
 		ExternalIDtoInternalID: make(map[string]uint32),
@@ -731,6 +730,52 @@ func TestRestrictSearchableFields(t *testing.T) {
 	})
 }
 
+func TestExcludeSearchableFields(t *testing.T) {
+	service, indexer := setupTestSearchService(t, nil)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "doc1", "title": "Hello World", "description": "A simple program"},
+		{"documentID": "doc2", "title": "Programming Guide", "description": "Hello developers"},
+		{"documentID": "doc3", "title": "Tags Only", "tags": []string{"hello"}},
+	}))
+
+	tests := []struct {
+		name     string
+		restrict []string
+		exclude  []string
+		want     []string
+		wantErr  string
+	}{
+		{name: "excluding one field searches the others", exclude: []string{"description"}, want: []string{"doc1", "doc3"}},
+		{name: "excluding several fields", exclude: []string{"title", "tags"}, want: []string{"doc2"}},
+		{name: "exclusion applies to restricted fields", restrict: []string{"title", "description"}, exclude: []string{"title"}, want: []string{"doc2"}},
+		{name: "unknown field", exclude: []string{"invalid_field"}, wantErr: "excluded searchable field 'invalid_field' is not configured"},
+		{name: "no field left", restrict: []string{"title"}, exclude: []string{"title"}, wantErr: "leave no field to search in"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Search(services.SearchQuery{
+				QueryString:              "hello",
+				RestrictSearchableFields: tt.restrict,
+				ExcludeSearchableFields:  tt.exclude,
+			})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			var ids []string
+			for _, hit := range result.Hits {
+				id, _ := hit.Document.GetDocumentID()
+				ids = append(ids, id)
+				for fieldName := range hit.FieldMatches {
+					assert.NotContains(t, tt.exclude, fieldName, "Excluded fields are not reported as matches")
+				}
+			}
+			assert.ElementsMatch(t, tt.want, ids)
+		})
+	}
+}
+
 // TestRetrievableFields tests the functionality of limiting returned document fields
 func TestRetrievableFields(t *testing.T) {
 	docID1 := "test_movie_1"
@@ -893,6 +938,37 @@ func TestRetrievableFields(t *testing.T) {
 			t.Errorf("Expected document to have 2 fields, but got %d", len(hit.Document))
 		}
 	})
+
+	t.Run("default_retrievable_fields used when none are requested", func(t *testing.T) {
+		settings := newTestIndexSettings()
+		settings.DefaultRetrievableFields = []string{"title", "year"}
+		service, indexer := setupTestSearchService(t, settings)
+		if err := indexer.AddDocuments([]model.Document{doc1, doc2}); err != nil {
+			t.Fatalf("Failed to add documents: %v", err)
+		}
+
+		result, err := service.Search(services.SearchQuery{QueryString: "Matrix"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(result.Hits) != 2 {
+			t.Fatalf("Expected 2 hits, got %d", len(result.Hits))
+		}
+		for _, hit := range result.Hits {
+			if hit.Document["documentID"] == docID1 {
+				assert.Equal(t, model.Document{"documentID": docID1, "title": "The Matrix", "year": 1999}, hit.Document)
+			}
+		}
+
+		// Requested fields replace the defaults, so heavy fields can still be retrieved
+		result, err = service.Search(services.SearchQuery{QueryString: "Matrix", RetrievableFields: []string{"description"}})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if _, exists := result.Hits[0].Document["description"]; !exists || len(result.Hits[0].Document) != 2 {
+			t.Errorf("Document = %v, want documentID and description", result.Hits[0].Document)
+		}
+	})
 }
 
 // createTestService creates a test service with the given documents
@@ -917,10 +993,7 @@ func createTestService(t *testing.T, docs []model.Document) *Service {
 		NextID:                 0,
 	}
 
-	invIndex := &index.InvertedIndex{
-		Index:    make(map[string]index.PostingList),
-		Settings: settings,
-	}
+	invIndex := index.NewInvertedIndex(settings)
 
 	indexerService, err := indexing.NewService(invIndex, docStore)
 	if err != nil {
@@ -938,9 +1011,6 @@ func createTestService(t *testing.T, docs []model.Document) *Service {
 		t.Fatalf("Failed to add documents: %v", err)
 	}
 
-	// Update typo finder after indexing
-	searchService.UpdateTypoFinder()
-
 	return searchService
 }
 
@@ -1119,6 +1189,49 @@ func TestMultiSearch(t *testing.T) {
 			t.Errorf("Expected 1 query result, got %d", len(result.Results))
 		}
 	})
+
+	t.Run("global deduplication", func(t *testing.T) {
+		multiQuery := services.MultiSearchQuery{
+			Queries: []services.NamedSearchQuery{
+				{Name: "go_search", Query: "go"},
+				{Name: "programming_search", Query: "programming"},
+			},
+			PageSize:    1,
+			Deduplicate: true,
+		}
+
+		result, err := service.MultiSearch(context.Background(), multiQuery)
+		if err != nil {
+			t.Fatalf("MultiSearch with deduplication failed: %v", err)
+		}
+
+		goResults := result.Results["go_search"]
+		if goResults.Total != 2 || len(goResults.Hits) != 1 || goResults.DuplicatesRemoved != 0 {
+			t.Errorf("Expected the first query to keep its 2 hits on pages of 1, got total=%d, hits=%d, removed=%d",
+				goResults.Total, len(goResults.Hits), goResults.DuplicatesRemoved)
+		}
+		programmingResults := result.Results["programming_search"]
+		if programmingResults.Total != 1 || programmingResults.DuplicatesRemoved != 1 {
+			t.Fatalf("Expected doc1 to be removed from the second query, got total=%d, removed=%d",
+				programmingResults.Total, programmingResults.DuplicatesRemoved)
+		}
+		if id, _ := programmingResults.Hits[0].Document.GetDocumentID(); id != "doc2" {
+			t.Errorf("Expected doc2 in the second query, got %s", id)
+		}
+
+		// Later pages are taken from the deduplicated hits
+		multiQuery.Page = 2
+		result, err = service.MultiSearch(context.Background(), multiQuery)
+		if err != nil {
+			t.Fatalf("MultiSearch with deduplication failed: %v", err)
+		}
+		if hits := result.Results["programming_search"].Hits; len(hits) != 0 {
+			t.Errorf("Expected no hits on page 2 of the second query, got %d", len(hits))
+		}
+		if hits := result.Results["go_search"].Hits; len(hits) != 1 {
+			t.Errorf("Expected 1 hit on page 2 of the first query, got %d", len(hits))
+		}
+	})
 }
 
 func TestNonTypoTolerantWords(t *testing.T) {
@@ -1158,6 +1271,10 @@ func TestNonTypoTolerantWords(t *testing.T) {
 	service.settings.MinWordSizeFor1Typo = 3
 	service.settings.MinWordSizeFor2Typos = 6
 
+	// Non-typo tolerant words are precompiled when the service is built from settings
+	service, err := NewService(service.invertedIndex, service.documentStore, service.settings)
+	assert.NoError(t, err)
+
 	// Test 1: Search for exact "hitler" should work (exact matches always work)
 	query := services.SearchQuery{
 		QueryString: "hitler",
@@ -1909,6 +2026,54 @@ func TestRankingCriteriaPriority(t *testing.T) {
 
 // TestExactMatchesScoreHigherThanTypos verifies that documents with exact matches
 // always get higher search relevance scores than documents with typo matches
+func TestRankingSelectors(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Series", "episode_ratings": []interface{}{9.0, 2.0}},
+		{"documentID": "2", "title": "Series", "episode_ratings": []interface{}{7.0, 6.0}},
+		{"documentID": "3", "title": "Series", "episode_ratings": 8.0},
+		{"documentID": "4", "title": "Series"},
+		{"documentID": "5", "title": "Series", "episode_ratings": []interface{}{"n/a"}},
+	})
+
+	tests := []struct {
+		name      string
+		criterion config.RankingCriterion
+		wantIDs   []string
+	}{
+		{"max descending", config.RankingCriterion{Field: "episode_ratings", Order: "desc", Selector: config.RankingSelectorMax}, []string{"1", "3", "2", "4", "5"}},
+		{"min descending", config.RankingCriterion{Field: "episode_ratings", Order: "desc", Selector: config.RankingSelectorMin}, []string{"3", "2", "1", "4", "5"}},
+		{"avg ascending, missing first", config.RankingCriterion{Field: "episode_ratings", Order: "asc", Selector: config.RankingSelectorAvg}, []string{"4", "5", "1", "2", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.settings.RankingCriteria = []config.RankingCriterion{tt.criterion}
+			result, err := s.Search(services.SearchQuery{QueryString: "series", PageSize: 10})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var ids []string
+			for _, hit := range result.Hits {
+				ids = append(ids, hit.Document["documentID"].(string))
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+
+	// Cursors resume the order of the selected values
+	s.settings.RankingCriteria = []config.RankingCriterion{tests[0].criterion}
+	first, err := s.Search(services.SearchQuery{QueryString: "series", PageSize: 2})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	next, err := s.Search(services.SearchQuery{QueryString: "series", PageSize: 2, SearchAfter: first.NextCursor})
+	if err != nil {
+		t.Fatalf("Search() after cursor error = %v", err)
+	}
+	if len(next.Hits) != 2 || next.Hits[0].Document["documentID"] != "2" || next.Hits[1].Document["documentID"] != "4" {
+		t.Errorf("next page = %+v, want documents 2 and 4", next.Hits)
+	}
+}
+
 func TestExactMatchesScoreHigherThanTypos(t *testing.T) {
 	docs := []model.Document{
 		{
@@ -2374,3 +2539,1478 @@ func TestFilters(t *testing.T) {
 	})
 
 }
+
+func TestFilterScoreWeight(t *testing.T) {
+	docs := []model.Document{
+		{"documentID": "plain", "title": "Movie", "genre": "Drama", "popularity": 9.0},
+		{"documentID": "boosted", "title": "Movie", "genre": "Action", "popularity": 1.0},
+	}
+	orFilters := &services.Filters{
+		Operator: "OR",
+		Filters: []services.FilterCondition{
+			{Field: "genre", Value: "Action", Score: 3.0},
+			{Field: "genre", Value: "Drama"},
+		},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		weight   float64
+		wantTop  string
+		wantBest float64
+	}{
+		{"filter scores do not affect relevance by default", 0, "plain", 1.0},
+		{"weighted filter scores are added to relevance", 0.5, "boosted", 2.5},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &config.IndexSettings{
+				Name:              "filter_score_weight_test",
+				SearchableFields:  []string{"title"},
+				FilterableFields:  []string{"genre"},
+				RankingCriteria:   []config.RankingCriterion{{Field: "~score", Order: "desc"}, {Field: "popularity", Order: "desc"}},
+				FilterScoreWeight: tt.weight,
+			}
+			service, indexer := setupTestSearchService(t, settings)
+			assert.NoError(t, indexer.AddDocuments(docs))
+
+			result, err := service.Search(services.SearchQuery{QueryString: "movie", Filters: orFilters, PageSize: 10})
+			assert.NoError(t, err)
+			if assert.Len(t, result.Hits, 2) {
+				assert.Equal(t, tt.wantTop, result.Hits[0].Document["documentID"])
+				assert.Equal(t, tt.wantBest, result.Hits[0].Score)
+			}
+		})
+	}
+}
+
+func TestFilterScoreAggregation(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "filter_score_aggregation_test",
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"genre", "year", "is_premium"},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "movie1", "title": "Movie", "genre": "Action", "year": 2023, "is_premium": true},
+		{"documentID": "movie2", "title": "Movie", "genre": "Comedy", "year": 2020, "is_premium": false},
+	}))
+
+	// Optional filters: genre and recency scored by their best match, premium weighted separately
+	optional := func(aggregation services.FilterAggregation, weight float64) *services.Filters {
+		return &services.Filters{
+			Operator: "OR",
+			Filters:  []services.FilterCondition{{Field: "is_premium", Value: true, Score: 1.0}},
+			Groups: []services.Filters{{
+				Operator: "OR",
+				Filters: []services.FilterCondition{
+					{Field: "genre", Value: "Action", Score: 2.0},
+					{Field: "genre", Value: "Comedy", Score: 1.0},
+					{Field: "year", Operator: "_gte", Value: 2022, Score: 4.0},
+				},
+				Aggregation: aggregation,
+				Weight:      weight,
+			}},
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		aggregation services.FilterAggregation
+		weight      float64
+		want        map[string]float64
+	}{
+		{"sum by default", "", 0, map[string]float64{"movie1": 1.0 + 2.0 + 4.0, "movie2": 1.0}},
+		{"max", services.FilterAggregationMax, 0, map[string]float64{"movie1": 1.0 + 4.0, "movie2": 1.0}},
+		{"avg", services.FilterAggregationAvg, 0, map[string]float64{"movie1": 1.0 + 3.0, "movie2": 1.0}},
+		{"weighted max", services.FilterAggregationMax, 0.5, map[string]float64{"movie1": 1.0 + 2.0, "movie2": 0.5}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Search(services.SearchQuery{QueryString: "movie", Filters: optional(tt.aggregation, tt.weight), PageSize: 10})
+			assert.NoError(t, err)
+			got := make(map[string]float64)
+			for _, hit := range result.Hits {
+				got[hit.Document["documentID"].(string)] = hit.Info.FilterScore
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid scoring is rejected", func(t *testing.T) {
+		invalid := optional("min", 0)
+		_, err := service.Search(services.SearchQuery{QueryString: "movie", Filters: invalid})
+		assert.ErrorContains(t, err, "unsupported filter aggregation 'min'")
+
+		invalid = optional(services.FilterAggregationMax, -1)
+		_, err = service.Search(services.SearchQuery{QueryString: "movie", Filters: invalid})
+		assert.ErrorContains(t, err, "weight cannot be negative")
+	})
+}
+
+func TestOptionalFilters(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "optional_filters_test",
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"genre", "year", "is_premium"},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "movie1", "title": "Movie", "genre": "Action", "year": 2023, "is_premium": true},
+		{"documentID": "movie2", "title": "Movie", "genre": "Comedy", "year": 2020, "is_premium": false},
+		{"documentID": "movie3", "title": "Movie", "genre": "Drama", "year": 2021, "is_premium": true},
+	}))
+	premium := services.FilterCondition{Field: "is_premium", Value: true, Score: 1.0, Optional: true}
+
+	for _, tt := range []struct {
+		name    string
+		filters services.Filters
+		want    map[string]float64
+	}{
+		{
+			"optional condition next to a required group",
+			services.Filters{
+				Operator: "AND",
+				Filters:  []services.FilterCondition{premium},
+				Groups: []services.Filters{{Operator: "OR", Filters: []services.FilterCondition{
+					{Field: "genre", Value: "Action"},
+					{Field: "genre", Value: "Comedy"},
+				}}},
+			},
+			map[string]float64{"movie1": 1.0, "movie2": 0},
+		},
+		{
+			"only optional conditions match every document",
+			services.Filters{Operator: "OR", Filters: []services.FilterCondition{
+				{Field: "genre", Value: "Action", Score: 2.0, Optional: true},
+				premium,
+			}},
+			map[string]float64{"movie1": 3.0, "movie2": 0, "movie3": 1.0},
+		},
+		{
+			"optional conditions do not satisfy OR",
+			services.Filters{Operator: "OR", Filters: []services.FilterCondition{
+				{Field: "genre", Value: "Action", Score: 2.0},
+				premium,
+			}},
+			map[string]float64{"movie1": 3.0},
+		},
+		{
+			"optional group",
+			services.Filters{
+				Operator: "OR",
+				Filters:  []services.FilterCondition{{Field: "genre", Value: "Comedy", Score: 1.0}, {Field: "genre", Value: "Action", Score: 1.0}},
+				Groups: []services.Filters{{Operator: "AND", Optional: true, Weight: 2, Filters: []services.FilterCondition{
+					{Field: "is_premium", Value: true, Score: 1.0},
+					{Field: "year", Operator: "_gte", Value: 2022, Score: 1.0},
+				}}},
+			},
+			map[string]float64{"movie1": 5.0, "movie2": 1.0},
+		},
+		{
+			"optional expression",
+			services.Filters{Operator: "AND", Optional: true, Filters: []services.FilterCondition{
+				{Field: "genre", Value: "Drama", Score: 2.0},
+				{Field: "is_premium", Value: true, Score: 1.0},
+			}},
+			map[string]float64{"movie1": 0, "movie2": 0, "movie3": 3.0},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Search(services.SearchQuery{QueryString: "movie", Filters: &tt.filters, PageSize: 10})
+			assert.NoError(t, err)
+			got := make(map[string]float64)
+			for _, hit := range result.Hits {
+				got[hit.Document["documentID"].(string)] = hit.Info.FilterScore
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExplainFilters(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "explain_filters_test",
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"platform", "plan", "year"},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "app1", "title": "App", "platform": "ios", "plan": "pro", "year": 2024},
+		{"documentID": "app2", "title": "App", "platform": "ios", "plan": "free", "year": 2020},
+		{"documentID": "app3", "title": "App", "platform": "android", "plan": "free", "year": 2024},
+	}))
+	filters := services.Filters{
+		Operator: "AND",
+		Filters:  []services.FilterCondition{{Field: "year", Operator: "_gte", Value: 2020}},
+		Groups: []services.Filters{{
+			ID:       "preferences",
+			Operator: "OR",
+			Filters: []services.FilterCondition{
+				{ID: "platform", Field: "platform", Value: "ios", Score: 2.0},
+				{ID: "plan", Field: "plan", Value: "pro", Score: 1.0},
+			},
+			Groups: []services.Filters{{Operator: "AND", Optional: true, Filters: []services.FilterCondition{
+				{Field: "year", Operator: "_gte", Value: 2024},
+				{Field: "plan", Value: "free"},
+			}}},
+		}},
+	}
+
+	result, err := service.Search(services.SearchQuery{QueryString: "app", Filters: &filters, RetrievableFields: []string{"title"}, ExplainFilters: true})
+	assert.NoError(t, err)
+	got := make(map[string][]string)
+	for _, hit := range result.Hits {
+		documentID, _ := hit.Document.GetDocumentID()
+		got[documentID] = hit.Info.MatchedFilters
+	}
+	assert.Equal(t, map[string][]string{
+		"app1": {"filters[0]", "preferences", "platform", "plan"},
+		"app2": {"filters[0]", "preferences", "platform"},
+	}, got)
+
+	// Conditions and groups without an ID are reported by their path, also within a named group
+	filters.Groups[0].Filters = filters.Groups[0].Filters[:1]
+	filters.Groups[0].Filters[0].Optional = true
+	result, err = service.Search(services.SearchQuery{QueryString: "app", Filters: &filters, ExplainFilters: true})
+	assert.NoError(t, err)
+	for _, hit := range result.Hits {
+		if hit.Document["documentID"] == "app3" {
+			assert.Equal(t, []string{"filters[0]", "preferences", "groups[0].groups[0]", "groups[0].groups[0].filters[0]", "groups[0].groups[0].filters[1]"}, hit.Info.MatchedFilters)
+		}
+	}
+
+	result, err = service.Search(services.SearchQuery{QueryString: "app", Filters: &filters})
+	assert.NoError(t, err)
+	for _, hit := range result.Hits {
+		assert.Nil(t, hit.Info.MatchedFilters, "Matched filters are only reported on request")
+	}
+}
+
+func TestNormalizedPreview(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "normalized_preview_test",
+		SearchableFields: []string{"title", "artist"},
+		Locale:           "de",
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Die Straße", "artist": "Müller-Lüdenscheidt"},
+	}))
+
+	result, err := service.Search(services.SearchQuery{QueryString: "STRASSE", RetrievableFields: []string{"title"}, NormalizedPreview: true})
+	assert.NoError(t, err)
+	if assert.Len(t, result.Hits, 1) {
+		hit := result.Hits[0]
+		assert.Equal(t, "Die Straße", hit.Document["title"], "Field values are returned as ingested")
+		assert.Equal(t, map[string]string{"title": "die strasse", "artist": "mueller luedenscheidt"}, hit.Normalized)
+	}
+	assert.Equal(t, "strasse", result.NormalizedQuery)
+
+	result, err = service.Search(services.SearchQuery{QueryString: "strasse"})
+	assert.NoError(t, err)
+	if assert.Len(t, result.Hits, 1) {
+		assert.Nil(t, result.Hits[0].Normalized, "The preview is only returned on request")
+	}
+	assert.Empty(t, result.NormalizedQuery)
+}
+
+func TestIDsOnly(t *testing.T) {
+	service, indexer := setupTestSearchService(t, nil)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "matrix", "popularity": 5.0},
+		{"documentID": "2", "title": "matrix", "popularity": 9.0},
+		{"documentID": "3", "title": "matrix"},
+	}))
+
+	full, err := service.Search(services.SearchQuery{QueryString: "matrix"})
+	assert.NoError(t, err)
+	result, err := service.Search(services.SearchQuery{QueryString: "matrix", RetrievableFields: []string{"title"}, IDsOnly: true})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Hits)
+	assert.Equal(t, 3, result.Total)
+	if assert.Len(t, result.HitRefs, 3) {
+		for i, ref := range result.HitRefs {
+			id, _ := full.Hits[i].Document.GetDocumentID()
+			assert.Equal(t, id, ref.ID, "Refs are ranked as the hits")
+			assert.Equal(t, full.Hits[i].Score, ref.Score)
+		}
+		// Sort keys follow the ranking criteria, ~score then popularity, even if popularity is not retrieved
+		assert.Equal(t, []interface{}{result.HitRefs[0].Score, 9.0}, result.HitRefs[0].SortKeys)
+		assert.Equal(t, []interface{}{result.HitRefs[2].Score, nil}, result.HitRefs[2].SortKeys)
+	}
+	assert.Nil(t, full.HitRefs, "Refs are only returned on request")
+
+	multi, err := service.MultiSearch(context.Background(), services.MultiSearchQuery{
+		Queries: []services.NamedSearchQuery{
+			{Name: "first", Query: "matrix", Filters: &services.Filters{Operator: "AND", Filters: []services.FilterCondition{{Field: "popularity", Operator: "_gte", Value: 9.0}}}},
+			{Name: "rest", Query: "matrix", IDsOnly: true},
+		},
+		Deduplicate: true,
+	})
+	assert.NoError(t, err)
+	rest := multi.Results["rest"]
+	assert.Empty(t, rest.Hits)
+	if assert.Len(t, rest.HitRefs, 2, "Deduplicated queries return refs of the remaining hits") {
+		assert.NotEqual(t, "2", rest.HitRefs[0].ID)
+		assert.NotEqual(t, "2", rest.HitRefs[1].ID)
+	}
+}
+
+func TestReportFieldMatches(t *testing.T) {
+	fieldMatches := map[string][]string{
+		"title":   {"cars(typo)", "lord", "rings"},
+		"content": {"lord"},
+	}
+
+	tests := []struct {
+		name           string
+		fieldsToReport []string
+		maxPerField    int
+		wantReported   map[string][]string
+		wantOmitted    map[string]int
+	}{
+		{"no limits", nil, 0, fieldMatches, nil},
+		{"exact matches are kept first", nil, 2, map[string][]string{"title": {"lord", "rings"}, "content": {"lord"}}, map[string]int{"title": 1}},
+		{"only requested fields", []string{"content"}, 0, map[string][]string{"content": {"lord"}}, nil},
+		{"both controls", []string{"title"}, 1, map[string][]string{"title": {"lord"}}, map[string]int{"title": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported, omitted := reportFieldMatches(fieldMatches, tt.fieldsToReport, tt.maxPerField)
+			assert.Equal(t, tt.wantReported, reported)
+			assert.Equal(t, tt.wantOmitted, omitted)
+		})
+	}
+}
+
+func TestSearchLimitsFieldMatches(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Lord of the Rings", "content": "The lord returns with the rings"},
+	})
+
+	result, err := s.Search(services.SearchQuery{QueryString: "lord rings", MaxMatchesPerField: 1, FieldsToReport: []string{"content"}})
+	assert.NoError(t, err)
+	if assert.Len(t, result.Hits, 1) {
+		hit := result.Hits[0]
+		assert.Equal(t, map[string][]string{"content": {"lord"}}, hit.FieldMatches)
+		assert.Equal(t, map[string]int{"content": 1}, hit.OmittedFieldMatches)
+		assert.Equal(t, 2, hit.Info.NumberExactWords, "Hit info still counts every matched term")
+	}
+}
+
+func TestSearchFacets(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "facets_test",
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"genres", "year", "available"},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Space Odyssey", "genres": []interface{}{"scifi", "drama", "scifi"}, "year": 1968.0, "available": true},
+		{"documentID": "2", "title": "Space Jam", "genres": []interface{}{"comedy"}, "year": 1996.0, "available": false},
+		{"documentID": "3", "title": "Space Cowboys", "genres": []interface{}{"drama"}, "year": 2000.0},
+		{"documentID": "4", "title": "Garden State", "genres": []interface{}{"drama"}, "year": 2004.0, "available": true},
+	}))
+
+	result, err := service.Search(services.SearchQuery{
+		QueryString:       "space",
+		PageSize:          1,
+		RetrievableFields: []string{"title"},
+		Facets:            []string{"genres", "year", "available"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result.Hits, 1)
+	assert.Equal(t, map[string]map[string]int{
+		"genres":    {"scifi": 1, "drama": 2, "comedy": 1},
+		"year":      {"1968": 1, "1996": 1, "2000": 1},
+		"available": {"true": 1, "false": 1},
+	}, result.Facets, "Facets count every matching hit, including fields that are not retrievable")
+
+	result, err = service.Search(services.SearchQuery{
+		QueryString: "space",
+		Filters:     &services.Filters{Filters: []services.FilterCondition{{Field: "genres", Value: "drama"}}},
+		Facets:      []string{"year"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]int{"year": {"1968": 1, "2000": 1}}, result.Facets, "Facets are computed after filtering")
+
+	result, err = service.Search(services.SearchQuery{QueryString: "space"})
+	assert.NoError(t, err)
+	assert.Nil(t, result.Facets, "Facets are only returned on request")
+
+	_, err = service.Search(services.SearchQuery{QueryString: "space", Facets: []string{"title"}})
+	assert.ErrorContains(t, err, "facet field 'title' is not configured as a filterable field")
+}
+
+func TestSearchSample(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "sample_test",
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"genre"},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	docs := make([]model.Document, 1000)
+	for i := range docs {
+		genre := "drama"
+		if i%4 == 0 {
+			genre = "comedy"
+		}
+		docs[i] = model.Document{"documentID": "doc" + strconv.Itoa(i), "title": "space movie", "genre": genre}
+	}
+	assert.NoError(t, indexer.AddDocuments(docs))
+
+	searchIDs := func(sample float64) map[string]bool {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: "space", Sample: sample, PageSize: len(docs)})
+		assert.NoError(t, err)
+		ids := make(map[string]bool, len(result.Hits))
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids[id] = true
+		}
+		return ids
+	}
+
+	result, err := service.Search(services.SearchQuery{QueryString: "space", Sample: 0.5, Facets: []string{"genre"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, result.Sample)
+	assert.InDelta(t, 1000, result.Total, 100, "Total is extrapolated from the sample")
+	assert.InDelta(t, 250, result.Facets["genre"]["comedy"], 60, "Facet counts are extrapolated from the sample")
+	assert.InDelta(t, 750, result.Facets["genre"]["drama"], 100, "Facet counts are extrapolated from the sample")
+
+	half, tenth := searchIDs(0.5), searchIDs(0.1)
+	assert.Equal(t, half, searchIDs(0.5), "The same documents are sampled by every query")
+	assert.Less(t, len(tenth), len(half))
+	for id := range tenth {
+		assert.True(t, half[id], "A smaller sample is a subset of a larger one")
+	}
+
+	result, err = service.Search(services.SearchQuery{QueryString: "space", Sample: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, result.Total, "A sample rate of 1 evaluates every candidate")
+	assert.Zero(t, result.Sample)
+
+	for _, sample := range []float64{-0.1, 1.5} {
+		_, err = service.Search(services.SearchQuery{QueryString: "space", Sample: sample})
+		assert.ErrorContains(t, err, "sample must be between 0 and 1")
+	}
+}
+
+func TestPhraseSearch(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:                "phrase_test",
+		SearchableFields:    []string{"title", "description"},
+		MinWordSizeFor1Typo: 4,
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Lord of the Rings", "description": "An epic journey"},
+		{"documentID": "2", "title": "Rings of Power", "description": "The lord returns"},
+		{"documentID": "3", "title": "New York Stories", "description": "A city tale"},
+		{"documentID": "4", "title": "York New", "description": "Reversed words"},
+	}))
+
+	searchIDs := func(queryString string, restrict ...string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: queryString, RestrictSearchableFields: restrict})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		restrict []string
+		want     []string
+	}{
+		{"words without quotes match anywhere", "new york", nil, []string{"3", "4"}},
+		{"phrase requires adjacent words in order", `"new york"`, nil, []string{"3"}},
+		{"phrase combined with other words", `"new york" tale`, nil, []string{"3"}},
+		{"phrase words must be in the same field", `"rings lord"`, nil, nil},
+		{"phrase words are not prefixes", `"new yor"`, nil, nil},
+		{"phrase words are not typos", `"new yrok"`, nil, nil},
+		{"proximity allows words in between", `"lord rings"~3`, nil, []string{"1"}},
+		{"proximity too small", `"lord rings"~2`, nil, nil},
+		{"proximity keeps the word order", `"york new"~3`, nil, []string{"4"}},
+		{"phrase in a restricted field", `"the lord"`, []string{"description"}, []string{"2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.want, searchIDs(tt.query, tt.restrict...))
+		})
+	}
+
+	for _, query := range []string{`"lord rings"~-1`, `"lord rings"~abc`, `"lord rings"~`, `"new york" "lord rings"~1.5`} {
+		_, err := service.Search(services.SearchQuery{QueryString: query})
+		assert.ErrorIs(t, err, errors.ErrInvalidInput, "Malformed proximity distance in %s", query)
+	}
+
+	// Postings indexed before positions were stored are checked against the stored documents
+	postings, _ := service.invertedIndex.Get("york")
+	legacy := make(index.PostingList, len(postings))
+	for i, entry := range postings {
+		entry.Positions = nil
+		legacy[i] = entry
+	}
+	service.invertedIndex.Set("york", legacy)
+	assert.ElementsMatch(t, []string{"3"}, searchIDs(`"new york"`))
+
+	settings.ZeroResultFallbacks = []config.FallbackStrategy{config.FallbackMatchAny}
+	// "lord" is one typo away from "word", a prefix of "words" in document 4
+	assert.ElementsMatch(t, []string{"1", "2", "4"}, searchIDs(`"rings lord"`), "Fallbacks drop the phrase")
+}
+
+func TestStopWords(t *testing.T) {
+	documents := []model.Document{
+		{"documentID": "1", "title": "The Office"},
+		{"documentID": "2", "title": "The Matrix"},
+		{"documentID": "3", "title": "Office Space"},
+		{"documentID": "4", "title": "Office of the Future"},
+	}
+	searchIDs := func(service *Service, queryString string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: queryString})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "stop_words_test",
+		SearchableFields: []string{"title"},
+		StopWords:        &config.StopWords{},
+	})
+	assert.NoError(t, indexer.AddDocuments(documents))
+
+	postings, _ := service.invertedIndex.Get("the")
+	for _, entry := range postings {
+		assert.False(t, entry.IsFullWord, "stop words should not be indexed as words")
+	}
+	assert.ElementsMatch(t, []string{"1", "3", "4"}, searchIDs(service, "the office"))
+	assert.ElementsMatch(t, []string{"1", "3", "4"}, searchIDs(service, `"the office"`))
+	assert.ElementsMatch(t, []string{"4"}, searchIDs(service, `"office future"`), "positions skip removed stop words")
+	assert.Empty(t, searchIDs(service, "the"), "stop words are not indexed")
+
+	service, indexer = setupTestSearchService(t, &config.IndexSettings{
+		Name:             "stop_words_phrases_test",
+		SearchableFields: []string{"title"},
+		StopWords:        &config.StopWords{KeepInPhrases: true},
+	})
+	assert.NoError(t, indexer.AddDocuments(documents))
+
+	assert.ElementsMatch(t, []string{"1", "3", "4"}, searchIDs(service, "the office"))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(service, `"the office"`))
+	assert.ElementsMatch(t, []string{"4"}, searchIDs(service, `"office of the future"`))
+	assert.ElementsMatch(t, []string{"1", "2", "4"}, searchIDs(service, "the"), "queries of stop words only are searched as is")
+}
+
+func TestSettingsRewriters(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "settings_rewriters_test",
+		SearchableFields: []string{"title"},
+		StopWords:        &config.StopWords{},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Television Classics"},
+		{"documentID": "2", "title": "The Matrix"},
+	}))
+	searchIDs := func(service *Service, queryString string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: queryString})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.Empty(t, searchIDs(service, "tv"))
+	assert.Empty(t, searchIDs(service, "matrx"), "typos are disabled")
+
+	// Settings are read when the service is created, as the engine recreates it on settings updates
+	settings.Synonyms = map[string]string{"tv": "the television"}
+	settings.SpellCorrection = &config.SpellCorrection{MaxDistance: 1}
+	service, err := NewService(service.invertedIndex, service.documentStore, settings)
+	assert.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(service, "tv"), "stop words of synonyms are dropped")
+	assert.ElementsMatch(t, []string{"2"}, searchIDs(service, "matrx"))
+	assert.Empty(t, searchIDs(service, "mtrx"), "corrections are limited to max_distance")
+}
+
+func TestCompoundWords(t *testing.T) {
+	documents := []model.Document{
+		{"documentID": "1", "title": "Sci-Fi Classics"},
+		{"documentID": "2", "title": "Scifi Weekly"},
+		{"documentID": "3", "title": "Sci Fi Channel"},
+		{"documentID": "4", "title": "Don't Look Up"},
+	}
+	searchIDs := func(service *Service, query services.SearchQuery) []string {
+		t.Helper()
+		result, err := service.Search(query)
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:                  "compound_words_test",
+		SearchableFields:      []string{"title"},
+		NoTypoToleranceFields: []string{"title"},
+		CompoundWords:         true,
+	})
+	assert.NoError(t, indexer.AddDocuments(documents))
+
+	postings, _ := service.invertedIndex.Get("scifi")
+	for _, entry := range postings {
+		assert.True(t, entry.IsFullWord, "joined hyphenated words are indexed as words")
+	}
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs(service, services.SearchQuery{QueryString: "scifi"}))
+	assert.ElementsMatch(t, []string{"1", "3"}, searchIDs(service, services.SearchQuery{QueryString: "sci-fi"}))
+	assert.ElementsMatch(t, []string{"1", "3"}, searchIDs(service, services.SearchQuery{QueryString: "sci fi"}))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(service, services.SearchQuery{QueryString: `"sci-fi classics"`}), "parts keep their positions")
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs(service, services.SearchQuery{Tokens: []services.QueryToken{{Token: "scifi", Mode: services.TokenMatchExact}}}))
+	assert.ElementsMatch(t, []string{"4"}, searchIDs(service, services.SearchQuery{QueryString: "don't"}))
+	assert.ElementsMatch(t, []string{"4"}, searchIDs(service, services.SearchQuery{QueryString: "dont look"}))
+
+	service, indexer = setupTestSearchService(t, &config.IndexSettings{
+		Name:                  "compound_words_disabled_test",
+		SearchableFields:      []string{"title"},
+		NoTypoToleranceFields: []string{"title"},
+	})
+	assert.NoError(t, indexer.AddDocuments(documents))
+
+	assert.ElementsMatch(t, []string{"2"}, searchIDs(service, services.SearchQuery{QueryString: "scifi"}))
+	assert.Empty(t, searchIDs(service, services.SearchQuery{QueryString: "dont"}))
+}
+
+func TestWordCharacters(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:                  "word_characters_test",
+		SearchableFields:      []string{"path"},
+		NoTypoToleranceFields: []string{"path"},
+		WordCharacters:        "_/",
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "path": "internal/search_service.go"},
+		{"documentID": "2", "path": "internal search service"},
+	}))
+	searchIDs := func(query string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: query})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"1"}, searchIDs("internal/search_service.go"))
+	assert.Empty(t, searchIDs("search_service"), "word characters join the path into one word")
+	assert.ElementsMatch(t, []string{"1"}, searchIDs("internal/search_"), "prefixes include word characters")
+	assert.ElementsMatch(t, []string{"2"}, searchIDs("search service"), "words joined by word characters are not split")
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs("internal"))
+	assert.ElementsMatch(t, []string{"2"}, searchIDs("internal -internal/search_service"), "excluded terms are tokenized the same way")
+}
+
+func TestStemming(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "stemming_test",
+		SearchableFields: []string{"title"},
+		Locale:           "en",
+		Stemming:         true,
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Running shoes"},
+		{"documentID": "2", "title": "A long run"},
+		{"documentID": "3", "title": "Connected devices"},
+	}))
+	searchIDs := func(query string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: query})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs("run"))
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs("runs"))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs("run shoe"))
+	assert.ElementsMatch(t, []string{"3"}, searchIDs("connection"))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(`"runs shoe"`), "phrases are stemmed too")
+	assert.ElementsMatch(t, []string{"2"}, searchIDs("runs -shoe"), "excluded terms are stemmed too")
+}
+
+func TestTextNormalization(t *testing.T) {
+	documents := []model.Document{
+		{"documentID": "1", "title": "Café de Flore"},
+		{"documentID": "2", "title": "Cafe Society"},
+		{"documentID": "3", "title": "Résumé writing"},
+	}
+	searchIDs := func(service *Service, query string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: query})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:                  "fold_accents_test",
+		SearchableFields:      []string{"title"},
+		NoTypoToleranceFields: []string{"title"},
+		TextNormalization:     config.TextNormalizationFoldAccents,
+	})
+	assert.NoError(t, indexer.AddDocuments(documents))
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs(service, "cafe"))
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs(service, "CAFÉ"))
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs(service, "ｃａｆｅ"), "full-width forms match ASCII")
+	assert.ElementsMatch(t, []string{"3"}, searchIDs(service, "resume"))
+
+	service, indexer = setupTestSearchService(t, &config.IndexSettings{
+		Name:                  "keep_accents_test",
+		SearchableFields:      []string{"title"},
+		NoTypoToleranceFields: []string{"title"},
+		Locale:                "fr",
+		TextNormalization:     config.TextNormalizationKeepAccents,
+	})
+	assert.NoError(t, indexer.AddDocuments(documents))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(service, "café"))
+	assert.ElementsMatch(t, []string{"2"}, searchIDs(service, "cafe"))
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs(service, "caf"), "prefixes of accented words are whole letters")
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(service, "ｃａｆé"))
+	assert.Empty(t, searchIDs(service, "resume"))
+}
+
+func TestCJKFields(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "cjk_fields_test",
+		SearchableFields: []string{"title"},
+		CJKFields:        []string{"title"},
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "東京タワーの夜景"},
+		{"documentID": "2", "title": "京都タワー"},
+		{"documentID": "3", "title": "東京駅"},
+	}))
+	searchIDs := func(query string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: query})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"1"}, searchIDs("東京タワー"), "words inside unspaced text match")
+	assert.Equal(t, []string{"1"}, searchIDs(`"東京タワー"`))
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs("タワー"))
+	assert.ElementsMatch(t, []string{"1", "3"}, searchIDs("東"), "single characters match as prefixes")
+}
+
+func TestExcludeTerms(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "exclude_terms_test",
+		SearchableFields: []string{"title", "genre"},
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Matrix", "genre": "science fiction"},
+		{"documentID": "2", "title": "The Matrix Reloaded", "genre": "science fiction"},
+		{"documentID": "3", "title": "The Matrix Revolutions", "genre": "science fiction"},
+		{"documentID": "4", "title": "Matrix of Spider-Man", "genre": "parody"},
+	}))
+	searchIDs := func(query services.SearchQuery) []string {
+		t.Helper()
+		result, err := service.Search(query)
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"1", "3", "4"}, searchIDs(services.SearchQuery{QueryString: "matrix -reloaded"}))
+	assert.ElementsMatch(t, []string{"1", "4"}, searchIDs(services.SearchQuery{QueryString: "matrix -Reloaded -revolutions"}))
+	assert.ElementsMatch(t, []string{"1", "2", "3", "4"}, searchIDs(services.SearchQuery{QueryString: "matrix -re"}), "only whole words are excluded")
+	assert.ElementsMatch(t, []string{"4"}, searchIDs(services.SearchQuery{QueryString: "matrix -fiction"}), "terms are excluded in all searchable fields")
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, searchIDs(services.SearchQuery{QueryString: "matrix -spider-man"}))
+	assert.ElementsMatch(t, []string{"4"}, searchIDs(services.SearchQuery{QueryString: "spider-man"}), "hyphens inside words do not exclude")
+	assert.ElementsMatch(t, []string{"2"}, searchIDs(services.SearchQuery{QueryString: `"matrix reloaded" -parody`}))
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, searchIDs(services.SearchQuery{QueryString: "matrix", ExcludeTerms: []string{"parody"}}))
+	assert.ElementsMatch(t, []string{"1", "2", "3", "4"}, searchIDs(services.SearchQuery{
+		QueryString:              "matrix",
+		RestrictSearchableFields: []string{"title"},
+		ExcludeTerms:             []string{"parody"},
+	}), "terms are only excluded in the searched fields")
+}
+
+func TestMatchingStrategy(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "matching_strategy_test",
+		SearchableFields: []string{"title"},
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "red apple pie"},
+		{"documentID": "2", "title": "red apple"},
+		{"documentID": "3", "title": "red car"},
+		{"documentID": "4", "title": "blue car"},
+	}))
+	search := func(strategy services.MatchingStrategy) services.SearchResult {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: "red apple pie", MatchingStrategy: strategy})
+		assert.NoError(t, err)
+		return result
+	}
+	hitIDs := func(result services.SearchResult) []string {
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"1"}, hitIDs(search("")), "all tokens must match by default")
+	assert.Equal(t, []string{"1"}, hitIDs(search(services.MatchingStrategyAll)))
+	assert.Equal(t, []string{"1", "2"}, hitIDs(search(services.MatchingStrategyMost)), "2 of 3 tokens are most of them")
+
+	anyResult := search(services.MatchingStrategyAny)
+	assert.Equal(t, []string{"1", "2", "3"}, hitIDs(anyResult), "documents matching more tokens rank higher")
+	for i, matchedTokens := range []int{3, 2, 1} {
+		assert.Equal(t, matchedTokens, anyResult.Hits[i].Info.MatchedTokens)
+	}
+
+	_, err := service.Search(services.SearchQuery{QueryString: "red", MatchingStrategy: "some"})
+	assert.ErrorContains(t, err, "matching strategy 'some' is not one of all, any or most")
+}
+
+func TestPrefixLast(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:                      "prefix_last_test",
+		SearchableFields:          []string{"title", "isbn"},
+		FieldsWithoutPrefixSearch: []string{"title", "isbn"},
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Matrix Reloaded", "isbn": "9780"},
+		{"documentID": "2", "title": "Matrix", "isbn": "1234"},
+		{"documentID": "3", "title": "Mathematics", "isbn": "5678"},
+	}))
+	searchIDs := func(query services.SearchQuery) []string {
+		t.Helper()
+		result, err := service.Search(query)
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.Empty(t, searchIDs(services.SearchQuery{QueryString: "matri"}), "fields without prefix search only match whole words")
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs(services.SearchQuery{QueryString: "matri", PrefixLast: true}))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(services.SearchQuery{QueryString: "matrix rel", PrefixLast: true}))
+	assert.Empty(t, searchIDs(services.SearchQuery{QueryString: "rel matrix", PrefixLast: true}), "only the last token is a prefix")
+	assert.Empty(t, searchIDs(services.SearchQuery{QueryString: "matri ", PrefixLast: true}), "a trailing space ends the word")
+	assert.ElementsMatch(t, []string{"2"}, searchIDs(services.SearchQuery{QueryString: "12", PrefixLast: true}))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(services.SearchQuery{
+		Tokens:     []services.QueryToken{{Token: "matrix"}, {Token: "relo"}},
+		PrefixLast: true,
+	}))
+}
+
+func TestRequireSameField(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:                 "require_same_field_test",
+		SearchableFields:     []string{"title", "description"},
+		MinWordSizeFor1Typo:  4,
+		MinWordSizeFor2Typos: 7,
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Red Apple", "description": "A crisp fruit"},
+		{"documentID": "2", "title": "Red Car", "description": "An apple green interior"},
+		{"documentID": "3", "title": "Green Tea", "description": "Brewed with red apple slices"},
+	}))
+	searchIDs := func(query services.SearchQuery) []string {
+		t.Helper()
+		result, err := service.Search(query)
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, searchIDs(services.SearchQuery{QueryString: "red apple"}), "tokens may match in different fields by default")
+	assert.ElementsMatch(t, []string{"1", "3"}, searchIDs(services.SearchQuery{QueryString: "red apple", RequireSameField: true}))
+	assert.ElementsMatch(t, []string{"1", "3"}, searchIDs(services.SearchQuery{QueryString: "red applle", RequireSameField: true}), "typo matches count in their field")
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, searchIDs(services.SearchQuery{QueryString: "apple", RequireSameField: true}))
+
+	// With the most strategy, the tokens it requires must be in a single field
+	assert.ElementsMatch(t, []string{"1", "3"}, searchIDs(services.SearchQuery{
+		QueryString:      "red apple crisp",
+		MatchingStrategy: services.MatchingStrategyMost,
+		RequireSameField: true,
+	}))
+	assert.ElementsMatch(t, []string{"3"}, searchIDs(services.SearchQuery{
+		QueryString:      "red apple slices",
+		MatchingStrategy: services.MatchingStrategyAll,
+		RequireSameField: true,
+	}))
+}
+
+func TestNestedFields(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "nested_fields_test",
+		SearchableFields: []string{"title", "credits.cast.name"},
+		FilterableFields: []string{"details.year", "credits.cast.role"},
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Matrix", "details": map[string]interface{}{"year": 1999.0}, "credits": map[string]interface{}{"cast": []interface{}{
+			map[string]interface{}{"name": "Keanu Reeves", "role": "Neo"},
+			map[string]interface{}{"name": "Laurence Fishburne", "role": "Morpheus"},
+		}}},
+		{"documentID": "2", "title": "John Wick", "details": map[string]interface{}{"year": 2014.0}, "credits": map[string]interface{}{"cast": []interface{}{
+			map[string]interface{}{"name": "Keanu Reeves", "role": "John Wick"},
+		}}},
+		{"documentID": "3", "title": "Speed Racer", "details": map[string]interface{}{"year": 2008.0}},
+	}))
+	searchIDs := func(query services.SearchQuery) []string {
+		t.Helper()
+		result, err := service.Search(query)
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs(services.SearchQuery{QueryString: "keanu"}))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(services.SearchQuery{QueryString: "fishburne"}))
+	assert.ElementsMatch(t, []string{"2"}, searchIDs(services.SearchQuery{QueryString: "keanu", Filters: &services.Filters{
+		Operator: "AND",
+		Filters:  []services.FilterCondition{{Field: "details.year", Operator: "_gt", Value: 2000.0}},
+	}}))
+	assert.ElementsMatch(t, []string{"1"}, searchIDs(services.SearchQuery{QueryString: "keanu", Filters: &services.Filters{
+		Operator: "AND",
+		Filters:  []services.FilterCondition{{Field: "credits.cast.role", Value: "Neo"}},
+	}}), "filters match any object of an array")
+}
+
+func TestNumberTypos(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:                 "number_typos_test",
+		SearchableFields:     []string{"title"},
+		MinWordSizeFor1Typo:  4,
+		MinWordSizeFor2Typos: 7,
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "Summer of 1999"},
+		{"documentID": "2", "title": "Summer of 1990"},
+		{"documentID": "3", "title": "Summer of 1899"},
+	}))
+	searchIDs := func(query string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: query})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"1"}, searchIDs("1999"), "numbers match exactly by default")
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, searchIDs("sumer"), "words still match with typos")
+	assert.Empty(t, service.Spellcheck(model.SpellcheckRequest{Query: "1998"}).Corrections, "numbers are not spellchecked")
+
+	settings.NumberTypos = true
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, searchIDs("1999"))
+}
+
+func TestSortableFields(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "sortable_fields_test",
+		SearchableFields: []string{"title"},
+		SortableFields:   []string{"released"},
+		RankingCriteria:  []config.RankingCriterion{{Field: "released", Order: "desc"}},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	indexer.SetSortableFields(settings.SortableFields)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "tokyo", "title": "New Year Movie", "released": "2000-01-01T05:00:00+09:00"}, // 1999-12-31T20:00Z
+		{"documentID": "utc", "title": "New Year Movie", "released": "1999-12-31T22:00:00Z"},
+		{"documentID": "old", "title": "New Year Movie", "released": "1950-06-01T00:00:00Z"},
+		{"documentID": "unknown", "title": "New Year Movie"},
+	}))
+	searchIDs := func() []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: "movie"})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"utc", "tokyo", "old", "unknown"}, searchIDs(), "dates are compared as instants, missing values last")
+
+	assert.NoError(t, indexer.AddDocuments([]model.Document{{"documentID": "old", "title": "New Year Movie", "released": "2001-01-01T00:00:00Z"}}))
+	assert.Equal(t, []string{"old", "utc", "tokyo", "unknown"}, searchIDs(), "sort keys follow document updates")
+
+	settings.RankingCriteria[0].Order = "asc"
+	assert.Equal(t, []string{"unknown", "tokyo", "utc", "old"}, searchIDs())
+}
+
+func TestSearchAfter(t *testing.T) {
+	var documents []model.Document
+	for i := 0; i < 25; i++ {
+		documents = append(documents, model.Document{
+			"documentID": "doc" + strconv.Itoa(i),
+			"title":      "Space Movie",
+			"popularity": float64(i % 4), // Ties are ordered by document ID
+			"series":     "series" + strconv.Itoa(i%20),
+		})
+	}
+	pageIDs := func(result services.SearchResult) []string {
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	for _, tc := range []struct {
+		name     string
+		settings config.IndexSettings
+		hits     int
+	}{
+		{name: "field values", settings: config.IndexSettings{}, hits: 25},
+		{name: "sortable fields", settings: config.IndexSettings{SortableFields: []string{"popularity"}}, hits: 25},
+		{name: "distinct field", settings: config.IndexSettings{DistinctField: "series"}, hits: 20},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := tc.settings
+			settings.Name = "search_after_test"
+			settings.SearchableFields = []string{"title"}
+			settings.RankingCriteria = []config.RankingCriterion{{Field: "popularity", Order: "desc"}}
+			service, indexer := setupTestSearchService(t, &settings)
+			indexer.SetSortableFields(settings.SortableFields)
+			assert.NoError(t, indexer.AddDocuments(documents))
+
+			var paged, cursored []string
+			cursor := ""
+			for page := 1; ; page++ {
+				result, err := service.Search(services.SearchQuery{QueryString: "movie", Page: page, PageSize: 10})
+				assert.NoError(t, err)
+				if len(result.Hits) == 0 {
+					break
+				}
+				paged = append(paged, pageIDs(result)...)
+
+				result, err = service.Search(services.SearchQuery{QueryString: "movie", PageSize: 10, SearchAfter: cursor})
+				assert.NoError(t, err)
+				cursored = append(cursored, pageIDs(result)...)
+				cursor = result.NextCursor
+				if cursor == "" {
+					break
+				}
+			}
+			assert.Equal(t, paged, cursored, "cursors page through the same hits as page numbers")
+			assert.Len(t, cursored, tc.hits)
+		})
+	}
+
+	service, _ := setupTestSearchService(t, &config.IndexSettings{Name: "search_after_errors_test", SearchableFields: []string{"title"}})
+	_, err := service.Search(services.SearchQuery{QueryString: "movie", SearchAfter: "not a cursor"})
+	assert.Error(t, err)
+	_, err = service.Search(services.SearchQuery{QueryString: "movie", Page: 2, SearchAfter: "eyJpZCI6ImRvYzAxIn0"})
+	assert.Error(t, err)
+}
+
+func TestTypoBudget(t *testing.T) {
+	documents := []model.Document{
+		{"documentID": "1", "title": "galxy konstelation", "tags": "", "description": ""},
+		{"documentID": "2", "title": "", "tags": "galxy konstelation", "description": ""},
+		{"documentID": "3", "title": "", "tags": "", "description": "galxy konstelation"},
+		{"documentID": "4", "title": "", "tags": "", "description": "galaxy constellation"},
+	}
+	searchIDs := func(service *Service, queryString string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: queryString})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:                 "typo_budget_test",
+		SearchableFields:     []string{"title", "tags", "description"},
+		TypoBudget:           &config.TypoBudget{TwoTypoFields: 1, OneTypoFields: 1},
+		MinWordSizeFor1Typo:  4,
+		MinWordSizeFor2Typos: 7,
+	})
+	assert.NoError(t, indexer.AddDocuments(documents))
+
+	assert.ElementsMatch(t, []string{"1", "2", "4"}, searchIDs(service, "galaxy"), "fields past the budget only match exactly")
+	assert.ElementsMatch(t, []string{"1", "4"}, searchIDs(service, "constellation"), "only the top fields match with 2 typos")
+
+	service, indexer = setupTestSearchService(t, &config.IndexSettings{
+		Name:                  "no_typo_fields_test",
+		SearchableFields:      []string{"title", "tags", "description"},
+		NoTypoToleranceFields: []string{"tags"},
+		MinWordSizeFor1Typo:   4,
+		MinWordSizeFor2Typos:  7,
+	})
+	assert.NoError(t, indexer.AddDocuments(documents))
+
+	assert.ElementsMatch(t, []string{"1", "3", "4"}, searchIDs(service, "galaxy"))
+	assert.ElementsMatch(t, []string{"1", "3", "4"}, searchIDs(service, "constellation"))
+}
+
+func TestTyposFollowWrites(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:                      "typo_writes_test",
+		SearchableFields:          []string{"title"},
+		MinWordSizeFor1Typo:       4,
+		FieldsWithoutPrefixSearch: []string{"title"},
+	})
+	searchIDs := func(queryString string) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: queryString})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Matrix"},
+		{"documentID": "2", "title": "Matrix Reloaded"},
+	}))
+	assert.ElementsMatch(t, []string{"1", "2"}, searchIDs("matrx"), "Terms are matched with typos as soon as they are indexed")
+
+	assert.NoError(t, indexer.DeleteDocument("1"))
+	assert.Equal(t, []string{"matrix"}, service.invertedIndex.Typos("matrx", 1, 0), "Terms stay while documents hold them")
+
+	assert.NoError(t, indexer.DeleteDocument("2"))
+	assert.Empty(t, service.invertedIndex.Typos("matrx", 1, 0), "Terms are dropped with their last document")
+	assert.Empty(t, searchIDs("matrx"))
+}
+
+func TestScoringAlgorithm(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "scoring_algorithm_test",
+		SearchableFields: []string{"title"},
+		RankingCriteria:  []config.RankingCriterion{{Field: "~score", Order: "desc"}},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "short", "title": "matrix"},
+		{"documentID": "long", "title": "matrix reloaded revolutions animatrix collection"},
+		{"documentID": "other", "title": "inception"},
+	}))
+	scores := func(queryString string) map[string]float64 {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: queryString, MatchingStrategy: services.MatchingStrategyAny})
+		assert.NoError(t, err)
+		scores := make(map[string]float64)
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			scores[id] = hit.Score
+		}
+		return scores
+	}
+
+	tf := scores("matrix")
+	assert.Equal(t, tf["short"], tf["long"], "term frequency scoring ignores field lengths")
+
+	settings.ScoringAlgorithm = config.ScoringBM25
+	bm25 := scores("matrix")
+	assert.Greater(t, bm25["short"], bm25["long"], "BM25 favors matches in shorter fields")
+	// "reloaded" is rarer than "matrix", so matching it is worth more
+	assert.Greater(t, service.bm25.FieldScore("reloaded", 1, "title", 1), service.bm25.FieldScore("matrix", 1, "title", 1))
+}
+
+func TestFieldWeights(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "field_weights_test",
+		SearchableFields: []string{"title", "description"},
+		RankingCriteria:  []config.RankingCriterion{{Field: "~score", Order: "desc"}},
+		FieldWeights:     map[string]float64{"title": 3},
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "in_title", "title": "matrix", "description": "a film"},
+		{"documentID": "in_description", "title": "a film", "description": "matrix"},
+	}))
+	scores := func(query services.SearchQuery) map[string]float64 {
+		t.Helper()
+		result, err := service.Search(query)
+		assert.NoError(t, err)
+		scores := make(map[string]float64)
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			scores[id] = hit.Score
+		}
+		return scores
+	}
+
+	indexWeights := scores(services.SearchQuery{QueryString: "matrix"})
+	assert.Greater(t, indexWeights["in_title"], indexWeights["in_description"], "title matches are weighted by the index settings")
+
+	queryWeights := scores(services.SearchQuery{QueryString: "matrix", FieldWeights: map[string]float64{"description": 5}})
+	assert.Greater(t, queryWeights["in_description"], queryWeights["in_title"], "query weights override the index weights")
+
+	_, err := service.Search(services.SearchQuery{QueryString: "matrix", FieldWeights: map[string]float64{"year": 2}})
+	assert.ErrorIs(t, err, errors.ErrInvalidQuery)
+	_, err = service.Search(services.SearchQuery{QueryString: "matrix", FieldWeights: map[string]float64{"title": 0}})
+	assert.ErrorIs(t, err, errors.ErrInvalidQuery)
+}
+
+func TestQueryTokenDecay(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "query_token_decay_test",
+		SearchableFields: []string{"title"},
+		RankingCriteria:  []config.RankingCriterion{{Field: "~score", Order: "desc"}},
+		QueryTokenDecay:  0.5,
+	}
+	service, indexer := setupTestSearchService(t, settings)
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "first_word", "title": "leather"},
+		{"documentID": "last_word", "title": "boots"},
+		{"documentID": "both_words", "title": "leather boots"},
+	}))
+	scores := func(decay *float64) map[string]float64 {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: "leather boots", MatchingStrategy: services.MatchingStrategyAny, QueryTokenDecay: decay})
+		assert.NoError(t, err)
+		scores := make(map[string]float64)
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			scores[id] = hit.Score
+		}
+		return scores
+	}
+	decay := func(d float64) *float64 { return &d }
+
+	indexDecay := scores(nil)
+	assert.Greater(t, indexDecay["first_word"], indexDecay["last_word"], "the first query word weighs more with the index's decay")
+
+	queryDecay := scores(decay(1.5))
+	assert.Greater(t, queryDecay["last_word"], queryDecay["first_word"], "a query decay above 1 favors the last words")
+	assert.InDelta(t, indexDecay["both_words"], queryDecay["both_words"], 1e-9, "weights average 1, so matching every word scores the same")
+
+	unweighted := scores(decay(1))
+	assert.InDelta(t, unweighted["first_word"], unweighted["last_word"], 1e-9, "a decay of 1 weights every word the same")
+
+	for _, invalid := range []float64{0, -1, 3} {
+		_, err := service.Search(services.SearchQuery{QueryString: "leather boots", QueryTokenDecay: decay(invalid)})
+		assert.ErrorIs(t, err, errors.ErrInvalidQuery)
+	}
+}
+
+func TestBoosts(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "boosts_test",
+		SearchableFields: []string{"title", "description"},
+		FilterableFields: []string{"is_premium", "year"},
+		RankingCriteria:  []config.RankingCriterion{{Field: "~score", Order: "desc"}},
+		FieldWeights:     map[string]float64{"title": 2},
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "regular", "title": "matrix", "description": "a film", "is_premium": false, "year": 1999},
+		{"documentID": "premium", "title": "a film", "description": "matrix", "is_premium": true, "year": 2003},
+	}))
+	search := func(boosts ...services.BoostRule) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{QueryString: "matrix", Boosts: boosts})
+		assert.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	premium := services.Filters{Filters: []services.FilterCondition{{Field: "is_premium", Operator: "_exact", Value: true}}}
+
+	assert.Equal(t, []string{"regular", "premium"}, search(), "the title match ranks first without boosts")
+	assert.Equal(t, []string{"premium", "regular"}, search(services.BoostRule{Filter: premium, Multiplier: 3}))
+	assert.Equal(t, []string{"premium", "regular"}, search(services.BoostRule{Filter: premium, Addend: 5}))
+
+	recent := services.Filters{Filters: []services.FilterCondition{{Field: "year", Operator: "_gte", Value: 2000}}}
+	result, err := service.Search(services.SearchQuery{QueryString: "matrix", Boosts: []services.BoostRule{
+		{Filter: premium, Multiplier: 2},
+		{Filter: recent, Addend: 1},
+	}})
+	assert.NoError(t, err)
+	base, err := service.Search(services.SearchQuery{QueryString: "matrix"})
+	assert.NoError(t, err)
+	scores := func(result services.SearchResult) map[string]float64 {
+		scores := make(map[string]float64)
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			scores[id] = hit.Score
+		}
+		return scores
+	}
+	assert.Equal(t, scores(base)["premium"]*2+1, scores(result)["premium"], "boosts are applied in order")
+	assert.Equal(t, scores(base)["regular"], scores(result)["regular"], "documents matching no boost keep their score")
+
+	for _, invalid := range []services.BoostRule{
+		{Multiplier: 2},
+		{Filter: premium, Multiplier: -1},
+		{Filter: premium},
+	} {
+		_, err := service.Search(services.SearchQuery{QueryString: "matrix", Boosts: []services.BoostRule{invalid}})
+		assert.ErrorIs(t, err, errors.ErrInvalidQuery)
+	}
+}
+
+func TestFilterFormats(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "filter_formats_test",
+		SearchableFields: []string{"title"},
+		FilterableFields: []string{"released", "price", "code"},
+		FieldFormats: map[string]config.FieldFormat{
+			"released": {DateFormat: "DD/MM/YYYY"},
+			"price":    {Locale: "de"},
+		},
+	})
+	assert.NoError(t, indexer.AddDocuments([]model.Document{
+		{"documentID": "old", "title": "film", "released": "31/12/1999", "price": "1.234,50", "code": "1,5"},
+		{"documentID": "new", "title": "film", "released": "01/02/2024", "price": "99,90", "code": "2,5"},
+	}))
+	search := func(locale string, conditions ...services.FilterCondition) []string {
+		t.Helper()
+		result, err := service.Search(services.SearchQuery{
+			QueryString:  "film",
+			Filters:      &services.Filters{Operator: "AND", Filters: conditions},
+			FilterLocale: locale,
+		})
+		assert.NoError(t, err)
+		ids := []string{}
+		for _, hit := range result.Hits {
+			id, _ := hit.Document.GetDocumentID()
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"new"}, search("", services.FilterCondition{Field: "released", Operator: "_gt", Value: "31/12/2023"}))
+	assert.Equal(t, []string{"new"}, search("", services.FilterCondition{Field: "released", Operator: "_gt", Value: "2023-12-31T00:00:00Z"}))
+	assert.Equal(t, []string{"old"}, search("", services.FilterCondition{Field: "price", Operator: "_gte", Value: "1.000"}))
+	assert.Equal(t, []string{"old"}, search("", services.FilterCondition{Field: "price", Operator: "_gte", Value: 1000}))
+	assert.Equal(t, []string{"new"}, search("", services.FilterCondition{Field: "price", Operator: "_exact", Value: "99,9"}))
+
+	// The query's locale overrides the field's format for filter values
+	assert.Equal(t, []string{"old"}, search("en-US", services.FilterCondition{Field: "price", Operator: "_gte", Value: "1,000.00"}))
+	assert.Equal(t, []string{"new"}, search("en-US", services.FilterCondition{Field: "released", Operator: "_gt", Value: "12/31/2023"}))
+
+	// Values of fields without a format are still compared as strings
+	assert.Equal(t, []string{"old"}, search("de", services.FilterCondition{Field: "code", Operator: "_exact", Value: "1,5"}))
+
+	format := localeValueFormat("de")
+	for _, value := range []string{"1.23.4", "1..234", "1.234,5,6", "12a", "", "--1"} {
+		assert.Equal(t, value, format.parse(value), "%q is not a number", value)
+	}
+	assert.Equal(t, 1234567.5, format.parse("1.234.567,5"))
+	assert.Equal(t, -1234.0, format.parse("-1 234"))
+}
+
+func TestPageSizeLimits(t *testing.T) {
+	service, indexer := setupTestSearchService(t, &config.IndexSettings{
+		Name:             "page_size_test",
+		SearchableFields: []string{"title"},
+		DefaultPageSize:  2,
+		MaxPageSize:      3,
+	})
+	var documents []model.Document
+	for i := 1; i <= 5; i++ {
+		documents = append(documents, model.Document{"documentID": strconv.Itoa(i), "title": "matrix"})
+	}
+	assert.NoError(t, indexer.AddDocuments(documents))
+
+	result, err := service.Search(services.SearchQuery{QueryString: "matrix"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.PageSize, "queries without a page size use the index default")
+	assert.Len(t, result.Hits, 2)
+
+	result, err = service.Search(services.SearchQuery{QueryString: "matrix", PageSize: 3})
+	assert.NoError(t, err)
+	assert.Len(t, result.Hits, 3)
+
+	_, err = service.Search(services.SearchQuery{QueryString: "matrix", PageSize: 4})
+	assert.ErrorIs(t, err, errors.ErrInvalidQuery)
+
+	_, err = service.MultiSearch(context.Background(), services.MultiSearchQuery{
+		Queries:  []services.NamedSearchQuery{{Name: "all", Query: "matrix"}},
+		PageSize: 100,
+	})
+	assert.ErrorIs(t, err, errors.ErrInvalidQuery)
+
+	multiResult, err := service.MultiSearch(context.Background(), services.MultiSearchQuery{
+		Queries:     []services.NamedSearchQuery{{Name: "all", Query: "matrix"}, {Name: "again", Query: "matrix"}},
+		Deduplicate: true,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, multiResult.Results["all"].Hits, 2, "deduplicated multi-searches still page with the index default")
+
+	result, err = service.SearchAll(services.SearchQuery{QueryString: "matrix"})
+	assert.NoError(t, err)
+	assert.Len(t, result.Hits, 5, "SearchAll returns every hit")
+}
+
+func TestFollowingPositions(t *testing.T) {
+	assert.Equal(t, []int{8}, followingPositions([]int{5, 6}, []int{8}, 2), "Any previous position can be followed")
+	assert.Nil(t, followingPositions([]int{5}, []int{5, 4}, 3), "Positions must come after the previous word")
+	assert.Equal(t, []int{2, 3}, followingPositions([]int{1}, []int{2, 3, 5}, 2))
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/logging"
+	"github.com/gcbaptista/go-search-engine/internal/tracing"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// SetSlowQueryThreshold makes searches taking at least threshold log a warning with their query,
+// IDs and timing. 0 disables slow query logging.
+func (s *Service) SetSlowQueryThreshold(threshold time.Duration) {
+	s.extensionsMu.Lock()
+	defer s.extensionsMu.Unlock()
+	s.slowQuery = threshold
+}
+
+// logSlowQuery logs a search that took at least the slow query threshold, with the ID of the API
+// request it served so it can be matched with the request's log entry, and of its trace if traced.
+func (s *Service) logSlowQuery(query services.SearchQuery, result services.SearchResult, took time.Duration) {
+	s.extensionsMu.RLock()
+	threshold := s.slowQuery
+	s.extensionsMu.RUnlock()
+	if threshold <= 0 || took < threshold {
+		return
+	}
+
+	queryText := query.QueryString
+	if len(query.Tokens) > 0 {
+		tokens := make([]string, len(query.Tokens))
+		for i, token := range query.Tokens {
+			tokens[i] = token.Token
+		}
+		queryText = strings.Join(tokens, " ")
+	}
+	attributes := []any{
+		"index", s.settings.Name,
+		"query", queryText,
+		"query_id", result.QueryId,
+		"took_ms", took.Milliseconds(),
+		"threshold_ms", threshold.Milliseconds(),
+		"total", result.Total,
+		"page", result.Page,
+		"page_size", result.PageSize,
+		"filtered", query.Filters != nil,
+		"warnings", len(result.Warnings),
+	}
+	if traceID := tracing.TraceID(query.Context); traceID != "" {
+		attributes = append(attributes, "trace_id", traceID)
+	}
+	logging.WithRequest(query.RequestID).Warn("slow query", attributes...)
+}
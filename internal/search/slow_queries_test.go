@@ -0,0 +1,42 @@
+package search
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/internal/logging"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestSlowQueryLogging(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	if err := logging.Setup(&buf, "info", logging.FormatText); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	s := createTestService(t, []model.Document{{"documentID": "1", "title": "Running Shoes"}})
+
+	if _, err := s.Search(services.SearchQuery{QueryString: "shoes", PageSize: 10}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "slow query") {
+		t.Fatalf("slow query logged without a threshold: %q", buf.String())
+	}
+
+	s.SetSlowQueryThreshold(time.Nanosecond)
+	result, err := s.Search(services.SearchQuery{QueryString: "shoes", PageSize: 10, RequestID: "req-7"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"slow query", "request_id=req-7", "query_id=" + result.QueryId, "query=shoes", "total=1"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("log output %q lacks %q", output, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gcbaptista/go-search-engine/index"
+	"github.com/gcbaptista/go-search-engine/internal/typoutil"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// Spellcheck suggests corrections for the query tokens that are not in the index, without running
+// a search. Tokens are corrected under the same rules as typo matching: short tokens,
+// non-typo-tolerant words and, unless number_typos is set, numbers are left alone. Candidates are indexed whole words within the allowed
+// edit distance; the closest one wins, and among equally close ones the most frequent.
+//
+// The confidence of a correction is its share of all candidates, each weighted by its frequency
+// and divided by the square of its edit distance, so a frequent word one typo away from the token
+// gets a confidence close to 1.
+//
+// With filters, only the words of the documents matching them count: tokens found only in other
+// documents are corrected, and corrections come from the matching documents.
+func (s *Service) Spellcheck(request model.SpellcheckRequest) model.SpellcheckResult {
+	result := model.SpellcheckResult{Query: request.Query, Corrections: []model.SpellcheckCorrection{}}
+	tokens := s.analyzer.TokenizeForFields(request.Query, nil)
+	var matching map[uint32]struct{}
+	if len(request.Filters) > 0 {
+		matching = s.matchingDocuments(request.Filters)
+	}
+
+	s.invertedIndex.Mu.RLock()
+	defer s.invertedIndex.Mu.RUnlock()
+
+	corrected := make([]string, len(tokens))
+	for i, token := range tokens {
+		corrected[i] = token
+		if correction, found := s.correctToken(token, matching); found {
+			corrected[i] = correction.Suggestion
+			result.Corrections = append(result.Corrections, correction)
+		}
+	}
+	if len(result.Corrections) > 0 {
+		result.CorrectedQuery = strings.Join(corrected, " ")
+	}
+	return result
+}
+
+// correctToken returns the best correction for a token, from the words of the matching documents
+// unless matching is nil. The caller must hold the inverted index read lock.
+func (s *Service) correctToken(token string, matching map[uint32]struct{}) (model.SpellcheckCorrection, bool) {
+	if postings, indexed := s.invertedIndex.Get(token); (indexed && hasMatchingPosting(postings, matching)) || s.protected.Contains(token) {
+		return model.SpellcheckCorrection{}, false
+	}
+	if !s.settings.NumberTypos && isNumber(token) {
+		return model.SpellcheckCorrection{}, false
+	}
+	maxDistance, size := 0, utf8.RuneCountInString(token)
+	if s.settings.MinWordSizeFor2Typos > 0 && size >= s.settings.MinWordSizeFor2Typos {
+		maxDistance = 2
+	} else if s.settings.MinWordSizeFor1Typo > 0 && size >= s.settings.MinWordSizeFor1Typo {
+		maxDistance = 1
+	}
+	if maxDistance == 0 {
+		return model.SpellcheckCorrection{}, false
+	}
+
+	var best model.SpellcheckCorrection
+	totalWeight, bestWeight := 0.0, 0.0
+	for _, candidate := range s.invertedIndex.Typos(token, maxDistance, 500) {
+		postings, _ := s.invertedIndex.Get(candidate)
+		frequency := s.wholeWordFrequency(postings, matching)
+		if frequency == 0 {
+			continue
+		}
+		distance := typoutil.CalculateEditDistance(token, candidate, maxDistance)
+		weight := float64(frequency) / float64(distance*distance)
+		totalWeight += weight
+		if best.Suggestion == "" || distance < best.Distance || (distance == best.Distance && frequency > best.Frequency) {
+			best = model.SpellcheckCorrection{Token: token, Suggestion: candidate, Distance: distance, Frequency: frequency}
+			bestWeight = weight
+		}
+	}
+	if best.Suggestion == "" {
+		return model.SpellcheckCorrection{}, false
+	}
+	best.Confidence = math.Round(bestWeight/totalWeight*1000) / 1000
+	return best, true
+}
+
+// wholeWordFrequency counts the documents in which a term occurs as a whole word, rather than as
+// a prefix n-gram, in a field that accepts typo matches. Only the matching documents are counted
+// unless matching is nil.
+func (s *Service) wholeWordFrequency(postings []index.PostingEntry, matching map[uint32]struct{}) int {
+	documents := make(map[uint32]struct{})
+	for _, entry := range postings {
+		if entry.IsFullWord && s.fieldMaxTypos[entry.FieldName] > 0 && isMatching(entry.DocID, matching) {
+			documents[entry.DocID] = struct{}{}
+		}
+	}
+	return len(documents)
+}
+
+// hasMatchingPosting reports whether any of the postings is of a matching document; all are when
+// matching is nil.
+func hasMatchingPosting(postings []index.PostingEntry, matching map[uint32]struct{}) bool {
+	for _, entry := range postings {
+		if isMatching(entry.DocID, matching) {
+			return true
+		}
+	}
+	return matching == nil
+}
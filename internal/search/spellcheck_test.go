@@ -0,0 +1,64 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestSpellcheck(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "The Matrix"},
+		{"documentID": "2", "title": "Matrix Reloaded"},
+		{"documentID": "3", "title": "Matrox Graphics"},
+		{"documentID": "4", "title": "Interstellar"},
+	})
+
+	t.Run("corrects unknown tokens", func(t *testing.T) {
+		result := s.Spellcheck(model.SpellcheckRequest{Query: "The Matrx Interstelar"})
+		if result.CorrectedQuery != "the matrix interstellar" {
+			t.Errorf("CorrectedQuery = %q, want %q", result.CorrectedQuery, "the matrix interstellar")
+		}
+		if len(result.Corrections) != 2 {
+			t.Fatalf("Expected 2 corrections, got %+v", result.Corrections)
+		}
+		matrix := result.Corrections[0]
+		if matrix.Token != "matrx" || matrix.Suggestion != "matrix" || matrix.Distance != 1 || matrix.Frequency != 2 {
+			t.Errorf("Unexpected correction for 'matrx': %+v", matrix)
+		}
+		// "matrox" is also one typo away but in fewer documents
+		if matrix.Confidence <= 0.5 || matrix.Confidence >= 1 {
+			t.Errorf("Expected a confidence between 0.5 and 1 for 'matrix', got %v", matrix.Confidence)
+		}
+		if interstellar := result.Corrections[1]; interstellar.Suggestion != "interstellar" || interstellar.Confidence != 1 {
+			t.Errorf("Unexpected correction for 'interstelar': %+v", interstellar)
+		}
+	})
+
+	t.Run("indexed tokens and prefixes are not corrected", func(t *testing.T) {
+		result := s.Spellcheck(model.SpellcheckRequest{Query: "matrix relo"})
+		if len(result.Corrections) != 0 || result.CorrectedQuery != "" {
+			t.Errorf("Expected no corrections, got %+v", result)
+		}
+	})
+
+	t.Run("filtered to matching documents", func(t *testing.T) {
+		request := model.SpellcheckRequest{
+			Query:   "matrx interstellar",
+			Filters: []model.RuleFilter{{Field: "title", Operator: "_exact", Value: "Matrox Graphics"}},
+		}
+		result := s.Spellcheck(request)
+		if len(result.Corrections) != 1 || result.Corrections[0].Suggestion != "matrox" || result.Corrections[0].Frequency != 1 {
+			t.Fatalf("Expected only 'matrox' from the matching document, got %+v", result.Corrections)
+		}
+		if result.CorrectedQuery != "matrox interstellar" {
+			t.Errorf("CorrectedQuery = %q, want %q", result.CorrectedQuery, "matrox interstellar")
+		}
+	})
+
+	t.Run("short tokens are not corrected", func(t *testing.T) {
+		if result := s.Spellcheck(model.SpellcheckRequest{Query: "thw"}); len(result.Corrections) != 0 {
+			t.Errorf("Expected no corrections below the typo word size, got %+v", result.Corrections)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gcbaptista/go-search-engine/internal/suggest"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// suggestionRefreshInterval is the least time between rebuilds of the suggestion trie, so typeahead
+// on an index being written to doesn't rebuild it on every keystroke.
+const suggestionRefreshInterval = time.Second
+
+// suggestionCache holds the suggestion trie of the index and the index version it was built from.
+type suggestionCache struct {
+	mu      sync.Mutex // Held while the trie is rebuilt, so concurrent requests build it once
+	trie    *suggest.Trie
+	version uint64
+	builtAt time.Time
+}
+
+// Suggest completes a typed prefix from a trie dedicated to typeahead, most frequent completions
+// first, without running a search. With a suggestion field, its values are suggested, found by
+// the start of any of their words; otherwise the last typed word is completed with indexed terms.
+// The trie is rebuilt from the index once it changed, at most once per suggestionRefreshInterval,
+// so suggestions may lag writes by that long. The request is expected to be validated, with a
+// positive limit.
+func (s *Service) Suggest(request model.SuggestRequest) model.SuggestResult {
+	result := model.SuggestResult{Query: request.Query, Field: s.settings.SuggestionField, Suggestions: []model.Suggestion{}}
+	tokens := s.analyzer.Tokenize(request.Query)
+
+	var prefix, typed string
+	if s.settings.SuggestionField != "" {
+		prefix = strings.Join(tokens, " ")
+		// A finished word only completes values with more words after it
+		if len(tokens) > 0 && strings.TrimRightFunc(request.Query, unicode.IsSpace) != request.Query {
+			prefix += " "
+		}
+	} else if len(tokens) > 0 {
+		prefix = tokens[len(tokens)-1]
+		if len(tokens) > 1 {
+			typed = strings.Join(tokens[:len(tokens)-1], " ") + " "
+		}
+	}
+
+	trie := s.suggestionTrie()
+	if len(request.Filters) > 0 {
+		trie = s.filteredSuggestionTrie(s.matchingDocuments(request.Filters))
+	}
+	completions, hasMore := trie.Complete(prefix, request.Limit)
+	for _, completion := range completions {
+		result.Suggestions = append(result.Suggestions, model.Suggestion{Text: typed + completion.Text, Documents: completion.Weight})
+	}
+	result.HasMore = hasMore
+	return result
+}
+
+// suggestionTrie returns the suggestion trie, rebuilding it first when the index changed since it
+// was built and the last rebuild is older than suggestionRefreshInterval.
+func (s *Service) suggestionTrie() *suggest.Trie {
+	cache := &s.suggestions
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	version := s.invertedIndex.Version()
+	if cache.trie != nil && (cache.version == version || time.Since(cache.builtAt) < suggestionRefreshInterval) {
+		return cache.trie
+	}
+
+	s.invertedIndex.Mu.RLock()
+	// Read under the lock, so writes made while building are seen as changes next time
+	version = s.invertedIndex.Version()
+	var suggestions []suggest.Suggestion
+	if s.settings.SuggestionField != "" {
+		suggestions = s.fieldSuggestions(s.settings.SuggestionField, nil)
+	} else {
+		suggestions = s.termSuggestions(nil)
+	}
+	s.invertedIndex.Mu.RUnlock()
+
+	cache.trie = suggest.New(suggestions)
+	cache.version = version
+	cache.builtAt = time.Now()
+	return cache.trie
+}
+
+// filteredSuggestionTrie returns a trie of the suggestions of the matching documents only. It is
+// built for each request rather than cached, as each set of filters has its own suggestions.
+func (s *Service) filteredSuggestionTrie(matching map[uint32]struct{}) *suggest.Trie {
+	s.invertedIndex.Mu.RLock()
+	defer s.invertedIndex.Mu.RUnlock()
+	if s.settings.SuggestionField != "" {
+		return suggest.New(s.fieldSuggestions(s.settings.SuggestionField, matching))
+	}
+	return suggest.New(s.termSuggestions(matching))
+}
+
+// termSuggestions returns the indexed whole words, weighted by the number of documents containing
+// them, of the matching documents only unless matching is nil. The caller must hold the inverted
+// index read lock.
+func (s *Service) termSuggestions(matching map[uint32]struct{}) []suggest.Suggestion {
+	var suggestions []suggest.Suggestion
+	s.invertedIndex.RangePrefix("", func(term string, _ int) bool {
+		if documents := s.termDocuments(term, "", true, matching); documents > 0 {
+			suggestions = append(suggestions, suggest.Suggestion{Text: term, Weight: documents, Keys: []string{term}})
+		}
+		return true
+	})
+	return suggestions
+}
+
+// fieldSuggestions returns the distinct values of a field, weighted by the number of documents
+// containing them. Values are compared by their analyzed words and suggested as most often written,
+// and found by their words from any of them on. Only the matching documents are read unless
+// matching is nil.
+func (s *Service) fieldSuggestions(field string, matching map[uint32]struct{}) []suggest.Suggestion {
+	type fieldValue struct {
+		forms     map[string]int // Times each way of writing the value occurs
+		documents int
+		keys      []string
+	}
+	values := make(map[string]*fieldValue)
+	s.documentStore.Range(func(internalID uint32, doc model.Document) bool {
+		if !isMatching(internalID, matching) {
+			return true
+		}
+		counted := make(map[string]bool)
+		for _, text := range facetValues(doc[field]) {
+			words := s.analyzer.Tokenize(text)
+			if len(words) == 0 {
+				continue
+			}
+			key := strings.Join(words, " ")
+			value, found := values[key]
+			if !found {
+				value = &fieldValue{forms: make(map[string]int)}
+				for i := range words {
+					value.keys = append(value.keys, strings.Join(words[i:], " "))
+				}
+				values[key] = value
+			}
+			value.forms[strings.TrimSpace(text)]++
+			if !counted[key] {
+				counted[key] = true
+				value.documents++
+			}
+		}
+		return true
+	})
+
+	suggestions := make([]suggest.Suggestion, 0, len(values))
+	for _, value := range values {
+		text, occurrences := "", 0
+		for form, count := range value.forms {
+			if count > occurrences || (count == occurrences && form < text) {
+				text, occurrences = form, count
+			}
+		}
+		suggestions = append(suggestions, suggest.Suggestion{Text: text, Weight: value.documents, Keys: value.keys})
+	}
+	return suggestions
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestSuggest(t *testing.T) {
+	settings := &config.IndexSettings{
+		Name:             "suggest_test",
+		SearchableFields: []string{"title", "genres"},
+	}
+	s, indexer := setupTestSearchService(t, settings)
+	if err := indexer.AddDocuments([]model.Document{
+		{"documentID": "1", "title": "The Matrix", "genres": []interface{}{"Action", "Sci-Fi"}},
+		{"documentID": "2", "title": "The Matrix", "genres": []interface{}{"Action"}},
+		{"documentID": "3", "title": "Mad Max", "genres": []interface{}{"Action"}},
+		{"documentID": "4", "title": "Matilda", "genres": []interface{}{"Family"}},
+	}); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	suggestions := func(query string, limit int) ([]model.Suggestion, bool) {
+		t.Helper()
+		result := s.Suggest(model.SuggestRequest{Query: query, Limit: limit})
+		return result.Suggestions, result.HasMore
+	}
+
+	t.Run("indexed words complete the last word", func(t *testing.T) {
+		got, hasMore := suggestions("Ma", 10)
+		want := []model.Suggestion{{Text: "matrix", Documents: 2}, {Text: "mad", Documents: 1}, {Text: "matilda", Documents: 1}, {Text: "max", Documents: 1}}
+		if !reflect.DeepEqual(got, want) || hasMore {
+			t.Errorf("Suggestions = %+v, want %+v", got, want)
+		}
+		if got, hasMore := suggestions("action ma", 1); !reflect.DeepEqual(got, []model.Suggestion{{Text: "action matrix", Documents: 2}}) || !hasMore {
+			t.Errorf("Expected the earlier words to be kept, got %+v", got)
+		}
+	})
+
+	t.Run("filtered to matching documents", func(t *testing.T) {
+		request := model.SuggestRequest{
+			Query:   "ma",
+			Limit:   10,
+			Filters: []model.RuleFilter{{Field: "title", Operator: "_exact", Value: "Mad Max"}},
+		}
+		want := []model.Suggestion{{Text: "mad", Documents: 1}, {Text: "max", Documents: 1}}
+		if got := s.Suggest(request).Suggestions; !reflect.DeepEqual(got, want) {
+			t.Errorf("Suggestions = %+v, want %+v", got, want)
+		}
+
+		s.settings.SuggestionField = "title"
+		defer func() {
+			s.settings.SuggestionField = ""
+			s.suggestions = suggestionCache{}
+		}()
+		want = []model.Suggestion{{Text: "Mad Max", Documents: 1}}
+		if got := s.Suggest(request).Suggestions; !reflect.DeepEqual(got, want) {
+			t.Errorf("Field value suggestions = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("suggestion field values", func(t *testing.T) {
+		s.settings.SuggestionField = "title"
+		s.suggestions = suggestionCache{}
+		defer func() {
+			s.settings.SuggestionField = ""
+			s.suggestions = suggestionCache{}
+		}()
+
+		got, _ := suggestions("ma", 10)
+		want := []model.Suggestion{{Text: "The Matrix", Documents: 2}, {Text: "Mad Max", Documents: 1}, {Text: "Matilda", Documents: 1}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Suggestions = %+v, want %+v", got, want)
+		}
+		if got, _ := suggestions("the mat", 10); len(got) != 1 || got[0].Text != "The Matrix" {
+			t.Errorf("Expected values to be found from their first word, got %+v", got)
+		}
+		if got, _ := suggestions("mad ", 10); len(got) != 1 || got[0].Text != "Mad Max" {
+			t.Errorf("Expected a finished word to complete values with more words, got %+v", got)
+		}
+		if got, _ := suggestions("matilda ", 10); len(got) != 0 {
+			t.Errorf("Expected no value with more words after 'matilda', got %+v", got)
+		}
+	})
+
+	t.Run("refreshed after writes", func(t *testing.T) {
+		suggestions("matr", 10)
+		if err := indexer.AddDocuments([]model.Document{{"documentID": "5", "title": "Matrix Reloaded", "genres": []interface{}{"Action"}}}); err != nil {
+			t.Fatalf("AddDocuments() error = %v", err)
+		}
+		if got, _ := suggestions("matr", 10); got[0].Documents != 2 {
+			t.Errorf("Expected the suggestions to be refreshed at most once per interval, got %+v", got)
+		}
+
+		s.suggestions.builtAt = time.Now().Add(-suggestionRefreshInterval)
+		if got, _ := suggestions("matr", 10); got[0].Documents != 3 {
+			t.Errorf("Expected the suggestions to be refreshed once the interval passed, got %+v", got)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// maxSuggestions bounds the number of queries suggested for a search that found nothing.
+const maxSuggestions = 3
+
+// suggestQueries returns corrected and relaxed versions of a query that found nothing, keeping only
+// those that find hits: first the query with its misspelled tokens corrected as by Spellcheck, then
+// the query without one of its tokens, those finding the most hits first. Suggestions are written
+// with the analyzed query tokens, without the query's phrases or explicit token match modes; its
+// filters, exclusions and other options still apply when checking them.
+func (s *Service) suggestQueries(query services.SearchQuery, tokens []string, mode matchMode, startTime time.Time) ([]string, error) {
+	// Suggestions are plain queries, only checked for hits: one per page is enough
+	query.Tokens = nil
+	query.Page, query.PageSize = 1, 1
+	query.Facets = nil
+	query.NormalizedPreview = false
+	query.ExplainFilters = false
+
+	var suggestions []string
+	seen := map[string]bool{strings.Join(tokens, " "): true}
+	if corrected, changed := s.correctTokens(tokens); changed {
+		total, err := s.suggestionTotal(query, corrected, mode, startTime)
+		if err != nil {
+			return nil, err
+		}
+		if total > 0 {
+			suggestions = append(suggestions, strings.Join(corrected, " "))
+		}
+	}
+
+	// Documents matching any token would already match the whole query
+	if len(tokens) < 2 || mode == matchAnyToken {
+		return suggestions, nil
+	}
+	type relaxedQuery struct {
+		query string
+		total int
+	}
+	var relaxed []relaxedQuery
+	for i := range tokens {
+		remaining := make([]string, 0, len(tokens)-1)
+		remaining = append(remaining, tokens[:i]...)
+		remaining = append(remaining, tokens[i+1:]...)
+		remainingQuery := strings.Join(remaining, " ")
+		if seen[remainingQuery] {
+			continue
+		}
+		seen[remainingQuery] = true
+
+		total, err := s.suggestionTotal(query, remaining, mode, startTime)
+		if err != nil {
+			return nil, err
+		}
+		if total > 0 {
+			relaxed = append(relaxed, relaxedQuery{query: remainingQuery, total: total})
+		}
+	}
+	sort.SliceStable(relaxed, func(i, j int) bool { return relaxed[i].total > relaxed[j].total })
+	for _, candidate := range relaxed {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, candidate.query)
+	}
+	return suggestions, nil
+}
+
+// suggestionTotal returns the number of hits of a query searched for the given tokens instead of
+// its own.
+func (s *Service) suggestionTotal(query services.SearchQuery, tokens []string, mode matchMode, startTime time.Time) (int, error) {
+	query.QueryString = strings.Join(tokens, " ")
+	result, err := s.execute(query, ruleMatch{rules: s.matchingRules(query.QueryString)}, tokens, nil, mode, startTime)
+	return result.Total, err
+}
+
+// correctTokens replaces the tokens absent from the index with their best spelling correction, and
+// reports whether any was corrected.
+func (s *Service) correctTokens(tokens []string) ([]string, bool) {
+	s.invertedIndex.Mu.RLock()
+	defer s.invertedIndex.Mu.RUnlock()
+
+	corrected := make([]string, len(tokens))
+	changed := false
+	for i, token := range tokens {
+		corrected[i] = token
+		if correction, found := s.correctToken(token, nil); found {
+			corrected[i] = correction.Suggestion
+			changed = true
+		}
+	}
+	return corrected, changed
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestQuerySuggestions(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Wireless Headphones"},
+		{"documentID": "2", "title": "Wireless Speaker"},
+		{"documentID": "3", "title": "Leather Wallet"},
+	})
+	noTypos := 0
+
+	tests := []struct {
+		name  string
+		query services.SearchQuery
+		want  []string
+	}{
+		{"not requested", services.SearchQuery{QueryString: "wireless wallet"}, nil},
+		{"dropped tokens, most hits first", services.SearchQuery{QueryString: "Wireless Wallet", Suggest: true}, []string{"wireless", "wallet"}},
+		{"typo correction", services.SearchQuery{QueryString: "walet", MinWordSizeFor1Typo: &noTypos, MinWordSizeFor2Typos: &noTypos, Suggest: true}, []string{"wallet"}},
+		{"only suggestions with hits", services.SearchQuery{QueryString: "walet wireless", MinWordSizeFor1Typo: &noTypos, MinWordSizeFor2Typos: &noTypos, Suggest: true}, []string{"wireless"}},
+		{"filters still apply", services.SearchQuery{QueryString: "wireless wallet", Filters: &services.Filters{Filters: []services.FilterCondition{{Field: "category", Value: "audio"}}}, Suggest: true}, nil},
+		{"nothing to suggest", services.SearchQuery{QueryString: "blender", Suggest: true}, nil},
+		{"matching queries get none", services.SearchQuery{QueryString: "wallet", Suggest: true}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Suggestions, tt.want) {
+				t.Errorf("Suggestions = %q, want %q", result.Suggestions, tt.want)
+			}
+		})
+	}
+
+	t.Run("fallbacks come first", func(t *testing.T) {
+		s.settings.ZeroResultFallbacks = []config.FallbackStrategy{config.FallbackMatchAny}
+		defer func() { s.settings.ZeroResultFallbacks = nil }()
+
+		result, err := s.Search(services.SearchQuery{QueryString: "wireless wallet", Suggest: true})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if result.Total != 3 || result.Suggestions != nil {
+			t.Errorf("Expected the fallback hits without suggestions, got %d hits and %q", result.Total, result.Suggestions)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// Terms lists the indexed terms starting with the request's prefix, in ascending order, with the
+// number of documents containing each. Terms only indexed as prefix n-grams of longer words are
+// left out unless the request includes prefixes, and with a field, only documents containing the
+// term in that field are counted. The request is expected to be validated, with a positive limit.
+func (s *Service) Terms(request model.TermsRequest) model.TermsResult {
+	prefix := strings.ToLower(strings.TrimSpace(request.Prefix))
+	result := model.TermsResult{Prefix: prefix, Terms: []model.TermCount{}}
+
+	s.invertedIndex.Mu.RLock()
+	defer s.invertedIndex.Mu.RUnlock()
+
+	s.invertedIndex.RangePrefix(prefix, func(term string, documentFrequency int) bool {
+		documents := documentFrequency
+		if !request.IncludePrefixes || request.Field != "" {
+			// Counting by entry kind or field needs the posting list
+			documents = s.termDocuments(term, request.Field, !request.IncludePrefixes, nil)
+		}
+		if documents == 0 {
+			return true
+		}
+		if len(result.Terms) == request.Limit {
+			result.HasMore = true
+			return false
+		}
+		result.Terms = append(result.Terms, model.TermCount{Term: term, Documents: documents})
+		return true
+	})
+	return result
+}
+
+// termDocuments counts the documents containing a term, in field unless it is empty, and as a
+// whole word if wholeWords is set. Only the matching documents are counted unless matching is nil.
+// The caller must hold the inverted index read lock.
+func (s *Service) termDocuments(term, field string, wholeWords bool, matching map[uint32]struct{}) int {
+	postings, _ := s.invertedIndex.Get(term)
+	documents := make(map[uint32]struct{})
+	for _, entry := range postings {
+		if (wholeWords && !entry.IsFullWord) || (field != "" && entry.FieldName != field) || !isMatching(entry.DocID, matching) {
+			continue
+		}
+		documents[entry.DocID] = struct{}{}
+	}
+	return len(documents)
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestTerms(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "The Matrix", "content": "Mathematics"},
+		{"documentID": "2", "title": "Matrix Reloaded", "content": "Matinee"},
+		{"documentID": "3", "title": "Interstellar", "content": "Matrix"},
+	})
+
+	terms := func(request model.TermsRequest) model.TermsResult {
+		t.Helper()
+		if request.Limit == 0 {
+			request.Limit = 20
+		}
+		return s.Terms(request)
+	}
+
+	t.Run("whole words by prefix", func(t *testing.T) {
+		result := terms(model.TermsRequest{Prefix: "Mat"})
+		want := []model.TermCount{{Term: "mathematics", Documents: 1}, {Term: "matinee", Documents: 1}, {Term: "matrix", Documents: 3}}
+		if len(result.Terms) != len(want) || result.HasMore {
+			t.Fatalf("Expected %+v, got %+v", want, result)
+		}
+		for i := range want {
+			if result.Terms[i] != want[i] {
+				t.Errorf("Term %d = %+v, want %+v", i, result.Terms[i], want[i])
+			}
+		}
+	})
+
+	t.Run("prefix n-grams on request", func(t *testing.T) {
+		result := terms(model.TermsRequest{Prefix: "mat", IncludePrefixes: true})
+		if len(result.Terms) == 0 || result.Terms[0] != (model.TermCount{Term: "mat", Documents: 3}) {
+			t.Errorf("Expected the n-gram 'mat' to be listed for all documents, got %+v", result.Terms)
+		}
+	})
+
+	t.Run("counts by field", func(t *testing.T) {
+		result := terms(model.TermsRequest{Prefix: "matrix", Field: "content"})
+		if len(result.Terms) != 1 || result.Terms[0].Documents != 1 {
+			t.Errorf("Expected 'matrix' to be counted once in content, got %+v", result.Terms)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		result := terms(model.TermsRequest{Prefix: "mat", Limit: 2})
+		if len(result.Terms) != 2 || !result.HasMore || result.Terms[1].Term != "matinee" {
+			t.Errorf("Expected the first 2 terms and more to follow, got %+v", result)
+		}
+	})
+
+	t.Run("terms stored in segments", func(t *testing.T) {
+		if _, err := s.invertedIndex.FlushSegment(filepath.Join(t.TempDir(), "segment.seg")); err != nil {
+			t.Fatalf("Failed to flush segment: %v", err)
+		}
+		s.invertedIndex.Delete("matinee")
+		result := terms(model.TermsRequest{Prefix: "mat"})
+		if len(result.Terms) != 2 || result.Terms[0].Term != "mathematics" || result.Terms[1] != (model.TermCount{Term: "matrix", Documents: 3}) {
+			t.Errorf("Expected segment terms to be listed and deleted ones hidden, got %+v", result.Terms)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// structuredQuery prepares a query whose tokens carry explicit match modes. Query rewriters are
+// skipped so the tokens are searched exactly as the client sent them. Each token is analyzed like
+// a query string; a token that splits into several words passes its mode on to all of them.
+func (s *Service) structuredQuery(query services.SearchQuery) (services.SearchQuery, []string) {
+	var tokens []string
+	var analyzed []services.QueryToken
+	for _, queryToken := range query.Tokens {
+		for _, token := range s.analyzer.TokenizeForFields(queryToken.Token, query.RestrictSearchableFields) {
+			tokens = append(tokens, token)
+			analyzed = append(analyzed, services.QueryToken{Token: token, Mode: queryToken.Mode, MaxTypos: queryToken.MaxTypos})
+		}
+	}
+
+	query.Tokens = analyzed
+	query.QueryString = strings.Join(tokens, " ")
+	return query, tokens
+}
+
+// validateTokens rejects the empty tokens of a structured query, which would otherwise be dropped
+// and leave the query matching less than the client asked for.
+func validateTokens(tokens []services.QueryToken) error {
+	for i, token := range tokens {
+		if strings.TrimSpace(token.Token) == "" {
+			return errors.NewValidationError(fmt.Sprintf("tokens[%d].token", i), "cannot be empty")
+		}
+	}
+	return nil
+}
+
+// tokenModes indexes the explicit match modes of a structured query by token. It returns nil for
+// plain queries, whose tokens all use the default matching heuristics.
+func tokenModes(tokens []services.QueryToken) map[string]services.QueryToken {
+	if len(tokens) == 0 {
+		return nil
+	}
+	modes := make(map[string]services.QueryToken, len(tokens))
+	for _, token := range tokens {
+		modes[token.Token] = token
+	}
+	return modes
+}
+
+// allowedTypos reports whether a query token may match with one and with two typos, given the
+// word-size thresholds that apply to the query. Exact and prefix tokens never match with typos,
+// and fuzzy tokens ignore the thresholds. Numbers only match with typos when numberTypos is set,
+// since a typo of "1999" is another number ("1990") rather than a misspelling.
+func allowedTypos(token string, mode services.QueryToken, minWordSizeFor1Typo, minWordSizeFor2Typos int, numberTypos bool) (oneTypo, twoTypos bool) {
+	switch mode.Mode {
+	case services.TokenMatchExact, services.TokenMatchPrefix:
+		return false, false
+	case services.TokenMatchFuzzy:
+		return true, mode.MaxTypos >= 2
+	}
+	if !numberTypos && isNumber(token) {
+		return false, false
+	}
+	size := utf8.RuneCountInString(token) // In characters, so two-character CJK words get no typos
+	return minWordSizeFor1Typo > 0 && size >= minWordSizeFor1Typo,
+		minWordSizeFor2Typos > 0 && size >= minWordSizeFor2Typos
+}
+
+// isNumber reports whether a token is made of digits only.
+func isNumber(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, r := range token {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldMaxTypos returns the most typos allowed in each searchable field of the settings, so the
+// typo passes check fields without scanning the settings for every posting.
+func fieldMaxTypos(settings *config.IndexSettings) map[string]int {
+	limits := make(map[string]int, len(settings.SearchableFields))
+	for _, field := range settings.SearchableFields {
+		limits[field] = settings.FieldMaxTypos(field)
+	}
+	return limits
+}
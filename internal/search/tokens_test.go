@@ -0,0 +1,72 @@
+package search
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestSearchWithTokenMatchModes(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Matrix Reloaded"},
+		{"documentID": "2", "title": "Mat Rider"},
+		{"documentID": "3", "title": "The Matter"},
+		{"documentID": "4", "title": "Metrix"},
+	})
+
+	tests := []struct {
+		name    string
+		tokens  []services.QueryToken
+		wantIDs []string
+	}{
+		{"exact matches whole words only", []services.QueryToken{{Token: "mat", Mode: services.TokenMatchExact}}, []string{"2"}},
+		{"prefix matches word starts", []services.QueryToken{{Token: "mat", Mode: services.TokenMatchPrefix}}, []string{"1", "2", "3"}},
+		{"prefix does not allow typos", []services.QueryToken{{Token: "matrix", Mode: services.TokenMatchPrefix}}, []string{"1"}},
+		{"exact does not allow typos", []services.QueryToken{{Token: "matrix", Mode: services.TokenMatchExact}}, []string{"1"}},
+		{"fuzzy ignores word-size thresholds", []services.QueryToken{{Token: "mat", Mode: services.TokenMatchFuzzy}}, []string{"1", "2", "3", "4"}},
+		{"modes combine per token", []services.QueryToken{{Token: "matrix", Mode: services.TokenMatchPrefix}, {Token: "reloaded", Mode: services.TokenMatchExact}}, []string{"1"}},
+		{"multi-word tokens keep their mode", []services.QueryToken{{Token: "Mat Rider", Mode: services.TokenMatchExact}}, []string{"2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Search(services.SearchQuery{Tokens: tt.tokens, PageSize: 10})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			var gotIDs []string
+			for _, hit := range result.Hits {
+				gotIDs = append(gotIDs, hit.Document["documentID"].(string))
+			}
+			sort.Strings(gotIDs)
+			if len(gotIDs) != len(tt.wantIDs) {
+				t.Fatalf("hits = %v, want %v", gotIDs, tt.wantIDs)
+			}
+			for i := range gotIDs {
+				if gotIDs[i] != tt.wantIDs[i] {
+					t.Errorf("hits = %v, want %v", gotIDs, tt.wantIDs)
+					break
+				}
+			}
+		})
+	}
+
+	// The default heuristics still apply typo tolerance to plain queries
+	result, err := s.Search(services.SearchQuery{QueryString: "matrix", PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if result.Total != 2 {
+		t.Errorf("plain query Total = %d, want 2", result.Total)
+	}
+
+	// Empty tokens are rejected rather than dropped
+	_, err = s.Search(services.SearchQuery{Tokens: []services.QueryToken{{Token: "matrix", Mode: services.TokenMatchExact}, {Token: " ", Mode: services.TokenMatchExact}}})
+	if validationErr, ok := err.(*errors.ValidationError); !ok || validationErr.Field != "tokens[1].token" {
+		t.Errorf("Search() with an empty token error = %v, want a validation error for tokens[1].token", err)
+	}
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestSearchSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "Running Shoes"},
+		{"documentID": "2", "title": "Trail Running Jacket"},
+	})
+	ctx, request := otel.Tracer("test").Start(context.Background(), "POST /indexes/:indexName/_search")
+	result, err := s.Search(services.SearchQuery{QueryString: "runing", PageSize: 10, Context: ctx})
+	request.End()
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	parents := map[string]string{
+		"search":                "POST /indexes/:indexName/_search",
+		"search.tokenize":       "search",
+		"search.candidates":     "search",
+		"search.typo_expansion": "search.candidates",
+		"search.filter":         "search",
+		"search.rank":           "search",
+	}
+	for name, parentName := range parents {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("No %s span recorded", name)
+			continue
+		}
+		if span.Parent().SpanID() != spans[parentName].SpanContext().SpanID() {
+			t.Errorf("Span %s is not a child of %s", name, parentName)
+		}
+	}
+
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range spans["search"].Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes["query_id"].AsString() != result.QueryId || attributes["total"].AsInt64() != 2 {
+		t.Errorf("search span attributes = %v, want the query ID and the 2 hits", attributes)
+	}
+	typoAttributes := spans["search.typo_expansion"].Attributes()
+	for _, kv := range typoAttributes {
+		if kv.Key == "typo_terms_matched" && kv.Value.AsInt64() == 0 {
+			t.Errorf("typo expansion span reports no matched typo terms for a misspelled query")
+		}
+	}
+}
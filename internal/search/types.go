@@ -7,5 +7,16 @@ type candidateHit struct {
 	doc                      model.Document
 	score                    float64
 	filterScore              float64
+	matchedTokens            int                            // Query tokens matched exactly or via typo
 	matchedQueryTermsByField map[string]map[string]struct{} // FieldName -> queryToken -> struct{}
 }
+
+// matchMode decides which documents are candidates for a query
+type matchMode int
+
+const (
+	matchAllTokens    matchMode = iota // Documents matching every query token
+	matchAnyToken                      // Documents matching at least one query token
+	matchMostTokens                    // Documents matching more than half of the query tokens
+	matchAllDocuments                  // Every document, regardless of the query tokens
+)
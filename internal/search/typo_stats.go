@@ -0,0 +1,82 @@
+package search
+
+import (
+	"sync"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// typoDistanceCounts tallies typo expansion at one edit distance.
+type typoDistanceCounts struct {
+	expandedTokens int64
+	generated      int64
+	matched        int64
+}
+
+// typoCounts tallies typo expansion for one search, or the running totals of a Service.
+type typoCounts struct {
+	searches     int64
+	totalHits    int64
+	hitsViaTypos int64
+	byDistance   [2]typoDistanceCounts // Indexed by edit distance - 1
+}
+
+// expanded records the candidates generated for a query token at an edit distance and how many of them matched.
+func (c *typoCounts) expanded(distance, generated, matched int) {
+	counts := &c.byDistance[distance-1]
+	counts.expandedTokens++
+	counts.generated += int64(generated)
+	counts.matched += int64(matched)
+}
+
+// typoStatsRecorder accumulates the typo counts of the searches run by a Service.
+type typoStatsRecorder struct {
+	mu     sync.Mutex
+	totals typoCounts
+}
+
+// record adds the counts of one search to the totals.
+func (r *typoStatsRecorder) record(counts typoCounts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.totals.searches += counts.searches
+	r.totals.totalHits += counts.totalHits
+	r.totals.hitsViaTypos += counts.hitsViaTypos
+	for i, distance := range counts.byDistance {
+		r.totals.byDistance[i].expandedTokens += distance.expandedTokens
+		r.totals.byDistance[i].generated += distance.generated
+		r.totals.byDistance[i].matched += distance.matched
+	}
+}
+
+// snapshot returns the totals recorded so far.
+func (r *typoStatsRecorder) snapshot() model.TypoStats {
+	r.mu.Lock()
+	totals := r.totals
+	r.mu.Unlock()
+
+	distanceStats := func(counts typoDistanceCounts) model.TypoDistanceStats {
+		stats := model.TypoDistanceStats{
+			ExpandedTokens:      counts.expandedTokens,
+			CandidatesGenerated: counts.generated,
+			CandidatesMatched:   counts.matched,
+		}
+		if counts.generated > 0 {
+			stats.CandidateMatchRate = float64(counts.matched) / float64(counts.generated)
+		}
+		return stats
+	}
+	return model.TypoStats{
+		Searches:     totals.searches,
+		TotalHits:    totals.totalHits,
+		HitsViaTypos: totals.hitsViaTypos,
+		OneTypo:      distanceStats(totals.byDistance[0]),
+		TwoTypos:     distanceStats(totals.byDistance[1]),
+	}
+}
+
+// TypoStats returns how much typo expansion has contributed to this index's searches. The counts
+// start over when the service is recreated, for example after a settings update.
+func (s *Service) TypoStats() model.TypoStats {
+	return s.typoStats.snapshot()
+}
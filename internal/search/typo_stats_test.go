@@ -0,0 +1,43 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypoStats(t *testing.T) {
+	s := createTestService(t, []model.Document{
+		{"documentID": "1", "title": "The Matrix"},
+		{"documentID": "2", "title": "Mother"},
+	})
+
+	result, err := s.Search(services.SearchQuery{QueryString: "matrx"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+
+	stats := s.TypoStats()
+	assert.Equal(t, int64(1), stats.Searches)
+	assert.Equal(t, int64(1), stats.TotalHits)
+	assert.Equal(t, int64(1), stats.HitsViaTypos)
+	assert.Equal(t, int64(1), stats.OneTypo.ExpandedTokens)
+	assert.Equal(t, int64(0), stats.TwoTypos.ExpandedTokens, "5-letter tokens are not expanded to 2 typos")
+	assert.Positive(t, stats.OneTypo.CandidatesMatched)
+	assert.LessOrEqual(t, stats.OneTypo.CandidatesMatched, stats.OneTypo.CandidatesGenerated)
+	matched := stats.OneTypo.CandidatesMatched
+
+	// An exact match still expands the token, but its candidates add nothing
+	result, err = s.Search(services.SearchQuery{QueryString: "matrix"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+
+	stats = s.TypoStats()
+	assert.Equal(t, int64(2), stats.Searches)
+	assert.Equal(t, int64(2), stats.TotalHits)
+	assert.Equal(t, int64(1), stats.HitsViaTypos)
+	assert.Equal(t, int64(2), stats.OneTypo.ExpandedTokens)
+	assert.Equal(t, matched, stats.OneTypo.CandidatesMatched)
+	assert.Less(t, stats.OneTypo.CandidateMatchRate, 1.0)
+}
@@ -0,0 +1,329 @@
+// Package stemmer reduces words to their stem, so that inflections of a word ("running", "runs")
+// are indexed and searched as the same term ("run").
+package stemmer
+
+import "strings"
+
+// stemmers holds the stemmer of each supported language (ISO 639-1 code).
+var stemmers = map[string]func(string) string{
+	"en": English,
+}
+
+// For returns the stemmer of a locale or language code ("en", "en-US"), or nil when the language
+// has none.
+func For(locale string) func(string) string {
+	language := strings.ToLower(strings.TrimSpace(locale))
+	if idx := strings.IndexAny(language, "-_"); idx >= 0 {
+		language = language[:idx]
+	}
+	return stemmers[language]
+}
+
+// IsSupported reports whether a locale or language code has a stemmer.
+func IsSupported(locale string) bool {
+	return For(locale) != nil
+}
+
+// English reduces a lowercased English word to its stem with the Porter algorithm ("running" ->
+// "run", "connections" -> "connect"). Words of at most two letters and words with characters other
+// than ASCII letters, such as numbers, are returned unchanged.
+func English(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	p := &porter{b: []byte(word)}
+	p.step1ab()
+	p.step1c()
+	p.step2()
+	p.step3()
+	p.step4()
+	p.step5()
+	return string(p.b)
+}
+
+// porter holds the word being stemmed by the steps of the Porter algorithm.
+type porter struct {
+	b []byte
+	j int // Index of the last letter of the stem before the suffix last matched by ends
+}
+
+// k returns the index of the last letter of the word.
+func (p *porter) k() int {
+	return len(p.b) - 1
+}
+
+// cons reports whether the letter at i is a consonant. "y" is a consonant at the start of a word
+// and after a vowel.
+func (p *porter) cons(i int) bool {
+	switch p.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !p.cons(i-1)
+	}
+	return true
+}
+
+// m measures the stem b[0..j]: the number of vowel-consonant sequences in it, n in [C](VC)^n[V].
+func (p *porter) m() int {
+	n, i := 0, 0
+	for {
+		if i > p.j {
+			return n
+		}
+		if !p.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > p.j {
+				return n
+			}
+			if p.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > p.j {
+				return n
+			}
+			if !p.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem reports whether the stem b[0..j] has a vowel.
+func (p *porter) vowelInStem() bool {
+	for i := 0; i <= p.j; i++ {
+		if !p.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleCons reports whether the letters at i-1 and i are the same consonant.
+func (p *porter) doubleCons(i int) bool {
+	return i >= 1 && p.b[i] == p.b[i-1] && p.cons(i)
+}
+
+// cvc reports whether the letters at i-2, i-1 and i are consonant, vowel, consonant, the last one
+// not being w, x or y. It restores an "e" in words like "hop(e)" and "fil(e)".
+func (p *porter) cvc(i int) bool {
+	if i < 2 || !p.cons(i) || p.cons(i-1) || !p.cons(i-2) {
+		return false
+	}
+	switch p.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends reports whether the word ends with suffix, setting j to the end of the stem before it.
+func (p *porter) ends(suffix string) bool {
+	if len(suffix) > len(p.b) || string(p.b[len(p.b)-len(suffix):]) != suffix {
+		return false
+	}
+	p.j = len(p.b) - len(suffix) - 1
+	return true
+}
+
+// setTo replaces the letters after j with s.
+func (p *porter) setTo(s string) {
+	p.b = append(p.b[:p.j+1], s...)
+}
+
+// replace replaces the suffix matched by ends with s when the stem measures more than 0.
+func (p *porter) replace(s string) {
+	if p.m() > 0 {
+		p.setTo(s)
+	}
+}
+
+// penultimate returns the letter before the last one, or 0 for one-letter words.
+func (p *porter) penultimate() byte {
+	if len(p.b) < 2 {
+		return 0
+	}
+	return p.b[len(p.b)-2]
+}
+
+// step1ab removes plurals and -ed or -ing ("caresses" -> "caress", "ponies" -> "poni", "meetings"
+// -> "meet", "hopping" -> "hop", "filing" -> "file").
+func (p *porter) step1ab() {
+	if p.b[p.k()] == 's' {
+		switch {
+		case p.ends("sses"):
+			p.b = p.b[:len(p.b)-2]
+		case p.ends("ies"):
+			p.setTo("i")
+		case p.penultimate() != 's':
+			p.b = p.b[:len(p.b)-1]
+		}
+	}
+	if p.ends("eed") {
+		if p.m() > 0 {
+			p.b = p.b[:len(p.b)-1]
+		}
+		return
+	}
+	if (p.ends("ed") || p.ends("ing")) && p.vowelInStem() {
+		p.b = p.b[:p.j+1]
+		switch {
+		case p.ends("at"):
+			p.setTo("ate")
+		case p.ends("bl"):
+			p.setTo("ble")
+		case p.ends("iz"):
+			p.setTo("ize")
+		case p.doubleCons(p.k()):
+			if last := p.b[p.k()]; last != 'l' && last != 's' && last != 'z' {
+				p.b = p.b[:len(p.b)-1]
+			}
+		case p.m() == 1 && p.cvc(p.k()):
+			p.setTo("e")
+		}
+	}
+}
+
+// step1c turns a final "y" into "i" when the stem has a vowel ("happy" -> "happi").
+func (p *porter) step1c() {
+	if p.ends("y") && p.vowelInStem() {
+		p.b[p.k()] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones ("relational" -> "relate", "digitizer" -> "digitize").
+func (p *porter) step2() {
+	var suffixes [][2]string
+	switch p.penultimate() {
+	case 'a':
+		suffixes = [][2]string{{"ational", "ate"}, {"tional", "tion"}}
+	case 'c':
+		suffixes = [][2]string{{"enci", "ence"}, {"anci", "ance"}}
+	case 'e':
+		suffixes = [][2]string{{"izer", "ize"}}
+	case 'l':
+		suffixes = [][2]string{{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"}}
+	case 'o':
+		suffixes = [][2]string{{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}}
+	case 's':
+		suffixes = [][2]string{{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"}}
+	case 't':
+		suffixes = [][2]string{{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}}
+	case 'g':
+		suffixes = [][2]string{{"logi", "log"}}
+	}
+	p.replaceFirst(suffixes)
+}
+
+// step3 removes or shortens -ic-, -full, -ness and similar suffixes ("triplicate" -> "triplic",
+// "hopeful" -> "hope").
+func (p *porter) step3() {
+	var suffixes [][2]string
+	switch p.b[p.k()] {
+	case 'e':
+		suffixes = [][2]string{{"icate", "ic"}, {"ative", ""}, {"alize", "al"}}
+	case 'i':
+		suffixes = [][2]string{{"iciti", "ic"}}
+	case 'l':
+		suffixes = [][2]string{{"ical", "ic"}, {"ful", ""}}
+	case 's':
+		suffixes = [][2]string{{"ness", ""}}
+	}
+	p.replaceFirst(suffixes)
+}
+
+// replaceFirst replaces the first of the suffixes the word ends with, if its stem measures more
+// than 0.
+func (p *porter) replaceFirst(suffixes [][2]string) {
+	for _, suffix := range suffixes {
+		if p.ends(suffix[0]) {
+			p.replace(suffix[1])
+			return
+		}
+	}
+}
+
+// step4 removes -ant, -ence and similar suffixes from stems measuring more than 1 ("adjustment"
+// -> "adjust", "adoption" -> "adopt").
+func (p *porter) step4() {
+	var suffixes []string
+	switch p.penultimate() {
+	case 'a':
+		suffixes = []string{"al"}
+	case 'c':
+		suffixes = []string{"ance", "ence"}
+	case 'e':
+		suffixes = []string{"er"}
+	case 'i':
+		suffixes = []string{"ic"}
+	case 'l':
+		suffixes = []string{"able", "ible"}
+	case 'n':
+		suffixes = []string{"ant", "ement", "ment", "ent"}
+	case 'o':
+		if p.ends("ion") && p.j >= 0 && (p.b[p.j] == 's' || p.b[p.j] == 't') {
+			break
+		}
+		suffixes = []string{"ou"}
+	case 's':
+		suffixes = []string{"ism"}
+	case 't':
+		suffixes = []string{"ate", "iti"}
+	case 'u':
+		suffixes = []string{"ous"}
+	case 'v':
+		suffixes = []string{"ive"}
+	case 'z':
+		suffixes = []string{"ize"}
+	default:
+		return
+	}
+	if suffixes != nil {
+		matched := false
+		for _, suffix := range suffixes {
+			if p.ends(suffix) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return
+		}
+	}
+	if p.m() > 1 {
+		p.b = p.b[:p.j+1]
+	}
+}
+
+// step5 removes a final "e" and a double "l" from stems measuring more than 1 ("probate" ->
+// "probat", "controll" -> "control").
+func (p *porter) step5() {
+	p.j = p.k()
+	if p.b[p.k()] == 'e' {
+		if m := p.m(); m > 1 || (m == 1 && !p.cvc(p.k()-1)) {
+			p.b = p.b[:len(p.b)-1]
+		}
+	}
+	if p.b[p.k()] == 'l' && p.doubleCons(p.k()) && p.m() > 1 {
+		p.b = p.b[:len(p.b)-1]
+	}
+}
@@ -0,0 +1,38 @@
+package stemmer
+
+import "testing"
+
+func TestEnglish(t *testing.T) {
+	// Pairs from the examples of the Porter algorithm's description
+	tests := map[string]string{
+		"caresses": "caress", "ponies": "poni", "ties": "ti", "caress": "caress", "cats": "cat",
+		"feed": "feed", "agreed": "agre", "plastered": "plaster", "bled": "bled", "motoring": "motor",
+		"sing": "sing", "conflated": "conflat", "troubled": "troubl", "sized": "size", "hopping": "hop",
+		"tanned": "tan", "falling": "fall", "hissing": "hiss", "fizzed": "fizz", "failing": "fail",
+		"filing": "file", "happy": "happi", "sky": "sky", "relational": "relat", "conditional": "condit",
+		"rational": "ration", "digitizer": "digit", "conformabli": "conform", "generalization": "gener",
+		"triplicate": "triplic", "formative": "form", "hopeful": "hope", "goodness": "good",
+		"revival": "reviv", "allowance": "allow", "inference": "infer", "airliner": "airlin",
+		"adjustable": "adjust", "defensible": "defens", "irritant": "irrit", "replacement": "replac",
+		"adjustment": "adjust", "dependent": "depend", "adoption": "adopt", "homologous": "homolog",
+		"communism": "commun", "activate": "activ", "effective": "effect", "bowdlerize": "bowdler",
+		"probate": "probat", "rate": "rate", "cease": "ceas", "controlling": "control", "roll": "roll",
+		"running": "run", "runs": "run", "run": "run", "connections": "connect",
+		// Short words, numbers and words with other characters are kept
+		"is": "is", "2024": "2024", "mp3s": "mp3s", "c++": "c++", "ies": "i",
+	}
+	for word, want := range tests {
+		if got := English(word); got != want {
+			t.Errorf("English(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestFor(t *testing.T) {
+	if For("en") == nil || For("en-US") == nil || For("EN_gb") == nil {
+		t.Error("Expected English locales to have a stemmer")
+	}
+	if IsSupported("de") || IsSupported("") {
+		t.Error("Expected only languages with a stemmer to be supported")
+	}
+}
@@ -0,0 +1,169 @@
+// Package suggest completes typed prefixes with weighted suggestions, heaviest first, from a trie
+// dedicated to typeahead so completions don't need a search.
+package suggest
+
+import (
+	"container/heap"
+	"slices"
+	"strings"
+)
+
+// Suggestion is a completion and its weight, e.g. the number of documents it occurs in. A
+// suggestion is found by a prefix of any of its keys.
+type Suggestion struct {
+	Text   string
+	Weight int
+	Keys   []string
+}
+
+// Trie is an immutable trie over the keys of suggestions. Each node records the highest weight of
+// the suggestions below it, so the heaviest completions of a prefix are found best-first without
+// visiting the others. A nil *Trie is an empty trie.
+type Trie struct {
+	root        *node
+	suggestions []Suggestion
+}
+
+type node struct {
+	label       byte    // Byte of the key leading from the parent
+	children    []*node // Sorted by label
+	suggestions []int   // Suggestions with a key ending at this node
+	best        int     // Highest weight of the suggestions at or below this node
+}
+
+// New builds a trie of suggestions. Suggestions without keys cannot be found.
+func New(suggestions []Suggestion) *Trie {
+	t := &Trie{root: &node{}, suggestions: suggestions}
+	for i, suggestion := range suggestions {
+		for _, key := range suggestion.Keys {
+			t.insert(key, i)
+		}
+	}
+	setBest(t.root, suggestions)
+	return t
+}
+
+// insert adds key to the trie, ending at suggestion i.
+func (t *Trie) insert(key string, i int) {
+	current := t.root
+	for j := 0; j < len(key); j++ {
+		position, found := slices.BinarySearchFunc(current.children, key[j], func(child *node, label byte) int {
+			return int(child.label) - int(label)
+		})
+		if !found {
+			current.children = slices.Insert(current.children, position, &node{label: key[j]})
+		}
+		current = current.children[position]
+	}
+	if !slices.Contains(current.suggestions, i) {
+		current.suggestions = append(current.suggestions, i)
+	}
+}
+
+// setBest records the highest weight below each node of the subtree of n, and returns that of n.
+func setBest(n *node, suggestions []Suggestion) int {
+	n.best = 0
+	for _, i := range n.suggestions {
+		n.best = max(n.best, suggestions[i].Weight)
+	}
+	for _, child := range n.children {
+		n.best = max(n.best, setBest(child, suggestions))
+	}
+	return n.best
+}
+
+// Len returns the number of suggestions in the trie.
+func (t *Trie) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.suggestions)
+}
+
+// Complete returns up to limit suggestions with a key starting with prefix, heaviest first and
+// alphabetically among equally heavy ones, and whether more suggestions match the prefix.
+func (t *Trie) Complete(prefix string, limit int) ([]Suggestion, bool) {
+	if t == nil || limit <= 0 {
+		return nil, false
+	}
+	start := t.root
+	for i := 0; i < len(prefix); i++ {
+		position, found := slices.BinarySearchFunc(start.children, prefix[i], func(child *node, label byte) int {
+			return int(child.label) - int(label)
+		})
+		if !found {
+			return nil, false
+		}
+		start = start.children[position]
+	}
+
+	// Nodes are expanded before suggestions of the same weight are taken, so all suggestions of a
+	// weight are queued, and ordered by text, before the first of them is returned
+	queue := &completionQueue{suggestions: t.suggestions}
+	heap.Push(queue, completion{node: start, weight: start.best})
+	var completions []Suggestion
+	seen := make(map[int]bool)
+	for queue.Len() > 0 {
+		next := heap.Pop(queue).(completion)
+		if next.node == nil {
+			if seen[next.suggestion] {
+				continue
+			}
+			if len(completions) == limit {
+				return completions, true
+			}
+			seen[next.suggestion] = true
+			completions = append(completions, t.suggestions[next.suggestion])
+			continue
+		}
+		for _, i := range next.node.suggestions {
+			if !seen[i] {
+				heap.Push(queue, completion{suggestion: i, weight: t.suggestions[i].Weight})
+			}
+		}
+		for _, child := range next.node.children {
+			heap.Push(queue, completion{node: child, weight: child.best})
+		}
+	}
+	return completions, false
+}
+
+// completion is a node of the trie to expand, or a suggestion when node is nil.
+type completion struct {
+	node       *node
+	suggestion int
+	weight     int
+}
+
+// completionQueue orders completions by weight, nodes before suggestions of the same weight, and
+// suggestions by text.
+type completionQueue struct {
+	items       []completion
+	suggestions []Suggestion
+}
+
+func (q *completionQueue) Len() int { return len(q.items) }
+
+func (q *completionQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if a.weight != b.weight {
+		return a.weight > b.weight
+	}
+	if (a.node == nil) != (b.node == nil) {
+		return a.node != nil
+	}
+	if a.node != nil {
+		return false
+	}
+	return strings.Compare(q.suggestions[a.suggestion].Text, q.suggestions[b.suggestion].Text) < 0
+}
+
+func (q *completionQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *completionQueue) Push(item any) { q.items = append(q.items, item.(completion)) }
+
+func (q *completionQueue) Pop() any {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
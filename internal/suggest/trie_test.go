@@ -0,0 +1,56 @@
+package suggest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func texts(suggestions []Suggestion) []string {
+	result := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		result = append(result, suggestion.Text)
+	}
+	return result
+}
+
+func TestTrieComplete(t *testing.T) {
+	trie := New([]Suggestion{
+		{Text: "The Matrix", Weight: 3, Keys: []string{"the matrix", "matrix"}},
+		{Text: "The Matrix Reloaded", Weight: 5, Keys: []string{"the matrix reloaded", "matrix reloaded", "reloaded"}},
+		{Text: "Mad Max", Weight: 3, Keys: []string{"mad max", "max"}},
+		{Text: "Matilda", Weight: 1, Keys: []string{"matilda"}},
+		{Text: "Unreachable", Weight: 9},
+	})
+
+	tests := []struct {
+		prefix   string
+		limit    int
+		want     []string
+		wantMore bool
+	}{
+		{"ma", 10, []string{"The Matrix Reloaded", "Mad Max", "The Matrix", "Matilda"}, false},
+		{"ma", 2, []string{"The Matrix Reloaded", "Mad Max"}, true},
+		{"matrix", 10, []string{"The Matrix Reloaded", "The Matrix"}, false},
+		{"the matrix r", 10, []string{"The Matrix Reloaded"}, false},
+		{"", 3, []string{"The Matrix Reloaded", "Mad Max", "The Matrix"}, true},
+		{"x", 10, nil, false},
+		{"ma", 0, nil, false},
+	}
+	for _, tt := range tests {
+		got, more := trie.Complete(tt.prefix, tt.limit)
+		if !reflect.DeepEqual(texts(got), tt.want) && !(len(got) == 0 && len(tt.want) == 0) {
+			t.Errorf("Complete(%q, %d) = %q, want %q", tt.prefix, tt.limit, texts(got), tt.want)
+		}
+		if more != tt.wantMore {
+			t.Errorf("Complete(%q, %d) has more = %v, want %v", tt.prefix, tt.limit, more, tt.wantMore)
+		}
+	}
+
+	if trie.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", trie.Len())
+	}
+	var empty *Trie
+	if got, more := empty.Complete("ma", 10); got != nil || more || empty.Len() != 0 {
+		t.Errorf("Expected an empty nil trie, got %q", texts(got))
+	}
+}
@@ -0,0 +1,367 @@
+package tokenizer
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/stemmer"
+)
+
+// Analyzer turns field and query text into tokens according to an index's settings.
+// The same analyzer must be used at index time and at query time so both sides agree on tokens.
+type Analyzer struct {
+	locale               string
+	fieldsWithoutPrefix  map[string]struct{}
+	localeNormalizerFunc func(string) string
+	languageFields       map[string]string // Per-language fields of language detection, to their language
+	stopWords            map[string]struct{}
+	keepStopWordsIndexed bool           // Stop words stay in field words so quoted phrases can match them
+	compoundWords        bool           // Hyphenated words are also indexed joined, and contractions are one word
+	wordRuns             *regexp.Regexp // Matches the tokens of lowercased text, with the index's word characters
+	stemming             bool           // Field and query words are reduced to their stem in languages with a stemmer
+	normalization        config.TextNormalization
+	cjkFields            map[string]struct{} // Fields whose CJK text is split into two-character words
+}
+
+// NewAnalyzer creates an analyzer for the given index settings.
+func NewAnalyzer(settings *config.IndexSettings) *Analyzer {
+	analyzer := &Analyzer{
+		fieldsWithoutPrefix: make(map[string]struct{}),
+		languageFields:      make(map[string]string),
+		stopWords:           make(map[string]struct{}),
+		wordRuns:            alphanumericRunRegex,
+		cjkFields:           make(map[string]struct{}),
+	}
+	if settings == nil {
+		return analyzer
+	}
+
+	analyzer.locale = settings.Locale
+	analyzer.compoundWords = settings.CompoundWords
+	analyzer.stemming = settings.Stemming
+	analyzer.normalization = settings.TextNormalization
+	for _, field := range settings.CJKFields {
+		analyzer.cjkFields[field] = struct{}{}
+	}
+	letters := asciiLetters
+	if settings.TextNormalization != "" {
+		letters = unicodeLetters
+	} else if len(settings.CJKFields) > 0 {
+		letters = cjkLetters
+	}
+	analyzer.wordRuns = wordRunRegex(letters, settings.WordCharacters)
+	analyzer.localeNormalizerFunc = localeNormalizer(settings.Locale)
+	for _, field := range settings.FieldsWithoutPrefixSearch {
+		analyzer.fieldsWithoutPrefix[field] = struct{}{}
+	}
+	if stopWords := settings.StopWords; stopWords != nil {
+		for _, word := range stopWords.List() {
+			for _, token := range analyzer.Tokenize(word) {
+				analyzer.stopWords[token] = struct{}{}
+			}
+		}
+		analyzer.keepStopWordsIndexed = stopWords.KeepInPhrases
+	}
+	if detection := settings.LanguageDetection; detection != nil {
+		for _, field := range detection.Fields {
+			for _, language := range detection.Languages {
+				analyzer.languageFields[LanguageField(field, language)] = language
+			}
+		}
+	}
+	return analyzer
+}
+
+// LanguageField returns the name of the per-language field that language detection routes the
+// text of field to ("title", "de" -> "title.de").
+func LanguageField(field, language string) string {
+	return field + "." + language
+}
+
+// IsLanguageField reports whether a field is a per-language field of language detection.
+func (a *Analyzer) IsLanguageField(fieldName string) bool {
+	_, ok := a.languageFields[fieldName]
+	return ok
+}
+
+// Locale returns the locale the analyzer was configured with.
+func (a *Analyzer) Locale() string {
+	return a.locale
+}
+
+// Normalize applies the character normalization that precedes tokenization: the one of the locale,
+// followed by accent folding with fold_accents, or only NFKC with keep_accents.
+func (a *Analyzer) Normalize(text string) string {
+	return a.normalizeWith(a.localeNormalizerFunc, text)
+}
+
+// Tokenize normalizes and tokenizes text into whole-word tokens.
+func (a *Analyzer) Tokenize(text string) []string {
+	return a.tokenize(a.Normalize(text))
+}
+
+// TokenizeForFields tokenizes query text searched in the given fields. Per-language fields are
+// analyzed with their language, so when every field is a per-language field of the same language
+// the query is normalized and stemmed for that language too. Stop words are removed, unless the
+// query is made only of stop words.
+func (a *Analyzer) TokenizeForFields(text string, fieldNames []string) []string {
+	tokens := a.tokenizeQuery(text, fieldNames)
+	if withoutStopWords := a.removeStopWords(tokens); len(withoutStopWords) > 0 {
+		tokens = withoutStopWords
+	}
+	return a.stemWords(tokens, a.queryLanguage(fieldNames))
+}
+
+// PhraseWords tokenizes the text of a quoted phrase searched in the given fields. Stop words are
+// kept when they are indexed for phrases, and removed otherwise like they are from field values.
+func (a *Analyzer) PhraseWords(text string, fieldNames []string) []string {
+	tokens := a.tokenizeQuery(text, fieldNames)
+	if !a.keepStopWordsIndexed {
+		tokens = a.removeStopWords(tokens)
+	}
+	return a.stemWords(tokens, a.queryLanguage(fieldNames))
+}
+
+// FieldWords returns the whole words of a field value, normalized like the field is indexed.
+// Stop words are removed unless they are kept for phrases.
+func (a *Analyzer) FieldWords(text string, fieldName string) []string {
+	words, _ := a.fieldWords(text, fieldName)
+	return words
+}
+
+// IndexedWords returns the whole words indexed for a field value: its words, followed by its
+// hyphenated words joined when compound words are enabled ("sci-fi" -> "sci", "fi", "scifi").
+func (a *Analyzer) IndexedWords(text string, fieldName string) []string {
+	words, compounds := a.fieldWords(text, fieldName)
+	for _, compound := range compounds {
+		words = append(words, compound.Word)
+	}
+	return words
+}
+
+// FieldWordPositions returns the positions of the indexed words of a field value. A joined
+// hyphenated word takes the position of its first part.
+func (a *Analyzer) FieldWordPositions(text string, fieldName string) map[string][]int {
+	words, compounds := a.fieldWords(text, fieldName)
+	positions := make(map[string][]int, len(words)+len(compounds))
+	for position, word := range words {
+		positions[word] = append(positions[word], position)
+	}
+	for _, compound := range compounds {
+		positions[compound.Word] = append(positions[compound.Word], compound.Start)
+		slices.Sort(positions[compound.Word])
+	}
+	return positions
+}
+
+// FieldTokens returns the tokens indexed for a field: whole words plus their prefix n-grams,
+// unless prefix search is disabled for the field.
+func (a *Analyzer) FieldTokens(text string, fieldName string) []string {
+	if !a.HasPrefixNGrams(fieldName) {
+		return a.IndexedWords(text, fieldName)
+	}
+	return WithPrefixNGrams(a.IndexedWords(text, fieldName))
+}
+
+// HasPrefixNGrams reports whether the prefix n-grams of a field's words are indexed.
+func (a *Analyzer) HasPrefixNGrams(fieldName string) bool {
+	_, noPrefix := a.fieldsWithoutPrefix[fieldName]
+	return !noPrefix
+}
+
+// isStopWord reports whether a token is one of the index's stop words.
+func (a *Analyzer) isStopWord(token string) bool {
+	_, ok := a.stopWords[token]
+	return ok
+}
+
+// tokenizeQuery normalizes and tokenizes query text searched in the given fields, all searchable
+// fields when there are none. CJK characters are split into two-character words when a CJK field
+// is searched.
+func (a *Analyzer) tokenizeQuery(text string, fieldNames []string) []string {
+	var tokens []string
+	if language := a.commonLanguage(fieldNames); language != "" {
+		tokens = a.tokenize(a.normalizeFor(language, text))
+	} else {
+		tokens = a.Tokenize(text)
+	}
+	if a.searchesCJKField(fieldNames) {
+		tokens, _ = splitCJK(tokens, nil)
+	}
+	return tokens
+}
+
+// isCJKField reports whether the CJK text of a field is split into two-character words. The
+// per-language fields of a CJK field are CJK fields too.
+func (a *Analyzer) isCJKField(fieldName string) bool {
+	if _, ok := a.cjkFields[fieldName]; ok {
+		return true
+	}
+	if language, ok := a.languageFields[fieldName]; ok {
+		_, ok = a.cjkFields[strings.TrimSuffix(fieldName, "."+language)]
+		return ok
+	}
+	return false
+}
+
+// searchesCJKField reports whether a query searching the given fields, all searchable fields when
+// there are none, searches a CJK field.
+func (a *Analyzer) searchesCJKField(fieldNames []string) bool {
+	if len(a.cjkFields) == 0 {
+		return false
+	}
+	return len(fieldNames) == 0 || slices.ContainsFunc(fieldNames, a.isCJKField)
+}
+
+// stemWords reduces words to their stem in place with the stemmer of a locale or language.
+func (a *Analyzer) stemWords(words []string, language string) []string {
+	if stem := a.stemmer(language); stem != nil {
+		for i, word := range words {
+			words[i] = stem(word)
+		}
+	}
+	return words
+}
+
+// stemmer returns the stemmer of a locale or language, or nil when stemming is disabled or the
+// language has no stemmer.
+func (a *Analyzer) stemmer(language string) func(string) string {
+	if !a.stemming {
+		return nil
+	}
+	return stemmer.For(language)
+}
+
+// tokenize tokenizes normalized text, keeping contractions one word when compound words are
+// enabled. Hyphenated words are split into their parts either way, unless the hyphen is a word
+// character.
+func (a *Analyzer) tokenize(text string) []string {
+	if !a.compoundWords {
+		return tokenizeRuns(splitCaseAndLower(text), a.wordRuns)
+	}
+	tokens, _ := tokenizeCompoundRuns(text, a.wordRuns)
+	return tokens
+}
+
+// fieldWords returns the words of a field value and, when compound words are enabled, its
+// hyphenated words, stemmed with the language of the field when stemming is enabled.
+func (a *Analyzer) fieldWords(text string, fieldName string) ([]string, []Compound) {
+	words, compounds := a.unstemmedFieldWords(text, fieldName)
+	language := a.locale
+	if fieldLanguage, ok := a.languageFields[fieldName]; ok {
+		language = fieldLanguage
+	}
+	if stem := a.stemmer(language); stem != nil {
+		a.stemWords(words, language)
+		for i := range compounds {
+			compounds[i].Word = stem(compounds[i].Word)
+		}
+	}
+	return words, compounds
+}
+
+// unstemmedFieldWords returns the words of a field value and, when compound words are enabled,
+// its hyphenated words. Removing stop words moves the compounds to the positions of the words kept.
+func (a *Analyzer) unstemmedFieldWords(text string, fieldName string) ([]string, []Compound) {
+	normalized := a.normalizeField(text, fieldName)
+	var tokens []string
+	var compounds []Compound
+	if a.compoundWords {
+		tokens, compounds = tokenizeCompoundRuns(normalized, a.wordRuns)
+	} else {
+		tokens = tokenizeRuns(splitCaseAndLower(normalized), a.wordRuns)
+	}
+	if a.isCJKField(fieldName) {
+		tokens, compounds = splitCJK(tokens, compounds)
+	}
+	if a.keepStopWordsIndexed || len(a.stopWords) == 0 {
+		return tokens, compounds
+	}
+
+	words := make([]string, 0, len(tokens))
+	wordIndexes := make([]int, len(tokens)+1) // Index in words of the first word kept from each token on
+	for i, token := range tokens {
+		wordIndexes[i] = len(words)
+		if !a.isStopWord(token) {
+			words = append(words, token)
+		}
+	}
+	wordIndexes[len(tokens)] = len(words)
+
+	kept := make([]Compound, 0, len(compounds))
+	for _, compound := range compounds {
+		if start, end := wordIndexes[compound.Start], wordIndexes[compound.End]; start < end {
+			kept = append(kept, Compound{Start: start, End: end, Word: compound.Word})
+		}
+	}
+	return words, kept
+}
+
+// removeStopWords returns the tokens that are not stop words.
+func (a *Analyzer) removeStopWords(tokens []string) []string {
+	if len(a.stopWords) == 0 {
+		return tokens
+	}
+	kept := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if !a.isStopWord(token) {
+			kept = append(kept, token)
+		}
+	}
+	return kept
+}
+
+// normalizeField normalizes a field value: per-language fields use the normalization of their
+// language, other fields the index locale.
+func (a *Analyzer) normalizeField(text string, fieldName string) string {
+	if language, ok := a.languageFields[fieldName]; ok {
+		return a.normalizeFor(language, text)
+	}
+	return a.Normalize(text)
+}
+
+// queryLanguage returns the language query text searched in the given fields is analyzed with:
+// the language of per-language fields that all share it, and the index locale otherwise.
+func (a *Analyzer) queryLanguage(fieldNames []string) string {
+	if language := a.commonLanguage(fieldNames); language != "" {
+		return language
+	}
+	return a.locale
+}
+
+// commonLanguage returns the language shared by fields that are all per-language fields, or "".
+func (a *Analyzer) commonLanguage(fieldNames []string) string {
+	language := ""
+	for _, fieldName := range fieldNames {
+		fieldLanguage, ok := a.languageFields[fieldName]
+		if !ok || (language != "" && fieldLanguage != language) {
+			return ""
+		}
+		language = fieldLanguage
+	}
+	return language
+}
+
+// normalizeFor applies the character normalization of a language.
+func (a *Analyzer) normalizeFor(language, text string) string {
+	return a.normalizeWith(localeNormalizer(language), text)
+}
+
+// normalizeWith applies the index's text normalization around a locale normalizer, which may be nil.
+// Keeping accents leaves out the locale normalizer, since it folds them.
+func (a *Analyzer) normalizeWith(localeNormalize func(string) string, text string) string {
+	if a.normalization == config.TextNormalizationKeepAccents {
+		return norm.NFKC.String(text)
+	}
+	if localeNormalize != nil {
+		text = localeNormalize(text)
+	}
+	if a.normalization == config.TextNormalizationFoldAccents {
+		return foldAccents(text)
+	}
+	return text
+}
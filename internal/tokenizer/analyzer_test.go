@@ -0,0 +1,263 @@
+package tokenizer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+)
+
+func TestAnalyzerLocaleNormalization(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		input  string
+		want   []string
+	}{
+		{"no locale keeps ascii tokenization", "", "Müller café", []string{"m", "ller", "caf"}},
+		{"german transliterates umlauts", "de", "Müller Straße", []string{"mueller", "strasse"}},
+		{"german region uses german analyzer", "de-CH", "Grüezi", []string{"grueezi"}},
+		{"french folds accents", "fr", "Crème Brûlée", []string{"creme", "brulee"}},
+		{"english folds accents", "en", "Café Naïve", []string{"cafe", "naive"}},
+		{"unsupported locale keeps ascii tokenization", "ja", "café", []string{"caf"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(&config.IndexSettings{Locale: tt.locale})
+			got := analyzer.Tokenize(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) with locale %q = %v, want %v", tt.input, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzerFieldTokens(t *testing.T) {
+	analyzer := NewAnalyzer(&config.IndexSettings{
+		Locale:                    "de",
+		FieldsWithoutPrefixSearch: []string{"isbn"},
+	})
+
+	if got, want := analyzer.FieldTokens("Öl", "title"), []string{"oel", "o", "oe"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldTokens for prefix field = %v, want %v", got, want)
+	}
+	if got, want := analyzer.FieldTokens("Öl", "isbn"), []string{"oel"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldTokens for field without prefix search = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzerLanguageFields(t *testing.T) {
+	analyzer := NewAnalyzer(&config.IndexSettings{
+		LanguageDetection: &config.LanguageDetection{Fields: []string{"title"}, Languages: []string{"en", "de"}},
+	})
+
+	if !analyzer.IsLanguageField("title.de") || analyzer.IsLanguageField("title") || analyzer.IsLanguageField("title.fr") {
+		t.Error("Expected only the per-language fields of the candidate languages to be language fields")
+	}
+	if got, want := analyzer.FieldWords("Müller", "title.de"), []string{"mueller"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords for a German field = %v, want %v", got, want)
+	}
+	if got, want := analyzer.FieldWords("Müller", "title"), []string{"m", "ller"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords for the source field = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("Müller", []string{"title.de"}), []string{"mueller"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields for German fields = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("Müller", []string{"title.de", "title.en"}), []string{"m", "ller"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields for mixed languages = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzerStopWords(t *testing.T) {
+	analyzer := NewAnalyzer(&config.IndexSettings{StopWords: &config.StopWords{}})
+
+	if got, want := analyzer.FieldWords("The Lord of the Rings", "title"), []string{"lord", "rings"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords = %v, want %v", got, want)
+	}
+	if got, want := analyzer.FieldTokens("The Office", "title"), []string{"office", "o", "of", "off", "offi", "offic"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldTokens = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("the office", nil), []string{"office"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("To be or not", nil), []string{"to", "be", "or", "not"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields for a query of stop words only = %v, want %v", got, want)
+	}
+	if got, want := analyzer.PhraseWords("lord of the rings", nil), []string{"lord", "rings"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PhraseWords = %v, want %v", got, want)
+	}
+
+	custom := NewAnalyzer(&config.IndexSettings{StopWords: &config.StopWords{Words: []string{"Der", "die"}, KeepInPhrases: true}})
+	if got, want := custom.FieldWords("Der Name der Rose", "title"), []string{"der", "name", "der", "rose"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords keeping stop words for phrases = %v, want %v", got, want)
+	}
+	if got, want := custom.TokenizeForFields("der name der rose", nil), []string{"name", "rose"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields with custom stop words = %v, want %v", got, want)
+	}
+	if got, want := custom.PhraseWords("der name der rose", nil), []string{"der", "name", "der", "rose"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PhraseWords keeping stop words = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzerCompoundWords(t *testing.T) {
+	analyzer := NewAnalyzer(&config.IndexSettings{CompoundWords: true, StopWords: &config.StopWords{}, FieldsWithoutPrefixSearch: []string{"code"}})
+
+	if got, want := analyzer.FieldWords("The Sci-Fi Classics", "title"), []string{"sci", "fi", "classics"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords = %v, want %v", got, want)
+	}
+	if got, want := analyzer.IndexedWords("The Sci-Fi Classics", "title"), []string{"sci", "fi", "classics", "scifi"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IndexedWords = %v, want %v", got, want)
+	}
+	wantPositions := map[string][]int{"sci": {0}, "fi": {1}, "classics": {2}, "scifi": {0}}
+	if got := analyzer.FieldWordPositions("The Sci-Fi Classics", "title"); !reflect.DeepEqual(got, wantPositions) {
+		t.Errorf("FieldWordPositions = %v, want %v", got, wantPositions)
+	}
+	if got, want := analyzer.IndexedWords("state-of-the-art", "title"), []string{"state", "art", "stateoftheart"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IndexedWords with stop word parts = %v, want %v", got, want)
+	}
+	if got, want := analyzer.FieldTokens("x-ray", "code"), []string{"x", "ray", "xray"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldTokens for field without prefix search = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("don't sci-fi", nil), []string{"dont", "sci", "fi"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields = %v, want %v", got, want)
+	}
+
+	disabled := NewAnalyzer(&config.IndexSettings{})
+	if got, want := disabled.IndexedWords("Don't sci-fi", "title"), []string{"don", "t", "sci", "fi"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IndexedWords without compound words = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzerWordCharacters(t *testing.T) {
+	analyzer := NewAnalyzer(&config.IndexSettings{WordCharacters: "_/-"})
+
+	if got, want := analyzer.FieldWords("Open src/main_test.go - now", "path"), []string{"open", "src/main_test", "go", "now"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("main_test sci-fi", nil), []string{"main_test", "sci-fi"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields = %v, want %v", got, want)
+	}
+
+	// A hyphen that is part of words makes no compounds of their parts
+	compounds := NewAnalyzer(&config.IndexSettings{WordCharacters: "-", CompoundWords: true})
+	if got, want := compounds.IndexedWords("Don't sci-fi", "title"), []string{"dont", "sci-fi"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IndexedWords with compound words = %v, want %v", got, want)
+	}
+
+	// Special characters of regular expressions are plain word characters
+	symbols := NewAnalyzer(&config.IndexSettings{WordCharacters: "]^\\+"})
+	if got, want := symbols.Tokenize("C++ a^b x]y a\\b"), []string{"c++", "a^b", "x]y", "a\\b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzerStemming(t *testing.T) {
+	analyzer := NewAnalyzer(&config.IndexSettings{
+		Locale:            "en-US",
+		Stemming:          true,
+		CompoundWords:     true,
+		StopWords:         &config.StopWords{Words: []string{"the"}},
+		LanguageDetection: &config.LanguageDetection{Fields: []string{"title"}, Languages: []string{"en", "de"}},
+	})
+
+	if got, want := analyzer.IndexedWords("The running dogs' re-runs", "title"), []string{"run", "dog", "re", "run", "rerun"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IndexedWords = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("the runs", nil), []string{"run"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields = %v, want %v", got, want)
+	}
+	if got, want := analyzer.PhraseWords("dogs running", []string{"title.en"}), []string{"dog", "run"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PhraseWords = %v, want %v", got, want)
+	}
+	if got, want := analyzer.Tokenize("running dogs"), []string{"running", "dogs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %v, want %v: only field and query words are stemmed", got, want)
+	}
+
+	// Per-language fields are stemmed in their own language, which may have no stemmer
+	if got, want := analyzer.FieldWords("Laufende Hunde", "title.de"), []string{"laufende", "hunde"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords of German field = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("Laufende Hunde", []string{"title.de"}), []string{"laufende", "hunde"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields of German field = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzerTextNormalization(t *testing.T) {
+	folding := NewAnalyzer(&config.IndexSettings{TextNormalization: config.TextNormalizationFoldAccents})
+	tests := map[string][]string{
+		"Café Crème": {"cafe", "creme"},
+		"ｆｕｌｌ－ｗｉｄｔｈ ＡＢＣ１２３": {"full", "width", "abc123"},
+		"Łódź Øresund ﬁnal": {"lodz", "oresund", "final"},
+		"Ελληνικά Привет":   {"ελληνικα", "привет"},
+		"नमस्ते":            {"नमस्ते"},
+	}
+	for input, want := range tests {
+		if got := folding.FieldWords(input, "title"); !reflect.DeepEqual(got, want) {
+			t.Errorf("FieldWords(%q) with fold_accents = %v, want %v", input, got, want)
+		}
+	}
+	if got, want := folding.TokenizeForFields("CAFÉ", nil), []string{"cafe"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields with fold_accents = %v, want %v", got, want)
+	}
+
+	// The German transliteration of the locale comes before folding
+	german := NewAnalyzer(&config.IndexSettings{Locale: "de", TextNormalization: config.TextNormalizationFoldAccents})
+	if got, want := german.FieldWords("Müller Straße", "title"), []string{"mueller", "strasse"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords with de locale = %v, want %v", got, want)
+	}
+
+	keeping := NewAnalyzer(&config.IndexSettings{Locale: "fr", TextNormalization: config.TextNormalizationKeepAccents})
+	if got, want := keeping.FieldWords("Résumé ＡＢＣ", "title"), []string{"résumé", "abc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords with keep_accents = %v, want %v", got, want)
+	}
+	// Decomposed and precomposed accents are the same token
+	if got, want := keeping.TokenizeForFields("re\u0301sume\u0301", nil), []string{"résumé"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields with keep_accents = %v, want %v", got, want)
+	}
+
+	// Without the setting, only ASCII letters make up tokens
+	if got, want := NewAnalyzer(&config.IndexSettings{}).FieldWords("Café", "title"), []string{"caf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords without normalization = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzerCJKFields(t *testing.T) {
+	analyzer := NewAnalyzer(&config.IndexSettings{
+		SearchableFields: []string{"title", "sku"},
+		CJKFields:        []string{"title"},
+	})
+	tests := map[string][]string{
+		"東京タワー":       {"東京", "京タ", "タワ", "ワー"},
+		"iPhone手机 新款": {"i", "phone", "手机", "新款"},
+		"서울 맛집。日":     {"서울", "맛집", "日"},
+		"Tokyo Tower": {"tokyo", "tower"},
+	}
+	for input, want := range tests {
+		if got := analyzer.FieldWords(input, "title"); !reflect.DeepEqual(got, want) {
+			t.Errorf("FieldWords(%q) = %v, want %v", input, got, want)
+		}
+	}
+
+	// Other fields keep CJK runs whole
+	if got, want := analyzer.FieldWords("東京タワー", "sku"), []string{"東京タワー"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldWords of a field not in cjk_fields = %v, want %v", got, want)
+	}
+
+	// Queries are split like the CJK fields they search
+	if got, want := analyzer.TokenizeForFields("東京タワー", nil), []string{"東京", "京タ", "タワ", "ワー"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields = %v, want %v", got, want)
+	}
+	if got, want := analyzer.TokenizeForFields("東京タワー", []string{"sku"}), []string{"東京タワー"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeForFields restricted to sku = %v, want %v", got, want)
+	}
+}
+
+func TestPrimaryLanguage(t *testing.T) {
+	tests := map[string]string{"de-CH": "de", "pt_BR": "pt", "EN": "en", "": ""}
+	for input, want := range tests {
+		if got := PrimaryLanguage(input); got != want {
+			t.Errorf("PrimaryLanguage(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tokenizer
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// germanReplacer transliterates umlauts and sharp s the way German speakers type them without
+// a German keyboard, so "Müller" and "Mueller" produce the same token.
+var germanReplacer = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss",
+	"Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ẞ", "SS",
+)
+
+// latinFoldings maps accented Latin letters to their unaccented ASCII form.
+var latinFoldings = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y", 'ß': "ss",
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE",
+	'Ç': "C", 'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ñ': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O", 'Œ': "OE",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ý': "Y",
+}
+
+// strokeFoldings maps Latin letters that have no decomposition into a base letter and a diacritic,
+// so NFKD leaves them, to their unaccented ASCII form.
+var strokeFoldings = map[rune]string{
+	'æ': "ae", 'ð': "d", 'đ': "d", 'ı': "i", 'ł': "l", 'ø': "o", 'œ': "oe", 'ß': "ss", 'þ': "th",
+	'Æ': "AE", 'Ð': "D", 'Đ': "D", 'Ł': "L", 'Ø': "O", 'Œ': "OE", 'ẞ': "SS", 'Þ': "TH",
+}
+
+// SupportedLocales lists the locales with a dedicated analyzer.
+var SupportedLocales = []string{"en", "de", "fr", "es", "it", "pt", "nl"}
+
+// PrimaryLanguage returns the lowercased primary language subtag of a locale ("de-CH" -> "de").
+func PrimaryLanguage(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if idx := strings.IndexAny(locale, "-_"); idx >= 0 {
+		return locale[:idx]
+	}
+	return locale
+}
+
+// IsSupportedLocale reports whether the locale's primary language has a dedicated analyzer.
+func IsSupportedLocale(locale string) bool {
+	language := PrimaryLanguage(locale)
+	for _, supported := range SupportedLocales {
+		if language == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// localeNormalizer returns the character normalization applied before tokenization for a locale.
+// Without a locale, text is tokenized as-is.
+func localeNormalizer(locale string) func(string) string {
+	switch PrimaryLanguage(locale) {
+	case "":
+		return nil
+	case "de":
+		return func(text string) string {
+			return foldLatin(germanReplacer.Replace(text))
+		}
+	default:
+		if !IsSupportedLocale(locale) {
+			return nil
+		}
+		return foldLatin
+	}
+}
+
+// foldLatin replaces accented Latin letters with their ASCII equivalent.
+func foldLatin(text string) string {
+	var builder strings.Builder
+	builder.Grow(len(text))
+	for _, r := range text {
+		if folded, ok := latinFoldings[r]; ok {
+			builder.WriteString(folded)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// foldAccents removes the diacritics of letters of any script and replaces compatibility characters
+// with their plain form ("Café" -> "Cafe", "ｆｕｌｌ" -> "full", "ﬁ" -> "fi"): text is decomposed with
+// NFKD, combining diacritical marks and Latin letter strokes are dropped, and the rest is composed
+// again with NFC, so scripts whose letters are made of combining marks are kept intact.
+func foldAccents(text string) string {
+	var builder strings.Builder
+	builder.Grow(len(text))
+	for _, r := range norm.NFKD.String(text) {
+		if isDiacritic(r) {
+			continue
+		}
+		if folded, ok := strokeFoldings[r]; ok {
+			builder.WriteString(folded)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(builder.String())
+}
+
+// isDiacritic reports whether r is in one of the blocks of combining diacritical marks.
+func isDiacritic(r rune) bool {
+	return (r >= 0x0300 && r <= 0x036F) || (r >= 0x1AB0 && r <= 0x1AFF) || (r >= 0x1DC0 && r <= 0x1DFF) ||
+		(r >= 0x20D0 && r <= 0x20FF) || (r >= 0xFE20 && r <= 0xFE2F)
+}
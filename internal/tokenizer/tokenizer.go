@@ -1,61 +1,228 @@
 package tokenizer
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
-// nonAlphanumericRegex matches sequences of non-alphanumeric characters.
-var nonAlphanumericRegex = regexp.MustCompile(`[^a-zA-Z0-9]+`)
-
 // acronymRegex handles cases like "HTTPRequest" -> "HTTP Request"
 var acronymRegex = regexp.MustCompile(`([A-Z]+)([A-Z][a-z])`)
 
 // camelCaseRegex handles cases like "theOffice" -> "the Office" or "myAPI" -> "my API"
 var camelCaseRegex = regexp.MustCompile(`([a-z0-9])([A-Z])`)
 
+// alphanumericRunRegex matches the runs of alphanumeric characters of lowercased text, which are
+// its tokens.
+var alphanumericRunRegex = regexp.MustCompile(`[a-z0-9]+`)
+
+// Character classes of the letters and digits that make up tokens
+const (
+	asciiLetters   = `a-z0-9`                                                  // ASCII letters and digits, the default
+	unicodeLetters = `\p{L}\p{M}\p{N}`                                         // Letters, combining marks and digits of any script, for Unicode-normalized text
+	cjkLetters     = `a-z0-9\p{Han}\p{Hiragana}\p{Katakana}\p{Hangul}\x{30fc}` // ASCII and CJK characters, for indexes with CJK fields
+)
+
+// wordRunRegexes caches the compiled word run regexes by letter class and word characters, since
+// analyzers are created for every operation.
+var wordRunRegexes sync.Map
+
+// wordRunRegex returns the regex matching the tokens of lowercased text: runs of the letters and
+// digits of a character class and of the given word characters, which are then part of words
+// instead of separating them ("_" keeps "my_variable" one token).
+func wordRunRegex(letters, wordCharacters string) *regexp.Regexp {
+	if letters == asciiLetters && wordCharacters == "" {
+		return alphanumericRunRegex
+	}
+	key := letters + "\x00" + wordCharacters
+	if cached, ok := wordRunRegexes.Load(key); ok {
+		return cached.(*regexp.Regexp)
+	}
+	var class strings.Builder
+	for _, r := range wordCharacters {
+		fmt.Fprintf(&class, `\x{%x}`, r)
+	}
+	wordRuns := regexp.MustCompile(`[` + letters + class.String() + `]+`)
+	wordRunRegexes.Store(key, wordRuns)
+	return wordRuns
+}
+
 // Tokenize converts a string into a slice of tokens.
 // It splits camel/PascalCase, lowercases the string, and splits by non-alphanumeric characters.
 func Tokenize(text string) []string {
-	// 1. Split camelCase/PascalCase
-	processedText := acronymRegex.ReplaceAllString(text, "$1 $2")
-	processedText = camelCaseRegex.ReplaceAllString(processedText, "$1 $2")
-
-	// 2. Lowercase
-	lowerText := strings.ToLower(processedText)
-
-	// 3. Split by non-alphanumeric characters
-	split := nonAlphanumericRegex.Split(lowerText, -1)
+	return tokenizeRuns(splitCaseAndLower(text), alphanumericRunRegex)
+}
 
+// tokenizeRuns returns the runs of lowercased text matched by wordRuns that have a letter or a
+// digit, so word characters alone ("-" in "rock - pop") are no token.
+func tokenizeRuns(lowerText string, wordRuns *regexp.Regexp) []string {
 	tokens := make([]string, 0) // Initialize as empty slice, not nil
-	for _, s := range split {
-		if s != "" { // Filter out empty strings
-			tokens = append(tokens, s)
+	for _, run := range wordRuns.FindAllString(lowerText, -1) {
+		if hasAlphanumeric(run) {
+			tokens = append(tokens, run)
 		}
 	}
 	return tokens
 }
 
+// isCJK reports whether r is a Chinese, Japanese or Korean character, written without spaces
+// between words.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー'
+}
+
+// cjkBigrams splits the runs of CJK characters of a token into overlapping two-character words,
+// since CJK text has no spaces between words to split it on ("東京タワー" -> "東京", "京タ", "タワ",
+// "ワー"). A run of a single character is kept as is, and so are the other parts of the token
+// ("iphone手机" -> "iphone", "手机").
+func cjkBigrams(token string) []string {
+	if strings.IndexFunc(token, isCJK) < 0 {
+		return []string{token}
+	}
+	runes := []rune(token)
+	var words []string
+	for start := 0; start < len(runes); {
+		cjk := isCJK(runes[start])
+		end := start + 1
+		for end < len(runes) && isCJK(runes[end]) == cjk {
+			end++
+		}
+		if !cjk || end-start == 1 {
+			words = append(words, string(runes[start:end]))
+		} else {
+			for i := start; i < end-1; i++ {
+				words = append(words, string(runes[i:i+2]))
+			}
+		}
+		start = end
+	}
+	return words
+}
+
+// splitCJK splits the CJK characters of tokens into two-character words with cjkBigrams, moving
+// the compounds to the positions of the words of their tokens.
+func splitCJK(tokens []string, compounds []Compound) ([]string, []Compound) {
+	words := make([]string, 0, len(tokens))
+	wordIndexes := make([]int, len(tokens)+1) // Index in words of the first word of each token
+	for i, token := range tokens {
+		wordIndexes[i] = len(words)
+		words = append(words, cjkBigrams(token)...)
+	}
+	wordIndexes[len(tokens)] = len(words)
+	for i := range compounds {
+		compounds[i].Start, compounds[i].End = wordIndexes[compounds[i].Start], wordIndexes[compounds[i].End]
+	}
+	return words, compounds
+}
+
+// Compound is a hyphenated word of tokenized text, such as "sci-fi": the range [Start, End) of the
+// tokens that are its parts, and the parts joined into one word ("scifi").
+type Compound struct {
+	Start int
+	End   int
+	Word  string
+}
+
+// TokenizeCompounds tokenizes text like Tokenize, except that apostrophes within words are dropped
+// instead of splitting them ("don't" -> "dont"). It also returns the hyphenated words of the text,
+// whose parts are tokens of their own ("sci-fi" -> "sci", "fi").
+func TokenizeCompounds(text string) ([]string, []Compound) {
+	return tokenizeCompoundRuns(text, alphanumericRunRegex)
+}
+
+// tokenizeCompoundRuns is TokenizeCompounds with the tokens matched by wordRuns. A hyphen that is
+// a word character is part of the tokens, so no compound is made of its parts.
+func tokenizeCompoundRuns(text string, wordRuns *regexp.Regexp) ([]string, []Compound) {
+	lowerText := joinContractions(splitCaseAndLower(text))
+
+	tokens := make([]string, 0)
+	compounds := make([]Compound, 0)
+	start, previousEnd := 0, 0
+	closeCompound := func() {
+		if len(tokens)-start > 1 {
+			compounds = append(compounds, Compound{Start: start, End: len(tokens), Word: strings.Join(tokens[start:], "")})
+		}
+		start = len(tokens)
+	}
+	for _, run := range wordRuns.FindAllStringIndex(lowerText, -1) {
+		if !hasAlphanumeric(lowerText[run[0]:run[1]]) {
+			continue
+		}
+		if len(tokens) > 0 && lowerText[previousEnd:run[0]] != "-" {
+			closeCompound()
+		}
+		tokens = append(tokens, lowerText[run[0]:run[1]])
+		previousEnd = run[1]
+	}
+	closeCompound()
+	return tokens, compounds
+}
+
+// splitCaseAndLower splits camelCase and PascalCase words and lowercases the text.
+func splitCaseAndLower(text string) string {
+	processedText := acronymRegex.ReplaceAllString(text, "$1 $2")
+	processedText = camelCaseRegex.ReplaceAllString(processedText, "$1 $2")
+	return strings.ToLower(processedText)
+}
+
+// joinContractions removes the apostrophes between two alphanumeric characters of lowercased
+// text, so contractions and elisions stay one word ("don't" -> "dont", "o'brien" -> "obrien").
+func joinContractions(text string) string {
+	if !strings.ContainsAny(text, "'’") {
+		return text
+	}
+	runes := []rune(text)
+	var builder strings.Builder
+	builder.Grow(len(text))
+	for i, r := range runes {
+		if (r == '\'' || r == '’') && i > 0 && i < len(runes)-1 && isLowerAlphanumeric(runes[i-1]) && isLowerAlphanumeric(runes[i+1]) {
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// isLowerAlphanumeric reports whether r is a character of tokens: a lowercase letter or a digit.
+// Letters other than ASCII ones only make up tokens of Unicode-normalized text.
+func isLowerAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || (r >= utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+}
+
+// hasAlphanumeric reports whether lowercased text has a letter or a digit.
+func hasAlphanumeric(text string) bool {
+	return strings.IndexFunc(text, isLowerAlphanumeric) >= 0
+}
+
 // GeneratePrefixNGrams creates n-grams from a token, starting from length 1 up to the token's length.
 // For example, for the token "search", it produces: "s", "se", "sea", "sear", "searc", "search".
+// Lengths count characters, so tokens with letters other than ASCII ones are not cut within a letter.
 func GeneratePrefixNGrams(token string) []string {
 	tokenLen := len(token)
 	if tokenLen == 0 {
 		return make([]string, 0) // Return empty slice instead of nil
 	}
 
-	ngrams := make([]string, tokenLen)
-	for i := 1; i <= tokenLen; i++ {
-		ngrams[i-1] = token[:i]
+	ngrams := make([]string, 0, tokenLen)
+	for i := range token {
+		if i > 0 {
+			ngrams = append(ngrams, token[:i])
+		}
 	}
-	return ngrams
+	return append(ngrams, token)
 }
 
 // TokenizeWithPrefixNGrams combines Tokenize and GeneratePrefixNGrams.
 // It produces original tokens and their prefix n-grams (from length 1).
 func TokenizeWithPrefixNGrams(text string) []string {
-	tokens := Tokenize(text)
+	return WithPrefixNGrams(Tokenize(text))
+}
 
+// WithPrefixNGrams returns the tokens followed by their prefix n-grams, without duplicates.
+func WithPrefixNGrams(tokens []string) []string {
 	result := make([]string, 0)             // Initialize as empty slice, not nil
 	seenNGrams := make(map[string]struct{}) // To avoid duplicate n-grams if tokens overlap etc.
 
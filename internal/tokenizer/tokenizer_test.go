@@ -44,6 +44,34 @@ func TestTokenize(t *testing.T) {
 	}
 }
 
+func TestTokenizeCompounds(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantTokens    []string
+		wantCompounds []Compound
+	}{
+		{"no compounds", "hello world", []string{"hello", "world"}, []Compound{}},
+		{"hyphenated word", "Sci-Fi movie", []string{"sci", "fi", "movie"}, []Compound{{Start: 0, End: 2, Word: "scifi"}}},
+		{"several parts", "a state-of-the-art lab", []string{"a", "state", "of", "the", "art", "lab"}, []Compound{{Start: 1, End: 5, Word: "stateoftheart"}}},
+		{"spaced hyphen is no compound", "rock - pop", []string{"rock", "pop"}, []Compound{}},
+		{"contraction", "Don't stop", []string{"dont", "stop"}, []Compound{}},
+		{"typographic apostrophe", "O’Brien", []string{"obrien"}, []Compound{}},
+		{"quotes are not joined", "'quoted' words", []string{"quoted", "words"}, []Compound{}},
+		{"contraction in a compound", "rock-'n'-roll", []string{"rock", "n", "roll"}, []Compound{}},
+		{"camelCase is still split", "theOffice x-ray", []string{"the", "office", "x", "ray"}, []Compound{{Start: 2, End: 4, Word: "xray"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, compounds := TokenizeCompounds(tt.input)
+			if !reflect.DeepEqual(tokens, tt.wantTokens) || !reflect.DeepEqual(compounds, tt.wantCompounds) {
+				t.Errorf("TokenizeCompounds(%q) = %v, %v, want %v, %v", tt.input, tokens, compounds, tt.wantTokens, tt.wantCompounds)
+			}
+		})
+	}
+}
+
 func TestGeneratePrefixNGrams(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -54,6 +82,7 @@ func TestGeneratePrefixNGrams(t *testing.T) {
 		{"single character", "a", []string{"a"}},
 		{"short token", "cat", []string{"c", "ca", "cat"}},
 		{"longer token", "search", []string{"s", "se", "sea", "sear", "searc", "search"}},
+		{"accented token", "café", []string{"c", "ca", "caf", "café"}},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), ExporterNone, 1)
+	if err != nil {
+		t.Fatalf("Setup() with no exporter error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+
+	if _, err := Setup(context.Background(), "zipkin", 1); err == nil {
+		t.Error("Setup() with an unknown exporter succeeded, want an error")
+	}
+	if _, err := Setup(context.Background(), ExporterStdout, 1.5); err == nil {
+		t.Error("Setup() with a sample ratio above 1 succeeded, want an error")
+	}
+}
+
+func TestStartAndEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	if id := TraceID(context.Background()); id != "" {
+		t.Errorf("TraceID() outside of a trace = %q, want none", id)
+	}
+
+	ctx, parent := StartRequest(context.Background(), "GET /indexes")
+	_, child := Start(ctx, "index.persist")
+	End(child, errors.New("disk full"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Got %d ended spans, want 2", len(spans))
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("Span started from the request's context is not a child of the request's span")
+	}
+	if spans[0].Status().Code != codes.Error || len(spans[0].Events()) == 0 {
+		t.Errorf("Span ended with an error has status %v and %d events, want the error recorded", spans[0].Status(), len(spans[0].Events()))
+	}
+	if id := TraceID(ctx); id != spans[1].SpanContext().TraceID().String() {
+		t.Errorf("TraceID() = %q, want the ID of the request's trace", id)
+	}
+
+	// Spans started without a context, such as those of searches run outside of requests, start
+	// their own trace
+	var noContext context.Context
+	_, root := Start(noContext, "search")
+	root.End()
+	if spans := recorder.Ended(); spans[2].Parent().IsValid() {
+		t.Error("Span started from a nil context has a parent")
+	}
+}
@@ -3,6 +3,7 @@ package typoutil
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 )
@@ -46,6 +47,31 @@ func generateTestTerms(count int, avgLength int) []string {
 	return terms
 }
 
+// generateVocabulary returns count distinct words made of syllables, whose prefixes are shared
+// the way those of natural language words are, unlike the random strings of generateTestTerms
+func generateVocabulary(count int) []string {
+	rng := rand.New(rand.NewSource(1))
+	onsets := []string{"", "b", "c", "d", "f", "g", "h", "l", "m", "n", "p", "r", "s", "t", "v", "st", "tr", "pl", "ch", "sh"}
+	vowels := []string{"a", "e", "i", "o", "u", "ea", "ou", "io"}
+	codas := []string{"", "", "n", "r", "s", "t", "l", "nd", "ng"}
+
+	seen := make(map[string]bool, count)
+	words := make([]string, 0, count)
+	for len(words) < count {
+		var word strings.Builder
+		for syllables := 1 + rng.Intn(4); syllables > 0; syllables-- {
+			word.WriteString(onsets[rng.Intn(len(onsets))])
+			word.WriteString(vowels[rng.Intn(len(vowels))])
+			word.WriteString(codas[rng.Intn(len(codas))])
+		}
+		if !seen[word.String()] {
+			seen[word.String()] = true
+			words = append(words, word.String())
+		}
+	}
+	return words
+}
+
 // Benchmark the original GenerateTypos function
 func BenchmarkGenerateTyposOriginal(b *testing.B) {
 	indexedTerms := generateTestTerms(1000, 6)
@@ -59,32 +85,44 @@ func BenchmarkGenerateTyposOriginal(b *testing.B) {
 	}
 }
 
-// Benchmark the simple version with early termination
-func BenchmarkGenerateTyposSimple(b *testing.B) {
+// Benchmark the term trie over the same terms
+func BenchmarkTermTrie(b *testing.B) {
 	indexedTerms := generateTestTerms(1000, 6)
 	queryTerms := []string{"action", "advnture", "comdy", "thrlr", "mysterey"}
 
+	trie := NewTermTrie(indexedTerms)
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		for _, term := range queryTerms {
-			_ = GenerateTyposSimple(term, indexedTerms, 1)
+			_ = trie.Typos(term, 1, 0)
 		}
 	}
 }
 
-// Benchmark the typo finder with cache
-func BenchmarkTypoFinderWithCache(b *testing.B) {
-	indexedTerms := generateTestTerms(1000, 6)
+// Benchmark the term trie on a million-term vocabulary, where scanning the terms takes tens of
+// milliseconds per lookup
+func BenchmarkTermTrieMillionTerms(b *testing.B) {
+	trie := NewTermTrie(generateVocabulary(1_000_000))
 	queryTerms := []string{"action", "advnture", "comdy", "thrlr", "mysterey"}
 
-	finder := NewTypoFinder(indexedTerms)
-	finder.UpdateIndexedTerms(indexedTerms)
+	for distance := 1; distance <= 2; distance++ {
+		b.Run(fmt.Sprintf("Distance_%d", distance), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = trie.Typos(queryTerms[i%len(queryTerms)], distance, 500)
+			}
+		})
+	}
+}
+
+// Benchmark inserting new terms one at a time, as the indexing service does
+func BenchmarkTermTrieInsert(b *testing.B) {
+	terms := generateTestTerms(b.N, 8)
+	trie := NewTermTrie(nil)
 
 	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		for _, term := range queryTerms {
-			_ = finder.GenerateTypos(term, 1, 10) // Limit to 10 results
-		}
+	for _, term := range terms {
+		trie = trie.Insert(term)
 	}
 }
 
@@ -104,22 +142,13 @@ func BenchmarkScaling(b *testing.B) {
 			}
 		})
 
-		b.Run(fmt.Sprintf("Simple_%d", size), func(b *testing.B) {
-			for i := 0; i < b.N; i++ {
-				for _, term := range queryTerms {
-					_ = GenerateTyposSimple(term, indexedTerms, 1)
-				}
-			}
-		})
-
-		b.Run(fmt.Sprintf("WithCache_%d", size), func(b *testing.B) {
-			finder := NewTypoFinder(indexedTerms)
-			finder.UpdateIndexedTerms(indexedTerms)
+		b.Run(fmt.Sprintf("Trie_%d", size), func(b *testing.B) {
+			trie := NewTermTrie(indexedTerms)
 			b.ResetTimer()
 
 			for i := 0; i < b.N; i++ {
 				for _, term := range queryTerms {
-					_ = finder.GenerateTypos(term, 1, 10)
+					_ = trie.Typos(term, 1, 0)
 				}
 			}
 		})
@@ -145,23 +174,6 @@ func BenchmarkEditDistance(b *testing.B) {
 	})
 }
 
-// Benchmark cache effectiveness
-func BenchmarkCacheEffectiveness(b *testing.B) {
-	indexedTerms := generateTestTerms(1000, 6)
-	// Use limited set of query terms to test cache hits
-	queryTerms := []string{"action", "advnture", "comdy", "action", "advnture", "comdy"}
-
-	finder := NewTypoFinder(indexedTerms)
-	finder.UpdateIndexedTerms(indexedTerms)
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		for _, term := range queryTerms {
-			_ = finder.GenerateTypos(term, 1, 10)
-		}
-	}
-}
-
 // Benchmark early termination effectiveness
 func BenchmarkEarlyTermination(b *testing.B) {
 	indexedTerms := generateTestTerms(5000, 6)
@@ -177,125 +189,14 @@ func BenchmarkEarlyTermination(b *testing.B) {
 		}
 	})
 
-	b.Run("Simple", func(b *testing.B) {
+	b.Run("Trie", func(b *testing.B) {
+		trie := NewTermTrie(indexedTerms)
+		b.ResetTimer()
+
 		for i := 0; i < b.N; i++ {
 			for _, term := range queryTerms {
-				_ = GenerateTyposSimple(term, indexedTerms, 1)
+				_ = trie.Typos(term, 1, 0)
 			}
 		}
 	})
 }
-
-func TestDualCriteriaStopping(t *testing.T) {
-	// Create a simple set with known distance 1 matches from our debug
-	simpleTermSet := []string{
-		"test", "tests", "testa", "testb", "testc", "testd", "teste", "testf", "testg", "testh",
-		"rest", "best", "nest", "pest", "west", "fest", "jest", "zest", "text", "temp",
-		"gest", "lest", "mest", "dest", "vest", "kest", "cest", "hest", "yest", "qest",
-	}
-
-	typoFinder := NewTypoFinder(simpleTermSet)
-	typoFinder.UpdateIndexedTerms(simpleTermSet)
-
-	t.Run("basic functionality verification", func(t *testing.T) {
-		// First verify that we can find matches at all
-		results := typoFinder.GenerateTypos("test", 1, 100)
-		if len(results) == 0 {
-			t.Fatal("Basic typo finding not working - no results found")
-		}
-		t.Logf("Basic check: Found %d matches for 'test' with distance 1", len(results))
-	})
-
-	t.Run("result limit enforcement", func(t *testing.T) {
-		// Test that result limit is enforced
-		startTime := time.Now()
-		results := typoFinder.GenerateTyposWithTimeLimit("test", 1, 5, 10*time.Second) // Only 5 results, long time
-		duration := time.Since(startTime)
-
-		if len(results) > 5 {
-			t.Errorf("Expected at most 5 results, got %d", len(results))
-		}
-
-		// Should complete quickly since we only need 5 results
-		if duration > 100*time.Millisecond {
-			t.Errorf("Expected to complete quickly when finding only 5 results, took %v", duration)
-		}
-
-		t.Logf("Result limit test: Found %d results in %v", len(results), duration)
-	})
-
-	t.Run("time limit enforcement", func(t *testing.T) {
-		// Create a larger dataset to test time limits
-		largeTermSet := make([]string, 10000)
-		for i := 0; i < 10000; i++ {
-			// Create terms that are exactly distance 1 from "test"
-			variations := []string{"aest", "best", "cest", "dest", "eest", "fest", "gest", "hest", "iest", "jest", "kest", "lest", "mest", "nest", "oest", "pest", "qest", "rest", "sest", "test", "uest", "vest", "west", "xest", "yest", "zest"}
-			base := variations[i%len(variations)]
-			largeTermSet[i] = fmt.Sprintf("%s%04d", base, i)
-		}
-
-		largeFinder := NewTypoFinder(largeTermSet)
-		largeFinder.UpdateIndexedTerms(largeTermSet)
-
-		startTime := time.Now()
-		results := largeFinder.GenerateTyposWithTimeLimit("test", 1, 10000, 1*time.Millisecond) // Very short time limit
-		duration := time.Since(startTime)
-
-		// Should stop due to time limit
-		if len(results) >= 10000 {
-			t.Errorf("Expected time limit to prevent finding all results, got %d", len(results))
-		}
-
-		// Duration should be reasonable (not too long)
-		if duration > 100*time.Millisecond {
-			t.Errorf("Expected to stop due to time limit quickly, took %v", duration)
-		}
-
-		t.Logf("Time limit test: Found %d results in %v", len(results), duration)
-	})
-
-	t.Run("real scenario - 500 results or 50ms", func(t *testing.T) {
-		// Test actual search engine parameters
-		startTime := time.Now()
-		results := typoFinder.GenerateTyposWithTimeLimit("test", 1, 500, 50*time.Millisecond)
-		duration := time.Since(startTime)
-
-		// Should work without error
-		if len(results) > 500 {
-			t.Errorf("Expected at most 500 results, got %d", len(results))
-		}
-
-		t.Logf("Real scenario: Found %d results in %v", len(results), duration)
-		t.Logf("✅ Dual criteria implementation working correctly!")
-	})
-
-	t.Run("warning when time limit reached with remaining terms", func(t *testing.T) {
-		// Create a large dataset to trigger time limit warning
-		largeTermSet := make([]string, 2000)
-		for i := 0; i < 2000; i++ {
-			// Create terms that are distance 1 from "test" to ensure matches
-			variations := []string{"best", "rest", "nest", "pest", "west", "fest", "jest", "zest", "test"}
-			base := variations[i%len(variations)]
-			largeTermSet[i] = fmt.Sprintf("%s_%04d", base, i)
-		}
-
-		largeFinder := NewTypoFinder(largeTermSet)
-		largeFinder.UpdateIndexedTerms(largeTermSet)
-
-		// Use very short time limit to trigger warning
-		startTime := time.Now()
-		results := largeFinder.GenerateTyposWithTimeLimit("test", 1, 500, 1*time.Millisecond)
-		duration := time.Since(startTime)
-
-		// Should complete quickly due to time limit
-		if duration > 50*time.Millisecond {
-			t.Errorf("Expected to stop due to time limit quickly, took %v", duration)
-		}
-
-		// Should have some results but likely not 500 due to time limit
-		t.Logf("Warning test: Found %d results in %v (check logs for warning message)", len(results), duration)
-
-		// Note: The warning should appear in the test output logs
-		// This test verifies the functionality works without failing
-	})
-}
@@ -0,0 +1,84 @@
+package typoutil
+
+import "strings"
+
+// minProtectedPrefixLength is the shortest typo candidate that is checked as a prefix
+// of a protected word. Shorter candidates are too ambiguous to block.
+const minProtectedPrefixLength = 3
+
+// ProtectedWords answers whether a term must not be reached through typo tolerance.
+// It is built once from the index's NonTypoTolerantWords and is safe for concurrent reads.
+type ProtectedWords struct {
+	words map[string]struct{} // Lowercased protected words for exact lookups
+	root  *prefixNode         // Prefix trie over the lowercased protected words
+}
+
+type prefixNode struct {
+	children map[rune]*prefixNode
+}
+
+// NewProtectedWords precompiles the given words into a lowercase set and a prefix trie.
+func NewProtectedWords(words []string) *ProtectedWords {
+	pw := &ProtectedWords{
+		words: make(map[string]struct{}, len(words)),
+		root:  &prefixNode{children: make(map[rune]*prefixNode)},
+	}
+
+	for _, word := range words {
+		lower := strings.ToLower(strings.TrimSpace(word))
+		if lower == "" {
+			continue
+		}
+		pw.words[lower] = struct{}{}
+
+		node := pw.root
+		for _, r := range lower {
+			child, ok := node.children[r]
+			if !ok {
+				child = &prefixNode{children: make(map[rune]*prefixNode)}
+				node.children[r] = child
+			}
+			node = child
+		}
+	}
+	return pw
+}
+
+// IsEmpty reports whether no protected words are configured.
+func (pw *ProtectedWords) IsEmpty() bool {
+	return pw == nil || len(pw.words) == 0
+}
+
+// Contains reports whether term equals a protected word (case-insensitive).
+func (pw *ProtectedWords) Contains(term string) bool {
+	if pw.IsEmpty() {
+		return false
+	}
+	_, ok := pw.words[strings.ToLower(term)]
+	return ok
+}
+
+// BlocksTypoTerm reports whether a typo candidate would match a protected word, either
+// exactly or as a prefix of one (e.g. "stal" for the protected word "stalin").
+func (pw *ProtectedWords) BlocksTypoTerm(term string) bool {
+	if pw.IsEmpty() {
+		return false
+	}
+	lower := strings.ToLower(term)
+	if _, ok := pw.words[lower]; ok {
+		return true
+	}
+	if len(lower) < minProtectedPrefixLength {
+		return false
+	}
+
+	node := pw.root
+	for _, r := range lower {
+		child, ok := node.children[r]
+		if !ok {
+			return false
+		}
+		node = child
+	}
+	return true
+}
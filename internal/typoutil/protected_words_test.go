@@ -0,0 +1,41 @@
+package typoutil
+
+import "testing"
+
+func TestProtectedWords(t *testing.T) {
+	pw := NewProtectedWords([]string{"Stalin", "covid", "  "})
+
+	tests := []struct {
+		term          string
+		contains      bool
+		blocksTypoHit bool
+		note          string
+	}{
+		{"stalin", true, true, "exact match"},
+		{"STALIN", true, true, "case-insensitive match"},
+		{"stal", false, true, "prefix of a protected word"},
+		{"st", false, false, "prefix shorter than the minimum length"},
+		{"stalingrad", false, false, "protected word is a prefix of the term"},
+		{"covd", false, false, "unrelated term"},
+		{"", false, false, "empty term"},
+	}
+
+	for _, test := range tests {
+		if got := pw.Contains(test.term); got != test.contains {
+			t.Errorf("Contains(%q) = %v; expected %v (%s)", test.term, got, test.contains, test.note)
+		}
+		if got := pw.BlocksTypoTerm(test.term); got != test.blocksTypoHit {
+			t.Errorf("BlocksTypoTerm(%q) = %v; expected %v (%s)", test.term, got, test.blocksTypoHit, test.note)
+		}
+	}
+}
+
+func TestProtectedWordsEmpty(t *testing.T) {
+	pw := NewProtectedWords(nil)
+	if !pw.IsEmpty() {
+		t.Error("expected empty protected words")
+	}
+	if pw.Contains("anything") || pw.BlocksTypoTerm("anything") {
+		t.Error("empty protected words should not block any term")
+	}
+}
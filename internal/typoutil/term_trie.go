@@ -0,0 +1,307 @@
+package typoutil
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// TermTrie is an immutable radix trie over a vocabulary, searched for the terms within an edit
+// distance of a query term by walking it with a Levenshtein automaton (see Typos). Inserting or
+// removing a term returns a new trie sharing the untouched nodes with the old one, so a trie can be
+// read without locks while a writer derives the next version. A nil *TermTrie is an empty trie.
+type TermTrie struct {
+	root *trieNode
+	size int
+}
+
+type trieNode struct {
+	label    string      // Edge label from the parent; empty for the root
+	first    rune        // First rune of label, read without loading the label when walking the trie
+	children []*trieNode // Sorted by first, which differs between children
+	terminal bool        // Whether the path to this node spells a term
+}
+
+// newTrieNode returns a node with the given edge label.
+func newTrieNode(label string) *trieNode {
+	return &trieNode{label: label, first: firstRune(label)}
+}
+
+// NewTermTrie builds a trie holding terms, which may be in any order and hold duplicates.
+func NewTermTrie(terms []string) *TermTrie {
+	sorted := slices.Compact(slices.Sorted(slices.Values(terms)))
+	if len(sorted) == 0 {
+		return nil
+	}
+	// UTF-8 preserves the order of code points, so terms sharing a first rune are contiguous
+	return &TermTrie{root: buildNode("", sorted, 0), size: len(sorted)}
+}
+
+// buildNode builds the node of the sorted terms, which share their first depth bytes.
+func buildNode(label string, terms []string, depth int) *trieNode {
+	node := newTrieNode(label)
+	if len(terms[0]) == depth {
+		node.terminal = true
+		terms = terms[1:]
+	}
+	for len(terms) > 0 {
+		r := firstRune(terms[0][depth:])
+		end := 1
+		for end < len(terms) && firstRune(terms[end][depth:]) == r {
+			end++
+		}
+		// The first and last of sorted terms share the prefix all of them share
+		common := depth + commonPrefixLength(terms[0][depth:], terms[end-1][depth:])
+		node.children = append(node.children, buildNode(terms[0][depth:common], terms[:end], common))
+		terms = terms[end:]
+	}
+	return node
+}
+
+// Len returns the number of terms in the trie.
+func (t *TermTrie) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.size
+}
+
+// Contains reports whether the trie holds term.
+func (t *TermTrie) Contains(term string) bool {
+	if t == nil {
+		return false
+	}
+	node := t.root
+	for term != "" {
+		i, found := node.child(firstRune(term))
+		if !found || !strings.HasPrefix(term, node.children[i].label) {
+			return false
+		}
+		node = node.children[i]
+		term = term[len(node.label):]
+	}
+	return node.terminal
+}
+
+// Insert returns a trie holding the terms of t and term. t is left unchanged.
+func (t *TermTrie) Insert(term string) *TermTrie {
+	if t.Contains(term) {
+		return t
+	}
+	root := &trieNode{}
+	if t != nil {
+		root = t.root
+	}
+	return &TermTrie{root: insertNode(root, term), size: t.Len() + 1}
+}
+
+// insertNode returns a copy of node holding rest below it, copying the nodes on its way.
+func insertNode(node *trieNode, rest string) *trieNode {
+	copied := *node
+	if rest == "" {
+		copied.terminal = true
+		return &copied
+	}
+	i, found := node.child(firstRune(rest))
+	if !found {
+		leaf := newTrieNode(rest)
+		leaf.terminal = true
+		copied.children = slices.Insert(slices.Clip(node.children), i, leaf)
+		return &copied
+	}
+
+	child := node.children[i]
+	var replacement *trieNode
+	if common := commonPrefixLength(child.label, rest); common == len(child.label) {
+		replacement = insertNode(child, rest[common:])
+	} else {
+		// The term leaves the child's edge midway, which is split where they diverge
+		lower := *child
+		lower.label, lower.first = child.label[common:], firstRune(child.label[common:])
+		replacement = newTrieNode(child.label[:common])
+		replacement.children = []*trieNode{&lower}
+		if common == len(rest) {
+			replacement.terminal = true
+		} else {
+			leaf := newTrieNode(rest[common:])
+			leaf.terminal = true
+			j, _ := replacement.child(leaf.first)
+			replacement.children = slices.Insert(replacement.children, j, leaf)
+		}
+	}
+	copied.children = slices.Clone(node.children)
+	copied.children[i] = replacement
+	return &copied
+}
+
+// Remove returns a trie holding the terms of t but term. t is left unchanged.
+func (t *TermTrie) Remove(term string) *TermTrie {
+	if !t.Contains(term) {
+		return t
+	}
+	return &TermTrie{root: removeNode(t.root, term), size: t.size - 1}
+}
+
+// removeNode returns a copy of node without rest below it, which the node must hold. Nodes left
+// without terms are dropped, and nodes left with a single child merged with it.
+func removeNode(node *trieNode, rest string) *trieNode {
+	copied := *node
+	if rest == "" {
+		copied.terminal = false
+		return &copied
+	}
+	i, _ := node.child(firstRune(rest))
+	child := node.children[i]
+	replacement := removeNode(child, rest[len(child.label):])
+	copied.children = slices.Clone(node.children)
+	switch {
+	case replacement.terminal || len(replacement.children) > 1:
+		copied.children[i] = replacement
+	case len(replacement.children) == 1:
+		merged := *replacement.children[0]
+		merged.label, merged.first = replacement.label+merged.label, replacement.first
+		copied.children[i] = &merged
+	default:
+		copied.children = slices.Delete(copied.children, i, i+1)
+	}
+	return &copied
+}
+
+// child returns the position of the child whose label starts with r, or where it would be inserted.
+func (n *trieNode) child(r rune) (int, bool) {
+	return slices.BinarySearchFunc(n.children, r, func(child *trieNode, r rune) int {
+		return int(child.first - r)
+	})
+}
+
+// Typos returns the terms of the trie within maxDistance edits of term, as counted by
+// CalculateEditDistance, other than term itself, in ascending order. At most maxResults terms are
+// returned, unless maxResults is 0.
+//
+// The trie is walked with the rows of the edit distance matrix of term as the states of a
+// Levenshtein automaton: a branch is left as soon as no term below it can be within maxDistance,
+// so a lookup visits the terms close to term rather than the whole vocabulary.
+func (t *TermTrie) Typos(term string, maxDistance int, maxResults int) []string {
+	search := &typoSearch{
+		target:      []rune(term),
+		maxDistance: maxDistance,
+		maxResults:  maxResults,
+		typos:       make([]string, 0),
+	}
+	if t == nil || maxDistance <= 0 || term == "" {
+		return search.typos
+	}
+	first := make([]int, len(search.target)+1)
+	for j := range first {
+		first[j] = j
+	}
+	search.rows = [][]int{first}
+	search.visit(t.root)
+	return search.typos
+}
+
+// typoSearch is the state of a walk of a trie looking for typos of a term.
+type typoSearch struct {
+	target      []rune
+	maxDistance int
+	maxResults  int
+	path        []rune  // Runes from the root to the node visited
+	rows        [][]int // rows[i] holds the edit distances between path[:i] and the prefixes of target; rows past len(path) are reused
+	typos       []string
+}
+
+// visit walks node, whose label follows s.path, and its children. It reports whether the search
+// goes on, which it doesn't once maxResults typos are found.
+func (s *typoSearch) visit(node *trieNode) bool {
+	depth := len(s.path)
+	goOn := s.walk(node)
+	s.path = s.path[:depth]
+	return goOn
+}
+
+// walk is visit without restoring s.path.
+func (s *typoSearch) walk(node *trieNode) bool {
+	if node.label != "" {
+		// Most branches are left at their first rune, before their label is loaded
+		if !s.step(node.first) {
+			return true
+		}
+		for _, r := range node.label[utf8.RuneLen(node.first):] {
+			if !s.step(r) {
+				return true
+			}
+		}
+	}
+	if node.terminal {
+		if distance := s.rows[len(s.path)][len(s.target)]; distance > 0 && distance <= s.maxDistance {
+			s.typos = append(s.typos, string(s.path))
+			if s.maxResults > 0 && len(s.typos) >= s.maxResults {
+				return false
+			}
+		}
+	}
+	for _, child := range node.children {
+		if !s.visit(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// step extends the path with r and computes its row of edit distances, like a row of
+// CalculateEditDistance. It reports whether terms continuing the path can be within maxDistance.
+//
+// Cells further than maxDistance from the diagonal exceed it, so only the band around the diagonal
+// is computed and the cells bordering it are set to maxDistance + 1.
+func (s *typoSearch) step(r rune) bool {
+	s.path = append(s.path, r)
+	i := len(s.path)
+	if i == len(s.rows) {
+		s.rows = append(s.rows, make([]int, len(s.target)+1))
+	}
+	prevRow, currRow := s.rows[i-1], s.rows[i]
+	beyond := s.maxDistance + 1
+
+	first, last := max(1, i-s.maxDistance), min(len(s.target), i+s.maxDistance)
+	if first == 1 {
+		currRow[0] = i
+	} else {
+		currRow[first-1] = beyond
+	}
+	if last < len(s.target) {
+		currRow[last+1] = beyond
+	}
+	minInRow := min(currRow[first-1], beyond)
+	for j := first; j <= last; j++ {
+		cost := 0
+		if s.target[j-1] != r {
+			cost = 1
+		}
+		currRow[j] = min(prevRow[j]+1, currRow[j-1]+1, prevRow[j-1]+cost)
+		if i > 1 && j > 1 && r == s.target[j-2] && s.path[i-2] == s.target[j-1] {
+			currRow[j] = min(currRow[j], s.rows[i-2][j-2]+cost)
+		}
+		minInRow = min(minInRow, currRow[j])
+	}
+	return minInRow <= s.maxDistance
+}
+
+// firstRune returns the first rune of s.
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// commonPrefixLength returns the length in bytes of the longest common prefix of a and b made of
+// whole runes.
+func commonPrefixLength(a, b string) int {
+	length := 0
+	for length < len(a) && length < len(b) {
+		ra, size := utf8.DecodeRuneInString(a[length:])
+		if rb, _ := utf8.DecodeRuneInString(b[length:]); ra != rb {
+			break
+		}
+		length += size
+	}
+	return length
+}
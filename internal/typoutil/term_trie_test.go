@@ -0,0 +1,127 @@
+package typoutil
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestTermTrieTypos(t *testing.T) {
+	trie := NewTermTrie([]string{"the", "form", "from", "farm", "firm", "fork", "receive", "recieve", "calendar", "calender", "form"})
+
+	tests := []struct {
+		term        string
+		maxDistance int
+		maxResults  int
+		expected    []string
+		note        string
+	}{
+		{"form", 1, 0, []string{"farm", "firm", "fork", "from"}, "transpositions count as one edit"},
+		{"teh", 1, 0, []string{"the"}, "transposition of the last runes"},
+		{"recieve", 1, 0, []string{"receive"}, "the term itself is left out"},
+		{"fxrm", 2, 0, []string{"farm", "firm", "fork", "form", "from"}, "two edits"},
+		{"form", 1, 2, []string{"farm", "firm"}, "results are limited"},
+		{"xyz", 1, 0, []string{}, "no terms close enough"},
+		{"form", 0, 0, []string{}, "no edits allowed"},
+		{"", 1, 0, []string{}, "empty term"},
+	}
+	for _, test := range tests {
+		if got := trie.Typos(test.term, test.maxDistance, test.maxResults); !slices.Equal(got, test.expected) {
+			t.Errorf("Typos(%q, %d, %d) = %v; expected %v (%s)", test.term, test.maxDistance, test.maxResults, got, test.expected, test.note)
+		}
+	}
+
+	var empty *TermTrie
+	if got := empty.Typos("form", 1, 0); got == nil || len(got) != 0 {
+		t.Errorf("Typos on an empty trie = %#v; expected an empty slice", got)
+	}
+}
+
+func TestTermTrieUnicode(t *testing.T) {
+	trie := NewTermTrie([]string{"café", "cafè", "cafe", "straße", "strasse"})
+
+	if got, expected := trie.Typos("cafe", 1, 0), []string{"cafè", "café"}; !slices.Equal(got, expected) {
+		t.Errorf("Typos(cafe) = %v; expected %v: accented runes are one edit", got, expected)
+	}
+	if got, expected := trie.Typos("strase", 1, 0), []string{"strasse", "straße"}; !slices.Equal(got, expected) {
+		t.Errorf("Typos(strase) = %v; expected %v", got, expected)
+	}
+}
+
+func TestTermTrieInsertRemove(t *testing.T) {
+	base := NewTermTrie([]string{"test", "team"})
+	added := base.Insert("tent").Insert("tests").Insert("te")
+	removed := added.Remove("test").Remove("missing")
+
+	if base.Len() != 2 || base.Contains("tent") {
+		t.Errorf("Insert changed the trie it was called on")
+	}
+	if !added.Contains("test") || added.Len() != 5 {
+		t.Errorf("Remove changed the trie it was called on")
+	}
+	for _, term := range []string{"team", "tent", "tests", "te"} {
+		if !removed.Contains(term) {
+			t.Errorf("Contains(%q) = false after removing another term", term)
+		}
+	}
+	if removed.Contains("test") || removed.Contains("tes") || removed.Len() != 4 {
+		t.Errorf("Remove left %q in the trie", "test")
+	}
+
+	emptied := removed
+	for _, term := range []string{"team", "tent", "tests", "te"} {
+		emptied = emptied.Remove(term)
+	}
+	if emptied.Len() != 0 || len(emptied.Typos("team", 2, 0)) != 0 {
+		t.Errorf("Removing every term left %d terms", emptied.Len())
+	}
+}
+
+// TestTermTrieMatchesScan checks the trie against scanning the terms with CalculateEditDistance,
+// as the trie is changed term by term.
+func TestTermTrieMatchesScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomTerm := func() string {
+		runes := make([]rune, 2+rng.Intn(6))
+		for i := range runes {
+			runes[i] = []rune("abcdeé")[rng.Intn(6)]
+		}
+		return string(runes)
+	}
+
+	terms := make(map[string]bool)
+	var trie *TermTrie
+	for round := 0; round < 2000; round++ {
+		term := randomTerm()
+		if terms[term] && rng.Intn(2) == 0 {
+			delete(terms, term)
+			trie = trie.Remove(term)
+		} else {
+			terms[term] = true
+			trie = trie.Insert(term)
+		}
+		if round%100 != 0 {
+			continue
+		}
+
+		var list []string
+		for term := range terms {
+			list = append(list, term)
+		}
+		rebuilt := NewTermTrie(list)
+		for distance := 1; distance <= 2; distance++ {
+			query := randomTerm()
+			expected := GenerateTypos(query, list, distance)
+			slices.Sort(expected)
+			if got := trie.Typos(query, distance, 0); !slices.Equal(got, expected) {
+				t.Fatalf("Typos(%q, %d) = %v; expected %v", query, distance, got, expected)
+			}
+			if got := rebuilt.Typos(query, distance, 0); !slices.Equal(got, expected) {
+				t.Fatalf("Typos(%q, %d) of a built trie = %v; expected %v", query, distance, got, expected)
+			}
+		}
+		if trie.Len() != len(terms) {
+			t.Fatalf("Len() = %d; expected %d", trie.Len(), len(terms))
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package model
+
+// Alias is a name that search and document requests can use in place of the index it points to
+type Alias struct {
+	Name  string `json:"alias"`
+	Index string `json:"index"`
+}
@@ -6,7 +6,8 @@ import "time"
 type SearchEvent struct {
 	IndexName    string        `json:"index_name"`
 	Query        string        `json:"query"`
-	SearchType   string        `json:"search_type"` // "exact_match", "fuzzy_search", "filtered", "wildcard"
+	QueryID      string        `json:"query_id,omitempty"` // ID of the search response, for correlating with clicks or logs
+	SearchType   string        `json:"search_type"`        // "exact_match", "fuzzy_search", "filtered", "wildcard"
 	ResponseTime time.Duration `json:"response_time"`
 	ResultCount  int           `json:"result_count"`
 	Timestamp    time.Time     `json:"timestamp"`
@@ -19,6 +20,45 @@ type PopularSearch struct {
 	TrendChange string `json:"trend_change,omitempty"` // "up", "down", "stable"
 }
 
+// PopularSearchesReport lists the most frequent successful queries of an index over a time window
+type PopularSearchesReport struct {
+	IndexName string          `json:"index_name"`
+	Window    string          `json:"window"` // Duration looked back from now, e.g. "24h0m0s"
+	Searches  []PopularSearch `json:"searches"`
+}
+
+// QueryStats represents aggregated data for a query of an index, whether or not it returned results
+type QueryStats struct {
+	Query             string    `json:"query"`
+	SearchCount       int       `json:"search_count"`
+	ZeroResultCount   int       `json:"zero_result_count"` // Searches that returned no results
+	AvgResponseTimeMs float64   `json:"avg_response_time_ms"`
+	LastQueryID       string    `json:"last_query_id,omitempty"` // Query ID of the latest search
+	LastSearchedAt    time.Time `json:"last_searched_at"`
+}
+
+// QueryStatsReport lists queries of an index over a time window, e.g. the most frequent or those
+// that most often returned no results
+type QueryStatsReport struct {
+	IndexName string       `json:"index_name"`
+	Window    string       `json:"window"` // Duration looked back from now, e.g. "24h0m0s"
+	Queries   []QueryStats `json:"queries"`
+}
+
+// LatencyReport summarizes the response times of the searches of an index over a time window.
+// Percentiles use the nearest-rank method, so each is the response time of an actual search.
+type LatencyReport struct {
+	IndexName   string  `json:"index_name"`
+	Window      string  `json:"window"` // Duration looked back from now, e.g. "24h0m0s"
+	SearchCount int     `json:"search_count"`
+	AvgMs       float64 `json:"avg_ms"`
+	P50Ms       float64 `json:"p50_ms"`
+	P90Ms       float64 `json:"p90_ms"`
+	P95Ms       float64 `json:"p95_ms"`
+	P99Ms       float64 `json:"p99_ms"`
+	MaxMs       float64 `json:"max_ms"`
+}
+
 // IndexStats represents statistics for a specific index
 type IndexStats struct {
 	IndexName     string  `json:"index_name"`
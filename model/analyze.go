@@ -0,0 +1,20 @@
+package model
+
+// AnalyzeRequest is text to run through an index's analyzer, as the value of a field and as a query
+type AnalyzeRequest struct {
+	Text  string `json:"text"`
+	Field string `json:"field"`
+	Query string `json:"query,omitempty"` // Query analyzed instead of Text on the query side, when set
+}
+
+// AnalyzeResult shows the tokens an index produces for a field value and for a query
+type AnalyzeResult struct {
+	Field                string   `json:"field"`
+	Locale               string   `json:"locale,omitempty"`
+	PrefixSearch         bool     `json:"prefix_search"`          // Whether prefix n-grams are indexed for the field
+	TypoTolerance        bool     `json:"typo_tolerance"`         // Whether typo matches are accepted in the field
+	IndexTokens          []string `json:"index_tokens"`           // Whole words indexed for the field value
+	IndexNGrams          []string `json:"index_ngrams"`           // Prefix n-grams indexed for the field value, excluding whole words
+	QueryTokens          []string `json:"query_tokens"`           // Query tokens after analysis and query rewriting
+	UnmatchedQueryTokens []string `json:"unmatched_query_tokens"` // Query tokens not among the indexed tokens and n-grams, before typo tolerance
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// APIKeyAction is a kind of request an API key can be allowed to make
+type APIKeyAction string
+
+const (
+	APIKeyActionSearch         APIKeyAction = "search"          // Search, multi-search, suggest and search exports
+	APIKeyActionDocumentsRead  APIKeyAction = "documents.read"  // Get, list and export documents
+	APIKeyActionDocumentsWrite APIKeyAction = "documents.write" // Add, delete and batch documents
+	APIKeyActionIndexesRead    APIKeyAction = "indexes.read"    // Read indexes, their settings, stats, rules, jobs and analytics
+	APIKeyActionIndexesWrite   APIKeyAction = "indexes.write"   // Create, change and delete indexes, settings, rules and aliases
+)
+
+// APIKey is a scoped key for the HTTP API. Its secret is not stored, only a hash of it, so it is
+// returned once, when the key is created.
+// A key with Indexes only reaches the indexes matching one of its patterns, and a key with Filters
+// can only search and read documents, and only reads the documents matching its filter conditions.
+type APIKey struct {
+	ID          string         `json:"id"`
+	Description string         `json:"description,omitempty"`
+	Actions     []APIKeyAction `json:"actions"`
+	Indexes     []string       `json:"indexes,omitempty"`    // Index name patterns, as in path.Match; empty allows every index
+	Filters     []RuleFilter   `json:"filters,omitempty"`    // Filter conditions every document the key reads must match
+	ExpiresAt   *time.Time     `json:"expires_at,omitempty"` // The key is rejected from this time on; nil never expires
+	KeyPrefix   string         `json:"key_prefix"`           // First characters of the secret, to tell keys apart
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// CreatedAPIKey is a newly created API key with its secret.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// BatchInfo describes an open write batch and the changes staged in it.
+// Staged changes are not visible to searches until the batch is committed.
+type BatchInfo struct {
+	ID              string    `json:"id"`
+	IndexName       string    `json:"index_name"`
+	StagedDocuments int       `json:"staged_documents"`
+	StagedDeletions int       `json:"staged_deletions"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
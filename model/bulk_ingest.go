@@ -0,0 +1,17 @@
+package model
+
+// BulkLineError is a line of a bulk ingest that was skipped because it is not a valid document
+type BulkLineError struct {
+	Line  int    `json:"line"` // Line number, starting at 1
+	Error string `json:"error"`
+}
+
+// BulkIngestReport is the result of indexing newline-delimited JSON documents
+type BulkIngestReport struct {
+	IndexName string          `json:"index_name"`
+	Lines     int             `json:"lines"`   // Non-empty lines read
+	Indexed   int             `json:"indexed"` // Documents indexed
+	Failed    int             `json:"failed"`  // Lines skipped because they are not valid documents
+	Errors    []BulkLineError `json:"errors"`  // The first lines skipped, up to a limit
+	Seq       uint64          `json:"seq"`     // Sequence number of the index once the documents were indexed
+}
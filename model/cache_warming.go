@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// CacheWarmingStats describes the background re-execution of an index's popular queries after writes
+type CacheWarmingStats struct {
+	IntervalMs    int64      `json:"interval_ms"`            // How often the index is checked for writes
+	TopQueries    int        `json:"top_queries"`            // Number of popular queries re-executed
+	MaxQPS        float64    `json:"max_qps"`                // Maximum warming queries per second
+	Runs          int64      `json:"runs"`                   // Warming passes run after writes
+	QueriesWarmed int64      `json:"queries_warmed"`         // Queries re-executed over all passes
+	LastWarmAt    *time.Time `json:"last_warm_at,omitempty"` // When the last pass finished
+}
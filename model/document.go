@@ -27,3 +27,19 @@ func (d Document) GetPopularity() (float64, bool) {
 	}
 	return 0, false
 }
+
+// DocumentList is a page of the documents of an index, in documentID order
+type DocumentList struct {
+	Documents []Document `json:"documents"`
+	Total     int        `json:"total"`
+	Page      int        `json:"page"`
+	PageSize  int        `json:"page_size"`
+	Pages     int        `json:"pages"`
+}
+
+// MultiGetResult holds the documents fetched by ID, in the order they were requested, and the
+// requested IDs that are not in the index
+type MultiGetResult struct {
+	Documents []Document `json:"documents"`
+	Missing   []string   `json:"missing"`
+}
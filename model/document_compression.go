@@ -0,0 +1,11 @@
+package model
+
+// DocumentCompressionStats describes how an index's stored documents are compressed
+type DocumentCompressionStats struct {
+	Algorithm           string `json:"algorithm"`            // Compression algorithm
+	CompressedDocuments int    `json:"compressed_documents"` // Documents stored compressed
+	CompressedBytes     int64  `json:"compressed_bytes"`     // Total size of the compressed documents
+	CachedDocuments     int    `json:"cached_documents"`     // Decompressed documents currently in the LRU cache
+	CacheHits           int64  `json:"cache_hits"`           // Reads of compressed documents served from the cache
+	CacheMisses         int64  `json:"cache_misses"`         // Reads of compressed documents that decompressed them
+}
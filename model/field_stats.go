@@ -0,0 +1,7 @@
+package model
+
+// FieldStats describes the length of a searchable field across an index's documents
+type FieldStats struct {
+	TotalLength   int     `json:"total_length"`   // Word occurrences in the field, across all documents
+	AverageLength float64 `json:"average_length"` // Words per document, counting documents without the field as 0
+}
@@ -0,0 +1,9 @@
+package model
+
+// IndexState is whether an index is loaded in memory, or closed to free the memory it used
+type IndexState string
+
+const (
+	IndexStateOpen   IndexState = "open"   // Loaded and serving requests
+	IndexStateClosed IndexState = "closed" // Only on disk until it is opened again
+)
@@ -0,0 +1,41 @@
+package model
+
+// IntegrityIssueType identifies an inconsistency between the document store and the inverted index
+type IntegrityIssueType string
+
+const (
+	// IntegrityOrphanedPosting is a posting that refers to a document missing from the document store
+	IntegrityOrphanedPosting IntegrityIssueType = "orphaned_posting"
+	// IntegrityDuplicatePosting is a second posting of a term for the same document and field
+	IntegrityDuplicatePosting IntegrityIssueType = "duplicate_posting"
+	// IntegrityDanglingMapping is a document ID mapped to a missing document
+	IntegrityDanglingMapping IntegrityIssueType = "dangling_mapping"
+	// IntegrityDuplicateInternalID is a document ID mapped to a document stored under another document ID
+	IntegrityDuplicateInternalID IntegrityIssueType = "duplicate_internal_id"
+	// IntegrityMissingMapping is a stored document whose document ID is not mapped
+	IntegrityMissingMapping IntegrityIssueType = "missing_mapping"
+	// IntegrityUnreachableDocument is a stored document without a document ID, or whose document ID maps to another document
+	IntegrityUnreachableDocument IntegrityIssueType = "unreachable_document"
+	// IntegrityStaleNextID is a next internal ID that would overwrite stored documents
+	IntegrityStaleNextID IntegrityIssueType = "stale_next_id"
+)
+
+// IntegrityIssue is a single inconsistency found while verifying an index
+type IntegrityIssue struct {
+	Type       IntegrityIssueType `json:"type"`
+	DocumentID string             `json:"document_id,omitempty"`
+	InternalID uint32             `json:"internal_id"`
+	Term       string             `json:"term,omitempty"`
+	Field      string             `json:"field,omitempty"`
+}
+
+// IntegrityReport is the result of cross-checking an index's document store and inverted index
+type IntegrityReport struct {
+	IndexName   string                     `json:"index_name"`
+	Documents   int                        `json:"documents"`
+	Terms       int                        `json:"terms"`
+	Healthy     bool                       `json:"healthy"`
+	IssueCounts map[IntegrityIssueType]int `json:"issue_counts"`
+	Issues      []IntegrityIssue           `json:"issues"`   // The first issues found, up to a limit
+	Repaired    bool                       `json:"repaired"` // Whether the issues were repaired
+}
@@ -23,10 +23,14 @@ const (
 	JobTypeUpdateSettings JobType = "update_settings"
 	JobTypeCreateIndex    JobType = "create_index"
 	JobTypeDeleteIndex    JobType = "delete_index"
+	JobTypeDeleteIndexes  JobType = "delete_indexes" // Indexes matching a pattern, in one job
 	JobTypeAddDocuments   JobType = "add_documents"
 	JobTypeDeleteAllDocs  JobType = "delete_all_docs"
 	JobTypeDeleteDocument JobType = "delete_document"
 	JobTypeRenameIndex    JobType = "rename_index"
+	JobTypeCommitBatch    JobType = "commit_batch"
+	JobTypeRollback       JobType = "rollback"
+	JobTypeSearchExport   JobType = "search_export"
 )
 
 // Job represents a long-running background operation
@@ -41,13 +45,35 @@ type Job struct {
 	StartedAt   *time.Time        `json:"started_at,omitempty"`
 	CompletedAt *time.Time        `json:"completed_at,omitempty"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+	// Cancellation was requested while the job was running; it stops at the next point it can
+	CancelRequested bool `json:"cancel_requested,omitempty"`
+}
+
+// IsFinished reports whether the job has completed, failed or been cancelled.
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
+
+// JobFilter selects the jobs to list; empty fields match every job
+type JobFilter struct {
+	IndexName string
+	Status    JobStatus
+	Type      JobType
+}
+
+// Matches reports whether a job is selected by the filter.
+func (f JobFilter) Matches(job *Job) bool {
+	return (f.IndexName == "" || job.IndexName == f.IndexName) &&
+		(f.Status == "" || job.Status == f.Status) &&
+		(f.Type == "" || job.Type == f.Type)
 }
 
 // JobProgress tracks the progress of a job
 type JobProgress struct {
-	Current int    `json:"current"`
-	Total   int    `json:"total"`
-	Message string `json:"message,omitempty"`
+	Current    int     `json:"current"`
+	Total      int     `json:"total"`
+	Percentage float64 `json:"percentage"` // Current out of Total, from 0 to 100
+	Message    string  `json:"message,omitempty"`
 }
 
 // GetProgressPercentage returns the progress as a percentage (0-100)
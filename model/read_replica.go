@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// ReadReplicaStats describes the in-memory copy of an index that serves its searches
+type ReadReplicaStats struct {
+	RefreshIntervalMs int64      `json:"refresh_interval_ms"`       // How often writes are merged into the copy
+	Refreshes         int64      `json:"refreshes"`                 // Merges that brought changes into the copy
+	LastRefreshAt     *time.Time `json:"last_refresh_at,omitempty"` // When changes were last merged
+	DocumentCount     int        `json:"document_count"`            // Documents visible to searches
+}
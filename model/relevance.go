@@ -0,0 +1,30 @@
+package model
+
+// DefaultRelevanceTopN is how many of the top hits a relevance test checks when TopN is not set
+const DefaultRelevanceTopN = 10
+
+// RelevanceTest asserts that a query ranks a document among its top hits
+type RelevanceTest struct {
+	Name       string `json:"name,omitempty"`
+	Query      string `json:"query"`
+	DocumentID string `json:"document_id"`
+	TopN       int    `json:"top_n,omitempty"` // Number of top hits the document must be in; defaults to DefaultRelevanceTopN
+}
+
+// RelevanceTestResult is the outcome of running a relevance test
+type RelevanceTestResult struct {
+	RelevanceTest
+	Passed   bool   `json:"passed"`
+	Position int    `json:"position,omitempty"` // 1-based position of the document in the hits, when it is in the top N
+	Total    int    `json:"total"`              // Number of hits of the query
+	Error    string `json:"error,omitempty"`    // Why the query could not run
+}
+
+// RelevanceReport is the outcome of running the relevance tests of an index
+type RelevanceReport struct {
+	IndexName string                `json:"index_name"`
+	Passed    bool                  `json:"passed"` // Whether every test passed
+	Total     int                   `json:"total"`
+	Failures  int                   `json:"failures"`
+	Results   []RelevanceTestResult `json:"results"`
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"time"
+)
+
+// RuleActionType identifies what a rule does to the results of a matching query
+type RuleActionType string
+
+const (
+	RuleActionPin          RuleActionType = "pin"           // Place documents at a fixed position
+	RuleActionHide         RuleActionType = "hide"          // Remove documents from the results
+	RuleActionBoost        RuleActionType = "boost"         // Change the scores of documents before ranking
+	RuleActionRewriteQuery RuleActionType = "rewrite_query" // Replace or extend the query before it runs
+	RuleActionAddFilter    RuleActionType = "add_filter"    // Add a filter condition to the query before it runs
+)
+
+// RuleRewriteMode controls how a rewrite_query action changes the query
+type RuleRewriteMode string
+
+const (
+	RuleRewriteReplace RuleRewriteMode = "replace" // Replace the query with the action's query
+	RuleRewriteAppend  RuleRewriteMode = "append"  // Add the action's query to the end of the query
+)
+
+// RuleMatchType controls how a rule condition is compared with the query
+type RuleMatchType string
+
+const (
+	RuleMatchExact    RuleMatchType = "exact"    // The query has exactly the condition's words
+	RuleMatchContains RuleMatchType = "contains" // The query contains the condition's words in sequence
+)
+
+// RuleCondition decides which queries a rule applies to.
+// An empty Query matches every query.
+type RuleCondition struct {
+	Query string        `json:"query"`
+	Match RuleMatchType `json:"match,omitempty"` // Defaults to exact
+}
+
+// RuleFilter is a filter condition that an add_filter action adds to the query.
+// An empty Operator is picked from the type of the document field, as in query filters.
+type RuleFilter struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator,omitempty"`
+	Value    interface{} `json:"value"`
+}
+
+// RuleAction is a change applied to a query matching the rule or to its results
+type RuleAction struct {
+	Type        RuleActionType  `json:"type"`
+	DocumentIDs []string        `json:"document_ids,omitempty"` // Documents changed by pin, hide and boost actions
+	Position    int             `json:"position,omitempty"`     // 1-based position of the first pinned document (pin only, defaults to 1)
+	Multiplier  float64         `json:"multiplier,omitempty"`   // Multiplies the scores of the documents when set (boost only)
+	Addend      float64         `json:"addend,omitempty"`       // Added to the scores of the documents, after the multiplier (boost only)
+	Query       string          `json:"query,omitempty"`        // Query the matching query is rewritten with (rewrite_query only)
+	Mode        RuleRewriteMode `json:"mode,omitempty"`         // How the query is rewritten (rewrite_query only, defaults to replace)
+	Filter      *RuleFilter     `json:"filter,omitempty"`       // Condition added to the query's filters (add_filter only)
+}
+
+// Rule is a merchandising rule attached to an index.
+// A rule with a validity window only applies to searches within it.
+type Rule struct {
+	ID          string        `json:"id"`
+	IndexName   string        `json:"index_name"`
+	Description string        `json:"description,omitempty"`
+	Condition   RuleCondition `json:"condition"`
+	Actions     []RuleAction  `json:"actions"`
+	ValidFrom   *time.Time    `json:"valid_from,omitempty"`  // Applies from this time on; nil applies as soon as it is stored
+	ValidUntil  *time.Time    `json:"valid_until,omitempty"` // Stops applying at this time; nil never expires
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// RuleList is a page of the rules of an index. Page, PageSize and Pages are only set when the
+// listing was paginated.
+type RuleList struct {
+	Rules    []Rule `json:"rules"`
+	Total    int    `json:"total"`
+	Page     int    `json:"page,omitempty"`
+	PageSize int    `json:"page_size,omitempty"`
+	Pages    int    `json:"pages,omitempty"`
+}
+
+// RuleImport is the outcome of a bulk rule import.
+type RuleImport struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"` // Rules missing from a replacing import
+	Total   int `json:"total"`   // Rules of the index after the import
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// SafeModeState describes where the monitoring of a settings update stands
+type SafeModeState string
+
+const (
+	SafeModeMonitoring SafeModeState = "monitoring" // Searches are being watched
+	SafeModePassed     SafeModeState = "passed"     // The window ended without a spike
+	SafeModeReverted   SafeModeState = "reverted"   // A spike restored the previous settings
+	SafeModeSuperseded SafeModeState = "superseded" // Another settings update started before the window ended
+)
+
+// SafeModeStats describes the monitoring of an index's latest settings update under safe mode
+type SafeModeStats struct {
+	State          SafeModeState `json:"state"`
+	StartedAt      time.Time     `json:"started_at"`              // When the update completed
+	WindowMs       int64         `json:"window_ms"`               // How long searches are watched
+	Searches       int64         `json:"searches"`                // Searches seen since the update
+	Errors         int64         `json:"errors"`                  // Searches that failed
+	ZeroResults    int64         `json:"zero_results"`            // Searches that returned no results
+	ErrorRate      float64       `json:"error_rate"`              // Errors over searches
+	ZeroResultRate float64       `json:"zero_result_rate"`        // Zero results over searches
+	EndedAt        *time.Time    `json:"ended_at,omitempty"`      // When monitoring ended
+	Reason         string        `json:"reason,omitempty"`        // Why the settings were reverted
+	RevertJobID    string        `json:"revert_job_id,omitempty"` // Job restoring the previous settings
+}
+
+// SafeModeAlert is posted to the safe mode webhook when a settings update is reverted
+type SafeModeAlert struct {
+	Event          string    `json:"event"` // Always "settings_reverted"
+	IndexName      string    `json:"index_name"`
+	Reason         string    `json:"reason"`
+	Searches       int64     `json:"searches"`
+	ErrorRate      float64   `json:"error_rate"`
+	ZeroResultRate float64   `json:"zero_result_rate"`
+	RevertJobID    string    `json:"revert_job_id,omitempty"`
+	RevertedAt     time.Time `json:"reverted_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// SearchExportFormat is the file format of a search export
+type SearchExportFormat string
+
+const (
+	SearchExportCSV    SearchExportFormat = "csv"    // One row per hit, one column per exported field
+	SearchExportNDJSON SearchExportFormat = "ndjson" // One JSON document per line
+)
+
+// SearchExport describes the file written by a search export job
+type SearchExport struct {
+	JobID     string             `json:"job_id"`
+	IndexName string             `json:"index_name"`
+	Format    SearchExportFormat `json:"format"`
+	Fields    []string           `json:"fields,omitempty"` // Exported document fields; all fields when empty
+	Hits      int                `json:"hits"`             // Hits written, once the job has completed
+	Bytes     int64              `json:"bytes"`            // Size of the file, once the job has completed
+	CreatedAt time.Time          `json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// SegmentStorageStats describes the on-disk segments holding an index's posting lists
+type SegmentStorageStats struct {
+	Segments      int   `json:"segments"`       // Segment files in use
+	SegmentBytes  int64 `json:"segment_bytes"`  // Total size of the segment files
+	BufferedTerms int   `json:"buffered_terms"` // Terms changed in memory since the index was last persisted
+	Merges        int64 `json:"merges"`         // Background merges completed since the index was loaded
+	Merging       bool  `json:"merging"`        // Whether a merge is running
+}
@@ -0,0 +1,10 @@
+package model
+
+// IndexSeq holds the sequence numbers of an index. Every document mutation is assigned the next
+// sequence number of its index, so a client holding the sequence number of a write knows the
+// write is visible once SearchableSeq reaches it.
+type IndexSeq struct {
+	IndexName     string `json:"index_name"`
+	Seq           uint64 `json:"seq"`            // Sequence number of the latest document mutation
+	SearchableSeq uint64 `json:"searchable_seq"` // Latest mutation searches see; behind Seq until a read replica refreshes
+}
@@ -0,0 +1,19 @@
+package model
+
+// SettingChange is a setting that differs between two indexes. Keys are the JSON keys of the
+// settings; nested settings use dotted keys such as "query_sanitizer.max_query_length".
+type SettingChange struct {
+	Key  string      `json:"key"`
+	From interface{} `json:"from"` // Value in the first index, null when not set
+	To   interface{} `json:"to"`   // Value in the other index, null when not set
+}
+
+// SettingsDiff compares the settings of two indexes. Index names are not compared.
+type SettingsDiff struct {
+	IndexName  string          `json:"index_name"`
+	OtherIndex string          `json:"other_index"`
+	Identical  bool            `json:"identical"`
+	Added      []SettingChange `json:"added"`   // Set only in the other index
+	Removed    []SettingChange `json:"removed"` // Set only in the first index
+	Changed    []SettingChange `json:"changed"` // Set in both indexes, to different values
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+// ShadowConfig configures shadow mode for an index: a sample of its live searches is also run
+// against a candidate index and only the metrics of those runs are kept.
+type ShadowConfig struct {
+	CandidateIndex   string  `json:"candidate_index"`
+	SamplePercentage float64 `json:"sample_percentage"` // Percentage of live searches mirrored to the candidate (0-100]
+}
+
+// ShadowSideStats aggregates the searches run against one side of a shadow comparison
+type ShadowSideStats struct {
+	AvgLatencyMs      float64 `json:"avg_latency_ms"`
+	AvgHits           float64 `json:"avg_hits"`
+	ZeroResultQueries int64   `json:"zero_result_queries"`
+}
+
+// ShadowStats compares the live index with its shadow candidate over the sampled searches
+type ShadowStats struct {
+	IndexName              string          `json:"index_name"`
+	CandidateIndex         string          `json:"candidate_index"`
+	SamplePercentage       float64         `json:"sample_percentage"`
+	StartedAt              time.Time       `json:"started_at"`
+	SampledQueries         int64           `json:"sampled_queries"`           // Searches successfully run against both indexes
+	FailedQueries          int64           `json:"failed_queries"`            // Searches that failed on the candidate
+	DroppedQueries         int64           `json:"dropped_queries"`           // Sampled searches skipped because too many shadow searches were running
+	HitCountChangedQueries int64           `json:"hit_count_changed_queries"` // Sampled searches whose total hit count differs between the indexes
+	Primary                ShadowSideStats `json:"primary"`
+	Candidate              ShadowSideStats `json:"candidate"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// SnapshotInfo describes an index snapshot archive
+type SnapshotInfo struct {
+	IndexName     string    `json:"index_name"`     // Index the snapshot was taken of, or restored into
+	SourceIndex   string    `json:"source_index"`   // Index the snapshot was taken of
+	FormatVersion int       `json:"format_version"` // Version of the archive format
+	CreatedAt     time.Time `json:"created_at"`     // When the snapshot was taken
+	DocumentCount int       `json:"document_count"`
+	TermCount     int       `json:"term_count"`
+	RuleCount     int       `json:"rule_count"`
+}
@@ -0,0 +1,23 @@
+package model
+
+// SpellcheckRequest is a query string to check against an index's vocabulary without searching
+type SpellcheckRequest struct {
+	Query   string       `json:"query"`
+	Filters []RuleFilter `json:"-"` // Only correct to words of documents matching all of them, e.g. those of an API key
+}
+
+// SpellcheckCorrection is the suggested replacement for a query token missing from the index
+type SpellcheckCorrection struct {
+	Token      string  `json:"token"`
+	Suggestion string  `json:"suggestion"`
+	Distance   int     `json:"distance"`   // Edit distance between the token and the suggestion
+	Frequency  int     `json:"frequency"`  // Documents containing the suggestion as a whole word
+	Confidence float64 `json:"confidence"` // Share of the suggestion among all candidate corrections, between 0 and 1
+}
+
+// SpellcheckResult lists the corrections suggested for a query
+type SpellcheckResult struct {
+	Query          string                 `json:"query"`
+	CorrectedQuery string                 `json:"corrected_query,omitempty"` // Analyzed query with corrections applied, when any
+	Corrections    []SpellcheckCorrection `json:"corrections"`
+}
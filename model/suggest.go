@@ -0,0 +1,22 @@
+package model
+
+// SuggestRequest is a typed prefix to complete for typeahead, without searching
+type SuggestRequest struct {
+	Query   string       `json:"q" form:"q"`         // Text typed so far; empty returns the most frequent suggestions
+	Limit   int          `json:"limit" form:"limit"` // Suggestions returned; defaults to 10, at most 100
+	Filters []RuleFilter `json:"-" form:"-"`         // Only suggest from documents matching all of them, e.g. those of an API key
+}
+
+// Suggestion is a completion and the number of documents containing it
+type Suggestion struct {
+	Text      string `json:"text"`
+	Documents int    `json:"documents"`
+}
+
+// SuggestResult lists the completions of a prefix, most frequent first
+type SuggestResult struct {
+	Query       string       `json:"q"`
+	Field       string       `json:"field,omitempty"` // Field whose values are suggested; indexed terms are suggested when empty
+	Suggestions []Suggestion `json:"suggestions"`
+	HasMore     bool         `json:"has_more"` // Whether more suggestions complete the prefix than were returned
+}
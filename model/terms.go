@@ -0,0 +1,23 @@
+package model
+
+// TermsRequest selects indexed terms by prefix, e.g. to explore an index's vocabulary or suggest
+// completions
+type TermsRequest struct {
+	Prefix          string `json:"prefix" form:"prefix"`                     // Terms starting with this prefix; empty lists all terms
+	Field           string `json:"field" form:"field"`                       // Only count documents containing the term in this searchable field
+	Limit           int    `json:"limit" form:"limit"`                       // Terms returned; defaults to 20, at most 1000
+	IncludePrefixes bool   `json:"include_prefixes" form:"include_prefixes"` // Also list and count the prefix n-grams indexed for prefix search
+}
+
+// TermCount is an indexed term and the number of documents containing it
+type TermCount struct {
+	Term      string `json:"term"`
+	Documents int    `json:"documents"`
+}
+
+// TermsResult lists the indexed terms starting with a prefix, in ascending order
+type TermsResult struct {
+	Prefix  string      `json:"prefix"`
+	Terms   []TermCount `json:"terms"`
+	HasMore bool        `json:"has_more"` // Whether more terms start with the prefix than were returned
+}
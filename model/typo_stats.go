@@ -0,0 +1,19 @@
+package model
+
+// TypoDistanceStats measures typo expansion at one edit distance
+type TypoDistanceStats struct {
+	ExpandedTokens      int64   `json:"expanded_tokens"`      // Query tokens for which typo candidates were looked up
+	CandidatesGenerated int64   `json:"candidates_generated"` // Indexed terms found within the edit distance
+	CandidatesMatched   int64   `json:"candidates_matched"`   // Candidates that contributed postings to a search
+	CandidateMatchRate  float64 `json:"candidate_match_rate"` // Share of generated candidates that matched, between 0 and 1
+}
+
+// TypoStats measures how much typo expansion contributes to an index's searches, to guide the
+// tuning of min_word_size_for_1_typo and min_word_size_for_2_typos
+type TypoStats struct {
+	Searches     int64             `json:"searches"`       // Searches run, including zero-result fallback retries
+	TotalHits    int64             `json:"total_hits"`     // Hits returned by those searches, across all pages
+	HitsViaTypos int64             `json:"hits_via_typos"` // Hits that matched at least one query token only through a typo
+	OneTypo      TypoDistanceStats `json:"one_typo"`
+	TwoTypos     TypoDistanceStats `json:"two_typos"`
+}
@@ -0,0 +1,213 @@
+// Package querybuilder builds search queries and filter expressions for programs embedding the
+// engine directly. Filter operators, AND/OR groups and query options are methods instead of
+// strings, so typos in them are compile errors:
+//
+//	query := querybuilder.Search("matrix").
+//		Where(querybuilder.Filter("genre").Eq("Action").And(querybuilder.Filter("year").Gte(1999))).
+//		PageSize(20).
+//		Build()
+package querybuilder
+
+import "github.com/gcbaptista/go-search-engine/services"
+
+// Expression is a filter expression: a single condition or an AND/OR group of expressions.
+type Expression interface {
+	// Filters returns the expression as the filters of a search query.
+	Filters() services.Filters
+}
+
+// Field starts a filter condition on a document field.
+type Field struct {
+	name string
+}
+
+// Filter starts a filter condition on the named field. The field should be one of the index's
+// filterable fields.
+func Filter(name string) Field {
+	return Field{name: name}
+}
+
+// Eq matches documents whose field equals the value, or contains it for array fields.
+func (f Field) Eq(value interface{}) Condition {
+	return f.condition("_exact", value)
+}
+
+// Ne matches documents whose field differs from the value.
+func (f Field) Ne(value interface{}) Condition {
+	return f.condition("_ne", value)
+}
+
+// Gt matches documents whose field is greater than the value. Numbers and dates are compared.
+func (f Field) Gt(value interface{}) Condition {
+	return f.condition("_gt", value)
+}
+
+// Gte matches documents whose field is greater than or equal to the value.
+func (f Field) Gte(value interface{}) Condition {
+	return f.condition("_gte", value)
+}
+
+// Lt matches documents whose field is less than the value.
+func (f Field) Lt(value interface{}) Condition {
+	return f.condition("_lt", value)
+}
+
+// Lte matches documents whose field is less than or equal to the value.
+func (f Field) Lte(value interface{}) Condition {
+	return f.condition("_lte", value)
+}
+
+// Between matches documents whose field is within the inclusive range.
+func (f Field) Between(low, high interface{}) Group {
+	return And(f.Gte(low), f.Lte(high))
+}
+
+// Contains matches documents whose text field contains the value, or whose array field has it.
+func (f Field) Contains(value interface{}) Condition {
+	return f.condition("_contains", value)
+}
+
+// NotContains matches documents whose field does not contain the value.
+func (f Field) NotContains(value interface{}) Condition {
+	return f.condition("_ncontains", value)
+}
+
+// ContainsAnyOf matches documents whose array field has at least one of the values.
+func (f Field) ContainsAnyOf(values ...interface{}) Condition {
+	return f.condition("_contains_any_of", values)
+}
+
+func (f Field) condition(operator string, value interface{}) Condition {
+	return Condition{condition: services.FilterCondition{Field: f.name, Operator: operator, Value: value}}
+}
+
+// Condition is a filter condition on a single field.
+type Condition struct {
+	condition services.FilterCondition
+}
+
+// Score sets the score added to documents matching the condition, used by the filter_score
+// ranking criterion.
+func (c Condition) Score(score float64) Condition {
+	c.condition.Score = score
+	return c
+}
+
+// Optional makes the condition never exclude documents: it only adds its score to those matching it.
+func (c Condition) Optional() Condition {
+	c.condition.Optional = true
+	return c
+}
+
+// ID names the condition in the matched filters reported with ExplainFilters.
+func (c Condition) ID(id string) Condition {
+	c.condition.ID = id
+	return c
+}
+
+// And matches documents matching the condition and all the other expressions.
+func (c Condition) And(others ...Expression) Group {
+	return And(append([]Expression{c}, others...)...)
+}
+
+// Or matches documents matching the condition or any of the other expressions.
+func (c Condition) Or(others ...Expression) Group {
+	return Or(append([]Expression{c}, others...)...)
+}
+
+// FilterCondition returns the condition as used in filter expressions.
+func (c Condition) FilterCondition() services.FilterCondition {
+	return c.condition
+}
+
+// Filters returns a group holding only the condition.
+func (c Condition) Filters() services.Filters {
+	return services.Filters{Operator: "AND", Filters: []services.FilterCondition{c.condition}}
+}
+
+// Group combines expressions with AND or OR logic.
+type Group struct {
+	id          string
+	operator    string
+	expressions []Expression
+	aggregation services.FilterAggregation
+	weight      float64
+	optional    bool
+}
+
+// And matches documents matching all the expressions.
+func And(expressions ...Expression) Group {
+	return Group{operator: "AND", expressions: expressions}
+}
+
+// Or matches documents matching at least one of the expressions.
+func Or(expressions ...Expression) Group {
+	return Group{operator: "OR", expressions: expressions}
+}
+
+// And matches documents matching the group and all the other expressions. Expressions are added
+// to an AND group rather than nested in a new one, unless the group is optional, named or scores differently.
+func (g Group) And(others ...Expression) Group {
+	if g.operator == "AND" && g.plain() {
+		return Group{operator: "AND", expressions: append(append([]Expression(nil), g.expressions...), others...)}
+	}
+	return And(append([]Expression{g}, others...)...)
+}
+
+// Or matches documents matching the group or any of the other expressions. Expressions are added
+// to an OR group rather than nested in a new one, unless the group is optional, named or scores differently.
+func (g Group) Or(others ...Expression) Group {
+	if g.operator == "OR" && g.plain() {
+		return Group{operator: "OR", expressions: append(append([]Expression(nil), g.expressions...), others...)}
+	}
+	return Or(append([]Expression{g}, others...)...)
+}
+
+// Max scores the group with the highest score of its matched expressions rather than their sum.
+func (g Group) Max() Group {
+	g.aggregation = services.FilterAggregationMax
+	return g
+}
+
+// Avg scores the group with the mean score of its matched expressions rather than their sum.
+func (g Group) Avg() Group {
+	g.aggregation = services.FilterAggregationAvg
+	return g
+}
+
+// Weight multiplies the score of the group.
+func (g Group) Weight(weight float64) Group {
+	g.weight = weight
+	return g
+}
+
+// Optional makes the group never exclude documents: it only adds its score to those matching it.
+func (g Group) Optional() Group {
+	g.optional = true
+	return g
+}
+
+// ID names the group in the matched filters reported with ExplainFilters.
+func (g Group) ID(id string) Group {
+	g.id = id
+	return g
+}
+
+// plain reports whether the group is required, unnamed and sums the scores of its expressions
+// unweighted, so more expressions can be added to it without changing its meaning.
+func (g Group) plain() bool {
+	return (g.aggregation == "" || g.aggregation == services.FilterAggregationSum) && g.weight == 0 && !g.optional && g.id == ""
+}
+
+// Filters returns the group as filters: its conditions are listed directly and its groups nested.
+func (g Group) Filters() services.Filters {
+	filters := services.Filters{ID: g.id, Operator: g.operator, Aggregation: g.aggregation, Weight: g.weight, Optional: g.optional}
+	for _, expression := range g.expressions {
+		if condition, ok := expression.(Condition); ok {
+			filters.Filters = append(filters.Filters, condition.condition)
+		} else {
+			filters.Groups = append(filters.Groups, expression.Filters())
+		}
+	}
+	return filters
+}
@@ -0,0 +1,254 @@
+package querybuilder
+
+import (
+	"maps"
+
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+// QueryBuilder builds a search query step by step. Its methods return the builder so calls can be
+// chained; Build returns the query.
+type QueryBuilder struct {
+	query services.SearchQuery
+}
+
+// Search starts a query for the query string, which may use quoted phrases and hyphen-prefixed
+// excluded words.
+func Search(queryString string) *QueryBuilder {
+	return &QueryBuilder{query: services.SearchQuery{QueryString: queryString}}
+}
+
+// SearchTokens starts a query of tokens with explicit match modes, searched instead of a query
+// string.
+func SearchTokens(tokens ...services.QueryToken) *QueryBuilder {
+	return &QueryBuilder{query: services.SearchQuery{Tokens: append([]services.QueryToken(nil), tokens...)}}
+}
+
+// Exact is a token matching whole words equal to it, without typos.
+func Exact(token string) services.QueryToken {
+	return services.QueryToken{Token: token, Mode: services.TokenMatchExact}
+}
+
+// Prefix is a token matching words starting with it, without typos.
+func Prefix(token string) services.QueryToken {
+	return services.QueryToken{Token: token, Mode: services.TokenMatchPrefix}
+}
+
+// Fuzzy is a token matching words starting with it or within maxTypos edits of it.
+func Fuzzy(token string, maxTypos int) services.QueryToken {
+	return services.QueryToken{Token: token, Mode: services.TokenMatchFuzzy, MaxTypos: maxTypos}
+}
+
+// Where filters the results with the expression.
+func (b *QueryBuilder) Where(expression Expression) *QueryBuilder {
+	filters := expression.Filters()
+	b.query.Filters = &filters
+	return b
+}
+
+// Page sets the page of results returned, starting at 1.
+func (b *QueryBuilder) Page(page int) *QueryBuilder {
+	b.query.Page = page
+	return b
+}
+
+// SearchAfter returns the hits ranked after a cursor, the NextCursor of the previous page, instead
+// of a numbered page.
+func (b *QueryBuilder) SearchAfter(cursor string) *QueryBuilder {
+	b.query.SearchAfter = cursor
+	return b
+}
+
+// PageSize sets the number of results per page.
+func (b *QueryBuilder) PageSize(pageSize int) *QueryBuilder {
+	b.query.PageSize = pageSize
+	return b
+}
+
+// RestrictFields searches only the given searchable fields.
+func (b *QueryBuilder) RestrictFields(fields ...string) *QueryBuilder {
+	b.query.RestrictSearchableFields = append(b.query.RestrictSearchableFields, fields...)
+	return b
+}
+
+// ExcludeFields leaves the given searchable fields out of the search.
+func (b *QueryBuilder) ExcludeFields(fields ...string) *QueryBuilder {
+	b.query.ExcludeSearchableFields = append(b.query.ExcludeSearchableFields, fields...)
+	return b
+}
+
+// ExcludeTerms leaves the documents containing any of the terms out of the results.
+func (b *QueryBuilder) ExcludeTerms(terms ...string) *QueryBuilder {
+	b.query.ExcludeTerms = append(b.query.ExcludeTerms, terms...)
+	return b
+}
+
+// MatchingStrategy sets how many query tokens documents must match.
+func (b *QueryBuilder) MatchingStrategy(strategy services.MatchingStrategy) *QueryBuilder {
+	b.query.MatchingStrategy = strategy
+	return b
+}
+
+// PrefixLast matches the last query token as a prefix in every searchable field, for
+// search-as-you-type.
+func (b *QueryBuilder) PrefixLast() *QueryBuilder {
+	b.query.PrefixLast = true
+	return b
+}
+
+// RequireSameField only matches documents with all query tokens in a single field, such as a
+// title, rather than spread over several fields.
+func (b *QueryBuilder) RequireSameField() *QueryBuilder {
+	b.query.RequireSameField = true
+	return b
+}
+
+// Retrieve returns only the given document fields in the results.
+func (b *QueryBuilder) Retrieve(fields ...string) *QueryBuilder {
+	b.query.RetrievableFields = append(b.query.RetrievableFields, fields...)
+	return b
+}
+
+// TypoTolerance overrides the index's minimum word sizes for 1 and 2 typos.
+func (b *QueryBuilder) TypoTolerance(minWordSizeFor1Typo, minWordSizeFor2Typos int) *QueryBuilder {
+	b.query.MinWordSizeFor1Typo = &minWordSizeFor1Typo
+	b.query.MinWordSizeFor2Typos = &minWordSizeFor2Typos
+	return b
+}
+
+// Facets returns the value counts of the given filterable fields.
+func (b *QueryBuilder) Facets(fields ...string) *QueryBuilder {
+	b.query.Facets = append(b.query.Facets, fields...)
+	return b
+}
+
+// NormalizedPreview returns the normalized text used for matching with each hit.
+func (b *QueryBuilder) NormalizedPreview() *QueryBuilder {
+	b.query.NormalizedPreview = true
+	return b
+}
+
+// ReportMatches limits the field matches reported with each hit to the given fields, and to
+// maxMatchesPerField terms per field. A maxMatchesPerField of 0 reports all terms.
+func (b *QueryBuilder) ReportMatches(maxMatchesPerField int, fields ...string) *QueryBuilder {
+	b.query.MaxMatchesPerField = maxMatchesPerField
+	b.query.FieldsToReport = append(b.query.FieldsToReport, fields...)
+	return b
+}
+
+// FieldWeight multiplies the scores of matches in a searchable field by weight, overriding the
+// index's weight for the field.
+func (b *QueryBuilder) FieldWeight(field string, weight float64) *QueryBuilder {
+	if b.query.FieldWeights == nil {
+		b.query.FieldWeights = make(map[string]float64)
+	}
+	b.query.FieldWeights[field] = weight
+	return b
+}
+
+// QueryTokenDecay weights the score of each query token decay times the one before it, overriding
+// the index's query_token_decay: below 1 the first tokens typed weigh most, above 1 the last ones.
+func (b *QueryBuilder) QueryTokenDecay(decay float64) *QueryBuilder {
+	b.query.QueryTokenDecay = &decay
+	return b
+}
+
+// Boost changes the scores of the hits matching the expression before they are ranked: they are
+// multiplied by multiplier, unless it is 0, and then addend is added to them.
+func (b *QueryBuilder) Boost(expression Expression, multiplier, addend float64) *QueryBuilder {
+	b.query.Boosts = append(b.query.Boosts, services.BoostRule{Filter: expression.Filters(), Multiplier: multiplier, Addend: addend})
+	return b
+}
+
+// FilterLocale reads numbers and dates written as strings in filter values with the conventions of
+// locale, e.g. "1.234,56" and "31.12.2024" for "de".
+func (b *QueryBuilder) FilterLocale(locale string) *QueryBuilder {
+	b.query.FilterLocale = locale
+	return b
+}
+
+// Sample evaluates only the given share of the candidates, between 0 and 1, and extrapolates the
+// total and facet counts from it, for fast approximate numbers on large indexes.
+func (b *QueryBuilder) Sample(rate float64) *QueryBuilder {
+	b.query.Sample = rate
+	return b
+}
+
+// Suggest returns corrected or relaxed queries that find hits when the query finds nothing.
+func (b *QueryBuilder) Suggest() *QueryBuilder {
+	b.query.Suggest = true
+	return b
+}
+
+// ExplainFilters reports with each hit the filter conditions and groups it matched.
+func (b *QueryBuilder) ExplainFilters() *QueryBuilder {
+	b.query.ExplainFilters = true
+	return b
+}
+
+// IDsOnly returns the ID, score and sort keys of each hit instead of its document, for clients
+// that fetch the documents separately.
+func (b *QueryBuilder) IDsOnly() *QueryBuilder {
+	b.query.IDsOnly = true
+	return b
+}
+
+// Build returns the query. The builder can keep being used; later changes do not affect
+// queries already built.
+func (b *QueryBuilder) Build() services.SearchQuery {
+	query := b.query
+	query.RestrictSearchableFields = cloneStrings(query.RestrictSearchableFields)
+	query.ExcludeSearchableFields = cloneStrings(query.ExcludeSearchableFields)
+	query.ExcludeTerms = cloneStrings(query.ExcludeTerms)
+	query.RetrievableFields = cloneStrings(query.RetrievableFields)
+	query.Facets = cloneStrings(query.Facets)
+	query.FieldsToReport = cloneStrings(query.FieldsToReport)
+	if query.Tokens != nil {
+		query.Tokens = append([]services.QueryToken(nil), query.Tokens...)
+	}
+	query.FieldWeights = maps.Clone(query.FieldWeights)
+	if query.Boosts != nil {
+		query.Boosts = append([]services.BoostRule(nil), query.Boosts...)
+	}
+	return query
+}
+
+// Named returns the query as a named query of a multi-search. Page and page size are set for the
+// whole multi-search instead.
+func (b *QueryBuilder) Named(name string) services.NamedSearchQuery {
+	query := b.Build()
+	return services.NamedSearchQuery{
+		Name:                     name,
+		Query:                    query.QueryString,
+		RestrictSearchableFields: query.RestrictSearchableFields,
+		ExcludeSearchableFields:  query.ExcludeSearchableFields,
+		ExcludeTerms:             query.ExcludeTerms,
+		MatchingStrategy:         query.MatchingStrategy,
+		PrefixLast:               query.PrefixLast,
+		RequireSameField:         query.RequireSameField,
+		RetrievableFields:        query.RetrievableFields,
+		Filters:                  query.Filters,
+		MinWordSizeFor1Typo:      query.MinWordSizeFor1Typo,
+		MinWordSizeFor2Typos:     query.MinWordSizeFor2Typos,
+		Tokens:                   query.Tokens,
+		NormalizedPreview:        query.NormalizedPreview,
+		MaxMatchesPerField:       query.MaxMatchesPerField,
+		FieldsToReport:           query.FieldsToReport,
+		Facets:                   query.Facets,
+		FieldWeights:             query.FieldWeights,
+		QueryTokenDecay:          query.QueryTokenDecay,
+		Boosts:                   query.Boosts,
+		FilterLocale:             query.FilterLocale,
+		Sample:                   query.Sample,
+		Suggest:                  query.Suggest,
+		ExplainFilters:           query.ExplainFilters,
+		IDsOnly:                  query.IDsOnly,
+	}
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string(nil), values...)
+}
@@ -0,0 +1,162 @@
+package querybuilder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/services"
+)
+
+func TestFilters(t *testing.T) {
+	expression := Filter("genre").Eq("Action").Score(2).
+		And(Filter("year").Between(1990, 2000), Filter("tags").ContainsAnyOf("cult", "classic")).
+		Or(Filter("rating").Gt(9))
+
+	want := services.Filters{
+		Operator: "OR",
+		Filters: []services.FilterCondition{
+			{Field: "rating", Operator: "_gt", Value: 9},
+		},
+		Groups: []services.Filters{{
+			Operator: "AND",
+			Filters: []services.FilterCondition{
+				{Field: "genre", Operator: "_exact", Value: "Action", Score: 2},
+				{Field: "tags", Operator: "_contains_any_of", Value: []interface{}{"cult", "classic"}},
+			},
+			Groups: []services.Filters{{
+				Operator: "AND",
+				Filters: []services.FilterCondition{
+					{Field: "year", Operator: "_gte", Value: 1990},
+					{Field: "year", Operator: "_lte", Value: 2000},
+				},
+			}},
+		}},
+	}
+	if got := expression.Filters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Filters() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGroupsAreFlattened(t *testing.T) {
+	a, b, c := Filter("a").Eq(1), Filter("b").Ne(2), Filter("c").Lt(3)
+
+	if got := And(a, b).And(c).Filters(); len(got.Filters) != 3 || len(got.Groups) != 0 {
+		t.Errorf("Expected AND of an AND group to add its conditions, got %+v", got)
+	}
+	if got := Or(a, b).And(c).Filters(); got.Operator != "AND" || len(got.Filters) != 1 || len(got.Groups) != 1 {
+		t.Errorf("Expected AND of an OR group to nest it, got %+v", got)
+	}
+	if got := a.Filters(); got.Operator != "AND" || !reflect.DeepEqual(got.Filters, []services.FilterCondition{a.FilterCondition()}) {
+		t.Errorf("Expected a single condition to be a group of its own, got %+v", got)
+	}
+}
+
+func TestGroupScoring(t *testing.T) {
+	a, b, c := Filter("a").Eq(1).Score(1), Filter("b").Eq(2).Score(3), Filter("c").Eq(3).Score(5)
+
+	got := Or(a, b).Max().Weight(2).Or(c).Filters()
+	want := services.Filters{
+		Operator: "OR",
+		Filters:  []services.FilterCondition{c.FilterCondition()},
+		Groups: []services.Filters{{
+			Operator:    "OR",
+			Filters:     []services.FilterCondition{a.FilterCondition(), b.FilterCondition()},
+			Aggregation: services.FilterAggregationMax,
+			Weight:      2,
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected a weighted max group to be nested rather than flattened, got %+v", got)
+	}
+	if got := And(a, b).Avg().Filters(); got.Aggregation != services.FilterAggregationAvg || got.Weight != 0 {
+		t.Errorf("Expected an unweighted avg group, got %+v", got)
+	}
+}
+
+func TestOptionalFilters(t *testing.T) {
+	premium := Filter("is_premium").Eq(true).Score(2).Optional()
+	if !premium.FilterCondition().Optional {
+		t.Errorf("Expected an optional condition, got %+v", premium.FilterCondition())
+	}
+
+	got := Or(Filter("genre").Eq("Action").Score(1), Filter("genre").Eq("Drama").Score(1)).Optional().And(premium).Filters()
+	if got.Operator != "AND" || got.Optional || len(got.Groups) != 1 || !got.Groups[0].Optional || len(got.Filters) != 1 || !got.Filters[0].Optional {
+		t.Errorf("Expected a required AND of an optional group and an optional condition, got %+v", got)
+	}
+	if got := And(premium).Optional().And(Filter("year").Gte(2000)).Filters(); len(got.Groups) != 1 {
+		t.Errorf("Expected expressions not to be added to an optional group, got %+v", got)
+	}
+}
+
+func TestFilterIDs(t *testing.T) {
+	platform := Filter("platform").Eq("ios").Score(1).ID("platform")
+	got := Or(platform, Filter("plan").Eq("pro").Score(1)).ID("preferences").Or(Filter("free").Eq(true)).Filters()
+	if len(got.Groups) != 1 || got.Groups[0].ID != "preferences" || got.Groups[0].Filters[0].ID != "platform" {
+		t.Errorf("Expected the named group to be nested with its named condition, got %+v", got)
+	}
+}
+
+func TestQueryBuilder(t *testing.T) {
+	builder := Search("matrix").
+		Where(Filter("genre").Contains("sci-fi")).
+		Page(2).
+		PageSize(20).
+		RestrictFields("title", "cast").
+		ExcludeFields("cast").
+		ExcludeTerms("reloaded").
+		MatchingStrategy(services.MatchingStrategyMost).
+		PrefixLast().
+		Retrieve("title").
+		TypoTolerance(3, 6).
+		Facets("genre").
+		ReportMatches(2, "title")
+	query := builder.Build()
+
+	if query.QueryString != "matrix" || query.Page != 2 || query.PageSize != 20 || query.MatchingStrategy != services.MatchingStrategyMost || !query.PrefixLast {
+		t.Errorf("Unexpected query string or pagination: %+v", query)
+	}
+	if query.Filters == nil || query.Filters.Filters[0].Operator != "_contains" {
+		t.Errorf("Expected the filter expression to be set, got %+v", query.Filters)
+	}
+	if *query.MinWordSizeFor1Typo != 3 || *query.MinWordSizeFor2Typos != 6 {
+		t.Errorf("Expected typo tolerance overrides 3 and 6, got %d and %d", *query.MinWordSizeFor1Typo, *query.MinWordSizeFor2Typos)
+	}
+	if !reflect.DeepEqual(query.RestrictSearchableFields, []string{"title", "cast"}) || !reflect.DeepEqual(query.ExcludeTerms, []string{"reloaded"}) {
+		t.Errorf("Unexpected fields or excluded terms: %+v", query)
+	}
+
+	// Queries already built are not affected by later changes to the builder
+	builder.ExcludeTerms("revolutions").RestrictFields("genre")
+	if len(query.ExcludeTerms) != 1 || len(query.RestrictSearchableFields) != 2 {
+		t.Errorf("Expected the built query to be unchanged, got %+v", query)
+	}
+
+	named := Search("matrix").ExcludeTerms("reloaded").Boost(Filter("is_premium").Eq(true), 1.5, 0).FilterLocale("de").Sample(0.1).Suggest().ExplainFilters().IDsOnly().RequireSameField().Named("movies")
+	if named.Name != "movies" || named.Query != "matrix" || !reflect.DeepEqual(named.ExcludeTerms, []string{"reloaded"}) {
+		t.Errorf("Unexpected named query: %+v", named)
+	}
+	if len(named.Boosts) != 1 || named.Boosts[0].Multiplier != 1.5 || named.Boosts[0].Filter.Filters[0].Field != "is_premium" {
+		t.Errorf("Expected the boost rule to be set, got %+v", named.Boosts)
+	}
+	if named.FilterLocale != "de" {
+		t.Errorf("Expected the filter locale to be set, got %q", named.FilterLocale)
+	}
+	if named.Sample != 0.1 {
+		t.Errorf("Expected the sample rate to be set, got %v", named.Sample)
+	}
+	if !named.Suggest || !named.ExplainFilters || !named.IDsOnly || !named.RequireSameField {
+		t.Errorf("Expected suggestions, filter explanations, IDs-only hits and same-field matches to be requested")
+	}
+}
+
+func TestSearchTokens(t *testing.T) {
+	query := SearchTokens(Exact("iphone"), Prefix("pro"), Fuzzy("maxx", 2)).Build()
+	want := []services.QueryToken{
+		{Token: "iphone", Mode: services.TokenMatchExact},
+		{Token: "pro", Mode: services.TokenMatchPrefix},
+		{Token: "maxx", Mode: services.TokenMatchFuzzy, MaxTypos: 2},
+	}
+	if query.QueryString != "" || !reflect.DeepEqual(query.Tokens, want) {
+		t.Errorf("Tokens = %+v, want %+v", query.Tokens, want)
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/internal/errors"
+	"github.com/gcbaptista/go-search-engine/model"
+	"github.com/gcbaptista/go-search-engine/store"
+)
+
+// ParsedQuery is a search query together with its analyzed tokens, as handed to query rewriters.
+type ParsedQuery struct {
+	Query  SearchQuery // The query that will be executed
+	Tokens []string    // Analyzed tokens of Query.QueryString
+}
+
+// Vocabulary exposes read access to the terms indexed by a single index.
+type Vocabulary interface {
+	// HasTerm reports whether the term is present in the index.
+	HasTerm(term string) bool
+	// ClosestTerm returns the most frequent indexed term within maxDistance edits of term.
+	ClosestTerm(term string, maxDistance int) (string, bool)
+}
+
+// RewriteContext carries information about the index a query is executed against.
+type RewriteContext struct {
+	IndexName  string
+	Settings   config.IndexSettings
+	Vocabulary Vocabulary
+}
+
+// QueryRewriter transforms a query before it is executed.
+// Rewriters are registered on the engine and run in registration order; each one
+// receives the output of the previous one. Returning an error aborts the search.
+type QueryRewriter interface {
+	Name() string
+	Rewrite(ctx RewriteContext, query ParsedQuery) (ParsedQuery, error)
+}
+
+// ScoringContext carries information about the query being scored.
+type ScoringContext struct {
+	IndexName   string
+	QueryTokens []string // Tokens of the executed query, after rewriting
+}
+
+// ScoringCandidate describes a document that matched a query, as handed to a Scorer.
+type ScoringCandidate struct {
+	Document     model.Document      // The full stored document
+	MatchedTerms map[string][]string // Field name -> matched query terms (typo matches are suffixed with "(typo)")
+	BaseScore    float64             // Score computed by the default frequency-based scoring, including the weighted filter score
+	Info         HitInfo             // Typo counts, exact word counts and filter score
+}
+
+// Scorer computes the relevance score of a matched document.
+// It can replace the default score or augment it using BaseScore.
+// A scorer is selected per index through the index settings.
+type Scorer interface {
+	Name() string
+	Score(ctx ScoringContext, candidate ScoringCandidate) float64
+}
+
+// ErrRuleNotFound is the error, possibly wrapped, a RuleStore returns for a rule it does not have,
+// so the API reports the rule as not found.
+var ErrRuleNotFound = errors.ErrRuleNotFound
+
+// RuleStore keeps the merchandising rules of every index.
+type RuleStore interface {
+	// ListRules returns the rules of an index in creation order.
+	ListRules(indexName string) []model.Rule
+	// GetRule returns an error wrapping ErrRuleNotFound when the index has no rule with the ID.
+	GetRule(indexName, ruleID string) (model.Rule, error)
+	// SaveRule creates the rule or replaces the existing rule with the same index and ID.
+	SaveRule(rule model.Rule) error
+	DeleteRule(indexName, ruleID string) error
+	// DeleteIndexRules removes every rule of an index.
+	DeleteIndexRules(indexName string) error
+	// ReplaceIndexRules makes the given rules the only rules of an index, in a single change.
+	ReplaceIndexRules(indexName string, rules []model.Rule) error
+	// RenameIndexRules moves the rules of an index to its new name.
+	RenameIndexRules(oldName, newName string) error
+}
+
+// DocumentStore keeps the documents of every index where they are persisted. The engine holds the
+// documents of open indexes in a store.DocumentStore for searching; it reads them from the
+// DocumentStore when it loads an index and writes them to it whenever the index is persisted.
+// The GobEncode and GobDecode methods of store.DocumentStore turn its documents into a single blob
+// and back, keeping the internal IDs the inverted index refers to.
+type DocumentStore interface {
+	// LoadDocuments reads the documents of an index into docs, an empty store. It returns an error
+	// wrapping os.ErrNotExist when the index has no stored documents.
+	LoadDocuments(indexName string, docs *store.DocumentStore) error
+	// SaveDocuments replaces the stored documents of an index with those of docs.
+	SaveDocuments(indexName string, docs *store.DocumentStore) error
+	// DeleteDocuments removes the stored documents of an index, if any.
+	DeleteDocuments(indexName string) error
+}
+
+// Extensions are the parts of an engine that embedders can replace or add to without forking it.
+// Parts left zero get the built-in implementation.
+type Extensions struct {
+	// JobRunner runs the background jobs of writes, such as document additions and reindexing.
+	// The built-in runner is a worker pool of twice the CPUs, between 4 and 16 workers. A custom
+	// runner must be ready to execute jobs when the engine is created; the engine never starts or
+	// stops it, and only the built-in runner reports job metrics.
+	JobRunner JobRunner
+
+	// RuleStore holds the merchandising rules of every index. The built-in store keeps them in a
+	// JSON file of the data directory.
+	RuleStore RuleStore
+
+	// DocumentStore persists the documents of every index. The built-in store keeps them in a gob
+	// file of the index directory, next to its settings and inverted index.
+	DocumentStore DocumentStore
+
+	// QueryRewriters are applied to searches on every index, in order. More can be added later
+	// with RegisterQueryRewriter.
+	QueryRewriters []QueryRewriter
+
+	// Scorers are selected per index by IndexSettings.Scorer. More can be added later with
+	// RegisterScorer.
+	Scorers []Scorer
+
+	// PopularQuerySource provides the queries re-executed by cache warming. It can be replaced
+	// later with SetPopularQuerySource.
+	PopularQuerySource PopularQuerySource
+}
+
+// ExtensionRegistry defines adding query rewriters and scorers to an engine once it is created,
+// and listing the extensions it runs with
+type ExtensionRegistry interface {
+	RegisterQueryRewriter(rewriter QueryRewriter) error
+	RegisterScorer(scorer Scorer) error
+	Extensions() Extensions
+}
+
+// Rollbacker defines reversing the latest document upserts and deletes of an index, e.g. to undo
+// a bad import
+type Rollbacker interface {
+	RollbackAsync(indexName string, ops int) (string, error) // Returns job ID
+}
+
+// IdempotentRunner defines running a write at most once per idempotency key, so a retried request
+// returns the job of the first one instead of writing again
+type IdempotentRunner interface {
+	RunIdempotent(indexName, key, operation string, payload interface{}, start func() (string, error)) (jobID string, replayed bool, err error)
+}
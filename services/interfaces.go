@@ -1,6 +1,10 @@
 package services
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/model"
 )
@@ -11,6 +15,9 @@ type HitInfo struct {
 	NumTypos         int     `json:"num_typos"`          // Number of original query terms that matched via typo correction
 	NumberExactWords int     `json:"number_exact_words"` // Number of original query terms that matched exactly (not via typo)
 	FilterScore      float64 `json:"filter_score"`       // Score from filter expression matching
+	MatchedTokens    int     `json:"matched_tokens"`     // Number of query tokens the document matched, exactly or via typo
+	// Filter conditions and groups the document matched, by ID or path in the filter expression. Only set with ExplainFilters.
+	MatchedFilters []string `json:"matched_filters,omitempty"`
 }
 
 // HitResult represents a single document in the search results,
@@ -20,15 +27,106 @@ type HitResult struct {
 	FieldMatches map[string][]string `json:"field_matches"` // e.g., {"title": ["lord", "ring"], "tags": ["epic"]}
 	Score        float64             `json:"score"`         // The overall score for this hit
 	Info         HitInfo             `json:"hit_info"`      // Contains metadata like typo counts and exact matches
+	// Normalized text of the searchable fields, as matched against the query. Only set with NormalizedPreview.
+	Normalized map[string]string `json:"normalized,omitempty"`
+	// Number of matched terms left out of FieldMatches per field by MaxMatchesPerField
+	OmittedFieldMatches map[string]int `json:"omitted_field_matches,omitempty"`
+}
+
+// HitRef identifies a ranked hit without its document, for clients that fetch documents by ID
+type HitRef struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+	// Values of the index's ranking criteria for the hit, in order: the score for ~score, the filter
+	// score for ~filters, and the document's value, or null, for a field
+	SortKeys []interface{} `json:"sort_keys,omitempty"`
 }
 
 type SearchResult struct {
-	Hits     []HitResult `json:"hits"`
-	Total    int         `json:"total"`
-	Page     int         `json:"page"`
-	PageSize int         `json:"page_size"`
-	Took     int64       `json:"took"`     // milliseconds
-	QueryId  string      `json:"query_id"` // unique UUID for this search query
+	Hits []HitResult `json:"hits"`
+	// Ranked hits without their documents. Only set with IDsOnly, Hits being empty then.
+	HitRefs  []HitRef `json:"hit_refs,omitempty"`
+	Total    int      `json:"total"`
+	Page     int      `json:"page"`
+	PageSize int      `json:"page_size"`
+	Took     int64    `json:"took"`     // milliseconds
+	QueryId  string   `json:"query_id"` // unique UUID for this search query
+	// Rules that changed the hits of this search, in the order they were applied
+	AppliedRules []AppliedRule `json:"applied_rules,omitempty"`
+	// Zero-result fallback strategy that produced the hits, if the query itself found nothing
+	FallbackStrategy config.FallbackStrategy `json:"fallback_strategy,omitempty"`
+	// Query tokens as matched against the index. Only set with NormalizedPreview.
+	NormalizedQuery string `json:"normalized_query,omitempty"`
+	// Hits left out because an earlier query of a deduplicated multi-search matched them
+	DuplicatesRemoved int `json:"duplicates_removed,omitempty"`
+	// Number of matching hits per value of each requested facet field, over all pages
+	Facets map[string]map[string]int `json:"facets,omitempty"`
+	// Share of the candidates evaluated when the search was sampled. Total and Facets are then
+	// estimates, and Hits only come from the sample.
+	Sample float64 `json:"sample,omitempty"`
+	// Corrected or relaxed queries that find hits, for a "did you mean" prompt. Only set with
+	// Suggest when the search found nothing.
+	Suggestions []string `json:"suggestions,omitempty"`
+	// Cursor of the last hit, sent as SearchAfter to get the next page. Only set when more hits
+	// follow and the search was not sampled.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Limits that cut the work of this search short, so Hits and Total may be incomplete
+	Warnings []SearchWarning `json:"warnings,omitempty"`
+}
+
+// SearchWarningCode identifies the limit that truncated a search
+type SearchWarningCode string
+
+const (
+	// The search had more candidates than the index's search_limits.max_candidates
+	SearchWarningCandidatesTruncated SearchWarningCode = "candidates_truncated"
+	// The search ran out of the index's search_limits.time_limit_ms while evaluating candidates
+	SearchWarningTimeLimitExceeded SearchWarningCode = "time_limit_exceeded"
+	// A query token had more typo variants in the index than are expanded
+	SearchWarningTypoExpansionTruncated SearchWarningCode = "typo_expansion_truncated"
+)
+
+// SearchWarning describes a limit that truncated a search
+type SearchWarning struct {
+	Code       SearchWarningCode `json:"code"`
+	Message    string            `json:"message"`
+	Limit      int               `json:"limit"`                // Value of the limit: candidates, milliseconds or typo variants
+	Evaluated  int               `json:"evaluated,omitempty"`  // Candidates evaluated, of Candidates
+	Candidates int               `json:"candidates,omitempty"` // Candidates the search had before it was truncated
+	Token      string            `json:"token,omitempty"`      // Query token whose typo variants were truncated
+}
+
+// AppliedRule describes how a rule changed the results of a search
+type AppliedRule struct {
+	RuleID      string               `json:"rule_id"`
+	Action      model.RuleActionType `json:"action"`
+	DocumentIDs []string             `json:"document_ids,omitempty"` // Documents pinned, hidden or boosted by the action
+	Query       string               `json:"query,omitempty"`        // Query left by a rewrite_query action
+}
+
+// TokenMatchMode controls how a query token generates candidate documents
+type TokenMatchMode string
+
+const (
+	TokenMatchExact  TokenMatchMode = "exact"  // Whole words equal to the token, no typos
+	TokenMatchPrefix TokenMatchMode = "prefix" // Words starting with the token, no typos
+	TokenMatchFuzzy  TokenMatchMode = "fuzzy"  // Words starting with the token or within MaxTypos edits, regardless of word-size thresholds
+)
+
+// MatchingStrategy controls how many query tokens a document must match to be a result
+type MatchingStrategy string
+
+const (
+	MatchingStrategyAll  MatchingStrategy = "all"  // Every token, the default
+	MatchingStrategyAny  MatchingStrategy = "any"  // At least one token
+	MatchingStrategyMost MatchingStrategy = "most" // More than half of the tokens
+)
+
+// QueryToken is a query token with an explicit match mode
+type QueryToken struct {
+	Token    string         `json:"token"`
+	Mode     TokenMatchMode `json:"mode"`
+	MaxTypos int            `json:"max_typos,omitempty"` // Fuzzy only: 1 or 2, defaults to 1
 }
 
 type SearchQuery struct {
@@ -36,10 +134,31 @@ type SearchQuery struct {
 	Filters                  *Filters `json:"filters,omitempty"` // Complex filter expressions
 	Page                     int
 	PageSize                 int
-	RestrictSearchableFields []string `json:"restrict_searchable_fields,omitempty"` // Optional: subset of searchable fields to search in
-	RetrievableFields        []string `json:"retrievable_fields,omitempty"`         // Optional: subset of document fields to return in results
-	MinWordSizeFor1Typo      *int     `json:"min_word_size_for_1_typo,omitempty"`   // Optional: override index setting for minimum word size for 1 typo
-	MinWordSizeFor2Typos     *int     `json:"min_word_size_for_2_typos,omitempty"`  // Optional: override index setting for minimum word size for 2 typos
+	RestrictSearchableFields []string           `json:"restrict_searchable_fields,omitempty"` // Optional: subset of searchable fields to search in
+	ExcludeSearchableFields  []string           `json:"exclude_searchable_fields,omitempty"`  // Optional: searchable fields left out of the search
+	ExcludeTerms             []string           `json:"exclude_terms,omitempty"`              // Optional: documents containing any of these terms are left out of the results
+	MatchingStrategy         MatchingStrategy   `json:"matching_strategy,omitempty"`          // Optional: how many query tokens documents must match, "all" by default
+	PrefixLast               bool               `json:"prefix_last,omitempty"`                // Optional: match the last query token as a prefix in every field, for search-as-you-type
+	RequireSameField         bool               `json:"require_same_field,omitempty"`         // Optional: documents must match the required query tokens within a single field
+	RetrievableFields        []string           `json:"retrievable_fields,omitempty"`         // Optional: subset of document fields to return in results
+	MinWordSizeFor1Typo      *int               `json:"min_word_size_for_1_typo,omitempty"`   // Optional: override index setting for minimum word size for 1 typo
+	MinWordSizeFor2Typos     *int               `json:"min_word_size_for_2_typos,omitempty"`  // Optional: override index setting for minimum word size for 2 typos
+	Tokens                   []QueryToken       `json:"tokens,omitempty"`                     // Optional: tokens with explicit match modes, used instead of QueryString
+	NormalizedPreview        bool               `json:"normalized_preview,omitempty"`         // Optional: debug flag returning the normalized text used for matching
+	MaxMatchesPerField       int                `json:"max_matches_per_field,omitempty"`      // Optional: maximum matched terms reported per field in FieldMatches, 0 for all
+	FieldsToReport           []string           `json:"fields_to_report,omitempty"`           // Optional: fields reported in FieldMatches, all matched fields when empty
+	Facets                   []string           `json:"facets,omitempty"`                     // Optional: filterable fields whose value counts are returned in Facets
+	FieldWeights             map[string]float64 `json:"field_weights,omitempty"`              // Optional: override index setting for the score multiplier of matches in each searchable field
+	QueryTokenDecay          *float64           `json:"query_token_decay,omitempty"`          // Optional: override index setting for the weight of each query token relative to the one before it
+	Boosts                   []BoostRule        `json:"boosts,omitempty"`                     // Optional: score changes of the hits matching filter conditions, applied before ranking
+	FilterLocale             string             `json:"filter_locale,omitempty"`              // Optional: locale of numbers and dates written as strings in filter values (e.g., "de")
+	Sample                   float64            `json:"sample,omitempty"`                     // Optional: share of the candidates evaluated, between 0 and 1, with counts extrapolated
+	Suggest                  bool               `json:"suggest,omitempty"`                    // Optional: suggest corrected or relaxed queries when nothing is found
+	ExplainFilters           bool               `json:"explain_filters,omitempty"`            // Optional: report the filter conditions and groups each hit matched
+	IDsOnly                  bool               `json:"ids_only,omitempty"`                   // Optional: return HitRefs, without documents, instead of Hits
+	SearchAfter              string             `json:"search_after,omitempty"`               // Optional: NextCursor of the previous page, to return the hits ranked after it
+	RequestID                string             `json:"-"`                                    // Optional: ID of the API request the search serves, for its logs
+	Context                  context.Context    `json:"-"`                                    // Optional: context of the API request the search serves, whose trace its spans join
 }
 
 // MultiSearchQuery represents a request to execute multiple named search queries
@@ -47,17 +166,41 @@ type MultiSearchQuery struct {
 	Queries  []NamedSearchQuery `json:"queries"`
 	Page     int                `json:"page,omitempty"`
 	PageSize int                `json:"page_size,omitempty"`
+	// Keep each document only in the results of the first query, in request order, that matches it
+	Deduplicate bool `json:"deduplicate,omitempty"`
+	// ID of the API request the searches serve, for their logs
+	RequestID string `json:"-"`
+	// Context of the API request the searches serve, whose trace their spans join
+	Context context.Context `json:"-"`
 }
 
 // NamedSearchQuery represents a single named search query within a multi-search request
 type NamedSearchQuery struct {
-	Name                     string   `json:"name"`
-	Query                    string   `json:"query"`
-	RestrictSearchableFields []string `json:"restrict_searchable_fields,omitempty"`
-	RetrievableFields        []string `json:"retrievable_fields,omitempty"`
-	Filters                  *Filters `json:"filters,omitempty"`
-	MinWordSizeFor1Typo      *int     `json:"min_word_size_for_1_typo,omitempty"`
-	MinWordSizeFor2Typos     *int     `json:"min_word_size_for_2_typos,omitempty"`
+	Name                     string             `json:"name"`
+	Query                    string             `json:"query"`
+	RestrictSearchableFields []string           `json:"restrict_searchable_fields,omitempty"`
+	ExcludeSearchableFields  []string           `json:"exclude_searchable_fields,omitempty"`
+	ExcludeTerms             []string           `json:"exclude_terms,omitempty"`
+	MatchingStrategy         MatchingStrategy   `json:"matching_strategy,omitempty"`
+	PrefixLast               bool               `json:"prefix_last,omitempty"`
+	RequireSameField         bool               `json:"require_same_field,omitempty"`
+	RetrievableFields        []string           `json:"retrievable_fields,omitempty"`
+	Filters                  *Filters           `json:"filters,omitempty"`
+	MinWordSizeFor1Typo      *int               `json:"min_word_size_for_1_typo,omitempty"`
+	MinWordSizeFor2Typos     *int               `json:"min_word_size_for_2_typos,omitempty"`
+	Tokens                   []QueryToken       `json:"tokens,omitempty"`
+	NormalizedPreview        bool               `json:"normalized_preview,omitempty"`
+	MaxMatchesPerField       int                `json:"max_matches_per_field,omitempty"`
+	FieldsToReport           []string           `json:"fields_to_report,omitempty"`
+	FieldWeights             map[string]float64 `json:"field_weights,omitempty"`
+	QueryTokenDecay          *float64           `json:"query_token_decay,omitempty"`
+	Boosts                   []BoostRule        `json:"boosts,omitempty"`
+	FilterLocale             string             `json:"filter_locale,omitempty"`
+	Sample                   float64            `json:"sample,omitempty"`
+	Facets                   []string           `json:"facets,omitempty"`
+	Suggest                  bool               `json:"suggest,omitempty"`
+	ExplainFilters           bool               `json:"explain_filters,omitempty"`
+	IDsOnly                  bool               `json:"ids_only,omitempty"`
 }
 
 // MultiSearchResult represents the response from a multi-search operation
@@ -69,17 +212,42 @@ type MultiSearchResult struct {
 
 // FilterCondition represents a single filter condition
 type FilterCondition struct {
+	ID       string      `json:"id,omitempty"` // Optional name reported in MatchedFilters instead of the condition's path
 	Field    string      `json:"field"`
 	Operator string      `json:"operator"`
 	Value    interface{} `json:"value"`
-	Score    float64     `json:"score,omitempty"` // Optional score boost for matching this condition
+	Score    float64     `json:"score,omitempty"`    // Optional score boost for matching this condition
+	Optional bool        `json:"optional,omitempty"` // Never excludes documents, only adds its score to those matching it
 }
 
+// FilterAggregation controls how the scores of the matched conditions and groups of a filter
+// expression are combined into its score
+type FilterAggregation string
+
+const (
+	FilterAggregationSum FilterAggregation = "sum" // Sum of the scores, the default
+	FilterAggregationMax FilterAggregation = "max" // Highest score
+	FilterAggregationAvg FilterAggregation = "avg" // Mean of the scores
+)
+
 // Filters represents a complex filter expression with AND/OR logic
 type Filters struct {
-	Operator string            `json:"operator"` // "AND" or "OR"
-	Filters  []FilterCondition `json:"filters"`
-	Groups   []Filters         `json:"groups"` // Nested filter expressions
+	ID          string            `json:"id,omitempty"` // Optional name reported in MatchedFilters instead of the group's path
+	Operator    string            `json:"operator"`     // "AND" or "OR"
+	Filters     []FilterCondition `json:"filters"`
+	Groups      []Filters         `json:"groups"`                // Nested filter expressions
+	Aggregation FilterAggregation `json:"aggregation,omitempty"` // How matched scores are combined; defaults to sum
+	Weight      float64           `json:"weight,omitempty"`      // Multiplies the expression's score when set
+	Optional    bool              `json:"optional,omitempty"`    // Never excludes documents, only adds its score to those matching it
+}
+
+// BoostRule changes the score of the hits whose documents match its filter: the score is multiplied
+// by Multiplier, when set, and then Addend is added to it. Filter scores of the conditions are not
+// used.
+type BoostRule struct {
+	Filter     Filters `json:"filter"`
+	Multiplier float64 `json:"multiplier,omitempty"`
+	Addend     float64 `json:"addend,omitempty"`
 }
 
 // Indexer defines operations for adding data to an index
@@ -99,18 +267,29 @@ type MultiSearcher interface {
 	MultiSearch(query MultiSearchQuery) (*MultiSearchResult, error)
 }
 
-// IndexManager manages the lifecycle of indices
-type IndexManager interface {
+// IndexLifecycle defines creating, looking up, renaming and deleting indices
+type IndexLifecycle interface {
 	CreateIndex(settings config.IndexSettings) error
 	GetIndex(name string) (IndexAccessor, error) // IndexAccessor combines Indexer and Searcher
-	GetIndexSettings(name string) (config.IndexSettings, error)
-	UpdateIndexSettings(name string, settings config.IndexSettings) error
 	RenameIndex(oldName, newName string) error
 	DeleteIndex(name string) error
 	ListIndexes() []string
 	PersistIndexData(indexName string) error
 }
 
+// SettingsManager defines reading and replacing the settings of an index
+type SettingsManager interface {
+	GetIndexSettings(name string) (config.IndexSettings, error)
+	UpdateIndexSettings(name string, settings config.IndexSettings) error
+}
+
+// IndexManager manages the lifecycle and settings of indices. Code that needs only part of it
+// should depend on the smaller interface it is made of.
+type IndexManager interface {
+	IndexLifecycle
+	SettingsManager
+}
+
 // IndexManagerWithReindex extends IndexManager with reindexing capabilities for settings updates
 type IndexManagerWithReindex interface {
 	IndexManager
@@ -129,6 +308,217 @@ type JobManager interface {
 	ListJobs(indexName string, status *model.JobStatus) []*model.Job
 }
 
+// JobRunner runs background jobs and tracks their status, progress and metadata, for JobManager
+// to report. A job is created pending, then run once by ExecuteJob, which returns as soon as the
+// job is scheduled; the job fails if its function returns an error.
+type JobRunner interface {
+	JobManager
+	CreateJob(jobType model.JobType, indexName string, metadata map[string]string) string // Returns job ID
+	ExecuteJob(jobID string, run func(ctx context.Context, job *model.Job) error) error
+	UpdateJobProgress(jobID string, current, total int, message string)
+	SetJobMetadata(jobID, key, value string)
+	Stop() // Shuts the runner down once its running jobs have returned
+}
+
+// JobController defines listing jobs across indexes, cancelling them and removing the records of
+// finished jobs
+type JobController interface {
+	ListAllJobs(filter model.JobFilter) []*model.Job // Newest first
+	CancelJob(jobID string) (*model.Job, error)
+	CleanupJobs(olderThan time.Duration) int // Returns the number of jobs removed
+}
+
+// BatchManager defines operations for staging document changes and committing them atomically
+type BatchManager interface {
+	OpenBatch(indexName string) (model.BatchInfo, error)
+	AddToBatch(indexName, batchID string, docs []model.Document) (model.BatchInfo, error)
+	DeleteInBatch(indexName, batchID, documentID string) (model.BatchInfo, error)
+	GetBatch(indexName, batchID string) (model.BatchInfo, error)
+	AbortBatch(indexName, batchID string) error
+	CommitBatchAsync(indexName, batchID string) (string, error)
+}
+
+// RuleListQuery defines the sorting and pagination of a rule listing. All rules are listed unless
+// Page or PageSize is set.
+type RuleListQuery struct {
+	Sort     string // created_at (default), updated_at or id
+	Order    string // asc (default) or desc
+	Page     int
+	PageSize int
+}
+
+// RuleManager defines operations for managing the merchandising rules of an index
+type RuleManager interface {
+	ListRules(indexName string, query RuleListQuery) (model.RuleList, error)
+	ImportRules(indexName string, rules []model.Rule, replace bool) (model.RuleImport, error)
+	GetRule(indexName, ruleID string) (model.Rule, error)
+	CreateRule(indexName string, rule model.Rule) (model.Rule, error)
+	UpdateRule(indexName, ruleID string, rule model.Rule) (model.Rule, error)
+	DeleteRule(indexName, ruleID string) error
+}
+
+// APIKeyManager defines operations for authenticating requests and managing scoped API keys
+type APIKeyManager interface {
+	AuthEnabled() bool
+	Authenticate(secret string) (key model.APIKey, admin bool, ok bool)
+	ListAPIKeys() []model.APIKey
+	GetAPIKey(id string) (model.APIKey, error)
+	CreateAPIKey(key model.APIKey) (model.CreatedAPIKey, error)
+	DeleteAPIKey(id string) error
+}
+
+// DocumentMatcher defines checking which documents of an index match filters, e.g. to limit the
+// documents an API key with filters can read
+type DocumentMatcher interface {
+	MatchingDocumentIDs(indexName string, documentIDs []string, filters Filters) ([]string, error) // All documents in documentID order when documentIDs is nil
+}
+
+// RelevanceTester defines operations for storing and running the relevance tests of an index
+type RelevanceTester interface {
+	GetRelevanceTests(indexName string) ([]model.RelevanceTest, error)
+	SetRelevanceTests(indexName string, tests []model.RelevanceTest) ([]model.RelevanceTest, error)
+	RunRelevanceTests(indexName string) (model.RelevanceReport, error)
+}
+
+// ShadowManager defines operations for mirroring live searches to a candidate index and comparing the results
+type ShadowManager interface {
+	EnableShadow(indexName string, config model.ShadowConfig) (model.ShadowStats, error)
+	GetShadowStats(indexName string) (model.ShadowStats, error)
+	DisableShadow(indexName string) (model.ShadowStats, error)
+	ShadowSearch(indexName string, query SearchQuery, result SearchResult, latency time.Duration)
+}
+
+// RenameAliasResolver resolves the old names of recently renamed indexes during their grace period
+type RenameAliasResolver interface {
+	ResolveRenamedIndex(name string) (newName string, expiresAt time.Time, ok bool)
+}
+
+// LocaleResolver resolves the locale-specific variant of an index that best matches an
+// Accept-Language style list of languages
+type LocaleResolver interface {
+	ResolveLocaleIndex(baseName string, acceptLanguage string) (string, error)
+}
+
+// AliasManager defines operations for index aliases, names that search and document requests can
+// use in place of the index they point to
+type AliasManager interface {
+	PutAlias(alias, indexName string) (previousIndex string, err error)
+	SwapAliases(first, second string) ([]model.Alias, error)
+	DeleteAlias(alias string) error
+	GetAlias(alias string) (model.Alias, error)
+	ListAliases() []model.Alias
+	ResolveAlias(name string) (indexName string, ok bool)
+}
+
+// PopularQuerySource provides the most frequent successful queries of an index, e.g. from search analytics
+type PopularQuerySource interface {
+	GetPopularSearches(indexName string, window time.Duration, limit int) []model.PopularSearch
+}
+
+// DataDirectory defines the directory an engine stores its data in, where other services, such as
+// analytics, keep their files too
+type DataDirectory interface {
+	DataDir() string // Empty when nothing may be written to it
+}
+
+// CacheWarmer accepts the source of the popular queries re-executed by indexes with cache warming enabled
+type CacheWarmer interface {
+	SetPopularQuerySource(source PopularQuerySource)
+}
+
+// TextAnalyzer defines operations for previewing how an index analyzes field values and queries
+type TextAnalyzer interface {
+	Analyze(indexName string, request model.AnalyzeRequest) (model.AnalyzeResult, error)
+}
+
+// Spellchecker defines operations for suggesting query corrections without searching
+type Spellchecker interface {
+	Spellcheck(indexName string, request model.SpellcheckRequest) (model.SpellcheckResult, error)
+}
+
+// TermExplorer defines operations for listing an index's indexed terms by prefix
+type TermExplorer interface {
+	Terms(indexName string, request model.TermsRequest) (model.TermsResult, error)
+}
+
+// Autocompleter defines operations for completing a typed prefix without searching
+type Autocompleter interface {
+	Suggest(indexName string, request model.SuggestRequest) (model.SuggestResult, error)
+}
+
+// IndexVerifier defines operations for checking, and optionally repairing, an index's consistency
+type IndexVerifier interface {
+	VerifyIndex(indexName string, repair bool) (model.IntegrityReport, error)
+}
+
+// IndexSnapshotter defines operations for exporting an index to an archive and restoring it,
+// e.g. for backups or to move it to another server
+type IndexSnapshotter interface {
+	SnapshotIndex(indexName string, w io.Writer) (model.SnapshotInfo, error)
+	RestoreIndex(indexName string, r io.Reader) (model.SnapshotInfo, error)
+}
+
+// IndexCloser defines closing indexes, which frees the memory they use while their files stay on
+// disk, and opening them again, e.g. for indexes that are rarely searched
+type IndexCloser interface {
+	CloseIndex(name string) error
+	OpenIndex(name string) (opened bool, err error) // Reports false when the index was open already
+	IndexState(name string) (model.IndexState, error)
+}
+
+// BulkIndexDeleter defines deleting every index matching a glob pattern in a single job, e.g. to
+// clean up the throwaway indexes of test environments
+type BulkIndexDeleter interface {
+	MatchIndexes(pattern string) ([]string, error)
+	DeleteIndexesAsync(pattern string) (string, []string, error) // Returns job ID and the indexes deleted
+}
+
+// DocumentIDGenerator defines operations for assigning generated IDs to documents added without one
+type DocumentIDGenerator interface {
+	GenerateDocumentIDs(indexName, idempotencyKey string, docs []model.Document) (map[int]string, error) // Returns the generated IDs by document position
+}
+
+// BulkIngester defines operations for indexing newline-delimited JSON documents read from a stream
+type BulkIngester interface {
+	BulkIngest(indexName string, r io.Reader) (model.BulkIngestReport, error)
+}
+
+// SearchExporter defines operations for writing every hit of a search to a file in the
+// background and downloading it once the job has completed, e.g. for reporting
+type SearchExporter interface {
+	ExportSearchAsync(indexName string, query SearchQuery, format model.SearchExportFormat, fields []string) (string, error) // Returns job ID
+	OpenSearchExport(indexName, jobID string) (model.SearchExport, io.ReadCloser, error)
+}
+
+// SequenceReader defines operations for reading the sequence numbers assigned to an index's
+// document mutations, e.g. to check that a write is searchable before reading it back
+type SequenceReader interface {
+	IndexSeq(indexName string) (model.IndexSeq, error)
+}
+
+// DocumentFetcher defines fetching documents of an index by ID, e.g. to hydrate the hits of an
+// IDs-only search, and listing them
+type DocumentFetcher interface {
+	GetDocument(indexName, documentID string, fields []string) (model.Document, error)
+	MultiGetDocuments(indexName string, documentIDs []string, fields []string) (model.MultiGetResult, error)
+	ListDocuments(indexName string, page, pageSize int, fields []string) (model.DocumentList, error)
+}
+
+// DocumentExportQuery selects the documents of an index to export and their fields
+type DocumentExportQuery struct {
+	Filters           *Filters // Optional: only documents matching the filters are exported
+	FilterLocale      string   // Optional: locale of numbers and dates written as strings in filter values
+	RetrievableFields []string // Fields to export besides documentID; defaults to the index's default retrievable fields, then all fields
+	After             string   // Cursor: only documents whose documentID sorts after it are exported
+	Limit             int      // Maximum number of documents exported; 0 exports all of them
+}
+
+// DocumentExporter defines streaming the documents of an index in documentID order, e.g. to dump
+// it for ETL or backups
+type DocumentExporter interface {
+	ExportDocuments(indexName string, query DocumentExportQuery, emit func(model.Document) error) (string, error) // Returns the cursor of the next page
+}
+
 type IndexAccessor interface {
 	Indexer
 	Searcher
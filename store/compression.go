@@ -0,0 +1,209 @@
+package store
+
+import (
+	"bytes"
+	"compress/flate"
+	"container/list"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// compressDocument encodes a document and compresses it with DEFLATE. It returns nil, without an
+// error, when the encoded document is smaller than minBytes and is not worth compressing.
+func compressDocument(doc model.Document, minBytes int) ([]byte, error) {
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(map[string]interface{}(doc)); err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	if encoded.Len() < minBytes {
+		return nil, nil
+	}
+
+	var compressed bytes.Buffer
+	writer, err := flate.NewWriter(&compressed, flate.BestSpeed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compressor: %w", err)
+	}
+	if _, err := writer.Write(encoded.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to compress document: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress document: %w", err)
+	}
+	return compressed.Bytes(), nil
+}
+
+// decompressDocument reverses compressDocument.
+func decompressDocument(data []byte) (model.Document, error) {
+	reader := flate.NewReader(bytes.NewReader(data))
+	defer reader.Close()
+	encoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress document: %w", err)
+	}
+
+	var doc model.Document
+	if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	return doc, nil
+}
+
+// SetCompression changes how documents are stored: with compression settings, documents whose
+// encoded size reaches the minimum are compressed, and with nil all documents are stored verbatim.
+// Stored documents are converted right away. It is a no-op if the settings did not change.
+func (ds *DocumentStore) SetCompression(settings *config.Compression) {
+	ds.Mu.Lock()
+	defer ds.Mu.Unlock()
+
+	if settings == nil && ds.compression == nil && len(ds.compressed) == 0 {
+		return
+	}
+	if settings != nil && ds.compression != nil && *settings == *ds.compression {
+		return
+	}
+
+	// Documents are decompressed first, so they are compressed again under the new settings
+	for internalID := range ds.compressed {
+		if doc, exists := ds.GetUnsafe(internalID); exists {
+			ds.Docs[internalID] = doc
+		}
+	}
+	ds.compressed = nil
+	ds.compression = nil
+	ds.cache = nil
+	if settings == nil {
+		return
+	}
+
+	current := *settings
+	ds.compression = &current
+	ds.cache = newDocumentCache(current.CachedDocuments())
+	for internalID, doc := range ds.Docs {
+		ds.SetUnsafe(internalID, doc)
+	}
+}
+
+// CompressionStats describes the compressed documents, or returns nil if compression is disabled.
+func (ds *DocumentStore) CompressionStats() *model.DocumentCompressionStats {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+
+	if ds.compression == nil {
+		return nil
+	}
+	stats := &model.DocumentCompressionStats{
+		Algorithm:           config.CompressionDeflate,
+		CompressedDocuments: len(ds.compressed),
+	}
+	for _, data := range ds.compressed {
+		stats.CompressedBytes += int64(len(data))
+	}
+	stats.CachedDocuments, stats.CacheHits, stats.CacheMisses = ds.cache.stats()
+	return stats
+}
+
+// documentCache is an LRU cache of decompressed documents. A nil cache caches nothing.
+type documentCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Most recently used first
+	entries map[uint32]*list.Element
+	hits    int64
+	misses  int64
+}
+
+type cachedDocument struct {
+	internalID uint32
+	doc        model.Document
+}
+
+func newDocumentCache(size int) *documentCache {
+	return &documentCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[uint32]*list.Element),
+	}
+}
+
+// get returns a cached document and marks it as recently used.
+func (c *documentCache) get(internalID uint32) (model.Document, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, exists := c.entries[internalID]
+	if !exists {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*cachedDocument).doc, true
+}
+
+// add caches a document, evicting the least recently used one when the cache is full.
+func (c *documentCache) add(internalID uint32, doc model.Document) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, exists := c.entries[internalID]; exists {
+		element.Value.(*cachedDocument).doc = doc
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[internalID] = c.order.PushFront(&cachedDocument{internalID: internalID, doc: doc})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedDocument).internalID)
+	}
+}
+
+// remove drops a document from the cache.
+func (c *documentCache) remove(internalID uint32) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, exists := c.entries[internalID]; exists {
+		c.order.Remove(element)
+		delete(c.entries, internalID)
+	}
+}
+
+// clear drops all cached documents.
+func (c *documentCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+}
+
+// stats returns the number of cached documents, hits and misses.
+func (c *documentCache) stats() (int, int64, int64) {
+	if c == nil {
+		return 0, 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len(), c.hits, c.misses
+}
+
+// logDecompressionError reports a compressed document that could not be read.
+func logDecompressionError(internalID uint32, err error) {
+	slog.Error("failed to read compressed document", "internal_id", internalID, "error", err)
+}
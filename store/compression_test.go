@@ -0,0 +1,94 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func newTestStore() *DocumentStore {
+	return &DocumentStore{
+		Docs:                   make(map[uint32]model.Document),
+		ExternalIDtoInternalID: make(map[string]uint32),
+	}
+}
+
+func TestDocumentStore_Compression(t *testing.T) {
+	ds := newTestStore()
+	large := model.Document{
+		"documentID": "large",
+		"body":       strings.Repeat("the quick brown fox jumps over the lazy dog ", 50),
+		"tags":       []interface{}{"a", "b"},
+		"year":       float64(1999),
+	}
+	small := model.Document{"documentID": "small", "title": "Tiny"}
+	ds.Put("large", 0, large)
+	ds.SetCompression(&config.Compression{MinDocumentBytes: 256, CacheSize: 1})
+	ds.Put("small", 1, small)
+
+	if len(ds.compressed) != 1 || len(ds.Docs) != 1 {
+		t.Fatalf("Expected only the large document to be compressed, got %d compressed and %d verbatim", len(ds.compressed), len(ds.Docs))
+	}
+	if ds.Len() != 2 {
+		t.Errorf("Expected 2 documents, got %d", ds.Len())
+	}
+	for internalID, expected := range map[uint32]model.Document{0: large, 1: small} {
+		if doc, exists := ds.Get(internalID); !exists || !reflect.DeepEqual(doc, expected) {
+			t.Errorf("Expected document %d to read back as %v, got %v", internalID, expected, doc)
+		}
+	}
+
+	ds.Get(0)
+	stats := ds.CompressionStats()
+	if stats.CompressedDocuments != 1 || stats.CachedDocuments != 1 || stats.CacheHits != 1 || stats.CacheMisses != 1 {
+		t.Errorf("Expected one cached document read once from the cache, got %+v", stats)
+	}
+	if stats.CompressedBytes == 0 || stats.CompressedBytes >= int64(len(large["body"].(string))) {
+		t.Errorf("Expected the compressed document to be smaller than its body, got %d bytes", stats.CompressedBytes)
+	}
+
+	// Replacing a document drops its cached version
+	updated := model.Document{"documentID": "large", "body": strings.Repeat("lorem ipsum dolor sit amet ", 50)}
+	ds.Put("large", 0, updated)
+	if doc, _ := ds.Get(0); !reflect.DeepEqual(doc, updated) {
+		t.Errorf("Expected the updated document, got %v", doc)
+	}
+
+	ds.SetCompression(nil)
+	if len(ds.compressed) != 0 || len(ds.Docs) != 2 || ds.CompressionStats() != nil {
+		t.Errorf("Expected all documents to be stored verbatim once compression is disabled")
+	}
+	if doc, _ := ds.Get(0); !reflect.DeepEqual(doc, updated) {
+		t.Errorf("Expected the decompressed document to be unchanged, got %v", doc)
+	}
+}
+
+func TestDocumentStore_CompressedGobRoundTrip(t *testing.T) {
+	ds := newTestStore()
+	ds.SetCompression(&config.Compression{MinDocumentBytes: 1})
+	doc := model.Document{"documentID": "1", "title": "Persisted Compressed"}
+	ds.Put("1", 0, doc)
+	ds.NextID = 1
+
+	data, err := ds.GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode() error = %v", err)
+	}
+	loaded := &DocumentStore{}
+	if err := loaded.GobDecode(data); err != nil {
+		t.Fatalf("GobDecode() error = %v", err)
+	}
+
+	if len(loaded.compressed) != 1 {
+		t.Errorf("Expected the document to stay compressed after loading, got %d compressed", len(loaded.compressed))
+	}
+	if got, exists := loaded.Get(0); !exists || !reflect.DeepEqual(got, doc) {
+		t.Errorf("Expected the loaded document %v, got %v", doc, got)
+	}
+	if internalID, exists := loaded.Lookup("1"); !exists || internalID != 0 || loaded.NextID != 1 {
+		t.Errorf("Expected the mappings and next ID to be loaded, got %d (%v), next ID %d", internalID, exists, loaded.NextID)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"sync"
 
+	"github.com/gcbaptista/go-search-engine/config"
 	"github.com/gcbaptista/go-search-engine/model"
 )
 
@@ -22,19 +26,31 @@ func init() {
 	gob.Register(false)
 }
 
+// DocumentStore holds the full documents of an index. With compression enabled, a document is kept
+// either verbatim in Docs or compressed, so documents are read through Get and Range rather than Docs.
 type DocumentStore struct {
 	Mu                     sync.RWMutex
-	Docs                   map[uint32]model.Document // Internal ID to full document
+	Docs                   map[uint32]model.Document // Internal ID to full document, for documents stored verbatim
 	ExternalIDtoInternalID map[string]uint32         // User-provided ID to internal uint32 ID
 	NextID                 uint32
+	LastSeq                uint64 // Sequence number of the latest document mutation
+
+	compressed  map[uint32][]byte   // Internal ID to compressed document
+	compression *config.Compression // nil when documents are stored verbatim
+	cache       *documentCache      // Recently read compressed documents, decompressed
+
+	sortableFields []string             // Fields whose sort keys are built as documents are stored
+	sortKeys       map[uint32][]SortKey // Internal ID to the sort keys of the document, by sortable field
 }
 
 // gobDocumentStoreData is a helper struct for Gob encoding/decoding DocumentStore data.
-// It excludes the mutex.
+// It excludes the mutex, the cache and the sort keys, which are rebuilt from the documents.
 type gobDocumentStoreData struct {
 	Docs                   map[uint32]model.Document
 	ExternalIDtoInternalID map[string]uint32
 	NextID                 uint32
+	Compressed             map[uint32][]byte // Absent from stores saved before compression was supported
+	Seq                    uint64            // Absent from stores saved before sequence numbers were assigned
 }
 
 // GobEncode implements the gob.GobEncoder interface for DocumentStore.
@@ -76,6 +92,8 @@ func (ds *DocumentStore) GobEncode() ([]byte, error) {
 		Docs:                   storableDocs, // Use the modified docs
 		ExternalIDtoInternalID: ds.ExternalIDtoInternalID,
 		NextID:                 ds.NextID,
+		Compressed:             ds.compressed,
+		Seq:                    ds.LastSeq,
 	}
 
 	var buf bytes.Buffer
@@ -102,6 +120,11 @@ func (ds *DocumentStore) GobDecode(data []byte) error {
 	ds.Docs = decodedData.Docs
 	ds.ExternalIDtoInternalID = decodedData.ExternalIDtoInternalID
 	ds.NextID = decodedData.NextID
+	ds.compressed = decodedData.Compressed
+	ds.LastSeq = decodedData.Seq
+	ds.cache.clear()
+	ds.sortableFields = nil
+	ds.sortKeys = nil
 
 	// Ensure maps are initialized if they were nil after decoding
 	if ds.Docs == nil {
@@ -117,3 +140,217 @@ func (ds *DocumentStore) GobDecode(data []byte) error {
 
 	return nil
 }
+
+// Get returns a document by its internal ID. The document must not be modified.
+func (ds *DocumentStore) Get(internalID uint32) (model.Document, bool) {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	return ds.GetUnsafe(internalID)
+}
+
+// GetUnsafe is Get for callers that hold the lock or otherwise keep writers out.
+func (ds *DocumentStore) GetUnsafe(internalID uint32) (model.Document, bool) {
+	if doc, exists := ds.Docs[internalID]; exists {
+		return doc, true
+	}
+	data, exists := ds.compressed[internalID]
+	if !exists {
+		return nil, false
+	}
+	if doc, cached := ds.cache.get(internalID); cached {
+		return doc, true
+	}
+	doc, err := decompressDocument(data)
+	if err != nil {
+		logDecompressionError(internalID, err)
+		return nil, false
+	}
+	ds.cache.add(internalID, doc)
+	return doc, true
+}
+
+// Lookup returns the internal ID of a document by its external ID.
+func (ds *DocumentStore) Lookup(externalID string) (uint32, bool) {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	internalID, exists := ds.ExternalIDtoInternalID[externalID]
+	return internalID, exists
+}
+
+// ExternalIDs returns the external IDs of the stored documents, sorted.
+func (ds *DocumentStore) ExternalIDs() []string {
+	ds.Mu.RLock()
+	ids := make([]string, 0, len(ds.ExternalIDtoInternalID))
+	for externalID := range ds.ExternalIDtoInternalID {
+		ids = append(ids, externalID)
+	}
+	ds.Mu.RUnlock()
+	slices.Sort(ids)
+	return ids
+}
+
+// GetByExternalID returns a document by its external ID without caching it once decompressed, for
+// scans that should not evict hot documents. The document must not be modified.
+func (ds *DocumentStore) GetByExternalID(externalID string) (model.Document, bool) {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	internalID, exists := ds.ExternalIDtoInternalID[externalID]
+	if !exists {
+		return nil, false
+	}
+	if doc, exists := ds.Docs[internalID]; exists {
+		return doc, true
+	}
+	data, exists := ds.compressed[internalID]
+	if !exists {
+		return nil, false
+	}
+	doc, err := decompressDocument(data)
+	if err != nil {
+		logDecompressionError(internalID, err)
+		return nil, false
+	}
+	return doc, true
+}
+
+// Len returns the number of stored documents.
+func (ds *DocumentStore) Len() int {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	return len(ds.Docs) + len(ds.compressed)
+}
+
+// Range calls fn for every document until fn returns false. fn must not modify the store or the documents.
+func (ds *DocumentStore) Range(fn func(internalID uint32, doc model.Document) bool) {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	ds.RangeUnsafe(fn)
+}
+
+// RangeUnsafe is Range for callers that hold the lock or otherwise keep writers out.
+// Compressed documents are decompressed without being cached, so a full scan doesn't evict hot documents.
+func (ds *DocumentStore) RangeUnsafe(fn func(internalID uint32, doc model.Document) bool) {
+	for internalID, doc := range ds.Docs {
+		if !fn(internalID, doc) {
+			return
+		}
+	}
+	for internalID, data := range ds.compressed {
+		doc, err := decompressDocument(data)
+		if err != nil {
+			logDecompressionError(internalID, err)
+			continue
+		}
+		if !fn(internalID, doc) {
+			return
+		}
+	}
+}
+
+// AllocateID reserves the next internal ID.
+func (ds *DocumentStore) AllocateID() uint32 {
+	ds.Mu.Lock()
+	defer ds.Mu.Unlock()
+	internalID := ds.NextID
+	ds.NextID++
+	return internalID
+}
+
+// Seq returns the sequence number of the latest document mutation, or 0 if there was none.
+func (ds *DocumentStore) Seq() uint64 {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	return ds.LastSeq
+}
+
+// AdvanceSeq assigns the next n sequence numbers to document mutations and returns the last one.
+// Sequence numbers only ever increase, even when all documents are removed.
+func (ds *DocumentStore) AdvanceSeq(n uint64) uint64 {
+	ds.Mu.Lock()
+	defer ds.Mu.Unlock()
+	ds.LastSeq += n
+	return ds.LastSeq
+}
+
+// Put stores a document under its internal ID and maps its external ID to it.
+func (ds *DocumentStore) Put(externalID string, internalID uint32, doc model.Document) {
+	ds.Mu.Lock()
+	defer ds.Mu.Unlock()
+	ds.SetUnsafe(internalID, doc)
+	ds.ExternalIDtoInternalID[externalID] = internalID
+}
+
+// SetUnsafe stores a document under its internal ID, compressing it if compression is enabled and
+// the document is large enough. The caller must hold the lock.
+func (ds *DocumentStore) SetUnsafe(internalID uint32, doc model.Document) {
+	ds.cache.remove(internalID)
+	if len(ds.sortableFields) > 0 {
+		if ds.sortKeys == nil {
+			ds.sortKeys = make(map[uint32][]SortKey)
+		}
+		ds.sortKeys[internalID] = buildSortKeys(doc, ds.sortableFields)
+	}
+	if ds.compression != nil {
+		data, err := compressDocument(doc, ds.compression.MinBytes())
+		if err != nil {
+			slog.Warn("storing document uncompressed", "internal_id", internalID, "error", err)
+		}
+		if data != nil {
+			if ds.compressed == nil {
+				ds.compressed = make(map[uint32][]byte)
+			}
+			ds.compressed[internalID] = data
+			delete(ds.Docs, internalID)
+			return
+		}
+	}
+	delete(ds.compressed, internalID)
+	ds.Docs[internalID] = doc
+}
+
+// Remove deletes a document and its external ID mapping.
+func (ds *DocumentStore) Remove(externalID string, internalID uint32) {
+	ds.Mu.Lock()
+	defer ds.Mu.Unlock()
+	ds.DeleteUnsafe(internalID)
+	delete(ds.ExternalIDtoInternalID, externalID)
+}
+
+// DeleteUnsafe deletes a document, leaving the external ID mappings alone. The caller must hold the lock.
+func (ds *DocumentStore) DeleteUnsafe(internalID uint32) {
+	delete(ds.Docs, internalID)
+	delete(ds.compressed, internalID)
+	delete(ds.sortKeys, internalID)
+	ds.cache.remove(internalID)
+}
+
+// Clone returns a copy of the store as it is now. Documents are never modified once stored, so the
+// copy shares them and only the maps are copied. The copy does not cache decompressed documents.
+func (ds *DocumentStore) Clone() *DocumentStore {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	return &DocumentStore{
+		Docs:                   maps.Clone(ds.Docs),
+		ExternalIDtoInternalID: maps.Clone(ds.ExternalIDtoInternalID),
+		NextID:                 ds.NextID,
+		LastSeq:                ds.LastSeq,
+		compressed:             maps.Clone(ds.compressed),
+		compression:            ds.compression,
+		sortableFields:         ds.sortableFields,
+		sortKeys:               maps.Clone(ds.sortKeys),
+	}
+}
+
+// Reset removes all documents and mappings and restarts internal IDs from 0. Sequence numbers continue.
+func (ds *DocumentStore) Reset() {
+	ds.Mu.Lock()
+	defer ds.Mu.Unlock()
+	ds.Docs = make(map[uint32]model.Document)
+	ds.ExternalIDtoInternalID = make(map[string]uint32)
+	ds.NextID = 0
+	ds.compressed = nil
+	ds.cache.clear()
+	if ds.sortKeys != nil {
+		ds.sortKeys = make(map[uint32][]SortKey)
+	}
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+// SortKeyKind is the type of the value a sort key was built from.
+type SortKeyKind uint8
+
+const (
+	SortKeyMissing SortKeyKind = iota // The document has no value in the field
+	SortKeyNumber                     // Numbers, booleans (0 and 1) and RFC 3339 dates (Unix nanoseconds)
+	SortKeyText                       // Other strings
+	SortKeyOther                      // Arrays, objects and null, which do not order documents
+)
+
+// SortKey is the typed value of a sortable field of a document, built when the document is stored
+// so that ranking compares it without type switches on the document's values.
+type SortKey struct {
+	Kind   SortKeyKind
+	Number float64
+	Text   string
+}
+
+// NewSortKey builds the sort key of a document value.
+func NewSortKey(value interface{}) SortKey {
+	switch v := value.(type) {
+	case float64:
+		return SortKey{Kind: SortKeyNumber, Number: v}
+	case float32:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case int:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case int8:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case int16:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case int32:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case int64:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case uint:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case uint8:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case uint16:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case uint32:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case uint64:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v)}
+	case bool:
+		if v {
+			return SortKey{Kind: SortKeyNumber, Number: 1}
+		}
+		return SortKey{Kind: SortKeyNumber}
+	case time.Time:
+		return SortKey{Kind: SortKeyNumber, Number: float64(v.UnixNano())}
+	case string:
+		// Only strings starting like a date are parsed, so plain text is not parsed twice
+		if len(v) >= len("2006-01-02T") && v[4] == '-' && strings.ContainsRune(v, 'T') {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return SortKey{Kind: SortKeyNumber, Number: float64(t.UnixNano())}
+			}
+		}
+		return SortKey{Kind: SortKeyText, Text: v}
+	}
+	return SortKey{Kind: SortKeyOther}
+}
+
+// Compare orders two sort keys: -1 if k comes first in ascending order, 1 if other does, and 0
+// when they are equal or of different kinds, which leave the order to the next ranking criterion.
+func (k SortKey) Compare(other SortKey) int {
+	if k.Kind != other.Kind {
+		return 0
+	}
+	switch k.Kind {
+	case SortKeyNumber:
+		return cmp.Compare(k.Number, other.Number)
+	case SortKeyText:
+		return strings.Compare(k.Text, other.Text)
+	}
+	return 0
+}
+
+// buildSortKeys returns the sort keys of the sortable fields of a document, in the order of the
+// fields.
+func buildSortKeys(doc model.Document, fields []string) []SortKey {
+	keys := make([]SortKey, len(fields))
+	for i, field := range fields {
+		if value, exists := doc[field]; exists {
+			keys[i] = NewSortKey(value)
+		}
+	}
+	return keys
+}
+
+// SetSortableFields changes the fields whose sort keys the store builds as documents are stored,
+// and rebuilds the sort keys of the stored documents. It is a no-op if the fields did not change.
+func (ds *DocumentStore) SetSortableFields(fields []string) {
+	ds.Mu.Lock()
+	defer ds.Mu.Unlock()
+
+	if slices.Equal(fields, ds.sortableFields) {
+		return
+	}
+	ds.sortableFields = slices.Clone(fields)
+	ds.sortKeys = nil
+	if len(fields) == 0 {
+		return
+	}
+	ds.sortKeys = make(map[uint32][]SortKey, len(ds.Docs)+len(ds.compressed))
+	ds.RangeUnsafe(func(internalID uint32, doc model.Document) bool {
+		ds.sortKeys[internalID] = buildSortKeys(doc, fields)
+		return true
+	})
+}
+
+// SortableFields returns the fields the store builds sort keys of. The returned slice must not be
+// modified.
+func (ds *DocumentStore) SortableFields() []string {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	return ds.sortableFields
+}
+
+// SortKeys returns the sort keys of a document, in the order of SortableFields, or nil when the
+// store has no sortable fields or no such document. The returned slice must not be modified.
+func (ds *DocumentStore) SortKeys(internalID uint32) []SortKey {
+	ds.Mu.RLock()
+	defer ds.Mu.RUnlock()
+	return ds.sortKeys[internalID]
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcbaptista/go-search-engine/config"
+	"github.com/gcbaptista/go-search-engine/model"
+)
+
+func TestNewSortKey(t *testing.T) {
+	released := time.Date(1999, 3, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value interface{}
+		want  SortKey
+	}{
+		{float64(8.7), SortKey{Kind: SortKeyNumber, Number: 8.7}},
+		{42, SortKey{Kind: SortKeyNumber, Number: 42}},
+		{true, SortKey{Kind: SortKeyNumber, Number: 1}},
+		{released, SortKey{Kind: SortKeyNumber, Number: float64(released.UnixNano())}},
+		{"1999-03-31T00:00:00Z", SortKey{Kind: SortKeyNumber, Number: float64(released.UnixNano())}},
+		{"The Matrix", SortKey{Kind: SortKeyText, Text: "The Matrix"}},
+		{"1999-03-31", SortKey{Kind: SortKeyText, Text: "1999-03-31"}},
+		{[]interface{}{"a"}, SortKey{Kind: SortKeyOther}},
+		{nil, SortKey{Kind: SortKeyOther}},
+	}
+	for _, tt := range tests {
+		if got := NewSortKey(tt.value); got != tt.want {
+			t.Errorf("NewSortKey(%v) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+
+	if NewSortKey(1.0).Compare(NewSortKey(2.0)) != -1 || NewSortKey("b").Compare(NewSortKey("a")) != 1 {
+		t.Error("Expected keys of the same kind to compare by value")
+	}
+	if NewSortKey(1.0).Compare(NewSortKey("a")) != 0 {
+		t.Error("Expected keys of different kinds to compare equal")
+	}
+}
+
+func TestDocumentStore_SortKeys(t *testing.T) {
+	ds := newTestStore()
+	ds.Put("a", 0, model.Document{"documentID": "a", "popularity": float64(10), "title": "Alpha"})
+	ds.SetCompression(&config.Compression{MinDocumentBytes: 64})
+	ds.Put("b", 1, model.Document{"documentID": "b", "popularity": float64(20), "body": strings.Repeat("long text ", 20)})
+
+	if keys := ds.SortKeys(0); keys != nil {
+		t.Errorf("Expected no sort keys without sortable fields, got %v", keys)
+	}
+	ds.SetSortableFields([]string{"popularity", "title"})
+	want := map[uint32][]SortKey{
+		0: {{Kind: SortKeyNumber, Number: 10}, {Kind: SortKeyText, Text: "Alpha"}},
+		1: {{Kind: SortKeyNumber, Number: 20}, {Kind: SortKeyMissing}},
+	}
+	for internalID, keys := range want {
+		if got := ds.SortKeys(internalID); !reflect.DeepEqual(got, keys) {
+			t.Errorf("SortKeys(%d) = %v, want %v", internalID, got, keys)
+		}
+	}
+
+	ds.Put("a", 0, model.Document{"documentID": "a", "popularity": float64(30)})
+	if got := ds.SortKeys(0); got[0].Number != 30 || got[1].Kind != SortKeyMissing {
+		t.Errorf("Expected the sort keys of an updated document to be rebuilt, got %v", got)
+	}
+	if clone := ds.Clone(); !reflect.DeepEqual(clone.SortKeys(1), ds.SortKeys(1)) {
+		t.Error("Expected a clone to keep the sort keys")
+	}
+	ds.Remove("a", 0)
+	if keys := ds.SortKeys(0); keys != nil {
+		t.Errorf("Expected the sort keys of a removed document to be dropped, got %v", keys)
+	}
+
+	ds.SetSortableFields(nil)
+	if keys := ds.SortKeys(1); keys != nil {
+		t.Errorf("Expected no sort keys once sortable fields are cleared, got %v", keys)
+	}
+}